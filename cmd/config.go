@@ -0,0 +1,395 @@
+// Path: cmd/config.go
+package main
+
+import (
+	"bank-api/internal/handlers"
+	"bank-api/internal/services"
+	"bank-api/pkg/accountnumber"
+	"bank-api/pkg/utils"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config - вся конфигурация сервера из переменных окружения, прочитанная и проверенная один раз при старте.
+type Config struct {
+	DatabaseURL string
+	JWTSecret   string
+	Port        string
+
+	// RequestTimeout ограничивает обработку запроса; дедлайн доходит до запросов к БД через контекст.
+	RequestTimeout     time.Duration
+	ShutdownTimeout    time.Duration
+	AutoMigrate        bool
+	SlowQueryThreshold time.Duration
+	LogMasking         bool
+
+	// BalanceSecret подписывает хэши балансов, по умолчанию JWT_SECRET.
+	BalanceSecret string
+	// EncryptionKey шифрует чувствительные столбцы (секреты 2FA и вебхуков); пустой - без шифрования.
+	EncryptionKey []byte
+	Transaction   services.TransactionConfig
+	Auth          services.AuthConfig
+
+	FXBase   string
+	FXRates  map[string]float64
+	FXTTL    time.Duration
+	FXSpread float64
+
+	MaxPayees            int
+	BalanceAuditInterval time.Duration
+	InterestInterval     time.Duration
+	StatementInterval    time.Duration
+
+	// Как часто помечать истёкшие запросы денег (срок - Transaction.MoneyRequestTTL).
+	MoneyRequestExpiryInterval time.Duration
+
+	// Dormancy - когда счёт без активности становится неактивным и сколько за это списывается.
+	Dormancy              services.DormancyConfig
+	DormancyCheckInterval time.Duration
+
+	// Сколько времени после закрытия счёт ещё можно открыть снова.
+	AccountReopenWindow time.Duration
+
+	// Фоновые задачи: разброс их первого запуска и сколько задач может выполняться одновременно.
+	JobsWarmup        time.Duration
+	JobsMaxConcurrent int
+
+	Compression        bool
+	CompressionMinSize int
+	StringIDs          bool
+	TwoFactorRoutes    []string
+	MaxWritesPerUser   int
+	MaxWritesPerIP     int
+
+	// Заголовки безопасности и отказ в запросах по HTTP; TrustedProxies - прокси, которым верим в X-Forwarded-*.
+	Security       handlers.SecurityConfig
+	TrustedProxies []string
+
+	// Features - включённые функции; FEATURES_DISABLED перечисляет выключенные.
+	Features *handlers.FeatureFlags
+	// Messages - переводы сообщений об ошибках; MESSAGES_FILE дополняет встроенные.
+	Messages *handlers.Messages
+	// IntrospectionToken - общий секрет сервисов, которым разрешено проверять токены пользователей.
+	IntrospectionToken string
+}
+
+// LoadConfig читает конфигурацию из окружения. Все отсутствующие и некорректные переменные
+// собираются в одну ошибку, чтобы их можно было исправить за один раз.
+func LoadConfig() (*Config, error) {
+	r := &envReader{secrets: secretProviderFromEnv()}
+	cfg := &Config{
+		DatabaseURL: r.required("DATABASE_URL"),
+		JWTSecret:   r.secret("JWT_SECRET", "", minJWTSecretLength),
+		Port:        r.string("PORT", "3000"),
+
+		RequestTimeout:     r.duration("REQUEST_TIMEOUT", 30*time.Second),
+		ShutdownTimeout:    r.duration("SHUTDOWN_TIMEOUT", 30*time.Second),
+		AutoMigrate:        r.bool("AUTO_MIGRATE", false),
+		SlowQueryThreshold: r.duration("SLOW_QUERY_THRESHOLD", 0),
+		LogMasking:         r.bool("LOG_MASKING", true),
+
+		FXBase:   os.Getenv("FX_BASE_CURRENCY"),
+		FXTTL:    r.duration("FX_CACHE_TTL", time.Minute),
+		FXSpread: r.float("FX_SPREAD_PERCENT"),
+
+		MaxPayees:            r.int("MAX_PAYEES", 50),
+		BalanceAuditInterval: r.duration("BALANCE_AUDIT_INTERVAL", 0),
+		InterestInterval:     r.duration("INTEREST_INTERVAL", 0),
+		StatementInterval:    r.duration("STATEMENT_INTERVAL", 0),
+
+		MoneyRequestExpiryInterval: r.duration("MONEY_REQUEST_EXPIRY_INTERVAL", 0),
+
+		Dormancy: services.DormancyConfig{
+			After: r.duration("DORMANCY_AFTER", 0),
+			Fee:   r.float("DORMANCY_FEE"),
+		},
+		DormancyCheckInterval: r.duration("DORMANCY_CHECK_INTERVAL", 0),
+
+		AccountReopenWindow: r.duration("ACCOUNT_REOPEN_WINDOW", services.DefaultReopenWindow),
+
+		JobsWarmup:        r.duration("JOBS_WARMUP", time.Minute),
+		JobsMaxConcurrent: r.int("JOBS_MAX_CONCURRENT", 1),
+
+		Compression:        r.bool("COMPRESSION", true),
+		CompressionMinSize: r.int("COMPRESSION_MIN_SIZE", 1024),
+		StringIDs:          r.bool("JSON_STRING_IDS", false),
+		TwoFactorRoutes:    envList("TWO_FACTOR_ROUTES"),
+		MaxWritesPerUser:   r.int("MAX_CONCURRENT_WRITES_PER_USER", 2),
+		MaxWritesPerIP:     r.int("MAX_CONCURRENT_WRITES_PER_IP", 20),
+
+		Security: handlers.SecurityConfig{
+			Headers:               r.bool("SECURITY_HEADERS", true),
+			HSTSMaxAge:            r.duration("HSTS_MAX_AGE", 365*24*time.Hour),
+			HSTSIncludeSubdomains: r.bool("HSTS_INCLUDE_SUBDOMAINS", false),
+			ContentSecurityPolicy: r.string("CONTENT_SECURITY_POLICY", "frame-ancestors 'none'"),
+			RequireHTTPS:          r.bool("REQUIRE_HTTPS", false),
+		},
+		TrustedProxies: envList("TRUSTED_PROXIES"),
+	}
+
+	if cfg.JWTSecret == "" {
+		r.fail("JWT_SECRET", "не установлен")
+	}
+
+	features, err := handlers.NewFeatureFlags(envList("FEATURES_DISABLED"))
+	if err != nil {
+		r.fail("FEATURES_DISABLED", err.Error())
+		features, _ = handlers.NewFeatureFlags(nil)
+	}
+	cfg.Features = features
+	// Без 2FA подтвердить токен нельзя, и маршруты из TWO_FACTOR_ROUTES стали бы недоступны.
+	if !features.Enabled(handlers.FeatureTwoFactor) && len(cfg.TwoFactorRoutes) > 0 {
+		r.fail("TWO_FACTOR_ROUTES", "задан при выключенной функции two_factor")
+	}
+
+	messages, err := handlers.NewMessages(os.Getenv("MESSAGES_FILE"))
+	if err != nil {
+		r.fail("MESSAGES_FILE", err.Error())
+		messages, _ = handlers.NewMessages("")
+	}
+	cfg.Messages = messages
+
+	// Секрет для хэшей балансов отделён от JWT, чтобы JWT_SECRET можно было ротировать.
+	// При первой ротации укажите в BALANCE_HMAC_SECRET старое значение JWT_SECRET.
+	// Длина не проверяется: сменить этот секрет без пересчёта всех хэшей нельзя.
+	cfg.BalanceSecret = r.secret("BALANCE_HMAC_SECRET", cfg.JWTSecret, 0)
+	cfg.IntrospectionToken = r.secret("INTROSPECTION_TOKEN", "", minJWTSecretLength)
+
+	// Ключ шифрования в base64, ровно 32 байта (openssl rand -base64 32).
+	if key := r.secret("ENCRYPTION_KEY", "", 0); key != "" {
+		decoded, err := base64.StdEncoding.DecodeString(key)
+		if err != nil || len(decoded) != utils.EncryptionKeySize {
+			r.fail("ENCRYPTION_KEY", fmt.Sprintf("ожидается %d байта в base64", utils.EncryptionKeySize))
+		}
+		cfg.EncryptionKey = decoded
+	}
+
+	var accountNumbers accountnumber.Scheme
+	r.parse("ACCOUNT_NUMBER_SCHEME", func(v string) (err error) {
+		accountNumbers, err = accountnumber.SchemeByName(v, os.Getenv("IBAN_COUNTRY"), os.Getenv("IBAN_BANK_CODE"))
+		return err
+	})
+
+	// Допустимое число знаков после запятой в суммах по валютам, например "JPY:0,KWD:3".
+	var currencyDecimals map[string]int
+	r.parse("CURRENCY_DECIMALS", func(v string) (err error) {
+		currencyDecimals, err = services.ParseCurrencyDecimals(v)
+		return err
+	})
+	// Лимиты одного пополнения по источникам, например "cash:1000,card:500".
+	var sourceLimits map[string]float64
+	r.parse("DEPOSIT_SOURCE_LIMITS", func(v string) (err error) {
+		sourceLimits, err = services.ParseSourceLimits(v)
+		return err
+	})
+	// Наименьшая принимаемая купюра или монета для наличных по валютам, например "USD:1,JPY:1000".
+	var cashDenominations map[string]float64
+	r.parse("CASH_DENOMINATIONS", func(v string) (err error) {
+		cashDenominations, err = services.ParseDenominations(v)
+		return err
+	})
+	// Счета, на которые зачисляются комиссии за переводы, по валютам, например "USD:1,EUR:2".
+	var feeAccounts map[string]int
+	r.parse("FEE_ACCOUNTS", func(v string) (err error) {
+		feeAccounts, err = services.ParseFeeAccounts(v)
+		return err
+	})
+	// Курсы валют: FX_RATES задаёт стоимость единицы валюты в базовой валюте.
+	r.parse("FX_RATES", func(v string) (err error) {
+		cfg.FXRates, err = services.ParseRates(v)
+		return err
+	})
+
+	cfg.Transaction = services.TransactionConfig{
+		AccountNumbers:       accountNumbers,
+		ApprovalThreshold:    r.float("TRANSFER_APPROVAL_THRESHOLD"),
+		DepositHoldThreshold: r.float("DEPOSIT_HOLD_THRESHOLD"),
+		DepositHoldWindow:    r.duration("DEPOSIT_HOLD_WINDOW", 0),
+		MinAccountAge:        r.duration("MIN_ACCOUNT_AGE", 0),
+		DailySpendingLimit:   r.float("DAILY_SPENDING_LIMIT"),
+		CurrencyDecimals:     currencyDecimals,
+		LedgerEntries:        r.bool("LEDGER_ENTRIES", false),
+		RecordFailures:       r.bool("RECORD_FAILED_TRANSACTIONS", false),
+		// Повтор такого же перевода в течение окна возвращает результат первого (защита от двойного клика).
+		DuplicateWindow: r.duration("TRANSFER_DEDUP_WINDOW", 0),
+		// Разрешены только переводы между своими счетами (возвраты отправителю проходят всегда).
+		OwnAccountsOnly: r.bool("OWN_ACCOUNTS_ONLY", false),
+		// Переводы между своими счетами: без комиссии, кода подтверждения, одобрения и дневного лимита.
+		OwnTransferFastPath: r.bool("OWN_TRANSFER_FAST_PATH", false),
+		// Сколько запрос денег ждёт ответа плательщика (0 - без срока).
+		MoneyRequestTTL: r.duration("MONEY_REQUEST_TTL", 0),
+		// Сколько кэшируется статистика /api/me/velocity.
+		VelocityCacheTTL: r.duration("VELOCITY_CACHE_TTL", 0),
+		// Коды подтверждения пока только пишутся в лог (имитация SMS/email).
+		ConfirmationThreshold: r.float("TRANSFER_CONFIRMATION_THRESHOLD"),
+		ConfirmationTTL:       r.duration("TRANSFER_CONFIRMATION_TTL", 0),
+		// Переводы выше этой суммы должны иметь описание (0 - выключено).
+		DescriptionThreshold: r.float("TRANSFER_DESCRIPTION_THRESHOLD"),
+		// Части баланса для пополнений (например, "bonus") и порядок, в котором из них списываются деньги.
+		SubBalances: services.SubBalanceConfig{
+			Names:    envList("SUB_BALANCES"),
+			Priority: envList("SUB_BALANCE_PRIORITY"),
+		},
+		// Автоматическая заморозка счетов при подозрительной активности (0 - правило выключено).
+		FreezeRules: services.FreezeRuleConfig{
+			IntegrityFailures:     r.int("FREEZE_INTEGRITY_FAILURES", 0),
+			LargeWithdrawals:      r.int("FREEZE_LARGE_WITHDRAWALS", 0),
+			LargeWithdrawalAmount: r.float("FREEZE_LARGE_WITHDRAWAL_AMOUNT"),
+			Window:                r.duration("FREEZE_WINDOW", 0),
+		},
+		DepositSources: services.DepositSourceConfig{
+			Allowed: envList("DEPOSIT_SOURCES"),
+			Limits:  sourceLimits,
+			Flagged: envList("DEPOSIT_FLAGGED_SOURCES"),
+			// Наличные пополнения должны быть кратны наименьшему номиналу валюты.
+			CashDenominations: cashDenominations,
+		},
+		Policies: services.AccountPolicyConfig{
+			OverdraftLimit:             r.float("OVERDRAFT_LIMIT"),
+			SavingsWithdrawalsPerMonth: r.int("SAVINGS_WITHDRAWALS_PER_MONTH", services.DefaultSavingsWithdrawalsPerMonth),
+			SavingsInterestRate:        r.float("SAVINGS_INTEREST_RATE"),
+		},
+		Fees: services.FeeConfig{
+			Flat:                  r.float("TRANSFER_FEE_FLAT"),
+			Percent:               r.float("TRANSFER_FEE_PERCENT"),
+			FreeTransfersPerMonth: r.int("FEE_FREE_TRANSFERS_PER_MONTH", 0),
+			WaivedTiers:           envList("FEE_WAIVED_TIERS"),
+			Accounts:              feeAccounts,
+		},
+	}
+	// Комиссия без счёта для неё списывалась бы в никуда.
+	if fees := cfg.Transaction.Fees; (fees.Flat > 0 || fees.Percent > 0) && len(fees.Accounts) == 0 {
+		r.fail("FEE_ACCOUNTS", "не задан, а комиссия за переводы включена")
+	}
+
+	cfg.Auth = services.AuthConfig{
+		PasswordAlgo:          os.Getenv("PASSWORD_HASH_ALGORITHM"),
+		PreviousJWTSecret:     r.secret("JWT_SECRET_PREVIOUS", "", minJWTSecretLength),
+		BalanceSecret:         cfg.BalanceSecret,
+		AccountNumbers:        accountNumbers,
+		LoginHistoryRetention: r.int("LOGIN_HISTORY_RETENTION", services.DefaultLoginHistoryRetention),
+		TokenGracePeriod:      r.duration("TOKEN_GRACE_PERIOD", 0),
+		UserRetention:         r.duration("USER_RETENTION", services.DefaultUserRetention),
+		Usernames: services.UsernamePolicy{
+			MinLength: r.int("USERNAME_MIN_LENGTH", services.DefaultUsernameMinLength),
+			MaxLength: r.int("USERNAME_MAX_LENGTH", services.DefaultUsernameMaxLength),
+			Reserved:  envList("RESERVED_USERNAMES"),
+		},
+	}
+	if cfg.Auth.Usernames.MinLength > cfg.Auth.Usernames.MaxLength {
+		r.fail("USERNAME_MIN_LENGTH", "больше USERNAME_MAX_LENGTH")
+	}
+	if cfg.Auth.PasswordAlgo != "" && !services.IsSupportedPasswordAlgo(cfg.Auth.PasswordAlgo) {
+		r.fail("PASSWORD_HASH_ALGORITHM", fmt.Sprintf("%q не поддерживается (допустимо: bcrypt, argon2id)", cfg.Auth.PasswordAlgo))
+	}
+
+	if len(r.errs) > 0 {
+		return nil, fmt.Errorf("некорректная конфигурация:\n  %s", strings.Join(r.errs, "\n  "))
+	}
+	return cfg, nil
+}
+
+// envReader читает переменные окружения и копит ошибки, вместо того чтобы падать на первой.
+type envReader struct {
+	errs    []string
+	secrets SecretProvider // Необязательное хранилище секретов, см. secret
+}
+
+func (r *envReader) fail(name, msg string) {
+	r.errs = append(r.errs, fmt.Sprintf("%s: %s", name, msg))
+}
+
+// required возвращает значение обязательной переменной.
+func (r *envReader) required(name string) string {
+	v := os.Getenv(name)
+	if v == "" {
+		r.fail(name, "не установлен")
+	}
+	return v
+}
+
+// string возвращает значение переменной или def, если она не задана.
+func (r *envReader) string(name, def string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return def
+}
+
+// float читает неотрицательное число (0, если не задано).
+func (r *envReader) float(name string) float64 {
+	v := os.Getenv(name)
+	if v == "" {
+		return 0
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f < 0 {
+		r.fail(name, fmt.Sprintf("ожидается неотрицательное число, получено %q", v))
+		return 0
+	}
+	return f
+}
+
+// int читает неотрицательное целое (def, если не задано).
+func (r *envReader) int(name string, def int) int {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		r.fail(name, fmt.Sprintf("ожидается неотрицательное целое, получено %q", v))
+		return def
+	}
+	return n
+}
+
+// duration читает длительность вида "24h" (def, если не задано).
+func (r *envReader) duration(name string, def time.Duration) time.Duration {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		r.fail(name, fmt.Sprintf("ожидается длительность вида \"30s\", получено %q", v))
+		return def
+	}
+	return d
+}
+
+// bool читает true/false (def, если не задано).
+func (r *envReader) bool(name string, def bool) bool {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		r.fail(name, fmt.Sprintf("ожидается true или false, получено %q", v))
+		return def
+	}
+	return b
+}
+
+// parse передаёт значение переменной в fn (даже пустое) и записывает её ошибку.
+func (r *envReader) parse(name string, fn func(v string) error) {
+	if err := fn(os.Getenv(name)); err != nil {
+		r.fail(name, err.Error())
+	}
+}
+
+// envList читает список через запятую из переменной окружения.
+func envList(name string) []string {
+	var list []string
+	for _, item := range strings.Split(os.Getenv(name), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
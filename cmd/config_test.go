@@ -0,0 +1,164 @@
+// Path: cmd/config_test.go
+package main
+
+import (
+	"bank-api/internal/handlers"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setValidEnv sets the required variables to valid values.
+func setValidEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("SECRETS_DIR", "")
+	t.Setenv("DATABASE_URL", "postgres://localhost/bank")
+	t.Setenv("JWT_SECRET", testJWTSecret)
+}
+
+func TestLoadConfigDefaults(t *testing.T) {
+	setValidEnv(t)
+	t.Setenv("PORT", "")
+	t.Setenv("REQUEST_TIMEOUT", "")
+	t.Setenv("MAX_PAYEES", "")
+	t.Setenv("COMPRESSION", "")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.DatabaseURL != "postgres://localhost/bank" || cfg.JWTSecret != testJWTSecret {
+		t.Errorf("required values = %q, %q", cfg.DatabaseURL, cfg.JWTSecret)
+	}
+	if cfg.Port != "3000" || cfg.RequestTimeout != 30*time.Second || cfg.MaxPayees != 50 || !cfg.Compression {
+		t.Errorf("defaults: port %q, timeout %v, payees %d, compression %t", cfg.Port, cfg.RequestTimeout, cfg.MaxPayees, cfg.Compression)
+	}
+}
+
+func TestLoadConfigParsesValues(t *testing.T) {
+	setValidEnv(t)
+	t.Setenv("PORT", "8080")
+	t.Setenv("REQUEST_TIMEOUT", "5s")
+	t.Setenv("MAX_PAYEES", "10")
+	t.Setenv("COMPRESSION", "false")
+	t.Setenv("TRANSFER_FEE_FLAT", "0.5")
+	t.Setenv("FEE_ACCOUNTS", "usd:1, EUR:2")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.Port != "8080" || cfg.RequestTimeout != 5*time.Second || cfg.MaxPayees != 10 || cfg.Compression || cfg.Transaction.Fees.Flat != 0.5 {
+		t.Errorf("parsed: port %q, timeout %v, payees %d, compression %t, fee %v", cfg.Port, cfg.RequestTimeout, cfg.MaxPayees, cfg.Compression, cfg.Transaction.Fees.Flat)
+	}
+	if want := map[string]int{"USD": 1, "EUR": 2}; !reflect.DeepEqual(cfg.Transaction.Fees.Accounts, want) {
+		t.Errorf("fee accounts = %v, want %v", cfg.Transaction.Fees.Accounts, want)
+	}
+}
+
+func TestLoadConfigRequiresFeeAccounts(t *testing.T) {
+	setValidEnv(t)
+	t.Setenv("TRANSFER_FEE_PERCENT", "0.5")
+	t.Setenv("FEE_ACCOUNTS", "")
+
+	_, err := LoadConfig()
+	if err == nil || !strings.Contains(err.Error(), "FEE_ACCOUNTS:") {
+		t.Errorf("LoadConfig = %v, want FEE_ACCOUNTS reported", err)
+	}
+}
+
+func TestLoadConfigListsEveryMissingVariable(t *testing.T) {
+	t.Setenv("SECRETS_DIR", "")
+	t.Setenv("DATABASE_URL", "")
+	t.Setenv("JWT_SECRET", "")
+	t.Setenv("JWT_SECRET_FILE", "")
+
+	_, err := LoadConfig()
+	if err == nil {
+		t.Fatal("LoadConfig succeeded without DATABASE_URL and JWT_SECRET")
+	}
+	for _, name := range []string{"DATABASE_URL", "JWT_SECRET"} {
+		if !strings.Contains(err.Error(), name+":") {
+			t.Errorf("error %q does not name %s", err, name)
+		}
+	}
+}
+
+func TestLoadConfigListsEveryInvalidValue(t *testing.T) {
+	setValidEnv(t)
+	invalid := map[string]string{
+		"MAX_PAYEES":          "many",
+		"REQUEST_TIMEOUT":     "soon",
+		"COMPRESSION":         "maybe",
+		"TRANSFER_FEE_FLAT":   "-1",
+		"JOBS_MAX_CONCURRENT": "-2",
+	}
+	for name, value := range invalid {
+		t.Setenv(name, value)
+	}
+
+	_, err := LoadConfig()
+	if err == nil {
+		t.Fatal("LoadConfig accepted invalid values")
+	}
+	for name, value := range invalid {
+		if !strings.Contains(err.Error(), name+":") || !strings.Contains(err.Error(), `"`+value+`"`) {
+			t.Errorf("error does not report %s=%q:\n%v", name, value, err)
+		}
+	}
+}
+
+func TestLoadConfigFeatures(t *testing.T) {
+	setValidEnv(t)
+	t.Setenv("TWO_FACTOR_ROUTES", "")
+	t.Setenv("FEATURES_DISABLED", "webhooks, two_factor")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.Features.Enabled(handlers.FeatureWebhooks) || cfg.Features.Enabled(handlers.FeatureTwoFactor) || !cfg.Features.Enabled(handlers.FeaturePayees) {
+		t.Errorf("features: webhooks %t, two_factor %t, payees %t", cfg.Features.Enabled(handlers.FeatureWebhooks),
+			cfg.Features.Enabled(handlers.FeatureTwoFactor), cfg.Features.Enabled(handlers.FeaturePayees))
+	}
+
+	t.Setenv("FEATURES_DISABLED", "teleport")
+	if _, err := LoadConfig(); err == nil || !strings.Contains(err.Error(), "FEATURES_DISABLED:") {
+		t.Errorf("unknown feature: %v, want an error naming FEATURES_DISABLED", err)
+	}
+
+	// 2FA routes can't be required with 2FA switched off.
+	t.Setenv("FEATURES_DISABLED", "two_factor")
+	t.Setenv("TWO_FACTOR_ROUTES", "/api/transfer")
+	if _, err := LoadConfig(); err == nil || !strings.Contains(err.Error(), "TWO_FACTOR_ROUTES:") {
+		t.Errorf("2FA routes without 2FA: %v, want an error naming TWO_FACTOR_ROUTES", err)
+	}
+}
+
+func TestLoadConfigSecurity(t *testing.T) {
+	setValidEnv(t)
+	t.Setenv("SECURITY_HEADERS", "")
+	t.Setenv("HSTS_MAX_AGE", "")
+	t.Setenv("REQUIRE_HTTPS", "")
+	t.Setenv("TRUSTED_PROXIES", "")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if !cfg.Security.Headers || cfg.Security.HSTSMaxAge != 365*24*time.Hour || cfg.Security.RequireHTTPS || len(cfg.TrustedProxies) != 0 {
+		t.Errorf("defaults: %+v, trusted proxies %v", cfg.Security, cfg.TrustedProxies)
+	}
+
+	t.Setenv("REQUIRE_HTTPS", "true")
+	t.Setenv("HSTS_MAX_AGE", "1h")
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.1, 10.0.0.2")
+	cfg, err = LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if !cfg.Security.RequireHTTPS || cfg.Security.HSTSMaxAge != time.Hour || len(cfg.TrustedProxies) != 2 || cfg.TrustedProxies[1] != "10.0.0.2" {
+		t.Errorf("parsed: %+v, trusted proxies %v", cfg.Security, cfg.TrustedProxies)
+	}
+}
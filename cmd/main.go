@@ -4,12 +4,18 @@ import (
 	"bank-api/internal/handlers"
 	"bank-api/internal/services"
 	"bank-api/pkg/database"
+	"bank-api/pkg/utils"
+	"context"
 	"log"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/gofiber/contrib/swagger"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/gofiber/fiber/v2/middleware/etag"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/joho/godotenv"
@@ -20,64 +26,325 @@ func main() {
 		log.Println("Не найден .env файл, используем переменные окружения")
 	}
 
-	dsn := os.Getenv("DATABASE_URL")
-	if dsn == "" {
-		log.Fatal("DATABASE_URL не установлен")
+	cfg, err := LoadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Маскируем номера счетов в логах (LOG_MASKING=false отключает).
+	if cfg.LogMasking {
+		log.SetOutput(utils.MaskingWriter{W: os.Stderr})
 	}
-	db, err := database.InitDB(dsn)
+
+	// Схема обновляется версионными миграциями; AUTO_MIGRATE=true дополнительно включает gorm AutoMigrate (только для разработки).
+	// Запросы к БД в обработчиках отменяются по дедлайну REQUEST_TIMEOUT через контекст запроса; миграции им не ограничены.
+	db, err := database.InitDB(cfg.DatabaseURL, database.Config{
+		AutoMigrate:        cfg.AutoMigrate,
+		SlowQueryThreshold: cfg.SlowQueryThreshold,
+		EncryptionKey:      cfg.EncryptionKey,
+	})
 	if err != nil {
 		log.Fatalf("Ошибка инициализации БД: %v", err)
 	}
 
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		log.Fatal("JWT_SECRET не установлен")
+	clock := utils.RealClock
+	cfg.Transaction.Clock = clock
+	cfg.Auth.Clock = clock
+
+	rateBase := cfg.FXBase
+	if rateBase == "" {
+		rateBase = services.DefaultCurrency
+	}
+	rateProvider := services.NewCachedRateProvider(services.NewStaticRateProvider(rateBase, cfg.FXRates), cfg.FXTTL, clock)
+
+	accountConfig := services.AccountConfig{Clock: clock, AccountNumbers: cfg.Transaction.AccountNumbers, Dormancy: cfg.Dormancy, SubBalances: cfg.Transaction.SubBalances, ReopenWindow: cfg.AccountReopenWindow}
+	if cfg.FXBase != "" {
+		accountConfig.BaseCurrency = cfg.FXBase
+		accountConfig.Rates = rateProvider
 	}
 
 	var (
-		transactionService = services.NewTransactionService(db, jwtSecret)
-		authService        = services.NewAuthService(db, jwtSecret)
-		accountService     = services.NewAccountService(db, jwtSecret)
+		transactionService = services.NewTransactionService(db, cfg.BalanceSecret, cfg.Transaction)
+		authService        = services.NewAuthService(db, cfg.JWTSecret, cfg.Auth)
+		accountService     = services.NewAccountService(db, cfg.BalanceSecret, accountConfig)
+		auditService       = services.NewAuditService(db, cfg.BalanceSecret, clock)
+		payeeService       = services.NewPayeeService(db, cfg.MaxPayees, clock)
+		receiptService     = services.NewReceiptService(services.NewTransactionRepository(db), cfg.BalanceSecret, cfg.Transaction.CurrencyDecimals)
+		rateService        = services.NewRateService(rateProvider, cfg.FXSpread)
+		exportService      = services.NewExportService(db, clock)
+		statementService   = services.NewStatementService(db, nil, clock)
+		webhookService     = services.NewWebhookService(db, nil, clock)
 	)
 
-	h := handlers.NewHandler(transactionService, authService, accountService)
+	h := handlers.NewHandler(transactionService, authService, accountService, auditService, payeeService, receiptService, rateService, exportService, statementService, webhookService, clock)
 
-	app := fiber.New(fiber.Config{
-		ErrorHandler: h.ErrorHandler,
-	})
+	// Фоновые задачи. Каждая стартует со случайной задержкой в пределах JOBS_WARMUP, чтобы после
+	// перезапуска они не нагружали БД все разом; одновременно выполняется не больше JOBS_MAX_CONCURRENT.
+	jobs := utils.NewScheduler(cfg.JobsMaxConcurrent, cfg.JobsWarmup)
+
+	// Периодическая проверка целостности балансов (BALANCE_AUDIT_INTERVAL=0 отключает).
+	if interval := cfg.BalanceAuditInterval; interval > 0 {
+		jobs.Every(interval, func() {
+			report, err := auditService.AuditBalances(nil)
+			if err != nil {
+				log.Printf("Ошибка аудита балансов: %v", err)
+				return
+			}
+			if len(report.FailedAccounts) > 0 {
+				log.Printf("Аудит балансов: %d из %d счетов не прошли проверку", len(report.FailedAccounts), report.Scanned)
+			}
+		})
+	}
+
+	// Начисление процентов по сберегательным счетам (INTEREST_INTERVAL=0 отключает).
+	// Проценты начисляются за полные дни, поэтому интервал может быть любым, например 1h.
+	if interval := cfg.InterestInterval; interval > 0 {
+		jobs.Every(interval, func() {
+			credited, err := transactionService.AccrueInterest()
+			if err != nil {
+				log.Printf("Ошибка начисления процентов: %v", err)
+			}
+			if credited > 0 {
+				log.Printf("Начислены проценты на счетов: %d", credited)
+			}
+		})
+	}
+
+	// Поиск счетов без активности дольше DORMANCY_AFTER (DORMANCY_CHECK_INTERVAL=0 отключает).
+	if interval := cfg.DormancyCheckInterval; interval > 0 {
+		jobs.Every(interval, func() {
+			flagged, err := accountService.FlagDormant()
+			if err != nil {
+				log.Printf("Ошибка поиска неактивных счетов: %v", err)
+			}
+			if flagged > 0 {
+				log.Printf("Счетов переведено в неактивные: %d", flagged)
+			}
+		})
+	}
+
+	// Рассылка выписок за прошлый месяц подписавшимся пользователям (STATEMENT_INTERVAL=0 отключает).
+	// Пока выписки только пишутся в лог; каждому пользователю выписка за месяц уходит один раз.
+	if interval := cfg.StatementInterval; interval > 0 {
+		jobs.Every(interval, func() {
+			sent, err := statementService.SendDue()
+			if err != nil {
+				log.Printf("Ошибка рассылки выписок: %v", err)
+			}
+			if sent > 0 {
+				log.Printf("Отправлено выписок: %d", sent)
+			}
+		})
+	}
+
+	// Истёкшие запросы денег получают статус expired (MONEY_REQUEST_EXPIRY_INTERVAL=0 отключает).
+	if interval := cfg.MoneyRequestExpiryInterval; interval > 0 && cfg.Transaction.MoneyRequestTTL > 0 {
+		jobs.Every(interval, func() {
+			expired, err := transactionService.ExpireMoneyRequests()
+			if err != nil {
+				log.Printf("Ошибка обработки истёкших запросов денег: %v", err)
+			}
+			if expired > 0 {
+				log.Printf("Истекло запросов денег: %d", expired)
+			}
+		})
+	}
+
+	jobs.Start()
+
+	app := fiber.New(newFiberConfig(cfg))
 
 	// Настройка CORS
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     "http://localhost:3000", // Укажите конкретный источник
-		AllowMethods:     "GET,POST,PUT,DELETE,OPTIONS",
+		AllowMethods:     "GET,POST,PUT,PATCH,DELETE,OPTIONS",
 		AllowHeaders:     "Origin, Content-Type, Accept, Authorization",
+		ExposeHeaders:    "X-Next-Cursor",
 		AllowCredentials: true, // Если вам нужно передавать куки
 	}))
 
-	cfg := swagger.Config{
+	swaggerConfig := swagger.Config{
 		BasePath: "/",
 		Path:     "swagger",
 		Title:    "BankX API Docs",
 	}
 
 	app.Use(recover.New())
-	app.Use(logger.New())
-	app.Use(swagger.New(cfg))
+	// Заголовки безопасности (HSTS, nosniff и т.п.) на всех ответах; REQUIRE_HTTPS=true отклоняет HTTP.
+	app.Use(handlers.SecureHeaders(cfg.Security))
+	loggerConfig := logger.ConfigDefault
+	if cfg.LogMasking {
+		loggerConfig.Output = utils.MaskingWriter{W: os.Stdout}
+	}
+	app.Use(logger.New(loggerConfig))
+	app.Use(swagger.New(swaggerConfig))
+	app.Use(handlers.Timeout(cfg.RequestTimeout))
+
+	// Сжатие ответов (COMPRESSION=false отключает) и ETag для GET-запросов.
+	// ETag считается по несжатому телу, поэтому подключается после сжатия.
+	// Потоковую выгрузку данных ETag не трогает, иначе она целиком буферизуется.
+	if cfg.Compression {
+		app.Use(handlers.Compress(cfg.CompressionMinSize))
+	}
+	app.Use(etag.New(etag.Config{
+		Next: func(c *fiber.Ctx) bool {
+			return c.Method() != fiber.MethodGet || c.Path() == "/api/me/export" ||
+				strings.HasPrefix(c.Path(), "/api/accounts/") && strings.HasSuffix(c.Path(), "/statement")
+		},
+	}))
+
+	// FEATURES_DISABLED выключает функции без пересборки: их маршруты отвечают 404.
+	// GET /api/features возвращает список включённых, чтобы клиент мог скрыть остальные.
+	feature := cfg.Features.Require
 
 	api := app.Group("/api")
+	api.Get("/features", cfg.Features.List)
 	api.Post("/register", h.Register)
 	api.Post("/login", h.Login)
+	api.Post("/receipts/verify", h.VerifyReceipt)
+	// Проверка токенов для других сервисов: по общему секрету INTROSPECTION_TOKEN или с токеном администратора.
+	api.Post("/token/introspect", h.IntrospectionAuth(cfg.IntrospectionToken), h.IntrospectToken)
 
-	protected := api.Group("/", h.AuthMiddleware)
+	// TWO_FACTOR_ROUTES - префиксы путей, требующие токена, подтверждённого через /api/2fa/elevate.
+	// Время операций всегда ставит сервер: тела запросов с created_at отклоняются.
+	protected := api.Group("/", h.AuthMiddleware, handlers.RequireTwoFactor(cfg.TwoFactorRoutes), handlers.RejectFields("created_at"))
+	// Ограничение одновременных запросов, двигающих деньги: лишние получают 429, а не ждут блокировок счетов.
+	writeLimit := handlers.ConcurrencyLimit(cfg.MaxWritesPerUser, cfg.MaxWritesPerIP)
+	protected.Post("/2fa/setup", feature(handlers.FeatureTwoFactor), h.SetupTwoFactor)
+	protected.Post("/2fa/elevate", feature(handlers.FeatureTwoFactor), h.ElevateToken)
+	protected.Get("/me/networth", h.GetNetWorth)
+	protected.Get("/me/velocity", h.GetVelocity)
+	protected.Get("/me/token-info", h.GetTokenInfo)
+	protected.Get("/me/security/login-history", h.GetLoginHistory)
+	protected.Get("/me/export", feature(handlers.FeatureExport), h.ExportData)
+	protected.Get("/accounts/:id/statement", h.GetAccountStatement)
+	protected.Get("/accounts/:id/proof", h.GetBalanceProof)
+	protected.Get("/accounts/:id/balance", h.GetBalanceAt)
+	protected.Get("/accounts/:id/sub-balances", h.GetSubBalances)
+	protected.Post("/accounts/:id/reactivate", writeLimit, h.ReactivateAccount)
+	protected.Post("/accounts/:id/close", writeLimit, h.CloseAccount)
+	protected.Post("/accounts/:id/reopen", writeLimit, h.ReopenAccount)
+	protected.Get("/accounts/:id/beneficiaries", feature(handlers.FeatureBeneficiaries), h.GetBeneficiaries)
+	protected.Post("/accounts/:id/beneficiaries", feature(handlers.FeatureBeneficiaries), h.AddBeneficiary)
+	protected.Get("/accounts/:id/transfer-whitelist", h.GetTransferWhitelist)
+	protected.Put("/accounts/:id/transfer-whitelist", h.SetTransferWhitelist)
+	protected.Get("/accounts/:id/cosigners", h.GetCosigners)
+	protected.Put("/accounts/:id/cosigners", h.SetCosigners)
+	protected.Get("/me/round-up", feature(handlers.FeatureRoundUp), h.GetRoundUp)
+	protected.Put("/me/round-up", feature(handlers.FeatureRoundUp), h.SetRoundUp)
+	protected.Get("/me/statements", feature(handlers.FeatureStatements), h.GetStatementSettings)
+	protected.Put("/me/statements", feature(handlers.FeatureStatements), h.SetStatementSettings)
+	protected.Get("/me/limits", h.GetLimits)
+	protected.Put("/me/limits", h.SetLimits)
+	protected.Get("/rates", h.GetRate)
+	protected.Get("/currencies", h.GetCurrencies)
 	protected.Get("/accounts", h.GetAccounts)
-	protected.Post("/transfer", h.Transfer)
-	protected.Post("/deposit/:id", h.Deposit)
-	protected.Post("/withdraw/:id", h.Withdraw)
+	protected.Post("/accounts", h.CreateAccount)
+	protected.Get("/accounts/:id", h.GetAccount)
+	protected.Patch("/accounts/:id", h.UpdateAccount)
+	protected.Get("/accounts/:id/entries", h.GetLedgerEntries)
+	protected.Get("/accounts/:id/with/:counterparty", h.GetCounterpartyTransactions)
+	protected.Post("/transfer", writeLimit, h.Transfer)
+	protected.Get("/transfer/fee", h.QuoteTransfer)
+	protected.Post("/transfer/validate", h.ValidateTransfer)
+	protected.Post("/transfers/batch", feature(handlers.FeatureBatchTransfers), writeLimit, h.BatchTransfer)
+	protected.Get("/transfers/batch/:id", feature(handlers.FeatureBatchTransfers), h.GetBatch)
+	protected.Get("/webhooks", feature(handlers.FeatureWebhooks), h.GetWebhooks)
+	protected.Post("/webhooks", feature(handlers.FeatureWebhooks), h.CreateWebhook)
+	protected.Get("/webhooks/:id/deliveries", feature(handlers.FeatureWebhooks), h.GetWebhookDeliveries)
+	protected.Post("/webhooks/:id/redeliver/:delivery_id", feature(handlers.FeatureWebhooks), h.RedeliverWebhook)
+	protected.Get("/payees", feature(handlers.FeaturePayees), h.GetPayees)
+	protected.Post("/payees", feature(handlers.FeaturePayees), h.CreatePayee)
+	protected.Get("/payees/:id", feature(handlers.FeaturePayees), h.GetPayee)
+	protected.Delete("/payees/:id", feature(handlers.FeaturePayees), h.DeletePayee)
+	protected.Post("/deposit/split", writeLimit, h.SplitDeposit) // До /deposit/:id, иначе "split" примут за ID
+	protected.Post("/deposit/:id", writeLimit, h.Deposit)
+	protected.Get("/deposit/:id/preview", h.PreviewDeposit)
+	protected.Post("/withdraw/:id", writeLimit, h.Withdraw)
+	protected.Get("/accounts/:id/holds", h.GetHolds)
+	protected.Post("/accounts/:id/holds", writeLimit, h.PlaceHold)
+	protected.Post("/holds/:id/capture", writeLimit, h.CaptureHold)
+	protected.Post("/holds/:id/release", writeLimit, h.ReleaseHold)
+	protected.Get("/requests", h.GetMoneyRequests)
+	protected.Post("/requests", writeLimit, h.RequestMoney)
+	protected.Post("/requests/:id/pay", writeLimit, h.PayMoneyRequest)
+	protected.Post("/requests/:id/reject", writeLimit, h.RejectMoneyRequest)
+	protected.Get("/transactions", h.GetTransactions)
+	protected.Post("/transactions/:id/tags", h.UpdateTags)
+	protected.Get("/transactions/:id/receipt", h.GetReceipt)
+	protected.Post("/transactions/:id/approve", writeLimit, h.ApproveTransfer)
+	protected.Post("/transactions/:id/reject", writeLimit, h.RejectTransfer)
+	protected.Post("/transactions/:id/cancel", writeLimit, h.CancelTransfer)
+	protected.Post("/transactions/:id/accept", writeLimit, h.AcceptTransfer)
+	protected.Post("/transactions/:id/decline", writeLimit, h.DeclineTransfer)
+	protected.Post("/transactions/:id/refund", feature(handlers.FeatureRefunds), writeLimit, h.RefundTransfer)
+
+	admin := protected.Group("/admin", h.AdminMiddleware)
+	admin.Post("/audit/balances", h.AuditBalances)
+	admin.Get("/metrics", h.GetMetrics)
+	admin.Get("/users", h.SearchUsers)
+	admin.Get("/transactions", h.SearchTransactions)
+	admin.Put("/users/:id/transfers", h.SetTransfersEnabled)
+	admin.Delete("/users/:id", h.DeleteUser)
+	admin.Delete("/users/:id/purge", h.PurgeUser)
+	admin.Post("/accounts/balances", h.LookupBalances)
+	admin.Post("/accounts/:id/unfreeze", h.UnfreezeAccount)
+	admin.Post("/accounts/:id/adjust", h.AdjustBalance)
+	admin.Post("/accounts/:id/distribute", feature(handlers.FeatureBeneficiaries), h.DistributeAccount)
+	// Журнал событий для интеграций: GET /api/events?after=<seq> отдаёт события после указанного номера.
+	protected.Get("/events", h.AdminMiddleware, h.GetEvents)
+
+	listenErr := make(chan error, 1)
+	go func() {
+		listenErr <- app.Listen(":" + cfg.Port)
+	}()
+	log.Printf("Сервер запущен на порту %s", cfg.Port)
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "3000"
+	// Корректная остановка по SIGINT/SIGTERM: сервер перестаёт принимать соединения, дожидается
+	// текущих запросов и отправки уже поставленных в очередь вебхуков, но не дольше SHUTDOWN_TIMEOUT.
+	// Недоставленные вебхуки остаются в журнале со статусом pending, их можно доставить повторно.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	select {
+	case err := <-listenErr:
+		log.Fatal(err)
+	case <-ctx.Done():
+	}
+
+	log.Println("Остановка сервера...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := app.ShutdownWithContext(shutdownCtx); err != nil {
+		log.Printf("Ошибка остановки HTTP-сервера: %v", err)
+	}
+	if err := jobs.Stop(shutdownCtx); err != nil {
+		log.Printf("Фоновые задачи не завершились до остановки: %v", err)
+	}
+	if err := webhookService.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Не все вебхуки доставлены до остановки: %v", err)
+	}
+	log.Println("Сервер остановлен")
+}
+
+// newFiberConfig собирает настройки fiber из конфигурации сервера.
+func newFiberConfig(cfg *Config) fiber.Config {
+	// Сообщения об ошибках на языке из Accept-Language; поле code от языка не зависит.
+	fiberConfig := fiber.Config{
+		ErrorHandler: cfg.Messages.ErrorHandler,
+	}
+	// X-Forwarded-Proto и X-Forwarded-Host принимаются только от TRUSTED_PROXIES, иначе клиент
+	// мог бы выдать HTTP-запрос за HTTPS.
+	fiberConfig.EnableTrustedProxyCheck = true
+	fiberConfig.TrustedProxies = cfg.TrustedProxies
+	// За прокси адрес клиента берётся из X-Forwarded-For (от них же), иначе лимиты по IP
+	// делили бы адрес прокси на всех. Прокси должен перезаписывать заголовок, а не дописывать в него.
+	if len(cfg.TrustedProxies) > 0 {
+		fiberConfig.ProxyHeader = fiber.HeaderXForwardedFor
+		fiberConfig.EnableIPValidation = true
+	}
+	// ID в ответах строками: JavaScript теряет точность целых больше 2^53.
+	if cfg.StringIDs {
+		fiberConfig.JSONEncoder = handlers.StringIDs
 	}
-	log.Printf("Сервер запущен на порту %s", port)
-	log.Fatal(app.Listen(":" + port))
+	return fiberConfig
 }
@@ -0,0 +1,61 @@
+// Path: cmd/main_test.go
+package main
+
+import (
+	"bank-api/internal/handlers"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestPerIPLimitBehindTrustedProxy(t *testing.T) {
+	setValidEnv(t)
+	release := make(chan struct{})
+	defer close(release)
+
+	send := func(app *fiber.App, client string) int {
+		t.Helper()
+		req := httptest.NewRequest(fiber.MethodPost, "/api/transfer", nil)
+		req.Header.Set(fiber.HeaderXForwardedFor, client)
+		resp, err := app.Test(req, 50)
+		if err != nil {
+			return 0 // Still held by the handler
+		}
+		return resp.StatusCode
+	}
+	newApp := func(trustedProxies string) *fiber.App {
+		t.Setenv("TRUSTED_PROXIES", trustedProxies)
+		cfg, err := LoadConfig()
+		if err != nil {
+			t.Fatalf("LoadConfig: %v", err)
+		}
+		app := fiber.New(newFiberConfig(cfg))
+		app.Post("/api/transfer", handlers.ConcurrencyLimit(0, 1), func(c *fiber.Ctx) error {
+			<-release
+			return c.SendString("done")
+		})
+		return app
+	}
+
+	// Test requests come from 0.0.0.0: as a trusted proxy, its X-Forwarded-For tells clients apart.
+	app := newApp("0.0.0.0")
+	if code := send(app, "203.0.113.1"); code != 0 {
+		t.Fatalf("first request from 203.0.113.1 = %d, want it held", code)
+	}
+	if code := send(app, "203.0.113.2"); code != 0 {
+		t.Errorf("request from another client behind the proxy = %d, want it admitted", code)
+	}
+	if code := send(app, "203.0.113.1"); code != fiber.StatusTooManyRequests {
+		t.Errorf("second request from 203.0.113.1 = %d, want 429", code)
+	}
+
+	// From an untrusted address the header is ignored and every request counts against it.
+	app = newApp("10.0.0.1")
+	if code := send(app, "203.0.113.1"); code != 0 {
+		t.Fatalf("first request = %d, want it held", code)
+	}
+	if code := send(app, "203.0.113.2"); code != fiber.StatusTooManyRequests {
+		t.Errorf("request with a spoofed X-Forwarded-For = %d, want 429", code)
+	}
+}
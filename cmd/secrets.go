@@ -0,0 +1,82 @@
+// Path: cmd/secrets.go
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// minJWTSecretLength - минимальная длина JWT_SECRET: HS256 нужен ключ не короче 256 бит.
+const minJWTSecretLength = 32
+
+// SecretProvider достаёт секреты из внешнего хранилища (менеджер секретов, смонтированные файлы).
+type SecretProvider interface {
+	// Secret возвращает значение секрета; ok=false, если в хранилище его нет.
+	Secret(name string) (value string, ok bool, err error)
+}
+
+// dirSecretProvider читает секреты из файлов <dir>/<ИМЯ>, как их монтируют Docker и Kubernetes.
+type dirSecretProvider struct {
+	dir string
+}
+
+func (p dirSecretProvider) Secret(name string) (string, bool, error) {
+	data, err := os.ReadFile(filepath.Join(p.dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return strings.TrimRight(string(data), "\r\n"), true, nil
+}
+
+// secretProviderFromEnv выбирает хранилище секретов: SECRETS_DIR - каталог с файлами секретов.
+// Без него секреты читаются только из окружения.
+func secretProviderFromEnv() SecretProvider {
+	if dir := os.Getenv("SECRETS_DIR"); dir != "" {
+		return dirSecretProvider{dir: dir}
+	}
+	return nil
+}
+
+// secret читает секрет, по порядку: из файла по пути в <ИМЯ>_FILE, из хранилища секретов,
+// из переменной <ИМЯ>, иначе def. Перевод строки в конце файла отбрасывается.
+// Непустой секрет короче minLen считается ошибкой.
+func (r *envReader) secret(name, def string, minLen int) string {
+	v, err := r.lookupSecret(name)
+	if err != nil {
+		r.fail(name, err.Error())
+		return def
+	}
+	if v == "" {
+		v = def
+	}
+	if v != "" && len(v) < minLen {
+		r.fail(name, fmt.Sprintf("секрет короче %d символов", minLen))
+	}
+	return v
+}
+
+func (r *envReader) lookupSecret(name string) (string, error) {
+	if path := os.Getenv(name + "_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("не удалось прочитать %s_FILE: %w", name, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+	if r.secrets != nil {
+		v, ok, err := r.secrets.Secret(name)
+		if err != nil {
+			return "", fmt.Errorf("хранилище секретов: %w", err)
+		}
+		if ok {
+			return v, nil
+		}
+	}
+	return os.Getenv(name), nil
+}
@@ -0,0 +1,111 @@
+// Path: cmd/secrets_test.go
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testJWTSecret = "0123456789abcdef0123456789abcdef"
+
+func TestSecretFromEnv(t *testing.T) {
+	t.Setenv("JWT_SECRET", testJWTSecret)
+	r := &envReader{}
+	if got := r.secret("JWT_SECRET", "", minJWTSecretLength); got != testJWTSecret || len(r.errs) > 0 {
+		t.Errorf("secret = %q, errors %v", got, r.errs)
+	}
+}
+
+func TestSecretFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwt")
+	if err := os.WriteFile(path, []byte(testJWTSecret+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("JWT_SECRET_FILE", path)
+	t.Setenv("JWT_SECRET", "ignored-because-the-file-comes-first")
+
+	r := &envReader{}
+	if got := r.secret("JWT_SECRET", "", minJWTSecretLength); got != testJWTSecret || len(r.errs) > 0 {
+		t.Errorf("secret = %q, errors %v", got, r.errs)
+	}
+}
+
+func TestSecretFromSecretsDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "JWT_SECRET"), []byte(testJWTSecret), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("JWT_SECRET", "ignored-because-the-store-comes-first")
+
+	r := &envReader{secrets: dirSecretProvider{dir: dir}}
+	if got := r.secret("JWT_SECRET", "", minJWTSecretLength); got != testJWTSecret || len(r.errs) > 0 {
+		t.Errorf("secret = %q, errors %v", got, r.errs)
+	}
+}
+
+func TestSecretRejectsShortValues(t *testing.T) {
+	t.Setenv("JWT_SECRET", "short")
+	r := &envReader{}
+	r.secret("JWT_SECRET", "", minJWTSecretLength)
+	if len(r.errs) != 1 || !strings.HasPrefix(r.errs[0], "JWT_SECRET:") {
+		t.Errorf("errors = %v, want one for JWT_SECRET", r.errs)
+	}
+}
+
+func TestLoadConfigRejectsShortPreviousJWTSecret(t *testing.T) {
+	t.Setenv("SECRETS_DIR", "")
+	t.Setenv("DATABASE_URL", "postgres://localhost/bank")
+	t.Setenv("JWT_SECRET", testJWTSecret)
+	t.Setenv("JWT_SECRET_PREVIOUS", "short")
+
+	_, err := LoadConfig()
+	if err == nil || !strings.Contains(err.Error(), "JWT_SECRET_PREVIOUS") {
+		t.Errorf("LoadConfig() = %v, want an error naming JWT_SECRET_PREVIOUS", err)
+	}
+
+	t.Setenv("JWT_SECRET_PREVIOUS", strings.Repeat("p", minJWTSecretLength))
+	if cfg, err := LoadConfig(); err != nil {
+		t.Errorf("LoadConfig() with a long enough previous secret: %v", err)
+	} else if cfg.Auth.PreviousJWTSecret != strings.Repeat("p", minJWTSecretLength) {
+		t.Errorf("PreviousJWTSecret = %q", cfg.Auth.PreviousJWTSecret)
+	}
+}
+
+func TestSecretFileMissing(t *testing.T) {
+	t.Setenv("JWT_SECRET_FILE", filepath.Join(t.TempDir(), "missing"))
+	t.Setenv("JWT_SECRET", testJWTSecret)
+	r := &envReader{}
+	r.secret("JWT_SECRET", "", minJWTSecretLength)
+	if len(r.errs) != 1 || !strings.Contains(r.errs[0], "JWT_SECRET_FILE") {
+		t.Errorf("errors = %v, want one naming JWT_SECRET_FILE", r.errs)
+	}
+}
+
+func TestLoadConfigBalanceSecret(t *testing.T) {
+	t.Setenv("SECRETS_DIR", "")
+	t.Setenv("DATABASE_URL", "postgres://localhost/bank")
+	t.Setenv("JWT_SECRET", testJWTSecret)
+	t.Setenv("BALANCE_HMAC_SECRET", "")
+
+	// Без BALANCE_HMAC_SECRET хэши балансов подписываются JWT_SECRET.
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.BalanceSecret != testJWTSecret {
+		t.Errorf("BalanceSecret = %q, want JWT_SECRET", cfg.BalanceSecret)
+	}
+
+	path := filepath.Join(t.TempDir(), "hmac")
+	if err := os.WriteFile(path, []byte("balance-secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("BALANCE_HMAC_SECRET_FILE", path)
+	if cfg, err := LoadConfig(); err != nil {
+		t.Errorf("LoadConfig with BALANCE_HMAC_SECRET_FILE: %v", err)
+	} else if cfg.BalanceSecret != "balance-secret" {
+		t.Errorf("BalanceSecret = %q, want the file contents", cfg.BalanceSecret)
+	}
+}
@@ -0,0 +1,441 @@
+// Path: internal/handlers/admin.go
+package handlers
+
+import (
+	"bank-api/internal/models"
+	"bank-api/internal/services"
+	"bank-api/pkg/metrics"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AdminMiddleware allows only users with the admin role. Must run after AuthMiddleware.
+func (h *Handler) AdminMiddleware(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	if claims.Role != models.RoleAdmin {
+		return &AppError{
+			Code:    fiber.StatusForbidden,
+			Message: "Access denied",
+			Details: "Administrator role required",
+		}
+	}
+
+	return c.Next()
+}
+
+func (h *Handler) AuditBalances(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	report, err := bind(c, h.auditService).AuditBalances(&claims.UserID)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Balance audit failed",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(report)
+}
+
+// GetMetrics returns the application metrics, such as the balance integrity counters.
+func (h *Handler) GetMetrics(c *fiber.Ctx) error {
+	return c.JSON(metrics.Snapshot())
+}
+
+func (h *Handler) SearchUsers(c *fiber.Ctx) error {
+	filter := models.UserSearchFilter{Query: c.Query("q")}
+	for name, dst := range map[string]*int{"limit": &filter.Limit, "offset": &filter.Offset} {
+		if v := c.Query(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return &AppError{
+					Code:    fiber.StatusBadRequest,
+					Message: "Invalid query parameter",
+					Details: name + ": " + err.Error(),
+					Err:     err,
+				}
+			}
+			*dst = n
+		}
+	}
+
+	users, err := bind(c, h.authService).SearchUsers(filter)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to search users",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(users)
+}
+
+// SearchTransactions lists the transactions of all users. Filters: ?from= and ?to= (RFC 3339,
+// to exclusive), ?type=, ?status=, ?limit= and ?offset=.
+func (h *Handler) SearchTransactions(c *fiber.Ctx) error {
+	filter := models.AdminTransactionFilter{Type: c.Query("type"), Status: c.Query("status"), Reference: c.Query("reference")}
+	for name, dst := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if v := c.Query(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return &AppError{
+					Code:    fiber.StatusBadRequest,
+					Message: "Invalid query parameter",
+					Details: name + ": expected an RFC 3339 timestamp",
+					Err:     err,
+				}
+			}
+			*dst = t
+		}
+	}
+	for name, dst := range map[string]*int{"limit": &filter.Limit, "offset": &filter.Offset} {
+		if v := c.Query(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return &AppError{
+					Code:    fiber.StatusBadRequest,
+					Message: "Invalid query parameter",
+					Details: name + ": " + err.Error(),
+					Err:     err,
+				}
+			}
+			*dst = n
+		}
+	}
+
+	page, err := bind(c, h.transactionService).SearchTransactions(filter)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to search transactions",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(page)
+}
+
+func (h *Handler) SetTransfersEnabled(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	userID, err := paramID(c, "id")
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid user ID",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	var req models.TransfersToggleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+	if req.Enabled == nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: "enabled is required",
+		}
+	}
+
+	user, err := bind(c, h.authService).SetTransfersEnabled(claims.UserID, uint(userID), *req.Enabled)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to update user",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(user)
+}
+
+// DeleteUser soft-deletes a user whose real accounts are all closed.
+func (h *Handler) DeleteUser(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	userID, err := paramID(c, "id")
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid user ID",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	user, err := bind(c, h.authService).DeleteUser(claims.UserID, uint(userID))
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to delete user",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(user)
+}
+
+// PurgeUser erases a deleted user after the retention window, anonymizing their transactions.
+func (h *Handler) PurgeUser(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	userID, err := paramID(c, "id")
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid user ID",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	if err := bind(c, h.authService).PurgeUser(claims.UserID, uint(userID)); err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to purge user",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *Handler) UnfreezeAccount(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	accountID, err := paramID(c, "id")
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid account ID",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	account, err := bind(c, h.accountService).Unfreeze(claims.UserID, accountID)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to unfreeze account",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(account)
+}
+
+// LookupBalances returns the balances of many accounts, named by ID or number.
+func (h *Handler) LookupBalances(c *fiber.Ctx) error {
+	var req models.BalanceLookupRequest
+	if err := c.BodyParser(&req); err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	lookup, err := bind(c, h.accountService).LookupBalances(&req)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to look up balances",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(lookup)
+}
+
+func (h *Handler) AdjustBalance(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	accountID, err := paramID(c, "id")
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid account ID",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	var req models.AdjustmentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	transaction, err := bind(c, h.transactionService).AdjustBalance(accountID, &req, claims)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to adjust balance",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(transaction)
+}
+
+// DistributeAccount pays out an account to its beneficiaries and freezes it.
+func (h *Handler) DistributeAccount(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	accountID, err := paramID(c, "id")
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid account ID",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	var req models.DistributionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	transactions, err := bind(c, h.transactionService).DistributeToBeneficiaries(accountID, &req, claims)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to distribute account",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(transactions)
+}
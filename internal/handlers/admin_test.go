@@ -0,0 +1,264 @@
+// Path: internal/handlers/admin_test.go
+package handlers
+
+import (
+	"bank-api/internal/models"
+	"bank-api/internal/services"
+	"testing"
+	"time"
+)
+
+func TestMetricsAdminOnly(t *testing.T) {
+	h := &Handler{}
+	for _, tt := range []struct {
+		role string
+		want int
+	}{
+		{models.RoleUser, 403},
+		{models.RoleAdmin, 200},
+	} {
+		app := testApp(t, &models.Claims{UserID: 1, Role: tt.role})
+		app.Get("/admin/metrics", h.AdminMiddleware, h.GetMetrics)
+
+		resp, body := do(t, app, "GET", "/admin/metrics", "")
+		if resp.StatusCode != tt.want {
+			t.Fatalf("%s: GET /admin/metrics = %d %s, want %d", tt.role, resp.StatusCode, body, tt.want)
+		}
+		if tt.want != 200 {
+			continue
+		}
+
+		var snapshot map[string]int64
+		decode(t, body, &snapshot)
+		if _, ok := snapshot["balance_integrity_failures"]; !ok {
+			t.Errorf("metrics %s lack balance_integrity_failures", body)
+		}
+		if _, ok := snapshot["memstats"]; ok {
+			t.Error("metrics expose the runtime memstats")
+		}
+	}
+}
+
+type togglingUsers struct {
+	services.AuthService
+	enabled *bool
+}
+
+func (s *togglingUsers) SetTransfersEnabled(actorID, userID uint, enabled bool) (*models.User, error) {
+	s.enabled = &enabled
+	return &models.User{ID: int(userID), TransfersEnabled: enabled}, nil
+}
+
+func TestSetTransfersEnabled(t *testing.T) {
+	users := &togglingUsers{}
+	h := &Handler{authService: users}
+	app := testApp(t, &models.Claims{UserID: 1, Role: models.RoleAdmin})
+	app.Put("/admin/users/:id/transfers", h.AdminMiddleware, h.SetTransfersEnabled)
+
+	if resp, body := do(t, app, "PUT", "/admin/users/5/transfers", `{}`); resp.StatusCode != 400 || users.enabled != nil {
+		t.Errorf("missing enabled = %d %s, want 400", resp.StatusCode, body)
+	}
+	resp, body := do(t, app, "PUT", "/admin/users/5/transfers", `{"enabled": false}`)
+	if resp.StatusCode != 200 || users.enabled == nil || *users.enabled {
+		t.Errorf("disabling = %d %s", resp.StatusCode, body)
+	}
+}
+
+type deletingUsers struct {
+	services.AuthService
+	purged []uint
+}
+
+func (s *deletingUsers) DeleteUser(actorID, userID uint) (*models.User, error) {
+	deletedAt := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	return &models.User{ID: int(userID), DeletedAt: &deletedAt}, nil
+}
+
+func (s *deletingUsers) PurgeUser(actorID, userID uint) error {
+	if userID == 6 {
+		return &services.AppError{Code: 409, Message: "Retention window has not passed"}
+	}
+	s.purged = append(s.purged, userID)
+	return nil
+}
+
+func TestDeleteAndPurgeUser(t *testing.T) {
+	users := &deletingUsers{}
+	h := &Handler{authService: users}
+	app := testApp(t, &models.Claims{UserID: 1, Role: models.RoleAdmin})
+	app.Delete("/admin/users/:id", h.AdminMiddleware, h.DeleteUser)
+	app.Delete("/admin/users/:id/purge", h.AdminMiddleware, h.PurgeUser)
+
+	resp, body := do(t, app, "DELETE", "/admin/users/5", "")
+	var user models.User
+	decode(t, body, &user)
+	if resp.StatusCode != 200 || user.ID != 5 || user.DeletedAt == nil {
+		t.Errorf("delete = %d %s", resp.StatusCode, body)
+	}
+	if resp, body := do(t, app, "DELETE", "/admin/users/5/purge", ""); resp.StatusCode != 204 || len(users.purged) != 1 {
+		t.Errorf("purge = %d %s, want 204", resp.StatusCode, body)
+	}
+	if resp, body := do(t, app, "DELETE", "/admin/users/6/purge", ""); resp.StatusCode != 409 {
+		t.Errorf("early purge = %d %s, want 409", resp.StatusCode, body)
+	}
+	if resp, body := do(t, app, "DELETE", "/admin/users/x/purge", ""); resp.StatusCode != 400 {
+		t.Errorf("purge with a bad id = %d %s, want 400", resp.StatusCode, body)
+	}
+}
+
+type searchingUsers struct {
+	services.AuthService
+	filter models.UserSearchFilter
+}
+
+func (s *searchingUsers) SearchUsers(filter models.UserSearchFilter) ([]models.UserSummary, error) {
+	s.filter = filter
+	return []models.UserSummary{{ID: 2, Username: "alice", AccountCount: 1}}, nil
+}
+
+func TestSearchUsersAdminOnly(t *testing.T) {
+	users := &searchingUsers{}
+	h := &Handler{authService: users}
+	for _, tt := range []struct {
+		role string
+		want int
+	}{
+		{models.RoleUser, 403},
+		{models.RoleAdmin, 200},
+	} {
+		app := testApp(t, &models.Claims{UserID: 1, Role: tt.role})
+		app.Get("/admin/users", h.AdminMiddleware, h.SearchUsers)
+		resp, body := do(t, app, "GET", "/admin/users?q=al&limit=5&offset=10", "")
+		if resp.StatusCode != tt.want {
+			t.Errorf("%s: GET /admin/users = %d %s, want %d", tt.role, resp.StatusCode, body, tt.want)
+		}
+	}
+	if want := (models.UserSearchFilter{Query: "al", Limit: 5, Offset: 10}); users.filter != want {
+		t.Errorf("filter = %+v, want %+v", users.filter, want)
+	}
+
+	app := testApp(t, &models.Claims{UserID: 1, Role: models.RoleAdmin})
+	app.Get("/admin/users", h.AdminMiddleware, h.SearchUsers)
+	if resp, _ := do(t, app, "GET", "/admin/users?limit=many", ""); resp.StatusCode != 400 {
+		t.Errorf("non-numeric limit = %d, want 400", resp.StatusCode)
+	}
+}
+
+type searchingTransactions struct {
+	services.TransactionService
+	filter models.AdminTransactionFilter
+}
+
+func (s *searchingTransactions) SearchTransactions(filter models.AdminTransactionFilter) (*models.AdminTransactionPage, error) {
+	s.filter = filter
+	return &models.AdminTransactionPage{Transactions: []models.Transaction{}, Total: 0, Volume: []models.CurrencyVolume{}}, nil
+}
+
+func TestSearchTransactionsAdminOnly(t *testing.T) {
+	transactions := &searchingTransactions{}
+	h := &Handler{transactionService: transactions}
+	target := "/admin/transactions?from=2024-05-01T00:00:00Z&to=2024-06-01T00:00:00Z&type=transfer&status=completed&limit=20&offset=40"
+	for _, tt := range []struct {
+		role string
+		want int
+	}{
+		{models.RoleUser, 403},
+		{models.RoleAdmin, 200},
+	} {
+		app := testApp(t, &models.Claims{UserID: 1, Role: tt.role})
+		app.Get("/admin/transactions", h.AdminMiddleware, h.SearchTransactions)
+		if resp, body := do(t, app, "GET", target, ""); resp.StatusCode != tt.want {
+			t.Errorf("%s: GET /admin/transactions = %d %s, want %d", tt.role, resp.StatusCode, body, tt.want)
+		}
+	}
+	want := models.AdminTransactionFilter{
+		From:   time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		To:     time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+		Type:   "transfer",
+		Status: "completed",
+		Limit:  20,
+		Offset: 40,
+	}
+	if transactions.filter != want {
+		t.Errorf("filter = %+v, want %+v", transactions.filter, want)
+	}
+
+	app := testApp(t, &models.Claims{UserID: 1, Role: models.RoleAdmin})
+	app.Get("/admin/transactions", h.AdminMiddleware, h.SearchTransactions)
+	for _, bad := range []string{"?from=yesterday", "?limit=many"} {
+		if resp, _ := do(t, app, "GET", "/admin/transactions"+bad, ""); resp.StatusCode != 400 {
+			t.Errorf("GET /admin/transactions%s = %d, want 400", bad, resp.StatusCode)
+		}
+	}
+}
+
+type tailingEvents struct {
+	services.TransactionService
+	filter models.EventFilter
+}
+
+func (s *tailingEvents) ListEvents(filter models.EventFilter) ([]models.Event, error) {
+	s.filter = filter
+	return []models.Event{{Seq: filter.After + 1, Type: services.EventDeposited}}, nil
+}
+
+func TestGetEventsAdminOnly(t *testing.T) {
+	events := &tailingEvents{}
+	h := &Handler{transactionService: events}
+	for _, tt := range []struct {
+		role string
+		want int
+	}{
+		{models.RoleUser, 403},
+		{models.RoleAdmin, 200},
+	} {
+		app := testApp(t, &models.Claims{UserID: 1, Role: tt.role})
+		app.Get("/events", h.AdminMiddleware, h.GetEvents)
+		if resp, body := do(t, app, "GET", "/events?after=41&limit=10", ""); resp.StatusCode != tt.want {
+			t.Errorf("%s: GET /events = %d %s, want %d", tt.role, resp.StatusCode, body, tt.want)
+		}
+	}
+	if want := (models.EventFilter{After: 41, Limit: 10}); events.filter != want {
+		t.Errorf("filter = %+v, want %+v", events.filter, want)
+	}
+
+	app := testApp(t, &models.Claims{UserID: 1, Role: models.RoleAdmin})
+	app.Get("/events", h.AdminMiddleware, h.GetEvents)
+	for _, bad := range []string{"?after=latest", "?limit=many"} {
+		if resp, _ := do(t, app, "GET", "/events"+bad, ""); resp.StatusCode != 400 {
+			t.Errorf("GET /events%s = %d, want 400", bad, resp.StatusCode)
+		}
+	}
+}
+
+type lookingUpBalances struct {
+	services.AccountService
+	req models.BalanceLookupRequest
+}
+
+func (s *lookingUpBalances) LookupBalances(req *models.BalanceLookupRequest) (*models.BalanceLookup, error) {
+	s.req = *req
+	return &models.BalanceLookup{Balances: []models.AccountBalanceEntry{}, NotFound: []string{}}, nil
+}
+
+func TestLookupBalancesAdminOnly(t *testing.T) {
+	accounts := &lookingUpBalances{}
+	h := &Handler{accountService: accounts}
+	body := `{"account_ids": [1, 2], "account_numbers": ["000000034"]}`
+	for _, tt := range []struct {
+		role string
+		want int
+	}{
+		{models.RoleUser, 403},
+		{models.RoleAdmin, 200},
+	} {
+		app := testApp(t, &models.Claims{UserID: 1, Role: tt.role})
+		app.Post("/admin/accounts/balances", h.AdminMiddleware, h.LookupBalances)
+		if resp, got := do(t, app, "POST", "/admin/accounts/balances", body); resp.StatusCode != tt.want {
+			t.Errorf("%s: POST /admin/accounts/balances = %d %s, want %d", tt.role, resp.StatusCode, got, tt.want)
+		}
+	}
+	if len(accounts.req.AccountIDs) != 2 || len(accounts.req.AccountNumbers) != 1 || accounts.req.AccountNumbers[0] != "000000034" {
+		t.Errorf("request = %+v", accounts.req)
+	}
+}
@@ -0,0 +1,88 @@
+// Path: internal/handlers/auth_test.go
+package handlers
+
+import (
+	"bank-api/internal/models"
+	"bank-api/internal/services"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// staleTokens validates every token as one in its post-expiry grace period.
+type staleTokens struct {
+	services.AuthService
+}
+
+func (staleTokens) ValidateToken(token string) (*models.Claims, error) {
+	return &models.Claims{UserID: 1, Stale: true}, nil
+}
+
+func TestStaleTokenCanReadButNotWrite(t *testing.T) {
+	h := &Handler{authService: staleTokens{}}
+	app := testApp(t, nil)
+	app.Use(h.AuthMiddleware)
+	ok := func(c *fiber.Ctx) error { return c.SendString("ok") }
+	app.Get("/api/accounts", ok)
+	app.Post("/api/transfer", ok)
+
+	auth := []string{fiber.HeaderAuthorization, "Bearer stale"}
+	if resp, body := do(t, app, fiber.MethodGet, "/api/accounts", "", auth...); resp.StatusCode != fiber.StatusOK {
+		t.Errorf("GET with a stale token = %d %s, want 200", resp.StatusCode, body)
+	}
+	if resp, body := do(t, app, fiber.MethodPost, "/api/transfer", `{}`, auth...); resp.StatusCode != fiber.StatusUnauthorized {
+		t.Errorf("POST with a stale token = %d %s, want 401", resp.StatusCode, body)
+	}
+}
+
+func TestTokenSignedWithAnotherKeyHasItsOwnCode(t *testing.T) {
+	h := &Handler{authService: services.NewAuthService(nil, testJWTSecret, services.AuthConfig{})}
+	app := testApp(t, nil)
+	app.Use(h.AuthMiddleware)
+	app.Get("/api/accounts", func(c *fiber.Ctx) error { return c.SendString("ok") })
+
+	claims := func(expires time.Time) *models.Claims {
+		return &models.Claims{UserID: 1, RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(expires)}}
+	}
+	for _, tt := range []struct{ name, token, code string }{
+		{"rotated secret", signedToken(t, claims(time.Now().Add(time.Hour)), "the-secret-before-the-rotation-0123"), "token_signed_with_a_different_key"},
+		{"malformed", "not.a.token", "invalid_token"},
+		{"expired", signedToken(t, claims(time.Now().Add(-time.Hour)), testJWTSecret), "invalid_token"},
+	} {
+		resp, body := do(t, app, fiber.MethodGet, "/api/accounts", "", fiber.HeaderAuthorization, "Bearer "+tt.token)
+		var got struct {
+			Code string `json:"code"`
+		}
+		decode(t, body, &got)
+		if resp.StatusCode != fiber.StatusUnauthorized || got.Code != tt.code {
+			t.Errorf("%s: %d %s, want 401 with code %s", tt.name, resp.StatusCode, body, tt.code)
+		}
+	}
+}
+
+func TestGetTokenInfoMatchesTheToken(t *testing.T) {
+	issued := time.Now().Add(-time.Hour).Truncate(time.Second)
+	expires := issued.Add(2 * time.Hour)
+	h := &Handler{authService: services.NewAuthService(nil, testJWTSecret, services.AuthConfig{})}
+	app := testApp(t, nil)
+	app.Get("/api/me/token-info", h.AuthMiddleware, h.GetTokenInfo)
+	token := signedToken(t, &models.Claims{UserID: 1, RegisteredClaims: jwt.RegisteredClaims{
+		IssuedAt:  jwt.NewNumericDate(issued),
+		ExpiresAt: jwt.NewNumericDate(expires),
+	}}, testJWTSecret)
+
+	resp, body := do(t, app, fiber.MethodGet, "/api/me/token-info", "", fiber.HeaderAuthorization, "Bearer "+token)
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("token info = %d %s", resp.StatusCode, body)
+	}
+	var info models.TokenInfo
+	decode(t, body, &info)
+	if info.IssuedAt != issued.Unix() || info.ExpiresAt != expires.Unix() || info.Stale {
+		t.Errorf("token info = %+v, want iat %d and exp %d", info, issued.Unix(), expires.Unix())
+	}
+	if left := info.ExpiresAt - info.ServerTime.Unix(); info.ExpiresIn < left-1 || info.ExpiresIn > left {
+		t.Errorf("expires_in = %d, want the %d seconds between the server time and exp", info.ExpiresIn, left)
+	}
+}
@@ -0,0 +1,96 @@
+// Path: internal/handlers/cosigners.go
+package handlers
+
+import (
+	"bank-api/internal/models"
+	"bank-api/internal/services"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetCosigners returns the users who may approve the held transfers of an account of the caller.
+func (h *Handler) GetCosigners(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	accountID, err := paramID(c, "id")
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid account ID",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	cosigners, err := bind(c, h.accountService).GetCosigners(claims.UserID, accountID)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve co-signers",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(cosigners)
+}
+
+// SetCosigners replaces the co-signers of an account of the caller.
+func (h *Handler) SetCosigners(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	accountID, err := paramID(c, "id")
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid account ID",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	var req models.Cosigners
+	if err := c.BodyParser(&req); err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	cosigners, err := bind(c, h.accountService).SetCosigners(claims.UserID, accountID, &req)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to update co-signers",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(cosigners)
+}
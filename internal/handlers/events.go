@@ -0,0 +1,56 @@
+// Path: internal/handlers/events.go
+package handlers
+
+import (
+	"bank-api/internal/models"
+	"bank-api/internal/services"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetEvents tails the event log: the events after the sequence number in ?after=, oldest first.
+func (h *Handler) GetEvents(c *fiber.Ctx) error {
+	var filter models.EventFilter
+	if v := c.Query("after"); v != "" {
+		after, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return &AppError{
+				Code:    fiber.StatusBadRequest,
+				Message: "Invalid query parameter",
+				Details: "after: " + err.Error(),
+				Err:     err,
+			}
+		}
+		filter.After = after
+	}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return &AppError{
+				Code:    fiber.StatusBadRequest,
+				Message: "Invalid query parameter",
+				Details: "limit: " + err.Error(),
+				Err:     err,
+			}
+		}
+		filter.Limit = limit
+	}
+
+	events, err := bind(c, h.transactionService).ListEvents(filter)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve events",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(events)
+}
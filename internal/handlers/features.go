@@ -0,0 +1,87 @@
+// Path: internal/handlers/features.go
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Features that operators can switch off. Everything is on unless disabled.
+const (
+	FeatureTwoFactor      = "two_factor"
+	FeatureBatchTransfers = "batch_transfers"
+	FeatureWebhooks       = "webhooks"
+	FeaturePayees         = "payees"
+	FeatureRoundUp        = "round_up"
+	FeatureStatements     = "statements"
+	FeatureExport         = "export"
+	FeatureRefunds        = "refunds"
+	FeatureBeneficiaries  = "beneficiaries"
+)
+
+var allFeatures = []string{
+	FeatureTwoFactor, FeatureBatchTransfers, FeatureWebhooks, FeaturePayees, FeatureRoundUp,
+	FeatureStatements, FeatureExport, FeatureRefunds, FeatureBeneficiaries,
+}
+
+// FeatureFlags records which features are enabled.
+type FeatureFlags struct {
+	disabled map[string]bool
+}
+
+// NewFeatureFlags enables every feature but the given ones. Unknown names are an error, so a typo
+// doesn't silently leave a feature on.
+func NewFeatureFlags(disabled []string) (*FeatureFlags, error) {
+	f := &FeatureFlags{disabled: make(map[string]bool)}
+	for _, name := range disabled {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !isFeature(name) {
+			return nil, fmt.Errorf("unknown feature %q, expected one of: %s", name, strings.Join(allFeatures, ", "))
+		}
+		f.disabled[name] = true
+	}
+	return f, nil
+}
+
+// Enabled reports whether a feature is on.
+func (f *FeatureFlags) Enabled(name string) bool {
+	return !f.disabled[name]
+}
+
+// Require answers 404 on the routes of a disabled feature, as if they didn't exist.
+func (f *FeatureFlags) Require(name string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !f.Enabled(name) {
+			return &AppError{
+				Code:    fiber.StatusNotFound,
+				Message: "Feature disabled",
+				Details: fmt.Sprintf("The %s feature is disabled on this server", name),
+			}
+		}
+		return c.Next()
+	}
+}
+
+// List responds with the enabled features, sorted.
+func (f *FeatureFlags) List(c *fiber.Ctx) error {
+	enabled := []string{}
+	for _, name := range allFeatures {
+		if f.Enabled(name) {
+			enabled = append(enabled, name)
+		}
+	}
+	sort.Strings(enabled)
+	return c.JSON(fiber.Map{"features": enabled})
+}
+
+func isFeature(name string) bool {
+	for _, feature := range allFeatures {
+		if feature == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+// Path: internal/handlers/features_test.go
+package handlers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestDisabledFeatureRoutesAreNotFound(t *testing.T) {
+	features, err := NewFeatureFlags([]string{" Webhooks ", FeatureRefunds})
+	if err != nil {
+		t.Fatalf("NewFeatureFlags: %v", err)
+	}
+	app := testApp(t, nil)
+	ok := func(c *fiber.Ctx) error { return c.SendString("ok") }
+	app.Get("/api/webhooks", features.Require(FeatureWebhooks), ok)
+	app.Get("/api/payees", features.Require(FeaturePayees), ok)
+
+	if resp, body := do(t, app, fiber.MethodGet, "/api/webhooks", ""); resp.StatusCode != fiber.StatusNotFound {
+		t.Errorf("disabled feature = %d %s, want 404", resp.StatusCode, body)
+	}
+	if resp, body := do(t, app, fiber.MethodGet, "/api/payees", ""); resp.StatusCode != fiber.StatusOK || body != "ok" {
+		t.Errorf("enabled feature = %d %s, want 200", resp.StatusCode, body)
+	}
+}
+
+func TestFeatureListReflectsConfig(t *testing.T) {
+	for _, tt := range []struct {
+		disabled []string
+		want     []string
+	}{
+		{nil, []string{"batch_transfers", "beneficiaries", "export", "payees", "refunds", "round_up", "statements", "two_factor", "webhooks"}},
+		{[]string{FeatureTwoFactor, FeatureExport, FeatureWebhooks}, []string{"batch_transfers", "beneficiaries", "payees", "refunds", "round_up", "statements"}},
+		{allFeatures, []string{}},
+	} {
+		features, err := NewFeatureFlags(tt.disabled)
+		if err != nil {
+			t.Fatalf("NewFeatureFlags(%v): %v", tt.disabled, err)
+		}
+		app := testApp(t, nil)
+		app.Get("/api/features", features.List)
+		resp, body := do(t, app, fiber.MethodGet, "/api/features", "")
+		if resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("GET /api/features = %d %s", resp.StatusCode, body)
+		}
+		var got struct {
+			Features []string `json:"features"`
+		}
+		decode(t, body, &got)
+		if !reflect.DeepEqual(got.Features, tt.want) {
+			t.Errorf("disabled %v: features = %v, want %v", tt.disabled, got.Features, tt.want)
+		}
+	}
+}
+
+func TestUnknownFeatureIsAnError(t *testing.T) {
+	if _, err := NewFeatureFlags([]string{"webhook"}); err == nil {
+		t.Error("misspelt feature accepted")
+	}
+}
@@ -0,0 +1,108 @@
+// Path: internal/handlers/fields.go
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Fields clients may select with ?fields=. Only fields listed here can be selected, so anything
+// sensitive stays out even if it is ever added to the JSON of a model.
+var (
+	accountFields = []string{"id", "user_id", "number", "balance", "currency", "type", "nickname", "sandbox", "frozen", "frozen_reason", "created_at", "dormant", "last_activity_at"}
+
+	transactionFields = []string{
+		"id", "from_account_id", "to_account_id", "amount", "type", "status", "description", "source",
+		"fee", "fee_waived", "fee_waiver", "initiator_id", "approver_id", "failure_reason", "refunded_amount", "refund_of", "created_at", "tags",
+	}
+)
+
+// sparseJSON responds with body, keeping only the fields named in the comma-separated "fields"
+// query parameter, e.g. ?fields=id,balance. Without it the full body is sent. body must encode
+// to an object or an array of objects.
+func sparseJSON(c *fiber.Ctx, body interface{}, allowed []string) error {
+	param := c.Query("fields")
+	if param == "" {
+		return c.JSON(body)
+	}
+
+	selected := make(map[string]bool)
+	var unknown []string
+	for _, field := range strings.Split(param, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if !containsField(allowed, field) {
+			unknown = append(unknown, field)
+			continue
+		}
+		selected[field] = true
+	}
+	if len(unknown) > 0 {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid fields",
+			Details: "Unknown or unavailable fields: " + strings.Join(unknown, ", ") + "; allowed: " + strings.Join(allowed, ", "),
+		}
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to encode response",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var items []map[string]json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return &AppError{
+				Code:    fiber.StatusInternalServerError,
+				Message: "Failed to encode response",
+				Details: err.Error(),
+				Err:     err,
+			}
+		}
+		for _, item := range items {
+			keepFields(item, selected)
+		}
+		return c.JSON(items)
+	}
+
+	var item map[string]json.RawMessage
+	if err := json.Unmarshal(data, &item); err != nil {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to encode response",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+	keepFields(item, selected)
+	return c.JSON(item)
+}
+
+func keepFields(item map[string]json.RawMessage, selected map[string]bool) {
+	for field := range item {
+		if !selected[field] {
+			delete(item, field)
+		}
+	}
+}
+
+func containsField(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+// Path: internal/handlers/fields_test.go
+package handlers
+
+import (
+	"bank-api/internal/models"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestSparseJSONKeepsSelectedFields(t *testing.T) {
+	accounts := []models.Account{
+		{ID: 1, Balance: 10, Currency: "USD", BalanceHash: "secret-hash"},
+		{ID: 2, Balance: 20, Currency: "EUR", BalanceHash: "secret-hash"},
+	}
+	app := testApp(t, nil)
+	app.Get("/accounts", func(c *fiber.Ctx) error { return sparseJSON(c, accounts, accountFields) })
+	app.Get("/account", func(c *fiber.Ctx) error { return sparseJSON(c, accounts[0], accountFields) })
+
+	resp, body := do(t, app, "GET", "/accounts?fields=id,%20balance", "")
+	if resp.StatusCode != 200 {
+		t.Fatalf("GET /accounts = %d %s", resp.StatusCode, body)
+	}
+	var items []map[string]interface{}
+	decode(t, body, &items)
+	if len(items) != 2 {
+		t.Fatalf("body = %s, want both accounts", body)
+	}
+	for _, item := range items {
+		if keys := sortedKeys(item); !reflect.DeepEqual(keys, []string{"balance", "id"}) {
+			t.Errorf("fields = %v, want balance and id", keys)
+		}
+	}
+
+	_, body = do(t, app, "GET", "/account?fields=currency", "")
+	var item map[string]interface{}
+	decode(t, body, &item)
+	if !reflect.DeepEqual(item, map[string]interface{}{"currency": "USD"}) {
+		t.Errorf("single account = %s, want only the currency", body)
+	}
+
+	// Without a selection the full body is sent, still without the hash.
+	_, body = do(t, app, "GET", "/account", "")
+	if !strings.Contains(body, `"nickname"`) || strings.Contains(body, "secret-hash") {
+		t.Errorf("full body = %s", body)
+	}
+}
+
+func TestSparseJSONRefusesUnlistedFields(t *testing.T) {
+	app := testApp(t, nil)
+	app.Get("/accounts", func(c *fiber.Ctx) error {
+		return sparseJSON(c, []models.Account{{ID: 1, BalanceHash: "secret-hash"}}, accountFields)
+	})
+
+	for _, fields := range []string{"balance_hash", "id,BalanceHash", "password"} {
+		resp, body := do(t, app, "GET", "/accounts?fields="+fields, "")
+		if resp.StatusCode != 400 || strings.Contains(body, "secret-hash") {
+			t.Errorf("fields=%s: %d %s, want 400", fields, resp.StatusCode, body)
+		}
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
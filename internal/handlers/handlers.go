@@ -4,26 +4,56 @@ package handlers
 import (
 	"bank-api/internal/models"
 	"bank-api/internal/services"
+	"bank-api/pkg/utils"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/gofiber/fiber/v2"
 	"strconv"
+	"time"
 )
 
 type Handler struct {
 	transactionService services.TransactionService
 	authService        services.AuthService
 	accountService     services.AccountService
+	auditService       services.AuditService
+	payeeService       services.PayeeService
+	receiptService     services.ReceiptService
+	rateService        services.RateService
+	exportService      services.ExportService
+	statementService   services.StatementService
+	webhookService     services.WebhookService
+	clock              utils.Clock
 }
 
-func NewHandler(ts services.TransactionService, as services.AuthService, acs services.AccountService) *Handler {
+func NewHandler(ts services.TransactionService, as services.AuthService, acs services.AccountService, aus services.AuditService, ps services.PayeeService, rs services.ReceiptService, frs services.RateService, es services.ExportService, ss services.StatementService, ws services.WebhookService, clock utils.Clock) *Handler {
 	return &Handler{
 		transactionService: ts,
 		authService:        as,
 		accountService:     acs,
+		auditService:       aus,
+		payeeService:       ps,
+		receiptService:     rs,
+		rateService:        frs,
+		exportService:      es,
+		statementService:   ss,
+		webhookService:     ws,
+		clock:              clock,
 	}
 }
 
+// now is the current time of the configured clock, or the real time when none is set.
+func (h *Handler) now() time.Time {
+	if h.clock == nil {
+		return utils.RealClock.Now()
+	}
+	return h.clock.Now()
+}
+
 type AppError struct {
 	Code    int    `json:"-"`
 	Message string `json:"message"`
@@ -35,29 +65,38 @@ func (e *AppError) Error() string {
 	return fmt.Sprintf("AppError: %s (Code: %d, Details: %s, OriginalError: %v)", e.Message, e.Code, e.Details, e.Err)
 }
 
-func (h *Handler) ErrorHandler(c *fiber.Ctx, err error) error {
-	fmt.Printf("Error: %v\n", err)
+// created responds with 201 and a Location header pointing to the new resource.
+func created(c *fiber.Ctx, location string, body interface{}) error {
+	c.Location(location)
+	return c.Status(fiber.StatusCreated).JSON(body)
+}
 
-	code := fiber.StatusInternalServerError
-	message := "Internal Server Error"
-	details := ""
+// paramID parses a path parameter as a resource ID. IDs start at 1, so zero and negative
+// values are rejected here instead of reaching the database as a confusing "not found".
+func paramID(c *fiber.Ctx, name string) (int, error) {
+	id, err := strconv.Atoi(c.Params(name))
+	if err != nil {
+		return 0, err
+	}
+	if id <= 0 {
+		return 0, fmt.Errorf("%s must be a positive integer, got %d", name, id)
+	}
+	return id, nil
+}
 
-	var appErr *AppError
-	if errors.As(err, &appErr) {
-		code = appErr.Code
-		message = appErr.Message
-		details = appErr.Details
-	} else if e, ok := err.(*fiber.Error); ok {
-		code = e.Code
-		message = e.Message
-	} else {
-		details = err.Error()
+// bind returns service bound to the request context, so the deadline set by Timeout reaches its
+// queries. Services without WithContext, such as test fakes, are used as they are. Exports and
+// webhooks aren't bound: they keep querying after the handler has returned.
+func bind[S any](c *fiber.Ctx, service S) S {
+	if s, ok := any(service).(interface{ WithContext(context.Context) S }); ok {
+		return s.WithContext(c.UserContext())
 	}
+	return service
+}
 
-	return c.Status(code).JSON(fiber.Map{
-		"error":   message,
-		"details": details,
-	})
+// loginMeta describes the client of a login request for the login history.
+func loginMeta(c *fiber.Ctx) models.LoginMeta {
+	return models.LoginMeta{IP: c.IP(), UserAgent: c.Get(fiber.HeaderUserAgent)}
 }
 
 // Регистрация с возвратом JWT токена
@@ -72,7 +111,7 @@ func (h *Handler) Register(c *fiber.Ctx) error {
 		}
 	}
 
-	if err := h.authService.Register(req.Username, req.Password); err != nil {
+	if err := bind(c, h.authService).Register(req.Username, req.Password); err != nil {
 		var appErr *services.AppError
 		if errors.As(err, &appErr) {
 			return appErr
@@ -86,7 +125,7 @@ func (h *Handler) Register(c *fiber.Ctx) error {
 	}
 
 	// Генерация токена после успешной регистрации
-	token, err := h.authService.Login(req.Username, req.Password)
+	token, err := bind(c, h.authService).Login(req.Username, req.Password, loginMeta(c))
 	if err != nil {
 		return &AppError{
 			Code:    fiber.StatusInternalServerError,
@@ -113,7 +152,9 @@ func (h *Handler) Login(c *fiber.Ctx) error {
 		}
 	}
 
-	token, err := h.authService.Login(req.Username, req.Password)
+	meta := loginMeta(c)
+	meta.Sandbox = req.Sandbox
+	token, err := bind(c, h.authService).Login(req.Username, req.Password, meta)
 	if err != nil {
 		var appErr *services.AppError
 		if errors.As(err, &appErr) {
@@ -135,9 +176,20 @@ func (h *Handler) AuthMiddleware(c *fiber.Ctx) error {
 		return c.Next()
 	}
 
+	claims, err := h.authenticate(c)
+	if err != nil {
+		return err
+	}
+
+	c.Locals("user", claims)
+	return c.Next()
+}
+
+// authenticate validates the bearer token of the request and returns its claims.
+func (h *Handler) authenticate(c *fiber.Ctx) (*models.Claims, error) {
 	authHeader := c.Get("Authorization")
 	if authHeader == "" {
-		return &AppError{
+		return nil, &AppError{
 			Code:    fiber.StatusUnauthorized,
 			Message: "Missing token",
 			Details: "Authorization header is empty",
@@ -146,24 +198,36 @@ func (h *Handler) AuthMiddleware(c *fiber.Ctx) error {
 
 	var token string
 	if _, err := fmt.Sscanf(authHeader, "Bearer %s", &token); err != nil {
-		return &AppError{
+		return nil, &AppError{
 			Code:    fiber.StatusUnauthorized,
 			Message: "Invalid token format",
 			Details: err.Error(),
 		}
 	}
 
-	claims, err := h.authService.ValidateToken(token)
+	claims, err := bind(c, h.authService).ValidateToken(token)
 	if err != nil {
-		return &AppError{
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return nil, appErr
+		}
+		return nil, &AppError{
 			Code:    fiber.StatusUnauthorized,
 			Message: "Invalid token",
 			Details: err.Error(),
 		}
 	}
 
-	c.Locals("user", claims)
-	return c.Next()
+	// A token in its post-expiry grace period may only read.
+	if claims.Stale && c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+		return nil, &AppError{
+			Code:    fiber.StatusUnauthorized,
+			Message: "Token expired",
+			Details: "Log in again to get a fresh token; an expired token only allows reads",
+		}
+	}
+
+	return claims, nil
 }
 
 func (h *Handler) GetAccounts(c *fiber.Ctx) error {
@@ -176,7 +240,7 @@ func (h *Handler) GetAccounts(c *fiber.Ctx) error {
 		}
 	}
 
-	accounts, err := h.accountService.GetAccounts(claims.UserID)
+	accounts, err := bind(c, h.accountService).GetAccounts(claims.UserID, claims.Sandbox)
 	if err != nil {
 		var appErr *services.AppError
 		if errors.As(err, &appErr) {
@@ -190,10 +254,10 @@ func (h *Handler) GetAccounts(c *fiber.Ctx) error {
 		}
 	}
 
-	return c.JSON(accounts)
+	return sparseJSON(c, accounts, accountFields)
 }
 
-func (h *Handler) Transfer(c *fiber.Ctx) error {
+func (h *Handler) CreateAccount(c *fiber.Ctx) error {
 	claims, ok := c.Locals("user").(*models.Claims)
 	if !ok {
 		return &AppError{
@@ -203,7 +267,7 @@ func (h *Handler) Transfer(c *fiber.Ctx) error {
 		}
 	}
 
-	var req models.TransferRequest
+	var req models.CreateAccountRequest
 	if err := c.BodyParser(&req); err != nil {
 		return &AppError{
 			Code:    fiber.StatusBadRequest,
@@ -213,23 +277,81 @@ func (h *Handler) Transfer(c *fiber.Ctx) error {
 		}
 	}
 
-	if err := h.transactionService.ProcessTransfer(&req, claims); err != nil {
+	req.Sandbox = claims.Sandbox
+	account, err := bind(c, h.accountService).CreateAccount(claims.UserID, &req)
+	if err != nil {
 		var appErr *services.AppError
 		if errors.As(err, &appErr) {
 			return appErr
 		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to create account",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return created(c, fmt.Sprintf("/api/accounts/%d", account.ID), account)
+}
+
+func (h *Handler) GetAccount(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	accountID, err := paramID(c, "id")
+	if err != nil {
 		return &AppError{
 			Code:    fiber.StatusBadRequest,
-			Message: "Transfer failed",
+			Message: "Invalid account ID",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	account, err := bind(c, h.accountService).GetAccount(claims, accountID)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve account",
 			Details: err.Error(),
 			Err:     err,
 		}
 	}
 
-	return c.JSON(fiber.Map{"message": "Transfer successful"})
+	// The balance hash changes with every balance change, so a client polling the account
+	// gets 304 Not Modified without a body until money moves or the account is edited.
+	c.Set(fiber.HeaderETag, accountETag(account, c.Query("fields")))
+	if c.Fresh() {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+	return sparseJSON(c, account, accountFields)
 }
 
-func (h *Handler) Deposit(c *fiber.Ctx) error {
+// accountETag is a strong validator of the account as served with the given fields parameter:
+// its balance hash plus the rest of its visible state.
+func accountETag(account *models.Account, fields string) string {
+	state, _ := json.Marshal(account) // Accounts always encode.
+	sum := sha256.New()
+	sum.Write([]byte(account.BalanceHash))
+	sum.Write([]byte{0})
+	sum.Write(state)
+	sum.Write([]byte{0})
+	sum.Write([]byte(fields))
+	return `"` + hex.EncodeToString(sum.Sum(nil)[:16]) + `"`
+}
+
+func (h *Handler) GetBalanceProof(c *fiber.Ctx) error {
 	claims, ok := c.Locals("user").(*models.Claims)
 	if !ok {
 		return &AppError{
@@ -239,7 +361,7 @@ func (h *Handler) Deposit(c *fiber.Ctx) error {
 		}
 	}
 
-	accountID, err := strconv.Atoi(c.Params("id"))
+	accountID, err := paramID(c, "id")
 	if err != nil {
 		return &AppError{
 			Code:    fiber.StatusBadRequest,
@@ -249,37 +371,112 @@ func (h *Handler) Deposit(c *fiber.Ctx) error {
 		}
 	}
 
-	var req models.TransactionRequest
-	if err := c.BodyParser(&req); err != nil {
+	proof, err := bind(c, h.accountService).GetBalanceProof(claims, accountID)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve balance proof",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(proof)
+}
+
+// GetBalanceAt returns the balance of an account as of ?at=<RFC 3339 timestamp>, by default now.
+func (h *Handler) GetBalanceAt(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	accountID, err := paramID(c, "id")
+	if err != nil {
 		return &AppError{
 			Code:    fiber.StatusBadRequest,
-			Message: "Invalid request format",
+			Message: "Invalid account ID",
 			Details: err.Error(),
 			Err:     err,
 		}
 	}
 
-	req.AccountID = accountID
-	if err := h.transactionService.ProcessDeposit(&req, claims); err != nil {
+	at := h.now()
+	if v := c.Query("at"); v != "" {
+		at, err = time.Parse(time.RFC3339, v)
+		if err != nil {
+			return &AppError{
+				Code:    fiber.StatusBadRequest,
+				Message: "Invalid query parameter",
+				Details: "at: expected an RFC 3339 timestamp",
+				Err:     err,
+			}
+		}
+	}
+
+	balance, err := bind(c, h.accountService).BalanceAt(claims.UserID, accountID, at)
+	if err != nil {
 		var appErr *services.AppError
 		if errors.As(err, &appErr) {
 			return appErr
 		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to compute balance",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(balance)
+}
+
+func (h *Handler) ReactivateAccount(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	accountID, err := paramID(c, "id")
+	if err != nil {
 		return &AppError{
 			Code:    fiber.StatusBadRequest,
-			Message: "Deposit failed",
+			Message: "Invalid account ID",
 			Details: err.Error(),
 			Err:     err,
 		}
 	}
 
-	return c.JSON(fiber.Map{
-		"message":       "Deposit successful",
-		"transactionID": req.TransactionID,
-	})
+	account, err := bind(c, h.accountService).Reactivate(claims.UserID, accountID)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to reactivate account",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(account)
 }
 
-func (h *Handler) Withdraw(c *fiber.Ctx) error {
+func (h *Handler) CloseAccount(c *fiber.Ctx) error {
 	claims, ok := c.Locals("user").(*models.Claims)
 	if !ok {
 		return &AppError{
@@ -289,7 +486,7 @@ func (h *Handler) Withdraw(c *fiber.Ctx) error {
 		}
 	}
 
-	accountID, err := strconv.Atoi(c.Params("id"))
+	accountID, err := paramID(c, "id")
 	if err != nil {
 		return &AppError{
 			Code:    fiber.StatusBadRequest,
@@ -299,7 +496,81 @@ func (h *Handler) Withdraw(c *fiber.Ctx) error {
 		}
 	}
 
-	var req models.TransactionRequest
+	account, err := bind(c, h.accountService).CloseAccount(claims, accountID)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to close account",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(account)
+}
+
+func (h *Handler) ReopenAccount(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	accountID, err := paramID(c, "id")
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid account ID",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	account, err := bind(c, h.accountService).ReopenAccount(claims, accountID)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to reopen account",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(account)
+}
+
+func (h *Handler) UpdateAccount(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	accountID, err := paramID(c, "id")
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid account ID",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	var req models.UpdateAccountRequest
 	if err := c.BodyParser(&req); err != nil {
 		return &AppError{
 			Code:    fiber.StatusBadRequest,
@@ -309,22 +580,631 @@ func (h *Handler) Withdraw(c *fiber.Ctx) error {
 		}
 	}
 
-	req.AccountID = accountID
-	if err := h.transactionService.ProcessWithdraw(&req, claims); err != nil {
+	account, err := bind(c, h.accountService).SetNickname(claims, accountID, &req)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to update account",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(account)
+}
+
+func (h *Handler) Transfer(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	var req models.TransferRequest
+	if err := c.BodyParser(&req); err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	if err := bind(c, h.transactionService).ProcessTransfer(&req, claims); err != nil {
 		var appErr *services.AppError
 		if errors.As(err, &appErr) {
 			return appErr
 		}
 		return &AppError{
 			Code:    fiber.StatusBadRequest,
-			Message: "Withdrawal failed",
+			Message: "Transfer failed",
 			Details: err.Error(),
 			Err:     err,
 		}
 	}
 
+	if !req.Duplicate {
+		h.dispatchTransaction(claims, "transfer", req.TransactionID, req.Status)
+	}
+
+	if req.Status == "pending_approval" || req.Status == "incoming_pending" {
+		message := "Transfer pending approval"
+		if req.Status == "incoming_pending" {
+			message = "Transfer awaiting acceptance"
+		}
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+			"message":       message,
+			"transactionID": req.TransactionID,
+			"status":        req.Status,
+			"amount":        req.Amount,
+			"fee":           req.Fee,
+			"fee_waived":    req.FeeWaived,
+			"duplicate":     req.Duplicate,
+		})
+	}
+
 	return c.JSON(fiber.Map{
-		"message":       "Withdrawal successful",
+		"message":       "Transfer successful",
 		"transactionID": req.TransactionID,
+		"status":        req.Status,
+		"amount":        req.Amount,
+		"fee":           req.Fee,
+		"fee_waived":    req.FeeWaived,
+		"duplicate":     req.Duplicate,
 	})
 }
+
+// QuoteTransfer returns the fee of a transfer without executing it. The query takes from, amount
+// and one of to, payee_id or to_account_number.
+func (h *Handler) QuoteTransfer(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	req := models.TransferRequest{ToNumber: c.Query("to_account_number"), Description: c.Query("description")}
+	for name, dst := range map[string]*int{"from": &req.FromID, "to": &req.ToID, "payee_id": &req.PayeeID} {
+		if v := c.Query(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return &AppError{
+					Code:    fiber.StatusBadRequest,
+					Message: "Invalid query parameter",
+					Details: name + ": " + err.Error(),
+					Err:     err,
+				}
+			}
+			*dst = n
+		}
+	}
+
+	amount, err := strconv.ParseFloat(c.Query("amount"), 64)
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid amount",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+	req.Amount = amount
+
+	quote, err := bind(c, h.transactionService).QuoteTransfer(&req, claims)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Fee quote failed",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(quote)
+}
+
+// ValidateTransfer runs the checks of a transfer request without executing it and reports each
+// one as passed, failed or skipped. A transfer that would be refused still gets a 200.
+func (h *Handler) ValidateTransfer(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	var req models.TransferRequest
+	if err := c.BodyParser(&req); err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	validation, err := bind(c, h.transactionService).ValidateTransfer(&req, claims)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Transfer validation failed",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(validation)
+}
+
+func (h *Handler) ApproveTransfer(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	transactionID := c.Params("id")
+	if err := bind(c, h.transactionService).ApproveTransfer(transactionID, claims); err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Approval failed",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"message":       "Transfer approved",
+		"transactionID": transactionID,
+	})
+}
+
+func (h *Handler) RejectTransfer(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	transactionID := c.Params("id")
+	if err := bind(c, h.transactionService).RejectTransfer(transactionID, claims); err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Rejection failed",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"message":       "Transfer rejected",
+		"transactionID": transactionID,
+	})
+}
+
+func (h *Handler) CancelTransfer(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	transactionID := c.Params("id")
+	if err := bind(c, h.transactionService).CancelTransfer(transactionID, claims); err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Cancellation failed",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"message":       "Transfer cancelled",
+		"transactionID": transactionID,
+	})
+}
+
+func (h *Handler) AcceptTransfer(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	transactionID := c.Params("id")
+	if err := bind(c, h.transactionService).AcceptTransfer(transactionID, claims); err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Acceptance failed",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"message":       "Transfer accepted",
+		"transactionID": transactionID,
+	})
+}
+
+func (h *Handler) DeclineTransfer(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	transactionID := c.Params("id")
+	if err := bind(c, h.transactionService).DeclineTransfer(transactionID, claims); err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Decline failed",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"message":       "Transfer declined",
+		"transactionID": transactionID,
+	})
+}
+
+func (h *Handler) Deposit(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	accountID, err := paramID(c, "id")
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid account ID",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	var req models.TransactionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	req.AccountID = accountID
+	balance, err := bind(c, h.transactionService).ProcessDeposit(&req, claims)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Deposit failed",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	h.dispatchTransaction(claims, "deposit", req.TransactionID, req.Status)
+
+	if req.Status == "held" {
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+			"message":          "Deposit held for review",
+			"transactionID":    req.TransactionID,
+			"status":           req.Status,
+			"balance":          balance.Balance,
+			"availableBalance": balance.AvailableBalance,
+			"balanceDisplay":   balance.Display,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":          "Deposit successful",
+		"transactionID":    req.TransactionID,
+		"status":           req.Status,
+		"balance":          balance.Balance,
+		"availableBalance": balance.AvailableBalance,
+		"balanceDisplay":   balance.Display,
+	})
+}
+
+// SplitDeposit deposits one amount across several of the user's accounts at once.
+func (h *Handler) SplitDeposit(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	var req models.SplitDepositRequest
+	if err := c.BodyParser(&req); err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	deposits, err := bind(c, h.transactionService).ProcessSplitDeposit(&req, claims)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Split deposit failed",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	for _, deposit := range deposits {
+		h.dispatchTransaction(claims, "deposit", deposit.TransactionID, deposit.Status)
+	}
+
+	return c.JSON(fiber.Map{
+		"message":  "Split deposit successful",
+		"total":    req.Total,
+		"deposits": deposits,
+	})
+}
+
+func (h *Handler) PreviewDeposit(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	accountID, err := paramID(c, "id")
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid account ID",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	amount, err := strconv.ParseFloat(c.Query("amount"), 64)
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid amount",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	req := models.TransactionRequest{AccountID: accountID, Amount: amount, Source: c.Query("source")}
+	preview, err := bind(c, h.transactionService).PreviewDeposit(&req, claims)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Deposit preview failed",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(preview)
+}
+
+func (h *Handler) Withdraw(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	accountID, err := paramID(c, "id")
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid account ID",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	var req models.TransactionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	req.AccountID = accountID
+	balance, err := bind(c, h.transactionService).ProcessWithdraw(&req, claims)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Withdrawal failed",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	h.dispatchTransaction(claims, "withdraw", req.TransactionID, req.Status)
+
+	return c.JSON(fiber.Map{
+		"message":          "Withdrawal successful",
+		"transactionID":    req.TransactionID,
+		"status":           req.Status,
+		"balance":          balance.Balance,
+		"availableBalance": balance.AvailableBalance,
+		"balanceDisplay":   balance.Display,
+	})
+}
+
+func (h *Handler) AddBeneficiary(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	accountID, err := paramID(c, "id")
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid account ID",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	var req models.BeneficiaryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	beneficiary, err := bind(c, h.accountService).AddBeneficiary(claims.UserID, accountID, &req)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to add beneficiary",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return created(c, fmt.Sprintf("/api/accounts/%d/beneficiaries", accountID), beneficiary)
+}
+
+func (h *Handler) GetBeneficiaries(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	accountID, err := paramID(c, "id")
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid account ID",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	beneficiaries, err := bind(c, h.accountService).GetBeneficiaries(claims.UserID, accountID)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve beneficiaries",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(beneficiaries)
+}
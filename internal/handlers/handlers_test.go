@@ -0,0 +1,416 @@
+// Path: internal/handlers/handlers_test.go
+package handlers
+
+import (
+	"bank-api/internal/models"
+	"bank-api/internal/services"
+	"bank-api/pkg/utils"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type creatingAccounts struct {
+	services.AccountService
+	req *models.CreateAccountRequest
+}
+
+func (s *creatingAccounts) CreateAccount(userID uint, req *models.CreateAccountRequest) (*models.Account, error) {
+	if req.Currency == "XXX" {
+		return nil, &services.AppError{Code: 400, Message: "Validation failed", Details: "currency"}
+	}
+	s.req = req
+	return &models.Account{ID: 42, UserID: int(userID), Currency: req.Currency, Nickname: req.Nickname}, nil
+}
+
+func TestCreateAccountReturnsLocation(t *testing.T) {
+	accounts := &creatingAccounts{}
+	h := &Handler{accountService: accounts}
+	app := testApp(t, &models.Claims{UserID: 7, Sandbox: true})
+	app.Post("/api/accounts", h.CreateAccount)
+
+	resp, body := do(t, app, "POST", "/api/accounts", `{"currency": "EUR", "nickname": "Travel"}`)
+	if resp.StatusCode != 201 {
+		t.Fatalf("POST /api/accounts = %d %s, want 201", resp.StatusCode, body)
+	}
+	if got := resp.Header.Get("Location"); got != "/api/accounts/42" {
+		t.Errorf("Location = %q, want /api/accounts/42", got)
+	}
+	var account models.Account
+	decode(t, body, &account)
+	if account.ID != 42 || account.Currency != "EUR" {
+		t.Errorf("body = %s", body)
+	}
+	if !accounts.req.Sandbox {
+		t.Error("a sandbox token created a real account")
+	}
+
+	resp, body = do(t, app, "POST", "/api/accounts", `{"currency": "XXX"}`)
+	if resp.StatusCode != 400 || resp.Header.Get("Location") != "" {
+		t.Errorf("failed creation = %d with Location %q: %s", resp.StatusCode, resp.Header.Get("Location"), body)
+	}
+}
+
+type creatingPayees struct {
+	services.PayeeService
+}
+
+func (creatingPayees) CreatePayee(req *models.PayeeRequest, claims *models.Claims) (*models.Payee, error) {
+	return &models.Payee{ID: 9, UserID: int(claims.UserID), AccountID: req.AccountID, Label: req.Label}, nil
+}
+
+func TestCreatePayeeReturnsLocation(t *testing.T) {
+	h := &Handler{payeeService: creatingPayees{}}
+	app := testApp(t, &models.Claims{UserID: 7})
+	app.Post("/api/payees", h.CreatePayee)
+
+	resp, body := do(t, app, "POST", "/api/payees", `{"account_id": 3, "label": "Rent"}`)
+	if resp.StatusCode != 201 || resp.Header.Get("Location") != "/api/payees/9" {
+		t.Errorf("POST /api/payees = %d with Location %q: %s", resp.StatusCode, resp.Header.Get("Location"), body)
+	}
+}
+
+func TestValidationErrorsListEveryField(t *testing.T) {
+	h := &Handler{transactionService: services.NewTransactionService(nil, "secret", services.TransactionConfig{})}
+	app := testApp(t, &models.Claims{UserID: 1})
+	app.Post("/api/transfer", h.Transfer)
+
+	resp, body := do(t, app, "POST", "/api/transfer", `{"amount": -5, "to_id": 3, "payee_id": 4}`)
+	if resp.StatusCode != 400 {
+		t.Fatalf("POST /api/transfer = %d %s, want 400", resp.StatusCode, body)
+	}
+	var response struct {
+		Details []services.FieldError `json:"details"`
+	}
+	decode(t, body, &response)
+	var fields []string
+	for _, field := range response.Details {
+		fields = append(fields, field.Field)
+	}
+	if want := []string{"amount", "from_id", "to_id"}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("details = %s, want fields %v", body, want)
+	}
+}
+
+type listingTransactions struct {
+	services.TransactionService
+	filter models.TransactionFilter
+}
+
+func (s *listingTransactions) ListTransactions(userID uint, filter models.TransactionFilter) ([]models.Transaction, string, error) {
+	s.filter = filter
+	return []models.Transaction{}, "", nil
+}
+
+func TestGetCounterpartyTransactionsParsesTheRoute(t *testing.T) {
+	transactions := &listingTransactions{}
+	h := &Handler{transactionService: transactions}
+	app := testApp(t, &models.Claims{UserID: 1})
+	app.Get("/api/accounts/:id/with/:counterparty", h.GetCounterpartyTransactions)
+
+	resp, body := do(t, app, "GET", "/api/accounts/3/with/8?limit=5&offset=10", "")
+	if resp.StatusCode != 200 {
+		t.Fatalf("GET = %d %s, want 200", resp.StatusCode, body)
+	}
+	if want := (models.TransactionFilter{AccountID: 3, CounterpartyID: 8, Limit: 5, Offset: 10}); !reflect.DeepEqual(transactions.filter, want) {
+		t.Errorf("filter = %+v, want %+v", transactions.filter, want)
+	}
+
+	for _, target := range []string{"/api/accounts/3/with/abc", "/api/accounts/x/with/8", "/api/accounts/3/with/8?limit=many"} {
+		if resp, body := do(t, app, "GET", target, ""); resp.StatusCode != 400 {
+			t.Errorf("GET %s = %d %s, want 400", target, resp.StatusCode, body)
+		}
+	}
+}
+
+func TestSetLimitsAboveTheMax(t *testing.T) {
+	h := &Handler{transactionService: services.NewTransactionService(nil, "secret", services.TransactionConfig{DailySpendingLimit: 500})}
+	app := testApp(t, &models.Claims{UserID: 1})
+	app.Put("/api/me/limits", h.SetLimits)
+
+	resp, body := do(t, app, "PUT", "/api/me/limits", `{"daily_limit": 501}`)
+	if resp.StatusCode != 400 || !strings.Contains(body, "daily_limit") {
+		t.Errorf("PUT /api/me/limits = %d %s, want 400 naming daily_limit", resp.StatusCode, body)
+	}
+}
+
+type movingMoney struct {
+	services.TransactionService
+	accountIDs []int
+	balance    models.AccountBalance
+}
+
+func (s *movingMoney) ProcessDeposit(req *models.TransactionRequest, claims *models.Claims) (*models.AccountBalance, error) {
+	s.accountIDs = append(s.accountIDs, req.AccountID)
+	req.Status = "completed"
+	balance := s.balance
+	return &balance, nil
+}
+
+func (s *movingMoney) ProcessWithdraw(req *models.TransactionRequest, claims *models.Claims) (*models.AccountBalance, error) {
+	return s.ProcessDeposit(req, claims)
+}
+
+type silentWebhooks struct {
+	services.WebhookService
+}
+
+func (silentWebhooks) Dispatch(userID uint, event string, payload interface{}) {}
+
+func TestAccountIDsMustBePositive(t *testing.T) {
+	transactions := &movingMoney{}
+	h := &Handler{transactionService: transactions, webhookService: silentWebhooks{}}
+	app := testApp(t, &models.Claims{UserID: 1})
+	app.Post("/api/deposit/:id", h.Deposit)
+	app.Post("/api/withdraw/:id", h.Withdraw)
+
+	for _, action := range []string{"deposit", "withdraw"} {
+		for _, id := range []string{"0", "-5", "abc"} {
+			resp, body := do(t, app, "POST", "/api/"+action+"/"+id, `{"amount": 10}`)
+			if resp.StatusCode != 400 || !strings.Contains(body, "Invalid account ID") {
+				t.Errorf("%s on account %s = %d %s, want 400", action, id, resp.StatusCode, body)
+			}
+		}
+		if resp, body := do(t, app, "POST", "/api/"+action+"/7", `{"amount": 10}`); resp.StatusCode != 200 {
+			t.Errorf("%s on account 7 = %d %s, want 200", action, resp.StatusCode, body)
+		}
+	}
+	if want := []int{7, 7}; !reflect.DeepEqual(transactions.accountIDs, want) {
+		t.Errorf("service saw accounts %v, want only %v", transactions.accountIDs, want)
+	}
+}
+
+func TestDepositAndWithdrawReturnTheBalance(t *testing.T) {
+	transactions := &movingMoney{balance: models.AccountBalance{AccountID: 7, Balance: 140.25, AvailableBalance: 110.25, Display: "$140.25"}}
+	h := &Handler{transactionService: transactions, webhookService: silentWebhooks{}}
+	app := testApp(t, &models.Claims{UserID: 1})
+	app.Post("/api/deposit/:id", h.Deposit)
+	app.Post("/api/withdraw/:id", h.Withdraw)
+
+	for _, action := range []string{"deposit", "withdraw"} {
+		resp, body := do(t, app, "POST", "/api/"+action+"/7", `{"amount": 10}`)
+		if resp.StatusCode != 200 {
+			t.Fatalf("%s = %d %s", action, resp.StatusCode, body)
+		}
+		var got struct {
+			Balance          float64 `json:"balance"`
+			AvailableBalance float64 `json:"availableBalance"`
+			BalanceDisplay   string  `json:"balanceDisplay"`
+		}
+		decode(t, body, &got)
+		if got.Balance != 140.25 || got.AvailableBalance != 110.25 || got.BalanceDisplay != "$140.25" {
+			t.Errorf("%s response = %s", action, body)
+		}
+	}
+}
+
+type historicalAccounts struct {
+	services.AccountService
+	at time.Time
+}
+
+func (s *historicalAccounts) BalanceAt(userID uint, accountID int, at time.Time) (*models.HistoricalBalance, error) {
+	s.at = at
+	return &models.HistoricalBalance{AccountID: accountID, Balance: 55, At: at}, nil
+}
+
+func TestGetBalanceAtParsesTheTimestamp(t *testing.T) {
+	accounts := &historicalAccounts{}
+	clock := utils.NewManualClock(time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC))
+	h := &Handler{accountService: accounts, clock: clock}
+	app := testApp(t, &models.Claims{UserID: 1})
+	app.Get("/api/accounts/:id/balance", h.GetBalanceAt)
+
+	// Without ?at the balance is as of the configured clock's now.
+	if resp, body := do(t, app, "GET", "/api/accounts/3/balance", ""); resp.StatusCode != 200 || !accounts.at.Equal(clock.Now()) {
+		t.Errorf("GET without at = %d %s, service asked for %v, want %v", resp.StatusCode, body, accounts.at, clock.Now())
+	}
+
+	resp, body := do(t, app, "GET", "/api/accounts/3/balance?at=2024-05-01T12:00:00Z", "")
+	if want := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC); resp.StatusCode != 200 || !accounts.at.Equal(want) {
+		t.Errorf("GET = %d %s, service asked for %v", resp.StatusCode, body, accounts.at)
+	}
+	if resp, body := do(t, app, "GET", "/api/accounts/3/balance?at=yesterday", ""); resp.StatusCode != 400 {
+		t.Errorf("bad timestamp = %d %s, want 400", resp.StatusCode, body)
+	}
+}
+
+type closingAccounts struct {
+	services.AccountService
+	claims *models.Claims
+}
+
+func (s *closingAccounts) CloseAccount(claims *models.Claims, accountID int) (*models.Account, error) {
+	if accountID != 3 {
+		return nil, &services.AppError{Code: 409, Message: "Account balance is not zero"}
+	}
+	s.claims = claims
+	closedAt := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	return &models.Account{ID: accountID, ClosedAt: &closedAt}, nil
+}
+
+func (s *closingAccounts) ReopenAccount(claims *models.Claims, accountID int) (*models.Account, error) {
+	s.claims = claims
+	return &models.Account{ID: accountID}, nil
+}
+
+func TestCloseAndReopenAccount(t *testing.T) {
+	accounts := &closingAccounts{}
+	h := &Handler{accountService: accounts}
+	app := testApp(t, &models.Claims{UserID: 7, Sandbox: true})
+	app.Post("/api/accounts/:id/close", h.CloseAccount)
+	app.Post("/api/accounts/:id/reopen", h.ReopenAccount)
+
+	resp, body := do(t, app, "POST", "/api/accounts/3/close", "")
+	var account models.Account
+	decode(t, body, &account)
+	if resp.StatusCode != 200 || account.ClosedAt == nil {
+		t.Errorf("close = %d %s", resp.StatusCode, body)
+	}
+	if accounts.claims == nil || !accounts.claims.Sandbox {
+		t.Error("the service didn't get the token's claims")
+	}
+	if resp, body := do(t, app, "POST", "/api/accounts/4/close", ""); resp.StatusCode != 409 {
+		t.Errorf("close with money = %d %s, want 409", resp.StatusCode, body)
+	}
+	if resp, body := do(t, app, "POST", "/api/accounts/x/reopen", ""); resp.StatusCode != 400 {
+		t.Errorf("reopen with a bad id = %d %s, want 400", resp.StatusCode, body)
+	}
+	if resp, body := do(t, app, "POST", "/api/accounts/3/reopen", ""); resp.StatusCode != 200 || strings.Contains(body, `"closed_at":"`) {
+		t.Errorf("reopen = %d %s", resp.StatusCode, body)
+	}
+}
+
+type repeatingTransfers struct {
+	services.TransactionService
+	calls int
+}
+
+func (s *repeatingTransfers) ProcessTransfer(req *models.TransferRequest, claims *models.Claims) error {
+	s.calls++
+	req.TransactionID, req.Status = "TXN-1", "completed"
+	req.Duplicate = s.calls > 1
+	return nil
+}
+
+type countingWebhooks struct {
+	services.WebhookService
+	events []string
+}
+
+func (w *countingWebhooks) Dispatch(userID uint, event string, payload interface{}) {
+	w.events = append(w.events, event)
+}
+
+func TestDuplicateTransferIsNotDispatchedAgain(t *testing.T) {
+	webhooks := &countingWebhooks{}
+	h := &Handler{transactionService: &repeatingTransfers{}, webhookService: webhooks}
+	app := testApp(t, &models.Claims{UserID: 1})
+	app.Post("/api/transfer", h.Transfer)
+
+	for i, want := range []bool{false, true} {
+		resp, body := do(t, app, "POST", "/api/transfer", `{"from_id": 1, "to_id": 2, "amount": 10}`)
+		if resp.StatusCode != 200 {
+			t.Fatalf("transfer %d = %d %s", i+1, resp.StatusCode, body)
+		}
+		var got struct {
+			TransactionID string `json:"transactionID"`
+			Duplicate     bool   `json:"duplicate"`
+		}
+		decode(t, body, &got)
+		if got.TransactionID != "TXN-1" || got.Duplicate != want {
+			t.Errorf("transfer %d = %+v, want TXN-1 with duplicate %v", i+1, got, want)
+		}
+	}
+	if want := []string{"transfer.completed"}; !reflect.DeepEqual(webhooks.events, want) {
+		t.Errorf("webhook events = %v, want %v", webhooks.events, want)
+	}
+}
+
+type quotingTransfers struct {
+	services.TransactionService
+	req models.TransferRequest
+}
+
+func (s *quotingTransfers) QuoteTransfer(req *models.TransferRequest, claims *models.Claims) (*models.TransferQuote, error) {
+	s.req = *req
+	return &models.TransferQuote{FromID: req.FromID, ToID: req.ToID, Amount: req.Amount, Fee: 1, TotalDebit: req.Amount + 1}, nil
+}
+
+func TestQuoteTransferParsesTheQuery(t *testing.T) {
+	transactions := &quotingTransfers{}
+	h := &Handler{transactionService: transactions}
+	app := testApp(t, &models.Claims{UserID: 1})
+	app.Get("/api/transfer/fee", h.QuoteTransfer)
+
+	resp, body := do(t, app, "GET", "/api/transfer/fee?from=3&to=4&amount=12.5", "")
+	if resp.StatusCode != 200 {
+		t.Fatalf("quote = %d %s", resp.StatusCode, body)
+	}
+	if want := (models.TransferRequest{FromID: 3, ToID: 4, Amount: 12.5}); !reflect.DeepEqual(transactions.req, want) {
+		t.Errorf("service got %+v, want %+v", transactions.req, want)
+	}
+	var quote models.TransferQuote
+	decode(t, body, &quote)
+	if quote.TotalDebit != 13.5 {
+		t.Errorf("total_debit = %v, want 13.5", quote.TotalDebit)
+	}
+
+	for _, bad := range []string{"?from=x&to=4&amount=1", "?from=3&to=4", "?from=3&to=4&amount=lots"} {
+		if resp, _ := do(t, app, "GET", "/api/transfer/fee"+bad, ""); resp.StatusCode != 400 {
+			t.Errorf("GET /api/transfer/fee%s = %d, want 400", bad, resp.StatusCode)
+		}
+	}
+}
+
+type pollingAccounts struct {
+	services.AccountService
+	account models.Account
+}
+
+func (s *pollingAccounts) GetAccount(claims *models.Claims, accountID int) (*models.Account, error) {
+	account := s.account
+	return &account, nil
+}
+
+func TestGetAccountAnswersNotModified(t *testing.T) {
+	accounts := &pollingAccounts{account: models.Account{ID: 7, UserID: 1, Balance: 100, Currency: "USD", BalanceHash: "hash-at-100"}}
+	h := &Handler{accountService: accounts}
+	app := testApp(t, &models.Claims{UserID: 1})
+	app.Get("/api/accounts/:id", h.GetAccount)
+
+	resp, _ := do(t, app, "GET", "/api/accounts/7", "")
+	tag := resp.Header.Get("ETag")
+	if resp.StatusCode != 200 || tag == "" {
+		t.Fatalf("first fetch = %d with ETag %q", resp.StatusCode, tag)
+	}
+
+	resp, body := do(t, app, "GET", "/api/accounts/7", "", "If-None-Match", tag)
+	if resp.StatusCode != 304 || body != "" || resp.Header.Get("ETag") != tag {
+		t.Errorf("unchanged account = %d %q with ETag %q, want 304 with %q", resp.StatusCode, body, resp.Header.Get("ETag"), tag)
+	}
+
+	// The same account served with other fields is another representation.
+	if resp, _ := do(t, app, "GET", "/api/accounts/7?fields=id,balance", "", "If-None-Match", tag); resp.StatusCode != 200 {
+		t.Errorf("other fields = %d, want 200", resp.StatusCode)
+	}
+
+	accounts.account.Balance, accounts.account.BalanceHash = 90, "hash-at-90"
+	resp, body = do(t, app, "GET", "/api/accounts/7", "", "If-None-Match", tag)
+	if resp.StatusCode != 200 || resp.Header.Get("ETag") == tag {
+		t.Fatalf("changed balance = %d with ETag %q, want 200 with a new ETag", resp.StatusCode, resp.Header.Get("ETag"))
+	}
+	var got models.Account
+	decode(t, body, &got)
+	if got.Balance != 90 {
+		t.Errorf("balance = %v, want 90", got.Balance)
+	}
+
+	// A nickname change alters the representation too.
+	tag = resp.Header.Get("ETag")
+	accounts.account.Nickname = "Rent"
+	if resp, _ := do(t, app, "GET", "/api/accounts/7", "", "If-None-Match", tag); resp.StatusCode != 200 {
+		t.Errorf("renamed account = %d, want 200", resp.StatusCode)
+	}
+}
@@ -0,0 +1,65 @@
+// Path: internal/handlers/helpers_test.go
+package handlers
+
+import (
+	"bank-api/internal/models"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// testApp returns an app rendering errors like the server does, where every request is
+// authenticated as claims. Nil claims leave requests anonymous.
+func testApp(t *testing.T, claims *models.Claims) *fiber.App {
+	t.Helper()
+	messages, err := NewMessages("")
+	if err != nil {
+		t.Fatalf("NewMessages: %v", err)
+	}
+	app := fiber.New(fiber.Config{ErrorHandler: messages.ErrorHandler})
+	app.Use(func(c *fiber.Ctx) error {
+		if claims != nil {
+			c.Locals("user", claims)
+		}
+		return c.Next()
+	})
+	return app
+}
+
+// do sends a request with an optional JSON body and returns the response and its body.
+func do(t *testing.T, app *fiber.App, method, target, body string, headers ...string) (*http.Response, string) {
+	t.Helper()
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, target, reader)
+	if body != "" {
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, target, err)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading %s %s: %v", method, target, err)
+	}
+	return resp, string(data)
+}
+
+// decode unmarshals a JSON response body into v.
+func decode(t *testing.T, body string, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal([]byte(body), v); err != nil {
+		t.Fatalf("decoding %q: %v", body, err)
+	}
+}
@@ -0,0 +1,185 @@
+// Path: internal/handlers/holds.go
+package handlers
+
+import (
+	"bank-api/internal/models"
+	"bank-api/internal/services"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PlaceHold reserves funds of an account until the hold is captured or released.
+func (h *Handler) PlaceHold(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	accountID, err := paramID(c, "id")
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid account ID",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	var req models.HoldRequest
+	if err := c.BodyParser(&req); err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	hold, err := bind(c, h.transactionService).PlaceHold(accountID, &req, claims)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Hold failed",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return created(c, fmt.Sprintf("/api/accounts/%d/holds", accountID), hold)
+}
+
+func (h *Handler) GetHolds(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	accountID, err := paramID(c, "id")
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid account ID",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	holds, err := bind(c, h.transactionService).GetHolds(accountID, claims)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve holds",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(holds)
+}
+
+// CaptureHold withdraws up to the held amount and releases the rest.
+func (h *Handler) CaptureHold(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	holdID, err := paramID(c, "id")
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid hold ID",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	// The body is optional: without an amount the whole hold is captured.
+	var req models.CaptureRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return &AppError{
+				Code:    fiber.StatusBadRequest,
+				Message: "Invalid request format",
+				Details: err.Error(),
+				Err:     err,
+			}
+		}
+	}
+
+	hold, err := bind(c, h.transactionService).CaptureHold(holdID, &req, claims)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Capture failed",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	h.dispatchTransaction(claims, "withdraw", *hold.TransactionID, "completed")
+	return c.JSON(hold)
+}
+
+func (h *Handler) ReleaseHold(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	holdID, err := paramID(c, "id")
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid hold ID",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	hold, err := bind(c, h.transactionService).ReleaseHold(holdID, claims)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Release failed",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(hold)
+}
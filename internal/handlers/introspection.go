@@ -0,0 +1,81 @@
+// Path: internal/handlers/introspection.go
+package handlers
+
+import (
+	"bank-api/internal/models"
+	"crypto/subtle"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// introspectionTokenHeader carries the shared secret of services allowed to introspect tokens.
+const introspectionTokenHeader = "X-Introspection-Token"
+
+// IntrospectionAuth lets through services presenting serviceToken in the X-Introspection-Token
+// header, and administrators with a valid bearer token. An empty serviceToken leaves only the
+// administrators.
+func (h *Handler) IntrospectionAuth(serviceToken string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if presented := c.Get(introspectionTokenHeader); serviceToken != "" && presented != "" {
+			if subtle.ConstantTimeCompare([]byte(presented), []byte(serviceToken)) == 1 {
+				return c.Next()
+			}
+			return &AppError{
+				Code:    fiber.StatusUnauthorized,
+				Message: "Invalid token",
+				Details: "The introspection token is not valid",
+			}
+		}
+
+		claims, err := h.authenticate(c)
+		if err != nil {
+			return err
+		}
+		if claims.Role != models.RoleAdmin {
+			return &AppError{
+				Code:    fiber.StatusForbidden,
+				Message: "Access denied",
+				Details: "Administrator role or introspection token required",
+			}
+		}
+		return c.Next()
+	}
+}
+
+// IntrospectToken tells another service whether a token is active and whose it is, in the
+// manner of RFC 7662, so it can accept our tokens without knowing the signing secret.
+// A token in its post-expiry grace period is reported inactive: the grace only covers reads
+// against this API.
+func (h *Handler) IntrospectToken(c *fiber.Ctx) error {
+	var req models.IntrospectionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	claims, err := bind(c, h.authService).ValidateToken(req.Token)
+	if err != nil || claims.Stale {
+		return c.JSON(models.TokenIntrospection{Active: false})
+	}
+
+	introspection := models.TokenIntrospection{
+		Active:    true,
+		UserID:    claims.UserID,
+		Role:      claims.Role,
+		TwoFactor: claims.TwoFactor,
+		AMR:       claims.AMR,
+		Sandbox:   claims.Sandbox,
+		Issuer:    claims.Issuer,
+	}
+	if claims.IssuedAt != nil {
+		introspection.IssuedAt = claims.IssuedAt.Unix()
+	}
+	if claims.ExpiresAt != nil {
+		introspection.ExpiresAt = claims.ExpiresAt.Unix()
+	}
+	return c.JSON(introspection)
+}
@@ -0,0 +1,119 @@
+// Path: internal/handlers/introspection_test.go
+package handlers
+
+import (
+	"bank-api/internal/models"
+	"bank-api/internal/services"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// testJWTSecret signs the tokens of tests using a real auth service.
+const testJWTSecret = "introspection-test-jwt-secret-0123"
+
+// signedToken signs claims with secret the way the auth service does.
+func signedToken(t *testing.T, claims *models.Claims, secret string) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return token
+}
+
+// introspectionApp serves the introspection endpoint the way main does, validating tokens with a
+// real auth service.
+func introspectionApp(t *testing.T, serviceToken string) *fiber.App {
+	t.Helper()
+	auth := services.NewAuthService(nil, testJWTSecret, services.AuthConfig{TokenGracePeriod: time.Hour})
+	h := &Handler{authService: auth}
+	app := testApp(t, nil)
+	app.Post("/api/token/introspect", h.IntrospectionAuth(serviceToken), h.IntrospectToken)
+	return app
+}
+
+func TestIntrospectActiveToken(t *testing.T) {
+	app := introspectionApp(t, "service-secret")
+	issued, expires := time.Now().Add(-time.Minute).Truncate(time.Second), time.Now().Add(time.Hour).Truncate(time.Second)
+	token := signedToken(t, &models.Claims{
+		UserID:    42,
+		Role:      models.RoleUser,
+		TwoFactor: true,
+		AMR:       []string{"pwd", "otp"},
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "bank-api",
+			IssuedAt:  jwt.NewNumericDate(issued),
+			ExpiresAt: jwt.NewNumericDate(expires),
+		},
+	}, testJWTSecret)
+
+	resp, body := do(t, app, fiber.MethodPost, "/api/token/introspect", `{"token": "`+token+`"}`, introspectionTokenHeader, "service-secret")
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("introspect = %d %s", resp.StatusCode, body)
+	}
+	var got models.TokenIntrospection
+	decode(t, body, &got)
+	want := models.TokenIntrospection{
+		Active:    true,
+		UserID:    42,
+		Role:      models.RoleUser,
+		TwoFactor: true,
+		AMR:       []string{"pwd", "otp"},
+		Issuer:    "bank-api",
+		IssuedAt:  issued.Unix(),
+		ExpiresAt: expires.Unix(),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("introspection = %+v, want %+v", got, want)
+	}
+}
+
+func TestIntrospectInactiveTokens(t *testing.T) {
+	app := introspectionApp(t, "service-secret")
+	claims := func(expires time.Time) *models.Claims {
+		return &models.Claims{UserID: 42, RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(expires)}}
+	}
+	for _, tt := range []struct{ name, token string }{
+		{"expired", signedToken(t, claims(time.Now().Add(-2*time.Hour)), testJWTSecret)},
+		{"in its grace period", signedToken(t, claims(time.Now().Add(-time.Minute)), testJWTSecret)},
+		{"signed with another key", signedToken(t, claims(time.Now().Add(time.Hour)), "some-other-secret-of-enough-length")},
+		{"malformed", "not-a-jwt"},
+	} {
+		resp, body := do(t, app, fiber.MethodPost, "/api/token/introspect", `{"token": "`+tt.token+`"}`, introspectionTokenHeader, "service-secret")
+		if resp.StatusCode != fiber.StatusOK || body != `{"active":false}` {
+			t.Errorf("%s: %d %s, want 200 with only active false", tt.name, resp.StatusCode, body)
+		}
+	}
+}
+
+func TestIntrospectionAccessControl(t *testing.T) {
+	token := func(role string) string {
+		return "Bearer " + signedToken(t, &models.Claims{UserID: 1, Role: role, RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		}}, testJWTSecret)
+	}
+	body := `{"token": "not-a-jwt"}`
+	for _, tt := range []struct {
+		name         string
+		serviceToken string
+		headers      []string
+		want         int
+	}{
+		{"service token", "service-secret", []string{introspectionTokenHeader, "service-secret"}, 200},
+		{"wrong service token", "service-secret", []string{introspectionTokenHeader, "guess"}, 401},
+		{"administrator", "service-secret", []string{fiber.HeaderAuthorization, token(models.RoleAdmin)}, 200},
+		{"regular user", "service-secret", []string{fiber.HeaderAuthorization, token(models.RoleUser)}, 403},
+		{"anonymous", "service-secret", nil, 401},
+		{"service token not configured", "", []string{introspectionTokenHeader, "service-secret"}, 401},
+		{"administrator without a service token configured", "", []string{fiber.HeaderAuthorization, token(models.RoleAdmin)}, 200},
+	} {
+		app := introspectionApp(t, tt.serviceToken)
+		if resp, got := do(t, app, fiber.MethodPost, "/api/token/introspect", body, tt.headers...); resp.StatusCode != tt.want {
+			t.Errorf("%s: %d %s, want %d", tt.name, resp.StatusCode, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+// Path: internal/handlers/json.go
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// StringIDs encodes v like encoding/json, but writes the numbers of ID fields as strings: "id",
+// keys ending in "_id" or "ID", and the elements of arrays under keys ending in "_ids".
+// JavaScript clients parse JSON numbers as doubles and silently lose precision above 2^53.
+// Use it as the JSONEncoder of the app; field order is preserved.
+func StringIDs(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return quoteIDs(data)
+}
+
+// jsonContainer is an object or array being rewritten by quoteIDs.
+type jsonContainer struct {
+	object    bool
+	key       string // Key the container is the value of, if any
+	expectKey bool   // Objects: the next token is a key
+	items     int
+}
+
+func quoteIDs(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var out bytes.Buffer
+	var stack []*jsonContainer
+	key := ""
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var top *jsonContainer
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+
+		if delim, ok := tok.(json.Delim); ok && (delim == '}' || delim == ']') {
+			out.WriteByte(byte(delim))
+			stack = stack[:len(stack)-1]
+			valueDone(stack)
+			continue
+		}
+
+		if top != nil && top.object && top.expectKey {
+			if top.items > 0 {
+				out.WriteByte(',')
+			}
+			key = tok.(string)
+			writeJSON(&out, key)
+			out.WriteByte(':')
+			top.expectKey = false
+			continue
+		}
+		if top != nil && !top.object && top.items > 0 {
+			out.WriteByte(',')
+		}
+
+		switch t := tok.(type) {
+		case json.Delim:
+			out.WriteByte(byte(t))
+			container := &jsonContainer{object: t == '{', expectKey: t == '{'}
+			if top != nil && top.object {
+				container.key = key
+			}
+			stack = append(stack, container)
+			continue
+		case json.Number:
+			quote := top != nil && ((top.object && isIDKey(key)) || (!top.object && strings.HasSuffix(top.key, "_ids")))
+			if quote {
+				writeJSON(&out, t.String())
+			} else {
+				out.WriteString(t.String())
+			}
+		default:
+			writeJSON(&out, t)
+		}
+		valueDone(stack)
+	}
+
+	return out.Bytes(), nil
+}
+
+// valueDone records a completed value in the innermost container.
+func valueDone(stack []*jsonContainer) {
+	if len(stack) == 0 {
+		return
+	}
+	top := stack[len(stack)-1]
+	top.items++
+	if top.object {
+		top.expectKey = true
+	}
+}
+
+func writeJSON(out *bytes.Buffer, v interface{}) {
+	data, _ := json.Marshal(v) // Strings, bools and nil always encode.
+	out.Write(data)
+}
+
+func isIDKey(key string) bool {
+	return key == "id" || strings.HasSuffix(key, "_id") || strings.HasSuffix(key, "ID")
+}
@@ -0,0 +1,67 @@
+// Path: internal/handlers/json_test.go
+package handlers
+
+import (
+	"bank-api/internal/models"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestStringIDsQuotesOnlyIDs(t *testing.T) {
+	fromID := 9007199254740993 // 2^53 + 1, which a double can't hold
+	tests := []struct {
+		name string
+		in   interface{}
+		want string
+	}{
+		{"flat", map[string]interface{}{"id": 7}, `{"id":"7"}`},
+		{"suffixes", fiber.Map{"user_id": 1, "transactionID": 2, "amount": 3.5, "idle": 4}, `{"amount":3.5,"idle":4,"transactionID":"2","user_id":"1"}`},
+		{"nested and arrays", fiber.Map{"items": []fiber.Map{{"id": 1, "fee": 0}, {"id": 2, "fee": 1}}}, `{"items":[{"fee":0,"id":"1"},{"fee":1,"id":"2"}]}`},
+		{"id lists", fiber.Map{"account_ids": []int{1, 2}, "amounts": []int{1, 2}}, `{"account_ids":["1","2"],"amounts":[1,2]}`},
+		{"nulls and strings", fiber.Map{"to_id": nil, "id": "tx-1", "flag": true}, `{"flag":true,"id":"tx-1","to_id":null}`},
+		{"large IDs keep every digit", models.Transaction{FromAccountID: &fromID}, ""},
+		{"top-level array", []int{1, 2}, `[1,2]`},
+	}
+	for _, tt := range tests {
+		got, err := StringIDs(tt.in)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if tt.want == "" {
+			var decoded struct {
+				FromAccountID string `json:"from_account_id"`
+			}
+			decode(t, string(got), &decoded)
+			if decoded.FromAccountID != "9007199254740993" {
+				t.Errorf("%s: from_account_id = %q", tt.name, decoded.FromAccountID)
+			}
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s: %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStringIDsAsTheAppEncoder(t *testing.T) {
+	account := models.Account{ID: 42, UserID: 7, Balance: 10}
+	for _, tt := range []struct {
+		encoder func(interface{}) ([]byte, error)
+		id      interface{}
+	}{
+		{nil, float64(42)},
+		{StringIDs, "42"},
+	} {
+		app := fiber.New(fiber.Config{JSONEncoder: tt.encoder})
+		app.Get("/account", func(c *fiber.Ctx) error { return c.JSON(account) })
+
+		_, body := do(t, app, "GET", "/account", "")
+		var got map[string]interface{}
+		decode(t, body, &got)
+		if got["id"] != tt.id || got["balance"] != float64(10) {
+			t.Errorf("body = %s, want id %#v and a numeric balance", body, tt.id)
+		}
+	}
+}
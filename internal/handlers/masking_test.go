@@ -0,0 +1,58 @@
+// Path: internal/handlers/masking_test.go
+package handlers
+
+import (
+	"bank-api/internal/models"
+	"bank-api/internal/services"
+	"bank-api/pkg/utils"
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+type maskingAccounts struct {
+	services.AccountService
+	accounts []models.Account
+}
+
+func (s *maskingAccounts) GetAccounts(userID uint, sandbox bool) ([]models.Account, error) {
+	return s.accounts, nil
+}
+
+func (s *maskingAccounts) GetAccount(claims *models.Claims, accountID int) (*models.Account, error) {
+	return nil, &services.AppError{Code: 500, Message: "Balance integrity check failed", Details: "account_id: 1234567"}
+}
+
+// TestOwnerResponsesUnmaskedLogsMasked checks that masking only applies to the logs: the owner
+// gets full identifiers, the log line of the same request doesn't.
+func TestOwnerResponsesUnmaskedLogsMasked(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(utils.MaskingWriter{W: &logs})
+	defer log.SetOutput(os.Stderr)
+
+	number := "0001234567"
+	h := &Handler{accountService: &maskingAccounts{accounts: []models.Account{{ID: 1234567, Number: &number, Currency: "USD"}}}}
+	app := testApp(t, &models.Claims{UserID: 1})
+	app.Get("/accounts", h.GetAccounts)
+	app.Get("/accounts/:id", h.GetAccount)
+
+	resp, body := do(t, app, "GET", "/accounts", "")
+	if resp.StatusCode != 200 {
+		t.Fatalf("GET /accounts = %d: %s", resp.StatusCode, body)
+	}
+	var accounts []models.Account
+	decode(t, body, &accounts)
+	if len(accounts) != 1 || accounts[0].ID != 1234567 || *accounts[0].Number != number {
+		t.Errorf("owner got %s, want the account unmasked", body)
+	}
+
+	resp, body = do(t, app, "GET", "/accounts/1234567", "")
+	if resp.StatusCode != 500 || !strings.Contains(body, "account_id: 1234567") {
+		t.Errorf("GET /accounts/1234567 = %d %s, want the unmasked details", resp.StatusCode, body)
+	}
+	if strings.Contains(logs.String(), "1234567") || !strings.Contains(logs.String(), "account_id: ***4567") {
+		t.Errorf("log %q, want the account ID masked", logs.String())
+	}
+}
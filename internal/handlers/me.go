@@ -0,0 +1,431 @@
+// Path: internal/handlers/me.go
+package handlers
+
+import (
+	"bank-api/internal/models"
+	"bank-api/internal/services"
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) GetNetWorth(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	netWorth, err := bind(c, h.accountService).GetNetWorth(claims.UserID, claims.Sandbox)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to calculate net worth",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(netWorth)
+}
+
+// GetVelocity reports how much the caller moved out in the last hour and day.
+func (h *Handler) GetVelocity(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	velocity, err := bind(c, h.transactionService).Velocity(claims.UserID, claims.Sandbox)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to calculate velocity",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(velocity)
+}
+
+// GetTokenInfo reports when the caller's token was issued and expires, with the server time, so
+// the client can log in again in time even if its own clock is off.
+func (h *Handler) GetTokenInfo(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	return c.JSON(bind(c, h.authService).TokenInfo(claims))
+}
+
+func (h *Handler) GetLoginHistory(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	var filter models.LoginHistoryFilter
+	for name, dst := range map[string]*int{"limit": &filter.Limit, "offset": &filter.Offset} {
+		if v := c.Query(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return &AppError{
+					Code:    fiber.StatusBadRequest,
+					Message: "Invalid query parameter",
+					Details: name + ": " + err.Error(),
+					Err:     err,
+				}
+			}
+			*dst = n
+		}
+	}
+
+	attempts, err := bind(c, h.authService).LoginHistory(claims.UserID, filter)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve login history",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(attempts)
+}
+
+func (h *Handler) GetRoundUp(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	settings, err := bind(c, h.accountService).GetRoundUp(claims.UserID)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve round-up settings",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(settings)
+}
+
+func (h *Handler) SetRoundUp(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	var req models.RoundUpSettings
+	if err := c.BodyParser(&req); err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	settings, err := bind(c, h.accountService).SetRoundUp(claims.UserID, &req)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to update round-up settings",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(settings)
+}
+
+func (h *Handler) GetStatementSettings(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	settings, err := bind(c, h.statementService).GetSettings(claims.UserID)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve statement settings",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(settings)
+}
+
+func (h *Handler) SetStatementSettings(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	var req models.StatementSettings
+	if err := c.BodyParser(&req); err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	settings, err := bind(c, h.statementService).SetSettings(claims.UserID, &req)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to update statement settings",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(settings)
+}
+
+// ExportData streams the user's data portability archive as a JSON attachment.
+func (h *Handler) ExportData(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	write, err := h.exportService.Export(claims.UserID)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to export user data",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	userID := claims.UserID
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="bankx-export.json"`)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		// The status is already sent: a failure can only truncate the archive.
+		if err := write(w); err != nil {
+			log.Printf("Export of user %d failed: %v", userID, err)
+		}
+	})
+	return nil
+}
+
+// statementDateLayout is the format of the from and to query parameters of account statements.
+const statementDateLayout = "2006-01-02"
+
+// GetAccountStatement streams an account statement for accounting software. The period is
+// ?from=YYYY-MM-DD to ?to=YYYY-MM-DD inclusive, by default the last month up to today.
+func (h *Handler) GetAccountStatement(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	accountID, err := paramID(c, "id")
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid account ID",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	now := h.now().UTC()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	from := to.AddDate(0, -1, 0)
+	for name, dst := range map[string]*time.Time{"from": &from, "to": &to} {
+		if v := c.Query(name); v != "" {
+			t, err := time.Parse(statementDateLayout, v)
+			if err != nil {
+				return &AppError{
+					Code:    fiber.StatusBadRequest,
+					Message: "Invalid query parameter",
+					Details: name + ": expected YYYY-MM-DD",
+					Err:     err,
+				}
+			}
+			*dst = t
+		}
+	}
+	to = to.AddDate(0, 0, 1) // The last day is included.
+
+	format := c.Query("format", services.StatementFormatOFX)
+	write, err := h.exportService.AccountStatement(claims.UserID, accountID, from, to, format)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to generate statement",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	contentType := "application/x-ofx"
+	if format == services.StatementFormatQIF {
+		contentType = "application/qif"
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="statement-%d.%s"`, accountID, format))
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		// The status is already sent: a failure can only truncate the statement.
+		if err := write(w); err != nil {
+			log.Printf("Statement of account %d failed: %v", accountID, err)
+		}
+	})
+	return nil
+}
+
+func (h *Handler) GetLimits(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	limits, err := bind(c, h.transactionService).GetLimits(claims.UserID)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve spending limits",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(limits)
+}
+
+func (h *Handler) SetLimits(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	var req models.SpendingLimits
+	if err := c.BodyParser(&req); err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	limits, err := bind(c, h.transactionService).SetLimits(claims.UserID, &req)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to update spending limits",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(limits)
+}
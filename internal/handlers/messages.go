@@ -0,0 +1,270 @@
+// Path: internal/handlers/messages.go
+package handlers
+
+import (
+	"bank-api/internal/services"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// defaultLanguage is the language errors are written in, and the fallback for every other one.
+const defaultLanguage = "en"
+
+// builtinMessages translates the errors clients can act on, keyed by error code. Internal
+// failures stay in English: they are for the logs and support, not for the user.
+var builtinMessages = map[string]map[string]string{
+	"ru": {
+		"2fa_already_set_up":                        "2FA уже настроена",
+		"2fa_not_set_up":                            "2FA не настроена",
+		"2fa_required":                              "Требуется подтверждение 2FA",
+		"access_denied":                             "Доступ запрещён",
+		"acceptance_failed":                         "Не удалось принять перевод",
+		"account_dormant":                           "Счёт неактивен",
+		"account_frozen":                            "Счёт заморожен",
+		"account_is_not_dormant":                    "Счёт не является неактивным",
+		"account_is_not_frozen":                     "Счёт не заморожен",
+		"account_is_too_new_for_outgoing_payments":  "Счёт слишком новый для исходящих платежей",
+		"account_not_found":                         "Счёт не найден",
+		"account_not_found_or_access_denied":        "Счёт не найден или доступ запрещён",
+		"adjustment_below_account_floor":            "Корректировка опускает баланс ниже допустимого",
+		"approval_failed":                           "Не удалось одобрить перевод",
+		"balance_integrity_check_failed":            "Нарушена целостность баланса",
+		"batch_not_found":                           "Пакет не найден",
+		"batch_transfer_failed":                     "Не удалось выполнить пакетный перевод",
+		"beneficiary_account_not_found":             "Счёт наследника не найден",
+		"beneficiary_already_named":                 "Наследник уже указан",
+		"beneficiary_shares_must_total_100_percent": "Доли наследников должны составлять 100 процентов",
+		"cancellation_failed":                       "Не удалось отменить перевод",
+		"confirmation_required":                     "Требуется подтверждение",
+		"currency_mismatch":                         "Валюты счетов не совпадают",
+		"daily_spending_limit_exceeded":             "Превышен дневной лимит расходов",
+		"decline_failed":                            "Не удалось отклонить перевод",
+		"delivery_not_found":                        "Доставка не найдена",
+		"deposit_failed":                            "Не удалось пополнить счёт",
+		"destination_account_not_found":             "Счёт зачисления не найден",
+		"destination_is_required":                   "Не указан получатель",
+		"destination_not_whitelisted":               "Счёт получателя не входит в белый список",
+		"feature_disabled":                          "Функция отключена",
+		"hold_is_no_longer_active":                  "Блокировка уже снята или списана",
+		"hold_not_found":                            "Блокировка не найдена",
+		"https_required":                            "Требуется HTTPS",
+		"insufficient_funds":                        "Недостаточно средств",
+		"internal_server_error":                     "Внутренняя ошибка сервера",
+		"invalid_2fa_code":                          "Неверный код 2FA",
+		"invalid_account_id":                        "Некорректный ID счёта",
+		"invalid_account_number":                    "Некорректный номер счёта",
+		"invalid_amount":                            "Некорректная сумма",
+		"invalid_confirmation_code":                 "Неверный код подтверждения",
+		"invalid_credentials":                       "Неверный логин или пароль",
+		"invalid_cursor":                            "Некорректный курсор",
+		"invalid_description":                       "Некорректное описание",
+		"invalid_fields":                            "Некорректные поля",
+		"invalid_money_request":                     "Некорректный запрос денег",
+		"invalid_query_parameter":                   "Некорректный параметр запроса",
+		"invalid_request_format":                    "Некорректный формат запроса",
+		"invalid_request_id":                        "Некорректный ID запроса",
+		"invalid_token":                             "Недействительный токен",
+		"invalid_transfer":                          "Некорректный перевод",
+		"login_failed":                              "Не удалось войти",
+		"method_not_allowed":                        "Метод не поддерживается",
+		"missing_token":                             "Не передан токен",
+		"not_found":                                 "Не найдено",
+		"money_request_failed":                      "Не удалось запросить деньги",
+		"money_request_has_expired":                 "Срок запроса денег истёк",
+		"money_request_is_no_longer_pending":        "Запрос денег уже обработан",
+		"money_request_not_found":                   "Запрос денег не найден",
+		"nothing_to_distribute":                     "Нечего распределять",
+		"offset_too_large":                          "Слишком большое смещение",
+		"only_completed_transfers_can_be_refunded":  "Вернуть можно только завершённый перевод",
+		"only_pending_transfers_can_be_cancelled":   "Отменить можно только ожидающий перевод",
+		"payee_account_not_found":                   "Счёт получателя не найден",
+		"payee_already_exists":                      "Получатель уже существует",
+		"payee_not_found":                           "Получатель не найден",
+		"payment_failed":                            "Не удалось выполнить платёж",
+		"pending_transfer_not_found":                "Ожидающий перевод не найден",
+		"refund_exceeds_the_transferred_amount":     "Сумма возврата больше суммы перевода",
+		"registration_failed":                       "Не удалось зарегистрироваться",
+		"rejection_failed":                          "Не удалось отклонить",
+		"request_entity_too_large":                  "Слишком большой запрос",
+		"request_timed_out":                         "Время ожидания запроса истекло",
+		"savings_withdrawal_limit_reached":          "Исчерпан лимит снятий со сберегательного счёта",
+		"self_approval_is_not_allowed":              "Нельзя одобрить собственный перевод",
+		"shares_exceed_100_percent":                 "Сумма долей больше 100 процентов",
+		"source_account_is_required":                "Не указан счёт списания",
+		"source_account_not_found_or_access_denied": "Счёт списания не найден или доступ запрещён",
+		"token_expired":                             "Срок действия токена истёк",
+		"token_signed_with_a_different_key":         "Токен подписан другим ключом, войдите заново",
+		"too_many_beneficiaries":                    "Слишком много наследников",
+		"too_many_concurrent_requests":              "Слишком много одновременных запросов",
+		"too_many_payees":                           "Слишком много получателей",
+		"too_many_requests":                         "Слишком много запросов",
+		"too_many_tags":                             "Слишком много тегов",
+		"transaction_not_found_or_access_denied":    "Операция не найдена или доступ запрещён",
+		"transfer_failed":                           "Не удалось выполнить перевод",
+		"transfer_is_no_longer_pending":             "Перевод уже обработан",
+		"transfer_not_found":                        "Перевод не найден",
+		"transfers_disabled":                        "Переводы отключены",
+		"transfers_to_other_users_are_disabled":     "Переводы другим пользователям отключены",
+		"unsupported_currency_pair":                 "Валютная пара не поддерживается",
+		"user_already_exists":                       "Пользователь уже существует",
+		"user_not_found":                            "Пользователь не найден",
+		"validation_failed":                         "Ошибка проверки данных",
+		"webhook_not_found":                         "Вебхук не найден",
+		"whitelisted_account_not_found":             "Счёт из белого списка не найден",
+		"withdrawal_failed":                         "Не удалось снять средства",
+	},
+}
+
+// Messages localizes error messages into the languages of its catalog.
+type Messages struct {
+	catalogs  map[string]map[string]string // Language -> error code -> message
+	languages []string                     // Languages with a catalog, sorted
+}
+
+// NewMessages returns the built-in catalog, extended with the JSON file at path if it isn't empty.
+// The file maps languages to error codes to messages, e.g. {"de": {"insufficient_funds": "..."}};
+// its messages take precedence over the built-in ones.
+func NewMessages(path string) (*Messages, error) {
+	m := &Messages{catalogs: make(map[string]map[string]string)}
+	for language, catalog := range builtinMessages {
+		m.add(language, catalog)
+	}
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var extra map[string]map[string]string
+		if err := json.Unmarshal(data, &extra); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+		for language, catalog := range extra {
+			m.add(strings.ToLower(language), catalog)
+		}
+	}
+
+	for language := range m.catalogs {
+		m.languages = append(m.languages, language)
+	}
+	sort.Strings(m.languages)
+	return m, nil
+}
+
+func (m *Messages) add(language string, catalog map[string]string) {
+	if m.catalogs[language] == nil {
+		m.catalogs[language] = make(map[string]string)
+	}
+	for code, message := range catalog {
+		m.catalogs[language][code] = message
+	}
+}
+
+// Localize returns the message of the error code in language, or message itself when the
+// language or the code has no translation.
+func (m *Messages) Localize(language, code, message string) string {
+	if translated, ok := m.catalogs[language][code]; ok {
+		return translated
+	}
+	return message
+}
+
+// Language picks the language of the response from an Accept-Language header: the supported
+// language with the highest weight, matching on the primary subtag, so "ru-RU" gets "ru".
+// Without a supported language it is English.
+func (m *Messages) Language(header string) string {
+	best, bestQ := defaultLanguage, 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+
+		language, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if q <= bestQ || (language != defaultLanguage && m.catalogs[language] == nil) {
+			continue
+		}
+		best, bestQ = language, q
+	}
+	return best
+}
+
+// ErrorCode derives the machine-readable code of an error from its English message, e.g.
+// "Insufficient funds" is "insufficient_funds". Clients should branch on the code: unlike
+// the message it doesn't depend on the language.
+func ErrorCode(message string) string {
+	var b strings.Builder
+	separate := false
+	for _, r := range strings.ToLower(message) {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			separate = b.Len() > 0
+			continue
+		}
+		if separate {
+			b.WriteByte('_')
+			separate = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// ErrorHandler renders errors as {"code", "error", "details"}, with the message in the language
+// asked for by Accept-Language. Field errors of validation failures are listed in details.
+func (m *Messages) ErrorHandler(c *fiber.Ctx, err error) error {
+	log.Printf("Error: %v", err)
+
+	code := fiber.StatusInternalServerError
+	message := "Internal Server Error"
+	errorCode := ""
+	var details interface{} = ""
+
+	var appErr *AppError
+	var svcErr *services.AppError
+	if errors.As(err, &appErr) {
+		code = appErr.Code
+		message = appErr.Message
+		details = appErr.Details
+	} else if errors.As(err, &svcErr) {
+		code = svcErr.Code
+		message = svcErr.Message
+		details = svcErr.Details
+		if len(svcErr.Fields) > 0 {
+			details = svcErr.Fields
+		}
+	} else if e, ok := err.(*fiber.Error); ok {
+		// Fiber's messages can name the request, as in "Cannot GET /x": the status names the error.
+		code = e.Code
+		message = e.Message
+		errorCode = ErrorCode(utils.StatusMessage(e.Code))
+	} else {
+		details = err.Error()
+	}
+	if errorCode == "" {
+		errorCode = ErrorCode(message)
+	}
+
+	language := m.Language(c.Get(fiber.HeaderAcceptLanguage))
+	c.Set(fiber.HeaderContentLanguage, language)
+	c.Vary(fiber.HeaderAcceptLanguage)
+	return c.Status(code).JSON(fiber.Map{
+		"code":    errorCode,
+		"error":   m.Localize(language, errorCode, message),
+		"details": details,
+	})
+}
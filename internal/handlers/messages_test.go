@@ -0,0 +1,103 @@
+// Path: internal/handlers/messages_test.go
+package handlers
+
+import (
+	"bank-api/internal/services"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestErrorCode(t *testing.T) {
+	for message, want := range map[string]string{
+		"Insufficient funds":                        "insufficient_funds",
+		"Account not found or access denied":        "account_not_found_or_access_denied",
+		"Beneficiary shares must total 100 percent": "beneficiary_shares_must_total_100_percent",
+		"  Invalid 2FA code!  ":                     "invalid_2fa_code",
+		"Account is too new for outgoing payments.": "account_is_too_new_for_outgoing_payments",
+	} {
+		if got := ErrorCode(message); got != want {
+			t.Errorf("ErrorCode(%q) = %q, want %q", message, got, want)
+		}
+	}
+}
+
+func TestLanguageFromAcceptLanguage(t *testing.T) {
+	messages, err := NewMessages("")
+	if err != nil {
+		t.Fatalf("NewMessages: %v", err)
+	}
+	for header, want := range map[string]string{
+		"":                             "en",
+		"ru":                           "ru",
+		"ru-RU,ru;q=0.9":               "ru",
+		"fr-FR, fr;q=0.9":              "en",
+		"en;q=0.9, ru;q=0.5":           "en",
+		"de;q=0.9, ru;q=0.8, en;q=0.1": "ru",
+		"ru;q=bad":                     "en",
+	} {
+		if got := messages.Language(header); got != want {
+			t.Errorf("Language(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestErrorsInTheClientsLanguage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messages.json")
+	catalog := `{"DE": {"insufficient_funds": "Unzureichende Deckung"}, "ru": {"insufficient_funds": "Не хватает денег"}}`
+	if err := os.WriteFile(path, []byte(catalog), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	messages, err := NewMessages(path)
+	if err != nil {
+		t.Fatalf("NewMessages: %v", err)
+	}
+	app := fiber.New(fiber.Config{ErrorHandler: messages.ErrorHandler})
+	app.Post("/api/withdraw", func(c *fiber.Ctx) error {
+		return &services.AppError{Code: fiber.StatusUnprocessableEntity, Message: "Insufficient funds", Details: "account_id: 7"}
+	})
+	app.Get("/api/accounts", func(c *fiber.Ctx) error {
+		return &AppError{Code: fiber.StatusNotFound, Message: "Account not found"}
+	})
+
+	for _, tt := range []struct {
+		method, target, language string
+		want, contentLanguage    string
+	}{
+		{fiber.MethodPost, "/api/withdraw", "", "Insufficient funds", "en"},
+		{fiber.MethodPost, "/api/withdraw", "de-DE", "Unzureichende Deckung", "de"},
+		{fiber.MethodPost, "/api/withdraw", "ru", "Не хватает денег", "ru"}, // The file overrides the built-in message
+		{fiber.MethodPost, "/api/withdraw", "fr", "Insufficient funds", "en"},
+		{fiber.MethodGet, "/api/accounts", "ru", "Счёт не найден", "ru"},
+		{fiber.MethodGet, "/api/accounts", "de", "Account not found", "de"}, // Untranslated in German
+	} {
+		resp, body := do(t, app, tt.method, tt.target, "", fiber.HeaderAcceptLanguage, tt.language)
+		var got struct {
+			Code  string `json:"code"`
+			Error string `json:"error"`
+		}
+		decode(t, body, &got)
+		// The code stays the same in every language.
+		wantCode := map[string]string{"/api/withdraw": "insufficient_funds", "/api/accounts": "account_not_found"}[tt.target]
+		if got.Error != tt.want || got.Code != wantCode {
+			t.Errorf("%s in %q = %+v, want %q with code %s", tt.target, tt.language, got, tt.want, wantCode)
+		}
+		if lang := resp.Header.Get(fiber.HeaderContentLanguage); lang != tt.contentLanguage {
+			t.Errorf("%s in %q: Content-Language %q, want %q", tt.target, tt.language, lang, tt.contentLanguage)
+		}
+	}
+}
+
+func TestNewMessagesRejectsABadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messages.json")
+	if err := os.WriteFile(path, []byte(`{"de": "not a catalog"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, bad := range []string{path, filepath.Join(t.TempDir(), "missing.json")} {
+		if _, err := NewMessages(bad); err == nil {
+			t.Errorf("NewMessages(%s) succeeded", bad)
+		}
+	}
+}
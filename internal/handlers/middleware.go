@@ -0,0 +1,169 @@
+// Path: internal/handlers/middleware.go
+package handlers
+
+import (
+	"bank-api/internal/models"
+	"bank-api/internal/services"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+// Compress compresses responses (brotli/gzip/deflate, negotiated via Accept-Encoding)
+// whose body is at least minSize bytes. Smaller responses are sent as is, since
+// compressing them costs more CPU than it saves bandwidth. Streamed responses are
+// left alone so they aren't buffered.
+func Compress(minSize int) fiber.Handler {
+	compressor := fasthttp.CompressHandlerBrotliLevel(func(*fasthttp.RequestCtx) {},
+		fasthttp.CompressBrotliDefaultCompression,
+		fasthttp.CompressDefaultCompression,
+	)
+
+	return func(c *fiber.Ctx) error {
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		if c.Response().IsBodyStream() || len(c.Response().Body()) < minSize {
+			return nil
+		}
+		compressor(c.Context())
+		return nil
+	}
+}
+
+// Timeout bounds request handling time. The request context carries the deadline, and services
+// bound to it cancel their queries when it passes, so a handler that fails after the deadline is
+// reported as 504. A handler that still succeeds late keeps its response: its work
+// is committed and the client must not retry it.
+func Timeout(timeout time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithTimeout(c.UserContext(), timeout)
+		defer cancel()
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return &AppError{
+				Code:    fiber.StatusGatewayTimeout,
+				Message: "Request timed out",
+				Details: fmt.Sprintf("Request exceeded %s", timeout),
+				Err:     err,
+			}
+		}
+		return err
+	}
+}
+
+// inFlight counts the requests currently being handled per key.
+type inFlight struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (f *inFlight) acquire(key string, limit int) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.counts[key] >= limit {
+		return false
+	}
+	f.counts[key]++
+	return true
+}
+
+func (f *inFlight) release(key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.counts[key]--; f.counts[key] <= 0 {
+		delete(f.counts, key)
+	}
+}
+
+// ConcurrencyLimit caps the requests handled at once per user and per client IP, answering 429
+// instead of queueing the excess on the locked account rows. Zero disables a limit. Meant for
+// money-movement routes, after AuthMiddleware.
+func ConcurrencyLimit(perUser, perIP int) fiber.Handler {
+	users := &inFlight{counts: map[string]int{}}
+	ips := &inFlight{counts: map[string]int{}}
+
+	tooMany := func(c *fiber.Ctx, details string) error {
+		c.Set(fiber.HeaderRetryAfter, "1")
+		return &AppError{
+			Code:    fiber.StatusTooManyRequests,
+			Message: "Too many concurrent requests",
+			Details: details,
+		}
+	}
+
+	return func(c *fiber.Ctx) error {
+		if perIP > 0 {
+			ip := c.IP()
+			if !ips.acquire(ip, perIP) {
+				return tooMany(c, fmt.Sprintf("At most %d requests from one IP may be in flight", perIP))
+			}
+			defer ips.release(ip)
+		}
+
+		if claims, ok := c.Locals("user").(*models.Claims); ok && perUser > 0 {
+			user := strconv.FormatUint(uint64(claims.UserID), 10)
+			if !users.acquire(user, perUser) {
+				return tooMany(c, fmt.Sprintf("At most %d requests per user may be in flight", perUser))
+			}
+			defer users.release(user)
+		}
+
+		return c.Next()
+	}
+}
+
+// RejectFields answers 400 to JSON bodies carrying any of the given server-assigned fields, at any
+// depth, so a forged value such as a back- or future-dated created_at is refused rather than
+// silently dropped. Malformed bodies are left to the handler.
+func RejectFields(fields ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		body := c.Body()
+		if len(body) == 0 || !c.Is("json") {
+			return c.Next()
+		}
+
+		var decoded interface{}
+		if err := json.Unmarshal(body, &decoded); err != nil {
+			return c.Next()
+		}
+
+		var found []services.FieldError
+		seen := map[string]bool{}
+		var walk func(v interface{})
+		walk = func(v interface{}) {
+			switch v := v.(type) {
+			case map[string]interface{}:
+				for _, field := range fields {
+					if _, ok := v[field]; ok && !seen[field] {
+						seen[field] = true
+						found = append(found, services.FieldError{Field: field, Message: "Assigned by the server and can't be set"})
+					}
+				}
+				for _, child := range v {
+					walk(child)
+				}
+			case []interface{}:
+				for _, child := range v {
+					walk(child)
+				}
+			}
+		}
+		walk(decoded)
+
+		if len(found) > 0 {
+			return &services.AppError{Code: fiber.StatusBadRequest, Message: "Validation failed", Details: "Request sets server-assigned fields", Fields: found}
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,240 @@
+// Path: internal/handlers/middleware_test.go
+package handlers
+
+import (
+	"bank-api/internal/models"
+	"bank-api/internal/services"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/etag"
+)
+
+func TestCompressHonoursAcceptEncoding(t *testing.T) {
+	large := strings.Repeat(`{"id":1,"balance":100}`, 200)
+	app := testApp(t, nil)
+	app.Use(Compress(1024))
+	app.Get("/large", func(c *fiber.Ctx) error { return c.SendString(large) })
+	app.Get("/small", func(c *fiber.Ctx) error { return c.SendString("{}") })
+
+	resp, body := do(t, app, fiber.MethodGet, "/large", "", fiber.HeaderAcceptEncoding, "gzip")
+	if got := resp.Header.Get(fiber.HeaderContentEncoding); got != "gzip" {
+		t.Errorf("Content-Encoding = %q, want gzip", got)
+	}
+	if len(body) >= len(large) {
+		t.Errorf("compressed body is %d bytes, plain is %d", len(body), len(large))
+	}
+
+	resp, body = do(t, app, fiber.MethodGet, "/large", "")
+	if got := resp.Header.Get(fiber.HeaderContentEncoding); got != "" || body != large {
+		t.Errorf("without Accept-Encoding: Content-Encoding %q, body of %d bytes", got, len(body))
+	}
+
+	resp, body = do(t, app, fiber.MethodGet, "/small", "", fiber.HeaderAcceptEncoding, "gzip")
+	if got := resp.Header.Get(fiber.HeaderContentEncoding); got != "" || body != "{}" {
+		t.Errorf("small body: Content-Encoding %q, body %q", got, body)
+	}
+}
+
+func TestETagAnswersNotModified(t *testing.T) {
+	app := testApp(t, nil)
+	app.Use(Compress(0))
+	app.Use(etag.New())
+	app.Get("/accounts", func(c *fiber.Ctx) error { return c.SendString(`[{"id":1}]`) })
+
+	resp, _ := do(t, app, fiber.MethodGet, "/accounts", "")
+	tag := resp.Header.Get(fiber.HeaderETag)
+	if tag == "" {
+		t.Fatal("no ETag on the first response")
+	}
+
+	resp, body := do(t, app, fiber.MethodGet, "/accounts", "", fiber.HeaderIfNoneMatch, tag)
+	if resp.StatusCode != fiber.StatusNotModified || body != "" {
+		t.Errorf("matching If-None-Match: %d %q, want 304 with no body", resp.StatusCode, body)
+	}
+
+	resp, _ = do(t, app, fiber.MethodGet, "/accounts", "", fiber.HeaderIfNoneMatch, `"stale"`)
+	if resp.StatusCode != fiber.StatusOK {
+		t.Errorf("stale If-None-Match: %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestTimeoutAnswersGatewayTimeout(t *testing.T) {
+	app := testApp(t, nil)
+	app.Use(Timeout(20 * time.Millisecond))
+	app.Get("/slow", func(c *fiber.Ctx) error {
+		<-c.UserContext().Done() // A query cut off by the deadline
+		return c.UserContext().Err()
+	})
+	app.Get("/late", func(c *fiber.Ctx) error {
+		time.Sleep(40 * time.Millisecond) // Committed work finishing after the deadline
+		return c.SendString("done")
+	})
+	app.Get("/fast", func(c *fiber.Ctx) error { return c.SendString("ok") })
+
+	resp, body := do(t, app, fiber.MethodGet, "/slow", "")
+	if resp.StatusCode != fiber.StatusGatewayTimeout || !strings.Contains(body, "20ms") {
+		t.Errorf("slow handler = %d %s, want 504", resp.StatusCode, body)
+	}
+	if resp, body := do(t, app, fiber.MethodGet, "/late", ""); resp.StatusCode != fiber.StatusOK || body != "done" {
+		t.Errorf("late success = %d %s, want its response kept", resp.StatusCode, body)
+	}
+	if resp, body := do(t, app, fiber.MethodGet, "/fast", ""); resp.StatusCode != fiber.StatusOK || body != "ok" {
+		t.Errorf("fast handler = %d %s", resp.StatusCode, body)
+	}
+}
+
+func TestConcurrencyLimitPerUser(t *testing.T) {
+	entered := make(chan struct{}, 10)
+	release := make(chan struct{})
+	app := testApp(t, &models.Claims{UserID: 7})
+	app.Use(ConcurrencyLimit(2, 0))
+	app.Post("/api/transfer", func(c *fiber.Ctx) error {
+		entered <- struct{}{}
+		<-release
+		return c.SendString("done")
+	})
+
+	var wg sync.WaitGroup
+	codes := make(chan int, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, _ := do(t, app, fiber.MethodPost, "/api/transfer", "")
+			codes <- resp.StatusCode
+		}()
+	}
+
+	// Two requests hold the slots; the other three are refused without waiting for them.
+	<-entered
+	<-entered
+	for i := 0; i < 3; i++ {
+		select {
+		case code := <-codes:
+			if code != fiber.StatusTooManyRequests {
+				t.Errorf("excess request = %d, want 429", code)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("excess requests were queued instead of refused")
+		}
+	}
+	close(release)
+	wg.Wait()
+	close(codes)
+	for code := range codes {
+		if code != fiber.StatusOK {
+			t.Errorf("admitted request = %d, want 200", code)
+		}
+	}
+
+	// The slots are released once the requests finish.
+	if resp, body := do(t, app, fiber.MethodPost, "/api/transfer", ""); resp.StatusCode != fiber.StatusOK {
+		t.Errorf("request after the others finished = %d %s", resp.StatusCode, body)
+	}
+}
+
+func TestConcurrencyLimitIsPerUser(t *testing.T) {
+	release, done := make(chan struct{}), make(chan struct{})
+	defer func() {
+		close(release)
+		<-done
+	}()
+	limit := ConcurrencyLimit(1, 0)
+	handler := func(c *fiber.Ctx) error {
+		if c.Query("block") != "" {
+			<-release
+		}
+		return c.SendString("done")
+	}
+	busy := testApp(t, &models.Claims{UserID: 1})
+	busy.Use(limit)
+	busy.Post("/api/transfer", handler)
+	other := testApp(t, &models.Claims{UserID: 2})
+	other.Use(limit)
+	other.Post("/api/transfer", handler)
+
+	go func() {
+		defer close(done)
+		do(t, busy, fiber.MethodPost, "/api/transfer?block=1", "")
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, _ := do(t, busy, fiber.MethodPost, "/api/transfer", "")
+		if resp.StatusCode == fiber.StatusTooManyRequests {
+			if resp.Header.Get(fiber.HeaderRetryAfter) == "" {
+				t.Error("429 without Retry-After")
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the blocked request never took the slot")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if resp, body := do(t, other, fiber.MethodPost, "/api/transfer", ""); resp.StatusCode != fiber.StatusOK {
+		t.Errorf("another user = %d %s, want 200", resp.StatusCode, body)
+	}
+}
+
+func TestRejectFieldsRefusesForgedTimestamps(t *testing.T) {
+	app := testApp(t, nil)
+	app.Use(RejectFields("created_at"))
+	app.Post("/api/deposit", func(c *fiber.Ctx) error { return c.SendString("ok") })
+
+	for _, tt := range []struct {
+		name, body string
+		want       int
+	}{
+		{"top level", `{"account_id": 1, "amount": 5, "created_at": "2099-01-01T00:00:00Z"}`, 400},
+		{"nested in a list", `{"items": [{"amount": 5}, {"amount": 5, "created_at": "1999-01-01T00:00:00Z"}]}`, 400},
+		{"null value", `{"amount": 5, "created_at": null}`, 400},
+		{"clean body", `{"account_id": 1, "amount": 5, "description": "created_at"}`, 200},
+		{"malformed body left to the handler", `{"created_at": `, 200},
+	} {
+		resp, body := do(t, app, fiber.MethodPost, "/api/deposit", tt.body)
+		if resp.StatusCode != tt.want {
+			t.Errorf("%s: %d %s, want %d", tt.name, resp.StatusCode, body, tt.want)
+			continue
+		}
+		if tt.want == 400 && !strings.Contains(body, `"field":"created_at"`) {
+			t.Errorf("%s: %s does not name created_at", tt.name, body)
+		}
+	}
+}
+
+// boundAccounts is bound to the request context like the real services: its queries give up
+// when the deadline passes.
+type boundAccounts struct {
+	services.AccountService
+	ctx context.Context
+}
+
+func (s *boundAccounts) WithContext(ctx context.Context) services.AccountService {
+	return &boundAccounts{ctx: ctx}
+}
+
+func (s *boundAccounts) GetAccounts(userID uint, sandbox bool) ([]models.Account, error) {
+	if s.ctx == nil {
+		return nil, errors.New("queried without the request context")
+	}
+	<-s.ctx.Done()
+	return nil, &services.AppError{Code: 500, Message: "Failed to query accounts", Err: s.ctx.Err()}
+}
+
+func TestTimeoutReachesServiceQueries(t *testing.T) {
+	h := &Handler{accountService: &boundAccounts{}}
+	app := testApp(t, &models.Claims{UserID: 7})
+	app.Use(Timeout(20 * time.Millisecond))
+	app.Get("/api/accounts", h.GetAccounts)
+
+	if resp, body := do(t, app, fiber.MethodGet, "/api/accounts", ""); resp.StatusCode != fiber.StatusGatewayTimeout {
+		t.Errorf("GET /api/accounts = %d %s, want 504", resp.StatusCode, body)
+	}
+}
@@ -0,0 +1,165 @@
+// Path: internal/handlers/money_requests.go
+package handlers
+
+import (
+	"bank-api/internal/models"
+	"bank-api/internal/services"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequestMoney asks another user to pay an amount into one of the caller's accounts.
+func (h *Handler) RequestMoney(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	var req models.MoneyRequestCreate
+	if err := c.BodyParser(&req); err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	request, err := bind(c, h.transactionService).RequestMoney(&req, claims)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Money request failed",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return created(c, fmt.Sprintf("/api/requests/%d", request.ID), request)
+}
+
+// GetMoneyRequests lists the money requests the caller sent or received.
+func (h *Handler) GetMoneyRequests(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	requests, err := bind(c, h.transactionService).GetMoneyRequests(claims.UserID)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve money requests",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(requests)
+}
+
+// PayMoneyRequest pays a request addressed to the caller with a transfer.
+func (h *Handler) PayMoneyRequest(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	requestID, err := paramID(c, "id")
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid request ID",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	var req models.MoneyRequestPayment
+	if err := c.BodyParser(&req); err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	request, transfer, err := bind(c, h.transactionService).PayMoneyRequest(requestID, &req, claims)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Payment failed",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	if !transfer.Duplicate {
+		h.dispatchTransaction(claims, "transfer", transfer.TransactionID, transfer.Status)
+	}
+	return c.JSON(request)
+}
+
+func (h *Handler) RejectMoneyRequest(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	requestID, err := paramID(c, "id")
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid request ID",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	request, err := bind(c, h.transactionService).RejectMoneyRequest(requestID, claims)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Rejection failed",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(request)
+}
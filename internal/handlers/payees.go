@@ -0,0 +1,148 @@
+// Path: internal/handlers/payees.go
+package handlers
+
+import (
+	"bank-api/internal/models"
+	"bank-api/internal/services"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) CreatePayee(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	var req models.PayeeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	payee, err := bind(c, h.payeeService).CreatePayee(&req, claims)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Failed to save payee",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return created(c, fmt.Sprintf("/api/payees/%d", payee.ID), payee)
+}
+
+func (h *Handler) GetPayees(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	payees, err := bind(c, h.payeeService).GetPayees(claims.UserID)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve payees",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(payees)
+}
+
+func (h *Handler) GetPayee(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	payeeID, err := paramID(c, "id")
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid payee ID",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	payee, err := bind(c, h.payeeService).GetPayee(claims.UserID, payeeID)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve payee",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(payee)
+}
+
+func (h *Handler) DeletePayee(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	payeeID, err := paramID(c, "id")
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid payee ID",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	if err := bind(c, h.payeeService).DeletePayee(payeeID, claims); err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to delete payee",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
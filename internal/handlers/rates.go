@@ -0,0 +1,32 @@
+// Path: internal/handlers/rates.go
+package handlers
+
+import (
+	"bank-api/internal/services"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetCurrencies lists the supported currency conventions: decimal places and symbols.
+func (h *Handler) GetCurrencies(c *fiber.Ctx) error {
+	return c.JSON(bind(c, h.transactionService).Currencies())
+}
+
+func (h *Handler) GetRate(c *fiber.Ctx) error {
+	quote, err := h.rateService.GetQuote(c.Query("from"), c.Query("to"))
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to fetch exchange rate",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(quote)
+}
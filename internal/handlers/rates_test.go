@@ -0,0 +1,28 @@
+// Path: internal/handlers/rates_test.go
+package handlers
+
+import (
+	"bank-api/internal/models"
+	"bank-api/internal/services"
+	"testing"
+)
+
+func TestGetRate(t *testing.T) {
+	h := &Handler{rateService: services.NewRateService(services.NewStaticRateProvider("USD", map[string]float64{"EUR": 1.25}), 1)}
+	app := testApp(t, &models.Claims{UserID: 1})
+	app.Get("/api/rates", h.GetRate)
+
+	resp, body := do(t, app, "GET", "/api/rates?from=EUR&to=USD", "")
+	if resp.StatusCode != 200 {
+		t.Fatalf("GET /api/rates = %d %s", resp.StatusCode, body)
+	}
+	var quote models.RateQuote
+	decode(t, body, &quote)
+	if quote.Rate != 1.25 || quote.SpreadPercent != 1 {
+		t.Errorf("quote = %+v", quote)
+	}
+
+	if resp, body := do(t, app, "GET", "/api/rates?from=USD&to=JPY", ""); resp.StatusCode != 404 {
+		t.Errorf("unsupported pair = %d %s, want 404", resp.StatusCode, body)
+	}
+}
@@ -0,0 +1,52 @@
+// Path: internal/handlers/receipts.go
+package handlers
+
+import (
+	"bank-api/internal/models"
+	"bank-api/internal/services"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) GetReceipt(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	receipt, err := bind(c, h.receiptService).GetReceipt(c.Params("id"), claims)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to issue receipt",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(receipt)
+}
+
+// VerifyReceipt is public: third parties can check a receipt a user handed them.
+func (h *Handler) VerifyReceipt(c *fiber.Ctx) error {
+	var req models.Receipt
+	if err := c.BodyParser(&req); err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(bind(c, h.receiptService).VerifyReceipt(&req))
+}
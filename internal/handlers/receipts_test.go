@@ -0,0 +1,46 @@
+// Path: internal/handlers/receipts_test.go
+package handlers
+
+import (
+	"bank-api/internal/models"
+	"bank-api/internal/services"
+	"encoding/json"
+	"testing"
+)
+
+type receiptTransactions struct{}
+
+func (receiptTransactions) FindVisible(transactionID string, userID uint) (*models.Transaction, error) {
+	return &models.Transaction{ID: transactionID, Type: "deposit", Amount: 10, Status: "completed"}, nil
+}
+
+func (receiptTransactions) Currency(transaction *models.Transaction) (string, error) {
+	return "USD", nil
+}
+
+func TestVerifyReceiptWithoutAuthentication(t *testing.T) {
+	receipts := services.NewReceiptService(receiptTransactions{}, "receipt-secret", nil)
+	receipt, err := receipts.GetReceipt("tx1", &models.Claims{UserID: 1})
+	if err != nil {
+		t.Fatalf("receipt: %v", err)
+	}
+	h := &Handler{receiptService: receipts}
+	app := testApp(t, nil)
+	app.Post("/api/receipts/verify", h.VerifyReceipt)
+
+	genuine, _ := json.Marshal(receipt)
+	receipt.Amount = 1000
+	tampered, _ := json.Marshal(receipt)
+	for _, tt := range []struct {
+		name  string
+		body  []byte
+		valid bool
+	}{{"genuine", genuine, true}, {"tampered", tampered, false}} {
+		resp, body := do(t, app, "POST", "/api/receipts/verify", string(tt.body))
+		var verification models.ReceiptVerification
+		decode(t, body, &verification)
+		if resp.StatusCode != 200 || verification.Valid != tt.valid {
+			t.Errorf("%s receipt: %d %s, want valid=%v", tt.name, resp.StatusCode, body, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+// Path: internal/handlers/security_test.go
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func secureApp(t *testing.T, cfg SecurityConfig) *fiber.App {
+	t.Helper()
+	app := testApp(t, nil)
+	app.Use(SecureHeaders(cfg))
+	app.Get("/api/accounts", func(c *fiber.Ctx) error { return c.SendString("ok") })
+	return app
+}
+
+func TestSecureHeadersOnResponses(t *testing.T) {
+	app := secureApp(t, SecurityConfig{
+		Headers:               true,
+		HSTSMaxAge:            365 * 24 * time.Hour,
+		HSTSIncludeSubdomains: true,
+		ContentSecurityPolicy: "frame-ancestors 'none'",
+	})
+	want := map[string]string{
+		fiber.HeaderXContentTypeOptions:     "nosniff",
+		fiber.HeaderXFrameOptions:           "DENY",
+		fiber.HeaderReferrerPolicy:          "no-referrer",
+		fiber.HeaderContentSecurityPolicy:   "frame-ancestors 'none'",
+		fiber.HeaderStrictTransportSecurity: "max-age=31536000; includeSubDomains",
+	}
+	for _, target := range []string{"/api/accounts", "/api/missing"} {
+		resp, _ := do(t, app, fiber.MethodGet, target, "", fiber.HeaderXForwardedProto, "https")
+		for header, value := range want {
+			if got := resp.Header.Get(header); got != value {
+				t.Errorf("%s: %s = %q, want %q", target, header, got, value)
+			}
+		}
+	}
+
+	// Browsers ignore HSTS over plain HTTP, so it isn't sent there.
+	resp, _ := do(t, app, fiber.MethodGet, "/api/accounts", "")
+	if got := resp.Header.Get(fiber.HeaderStrictTransportSecurity); got != "" {
+		t.Errorf("HSTS over HTTP = %q, want none", got)
+	}
+	if got := resp.Header.Get(fiber.HeaderXContentTypeOptions); got != "nosniff" {
+		t.Errorf("X-Content-Type-Options over HTTP = %q", got)
+	}
+}
+
+func TestSecureHeadersDisabled(t *testing.T) {
+	app := secureApp(t, SecurityConfig{HSTSMaxAge: time.Hour, ContentSecurityPolicy: "default-src 'self'"})
+	resp, _ := do(t, app, fiber.MethodGet, "/api/accounts", "", fiber.HeaderXForwardedProto, "https")
+	for _, header := range []string{fiber.HeaderXContentTypeOptions, fiber.HeaderStrictTransportSecurity, fiber.HeaderContentSecurityPolicy} {
+		if got := resp.Header.Get(header); got != "" {
+			t.Errorf("%s = %q with headers disabled", header, got)
+		}
+	}
+}
+
+func TestRequireHTTPS(t *testing.T) {
+	app := secureApp(t, SecurityConfig{Headers: true, RequireHTTPS: true})
+	resp, body := do(t, app, fiber.MethodGet, "/api/accounts", "")
+	if resp.StatusCode != fiber.StatusForbidden {
+		t.Errorf("plain HTTP = %d %s, want 403", resp.StatusCode, body)
+	}
+	if got := resp.Header.Get(fiber.HeaderXContentTypeOptions); got != "nosniff" {
+		t.Errorf("rejection without security headers: X-Content-Type-Options = %q", got)
+	}
+	if resp, body := do(t, app, fiber.MethodGet, "/api/accounts", "", fiber.HeaderXForwardedProto, "https"); resp.StatusCode != fiber.StatusOK {
+		t.Errorf("HTTPS = %d %s, want 200", resp.StatusCode, body)
+	}
+}
+
+func TestRequireHTTPSTrustsOnlyConfiguredProxies(t *testing.T) {
+	messages, err := NewMessages("")
+	if err != nil {
+		t.Fatalf("NewMessages: %v", err)
+	}
+	app := fiber.New(fiber.Config{
+		ErrorHandler:            messages.ErrorHandler,
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          []string{"10.0.0.1"},
+	})
+	app.Use(SecureHeaders(SecurityConfig{RequireHTTPS: true}))
+	app.Get("/api/accounts", func(c *fiber.Ctx) error { return c.SendString("ok") })
+
+	// The test client is not 10.0.0.1, so its X-Forwarded-Proto is ignored.
+	resp, body := do(t, app, fiber.MethodGet, "/api/accounts", "", fiber.HeaderXForwardedProto, "https")
+	if resp.StatusCode != fiber.StatusForbidden {
+		t.Errorf("spoofed X-Forwarded-Proto = %d %s, want 403", resp.StatusCode, body)
+	}
+}
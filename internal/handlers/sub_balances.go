@@ -0,0 +1,48 @@
+// Path: internal/handlers/sub_balances.go
+package handlers
+
+import (
+	"bank-api/internal/models"
+	"bank-api/internal/services"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetSubBalances returns the principal and the sub-balances of an account of the caller.
+func (h *Handler) GetSubBalances(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	accountID, err := paramID(c, "id")
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid account ID",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	subBalances, err := bind(c, h.transactionService).GetSubBalances(accountID, claims)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve sub-balances",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(subBalances)
+}
@@ -0,0 +1,321 @@
+// Path: internal/handlers/transactions.go
+package handlers
+
+import (
+	"bank-api/internal/models"
+	"bank-api/internal/services"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// headerNextCursor carries the cursor of the next history page; absent on the last page.
+const headerNextCursor = "X-Next-Cursor"
+
+func (h *Handler) GetTransactions(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	filter := models.TransactionFilter{Tag: c.Query("tag"), Cursor: c.Query("cursor")}
+	for name, dst := range map[string]*int{"account_id": &filter.AccountID, "limit": &filter.Limit, "offset": &filter.Offset} {
+		if v := c.Query(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return &AppError{
+					Code:    fiber.StatusBadRequest,
+					Message: "Invalid query parameter",
+					Details: name + ": " + err.Error(),
+					Err:     err,
+				}
+			}
+			*dst = n
+		}
+	}
+
+	transactions, next, err := bind(c, h.transactionService).ListTransactions(claims.UserID, filter)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve transactions",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	if next != "" {
+		c.Set(headerNextCursor, next)
+	}
+	return sparseJSON(c, transactions, transactionFields)
+}
+
+// GetCounterpartyTransactions lists the transfers between an owned account and another account.
+func (h *Handler) GetCounterpartyTransactions(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	filter := models.TransactionFilter{Cursor: c.Query("cursor")}
+	for name, dst := range map[string]*int{"id": &filter.AccountID, "counterparty": &filter.CounterpartyID} {
+		n, err := paramID(c, name)
+		if err != nil {
+			return &AppError{
+				Code:    fiber.StatusBadRequest,
+				Message: "Invalid account ID",
+				Details: name + ": " + err.Error(),
+				Err:     err,
+			}
+		}
+		*dst = n
+	}
+	for name, dst := range map[string]*int{"limit": &filter.Limit, "offset": &filter.Offset} {
+		if v := c.Query(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return &AppError{
+					Code:    fiber.StatusBadRequest,
+					Message: "Invalid query parameter",
+					Details: name + ": " + err.Error(),
+					Err:     err,
+				}
+			}
+			*dst = n
+		}
+	}
+
+	transactions, next, err := bind(c, h.transactionService).ListTransactions(claims.UserID, filter)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve transactions",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	if next != "" {
+		c.Set(headerNextCursor, next)
+	}
+	return sparseJSON(c, transactions, transactionFields)
+}
+
+func (h *Handler) UpdateTags(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	var req models.TagsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	tags, err := bind(c, h.transactionService).UpdateTags(c.Params("id"), &req, claims)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to update tags",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(fiber.Map{"tags": tags})
+}
+
+func (h *Handler) GetLedgerEntries(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	accountID, err := paramID(c, "id")
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid account ID",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	var filter models.LedgerFilter
+	for name, dst := range map[string]*int{"limit": &filter.Limit, "offset": &filter.Offset} {
+		if v := c.Query(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return &AppError{
+					Code:    fiber.StatusBadRequest,
+					Message: "Invalid query parameter",
+					Details: name + ": " + err.Error(),
+					Err:     err,
+				}
+			}
+			*dst = n
+		}
+	}
+
+	entries, err := bind(c, h.transactionService).ListLedgerEntries(claims.UserID, accountID, filter)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve ledger entries",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(entries)
+}
+
+func (h *Handler) BatchTransfer(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	var req models.BatchTransferRequest
+	if err := c.BodyParser(&req); err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	batch, err := bind(c, h.transactionService).ProcessBatch(&req, claims)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Batch transfer failed",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return created(c, fmt.Sprintf("/api/transfers/batch/%d", batch.ID), batch)
+}
+
+func (h *Handler) GetBatch(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	batchID, err := paramID(c, "id")
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid batch ID",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	batch, err := bind(c, h.transactionService).GetBatch(batchID, claims.UserID)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve batch",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(batch)
+}
+
+func (h *Handler) RefundTransfer(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	var req models.RefundRequest
+	if err := c.BodyParser(&req); err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	refund, err := bind(c, h.transactionService).RefundTransfer(c.Params("id"), &req, claims)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Refund failed",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(refund)
+}
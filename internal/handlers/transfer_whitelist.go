@@ -0,0 +1,96 @@
+// Path: internal/handlers/transfer_whitelist.go
+package handlers
+
+import (
+	"bank-api/internal/models"
+	"bank-api/internal/services"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetTransferWhitelist returns the destinations an account of the caller may transfer to.
+func (h *Handler) GetTransferWhitelist(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	accountID, err := paramID(c, "id")
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid account ID",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	whitelist, err := bind(c, h.accountService).GetTransferWhitelist(claims.UserID, accountID)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve transfer whitelist",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(whitelist)
+}
+
+// SetTransferWhitelist replaces the transfer whitelist of an account of the caller.
+func (h *Handler) SetTransferWhitelist(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	accountID, err := paramID(c, "id")
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid account ID",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	var req models.TransferWhitelist
+	if err := c.BodyParser(&req); err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	whitelist, err := bind(c, h.accountService).SetTransferWhitelist(claims.UserID, accountID, &req)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to update transfer whitelist",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(whitelist)
+}
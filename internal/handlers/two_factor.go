@@ -0,0 +1,114 @@
+// Path: internal/handlers/two_factor.go
+package handlers
+
+import (
+	"bank-api/internal/models"
+	"bank-api/internal/services"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireTwoFactor rejects tokens without the 2fa claim on paths starting with one of the given
+// prefixes. Must run after AuthMiddleware.
+func RequireTwoFactor(prefixes []string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Method() == fiber.MethodOptions {
+			return c.Next()
+		}
+
+		required := false
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(c.Path(), prefix) {
+				required = true
+				break
+			}
+		}
+		if !required {
+			return c.Next()
+		}
+
+		claims, ok := c.Locals("user").(*models.Claims)
+		if !ok {
+			return &AppError{
+				Code:    fiber.StatusInternalServerError,
+				Message: "Failed to retrieve user claims",
+				Details: "User claims were not of the expected type",
+			}
+		}
+		if !claims.TwoFactor {
+			return &AppError{
+				Code:    fiber.StatusForbidden,
+				Message: "2FA required",
+				Details: "Elevate the token via POST /api/2fa/elevate",
+			}
+		}
+
+		return c.Next()
+	}
+}
+
+func (h *Handler) SetupTwoFactor(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	setup, err := bind(c, h.authService).SetupTwoFactor(claims.UserID)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to set up 2FA",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(setup)
+}
+
+func (h *Handler) ElevateToken(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	var req models.ElevateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	token, err := bind(c, h.authService).Elevate(claims, req.Code)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusUnauthorized,
+			Message: "Elevation failed",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(fiber.Map{"token": token})
+}
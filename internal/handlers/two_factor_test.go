@@ -0,0 +1,32 @@
+// Path: internal/handlers/two_factor_test.go
+package handlers
+
+import (
+	"bank-api/internal/models"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestRequireTwoFactor(t *testing.T) {
+	ok := func(c *fiber.Ctx) error { return c.SendString("ok") }
+	for _, tt := range []struct {
+		elevated bool
+		path     string
+		want     int
+	}{
+		{false, "/api/transfer", 403},
+		{true, "/api/transfer", 200},
+		{false, "/api/accounts", 200},
+	} {
+		app := testApp(t, &models.Claims{UserID: 1, TwoFactor: tt.elevated})
+		app.Use(RequireTwoFactor([]string{"/api/transfer"}))
+		app.Post("/api/transfer", ok)
+		app.Post("/api/accounts", ok)
+
+		resp, body := do(t, app, "POST", tt.path, "")
+		if resp.StatusCode != tt.want {
+			t.Errorf("elevated %t, POST %s = %d %s, want %d", tt.elevated, tt.path, resp.StatusCode, body, tt.want)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+// Path: internal/handlers/webhooks.go
+package handlers
+
+import (
+	"bank-api/internal/models"
+	"bank-api/internal/services"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// dispatchTransaction notifies the user's webhooks of a transaction they made, e.g. "transfer.completed".
+func (h *Handler) dispatchTransaction(claims *models.Claims, txType, transactionID, status string) {
+	h.webhookService.Dispatch(claims.UserID, txType+"."+status, fiber.Map{
+		"transactionID": transactionID,
+		"type":          txType,
+		"status":        status,
+	})
+}
+
+func (h *Handler) CreateWebhook(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	var req models.WebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	webhook, err := h.webhookService.CreateWebhook(claims.UserID, &req)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to save webhook",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return created(c, fmt.Sprintf("/api/webhooks/%d", webhook.ID), webhook)
+}
+
+func (h *Handler) GetWebhooks(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	webhooks, err := h.webhookService.GetWebhooks(claims.UserID)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve webhooks",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(webhooks)
+}
+
+func (h *Handler) GetWebhookDeliveries(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	webhookID, err := paramID(c, "id")
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid webhook ID",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	limit := 0
+	if v := c.Query("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil {
+			return &AppError{
+				Code:    fiber.StatusBadRequest,
+				Message: "Invalid query parameter",
+				Details: "limit: " + err.Error(),
+				Err:     err,
+			}
+		}
+	}
+
+	deliveries, err := h.webhookService.GetDeliveries(claims.UserID, webhookID, limit)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve webhook deliveries",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(deliveries)
+}
+
+func (h *Handler) RedeliverWebhook(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*models.Claims)
+	if !ok {
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to retrieve user claims",
+			Details: "User claims were not of the expected type",
+		}
+	}
+
+	webhookID, err := paramID(c, "id")
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid webhook ID",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+	deliveryID, err := paramID(c, "delivery_id")
+	if err != nil {
+		return &AppError{
+			Code:    fiber.StatusBadRequest,
+			Message: "Invalid delivery ID",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	delivery, err := h.webhookService.Redeliver(claims.UserID, webhookID, deliveryID)
+	if err != nil {
+		var appErr *services.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return &AppError{
+			Code:    fiber.StatusInternalServerError,
+			Message: "Failed to redeliver webhook",
+			Details: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return c.JSON(delivery)
+}
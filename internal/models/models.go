@@ -2,31 +2,284 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"github.com/golang-jwt/jwt/v4"
 	"time"
 )
 
 // User represents a user in the database.
 type User struct {
-	ID        int    `json:"id"`
-	Username  string `json:"username"`
-	Password  string `json:"-"`
-	CreatedAt string `json:"created_at"`
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+	Password string `json:"-"`
+	Role     string `json:"role"`
+	Tier     string `json:"tier"`
+	// TransfersEnabled is cleared by admins to restrict a user to read-only: deposits still work.
+	TransfersEnabled bool `json:"transfers_enabled"`
+	// RoundUpEnabled moves the change of each withdrawal and transfer, rounded up to a whole unit, to RoundUpAccountID.
+	RoundUpEnabled   bool `json:"round_up_enabled"`
+	RoundUpAccountID *int `json:"round_up_account_id"`
+	// TOTPSecret is the base32 secret of the user's authenticator app. Empty until 2FA is set up.
+	TOTPSecret string `json:"-" gorm:"serializer:encrypted"`
+	// TOTPLastStep is the time step of the last accepted code, so a code can't be used twice.
+	TOTPLastStep int64 `json:"-"`
+	// StatementsEnabled opts the user in to monthly statements; StatementLastPeriod is the last one sent.
+	StatementsEnabled   bool   `json:"statements_enabled"`
+	StatementLastPeriod string `json:"-"`
+	// DailySpendingLimit is the user's own cap on daily withdrawals and transfers, below the system one.
+	DailySpendingLimit *float64 `json:"daily_spending_limit"`
+	CreatedAt          string   `json:"created_at"`
+	// DeletedAt is set when an admin deletes the user: they can't log in, and their data is purged
+	// once the retention window has passed.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
+// User tiers.
+const (
+	TierStandard = "standard"
+	TierPremium  = "premium"
+)
+
+// User roles.
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// Account types.
+const (
+	AccountTypeChecking  = "checking"
+	AccountTypeSavings   = "savings"
+	AccountTypeOverdraft = "overdraft"
+)
+
 // Account represents an account in the database.
 type Account struct {
 	ID          int     `json:"id"`
-	UserID      int     `json:"user_id"`
+	UserID      int     `json:"user_id"` // 0 once the owner was purged
+	Number      *string `json:"number"`  // Human-facing account number (internal or IBAN format)
 	Balance     float64 `json:"balance"`
-	BalanceHash string  `json:"-"` // Excluded from JSON
-	CreatedAt   string  `json:"created_at"`
+	Currency    string  `json:"currency"`
+	Type        string  `json:"type"` // checking, savings or overdraft
+	BalanceHash string  `json:"-"`    // Excluded from JSON
+	Nickname    string  `json:"nickname"`
+	Sandbox     bool    `json:"sandbox"` // Test account, reachable only with sandbox tokens
+	// Frozen blocks outgoing payments after a freeze rule fired, until an admin unfreezes the account.
+	Frozen       bool      `json:"frozen"`
+	FrozenReason string    `json:"frozen_reason,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	// Dormant accounts had no activity for too long and must be reactivated by the owner before use.
+	Dormant        bool       `json:"dormant"`
+	LastActivityAt *time.Time `json:"last_activity_at"` // Last deposit, withdrawal or outgoing transfer
+	// ClosedAt is set while the account is closed: it keeps its history but takes no payments.
+	ClosedAt *time.Time `json:"closed_at"`
+
+	// TransferWhitelist restricts outgoing transfers to the destinations on the account's whitelist.
+	TransferWhitelist bool `json:"transfer_whitelist"`
+
+	// SubBalances holds the named parts of Balance, e.g. bonus funds; the principal is the rest.
+	// They are covered by BalanceHash together with Balance.
+	SubBalances SubBalances `json:"sub_balances,omitempty"`
+}
+
+// BalanceProof lets an auditor holding the balance secret verify a balance: Proof must equal
+// the hex HMAC of Message keyed with the secret.
+type BalanceProof struct {
+	AccountID int       `json:"account_id"`
+	Balance   float64   `json:"balance"`
+	Currency  string    `json:"currency"`
+	Message   string    `json:"message"` // Signed string: "<balance with 6 decimals>:<account id>"
+	Algorithm string    `json:"algorithm"`
+	Proof     string    `json:"proof"` // Stored balance hash
+	AsOf      time.Time `json:"as_of"`
+}
+
+// HistoricalBalance is the balance of an account as of a past moment.
+type HistoricalBalance struct {
+	AccountID int       `json:"account_id"`
+	Balance   float64   `json:"balance"`
+	Currency  string    `json:"currency"`
+	At        time.Time `json:"at"`
+}
+
+// Beneficiary is an account receiving a share of another account's balance when it is distributed.
+type Beneficiary struct {
+	ID                   int       `json:"id"`
+	AccountID            int       `json:"account_id"`
+	BeneficiaryAccountID int       `json:"beneficiary_account_id"`
+	SharePercent         float64   `json:"share_percent"`
+	CreatedAt            time.Time `json:"created_at"`
+}
+
+// TransferWhitelist lists the destinations an account may transfer to. It is the request body of
+// PUT /api/accounts/:id/transfer-whitelist as well, which replaces the list.
+type TransferWhitelist struct {
+	Enabled    bool  `json:"enabled"` // When false every destination is allowed and the list is kept
+	AccountIDs []int `json:"account_ids"`
+}
+
+// TransferWhitelistEntry is a destination on the transfer whitelist of an account.
+type TransferWhitelistEntry struct {
+	AccountID            int
+	DestinationAccountID int
+	CreatedAt            time.Time
+}
+
+// Cosigners lists the users who may approve the transfers from an account held for approval, as
+// administrators do. It is the request body of PUT /api/accounts/:id/cosigners as well, which
+// replaces the list.
+type Cosigners struct {
+	UserIDs []int `json:"user_ids"`
+}
+
+// AccountCosigner is a user designated to approve the transfers from an account.
+type AccountCosigner struct {
+	AccountID int
+	UserID    int
+	CreatedAt time.Time
+}
+
+// SubBalance is a named part of an account balance, e.g. bonus funds, kept apart from the
+// principal: the principal is what remains of the balance after the sub-balances.
+type SubBalance struct {
+	Name   string  `json:"name"`
+	Amount float64 `json:"amount"`
+}
+
+// SubBalances maps sub-balance names to their amounts. It is stored as a JSON column, NULL when empty.
+type SubBalances map[string]float64
+
+// Value implements driver.Valuer.
+func (s SubBalances) Value() (driver.Value, error) {
+	if len(s) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(map[string]float64(s))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner.
+func (s *SubBalances) Scan(value interface{}) error {
+	*s = nil
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported sub-balances value %T", value)
+	}
+	return json.Unmarshal(data, s)
+}
+
+// Hold reserves funds of an account for a later capture, like a card authorization.
+type Hold struct {
+	ID             int        `json:"id"`
+	AccountID      int        `json:"account_id"`
+	Amount         float64    `json:"amount"`
+	CapturedAmount float64    `json:"captured_amount"`
+	Status         string     `json:"status"` // "active", "captured" or "released"
+	Description    string     `json:"description"`
+	TransactionID  *string    `json:"transaction_id"` // Withdrawal made by the capture
+	CreatedAt      time.Time  `json:"created_at"`
+	ResolvedAt     *time.Time `json:"resolved_at"` // When the hold was captured or released
+}
+
+// HoldRequest is the request body for placing a hold.
+type HoldRequest struct {
+	Amount      float64 `json:"amount"`
+	Description string  `json:"description"`
+}
+
+// CaptureRequest is the request body for capturing a hold. A zero amount captures all of it.
+type CaptureRequest struct {
+	Amount float64 `json:"amount"`
+}
+
+// MoneyRequest is a request of one user to be paid an amount by another.
+type MoneyRequest struct {
+	ID            int        `json:"id"`
+	RequesterID   int        `json:"requester_id"`
+	PayerID       int        `json:"payer_id"`
+	AccountID     int        `json:"account_id"` // Requester's account the payment goes to
+	Amount        float64    `json:"amount"`
+	Status        string     `json:"status"` // "pending", "paid", "rejected" or "expired"
+	Description   string     `json:"description"`
+	TransactionID *string    `json:"transaction_id"` // Transfer made by the payment
+	CreatedAt     time.Time  `json:"created_at"`
+	ResolvedAt    *time.Time `json:"resolved_at"` // When the request was paid, rejected or expired
+}
+
+// MoneyRequestCreate is the request body for requesting money. The payer is named by username
+// or by the number of one of their accounts.
+type MoneyRequestCreate struct {
+	AccountID     int     `json:"account_id"`
+	Username      string  `json:"username"`
+	AccountNumber string  `json:"account_number"`
+	Amount        float64 `json:"amount"`
+	Description   string  `json:"description"`
+}
+
+// MoneyRequestPayment is the request body for paying a money request.
+type MoneyRequestPayment struct {
+	FromID int `json:"from_id"`
+	// ConfirmationCode is the one-time code sent for payments above the confirmation threshold.
+	ConfirmationCode string `json:"confirmation_code"`
+}
+
+// BeneficiaryRequest names a beneficiary of an account.
+type BeneficiaryRequest struct {
+	AccountID    int     `json:"beneficiary_account_id"`
+	SharePercent float64 `json:"share_percent"`
+}
+
+// DistributionRequest pays out an account to its beneficiaries.
+type DistributionRequest struct {
+	Reason string `json:"reason"` // The confirmed event triggering the distribution, recorded in the audit trail
+}
+
+// CreateAccountRequest represents a request for opening a new account.
+type CreateAccountRequest struct {
+	Currency string `json:"currency"` // ISO 4217 code, defaults to USD
+	Nickname string `json:"nickname"`
+	Type     string `json:"type"` // checking (default), savings or overdraft
+	Sandbox  bool   `json:"-"`    // Set from the token: sandbox tokens open sandbox accounts
+}
+
+// UpdateAccountRequest represents a request for updating account settings.
+type UpdateAccountRequest struct {
+	Nickname string `json:"nickname"` // Empty string clears the nickname.
+}
+
+// NetWorth represents the aggregated balances of a user.
+type NetWorth struct {
+	Totals         map[string]float64 `json:"totals"`                    // Per currency, never mixed
+	BaseCurrency   string             `json:"base_currency,omitempty"`   // Set when an FX base currency is configured
+	ConvertedTotal *float64           `json:"converted_total,omitempty"` // Total in BaseCurrency
+}
+
+// RateQuote represents the exchange rate offered for a currency pair.
+type RateQuote struct {
+	From          string  `json:"from"`
+	To            string  `json:"to"`
+	Rate          float64 `json:"rate"`           // Mid-market rate: units of To per unit of From
+	SpreadPercent float64 `json:"spread_percent"` // Margin applied to conversions
+	ClientRate    float64 `json:"client_rate"`    // Rate actually applied to conversions
 }
 
 // AuthRequest represents a request for user authentication.
 type AuthRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	Sandbox  bool   `json:"sandbox"` // Issue a sandbox token working only with sandbox accounts
 }
 
 // TransactionRequest represents a request for a transaction.
@@ -34,28 +287,542 @@ type TransactionRequest struct {
 	AccountID     int     `json:"account_id"`
 	Amount        float64 `json:"amount"`
 	TransactionID string  `json:"transaction_id"` // This should be returned during the request for admin tracking.
+	Status        string  `json:"status"`         // Filled in by the service, e.g. "completed" or "held".
+	Description   string  `json:"description"`
+	Source        string  `json:"source"` // Declared origin of a deposit: cash, wire, card...
+
+	// SubBalance credits a deposit to a named sub-balance, e.g. "bonus", instead of the principal.
+	SubBalance string `json:"sub_balance"`
+}
+
+// SplitDepositRequest is the request body for depositing one amount across several accounts.
+type SplitDepositRequest struct {
+	Total       float64            `json:"total"` // Must equal the sum of the parts
+	Description string             `json:"description"`
+	Source      string             `json:"source"`
+	Parts       []SplitDepositPart `json:"parts"`
+}
+
+// SplitDepositPart is the share of a split deposit going to one account.
+type SplitDepositPart struct {
+	AccountID int     `json:"account_id"`
+	Amount    float64 `json:"amount"`
+}
+
+// DepositResult is the outcome of one deposit of a split deposit.
+type DepositResult struct {
+	TransactionID string         `json:"transaction_id"`
+	Status        string         `json:"status"` // "completed" or "held"
+	Amount        float64        `json:"amount"`
+	Balance       AccountBalance `json:"balance"`
+}
+
+// DepositPreview describes how a deposit would be treated without executing it.
+type DepositPreview struct {
+	AccountID   int     `json:"account_id"`
+	Amount      float64 `json:"amount"`
+	WindowTotal float64 `json:"window_total"` // Deposits already made within the rolling window
+	Threshold   float64 `json:"threshold"`    // Zero when holds are disabled
+	WouldHold   bool    `json:"would_hold"`
+}
+
+// AccountBalance is the balance of an account right after a deposit or withdrawal.
+type AccountBalance struct {
+	AccountID        int     `json:"account_id"`
+	Balance          float64 `json:"balance"`
+	AvailableBalance float64 `json:"available_balance"` // Balance minus funds reserved by pending transfers
+	Currency         string  `json:"currency"`
+	Display          string  `json:"display"` // Balance formatted for display, e.g. "$1,234.50"
+}
+
+// UserSummary is the non-sensitive view of a user shown to support staff.
+type UserSummary struct {
+	ID               int    `json:"id"`
+	Username         string `json:"username"`
+	Role             string `json:"role"`
+	Tier             string `json:"tier"`
+	TransfersEnabled bool   `json:"transfers_enabled"`
+	AccountCount     int    `json:"account_count"`
+	CreatedAt        string `json:"created_at"`
+}
+
+// UserSearchFilter narrows down the admin user search.
+type UserSearchFilter struct {
+	Query  string // Username prefix, case-insensitive
+	Limit  int
+	Offset int
+}
+
+// AdminTransactionFilter narrows down the transactions of all users for back-office monitoring.
+type AdminTransactionFilter struct {
+	From   time.Time // Inclusive; zero means unbounded
+	To     time.Time // Exclusive; zero means unbounded
+	Type   string    // Empty means any
+	Status string    // Empty means any
+	Limit  int
+	Offset int
+
+	// Reference finds the transaction a customer quotes, e.g. TXN-2024-000123. Empty means any.
+	Reference string
+}
+
+// AdminTransactionPage is a page of the transactions matching an admin filter, with aggregates
+// over all matches rather than just the page.
+type AdminTransactionPage struct {
+	Transactions []Transaction    `json:"transactions"`
+	Total        int64            `json:"total"`
+	Volume       []CurrencyVolume `json:"volume"`
+}
+
+// Currency describes how amounts in a currency are stored and displayed.
+type Currency struct {
+	Code     string `json:"code"`
+	Decimals int    `json:"decimals"` // Decimal places of amounts, e.g. 0 for JPY and 3 for BHD
+	Symbol   string `json:"symbol"`
+}
+
+// CurrencyVolume sums the transactions in one currency.
+type CurrencyVolume struct {
+	Currency string  `json:"currency"`
+	Count    int64   `json:"count"`
+	Amount   float64 `json:"amount"`
+	Fees     float64 `json:"fees"`
+}
+
+// StatementSettings configures the monthly statements of a user.
+type StatementSettings struct {
+	Enabled bool `json:"enabled"`
+}
+
+// SpendingLimits are the daily spending limits of a user. Only DailyLimit can be set; zero limits mean unlimited.
+type SpendingLimits struct {
+	DailyLimit     *float64 `json:"daily_limit"`
+	MaxDailyLimit  float64  `json:"max_daily_limit"`
+	EffectiveLimit float64  `json:"effective_daily_limit"`
+}
+
+// Velocity is how much money a user moved out recently: withdrawals and transfers, including those
+// still waiting for approval or acceptance.
+type Velocity struct {
+	LastHour   VelocityWindow `json:"last_hour"`
+	LastDay    VelocityWindow `json:"last_day"` // The last 24 hours, not the calendar day
+	ComputedAt time.Time      `json:"computed_at"`
+}
+
+// VelocityWindow counts the outgoing transactions within a window. Volumes are per currency.
+type VelocityWindow struct {
+	Count  int64              `json:"count"`
+	Volume map[string]float64 `json:"volume"`
+}
+
+// RoundUpSettings configures the round-up savings of a user.
+type RoundUpSettings struct {
+	Enabled   bool `json:"enabled"`
+	AccountID *int `json:"account_id"` // Savings account receiving the round-ups
+}
+
+// TransfersToggleRequest enables or disables the outgoing payments of a user.
+type TransfersToggleRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// RefundRequest is a request for refunding part or all of a received transfer.
+type RefundRequest struct {
+	Amount      float64 `json:"amount"`
+	Description string  `json:"description"` // Defaults to "Refund of <transaction id>"
+}
+
+// AdjustmentRequest is a manual balance correction by an admin.
+type AdjustmentRequest struct {
+	Amount float64 `json:"amount"` // Positive credits, negative debits the account
+	Reason string  `json:"reason"`
+	Force  bool    `json:"force"` // Apply a debit even below the floor of the account type
 }
 
 // TransferRequest represents a request for transferring funds between accounts.
 type TransferRequest struct {
-	FromID int     `json:"from_id"`
-	ToID   int     `json:"to_id"`
-	Amount float64 `json:"amount"`
+	FromID        int     `json:"from_id"`
+	ToID          int     `json:"to_id"`
+	PayeeID       int     `json:"payee_id"`          // Alternative to ToID: a saved payee of the user
+	ToNumber      string  `json:"to_account_number"` // Alternative to ToID: account number in any supported format
+	Amount        float64 `json:"amount"`
+	TransactionID string  `json:"transaction_id"` // Filled in by the service.
+	Status        string  `json:"status"`         // Filled in by the service, e.g. "completed" or "pending_approval".
+	Description   string  `json:"description"`
+	Fee           float64 `json:"fee"`        // Filled in by the service.
+	FeeWaived     bool    `json:"fee_waived"` // Filled in by the service.
+	// Duplicate is set by the service when the transfer repeated a recent identical one, whose result
+	// is returned instead of executing it again.
+	Duplicate bool `json:"duplicate,omitempty"`
+	// ConfirmationCode is the one-time code sent for transfers above the confirmation threshold.
+	ConfirmationCode string `json:"confirmation_code"`
+
+	// RequireAcceptance holds the transfer as "incoming_pending" until the recipient accepts it;
+	// until then the funds stay reserved on the source account.
+	RequireAcceptance bool `json:"require_acceptance"`
+
+	// SendAll empties the source account: the service computes the largest amount that, with its
+	// fee, leaves the account at zero or at its minimum balance. Amount must be left out.
+	SendAll bool `json:"send_all"`
+}
+
+// TransferValidation reports the checks a transfer would go through, without executing it.
+type TransferValidation struct {
+	Valid                bool            `json:"valid"` // Every check passed
+	Checks               []TransferCheck `json:"checks"`
+	Fee                  float64         `json:"fee"`
+	RequiresConfirmation bool            `json:"requires_confirmation"` // A confirmation code will be needed
+	RequiresApproval     bool            `json:"requires_approval"`     // The transfer will wait for an administrator
+}
+
+// TransferCheck is the outcome of one transfer check.
+type TransferCheck struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"` // passed, failed or skipped
+	Message string `json:"message,omitempty"`
+	Details string `json:"details,omitempty"`
+}
+
+// TransferQuote is the fee a transfer would be charged, computed without executing it.
+type TransferQuote struct {
+	FromID     int     `json:"from_id"`
+	ToID       int     `json:"to_id"`
+	Amount     float64 `json:"amount"`
+	Currency   string  `json:"currency"`
+	Fee        float64 `json:"fee"`
+	FeeWaived  bool    `json:"fee_waived"`
+	FeeWaiver  string  `json:"fee_waiver,omitempty"`
+	TotalDebit float64 `json:"total_debit"` // Amount plus fee, taken from the source account
+}
+
+// BatchTransferRequest submits several transfers at once. Items are settled independently:
+// a failing item is recorded and doesn't stop the others.
+type BatchTransferRequest struct {
+	Items []TransferRequest `json:"items"`
+}
+
+// Webhook is an endpoint receiving the user's transaction events.
+type Webhook struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"-"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty" gorm:"serializer:encrypted"` // Signs the deliveries; only returned when the webhook is created
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookRequest is the request body for registering a webhook.
+type WebhookRequest struct {
+	URL string `json:"url"`
+}
+
+// WebhookDelivery is one event sent, or being sent, to a webhook.
+type WebhookDelivery struct {
+	ID            int        `json:"id"`
+	WebhookID     int        `json:"webhook_id"`
+	Event         string     `json:"event"`
+	Payload       string     `json:"payload"`
+	Status        string     `json:"status"`      // "pending", "delivered" or "failed"
+	StatusCode    int        `json:"status_code"` // HTTP status of the last attempt, 0 if it got no response
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"last_error,omitempty"`
+	LastAttemptAt *time.Time `json:"last_attempt_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// BatchTransfer is the settlement report of a batch of transfers.
+type BatchTransfer struct {
+	ID        int         `json:"id"`
+	UserID    int         `json:"user_id"`
+	CreatedAt time.Time   `json:"created_at"`
+	Totals    BatchTotals `json:"totals" gorm:"-"`
+	Items     []BatchItem `json:"items" gorm:"-"`
+}
+
+// BatchTotals summarizes the outcome of a batch.
+type BatchTotals struct {
+	Items           int     `json:"items"`
+	Completed       int     `json:"completed"` // Includes transfers pending approval
+	Failed          int     `json:"failed"`
+	RequestedAmount float64 `json:"requested_amount"`
+	SettledAmount   float64 `json:"settled_amount"` // Amount of completed and pending items
+}
+
+// BatchItem is the outcome of one transfer of a batch.
+type BatchItem struct {
+	ID            int     `json:"-"`
+	BatchID       int     `json:"-"`
+	Position      int     `json:"position"` // Index of the item in the request
+	FromID        int     `json:"from_id"`
+	ToID          int     `json:"to_id"`
+	Amount        float64 `json:"amount"`
+	Status        string  `json:"status"`                   // "failed" or the status of the created transaction
+	Error         string  `json:"error,omitempty"`          // Why the item failed
+	TransactionID *string `json:"transaction_id,omitempty"` // Transaction created for the item
+}
+
+// ConfirmationCode is a one-time code confirming a specific transfer. Only its HMAC is stored.
+type ConfirmationCode struct {
+	ID          int
+	UserID      int
+	Fingerprint string // Binds the code to the source, destination and amount of the transfer
+	CodeHash    string
+	Attempts    int
+	ExpiresAt   time.Time
+	UsedAt      *time.Time
+	CreatedAt   time.Time
+}
+
+// Payee represents a saved transfer recipient.
+type Payee struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"user_id"`
+	AccountID int       `json:"account_id"`
+	Label     string    `json:"label"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PayeeRequest represents a request for saving a payee.
+type PayeeRequest struct {
+	AccountID int    `json:"account_id"`
+	Label     string `json:"label"`
 }
 
 // Claims represents JWT claims.
 type Claims struct {
-	UserID uint `json:"user_id"`
+	UserID uint   `json:"user_id"`
+	Role   string `json:"role"`
+	// TwoFactor is set on tokens elevated with a TOTP code. AMR lists the authentication methods used.
+	TwoFactor bool     `json:"2fa,omitempty"`
+	AMR       []string `json:"amr,omitempty"`
+	// Stale is set by validation on a token past its expiry but within the grace period. Never serialized.
+	Stale bool `json:"-"`
+	// Sandbox tokens move money only between sandbox accounts, never touching real funds.
+	Sandbox bool `json:"sandbox,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// IntrospectionRequest is the request body for introspecting a token, as JSON or a form.
+type IntrospectionRequest struct {
+	Token string `json:"token" form:"token"`
+}
+
+// TokenIntrospection describes a token to another service. An inactive token has no other fields.
+type TokenIntrospection struct {
+	Active    bool     `json:"active"`
+	UserID    uint     `json:"user_id,omitempty"`
+	Role      string   `json:"role,omitempty"`
+	TwoFactor bool     `json:"2fa,omitempty"`
+	AMR       []string `json:"amr,omitempty"`
+	Sandbox   bool     `json:"sandbox,omitempty"`
+	Issuer    string   `json:"iss,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"`
+}
+
+// TokenInfo tells a client when its token expires, so it can log in again before it does.
+type TokenInfo struct {
+	IssuedAt   int64     `json:"iat"`
+	ExpiresAt  int64     `json:"exp"`
+	ExpiresIn  int64     `json:"expires_in"` // Seconds left until exp, 0 once expired
+	Stale      bool      `json:"stale"`      // Expired but within the grace period: reads only
+	ServerTime time.Time `json:"server_time"`
+}
+
+// TwoFactorSetup is the secret to enter into an authenticator app, directly or as an otpauth:// URL.
+type TwoFactorSetup struct {
+	Secret string `json:"secret"`
+	URL    string `json:"url"`
+}
+
+// ElevateRequest carries the TOTP code exchanged for an elevated token.
+type ElevateRequest struct {
+	Code string `json:"code"`
+}
+
 // Transaction represents a transaction in the database.
 type Transaction struct {
-	ID            string    `json:"id"`
-	FromAccountID *int      `json:"from_account_id"` // Nullable for deposits
-	ToAccountID   *int      `json:"to_account_id"`   // Nullable for withdrawals
-	Amount        float64   `json:"amount"`
-	Type          string    `json:"type"`
-	Status        string    `json:"status"`
-	CreatedAt     time.Time `json:"created_at"`
+	ID             string     `json:"id"`
+	FromAccountID  *int       `json:"from_account_id"` // Nullable for deposits
+	ToAccountID    *int       `json:"to_account_id"`   // Nullable for withdrawals
+	Amount         float64    `json:"amount"`
+	Type           string     `json:"type"`
+	Status         string     `json:"status"`
+	Description    string     `json:"description"`
+	Source         string     `json:"source,omitempty"` // Declared origin of a deposit
+	Fee            float64    `json:"fee"`
+	FeeWaived      bool       `json:"fee_waived"`
+	FeeWaiver      string     `json:"fee_waiver,omitempty"`      // Why the fee was waived
+	InitiatorID    *int       `json:"initiator_id"`              // User who created the transaction
+	ApproverID     *int       `json:"approver_id"`               // User who approved or rejected a pending transfer
+	FailureReason  string     `json:"failure_reason,omitempty"`  // Why a "failed" attempt was rejected
+	RefundedAmount float64    `json:"refunded_amount,omitempty"` // Total refunded so far, for transfers
+	RefundOf       *string    `json:"refund_of,omitempty"`       // Transfer a refund returns money of
+	CreatedAt      time.Time  `json:"created_at"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`  // When an approved or accepted transfer completed
+	Tags           []string   `json:"tags,omitempty" gorm:"-"` // Tags of the requesting user, filled in by history queries
+
+	// Reference is a human-friendly number for support, e.g. TXN-2024-000123. The database assigns
+	// it from a sequence on insert.
+	Reference string `json:"reference" gorm:"default:next_transaction_reference()"`
+
+	// SubBalance is the sub-balance a deposit was credited to; empty for the principal.
+	SubBalance string `json:"sub_balance,omitempty"`
+}
+
+// TransactionTag represents a user's tag on a transaction.
+type TransactionTag struct {
+	TransactionID string `json:"transaction_id"`
+	UserID        int    `json:"user_id"`
+	Tag           string `json:"tag"`
+}
+
+// TagsRequest represents a request for adding and removing transaction tags.
+type TagsRequest struct {
+	Add    []string `json:"add"`
+	Remove []string `json:"remove"`
+}
+
+// LedgerEntry is one leg of a completed transfer: a debit on the source account or a credit on the
+// destination. Legs of the same transfer share the group ID (the transaction ID) and net to zero.
+type LedgerEntry struct {
+	ID        int       `json:"id"`
+	GroupID   string    `json:"group_id"`
+	AccountID int       `json:"account_id"`
+	Direction string    `json:"direction"` // "debit" or "credit"
+	Amount    float64   `json:"amount"`    // Negative for debits
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Event is an entry of the append-only event log, written in the same database transaction as the
+// change it records. Seq increases in commit order, so consumers tail the log by the last Seq seen.
+type Event struct {
+	Seq              int64     `json:"seq" gorm:"primaryKey"`
+	Type             string    `json:"type"` // AccountCreated, Deposited, Withdrawn or Transferred
+	AccountID        int       `json:"account_id"`
+	CounterAccountID *int      `json:"counter_account_id,omitempty"` // Destination of a transfer
+	TransactionID    *string   `json:"transaction_id,omitempty"`
+	Amount           float64   `json:"amount"`
+	Fee              float64   `json:"fee"`
+	Currency         string    `json:"currency"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// EventFilter selects the events following a sequence number.
+type EventFilter struct {
+	After int64 // Only events with a greater Seq
+	Limit int
+}
+
+// LedgerFilter pages the ledger entries of an account.
+type LedgerFilter struct {
+	Limit  int
+	Offset int
+}
+
+// TransactionFilter narrows down the transaction history.
+type TransactionFilter struct {
+	AccountID      int    // Zero means all accounts of the user
+	CounterpartyID int    // Only transfers between AccountID and this account, either way. Zero means any
+	Tag            string // Empty means any
+	Limit          int
+	Offset         int    // Ignored when Cursor is set
+	Cursor         string // Continues after the page that returned it, see next cursor of ListTransactions
+}
+
+// AuditLog represents an entry of the audit trail.
+type AuditLog struct {
+	ID        int       `json:"id"`
+	UserID    *int      `json:"user_id"` // Actor, nil for system jobs
+	Action    string    `json:"action"`
+	Severity  string    `json:"severity"`
+	Details   string    `json:"details"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SuspiciousActivity is an event counted by the freeze rules, such as a failed integrity check.
+type SuspiciousActivity struct {
+	ID        int       `json:"id"`
+	AccountID int       `json:"account_id"`
+	Rule      string    `json:"rule"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Audit severities.
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// LoginAttempt is a successful or failed login of a user.
+type LoginAttempt struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"-"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	Outcome   string    `json:"outcome"` // "success" or "failed"
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LoginMeta describes where a login request came from.
+type LoginMeta struct {
+	IP        string
+	UserAgent string
+	Sandbox   bool // The client asked for a sandbox token
+}
+
+// LoginHistoryFilter pages the login history.
+type LoginHistoryFilter struct {
+	Limit  int
+	Offset int
+}
+
+// BalanceAuditReport is the result of a balance integrity scan.
+type BalanceAuditReport struct {
+	Scanned        int       `json:"scanned"`
+	FailedAccounts []int     `json:"failed_accounts"`
+	StartedAt      time.Time `json:"started_at"`
+	FinishedAt     time.Time `json:"finished_at"`
+}
+
+// BalanceLookupRequest names the accounts of a bulk balance lookup, by ID, by number or both.
+type BalanceLookupRequest struct {
+	AccountIDs     []int    `json:"account_ids"`
+	AccountNumbers []string `json:"account_numbers"` // In any supported format
+}
+
+// BalanceLookup is the result of a bulk balance lookup.
+type BalanceLookup struct {
+	Balances []AccountBalanceEntry `json:"balances"`
+	NotFound []string              `json:"not_found"` // Requested IDs and numbers matching no account, as given
+}
+
+// AccountBalanceEntry is the balance of one account in a bulk lookup. Balance is left out when the
+// balance hash doesn't match: the stored figure can't be trusted.
+type AccountBalanceEntry struct {
+	AccountID   int      `json:"account_id"`
+	UserID      int      `json:"user_id"`
+	Number      *string  `json:"number"`
+	Balance     *float64 `json:"balance"`
+	Currency    string   `json:"currency"`
+	IntegrityOK bool     `json:"integrity_ok"`
+}
+
+// Receipt is a signed summary of a transaction that can be handed to third parties.
+type Receipt struct {
+	TransactionID string  `json:"transaction_id"`
+	Type          string  `json:"type"`
+	Amount        float64 `json:"amount"`
+	Currency      string  `json:"currency"`
+	FromAccountID *int    `json:"from_account_id"`
+	ToAccountID   *int    `json:"to_account_id"`
+	Status        string  `json:"status"`
+	CreatedAt     string  `json:"created_at"` // RFC3339, UTC
+	Signature     string  `json:"signature"`
+}
+
+// ReceiptVerification is the result of verifying a receipt signature.
+type ReceiptVerification struct {
+	Valid   bool    `json:"valid"`
+	Receipt Receipt `json:"receipt"` // Echo of the submitted canonical fields
 }
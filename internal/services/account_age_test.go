@@ -0,0 +1,48 @@
+// Path: internal/services/account_age_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"testing"
+	"time"
+)
+
+func TestCheckAccountAge(t *testing.T) {
+	created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	clock := utils.NewManualClock(created.Add(30 * time.Minute))
+	s := NewTransactionService(nil, testSecret, TransactionConfig{MinAccountAge: time.Hour, Clock: clock}).(*transactionService)
+	account := &models.Account{ID: 3, CreatedAt: created}
+
+	err := s.checkAccountAge(account)
+	if appErr := wantAppError(t, err, 403); appErr.Details != "account_id: 3, available in: 30m0s" {
+		t.Errorf("details = %q", appErr.Details)
+	}
+
+	clock.Advance(30 * time.Minute)
+	if err := s.checkAccountAge(account); err != nil {
+		t.Errorf("account exactly MinAccountAge old: %v", err)
+	}
+
+	disabled := NewTransactionService(nil, testSecret, TransactionConfig{Clock: utils.NewManualClock(created)}).(*transactionService)
+	if err := disabled.checkAccountAge(account); err != nil {
+		t.Errorf("without MinAccountAge: %v", err)
+	}
+}
+
+func TestWithdrawFromNewAccount(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "newcomer")
+	account := seedAccount(t, db, user, 100, "USD") // Created a day ago
+	clock := utils.NewManualClock(account.CreatedAt.Add(time.Hour))
+	s := NewTransactionService(db, testSecret, TransactionConfig{MinAccountAge: 2 * time.Hour, Clock: clock})
+	withdraw := &models.TransactionRequest{AccountID: account.ID, Amount: 10}
+
+	_, err := s.ProcessWithdraw(withdraw, claimsFor(user))
+	wantAppError(t, err, 403)
+
+	clock.Advance(time.Hour + time.Second)
+	if _, err := s.ProcessWithdraw(withdraw, claimsFor(user)); err != nil {
+		t.Errorf("withdrawal from an aged account: %v", err)
+	}
+}
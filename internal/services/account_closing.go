@@ -0,0 +1,159 @@
+// Path: internal/services/account_closing.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultReopenWindow is how long a closed account can be reopened when no window is configured.
+const DefaultReopenWindow = 30 * 24 * time.Hour
+
+// checkNotClosed rejects operations on a closed account.
+func checkNotClosed(account *models.Account) error {
+	if account.ClosedAt != nil {
+		return &AppError{Code: 403, Message: "Account closed", Details: fmt.Sprintf("account_id: %d", account.ID)}
+	}
+	return nil
+}
+
+// checkDestinationOpen rejects transfers to a closed account.
+func checkDestinationOpen(toAccount *models.Account) error {
+	if toAccount.ClosedAt != nil {
+		return &AppError{Code: 403, Message: "Destination account closed", Details: fmt.Sprintf("account_id: %d", toAccount.ID)}
+	}
+	return nil
+}
+
+// CloseAccount closes an empty account of the user: its balance must be zero, with no active holds
+// and no transfers pending from or to it. A closed account keeps its history but takes no payments
+// until it is reopened.
+func (s *accountService) CloseAccount(claims *models.Claims, accountID int) (*models.Account, error) {
+	account, err := s.getOwnedAccount(claims, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.ClosedAt != nil {
+		return nil, &AppError{Code: 409, Message: "Account is already closed", Details: fmt.Sprintf("account_id: %d", accountID)}
+	}
+	if err := s.verifyIntegrity(account); err != nil {
+		return nil, err
+	}
+	if err := checkZeroBalance(account); err != nil {
+		return nil, err
+	}
+
+	now := s.cfg.Clock.Now()
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var holds, pending int64
+		if err := tx.Model(&models.Hold{}).Where("account_id = ? AND status = ?", accountID, HoldActive).Count(&holds).Error; err != nil {
+			return &AppError{Code: 500, Message: "Failed to query holds", Details: err.Error(), Err: err}
+		}
+		err := tx.Model(&models.Transaction{}).
+			Where("(from_account_id = ? OR to_account_id = ?) AND status IN ?", accountID, accountID, []string{"pending_approval", "incoming_pending"}).
+			Count(&pending).Error
+		if err != nil {
+			return &AppError{Code: 500, Message: "Failed to query pending transfers", Details: err.Error(), Err: err}
+		}
+		if holds > 0 || pending > 0 {
+			return &AppError{Code: 409, Message: "Account has pending operations", Details: fmt.Sprintf("account_id: %d, active holds: %d, pending transfers: %d", accountID, holds, pending)}
+		}
+
+		// The balance hash pins the zero balance checked above.
+		result := tx.Model(&models.Account{}).
+			Where("id = ? AND balance_hash = ? AND closed_at IS NULL", accountID, account.BalanceHash).
+			Update("closed_at", now)
+		if result.Error != nil {
+			return &AppError{Code: 500, Message: "Failed to close account", Details: result.Error.Error(), Err: result.Error}
+		}
+		if result.RowsAffected == 0 {
+			return &AppError{Code: 409, Message: "Account changed while closing", Details: fmt.Sprintf("account_id: %d", accountID)}
+		}
+		return recordAccountAudit(tx, claims.UserID, "account_closed", accountID, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	account.ClosedAt = &now
+	return account, nil
+}
+
+// ReopenAccount makes a closed account usable again. Owners reopen their own accounts, admins any
+// account; either way the account must have been closed within the reopen window and its balance
+// must still be a verified zero.
+func (s *accountService) ReopenAccount(claims *models.Claims, accountID int) (*models.Account, error) {
+	var account *models.Account
+	var err error
+	if claims.Role == models.RoleAdmin {
+		account, err = s.cfg.Accounts.Find(accountID)
+		if err != nil {
+			err = accountLookupError(err, claims.UserID, accountID)
+		}
+	} else {
+		account, err = s.getOwnedAccount(claims, accountID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if account.ClosedAt == nil {
+		return nil, &AppError{Code: 409, Message: "Account is not closed", Details: fmt.Sprintf("account_id: %d", accountID)}
+	}
+	if err := s.verifyIntegrity(account); err != nil {
+		return nil, err
+	}
+	if err := checkZeroBalance(account); err != nil {
+		return nil, err
+	}
+	now := s.cfg.Clock.Now()
+	if now.Sub(*account.ClosedAt) > s.cfg.ReopenWindow {
+		return nil, &AppError{Code: 409, Message: "Account was closed too long ago to reopen", Details: fmt.Sprintf("account_id: %d, closed at %s, reopen window: %s", accountID, account.ClosedAt.Format(time.RFC3339), s.cfg.ReopenWindow)}
+	}
+
+	// The account starts a new period of activity, so it doesn't turn dormant right away.
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Account{}).
+			Where("id = ? AND balance_hash = ? AND closed_at IS NOT NULL", accountID, account.BalanceHash).
+			Updates(map[string]interface{}{"closed_at": nil, "last_activity_at": now})
+		if result.Error != nil {
+			return &AppError{Code: 500, Message: "Failed to reopen account", Details: result.Error.Error(), Err: result.Error}
+		}
+		if result.RowsAffected == 0 {
+			return &AppError{Code: 409, Message: "Account is not closed", Details: fmt.Sprintf("account_id: %d", accountID)}
+		}
+		return recordAccountAudit(tx, claims.UserID, "account_reopened", accountID, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	account.ClosedAt, account.LastActivityAt = nil, &now
+	return account, nil
+}
+
+// checkZeroBalance rejects closing or reopening an account holding money.
+func checkZeroBalance(account *models.Account) error {
+	if account.Balance != 0 {
+		return &AppError{Code: 409, Message: "Account balance is not zero", Details: fmt.Sprintf("account_id: %d, balance: %.2f", account.ID, account.Balance)}
+	}
+	return nil
+}
+
+// recordAccountAudit writes an audit log entry for a change of an account's state by actorID.
+func recordAccountAudit(tx *gorm.DB, actorID uint, action string, accountID int, at time.Time) error {
+	actor := int(actorID)
+	entry := models.AuditLog{
+		UserID:    &actor,
+		Action:    action,
+		Severity:  models.SeverityInfo,
+		Details:   fmt.Sprintf("account_id: %d", accountID),
+		CreatedAt: at,
+	}
+	if err := tx.Create(&entry).Error; err != nil {
+		return &AppError{Code: 500, Message: "Failed to write audit log", Details: err.Error(), Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+// Path: internal/services/account_closing_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"testing"
+	"time"
+)
+
+func TestCheckNotClosed(t *testing.T) {
+	now := time.Now()
+	if err := checkNotClosed(&models.Account{ID: 1}); err != nil {
+		t.Errorf("open account: %v", err)
+	}
+	wantAppError(t, checkNotClosed(&models.Account{ID: 1, ClosedAt: &now}), 403)
+	wantAppError(t, checkDestinationOpen(&models.Account{ID: 1, ClosedAt: &now}), 403)
+	wantAppError(t, checkZeroBalance(&models.Account{ID: 1, Balance: 0.01}), 409)
+}
+
+func TestCloseAndReopenAccount(t *testing.T) {
+	db := testDB(t)
+	alice, bob := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+	empty, funded := seedAccount(t, db, alice, 0, "USD"), seedAccount(t, db, alice, 50, "USD")
+	other := seedAccount(t, db, bob, 20, "USD")
+
+	clock := utils.NewManualClock(time.Now())
+	accounts := NewAccountService(db, testSecret, AccountConfig{Clock: clock, ReopenWindow: 10 * 24 * time.Hour})
+	transactions := NewTransactionService(db, testSecret, TransactionConfig{Clock: clock})
+
+	// Only empty accounts of the owner close.
+	_, err := accounts.CloseAccount(claimsFor(alice), funded.ID)
+	wantAppError(t, err, 409)
+	_, err = accounts.CloseAccount(claimsFor(bob), empty.ID)
+	wantAppError(t, err, 404)
+	closed, err := accounts.CloseAccount(claimsFor(alice), empty.ID)
+	if err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if closed.ClosedAt == nil || reloadAccount(t, db, empty.ID).ClosedAt == nil {
+		t.Fatal("closed account has no closed_at")
+	}
+	_, err = accounts.CloseAccount(claimsFor(alice), empty.ID)
+	wantAppError(t, err, 409)
+
+	// A closed account takes no payments either way.
+	_, err = transactions.ProcessDeposit(&models.TransactionRequest{AccountID: empty.ID, Amount: 5}, claimsFor(alice))
+	wantAppError(t, err, 403)
+	err = transactions.ProcessTransfer(&models.TransferRequest{FromID: other.ID, ToID: empty.ID, Amount: 5}, claimsFor(bob))
+	wantAppError(t, err, 403)
+	if got := reloadAccount(t, db, other.ID).Balance; got != 20 {
+		t.Errorf("sender balance = %v, want 20", got)
+	}
+
+	// Within the window the owner reopens it, and it works again.
+	clock.Advance(5 * 24 * time.Hour)
+	if _, err := accounts.ReopenAccount(claimsFor(alice), empty.ID); err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	_, err = accounts.ReopenAccount(claimsFor(alice), empty.ID)
+	wantAppError(t, err, 409)
+	if _, err := transactions.ProcessDeposit(&models.TransactionRequest{AccountID: empty.ID, Amount: 5}, claimsFor(alice)); err != nil {
+		t.Errorf("deposit after reopening: %v", err)
+	}
+}
+
+func TestReopenAccountLimits(t *testing.T) {
+	db := testDB(t)
+	alice, admin := seedUser(t, db, "alice"), seedAdmin(t, db, "admin")
+	stale, recent, funded := seedAccount(t, db, alice, 0, "USD"), seedAccount(t, db, alice, 0, "USD"), seedAccount(t, db, alice, 0, "USD")
+
+	clock := utils.NewManualClock(time.Now())
+	accounts := NewAccountService(db, testSecret, AccountConfig{Clock: clock, ReopenWindow: 10 * 24 * time.Hour})
+	for _, id := range []int{stale.ID, funded.ID} {
+		if _, err := accounts.CloseAccount(claimsFor(alice), id); err != nil {
+			t.Fatalf("close %d: %v", id, err)
+		}
+	}
+
+	// Money that reached a closed account behind the service's back blocks reopening it.
+	account := reloadAccount(t, db, funded.ID)
+	account.Balance = 5
+	if err := db.Model(account).Updates(map[string]interface{}{"balance": 5, "balance_hash": balanceHash(account, testSecret)}).Error; err != nil {
+		t.Fatal(err)
+	}
+	_, err := accounts.ReopenAccount(claimsFor(alice), funded.ID)
+	wantAppError(t, err, 409)
+
+	// Past the window the account stays closed, for the owner and the admin alike.
+	clock.Advance(11 * 24 * time.Hour)
+	_, err = accounts.ReopenAccount(claimsFor(alice), stale.ID)
+	wantAppError(t, err, 409)
+	_, err = accounts.ReopenAccount(claimsFor(admin), stale.ID)
+	wantAppError(t, err, 409)
+	if reloadAccount(t, db, stale.ID).ClosedAt == nil {
+		t.Error("stale account was reopened")
+	}
+
+	// An admin reopens an account of another user within the window.
+	if _, err := accounts.CloseAccount(claimsFor(alice), recent.ID); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if _, err := accounts.ReopenAccount(claimsFor(admin), recent.ID); err != nil {
+		t.Fatalf("admin reopen: %v", err)
+	}
+	if reloadAccount(t, db, recent.ID).ClosedAt != nil {
+		t.Error("account is still closed after the admin reopened it")
+	}
+}
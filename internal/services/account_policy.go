@@ -0,0 +1,117 @@
+// Path: internal/services/account_policy.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultSavingsWithdrawalsPerMonth is the default number of outgoing payments a savings account allows per month.
+const DefaultSavingsWithdrawalsPerMonth = 6
+
+// AccountPolicy holds the business rules of one account type. Transaction services consult
+// the policy of the account instead of branching on its type.
+type AccountPolicy interface {
+	// CheckDebit reports whether amount may leave the account, given its available balance.
+	CheckDebit(tx *gorm.DB, account *models.Account, available, amount float64, now time.Time) error
+	// MinBalance is the floor the balance may not go below.
+	MinBalance() float64
+	// InterestRate is the yearly interest paid on a positive balance, in percent. Zero pays none.
+	InterestRate() float64
+}
+
+// AccountPolicyConfig holds the tunable settings of the account type policies.
+type AccountPolicyConfig struct {
+	// OverdraftLimit is how far below zero an overdraft account may go.
+	OverdraftLimit float64
+	// SavingsWithdrawalsPerMonth caps outgoing payments from a savings account per calendar month.
+	// Defaults to DefaultSavingsWithdrawalsPerMonth.
+	SavingsWithdrawalsPerMonth int
+	// SavingsInterestRate is the yearly interest rate of savings accounts in percent, e.g. 2.5.
+	SavingsInterestRate float64
+}
+
+// newAccountPolicies builds the policy of every supported account type.
+func newAccountPolicies(cfg AccountPolicyConfig) map[string]AccountPolicy {
+	if cfg.SavingsWithdrawalsPerMonth <= 0 {
+		cfg.SavingsWithdrawalsPerMonth = DefaultSavingsWithdrawalsPerMonth
+	}
+	return map[string]AccountPolicy{
+		models.AccountTypeChecking:  minBalancePolicy{},
+		models.AccountTypeSavings:   savingsPolicy{withdrawalsPerMonth: cfg.SavingsWithdrawalsPerMonth, interestRate: cfg.SavingsInterestRate},
+		models.AccountTypeOverdraft: minBalancePolicy{minBalance: -cfg.OverdraftLimit},
+	}
+}
+
+// IsSupportedAccountType reports whether accounts of the type can be opened.
+func IsSupportedAccountType(accountType string) bool {
+	switch accountType {
+	case models.AccountTypeChecking, models.AccountTypeSavings, models.AccountTypeOverdraft:
+		return true
+	}
+	return false
+}
+
+// policyFor returns the policy of the account's type. Unknown types get the checking rules.
+func (s *transactionService) policyFor(account *models.Account) AccountPolicy {
+	if policy, ok := s.policies[account.Type]; ok {
+		return policy
+	}
+	return s.policies[models.AccountTypeChecking]
+}
+
+// minBalancePolicy lets the available balance go down to minBalance.
+type minBalancePolicy struct {
+	minBalance float64
+}
+
+func (p minBalancePolicy) CheckDebit(_ *gorm.DB, account *models.Account, available, amount float64, _ time.Time) error {
+	if available-amount < p.minBalance {
+		return &AppError{Code: 400, Message: "Insufficient funds", Details: fmt.Sprintf("account_id: %d, balance: %f, available: %f, requested: %f", account.ID, account.Balance, available-p.minBalance, amount)}
+	}
+	return nil
+}
+
+func (p minBalancePolicy) MinBalance() float64 {
+	return p.minBalance
+}
+
+func (p minBalancePolicy) InterestRate() float64 {
+	return 0
+}
+
+// savingsPolicy can't go below zero and caps the number of outgoing payments per calendar month.
+type savingsPolicy struct {
+	withdrawalsPerMonth int
+	interestRate        float64
+}
+
+func (p savingsPolicy) CheckDebit(tx *gorm.DB, account *models.Account, available, amount float64, now time.Time) error {
+	if err := (minBalancePolicy{}).CheckDebit(tx, account, available, amount, now); err != nil {
+		return err
+	}
+
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	var count int64
+	err := tx.Model(&models.Transaction{}).
+		Where("from_account_id = ? AND type NOT IN ? AND status NOT IN ? AND created_at >= ?", account.ID, []string{"adjustment", "dormancy_fee"}, []string{"rejected", "cancelled", "declined", "failed"}, monthStart).
+		Count(&count).Error
+	if err != nil {
+		return &AppError{Code: 500, Message: "Failed to count monthly withdrawals", Details: err.Error(), Err: err}
+	}
+	if count >= int64(p.withdrawalsPerMonth) {
+		return &AppError{Code: 400, Message: "Savings withdrawal limit reached", Details: fmt.Sprintf("A savings account allows %d outgoing payments per month", p.withdrawalsPerMonth)}
+	}
+	return nil
+}
+
+func (p savingsPolicy) MinBalance() float64 {
+	return 0
+}
+
+func (p savingsPolicy) InterestRate() float64 {
+	return p.interestRate
+}
@@ -0,0 +1,78 @@
+// Path: internal/services/account_policy_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"testing"
+	"time"
+)
+
+func TestAccountPoliciesDifferForTheSameDebit(t *testing.T) {
+	policies := newAccountPolicies(AccountPolicyConfig{OverdraftLimit: 100, SavingsInterestRate: 2.5})
+	now := time.Now()
+
+	checking := &models.Account{ID: 1, Type: models.AccountTypeChecking, Balance: 10}
+	err := policies[models.AccountTypeChecking].CheckDebit(nil, checking, 10, 50, now)
+	if appErr := wantAppError(t, err, 400); appErr.Message != "Insufficient funds" {
+		t.Errorf("checking: %q", appErr.Message)
+	}
+
+	overdraft := &models.Account{ID: 2, Type: models.AccountTypeOverdraft, Balance: 10}
+	if err := policies[models.AccountTypeOverdraft].CheckDebit(nil, overdraft, 10, 50, now); err != nil {
+		t.Errorf("overdraft within its limit: %v", err)
+	}
+	wantAppError(t, policies[models.AccountTypeOverdraft].CheckDebit(nil, overdraft, 10, 111, now), 400)
+
+	tests := []struct {
+		accountType string
+		minBalance  float64
+		interest    float64
+	}{
+		{models.AccountTypeChecking, 0, 0},
+		{models.AccountTypeSavings, 0, 2.5},
+		{models.AccountTypeOverdraft, -100, 0},
+	}
+	for _, tt := range tests {
+		policy := policies[tt.accountType]
+		if policy.MinBalance() != tt.minBalance || policy.InterestRate() != tt.interest {
+			t.Errorf("%s: min balance %v, interest %v; want %v and %v", tt.accountType, policy.MinBalance(), policy.InterestRate(), tt.minBalance, tt.interest)
+		}
+	}
+}
+
+func TestPolicyForUnknownTypeIsChecking(t *testing.T) {
+	s := NewTransactionService(nil, testSecret, TransactionConfig{Policies: AccountPolicyConfig{OverdraftLimit: 100}}).(*transactionService)
+	if got := s.policyFor(&models.Account{Type: "legacy"}).MinBalance(); got != 0 {
+		t.Errorf("unknown type min balance = %v, want the checking floor 0", got)
+	}
+	if !IsSupportedAccountType(models.AccountTypeSavings) || IsSupportedAccountType("legacy") {
+		t.Error("IsSupportedAccountType disagrees with the policies")
+	}
+}
+
+func TestSavingsCapsMonthlyWithdrawals(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "saver")
+	checking := seedAccount(t, db, user, 100, "USD")
+	savings := seedAccount(t, db, user, 100, "USD")
+	if err := db.Model(savings).Update("type", models.AccountTypeSavings).Error; err != nil {
+		t.Fatalf("make savings: %v", err)
+	}
+	s := NewTransactionService(db, testSecret, TransactionConfig{Policies: AccountPolicyConfig{SavingsWithdrawalsPerMonth: 2}})
+
+	for i := 0; i < 3; i++ {
+		_, err := s.ProcessWithdraw(&models.TransactionRequest{AccountID: checking.ID, Amount: 1}, claimsFor(user))
+		if err != nil {
+			t.Errorf("checking withdrawal %d: %v", i+1, err)
+		}
+		_, err = s.ProcessWithdraw(&models.TransactionRequest{AccountID: savings.ID, Amount: 1}, claimsFor(user))
+		if i < 2 && err != nil {
+			t.Errorf("savings withdrawal %d: %v", i+1, err)
+		}
+		if i == 2 {
+			if appErr := wantAppError(t, err, 400); appErr.Message != "Savings withdrawal limit reached" {
+				t.Errorf("third savings withdrawal: %q", appErr.Message)
+			}
+		}
+	}
+}
@@ -3,43 +3,320 @@ package services
 
 import (
 	"bank-api/internal/models"
+	"bank-api/pkg/accountnumber"
 	"bank-api/pkg/utils"
+	"context"
+	"errors"
 	"fmt"
+	"regexp"
+	"time"
+
 	"gorm.io/gorm"
 )
 
+// currencyCodePattern matches ISO 4217 currency codes.
+var currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// MaxNicknameLength is the maximum account nickname length in characters.
+const MaxNicknameLength = 50
+
 // AccountService handles account-related operations.
 type AccountService interface {
-	GetAccounts(userID uint) ([]models.Account, error)
+	GetAccounts(userID uint, sandbox bool) ([]models.Account, error)
+	GetAccount(claims *models.Claims, accountID int) (*models.Account, error)
+	GetBalanceProof(claims *models.Claims, accountID int) (*models.BalanceProof, error)
+	BalanceAt(userID uint, accountID int, at time.Time) (*models.HistoricalBalance, error)
+	FlagDormant() (int, error)
+	AddBeneficiary(userID uint, accountID int, req *models.BeneficiaryRequest) (*models.Beneficiary, error)
+	GetBeneficiaries(userID uint, accountID int) ([]models.Beneficiary, error)
+	Reactivate(userID uint, accountID int) (*models.Account, error)
+	CloseAccount(claims *models.Claims, accountID int) (*models.Account, error)
+	ReopenAccount(claims *models.Claims, accountID int) (*models.Account, error)
+	SetNickname(claims *models.Claims, accountID int, req *models.UpdateAccountRequest) (*models.Account, error)
+	GetNetWorth(userID uint, sandbox bool) (*models.NetWorth, error)
+	CreateAccount(userID uint, req *models.CreateAccountRequest) (*models.Account, error)
+	GetRoundUp(userID uint) (*models.RoundUpSettings, error)
+	SetRoundUp(userID uint, req *models.RoundUpSettings) (*models.RoundUpSettings, error)
+	Unfreeze(actorID uint, accountID int) (*models.Account, error)
+	GetTransferWhitelist(userID uint, accountID int) (*models.TransferWhitelist, error)
+	SetTransferWhitelist(userID uint, accountID int, req *models.TransferWhitelist) (*models.TransferWhitelist, error)
+	GetCosigners(userID uint, accountID int) (*models.Cosigners, error)
+	SetCosigners(userID uint, accountID int, req *models.Cosigners) (*models.Cosigners, error)
+	LookupBalances(req *models.BalanceLookupRequest) (*models.BalanceLookup, error)
+}
+
+// AccountConfig holds the tunable settings of the account service.
+type AccountConfig struct {
+	// BaseCurrency enables converted totals in GetNetWorth. Empty disables conversion.
+	BaseCurrency string
+	// Rates converts balances into BaseCurrency. Required when BaseCurrency is set.
+	Rates ExchangeRateProvider
+	// Clock supplies the current time. Defaults to the wall clock.
+	Clock utils.Clock
+	// AccountNumbers generates numbers for new accounts. Defaults to the internal format.
+	AccountNumbers accountnumber.Scheme
+	// Dormancy marks accounts without activity as dormant.
+	Dormancy DormancyConfig
+	// Accounts stores the accounts read outside transactions. Defaults to the gorm repository over db.
+	Accounts AccountRepository
+	// IntegrityAlerter is told about failed balance integrity checks. Defaults to NoopIntegrityAlerter.
+	IntegrityAlerter IntegrityAlerter
+	// SubBalances sets the order the dormancy fee draws from the sub-balances, as for transactions.
+	SubBalances SubBalanceConfig
+	// ReopenWindow is how long after closing an account can be reopened. Defaults to DefaultReopenWindow.
+	ReopenWindow time.Duration
 }
 
 type accountService struct {
 	db        *gorm.DB
 	secretKey string
+	cfg       AccountConfig
 }
 
 // NewAccountService creates a new AccountService.
-func NewAccountService(db *gorm.DB, secretKey string) AccountService {
+func NewAccountService(db *gorm.DB, secretKey string, cfg AccountConfig) AccountService {
+	if cfg.Clock == nil {
+		cfg.Clock = utils.RealClock
+	}
+	if cfg.AccountNumbers == nil {
+		cfg.AccountNumbers = accountnumber.Internal{}
+	}
+	if cfg.IntegrityAlerter == nil {
+		cfg.IntegrityAlerter = NoopIntegrityAlerter{}
+	}
+	if cfg.Accounts == nil {
+		cfg.Accounts = NewAccountRepository(db)
+	}
+	if cfg.ReopenWindow <= 0 {
+		cfg.ReopenWindow = DefaultReopenWindow
+	}
+	cfg.SubBalances.normalize()
 	return &accountService{
 		db:        db,
 		secretKey: secretKey,
+		cfg:       cfg,
 	}
 }
 
-// GetAccounts retrieves all accounts for a given user.
-func (s *accountService) GetAccounts(userID uint) ([]models.Account, error) {
-	var accounts []models.Account
-	if err := s.db.Where("user_id = ?", userID).Find(&accounts).Error; err != nil {
+// WithContext returns a copy of the service whose queries run under ctx, so a request's deadline
+// and cancellation reach the database.
+func (s *accountService) WithContext(ctx context.Context) AccountService {
+	bound := *s
+	bound.db = dbWithContext(s.db, ctx)
+	bound.cfg.Accounts = accountsWithContext(s.cfg.Accounts, ctx)
+	return &bound
+}
+
+// GetAccounts retrieves the sandbox or the real accounts of a user.
+func (s *accountService) GetAccounts(userID uint, sandbox bool) ([]models.Account, error) {
+	accounts, err := s.cfg.Accounts.ListByUser(userID, sandbox)
+	if err != nil {
 		return nil, &AppError{Code: 500, Message: "Failed to query accounts", Details: err.Error(), Err: err}
 	}
 
 	for _, acc := range accounts {
-		// Verify balance integrity
-		expectedHash := utils.CreateHMAC(fmt.Sprintf("%f:%d", acc.Balance, acc.ID), []byte(s.secretKey))
-		if acc.BalanceHash != expectedHash {
-			return nil, &AppError{Code: 500, Message: "Balance integrity check failed", Details: fmt.Sprintf("account_id: %d", acc.ID)}
+		if err := s.verifyIntegrity(&acc); err != nil {
+			return nil, err
 		}
 	}
 
 	return accounts, nil
 }
+
+// GetAccount retrieves a single account owned by the user, of the mode (sandbox or real) of
+// their token.
+func (s *accountService) GetAccount(claims *models.Claims, accountID int) (*models.Account, error) {
+	account, err := s.getOwnedAccount(claims, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verifyIntegrity(account); err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+// GetBalanceProof returns the stored balance of an account with its balance hash, so an auditor
+// holding the balance secret can verify it independently. Only the owner and admins can read it.
+// The read takes no locks; the proof holds for the balance returned with it.
+func (s *accountService) GetBalanceProof(claims *models.Claims, accountID int) (*models.BalanceProof, error) {
+	var account *models.Account
+	var err error
+	if claims.Role == models.RoleAdmin {
+		account, err = s.cfg.Accounts.Find(accountID)
+	} else {
+		account, err = s.cfg.Accounts.FindOwned(claims.UserID, accountID, claims.Sandbox)
+	}
+	if err != nil {
+		return nil, accountLookupError(err, claims.UserID, accountID)
+	}
+
+	return &models.BalanceProof{
+		AccountID: account.ID,
+		Balance:   account.Balance,
+		Currency:  account.Currency,
+		Message:   utils.AccountHashMessage(account.Balance, account.ID, account.SubBalances),
+		Algorithm: "HMAC-SHA256",
+		Proof:     account.BalanceHash,
+		AsOf:      s.cfg.Clock.Now(),
+	}, nil
+}
+
+// SetNickname sets or clears (empty nickname) the friendly name of an owned account.
+func (s *accountService) SetNickname(claims *models.Claims, accountID int, req *models.UpdateAccountRequest) (*models.Account, error) {
+	if err := validateUpdateAccountRequest(req); err != nil {
+		return nil, err
+	}
+	nickname := req.Nickname
+
+	account, err := s.getOwnedAccount(claims, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cfg.Accounts.UpdateNickname(account.ID, nickname); err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to update account nickname", Details: err.Error(), Err: err}
+	}
+	account.Nickname = nickname
+
+	return account, nil
+}
+
+// CreateAccount opens a new empty account for the user.
+func (s *accountService) CreateAccount(userID uint, req *models.CreateAccountRequest) (*models.Account, error) {
+	if err := validateCreateAccountRequest(req); err != nil {
+		return nil, err
+	}
+
+	account := models.Account{
+		UserID:   int(userID),
+		Currency: req.Currency,
+		Nickname: req.Nickname,
+		Type:     req.Type,
+		Sandbox:  req.Sandbox,
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		return createAccount(tx, &account, s.secretKey, s.cfg.Clock.Now(), s.cfg.AccountNumbers)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+// createAccount inserts an account, assigns its number, signs its initial balance and logs its creation.
+// Both the number and the hash derive from the account ID, so they are set once the row exists.
+func createAccount(tx *gorm.DB, account *models.Account, secretKey string, now time.Time, numbers accountnumber.Scheme) error {
+	if account.Type == "" {
+		account.Type = models.AccountTypeChecking
+	}
+	account.BalanceHash = "pending"
+	account.CreatedAt = now
+	if err := tx.Create(account).Error; err != nil {
+		return &AppError{Code: 500, Message: "Failed to create account", Details: err.Error(), Err: err}
+	}
+
+	number := numbers.Generate(uint(account.ID))
+	account.Number = &number
+	account.BalanceHash = balanceHash(account, secretKey)
+	if err := tx.Model(account).Updates(map[string]interface{}{"number": number, "balance_hash": account.BalanceHash}).Error; err != nil {
+		return &AppError{Code: 500, Message: "Failed to finalize account", Details: err.Error(), Err: err}
+	}
+
+	return appendEvent(tx, EventAccountCreated, account, nil)
+}
+
+// resolveAccountNumber finds the account ID for a number given in any supported format.
+func resolveAccountNumber(tx *gorm.DB, number string, configured accountnumber.Scheme) (int, error) {
+	normalized, err := accountnumber.ValidateAny(number, accountnumber.All(configured)...)
+	if err != nil {
+		return 0, &AppError{Code: 400, Message: "Invalid account number", Details: fmt.Sprintf("account_number: %s", accountnumber.Normalize(number))}
+	}
+
+	var account models.Account
+	if err := tx.Select("id").Where("number = ?", normalized).First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, &AppError{Code: 404, Message: "Destination account not found", Details: fmt.Sprintf("account_number: %s", normalized)}
+		}
+		return 0, &AppError{Code: 500, Message: "Failed to query account", Details: err.Error(), Err: err}
+	}
+
+	return account.ID, nil
+}
+
+// GetNetWorth sums the balances of all user accounts per currency. Currencies are never
+// summed together unless a base currency is configured, in which case a converted total is added.
+func (s *accountService) GetNetWorth(userID uint, sandbox bool) (*models.NetWorth, error) {
+	accounts, err := s.GetAccounts(userID, sandbox) // Verifies the integrity of every account
+	if err != nil {
+		return nil, err
+	}
+
+	netWorth := &models.NetWorth{Totals: make(map[string]float64)}
+	for _, acc := range accounts {
+		currency := acc.Currency
+		if currency == "" {
+			currency = DefaultCurrency
+		}
+		netWorth.Totals[currency] += acc.Balance
+	}
+
+	if s.cfg.BaseCurrency != "" && s.cfg.Rates != nil {
+		var converted float64
+		for currency, total := range netWorth.Totals {
+			rate, err := s.cfg.Rates.Rate(currency, s.cfg.BaseCurrency)
+			if err != nil {
+				return nil, &AppError{Code: 500, Message: "Failed to convert balance", Details: fmt.Sprintf("%s -> %s: %v", currency, s.cfg.BaseCurrency, err), Err: err}
+			}
+			converted += total * rate
+		}
+		netWorth.BaseCurrency = s.cfg.BaseCurrency
+		netWorth.ConvertedTotal = &converted
+	}
+
+	return netWorth, nil
+}
+
+// getOwnedAccount loads an account through the repository and checks it belongs to the user.
+// Accounts of the other mode (sandbox or real) than the token are treated as not found.
+func (s *accountService) getOwnedAccount(claims *models.Claims, accountID int) (*models.Account, error) {
+	account, err := s.cfg.Accounts.FindOwned(claims.UserID, accountID, claims.Sandbox)
+	if err != nil {
+		return nil, accountLookupError(err, claims.UserID, accountID)
+	}
+	return account, nil
+}
+
+// findOwnedAccount loads an account of either mode within db, typically a transaction, and checks
+// it belongs to the user.
+func (s *accountService) findOwnedAccount(db *gorm.DB, userID uint, accountID int) (*models.Account, error) {
+	account, err := NewAccountRepository(db).Find(accountID)
+	if err == nil && account.UserID != int(userID) {
+		err = ErrNotFound
+	}
+	if err != nil {
+		return nil, accountLookupError(err, userID, accountID)
+	}
+	return account, nil
+}
+
+// accountLookupError translates a repository error for an account of the user.
+func accountLookupError(err error, userID uint, accountID int) error {
+	if errors.Is(err, ErrNotFound) {
+		return &AppError{Code: 404, Message: "Account not found or access denied", Details: fmt.Sprintf("account_id: %d, user_id: %d", accountID, userID)}
+	}
+	return &AppError{Code: 500, Message: "Failed to query account", Details: err.Error(), Err: err}
+}
+
+// verifyIntegrity checks the stored balance hash of an account.
+func (s *accountService) verifyIntegrity(acc *models.Account) error {
+	expectedHash := balanceHash(acc, s.secretKey)
+	if acc.BalanceHash != expectedHash {
+		reportIntegrityFailure(s.db, s.cfg.IntegrityAlerter, s.cfg.Clock.Now(), acc.ID)
+		return &AppError{Code: 500, Message: "Balance integrity check failed", Details: fmt.Sprintf("account_id: %d", acc.ID)}
+	}
+
+	return nil
+}
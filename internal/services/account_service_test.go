@@ -0,0 +1,153 @@
+// Path: internal/services/account_service_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/accountnumber"
+	"bank-api/pkg/utils"
+	"strings"
+	"testing"
+)
+
+func TestSetNickname(t *testing.T) {
+	accounts := newMemAccounts(models.Account{ID: 1, UserID: 10, Balance: 50, Currency: "USD"})
+	s := NewAccountService(nil, testSecret, AccountConfig{Accounts: accounts})
+
+	account, err := s.SetNickname(&models.Claims{UserID: 10}, 1, &models.UpdateAccountRequest{Nickname: "  Holidays  "})
+	if err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if account.Nickname != "Holidays" || accounts.accounts[1].Nickname != "Holidays" {
+		t.Errorf("nickname = %q, stored %q; want Holidays", account.Nickname, accounts.accounts[1].Nickname)
+	}
+
+	account, err = s.SetNickname(&models.Claims{UserID: 10}, 1, &models.UpdateAccountRequest{Nickname: ""})
+	if err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	if account.Nickname != "" || accounts.accounts[1].Nickname != "" {
+		t.Errorf("nickname = %q, stored %q; want it cleared", account.Nickname, accounts.accounts[1].Nickname)
+	}
+
+	_, err = s.SetNickname(&models.Claims{UserID: 10}, 1, &models.UpdateAccountRequest{Nickname: strings.Repeat("n", MaxNicknameLength+1)})
+	wantAppError(t, err, 400)
+}
+
+func TestSetNicknameOfAnotherUsersAccount(t *testing.T) {
+	accounts := newMemAccounts(models.Account{ID: 1, UserID: 10, Currency: "USD", Nickname: "Mine"})
+	s := NewAccountService(nil, testSecret, AccountConfig{Accounts: accounts})
+
+	_, err := s.SetNickname(&models.Claims{UserID: 20}, 1, &models.UpdateAccountRequest{Nickname: "Yours"})
+	wantAppError(t, err, 404)
+	if got := accounts.accounts[1].Nickname; got != "Mine" {
+		t.Errorf("stored nickname = %q, want it unchanged", got)
+	}
+}
+
+func TestGetNetWorthSingleCurrency(t *testing.T) {
+	accounts := newMemAccounts(
+		models.Account{ID: 1, UserID: 10, Balance: 100.5, Currency: "USD"},
+		models.Account{ID: 2, UserID: 10, Balance: 49.5, Currency: "USD"},
+		models.Account{ID: 3, UserID: 20, Balance: 1000, Currency: "USD"},
+	)
+	s := NewAccountService(nil, testSecret, AccountConfig{Accounts: accounts})
+
+	netWorth, err := s.GetNetWorth(10, false)
+	if err != nil {
+		t.Fatalf("net worth: %v", err)
+	}
+	if len(netWorth.Totals) != 1 || netWorth.Totals["USD"] != 150 || netWorth.ConvertedTotal != nil {
+		t.Errorf("net worth = %+v, want 150 USD and no conversion", netWorth)
+	}
+}
+
+func TestGetNetWorthMultiCurrency(t *testing.T) {
+	accounts := newMemAccounts(
+		models.Account{ID: 1, UserID: 10, Balance: 100, Currency: "USD"},
+		models.Account{ID: 2, UserID: 10, Balance: 50, Currency: "EUR"},
+		models.Account{ID: 3, UserID: 10, Balance: 10, Currency: "EUR"},
+	)
+	s := NewAccountService(nil, testSecret, AccountConfig{Accounts: accounts})
+
+	netWorth, err := s.GetNetWorth(10, false)
+	if err != nil {
+		t.Fatalf("net worth: %v", err)
+	}
+	if netWorth.Totals["USD"] != 100 || netWorth.Totals["EUR"] != 60 || netWorth.ConvertedTotal != nil {
+		t.Errorf("net worth = %+v, want 100 USD and 60 EUR kept apart", netWorth)
+	}
+
+	s = NewAccountService(nil, testSecret, AccountConfig{
+		Accounts:     accounts,
+		BaseCurrency: "USD",
+		Rates:        NewStaticRateProvider("USD", map[string]float64{"EUR": 1.5}),
+	})
+	netWorth, err = s.GetNetWorth(10, false)
+	if err != nil {
+		t.Fatalf("converted net worth: %v", err)
+	}
+	if netWorth.BaseCurrency != "USD" || netWorth.ConvertedTotal == nil || *netWorth.ConvertedTotal != 190 {
+		t.Errorf("net worth = %+v, want 190 USD converted", netWorth)
+	}
+}
+
+func TestResolveAccountNumberParsesAnyScheme(t *testing.T) {
+	db := testDB(t)
+	account := seedAccount(t, db, seedUser(t, db, "numbers"), 0, "USD")
+	iban := accountnumber.IBAN{Country: "DE", BankCode: "BNKX"}
+
+	spaced := (*account.Number)[:4] + " " + (*account.Number)[4:]
+	if id, err := resolveAccountNumber(db, spaced, iban); err != nil || id != account.ID {
+		t.Errorf("internal number under the IBAN scheme = %d, %v; want %d", id, err, account.ID)
+	}
+	_, err := resolveAccountNumber(db, iban.Generate(9999), iban)
+	wantAppError(t, err, 404)
+	wantMaskedInLog(t, err, iban.Generate(9999))
+	_, err = resolveAccountNumber(db, "GB00WEST12345698765432", iban)
+	wantAppError(t, err, 400)
+}
+
+func TestInvalidAccountNumberMaskedInLogs(t *testing.T) {
+	// The number is rejected before any query.
+	_, err := resolveAccountNumber(nil, "GB00 WEST 1234 5698 7654 32", accountnumber.IBAN{Country: "DE", BankCode: "BNKX"})
+	wantAppError(t, err, 400)
+	wantMaskedInLog(t, err, "GB00WEST12345698765432")
+}
+
+func TestBalanceProofVerifiesWithTheSecret(t *testing.T) {
+	accounts := newMemAccounts(
+		models.Account{ID: 1, UserID: 10, Balance: 1234.5, Currency: "USD"},
+		models.Account{ID: 2, UserID: 10, Balance: 80, Currency: "EUR", SubBalances: models.SubBalances{"bonus": 30}},
+	)
+	s := NewAccountService(nil, testSecret, AccountConfig{Accounts: accounts})
+
+	for _, id := range []int{1, 2} {
+		proof, err := s.GetBalanceProof(&models.Claims{UserID: 10, Role: models.RoleUser}, id)
+		if err != nil {
+			t.Fatalf("proof of account %d: %v", id, err)
+		}
+		// What an auditor does: sign the message with the secret and compare.
+		if proof.Proof != utils.CreateHMAC(proof.Message, []byte(testSecret)) {
+			t.Errorf("account %d: proof %s does not verify message %q", id, proof.Proof, proof.Message)
+		}
+		if proof.Proof == utils.CreateHMAC(proof.Message, []byte("wrong-secret")) {
+			t.Errorf("account %d: proof verifies with another secret", id)
+		}
+		if proof.AccountID != id || proof.Algorithm != "HMAC-SHA256" {
+			t.Errorf("proof = %+v", proof)
+		}
+	}
+}
+
+func TestBalanceProofOwnership(t *testing.T) {
+	accounts := newMemAccounts(models.Account{ID: 1, UserID: 10, Balance: 50, Currency: "USD"})
+	s := NewAccountService(nil, testSecret, AccountConfig{Accounts: accounts})
+
+	_, err := s.GetBalanceProof(&models.Claims{UserID: 11, Role: models.RoleUser}, 1)
+	wantAppError(t, err, 404)
+	if _, err := s.GetBalanceProof(&models.Claims{UserID: 11, Role: models.RoleAdmin}, 1); err != nil {
+		t.Errorf("admin: %v", err)
+	}
+	_, err = s.GetBalanceProof(&models.Claims{UserID: 11, Role: models.RoleAdmin}, 2)
+	wantAppError(t, err, 404)
+}
@@ -0,0 +1,193 @@
+// Path: internal/services/account_statement.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Account statement formats for accounting software.
+const (
+	StatementFormatOFX = "ofx"
+	StatementFormatQIF = "qif"
+)
+
+// ofxBankID identifies the bank in OFX documents.
+const ofxBankID = "BANKX"
+
+// statementEntry is a transaction as seen from one account: credits are positive, debits negative.
+type statementEntry struct {
+	models.Transaction
+	signed  float64
+	trnType string // OFX transaction type
+	name    string
+}
+
+// AccountStatement checks the account belongs to the user and returns a function writing its
+// completed transactions in [from, to) as an OFX or QIF document, streamed from the database.
+// Errors found before streaming are returned directly; errors while streaming truncate the document.
+func (s *exportService) AccountStatement(userID uint, accountID int, from, to time.Time, format string) (func(w io.Writer) error, error) {
+	var v validation
+	v.check(format == StatementFormatOFX || format == StatementFormatQIF, "format", "Format must be ofx or qif")
+	v.check(from.Before(to), "from", "The start of the period must be before its end")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	var account models.Account
+	if err := s.db.Where("id = ? AND user_id = ?", accountID, userID).First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &AppError{Code: 404, Message: "Account not found or access denied", Details: fmt.Sprintf("account_id: %d, user_id: %d", accountID, userID)}
+		}
+		return nil, &AppError{Code: 500, Message: "Failed to query account", Details: err.Error(), Err: err}
+	}
+
+	now := s.clock.Now()
+	decimals := currencyDecimals(account.Currency, nil)
+	amount := func(a float64) string { return formatAmount(a, decimals) }
+
+	if format == StatementFormatQIF {
+		return func(w io.Writer) error {
+			if _, err := io.WriteString(w, "!Type:Bank\n"); err != nil {
+				return err
+			}
+			return s.streamStatement(&account, from, to, func(e statementEntry) error {
+				_, err := fmt.Fprintf(w, "D%s\nT%s\nP%s\nM%s\nN%s\n^\n",
+					e.CreatedAt.Format("01/02/2006"), amount(e.signed), qifLine(e.name), qifLine(e.Description), e.ID)
+				return err
+			})
+		}, nil
+	}
+
+	accountNumber := strconv.Itoa(account.ID)
+	if account.Number != nil {
+		accountNumber = *account.Number
+	}
+	return func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS><DTSERVER>%s</DTSERVER><LANGUAGE>ENG</LANGUAGE></SONRS></SIGNONMSGSRSV1>
+<BANKMSGSRSV1><STMTTRNRS><TRNUID>0</TRNUID><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>
+<STMTRS><CURDEF>%s</CURDEF>
+<BANKACCTFROM><BANKID>%s</BANKID><ACCTID>%s</ACCTID><ACCTTYPE>%s</ACCTTYPE></BANKACCTFROM>
+<BANKTRANLIST><DTSTART>%s</DTSTART><DTEND>%s</DTEND>
+`, ofxTime(now), ofxText(account.Currency), ofxBankID, ofxText(accountNumber), ofxAccountType(account.Type), ofxTime(from), ofxTime(to))
+		if err != nil {
+			return err
+		}
+
+		err = s.streamStatement(&account, from, to, func(e statementEntry) error {
+			_, err := fmt.Fprintf(w, "<STMTTRN><TRNTYPE>%s</TRNTYPE><DTPOSTED>%s</DTPOSTED><TRNAMT>%s</TRNAMT><FITID>%s</FITID><NAME>%s</NAME><MEMO>%s</MEMO></STMTTRN>\n",
+				e.trnType, ofxTime(e.CreatedAt), amount(e.signed), ofxText(e.ID), ofxText(e.name), ofxText(e.Description))
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(w, "</BANKTRANLIST>\n<LEDGERBAL><BALAMT>%s</BALAMT><DTASOF>%s</DTASOF></LEDGERBAL>\n</STMTRS></STMTTRNRS></BANKMSGSRSV1>\n</OFX>\n",
+			amount(account.Balance), ofxTime(now))
+		return err
+	}, nil
+}
+
+// streamStatement calls write for each completed transaction of the account in [from, to), oldest first.
+func (s *exportService) streamStatement(account *models.Account, from, to time.Time, write func(statementEntry) error) error {
+	rows, err := s.db.Model(&models.Transaction{}).
+		Where("(from_account_id = ? OR to_account_id = ?) AND status = ?", account.ID, account.ID, "completed").
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Order("created_at, id").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var transaction models.Transaction
+		if err := s.db.ScanRows(rows, &transaction); err != nil {
+			return err
+		}
+		if err := write(newStatementEntry(account.ID, transaction)); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// newStatementEntry signs the transaction from the account's side and maps its type.
+func newStatementEntry(accountID int, t models.Transaction) statementEntry {
+	e := statementEntry{Transaction: t}
+	credit := t.ToAccountID != nil && *t.ToAccountID == accountID
+	if credit {
+		e.signed = t.Amount
+	} else {
+		e.signed = -(t.Amount + t.Fee) // The sender pays the fee on top.
+	}
+
+	switch t.Type {
+	case "deposit":
+		e.trnType, e.name = "DEP", "Deposit"
+	case "withdraw":
+		e.trnType, e.name = "CASH", "Withdrawal"
+	case "interest":
+		e.trnType, e.name = "INT", "Interest"
+	case "dormancy_fee":
+		e.trnType, e.name = "FEE", "Dormant account fee"
+	case "transfer", "refund":
+		e.trnType = "XFER"
+		if credit && t.FromAccountID != nil {
+			e.name = fmt.Sprintf("From account %d", *t.FromAccountID)
+		} else if !credit && t.ToAccountID != nil {
+			e.name = fmt.Sprintf("To account %d", *t.ToAccountID)
+		}
+		if t.Type == "refund" {
+			e.name = "Refund " + strings.ToLower(e.name)
+		}
+	default:
+		e.trnType, e.name = "DEBIT", t.Type
+		if credit {
+			e.trnType = "CREDIT"
+		}
+		if t.Type == "adjustment" {
+			e.name = "Balance adjustment"
+		}
+	}
+	return e
+}
+
+func ofxAccountType(accountType string) string {
+	switch accountType {
+	case models.AccountTypeSavings:
+		return "SAVINGS"
+	case models.AccountTypeOverdraft:
+		return "CREDITLINE"
+	}
+	return "CHECKING"
+}
+
+// ofxTime formats a time as an OFX datetime in UTC.
+func ofxTime(t time.Time) string {
+	return t.UTC().Format("20060102150405") + "[0:GMT]"
+}
+
+// ofxText escapes text for an OFX element.
+func ofxText(text string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
+
+// qifLine keeps a value on one QIF line.
+func qifLine(text string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(text)
+}
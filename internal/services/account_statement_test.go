@@ -0,0 +1,130 @@
+// Path: internal/services/account_statement_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"bytes"
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatementEntrySignsAndTypes(t *testing.T) {
+	own, other := 1, 2
+	tests := []struct {
+		tx      models.Transaction
+		signed  float64
+		trnType string
+		name    string
+	}{
+		{models.Transaction{Type: "deposit", ToAccountID: &own, Amount: 100}, 100, "DEP", "Deposit"},
+		{models.Transaction{Type: "withdraw", FromAccountID: &own, Amount: 30}, -30, "CASH", "Withdrawal"},
+		{models.Transaction{Type: "transfer", FromAccountID: &own, ToAccountID: &other, Amount: 20, Fee: 1}, -21, "XFER", "To account 2"},
+		{models.Transaction{Type: "transfer", FromAccountID: &other, ToAccountID: &own, Amount: 5, Fee: 1}, 5, "XFER", "From account 2"},
+		{models.Transaction{Type: "refund", FromAccountID: &other, ToAccountID: &own, Amount: 4}, 4, "XFER", "Refund from account 2"},
+		{models.Transaction{Type: "interest", ToAccountID: &own, Amount: 0.5}, 0.5, "INT", "Interest"},
+		{models.Transaction{Type: "adjustment", FromAccountID: &own, Amount: 3}, -3, "DEBIT", "Balance adjustment"},
+		{models.Transaction{Type: "adjustment", ToAccountID: &own, Amount: 3}, 3, "CREDIT", "Balance adjustment"},
+	}
+	for _, tt := range tests {
+		e := newStatementEntry(own, tt.tx)
+		if e.signed != tt.signed || e.trnType != tt.trnType || e.name != tt.name {
+			t.Errorf("%s: %v %s %q, want %v %s %q", tt.tx.Type, e.signed, e.trnType, e.name, tt.signed, tt.trnType, tt.name)
+		}
+	}
+}
+
+func TestAccountStatementValidation(t *testing.T) {
+	s := NewExportService(nil, utils.RealClock)
+	now := time.Now()
+	_, err := s.AccountStatement(1, 1, now.Add(-time.Hour), now, "csv")
+	wantAppError(t, err, 400)
+	_, err = s.AccountStatement(1, 1, now, now, StatementFormatOFX)
+	wantAppError(t, err, 400)
+}
+
+// ofxDocument is the part of an OFX statement the tests look at.
+type ofxDocument struct {
+	Statement struct {
+		Currency     string `xml:"CURDEF"`
+		Transactions []struct {
+			Type   string  `xml:"TRNTYPE"`
+			Amount float64 `xml:"TRNAMT"`
+			ID     string  `xml:"FITID"`
+			Name   string  `xml:"NAME"`
+			Memo   string  `xml:"MEMO"`
+		} `xml:"BANKTRANLIST>STMTTRN"`
+		Balance float64 `xml:"LEDGERBAL>BALAMT"`
+	} `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS"`
+}
+
+func TestAccountStatementOFXAndQIF(t *testing.T) {
+	db := testDB(t)
+	alice := seedUser(t, db, "alice")
+	bob := seedUser(t, db, "bob")
+	account := seedAccount(t, db, alice, 0, "USD")
+	other := seedAccount(t, db, bob, 50, "USD")
+	transactions := NewTransactionService(db, testSecret, TransactionConfig{})
+	if _, err := transactions.ProcessDeposit(&models.TransactionRequest{AccountID: account.ID, Amount: 100, Description: "Salary <June> & bonus"}, claimsFor(alice)); err != nil {
+		t.Fatalf("deposit: %v", err)
+	}
+	if _, err := transactions.ProcessWithdraw(&models.TransactionRequest{AccountID: account.ID, Amount: 30}, claimsFor(alice)); err != nil {
+		t.Fatalf("withdraw: %v", err)
+	}
+	if err := transactions.ProcessTransfer(&models.TransferRequest{FromID: account.ID, ToID: other.ID, Amount: 20}, claimsFor(alice)); err != nil {
+		t.Fatalf("transfer out: %v", err)
+	}
+	if err := transactions.ProcessTransfer(&models.TransferRequest{FromID: other.ID, ToID: account.ID, Amount: 5}, claimsFor(bob)); err != nil {
+		t.Fatalf("transfer in: %v", err)
+	}
+
+	s := NewExportService(db, utils.RealClock)
+	from, to := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	write, err := s.AccountStatement(uint(alice.ID), account.ID, from, to, StatementFormatOFX)
+	if err != nil {
+		t.Fatalf("AccountStatement: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := write(&buf); err != nil {
+		t.Fatalf("write OFX: %v", err)
+	}
+
+	var doc ofxDocument
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("OFX does not parse: %v\n%s", err, buf.String())
+	}
+	var types []string
+	var amounts []float64
+	for _, tx := range doc.Statement.Transactions {
+		types = append(types, tx.Type)
+		amounts = append(amounts, tx.Amount)
+	}
+	if want := []string{"DEP", "CASH", "XFER", "XFER"}; !reflect.DeepEqual(types, want) {
+		t.Errorf("types = %v, want %v", types, want)
+	}
+	if want := []float64{100, -30, -20, 5}; !reflect.DeepEqual(amounts, want) {
+		t.Errorf("amounts = %v, want %v", amounts, want)
+	}
+	if doc.Statement.Currency != "USD" || doc.Statement.Balance != 55 || doc.Statement.Transactions[0].Memo != "Salary <June> & bonus" {
+		t.Errorf("statement = %+v", doc.Statement)
+	}
+
+	write, err = s.AccountStatement(uint(alice.ID), account.ID, from, to, StatementFormatQIF)
+	if err != nil {
+		t.Fatalf("AccountStatement QIF: %v", err)
+	}
+	buf.Reset()
+	if err := write(&buf); err != nil {
+		t.Fatalf("write QIF: %v", err)
+	}
+	qif := buf.String()
+	if !strings.HasPrefix(qif, "!Type:Bank\n") || strings.Count(qif, "^\n") != 4 || !strings.Contains(qif, "\nT-30.00\n") || !strings.Contains(qif, "\nT5.00\n") {
+		t.Errorf("QIF = %s", qif)
+	}
+
+	_, err = s.AccountStatement(uint(bob.ID), account.ID, from, to, StatementFormatOFX)
+	wantAppError(t, err, 404)
+}
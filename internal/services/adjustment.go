@@ -0,0 +1,99 @@
+// Path: internal/services/adjustment.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// AdjustBalance applies a manual correction by an admin: a positive amount credits the account,
+// a negative one debits it. The change is recorded as an "adjustment" transaction and in the audit
+// trail. A debit below the floor of the account type is refused unless req.Force is set.
+func (s *transactionService) AdjustBalance(accountID int, req *models.AdjustmentRequest, claims *models.Claims) (*models.Transaction, error) {
+	if claims.Role != models.RoleAdmin {
+		return nil, &AppError{Code: 403, Message: "Access denied", Details: "Only administrators can adjust balances"}
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	var v validation
+	v.check(req.Amount != 0 && !math.IsNaN(req.Amount) && !math.IsInf(req.Amount, 0), "amount", "Amount must be a non-zero number")
+	v.check(req.Reason != "", "reason", "Reason is required")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	var transaction models.Transaction
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var account models.Account
+		if err := tx.First(&account, accountID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return &AppError{Code: 404, Message: "Account not found", Details: fmt.Sprintf("account_id: %d", accountID)}
+			}
+			return &AppError{Code: 500, Message: "Failed to query account", Details: err.Error(), Err: err}
+		}
+		if account.BalanceHash != balanceHash(&account, s.secretKey) {
+			return &AppError{Code: 500, Message: "Balance integrity check failed", Details: fmt.Sprintf("account_id: %d", accountID), Err: s.integrityFailed(accountID)}
+		}
+
+		amount := math.Abs(req.Amount)
+		if err := checkAmountPrecision(amount, account.Currency, s.cfg.CurrencyDecimals); err != nil {
+			return err
+		}
+		floor := s.policyFor(&account).MinBalance()
+		if req.Amount < 0 && account.Balance+req.Amount < floor && !req.Force {
+			return &AppError{Code: 400, Message: "Adjustment below account floor", Details: fmt.Sprintf("account_id: %d, balance: %f, floor: %f, adjustment: %f; set force to apply anyway", accountID, account.Balance, floor, req.Amount)}
+		}
+
+		if req.Amount < 0 {
+			s.drawSubBalances(&account, amount)
+		}
+		before := account.Balance
+		account.Balance = s.roundAmount(account.Balance+req.Amount, account.Currency)
+		account.BalanceHash = balanceHash(&account, s.secretKey)
+		if err := tx.Save(&account).Error; err != nil {
+			return &AppError{Code: 500, Message: "Failed to update account balance", Details: err.Error(), Err: err}
+		}
+
+		adminID := int(claims.UserID)
+		transaction = models.Transaction{
+			ID:          utils.GenerateTransactionID(s.clock.Now()),
+			Amount:      amount,
+			Type:        "adjustment",
+			Status:      "completed",
+			Description: req.Reason,
+			InitiatorID: &adminID,
+			CreatedAt:   s.clock.Now(),
+		}
+		if req.Amount > 0 {
+			transaction.ToAccountID = &account.ID
+		} else {
+			transaction.FromAccountID = &account.ID
+		}
+		if err := tx.Create(&transaction).Error; err != nil {
+			return &AppError{Code: 500, Message: "Failed to insert transaction record", Details: err.Error(), Err: err}
+		}
+
+		entry := models.AuditLog{
+			UserID:   &adminID,
+			Action:   "balance_adjusted",
+			Severity: models.SeverityCritical,
+			Details: fmt.Sprintf("account_id: %d, transaction_id: %s, amount: %f, balance: %f -> %f, forced: %t, reason: %s",
+				accountID, transaction.ID, req.Amount, before, account.Balance, req.Force, req.Reason),
+			CreatedAt: s.clock.Now(),
+		}
+		if err := tx.Create(&entry).Error; err != nil {
+			return &AppError{Code: 500, Message: "Failed to write audit log", Details: err.Error(), Err: err}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &transaction, nil
+}
@@ -0,0 +1,80 @@
+// Path: internal/services/adjustment_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAdjustBalanceValidation(t *testing.T) {
+	s := NewTransactionService(nil, testSecret, TransactionConfig{})
+	admin := &models.Claims{UserID: 1, Role: models.RoleAdmin}
+
+	_, err := s.AdjustBalance(1, &models.AdjustmentRequest{Amount: 10, Reason: "refund"}, &models.Claims{UserID: 2, Role: models.RoleUser})
+	wantAppError(t, err, 403)
+
+	_, err = s.AdjustBalance(1, &models.AdjustmentRequest{Amount: 0, Reason: "  "}, admin)
+	if got := fieldNames(t, err); !reflect.DeepEqual(got, []string{"amount", "reason"}) {
+		t.Errorf("fields = %v, want amount and reason", got)
+	}
+	_, err = s.AdjustBalance(1, &models.AdjustmentRequest{Amount: math.NaN(), Reason: "oops"}, admin)
+	wantAppError(t, err, 400)
+}
+
+func TestAdjustBalanceCreditDebitAndFloor(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "customer")
+	admin := seedAdmin(t, db, "support")
+	account := seedAccount(t, db, user, 50, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{})
+
+	credit, err := s.AdjustBalance(account.ID, &models.AdjustmentRequest{Amount: 25, Reason: "Goodwill credit"}, claimsFor(admin))
+	if err != nil {
+		t.Fatalf("credit: %v", err)
+	}
+	if credit.Type != "adjustment" || credit.Amount != 25 || credit.ToAccountID == nil || credit.FromAccountID != nil ||
+		credit.InitiatorID == nil || *credit.InitiatorID != admin.ID {
+		t.Errorf("credit = %+v", credit)
+	}
+
+	debit, err := s.AdjustBalance(account.ID, &models.AdjustmentRequest{Amount: -15, Reason: "Duplicate refund"}, claimsFor(admin))
+	if err != nil {
+		t.Fatalf("debit: %v", err)
+	}
+	if debit.Amount != 15 || debit.FromAccountID == nil || debit.ToAccountID != nil {
+		t.Errorf("debit = %+v", debit)
+	}
+
+	stored := reloadAccount(t, db, account.ID)
+	if stored.Balance != 60 || stored.BalanceHash != balanceHash(stored, testSecret) {
+		t.Errorf("balance %v with hash ok %t, want 60 and a valid hash", stored.Balance, stored.BalanceHash == balanceHash(stored, testSecret))
+	}
+
+	// A checking account can't go below zero unless forced.
+	_, err = s.AdjustBalance(account.ID, &models.AdjustmentRequest{Amount: -100, Reason: "Chargeback"}, claimsFor(admin))
+	if appErr := wantAppError(t, err, 400); appErr.Message != "Adjustment below account floor" {
+		t.Errorf("below floor: %q", appErr.Message)
+	}
+	if got := reloadAccount(t, db, account.ID).Balance; got != 60 {
+		t.Errorf("balance after the refused debit = %v, want 60", got)
+	}
+	if _, err := s.AdjustBalance(account.ID, &models.AdjustmentRequest{Amount: -100, Reason: "Chargeback", Force: true}, claimsFor(admin)); err != nil {
+		t.Fatalf("forced debit: %v", err)
+	}
+	if got := reloadAccount(t, db, account.ID).Balance; got != -40 {
+		t.Errorf("balance after the forced debit = %v, want -40", got)
+	}
+
+	var audits []models.AuditLog
+	db.Where("action = ?", "balance_adjusted").Order("id").Find(&audits)
+	if len(audits) != 3 {
+		t.Fatalf("%d adjustment audit entries, want 3", len(audits))
+	}
+	if audits[0].UserID == nil || *audits[0].UserID != admin.ID || !strings.Contains(audits[0].Details, "Goodwill credit") ||
+		!strings.Contains(audits[2].Details, "forced: true") {
+		t.Errorf("audit entries = %+v", audits)
+	}
+}
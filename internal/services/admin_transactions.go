@@ -0,0 +1,73 @@
+// Path: internal/services/admin_transactions.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// SearchTransactions lists the transactions of all users matching the filter, newest first, with
+// the number of matches and their volume per currency. Routes must restrict it to admins.
+func (s *transactionService) SearchTransactions(filter models.AdminTransactionFilter) (*models.AdminTransactionPage, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultHistoryLimit
+	}
+	if filter.Limit > MaxHistoryLimit {
+		filter.Limit = MaxHistoryLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	if filter.Offset > MaxHistoryOffset {
+		return nil, &AppError{Code: 400, Message: "Offset too large", Details: fmt.Sprintf("Offsets above %d are not supported, narrow the period instead", MaxHistoryOffset)}
+	}
+
+	// Columns are qualified: the volume query joins the accounts, which have a created_at too.
+	filtered := func(query *gorm.DB) *gorm.DB {
+		if !filter.From.IsZero() {
+			query = query.Where("transactions.created_at >= ?", filter.From)
+		}
+		if !filter.To.IsZero() {
+			query = query.Where("transactions.created_at < ?", filter.To)
+		}
+		if filter.Type != "" {
+			query = query.Where("transactions.type = ?", filter.Type)
+		}
+		if filter.Status != "" {
+			query = query.Where("transactions.status = ?", filter.Status)
+		}
+		if filter.Reference != "" {
+			query = query.Where("transactions.reference = ?", strings.ToUpper(strings.TrimSpace(filter.Reference)))
+		}
+		return query
+	}
+
+	page := &models.AdminTransactionPage{Transactions: []models.Transaction{}, Volume: []models.CurrencyVolume{}}
+	err := filtered(s.db.Model(&models.Transaction{})).
+		Order("transactions.created_at DESC, transactions.id DESC").
+		Limit(filter.Limit).Offset(filter.Offset).
+		Find(&page.Transactions).Error
+	if err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to query transactions", Details: err.Error(), Err: err}
+	}
+
+	if err := filtered(s.db.Model(&models.Transaction{})).Count(&page.Total).Error; err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to count transactions", Details: err.Error(), Err: err}
+	}
+
+	// Amounts are in the currency of the account they leave, or of the account they reach for deposits.
+	err = filtered(s.db.Model(&models.Transaction{})).
+		Joins("JOIN accounts ON accounts.id = COALESCE(transactions.from_account_id, transactions.to_account_id)").
+		Select("accounts.currency, COUNT(*) AS count, COALESCE(SUM(transactions.amount), 0) AS amount, COALESCE(SUM(transactions.fee), 0) AS fees").
+		Group("accounts.currency").
+		Order("accounts.currency").
+		Scan(&page.Volume).Error
+	if err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to sum transaction volume", Details: err.Error(), Err: err}
+	}
+
+	return page, nil
+}
@@ -0,0 +1,86 @@
+// Path: internal/services/admin_transactions_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSearchTransactionsCapsTheOffset(t *testing.T) {
+	s := &transactionService{}
+	_, err := s.SearchTransactions(models.AdminTransactionFilter{Offset: MaxHistoryOffset + 1})
+	wantAppError(t, err, 400)
+}
+
+func TestSearchTransactionsFiltersAndAggregates(t *testing.T) {
+	db := testDB(t)
+	alice, bob := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+	dollars, euros := seedAccount(t, db, alice, 0, "USD"), seedAccount(t, db, alice, 0, "EUR")
+	bobs := seedAccount(t, db, bob, 0, "USD")
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	clock := utils.NewManualClock(start)
+	s := NewTransactionService(db, testSecret, TransactionConfig{Clock: clock, Fees: FeeConfig{Flat: 1, Accounts: seedFeeAccounts(t, db, "USD", "EUR")}})
+
+	// Day one: two deposits in each currency. Day two: a transfer of dollars with its fee.
+	for _, deposit := range []struct {
+		account *models.Account
+		amount  float64
+	}{{dollars, 100}, {dollars, 50}, {euros, 40}, {euros, 20}} {
+		if _, err := s.ProcessDeposit(&models.TransactionRequest{AccountID: deposit.account.ID, Amount: deposit.amount}, claimsFor(alice)); err != nil {
+			t.Fatalf("deposit: %v", err)
+		}
+	}
+	clock.Advance(24 * time.Hour)
+	if err := s.ProcessTransfer(&models.TransferRequest{FromID: dollars.ID, ToID: bobs.ID, Amount: 30}, claimsFor(alice)); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+
+	search := func(filter models.AdminTransactionFilter) *models.AdminTransactionPage {
+		t.Helper()
+		page, err := s.SearchTransactions(filter)
+		if err != nil {
+			t.Fatalf("search %+v: %v", filter, err)
+		}
+		return page
+	}
+
+	all := search(models.AdminTransactionFilter{})
+	if all.Total != 5 || len(all.Transactions) != 5 {
+		t.Fatalf("unfiltered: %d of %d transactions, want 5", len(all.Transactions), all.Total)
+	}
+	if all.Transactions[0].Type != "transfer" {
+		t.Errorf("newest transaction = %s, want the transfer", all.Transactions[0].Type)
+	}
+	want := []models.CurrencyVolume{
+		{Currency: "EUR", Count: 2, Amount: 60},
+		{Currency: "USD", Count: 3, Amount: 180, Fees: 1},
+	}
+	if !reflect.DeepEqual(all.Volume, want) {
+		t.Errorf("volume = %+v, want %+v", all.Volume, want)
+	}
+
+	// The aggregates cover every match, not just the page.
+	page := search(models.AdminTransactionFilter{Type: "deposit", Limit: 1, Offset: 1})
+	if page.Total != 4 || len(page.Transactions) != 1 || page.Transactions[0].Type != "deposit" {
+		t.Errorf("deposits page: %d of %d, want 1 of 4", len(page.Transactions), page.Total)
+	}
+	want = []models.CurrencyVolume{{Currency: "EUR", Count: 2, Amount: 60}, {Currency: "USD", Count: 2, Amount: 150}}
+	if !reflect.DeepEqual(page.Volume, want) {
+		t.Errorf("deposit volume = %+v, want %+v", page.Volume, want)
+	}
+
+	// From is inclusive and To exclusive.
+	if got := search(models.AdminTransactionFilter{From: start.Add(time.Hour)}).Total; got != 1 {
+		t.Errorf("from day two: %d matches, want the transfer", got)
+	}
+	if got := search(models.AdminTransactionFilter{From: start, To: start.Add(24 * time.Hour)}).Total; got != 4 {
+		t.Errorf("day one: %d matches, want the 4 deposits", got)
+	}
+
+	if page := search(models.AdminTransactionFilter{Status: "failed"}); page.Total != 0 || len(page.Volume) != 0 {
+		t.Errorf("failed: %d matches with volume %+v, want none", page.Total, page.Volume)
+	}
+}
@@ -0,0 +1,46 @@
+// Path: internal/services/audit_service_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/metrics"
+	"bank-api/pkg/utils"
+	"reflect"
+	"testing"
+)
+
+func TestAuditBalancesReportsTamperedAccount(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "audited")
+	seedAccount(t, db, user, 100, "USD")
+	tampered := seedAccount(t, db, user, 200, "USD")
+	seedAccount(t, db, user, 300, "USD")
+	if err := db.Exec("UPDATE accounts SET balance = 1000000 WHERE id = ?", tampered.ID).Error; err != nil {
+		t.Fatalf("tamper: %v", err)
+	}
+
+	runs := metrics.BalanceAuditRuns.Value()
+	s := NewAuditService(db, testSecret, utils.RealClock)
+	report, err := s.AuditBalances(nil)
+	if err != nil {
+		t.Fatalf("audit: %v", err)
+	}
+
+	if report.Scanned != 3 || !reflect.DeepEqual(report.FailedAccounts, []int{tampered.ID}) {
+		t.Errorf("report scanned %d, failed %v; want 3 scanned, [%d] failed", report.Scanned, report.FailedAccounts, tampered.ID)
+	}
+	if got := metrics.BalanceAuditRuns.Value(); got != runs+1 {
+		t.Errorf("audit runs = %d, want %d", got, runs+1)
+	}
+	if got := metrics.BalanceIntegrityFailures.Value(); got != 1 {
+		t.Errorf("integrity failures gauge = %d, want 1", got)
+	}
+
+	var entry models.AuditLog
+	if err := db.Where("action = ?", "balance_audit").First(&entry).Error; err != nil {
+		t.Fatalf("load audit entry: %v", err)
+	}
+	if entry.Severity != models.SeverityCritical {
+		t.Errorf("audit severity = %q, want %q", entry.Severity, models.SeverityCritical)
+	}
+}
@@ -3,41 +3,119 @@ package services
 
 import (
 	"bank-api/internal/models"
+	"bank-api/pkg/accountnumber"
+	"bank-api/pkg/utils"
+	"context"
 	"errors"
 	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
 // AuthService handles user authentication and registration.
 type AuthService interface {
 	Register(username, password string) error
-	Login(username, password string) (string, error)
+	Login(username, password string, meta models.LoginMeta) (string, error)
 	ValidateToken(token string) (*models.Claims, error)
+	LoginHistory(userID uint, filter models.LoginHistoryFilter) ([]models.LoginAttempt, error)
+	SetTransfersEnabled(actorID, userID uint, enabled bool) (*models.User, error)
+	DeleteUser(actorID, userID uint) (*models.User, error)
+	PurgeUser(actorID, userID uint) error
+	SearchUsers(filter models.UserSearchFilter) ([]models.UserSummary, error)
+	SetupTwoFactor(userID uint) (*models.TwoFactorSetup, error)
+	Elevate(claims *models.Claims, code string) (string, error)
+	TokenInfo(claims *models.Claims) *models.TokenInfo
+}
+
+// AuthConfig holds the tunable settings of the auth service.
+type AuthConfig struct {
+	// PasswordAlgo is the hashing algorithm used for new registrations ("bcrypt" or "argon2id").
+	// Existing hashes are always verified with the algorithm recorded in their prefix.
+	PasswordAlgo string
+	// PreviousJWTSecret is still accepted by ValidateToken during a secret rotation window.
+	// Tokens are only ever signed with the current secret. Empty disables it.
+	PreviousJWTSecret string
+	// BalanceSecret signs the balance hash of accounts created on registration. Defaults to the JWT secret.
+	BalanceSecret string
+	// Clock supplies the current time for token issuing and expiry. Defaults to the wall clock.
+	Clock utils.Clock
+	// AccountNumbers generates numbers for accounts created on registration. Defaults to the internal format.
+	AccountNumbers accountnumber.Scheme
+	// TokenGracePeriod keeps accepting a token this long after it expired, marked as stale, so
+	// clients can keep reading while they refresh it. Zero disables it.
+	TokenGracePeriod time.Duration
+	// LoginHistoryRetention is how many login attempts are kept per user. Defaults to DefaultLoginHistoryRetention.
+	LoginHistoryRetention int
+	// Usernames holds the rules for usernames of new registrations.
+	Usernames UsernamePolicy
+	// Users stores the users read outside transactions. Defaults to the gorm repository over db.
+	Users UserRepository
+	// UserRetention is how long a deleted user's data is kept before it can be purged. Defaults to
+	// DefaultUserRetention.
+	UserRetention time.Duration
 }
 
 type authService struct {
 	db     *gorm.DB
 	jwtKey string
+	cfg    AuthConfig
 }
 
 // NewAuthService creates a new AuthService.
-func NewAuthService(db *gorm.DB, jwtSecret string) AuthService {
+func NewAuthService(db *gorm.DB, jwtSecret string, cfg AuthConfig) AuthService {
+	if cfg.PasswordAlgo == "" {
+		cfg.PasswordAlgo = PasswordAlgoBcrypt
+	}
+	if cfg.BalanceSecret == "" {
+		cfg.BalanceSecret = jwtSecret
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = utils.RealClock
+	}
+	if cfg.AccountNumbers == nil {
+		cfg.AccountNumbers = accountnumber.Internal{}
+	}
+	if cfg.LoginHistoryRetention <= 0 {
+		cfg.LoginHistoryRetention = DefaultLoginHistoryRetention
+	}
+	cfg.Usernames = cfg.Usernames.withDefaults()
+	if cfg.UserRetention <= 0 {
+		cfg.UserRetention = DefaultUserRetention
+	}
+	if cfg.Users == nil {
+		cfg.Users = NewUserRepository(db)
+	}
 	return &authService{
 		db:     db,
 		jwtKey: jwtSecret,
+		cfg:    cfg,
 	}
 }
 
+// WithContext returns a copy of the service whose queries run under ctx, so a request's deadline
+// and cancellation reach the database.
+func (s *authService) WithContext(ctx context.Context) AuthService {
+	bound := *s
+	bound.db = dbWithContext(s.db, ctx)
+	bound.cfg.Users = usersWithContext(s.cfg.Users, ctx)
+	return &bound
+}
+
 // Register registers a new user.
 func (s *authService) Register(username, password string) error {
+	var v validation
+	v.username("username", &username, s.cfg.Usernames)
+	v.check(password != "", "password", "Password is required")
+	if err := v.err(); err != nil {
+		return err
+	}
+
 	err := s.db.Transaction(func(tx *gorm.DB) error {
-		// Check if user already exists.
+		// Check if user already exists. Users registered before usernames were normalized may have capitals.
 		var count int64
-		err := tx.Model(&models.User{}).Where("username = ?", username).Count(&count).Error
+		err := tx.Model(&models.User{}).Where("LOWER(username) = ?", username).Count(&count).Error
 		if err != nil {
 			return &AppError{Code: 500, Message: "Failed to check user existence", Details: err.Error(), Err: err}
 		}
@@ -46,7 +124,7 @@ func (s *authService) Register(username, password string) error {
 		}
 
 		// Hash the password.
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		hashedPassword, err := hashPassword(s.cfg.PasswordAlgo, password)
 		if err != nil {
 			return &AppError{Code: 500, Message: "Failed to hash password", Details: err.Error(), Err: err}
 		}
@@ -54,22 +132,29 @@ func (s *authService) Register(username, password string) error {
 		// Insert the new user.
 		user := models.User{
 			Username: username,
-			Password: string(hashedPassword),
+			Password: hashedPassword,
+			Role:     models.RoleUser,
+			Tier:     models.TierStandard,
+			// Set explicitly: gorm would otherwise insert the zero value.
+			TransfersEnabled: true,
 		}
-		user.CreatedAt = time.Now().Format(time.RFC3339) // Set the CreatedAt field to the current time as a string
+		user.CreatedAt = s.cfg.Clock.Now().Format(time.RFC3339) // Set the CreatedAt field to the current time as a string
 		if err := tx.Create(&user).Error; err != nil {
+			// The check above races with concurrent registrations; the unique index settles it.
+			if isUniqueViolation(err) {
+				return &AppError{Code: 400, Message: "User already exists", Details: fmt.Sprintf("username: %s", username), Err: err}
+			}
 			return &AppError{Code: 500, Message: "Failed to insert user", Details: err.Error(), Err: err}
 		}
 
 		// Create a default account for the user.
-		initialHash := CalculateBalanceHash(0, uint(user.ID), s.jwtKey) // Use consistent hashing
 		account := models.Account{
-			UserID:      user.ID,
-			Balance:     0,
-			BalanceHash: initialHash,
+			UserID:   user.ID,
+			Balance:  0,
+			Currency: DefaultCurrency,
 		}
-		if err := tx.Create(&account).Error; err != nil {
-			return &AppError{Code: 500, Message: "Failed to create initial account", Details: err.Error(), Err: err}
+		if err := createAccount(tx, &account, s.cfg.BalanceSecret, s.cfg.Clock.Now(), s.cfg.AccountNumbers); err != nil {
+			return err
 		}
 
 		return nil
@@ -83,47 +168,110 @@ func (s *authService) Register(username, password string) error {
 }
 
 // Login authenticates a user and returns a JWT.
-func (s *authService) Login(username, password string) (string, error) {
-	var user models.User
-	err := s.db.Where("username = ?", username).First(&user).Error
+// Attempts against an existing user are recorded in the user's login history.
+func (s *authService) Login(username, password string, meta models.LoginMeta) (string, error) {
+	if err := validateCredentials(username, password); err != nil {
+		return "", err
+	}
+
+	user, err := s.cfg.Users.FindByUsername(username)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+		if errors.Is(err, ErrNotFound) {
 			return "", &AppError{Code: 401, Message: "Invalid credentials", Details: "User not found"}
 		}
 		return "", &AppError{Code: 500, Message: "Failed to query user", Details: err.Error(), Err: err}
 	}
 
 	// Check password.
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		return "", &AppError{Code: 401, Message: "Invalid credentials", Details: "Incorrect password"}
+	if err := verifyPassword(user.Password, password); err != nil {
+		s.recordLoginAttempt(user.ID, meta, "failed")
+		if errors.Is(err, errPasswordMismatch) {
+			return "", &AppError{Code: 401, Message: "Invalid credentials", Details: "Incorrect password"}
+		}
+		return "", &AppError{Code: 401, Message: "Invalid credentials", Details: "Unsupported password hash", Err: err}
 	}
 
 	// Create JWT claims.
+	now := s.cfg.Clock.Now()
 	claims := &models.Claims{
-		UserID: uint(user.ID),
+		UserID:  uint(user.ID),
+		Role:    user.Role,
+		AMR:     []string{"pwd"},
+		Sandbox: meta.Sandbox,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(now.Add(24 * time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(now),
 			Issuer:    "bank-api",
 		},
 	}
 
-	// Create and sign the token.
+	tokenString, err := s.signToken(claims)
+	if err != nil {
+		return "", err
+	}
+	s.recordLoginAttempt(user.ID, meta, "success")
+
+	return tokenString, nil
+}
+
+// signToken signs claims with the current secret.
+func (s *authService) signToken(claims *models.Claims) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	tokenString, err := token.SignedString([]byte(s.jwtKey))
 	if err != nil {
 		return "", &AppError{Code: 500, Message: "Failed to sign token", Details: err.Error(), Err: err}
 	}
-
 	return tokenString, nil
 }
 
+// SetTransfersEnabled enables or disables a user's transfers and withdrawals and records it in the audit trail.
+// The user can still log in, view balances and receive deposits.
+func (s *authService) SetTransfersEnabled(actorID, userID uint, enabled bool) (*models.User, error) {
+	var user models.User
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("deleted_at IS NULL").First(&user, userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return &AppError{Code: 404, Message: "User not found", Details: fmt.Sprintf("user_id: %d", userID)}
+			}
+			return &AppError{Code: 500, Message: "Failed to query user", Details: err.Error(), Err: err}
+		}
+
+		if err := tx.Model(&user).Update("transfers_enabled", enabled).Error; err != nil {
+			return &AppError{Code: 500, Message: "Failed to update user", Details: err.Error(), Err: err}
+		}
+		user.TransfersEnabled = enabled
+
+		actor := int(actorID)
+		entry := models.AuditLog{
+			UserID:    &actor,
+			Action:    "user_transfers_toggled",
+			Severity:  models.SeverityWarning,
+			Details:   fmt.Sprintf("user_id: %d, transfers_enabled: %t", userID, enabled),
+			CreatedAt: s.cfg.Clock.Now(),
+		}
+		if err := tx.Create(&entry).Error; err != nil {
+			return &AppError{Code: 500, Message: "Failed to write audit log", Details: err.Error(), Err: err}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
 // ValidateToken validates a JWT and returns the claims.
 func (s *authService) ValidateToken(tokenString string) (*models.Claims, error) {
 	claims := &models.Claims{}
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(s.jwtKey), nil
-	})
+	token, err := s.parseToken(tokenString, claims, s.jwtKey)
+
+	// During a rotation window, fall back to the previous secret if the signature doesn't match the current one.
+	var ve *jwt.ValidationError
+	if err != nil && s.cfg.PreviousJWTSecret != "" && errors.As(err, &ve) && ve.Errors&jwt.ValidationErrorSignatureInvalid != 0 {
+		claims = &models.Claims{}
+		token, err = s.parseToken(tokenString, claims, s.cfg.PreviousJWTSecret)
+	}
 
 	if err != nil {
 		// Distinguish between different parsing errors for better diagnostics
@@ -132,6 +280,10 @@ func (s *authService) ValidateToken(tokenString string) (*models.Claims, error)
 				return nil, &AppError{Code: 401, Message: "Invalid token", Details: "Malformed token"}
 			} else if ve.Errors&(jwt.ValidationErrorExpired|jwt.ValidationErrorNotValidYet) != 0 {
 				return nil, &AppError{Code: 401, Message: "Invalid token", Details: "Token expired or not yet valid"}
+			} else if ve.Errors&jwt.ValidationErrorSignatureInvalid != 0 {
+				// Most likely JWT_SECRET was changed without keeping the old one in JWT_SECRET_PREVIOUS,
+				// which signs every user out: tell them so instead of a bare "invalid token".
+				return nil, &AppError{Code: 401, Message: "Token signed with a different key", Details: "The token was signed with a key this server no longer accepts, please log in again"}
 			}
 		}
 		return nil, &AppError{Code: 401, Message: "Invalid token", Details: err.Error(), Err: err}
@@ -143,3 +295,47 @@ func (s *authService) ValidateToken(tokenString string) (*models.Claims, error)
 
 	return claims, nil
 }
+
+// TokenInfo reports the lifetime of a validated token against the service clock.
+func (s *authService) TokenInfo(claims *models.Claims) *models.TokenInfo {
+	now := s.cfg.Clock.Now()
+	info := &models.TokenInfo{Stale: claims.Stale, ServerTime: now}
+	if claims.IssuedAt != nil {
+		info.IssuedAt = claims.IssuedAt.Unix()
+	}
+	if claims.ExpiresAt != nil {
+		info.ExpiresAt = claims.ExpiresAt.Unix()
+		if left := claims.ExpiresAt.Sub(now); left > 0 {
+			info.ExpiresIn = int64(left / time.Second)
+		}
+	}
+	return info
+}
+
+// parseToken parses and verifies an HS256 token with the given secret.
+// Time-based claims are checked against the service clock rather than jwt's global time.
+func (s *authService) parseToken(tokenString string, claims *models.Claims, secret string) (*jwt.Token, error) {
+	parser := jwt.Parser{SkipClaimsValidation: true}
+	token, err := parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	})
+	if err != nil {
+		return token, err
+	}
+
+	now := s.cfg.Clock.Now()
+	if !claims.VerifyExpiresAt(now, true) {
+		if s.cfg.TokenGracePeriod <= 0 || !claims.VerifyExpiresAt(now.Add(-s.cfg.TokenGracePeriod), true) {
+			return token, jwt.NewValidationError("token is expired", jwt.ValidationErrorExpired)
+		}
+		claims.Stale = true
+	}
+	if !claims.VerifyNotBefore(now, false) {
+		return token, jwt.NewValidationError("token is not valid yet", jwt.ValidationErrorNotValidYet)
+	}
+	if !claims.VerifyIssuedAt(now, false) {
+		return token, jwt.NewValidationError("token used before issued", jwt.ValidationErrorIssuedAt)
+	}
+
+	return token, nil
+}
@@ -0,0 +1,163 @@
+// Path: internal/services/auth_service_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	testJWTSecret         = "current-jwt-secret-0123456789abcdef"
+	testPreviousJWTSecret = "previous-jwt-secret-0123456789abcdef"
+)
+
+// signTestToken signs a token of user 1 issued at issuedAt and valid for a day.
+func signTestToken(t *testing.T, secret string, issuedAt time.Time) string {
+	t.Helper()
+	claims := &models.Claims{
+		UserID: 1,
+		Role:   models.RoleUser,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(issuedAt.Add(24 * time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			Issuer:    "bank-api",
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return token
+}
+
+func TestValidateTokenDuringKeyRotation(t *testing.T) {
+	now := time.Now()
+	old := signTestToken(t, testPreviousJWTSecret, now)
+	current := signTestToken(t, testJWTSecret, now)
+
+	rotating := NewAuthService(nil, testJWTSecret, AuthConfig{PreviousJWTSecret: testPreviousJWTSecret})
+	for name, token := range map[string]string{"previous key": old, "current key": current} {
+		if claims, err := rotating.ValidateToken(token); err != nil || claims.UserID != 1 {
+			t.Errorf("%s during the rotation: %v", name, err)
+		}
+	}
+
+	rotated := NewAuthService(nil, testJWTSecret, AuthConfig{})
+	if _, err := rotated.ValidateToken(current); err != nil {
+		t.Errorf("current key after the rotation: %v", err)
+	}
+	_, err := rotated.ValidateToken(old)
+	if appErr := wantAppError(t, err, 401); appErr.Message != "Token signed with a different key" {
+		t.Errorf("previous key after the rotation: %q", appErr.Message)
+	}
+}
+
+func TestValidateTokenNeverAcceptsUnknownKeys(t *testing.T) {
+	s := NewAuthService(nil, testJWTSecret, AuthConfig{PreviousJWTSecret: testPreviousJWTSecret})
+	_, err := s.ValidateToken(signTestToken(t, "some-other-secret-0123456789abcdef", time.Now()))
+	wantAppError(t, err, 401)
+}
+
+func TestValidateTokenExpiresWithTheClock(t *testing.T) {
+	issued := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	token := signTestToken(t, testJWTSecret, issued)
+	clock := utils.NewManualClock(issued.Add(-time.Minute))
+	s := NewAuthService(nil, testJWTSecret, AuthConfig{Clock: clock})
+
+	if _, err := s.ValidateToken(token); err == nil {
+		t.Error("token accepted before it was issued")
+	}
+
+	clock.Set(issued.Add(23 * time.Hour))
+	if _, err := s.ValidateToken(token); err != nil {
+		t.Errorf("token an hour before expiry: %v", err)
+	}
+
+	clock.Advance(2 * time.Hour)
+	_, err := s.ValidateToken(token)
+	if appErr := wantAppError(t, err, 401); appErr.Details != "Token expired or not yet valid" {
+		t.Errorf("expired token: %q", appErr.Details)
+	}
+}
+
+func TestValidateTokenGracePeriodMarksStale(t *testing.T) {
+	issued := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	token := signTestToken(t, testJWTSecret, issued)
+	clock := utils.NewManualClock(issued.Add(23 * time.Hour))
+	s := NewAuthService(nil, testJWTSecret, AuthConfig{Clock: clock, TokenGracePeriod: 5 * time.Minute})
+
+	if claims, err := s.ValidateToken(token); err != nil || claims.Stale {
+		t.Errorf("fresh token = %+v, %v; want valid and not stale", claims, err)
+	}
+
+	clock.Set(issued.Add(24*time.Hour + time.Minute))
+	if claims, err := s.ValidateToken(token); err != nil || !claims.Stale {
+		t.Errorf("just expired token = %+v, %v; want valid and stale", claims, err)
+	}
+
+	clock.Advance(5 * time.Minute)
+	_, err := s.ValidateToken(token)
+	wantAppError(t, err, 401)
+
+	// Without a grace period an expired token is rejected outright.
+	clock.Set(issued.Add(24*time.Hour + time.Minute))
+	_, err = NewAuthService(nil, testJWTSecret, AuthConfig{Clock: clock}).ValidateToken(token)
+	wantAppError(t, err, 401)
+}
+
+func TestValidateTokenTellsFailuresApart(t *testing.T) {
+	now := time.Now()
+	s := NewAuthService(nil, testJWTSecret, AuthConfig{})
+	for _, tt := range []struct {
+		name, token, message, details string
+	}{
+		{"different key", signTestToken(t, "some-other-secret-0123456789abcdef", now), "Token signed with a different key", ""},
+		{"malformed", "not.a.token", "Invalid token", "Malformed token"},
+		{"expired", signTestToken(t, testJWTSecret, now.Add(-48*time.Hour)), "Invalid token", "Token expired or not yet valid"},
+	} {
+		_, err := s.ValidateToken(tt.token)
+		appErr := wantAppError(t, err, 401)
+		if appErr.Message != tt.message || (tt.details != "" && appErr.Details != tt.details) {
+			t.Errorf("%s: %q (%q), want %q", tt.name, appErr.Message, appErr.Details, tt.message)
+		}
+	}
+}
+
+func TestTokenInfoCountsDown(t *testing.T) {
+	issued := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	clock := utils.NewManualClock(issued.Add(time.Hour))
+	s := NewAuthService(nil, testJWTSecret, AuthConfig{Clock: clock, TokenGracePeriod: 5 * time.Minute})
+	claims, err := s.ValidateToken(signTestToken(t, testJWTSecret, issued))
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	info := s.TokenInfo(claims)
+	want := models.TokenInfo{
+		IssuedAt:   issued.Unix(),
+		ExpiresAt:  issued.Add(24 * time.Hour).Unix(),
+		ExpiresIn:  int64((23 * time.Hour) / time.Second),
+		ServerTime: issued.Add(time.Hour),
+	}
+	if *info != want {
+		t.Errorf("token info = %+v, want %+v", *info, want)
+	}
+
+	clock.Advance(10 * time.Minute)
+	if later := s.TokenInfo(claims); later.ExpiresIn != info.ExpiresIn-600 || !later.ServerTime.Equal(clock.Now()) {
+		t.Errorf("ten minutes later: %+v, want %d seconds left", later, info.ExpiresIn-600)
+	}
+
+	clock.Set(issued.Add(24*time.Hour + time.Minute))
+	claims, err = s.ValidateToken(signTestToken(t, testJWTSecret, issued))
+	if err != nil {
+		t.Fatalf("validate within the grace period: %v", err)
+	}
+	if expired := s.TokenInfo(claims); expired.ExpiresIn != 0 || !expired.Stale {
+		t.Errorf("after expiry: %+v, want 0 seconds left and stale", expired)
+	}
+}
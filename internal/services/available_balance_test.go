@@ -0,0 +1,74 @@
+// Path: internal/services/available_balance_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"testing"
+)
+
+func TestWithdrawalCountsPendingTransfers(t *testing.T) {
+	db := testDB(t)
+	alice, bob := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+	from, to := seedAccount(t, db, alice, 100, "USD"), seedAccount(t, db, bob, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{})
+
+	pending := &models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: 80, RequireAcceptance: true}
+	if err := s.ProcessTransfer(pending, claimsFor(alice)); err != nil || pending.Status != "incoming_pending" {
+		t.Fatalf("pending transfer = %q, %v; want incoming_pending", pending.Status, err)
+	}
+
+	// The raw balance of 100 covers 50, but only 20 is available.
+	_, err := s.ProcessWithdraw(&models.TransactionRequest{AccountID: from.ID, Amount: 50}, claimsFor(alice))
+	if appErr := wantAppError(t, err, 400); appErr.Message != "Insufficient funds" {
+		t.Errorf("message = %q, want Insufficient funds", appErr.Message)
+	}
+	if got := reloadAccount(t, db, from.ID).Balance; got != 100 {
+		t.Errorf("balance after the rejected withdrawal = %v, want 100", got)
+	}
+
+	balance, err := s.ProcessWithdraw(&models.TransactionRequest{AccountID: from.ID, Amount: 20}, claimsFor(alice))
+	if err != nil {
+		t.Fatalf("withdraw the available balance: %v", err)
+	}
+	if balance.Balance != 80 || balance.AvailableBalance != 0 {
+		t.Errorf("balance = %+v, want 80 with nothing available", balance)
+	}
+}
+
+func TestReturnedBalanceMatchesALaterFetch(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "returned")
+	account := seedAccount(t, db, user, 100, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{})
+	accounts := NewAccountService(db, testSecret, AccountConfig{})
+
+	fetched := func() float64 {
+		t.Helper()
+		got, err := accounts.GetAccount(claimsFor(user), account.ID)
+		if err != nil {
+			t.Fatalf("GetAccount: %v", err)
+		}
+		return got.Balance
+	}
+
+	deposited, err := s.ProcessDeposit(&models.TransactionRequest{AccountID: account.ID, Amount: 40.25}, claimsFor(user))
+	if err != nil {
+		t.Fatalf("deposit: %v", err)
+	}
+	if deposited.AccountID != account.ID || deposited.Balance != 140.25 || deposited.Balance != fetched() ||
+		deposited.AvailableBalance != 140.25 || deposited.Display != "$140.25" {
+		t.Errorf("deposit returned %+v, fetched %v", deposited, fetched())
+	}
+
+	// A hold leaves the balance alone but lowers what is available.
+	if _, err := s.PlaceHold(account.ID, &models.HoldRequest{Amount: 30}, claimsFor(user)); err != nil {
+		t.Fatalf("hold: %v", err)
+	}
+	withdrawn, err := s.ProcessWithdraw(&models.TransactionRequest{AccountID: account.ID, Amount: 10}, claimsFor(user))
+	if err != nil {
+		t.Fatalf("withdraw: %v", err)
+	}
+	if withdrawn.Balance != 130.25 || withdrawn.Balance != fetched() || withdrawn.AvailableBalance != 100.25 {
+		t.Errorf("withdrawal returned %+v, fetched %v; want 130.25 with 100.25 available", withdrawn, fetched())
+	}
+}
@@ -0,0 +1,41 @@
+// Path: internal/services/balance_history.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BalanceAt computes the balance of one of the user's accounts as of a past moment by replaying its
+// completed transactions up to and including at. Transactions count from the moment the money
+// moved: a transfer approved or accepted later counts from then, not from when it was requested.
+// Before the account existed the balance is zero.
+func (s *accountService) BalanceAt(userID uint, accountID int, at time.Time) (*models.HistoricalBalance, error) {
+	var account models.Account
+	if err := s.db.Where("id = ? AND user_id = ?", accountID, userID).First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &AppError{Code: 404, Message: "Account not found or access denied", Details: fmt.Sprintf("account_id: %d, user_id: %d", accountID, userID)}
+		}
+		return nil, &AppError{Code: 500, Message: "Failed to query account", Details: err.Error(), Err: err}
+	}
+
+	balance := &models.HistoricalBalance{AccountID: account.ID, Currency: account.Currency, At: at}
+	if at.Before(account.CreatedAt) {
+		return balance, nil
+	}
+
+	// The sender pays the fee on top of the amount.
+	err := s.db.Model(&models.Transaction{}).
+		Select("COALESCE(SUM(CASE WHEN to_account_id = ? THEN amount ELSE 0 END), 0) - COALESCE(SUM(CASE WHEN from_account_id = ? THEN amount + fee ELSE 0 END), 0)", account.ID, account.ID).
+		Where("(from_account_id = ? OR to_account_id = ?) AND status = ? AND COALESCE(completed_at, created_at) <= ?", account.ID, account.ID, "completed", at).
+		Scan(&balance.Balance).Error
+	if err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to replay transactions", Details: err.Error(), Err: err}
+	}
+
+	return balance, nil
+}
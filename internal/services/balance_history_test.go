@@ -0,0 +1,114 @@
+// Path: internal/services/balance_history_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"testing"
+	"time"
+)
+
+func TestBalanceAtReplaysHistory(t *testing.T) {
+	db := testDB(t)
+	alice, bob := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+	account := seedAccount(t, db, alice, 0, "USD") // Created 24 hours ago
+	other := seedAccount(t, db, bob, 0, "USD")
+	now := time.Now().Truncate(time.Second)
+	clock := utils.NewManualClock(now.Add(-20 * time.Hour))
+	s := NewTransactionService(db, testSecret, TransactionConfig{Clock: clock})
+
+	if _, err := s.ProcessDeposit(&models.TransactionRequest{AccountID: account.ID, Amount: 100}, claimsFor(alice)); err != nil {
+		t.Fatalf("deposit: %v", err)
+	}
+	clock.Set(now.Add(-10 * time.Hour))
+	if _, err := s.ProcessWithdraw(&models.TransactionRequest{AccountID: account.ID, Amount: 30}, claimsFor(alice)); err != nil {
+		t.Fatalf("withdraw: %v", err)
+	}
+	clock.Set(now.Add(-5 * time.Hour))
+	if err := s.ProcessTransfer(&models.TransferRequest{FromID: account.ID, ToID: other.ID, Amount: 20}, claimsFor(alice)); err != nil {
+		t.Fatalf("transfer out: %v", err)
+	}
+	clock.Set(now.Add(-2 * time.Hour))
+	if err := s.ProcessTransfer(&models.TransferRequest{FromID: other.ID, ToID: account.ID, Amount: 5}, claimsFor(bob)); err != nil {
+		t.Fatalf("transfer in: %v", err)
+	}
+
+	accounts := NewAccountService(db, testSecret, AccountConfig{})
+	for _, tt := range []struct {
+		ago  time.Duration
+		want float64
+	}{
+		{48 * time.Hour, 0}, // Before the account existed
+		{22 * time.Hour, 0},
+		{20 * time.Hour, 100}, // The deposit itself counts
+		{15 * time.Hour, 100},
+		{10*time.Hour - time.Second, 70},
+		{3 * time.Hour, 50},
+		{0, 55},
+	} {
+		got, err := accounts.BalanceAt(uint(alice.ID), account.ID, now.Add(-tt.ago))
+		if err != nil {
+			t.Fatalf("BalanceAt %s ago: %v", tt.ago, err)
+		}
+		if got.Balance != tt.want {
+			t.Errorf("balance %s ago = %v, want %v", tt.ago, got.Balance, tt.want)
+		}
+	}
+	if current := reloadAccount(t, db, account.ID).Balance; current != 55 {
+		t.Errorf("current balance = %v, want the replayed 55", current)
+	}
+
+	_, err := accounts.BalanceAt(uint(bob.ID), account.ID, now)
+	wantAppError(t, err, 404)
+}
+
+func TestBalanceAtCountsTransfersWhenTheyComplete(t *testing.T) {
+	db := testDB(t)
+	alice, bob := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+	admin := seedAdmin(t, db, "admin")
+	account := seedAccount(t, db, alice, 500, "USD") // Created 24 hours ago
+	other := seedAccount(t, db, bob, 0, "USD")
+	now := time.Now().Truncate(time.Second)
+	clock := utils.NewManualClock(now.Add(-10 * time.Hour))
+	s := NewTransactionService(db, testSecret, TransactionConfig{Clock: clock, ApprovalThreshold: 100})
+
+	// Requested 10 hours ago, approved 6 hours ago.
+	large := &models.TransferRequest{FromID: account.ID, ToID: other.ID, Amount: 200}
+	if err := s.ProcessTransfer(large, claimsFor(alice)); err != nil {
+		t.Fatalf("large transfer: %v", err)
+	}
+	clock.Set(now.Add(-6 * time.Hour))
+	if err := s.ApproveTransfer(large.TransactionID, claimsFor(admin)); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+
+	// Requested 4 hours ago, accepted by bob 2 hours ago.
+	clock.Set(now.Add(-4 * time.Hour))
+	incoming := &models.TransferRequest{FromID: account.ID, ToID: other.ID, Amount: 50, RequireAcceptance: true}
+	if err := s.ProcessTransfer(incoming, claimsFor(alice)); err != nil {
+		t.Fatalf("transfer requiring acceptance: %v", err)
+	}
+	clock.Set(now.Add(-2 * time.Hour))
+	if err := s.AcceptTransfer(incoming.TransactionID, claimsFor(bob)); err != nil {
+		t.Fatalf("accept: %v", err)
+	}
+
+	accounts := NewAccountService(db, testSecret, AccountConfig{})
+	for _, tt := range []struct {
+		ago  time.Duration
+		want float64
+	}{
+		{8 * time.Hour, 0},   // Requested, not yet approved
+		{5 * time.Hour, 200}, // Approved
+		{3 * time.Hour, 200}, // Requested, not yet accepted
+		{time.Hour, 250},     // Accepted
+	} {
+		got, err := accounts.BalanceAt(uint(bob.ID), other.ID, now.Add(-tt.ago))
+		if err != nil {
+			t.Fatalf("BalanceAt %s ago: %v", tt.ago, err)
+		}
+		if got.Balance != tt.want {
+			t.Errorf("recipient balance %s ago = %v, want %v", tt.ago, got.Balance, tt.want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// Path: internal/services/balance_lookup.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/accountnumber"
+	"fmt"
+	"strconv"
+)
+
+// MaxBalanceLookup caps the accounts, IDs and numbers together, of one bulk balance lookup.
+const MaxBalanceLookup = 500
+
+// LookupBalances returns the balances of many accounts of any users, found by ID or number in a
+// single query. Each balance hash is verified; a failure is reported like anywhere else and the
+// balance left out. Accounts matching nothing are listed as not found rather than failing the lookup.
+func (s *accountService) LookupBalances(req *models.BalanceLookupRequest) (*models.BalanceLookup, error) {
+	var v validation
+	total := len(req.AccountIDs) + len(req.AccountNumbers)
+	v.check(total > 0, "account_ids", "Give at least one account ID or number")
+	v.check(total <= MaxBalanceLookup, "account_ids", fmt.Sprintf("At most %d accounts can be looked up at once", MaxBalanceLookup))
+	positive := true
+	for _, id := range req.AccountIDs {
+		positive = positive && id > 0
+	}
+	v.check(positive, "account_ids", "Account IDs must be positive")
+	normalized := make([]string, len(req.AccountNumbers))
+	for i, number := range req.AccountNumbers {
+		n, err := accountnumber.ValidateAny(number, accountnumber.All(s.cfg.AccountNumbers)...)
+		v.check(err == nil, "account_numbers", fmt.Sprintf("Invalid account number: %s", accountnumber.Normalize(number)))
+		normalized[i] = n
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	var accounts []models.Account
+	err := s.db.Where("id IN ?", req.AccountIDs).Or("number IN ?", normalized).Order("id").Find(&accounts).Error
+	if err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to query accounts", Details: err.Error(), Err: err}
+	}
+
+	lookup := &models.BalanceLookup{Balances: make([]models.AccountBalanceEntry, 0, len(accounts)), NotFound: []string{}}
+	foundIDs, foundNumbers := map[int]bool{}, map[string]bool{}
+	for i := range accounts {
+		acc := &accounts[i]
+		foundIDs[acc.ID] = true
+		if acc.Number != nil {
+			foundNumbers[*acc.Number] = true
+		}
+
+		entry := models.AccountBalanceEntry{AccountID: acc.ID, UserID: acc.UserID, Number: acc.Number, Currency: acc.Currency}
+		if acc.BalanceHash == balanceHash(acc, s.secretKey) {
+			entry.Balance, entry.IntegrityOK = &acc.Balance, true
+		} else {
+			reportIntegrityFailure(s.db, s.cfg.IntegrityAlerter, s.cfg.Clock.Now(), acc.ID)
+		}
+		lookup.Balances = append(lookup.Balances, entry)
+	}
+
+	for _, id := range req.AccountIDs {
+		if !foundIDs[id] {
+			lookup.NotFound = append(lookup.NotFound, strconv.Itoa(id))
+		}
+	}
+	for i, number := range req.AccountNumbers {
+		if !foundNumbers[normalized[i]] {
+			lookup.NotFound = append(lookup.NotFound, number)
+		}
+	}
+	return lookup, nil
+}
@@ -0,0 +1,77 @@
+// Path: internal/services/balance_lookup_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/accountnumber"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestLookupBalancesValidation(t *testing.T) {
+	s := NewAccountService(nil, testSecret, AccountConfig{})
+	tooMany := make([]int, MaxBalanceLookup)
+	for i := range tooMany {
+		tooMany[i] = i + 1
+	}
+	for _, tt := range []struct {
+		name string
+		req  models.BalanceLookupRequest
+		want string
+	}{
+		{"nothing", models.BalanceLookupRequest{}, "account_ids"},
+		{"over the cap", models.BalanceLookupRequest{AccountIDs: tooMany, AccountNumbers: []string{accountnumber.Internal{}.Generate(1)}}, "account_ids"},
+		{"non-positive ID", models.BalanceLookupRequest{AccountIDs: []int{1, -2}}, "account_ids"},
+		{"bad number", models.BalanceLookupRequest{AccountNumbers: []string{"12345"}}, "account_numbers"},
+	} {
+		_, err := s.LookupBalances(&tt.req)
+		if names := fieldNames(t, err); len(names) != 1 || names[0] != tt.want {
+			t.Errorf("%s: fields %v, want %s", tt.name, names, tt.want)
+		}
+	}
+}
+
+func TestLookupBalancesInvalidNumberMaskedInLogs(t *testing.T) {
+	s := NewAccountService(nil, testSecret, AccountConfig{})
+	_, err := s.LookupBalances(&models.BalanceLookupRequest{AccountNumbers: []string{"gb00 west 1234 5698 7654 32"}})
+	appErr := wantAppError(t, err, 400)
+	if len(appErr.Fields) != 1 {
+		t.Fatalf("fields = %+v, want one", appErr.Fields)
+	}
+	wantMaskedInLog(t, appErr.Fields[0].Message, "GB00WEST12345698765432")
+}
+
+func TestLookupBalancesMixedList(t *testing.T) {
+	db := testDB(t)
+	alice, bob := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+	byID, byNumber := seedAccount(t, db, alice, 10, "USD"), seedAccount(t, db, bob, 20, "EUR")
+	tampered := seedAccount(t, db, bob, 30, "USD")
+	if err := db.Exec("UPDATE accounts SET balance = 3000 WHERE id = ?", tampered.ID).Error; err != nil {
+		t.Fatalf("tamper: %v", err)
+	}
+	s := NewAccountService(db, testSecret, AccountConfig{})
+
+	missingID := tampered.ID + 100
+	missingNumber := accountnumber.Internal{}.Generate(uint(tampered.ID + 200))
+	lookup, err := s.LookupBalances(&models.BalanceLookupRequest{
+		AccountIDs:     []int{byID.ID, missingID, tampered.ID},
+		AccountNumbers: []string{*byNumber.Number, missingNumber},
+	})
+	if err != nil {
+		t.Fatalf("lookup: %v", err)
+	}
+
+	ten, twenty := 10.0, 20.0
+	want := []models.AccountBalanceEntry{
+		{AccountID: byID.ID, UserID: alice.ID, Number: byID.Number, Balance: &ten, Currency: "USD", IntegrityOK: true},
+		{AccountID: byNumber.ID, UserID: bob.ID, Number: byNumber.Number, Balance: &twenty, Currency: "EUR", IntegrityOK: true},
+		{AccountID: tampered.ID, UserID: bob.ID, Number: tampered.Number, Currency: "USD"},
+	}
+	if !reflect.DeepEqual(lookup.Balances, want) {
+		t.Errorf("balances = %+v, want %+v", lookup.Balances, want)
+	}
+	if want := []string{strconv.Itoa(missingID), missingNumber}; !reflect.DeepEqual(lookup.NotFound, want) {
+		t.Errorf("not found = %v, want %v", lookup.NotFound, want)
+	}
+}
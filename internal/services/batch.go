@@ -0,0 +1,83 @@
+// Path: internal/services/batch.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"errors"
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// MaxBatchItems caps the number of transfers in one batch.
+const MaxBatchItems = 100
+
+// ProcessBatch settles each transfer of the batch independently, like separate ProcessTransfer
+// calls, and records the outcome of every item. Failed items don't roll back the others;
+// the returned report, also available later from GetBatch, tells which ones went through.
+func (s *transactionService) ProcessBatch(req *models.BatchTransferRequest, claims *models.Claims) (*models.BatchTransfer, error) {
+	var v validation
+	v.check(len(req.Items) > 0, "items", "At least one transfer is required")
+	v.check(len(req.Items) <= MaxBatchItems, "items", fmt.Sprintf("At most %d transfers per batch", MaxBatchItems))
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	batch := models.BatchTransfer{UserID: int(claims.UserID), CreatedAt: s.clock.Now()}
+	if err := s.db.Create(&batch).Error; err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to create batch", Details: err.Error(), Err: err}
+	}
+
+	for i := range req.Items {
+		transfer := req.Items[i]
+		transfer.TransactionID = ""
+		item := models.BatchItem{BatchID: batch.ID, Position: i}
+
+		if err := s.ProcessTransfer(&transfer, claims); err != nil {
+			item.Status = "failed"
+			item.Error = failureReason(err)
+		} else {
+			item.Status = transfer.Status
+			item.TransactionID = &transfer.TransactionID
+		}
+		// ToID is resolved from a payee or account number by ProcessTransfer.
+		item.FromID, item.ToID, item.Amount = transfer.FromID, transfer.ToID, transfer.Amount
+
+		if err := s.db.Create(&item).Error; err != nil {
+			// The transfer itself may already be committed, so report rather than fail the batch.
+			log.Printf("Failed to record item %d of batch %d: %v", i, batch.ID, err)
+		}
+	}
+
+	return s.GetBatch(batch.ID, claims.UserID)
+}
+
+// GetBatch returns the settlement report of one of the user's batches.
+func (s *transactionService) GetBatch(batchID int, userID uint) (*models.BatchTransfer, error) {
+	var batch models.BatchTransfer
+	if err := s.db.Where("id = ? AND user_id = ?", batchID, userID).First(&batch).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &AppError{Code: 404, Message: "Batch not found", Details: fmt.Sprintf("batch_id: %d", batchID)}
+		}
+		return nil, &AppError{Code: 500, Message: "Failed to query batch", Details: err.Error(), Err: err}
+	}
+
+	batch.Items = []models.BatchItem{}
+	if err := s.db.Where("batch_id = ?", batch.ID).Order("position").Find(&batch.Items).Error; err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to query batch items", Details: err.Error(), Err: err}
+	}
+
+	for _, item := range batch.Items {
+		batch.Totals.Items++
+		batch.Totals.RequestedAmount += item.Amount
+		if item.Status == "failed" {
+			batch.Totals.Failed++
+			continue
+		}
+		batch.Totals.Completed++
+		batch.Totals.SettledAmount += item.Amount
+	}
+
+	return &batch, nil
+}
@@ -0,0 +1,64 @@
+// Path: internal/services/batch_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"strings"
+	"testing"
+)
+
+func TestProcessBatchRejectsEmptyAndOversizedBatches(t *testing.T) {
+	s := NewTransactionService(nil, testSecret, TransactionConfig{})
+	_, err := s.ProcessBatch(&models.BatchTransferRequest{}, &models.Claims{UserID: 1})
+	wantAppError(t, err, 400)
+	_, err = s.ProcessBatch(&models.BatchTransferRequest{Items: make([]models.TransferRequest, MaxBatchItems+1)}, &models.Claims{UserID: 1})
+	wantAppError(t, err, 400)
+}
+
+func TestBatchReportsMixedOutcomes(t *testing.T) {
+	db := testDB(t)
+	alice := seedUser(t, db, "alice")
+	bob := seedUser(t, db, "bob")
+	from := seedAccount(t, db, alice, 100, "USD")
+	to := seedAccount(t, db, bob, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{})
+
+	batch, err := s.ProcessBatch(&models.BatchTransferRequest{Items: []models.TransferRequest{
+		{FromID: from.ID, ToID: to.ID, Amount: 60},
+		{FromID: from.ID, ToID: to.ID, Amount: 50}, // Only 40 left
+		{FromID: from.ID, ToID: 99999, Amount: 10},
+		{FromID: from.ID, ToID: to.ID, Amount: 40},
+	}}, claimsFor(alice))
+	if err != nil {
+		t.Fatalf("ProcessBatch: %v", err)
+	}
+
+	want := []struct {
+		status, reason string
+	}{{"completed", ""}, {"failed", "Insufficient funds"}, {"failed", "not found"}, {"completed", ""}}
+	if len(batch.Items) != len(want) {
+		t.Fatalf("%d items, want %d", len(batch.Items), len(want))
+	}
+	for i, w := range want {
+		item := batch.Items[i]
+		if item.Position != i || item.Status != w.status || !strings.Contains(item.Error, w.reason) {
+			t.Errorf("item %d = %+v, want %s %q", i, item, w.status, w.reason)
+		}
+		if (item.TransactionID != nil) != (w.status == "completed") {
+			t.Errorf("item %d transaction ID = %v", i, item.TransactionID)
+		}
+	}
+	if want := (models.BatchTotals{Items: 4, Completed: 2, Failed: 2, RequestedAmount: 160, SettledAmount: 100}); batch.Totals != want {
+		t.Errorf("totals = %+v, want %+v", batch.Totals, want)
+	}
+	if got := reloadAccount(t, db, to.ID).Balance; got != 100 {
+		t.Errorf("destination balance = %v, want 100", got)
+	}
+
+	report, err := s.GetBatch(batch.ID, uint(alice.ID))
+	if err != nil || report.Totals != batch.Totals || len(report.Items) != 4 {
+		t.Errorf("GetBatch = %+v, %v; want the same report", report, err)
+	}
+	_, err = s.GetBatch(batch.ID, uint(bob.ID))
+	wantAppError(t, err, 404)
+}
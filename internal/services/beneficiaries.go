@@ -0,0 +1,205 @@
+// Path: internal/services/beneficiaries.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// MaxBeneficiaries caps the beneficiaries named on one account.
+const MaxBeneficiaries = 10
+
+// AddBeneficiary names an account to receive a share of the balance of one of the user's accounts
+// when it is distributed. The shares of an account may not exceed 100 percent in total.
+func (s *accountService) AddBeneficiary(userID uint, accountID int, req *models.BeneficiaryRequest) (*models.Beneficiary, error) {
+	var v validation
+	v.check(req.AccountID > 0, "beneficiary_account_id", "Beneficiary account is required")
+	v.check(req.AccountID != accountID, "beneficiary_account_id", "An account can't be its own beneficiary")
+	v.check(req.SharePercent > 0 && req.SharePercent <= 100, "share_percent", "Share must be above 0 and at most 100 percent")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	var beneficiary models.Beneficiary
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		account, err := s.findOwnedAccount(tx, userID, accountID)
+		if err != nil {
+			return err
+		}
+
+		var target models.Account
+		if err := tx.Where("id = ? AND sandbox = ?", req.AccountID, account.Sandbox).First(&target).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return &AppError{Code: 404, Message: "Beneficiary account not found", Details: fmt.Sprintf("account_id: %d", req.AccountID)}
+			}
+			return &AppError{Code: 500, Message: "Failed to query beneficiary account", Details: err.Error(), Err: err}
+		}
+		if target.Currency != account.Currency {
+			return &AppError{Code: 400, Message: "Currency mismatch", Details: fmt.Sprintf("The beneficiary account must be in %s", account.Currency)}
+		}
+
+		var existing []models.Beneficiary
+		if err := tx.Where("account_id = ?", accountID).Find(&existing).Error; err != nil {
+			return &AppError{Code: 500, Message: "Failed to query beneficiaries", Details: err.Error(), Err: err}
+		}
+		if len(existing) >= MaxBeneficiaries {
+			return &AppError{Code: 409, Message: "Too many beneficiaries", Details: fmt.Sprintf("An account can name at most %d beneficiaries", MaxBeneficiaries)}
+		}
+		total := req.SharePercent
+		for _, b := range existing {
+			if b.BeneficiaryAccountID == req.AccountID {
+				return &AppError{Code: 409, Message: "Beneficiary already named", Details: fmt.Sprintf("account_id: %d", req.AccountID)}
+			}
+			total += b.SharePercent
+		}
+		if total > 100+1e-9 {
+			return &AppError{Code: 400, Message: "Shares exceed 100 percent", Details: fmt.Sprintf("The shares would total %g percent", total)}
+		}
+
+		beneficiary = models.Beneficiary{
+			AccountID:            accountID,
+			BeneficiaryAccountID: req.AccountID,
+			SharePercent:         req.SharePercent,
+			CreatedAt:            s.cfg.Clock.Now(),
+		}
+		if err := tx.Create(&beneficiary).Error; err != nil {
+			return &AppError{Code: 500, Message: "Failed to save beneficiary", Details: err.Error(), Err: err}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &beneficiary, nil
+}
+
+// GetBeneficiaries lists the beneficiaries of one of the user's accounts.
+func (s *accountService) GetBeneficiaries(userID uint, accountID int) ([]models.Beneficiary, error) {
+	if _, err := s.findOwnedAccount(s.db, userID, accountID); err != nil {
+		return nil, err
+	}
+
+	beneficiaries := []models.Beneficiary{}
+	if err := s.db.Where("account_id = ?", accountID).Order("id").Find(&beneficiaries).Error; err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to query beneficiaries", Details: err.Error(), Err: err}
+	}
+	return beneficiaries, nil
+}
+
+// DistributeToBeneficiaries pays out the whole balance of an account to its beneficiaries by their
+// shares, after an admin confirmed the triggering event (e.g. the owner's death). Each payment is a
+// transfer; the account is then frozen and the distribution recorded in the audit trail. The shares
+// must total exactly 100 percent. Rounding leftovers go to the last beneficiary.
+func (s *transactionService) DistributeToBeneficiaries(accountID int, req *models.DistributionRequest, claims *models.Claims) ([]models.Transaction, error) {
+	if claims.Role != models.RoleAdmin {
+		return nil, &AppError{Code: 403, Message: "Access denied", Details: "Only administrators can distribute accounts"}
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	var v validation
+	v.check(req.Reason != "", "reason", "Reason is required")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	transactions := []models.Transaction{}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var account models.Account
+		if err := tx.First(&account, accountID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return &AppError{Code: 404, Message: "Account not found", Details: fmt.Sprintf("account_id: %d", accountID)}
+			}
+			return &AppError{Code: 500, Message: "Failed to query account", Details: err.Error(), Err: err}
+		}
+		if account.BalanceHash != balanceHash(&account, s.secretKey) {
+			return &AppError{Code: 500, Message: "Balance integrity check failed", Details: fmt.Sprintf("account_id: %d", accountID), Err: s.integrityFailed(accountID)}
+		}
+
+		var beneficiaries []models.Beneficiary
+		if err := tx.Where("account_id = ?", accountID).Order("id").Find(&beneficiaries).Error; err != nil {
+			return &AppError{Code: 500, Message: "Failed to query beneficiaries", Details: err.Error(), Err: err}
+		}
+		total := 0.0
+		for _, b := range beneficiaries {
+			total += b.SharePercent
+		}
+		if len(beneficiaries) == 0 || math.Abs(total-100) > 1e-9 {
+			return &AppError{Code: 409, Message: "Beneficiary shares must total 100 percent", Details: fmt.Sprintf("account_id: %d, beneficiaries: %d, total: %g", accountID, len(beneficiaries), total)}
+		}
+
+		// Split in minor units so the payments add up to the balance exactly.
+		decimals := currencyDecimals(account.Currency, s.cfg.CurrencyDecimals)
+		remaining := toMinorUnits(account.Balance, decimals)
+		if remaining <= 0 {
+			return &AppError{Code: 409, Message: "Nothing to distribute", Details: fmt.Sprintf("account_id: %d, balance: %f", accountID, account.Balance)}
+		}
+		balance := remaining
+
+		account.Frozen, account.FrozenReason = true, "Distributed to beneficiaries"
+		adminID := int(claims.UserID)
+		for i, b := range beneficiaries {
+			share := remaining
+			if i < len(beneficiaries)-1 {
+				share = int64(math.Floor(float64(balance) * b.SharePercent / 100))
+			}
+			remaining -= share
+			if share == 0 {
+				continue
+			}
+
+			var target models.Account
+			if err := tx.First(&target, b.BeneficiaryAccountID).Error; err != nil {
+				return &AppError{Code: 500, Message: "Failed to query beneficiary account", Details: err.Error(), Err: err}
+			}
+			if target.BalanceHash != balanceHash(&target, s.secretKey) {
+				return &AppError{Code: 500, Message: "Beneficiary account balance integrity check failed", Details: fmt.Sprintf("account_id: %d", target.ID), Err: s.integrityFailed(target.ID)}
+			}
+
+			amount := fromMinorUnits(share, decimals)
+			if err := s.applyTransfer(tx, &account, &target, amount, 0); err != nil {
+				return err
+			}
+			transaction := models.Transaction{
+				ID:            utils.GenerateTransactionID(s.clock.Now()),
+				FromAccountID: &account.ID,
+				ToAccountID:   &target.ID,
+				Amount:        amount,
+				Type:          "transfer",
+				Status:        "completed",
+				Description:   "Beneficiary distribution",
+				InitiatorID:   &adminID,
+				CreatedAt:     s.clock.Now(),
+			}
+			if err := tx.Create(&transaction).Error; err != nil {
+				return &AppError{Code: 500, Message: "Failed to insert transaction record", Details: err.Error(), Err: err}
+			}
+			if err := s.recordTransferLegs(tx, &transaction); err != nil {
+				return err
+			}
+			transactions = append(transactions, transaction)
+		}
+
+		entry := models.AuditLog{
+			UserID:    &adminID,
+			Action:    "account_distributed",
+			Severity:  models.SeverityCritical,
+			Details:   fmt.Sprintf("account_id: %d, amount: %f, transfers: %d, reason: %s", accountID, fromMinorUnits(balance, decimals), len(transactions), req.Reason),
+			CreatedAt: s.clock.Now(),
+		}
+		if err := tx.Create(&entry).Error; err != nil {
+			return &AppError{Code: 500, Message: "Failed to write audit log", Details: err.Error(), Err: err}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
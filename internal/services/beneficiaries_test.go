@@ -0,0 +1,111 @@
+// Path: internal/services/beneficiaries_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"reflect"
+	"testing"
+)
+
+func TestBeneficiaryValidation(t *testing.T) {
+	accounts := NewAccountService(nil, testSecret, AccountConfig{})
+	_, err := accounts.AddBeneficiary(1, 5, &models.BeneficiaryRequest{AccountID: 5, SharePercent: 120})
+	if got := fieldNames(t, err); !reflect.DeepEqual(got, []string{"beneficiary_account_id", "share_percent"}) {
+		t.Errorf("fields = %v, want beneficiary_account_id and share_percent", got)
+	}
+
+	transactions := NewTransactionService(nil, testSecret, TransactionConfig{})
+	_, err = transactions.DistributeToBeneficiaries(5, &models.DistributionRequest{Reason: "estate"}, &models.Claims{UserID: 1, Role: models.RoleUser})
+	wantAppError(t, err, 403)
+	_, err = transactions.DistributeToBeneficiaries(5, &models.DistributionRequest{Reason: "  "}, &models.Claims{UserID: 1, Role: models.RoleAdmin})
+	if got := fieldNames(t, err); !reflect.DeepEqual(got, []string{"reason"}) {
+		t.Errorf("fields = %v, want reason", got)
+	}
+}
+
+func TestAddBeneficiaries(t *testing.T) {
+	db := testDB(t)
+	owner, heir, stranger := seedUser(t, db, "owner"), seedUser(t, db, "heir"), seedUser(t, db, "stranger")
+	account := seedAccount(t, db, owner, 100, "USD")
+	first, second := seedAccount(t, db, heir, 0, "USD"), seedAccount(t, db, stranger, 0, "USD")
+	euros := seedAccount(t, db, heir, 0, "EUR")
+	s := NewAccountService(db, testSecret, AccountConfig{})
+	add := func(userID, target int, share float64) error {
+		_, err := s.AddBeneficiary(uint(userID), account.ID, &models.BeneficiaryRequest{AccountID: target, SharePercent: share})
+		return err
+	}
+
+	if err := add(owner.ID, first.ID, 60); err != nil {
+		t.Fatalf("add first beneficiary: %v", err)
+	}
+	wantAppError(t, add(stranger.ID, second.ID, 10), 404)       // Not the stranger's account
+	wantAppError(t, add(owner.ID, second.ID+euros.ID, 10), 404) // No such account
+	wantAppError(t, add(owner.ID, euros.ID, 10), 400)           // Another currency
+	wantAppError(t, add(owner.ID, first.ID, 10), 409)           // Already named
+	wantAppError(t, add(owner.ID, second.ID, 50), 400)          // 110 percent in total
+	if err := add(owner.ID, second.ID, 40); err != nil {
+		t.Fatalf("add second beneficiary: %v", err)
+	}
+
+	beneficiaries, err := s.GetBeneficiaries(uint(owner.ID), account.ID)
+	if err != nil {
+		t.Fatalf("GetBeneficiaries: %v", err)
+	}
+	if len(beneficiaries) != 2 || beneficiaries[0].BeneficiaryAccountID != first.ID || beneficiaries[1].SharePercent != 40 {
+		t.Errorf("beneficiaries = %+v", beneficiaries)
+	}
+	_, err = s.GetBeneficiaries(uint(stranger.ID), account.ID)
+	wantAppError(t, err, 404)
+}
+
+func TestDistributeToBeneficiaries(t *testing.T) {
+	db := testDB(t)
+	owner, heir, admin := seedUser(t, db, "owner"), seedUser(t, db, "heir"), seedAdmin(t, db, "executor")
+	account := seedAccount(t, db, owner, 100.01, "USD")
+	first, second := seedAccount(t, db, heir, 0, "USD"), seedAccount(t, db, heir, 0, "USD")
+	accounts := NewAccountService(db, testSecret, AccountConfig{})
+	transactions := NewTransactionService(db, testSecret, TransactionConfig{})
+	distribute := func() ([]models.Transaction, error) {
+		return transactions.DistributeToBeneficiaries(account.ID, &models.DistributionRequest{Reason: "Death certificate received"}, claimsFor(admin))
+	}
+
+	if _, err := accounts.AddBeneficiary(uint(owner.ID), account.ID, &models.BeneficiaryRequest{AccountID: first.ID, SharePercent: 33.33}); err != nil {
+		t.Fatalf("add beneficiary: %v", err)
+	}
+	_, err := distribute()
+	wantAppError(t, err, 409) // Shares total 33.33 percent
+	if _, err := accounts.AddBeneficiary(uint(owner.ID), account.ID, &models.BeneficiaryRequest{AccountID: second.ID, SharePercent: 66.67}); err != nil {
+		t.Fatalf("add beneficiary: %v", err)
+	}
+
+	paid, err := distribute()
+	if err != nil {
+		t.Fatalf("distribute: %v", err)
+	}
+	// 33.33% of 100.01 rounded down is 33.33; the last beneficiary gets the rest.
+	if len(paid) != 2 || paid[0].Amount != 33.33 || paid[1].Amount != 66.68 {
+		t.Errorf("payments = %+v, want 33.33 and 66.68", paid)
+	}
+	for _, p := range paid {
+		if p.Type != "transfer" || p.InitiatorID == nil || *p.InitiatorID != admin.ID {
+			t.Errorf("payment = %+v, want a transfer initiated by the admin", p)
+		}
+	}
+	for id, want := range map[int]float64{account.ID: 0, first.ID: 33.33, second.ID: 66.68} {
+		stored := reloadAccount(t, db, id)
+		if roundAmount(stored.Balance, 2) != want || stored.BalanceHash != balanceHash(stored, testSecret) {
+			t.Errorf("account %d: balance %v, want %v with a matching hash", id, stored.Balance, want)
+		}
+	}
+	if !reloadAccount(t, db, account.ID).Frozen {
+		t.Error("distributed account is not frozen")
+	}
+	var audits int64
+	db.Model(&models.AuditLog{}).Where("action = ?", "account_distributed").Count(&audits)
+	if audits != 1 {
+		t.Errorf("%d distribution audit entries, want 1", audits)
+	}
+
+	_, err = distribute()
+	wantAppError(t, err, 409) // Nothing left
+}
@@ -0,0 +1,63 @@
+// Path: internal/services/cancel_transfer_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"testing"
+)
+
+func TestCancelPendingTransfer(t *testing.T) {
+	db := testDB(t)
+	alice, bob := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+	admin := seedAdmin(t, db, "admin")
+	from, to := seedAccount(t, db, alice, 100, "USD"), seedAccount(t, db, bob, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{})
+
+	pending := &models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: 80, RequireAcceptance: true}
+	if err := s.ProcessTransfer(pending, claimsFor(alice)); err != nil || pending.Status != "incoming_pending" {
+		t.Fatalf("pending transfer = %q, %v", pending.Status, err)
+	}
+
+	// Only the sender (or an admin) cancels.
+	err := s.CancelTransfer(pending.TransactionID, claimsFor(bob))
+	wantAppError(t, err, 403)
+
+	if err := s.CancelTransfer(pending.TransactionID, claimsFor(alice)); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	var cancelled models.Transaction
+	db.First(&cancelled, "id = ?", pending.TransactionID)
+	if cancelled.Status != "cancelled" {
+		t.Errorf("status = %q, want cancelled", cancelled.Status)
+	}
+	err = s.CancelTransfer(pending.TransactionID, claimsFor(alice))
+	wantAppError(t, err, 409)
+
+	// The reserved funds are free again.
+	balance, err := s.ProcessWithdraw(&models.TransactionRequest{AccountID: from.ID, Amount: 50}, claimsFor(alice))
+	if err != nil || balance.AvailableBalance != 50 {
+		t.Errorf("withdrawal after cancelling = %+v, %v; want 50 available", balance, err)
+	}
+	if got := reloadAccount(t, db, to.ID).Balance; got != 0 {
+		t.Errorf("recipient balance = %v, want 0", got)
+	}
+
+	completed := &models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: 10}
+	if err := s.ProcessTransfer(completed, claimsFor(alice)); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	err = s.CancelTransfer(completed.TransactionID, claimsFor(alice))
+	if appErr := wantAppError(t, err, 409); appErr.Message != "Only pending transfers can be cancelled" {
+		t.Errorf("cancelling a completed transfer: %q", appErr.Message)
+	}
+
+	another := &models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: 5, RequireAcceptance: true}
+	if err := s.ProcessTransfer(another, claimsFor(alice)); err != nil {
+		t.Fatalf("pending transfer: %v", err)
+	}
+	if err := s.CancelTransfer(another.TransactionID, claimsFor(admin)); err != nil {
+		t.Errorf("admin cancel: %v", err)
+	}
+	err = s.CancelTransfer("no-such-transfer", claimsFor(alice))
+	wantAppError(t, err, 404)
+}
@@ -0,0 +1,43 @@
+// Path: internal/services/concurrency_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"sync"
+	"testing"
+)
+
+func TestConcurrentTransfersKeepTheBalanceConsistent(t *testing.T) {
+	db := testDB(t)
+	alice := seedUser(t, db, "alice")
+	bob := seedUser(t, db, "bob")
+	from := seedAccount(t, db, alice, 100, "USD")
+	to := seedAccount(t, db, bob, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{})
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.ProcessTransfer(&models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: 10}, claimsFor(alice)); err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	source, destination := reloadAccount(t, db, from.ID), reloadAccount(t, db, to.ID)
+	if succeeded != 10 || source.Balance != 0 || destination.Balance != 100 {
+		t.Errorf("%d transfers succeeded, balances %v and %v; want 10, 0 and 100", succeeded, source.Balance, destination.Balance)
+	}
+	for _, account := range []*models.Account{source, destination} {
+		if account.BalanceHash != balanceHash(account, testSecret) {
+			t.Errorf("hash of account %d does not verify", account.ID)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Path: internal/services/confirmation.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"crypto/rand"
+	"fmt"
+	"log"
+	"math/big"
+	"time"
+)
+
+// Transfer confirmation code settings.
+const (
+	DefaultConfirmationTTL  = 5 * time.Minute
+	maxConfirmationAttempts = 5
+	confirmationCodeDigits  = 6
+	confirmationCodeModulus = 1000000
+	confirmationCodePurpose = "transfer"
+)
+
+// CodeSender delivers one-time confirmation codes to users out of band (email, SMS).
+// Tests can plug in their own sender to read the generated codes.
+type CodeSender interface {
+	Send(userID uint, code string) error
+}
+
+// LogCodeSender simulates delivery by writing codes to the log. For development only.
+type LogCodeSender struct{}
+
+func (LogCodeSender) Send(userID uint, code string) error {
+	log.Printf("Confirmation code for user %d: %s", userID, code)
+	return nil
+}
+
+// transferFingerprint binds a confirmation code to one transfer, so it can't confirm another.
+func transferFingerprint(req *models.TransferRequest) string {
+	return fmt.Sprintf("%s:%d:%d:%.8f", confirmationCodePurpose, req.FromID, req.ToID, req.Amount)
+}
+
+// confirmTransfer enforces the one-time code for transfers above the confirmation threshold.
+// Without a code, a new one is sent and the transfer is refused with 428; with a code, the
+// matching pending code is consumed. The destination must already be resolved.
+func (s *transactionService) confirmTransfer(req *models.TransferRequest, claims *models.Claims) error {
+	if s.cfg.ConfirmationThreshold <= 0 || req.Amount <= s.cfg.ConfirmationThreshold {
+		return nil
+	}
+
+	fingerprint := transferFingerprint(req)
+	if req.ConfirmationCode == "" {
+		return s.issueConfirmationCode(claims.UserID, fingerprint)
+	}
+
+	now := s.clock.Now()
+	var code models.ConfirmationCode
+	err := s.db.Where("user_id = ? AND fingerprint = ? AND used_at IS NULL AND expires_at > ? AND attempts < ?", claims.UserID, fingerprint, now, maxConfirmationAttempts).
+		Order("id DESC").Limit(1).Find(&code).Error
+	if err != nil {
+		return &AppError{Code: 500, Message: "Failed to query confirmation code", Details: err.Error(), Err: err}
+	}
+	if code.ID == 0 {
+		return &AppError{Code: 403, Message: "Invalid confirmation code", Details: "No pending code for this transfer; submit it without a code to get a new one"}
+	}
+
+	if utils.CreateHMAC(req.ConfirmationCode, []byte(s.secretKey)) != code.CodeHash {
+		if err := s.db.Model(&code).Update("attempts", code.Attempts+1).Error; err != nil {
+			return &AppError{Code: 500, Message: "Failed to update confirmation code", Details: err.Error(), Err: err}
+		}
+		return &AppError{Code: 403, Message: "Invalid confirmation code", Details: fmt.Sprintf("%d attempts left", maxConfirmationAttempts-code.Attempts-1)}
+	}
+
+	// Consume the code; the condition keeps a concurrent request from using it twice.
+	result := s.db.Model(&models.ConfirmationCode{}).Where("id = ? AND used_at IS NULL", code.ID).Update("used_at", now)
+	if result.Error != nil {
+		return &AppError{Code: 500, Message: "Failed to consume confirmation code", Details: result.Error.Error(), Err: result.Error}
+	}
+	if result.RowsAffected == 0 {
+		return &AppError{Code: 403, Message: "Invalid confirmation code", Details: "The code has already been used"}
+	}
+
+	return nil
+}
+
+// issueConfirmationCode stores a new code for the transfer, sends it and asks the client to confirm.
+func (s *transactionService) issueConfirmationCode(userID uint, fingerprint string) error {
+	n, err := rand.Int(rand.Reader, big.NewInt(confirmationCodeModulus))
+	if err != nil {
+		return &AppError{Code: 500, Message: "Failed to generate confirmation code", Details: err.Error(), Err: err}
+	}
+	plain := fmt.Sprintf("%0*d", confirmationCodeDigits, n.Int64())
+
+	now := s.clock.Now()
+	code := models.ConfirmationCode{
+		UserID:      int(userID),
+		Fingerprint: fingerprint,
+		CodeHash:    utils.CreateHMAC(plain, []byte(s.secretKey)),
+		ExpiresAt:   now.Add(s.cfg.ConfirmationTTL),
+		CreatedAt:   now,
+	}
+	if err := s.db.Create(&code).Error; err != nil {
+		return &AppError{Code: 500, Message: "Failed to store confirmation code", Details: err.Error(), Err: err}
+	}
+
+	if err := s.cfg.CodeSender.Send(userID, plain); err != nil {
+		return &AppError{Code: 502, Message: "Failed to send confirmation code", Details: err.Error(), Err: err}
+	}
+
+	return &AppError{Code: 428, Message: "Confirmation required", Details: fmt.Sprintf("A confirmation code was sent; repeat the transfer with confirmation_code within %s", s.cfg.ConfirmationTTL)}
+}
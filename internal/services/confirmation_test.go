@@ -0,0 +1,75 @@
+// Path: internal/services/confirmation_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"testing"
+	"time"
+)
+
+// capturedCodes is a CodeSender keeping the last code sent to each user.
+type capturedCodes map[uint]string
+
+func (c capturedCodes) Send(userID uint, code string) error {
+	c[userID] = code
+	return nil
+}
+
+func TestTransferFingerprintBindsTheTransfer(t *testing.T) {
+	base := models.TransferRequest{FromID: 1, ToID: 2, Amount: 500}
+	for _, other := range []models.TransferRequest{{FromID: 1, ToID: 3, Amount: 500}, {FromID: 4, ToID: 2, Amount: 500}, {FromID: 1, ToID: 2, Amount: 500.01}} {
+		if transferFingerprint(&base) == transferFingerprint(&other) {
+			t.Errorf("%+v shares the fingerprint of %+v", other, base)
+		}
+	}
+}
+
+func TestTransferConfirmationCode(t *testing.T) {
+	db := testDB(t)
+	alice := seedUser(t, db, "alice")
+	bob := seedUser(t, db, "bob")
+	from := seedAccount(t, db, alice, 1000, "USD")
+	to := seedAccount(t, db, bob, 0, "USD")
+	codes := capturedCodes{}
+	clock := utils.NewManualClock(time.Now())
+	s := NewTransactionService(db, testSecret, TransactionConfig{ConfirmationThreshold: 100, CodeSender: codes, Clock: clock})
+	transfer := func(amount float64, code string) error {
+		return s.ProcessTransfer(&models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: amount, ConfirmationCode: code}, claimsFor(alice))
+	}
+
+	// At or below the threshold no code is needed.
+	if err := transfer(100, ""); err != nil {
+		t.Fatalf("transfer at the threshold: %v", err)
+	}
+	if len(codes) != 0 {
+		t.Errorf("codes sent below the threshold: %v", codes)
+	}
+
+	wantAppError(t, transfer(300, ""), 428)
+	code := codes[uint(alice.ID)]
+	if len(code) != confirmationCodeDigits {
+		t.Fatalf("code %q sent, want %d digits", code, confirmationCodeDigits)
+	}
+	if got := reloadAccount(t, db, from.ID).Balance; got != 900 {
+		t.Fatalf("balance = %v, want 900: the unconfirmed transfer moved money", got)
+	}
+
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+	wantAppError(t, transfer(300, wrong), 403)
+	wantAppError(t, transfer(301, code), 403) // The code confirms only the transfer it was sent for
+	if err := transfer(300, code); err != nil {
+		t.Fatalf("transfer with the code: %v", err)
+	}
+	if got := reloadAccount(t, db, from.ID).Balance; got != 600 {
+		t.Errorf("balance = %v, want 600", got)
+	}
+	wantAppError(t, transfer(300, code), 403) // Codes are single use
+
+	wantAppError(t, transfer(200, ""), 428)
+	clock.Advance(DefaultConfirmationTTL + time.Second)
+	wantAppError(t, transfer(200, codes[uint(alice.ID)]), 403)
+}
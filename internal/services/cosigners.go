@@ -0,0 +1,102 @@
+// Path: internal/services/cosigners.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"fmt"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// MaxCosigners caps the co-signers of one account.
+const MaxCosigners = 10
+
+// GetCosigners returns the co-signers of one of the user's accounts.
+func (s *accountService) GetCosigners(userID uint, accountID int) (*models.Cosigners, error) {
+	account, err := s.findOwnedAccount(s.db, userID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	return loadCosigners(s.db, account.ID)
+}
+
+// SetCosigners replaces the co-signers of one of the user's accounts: the users who may approve its
+// transfers held for approval, besides administrators. The owner can't co-sign their own account.
+func (s *accountService) SetCosigners(userID uint, accountID int, req *models.Cosigners) (*models.Cosigners, error) {
+	users := map[int]bool{}
+	positive, self := true, false
+	for _, id := range req.UserIDs {
+		users[id] = true
+		positive = positive && id > 0
+		self = self || uint(id) == userID
+	}
+	var v validation
+	v.check(positive, "user_ids", "User IDs must be positive")
+	v.check(!self, "user_ids", "The owner of an account can't be its co-signer")
+	v.check(len(users) <= MaxCosigners, "user_ids", fmt.Sprintf("An account can have at most %d co-signers", MaxCosigners))
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	var cosigners *models.Cosigners
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := s.findOwnedAccount(tx, userID, accountID); err != nil {
+			return err
+		}
+
+		ids := make([]int, 0, len(users))
+		for id := range users {
+			ids = append(ids, id)
+		}
+		var found int64
+		if err := tx.Model(&models.User{}).Where("id IN ? AND deleted_at IS NULL", ids).Count(&found).Error; err != nil {
+			return &AppError{Code: 500, Message: "Failed to query co-signers", Details: err.Error(), Err: err}
+		}
+		if int(found) != len(ids) {
+			return &AppError{Code: 404, Message: "Co-signer not found", Details: fmt.Sprintf("%d of %d users don't exist", len(ids)-int(found), len(ids))}
+		}
+
+		if err := tx.Where("account_id = ?", accountID).Delete(&models.AccountCosigner{}).Error; err != nil {
+			return &AppError{Code: 500, Message: "Failed to update co-signers", Details: err.Error(), Err: err}
+		}
+		if len(ids) > 0 {
+			now := s.cfg.Clock.Now()
+			entries := make([]models.AccountCosigner, len(ids))
+			for i, id := range ids {
+				entries[i] = models.AccountCosigner{AccountID: accountID, UserID: id, CreatedAt: now}
+			}
+			if err := tx.Create(&entries).Error; err != nil {
+				return &AppError{Code: 500, Message: "Failed to update co-signers", Details: err.Error(), Err: err}
+			}
+		}
+
+		var err error
+		cosigners, err = loadCosigners(tx, accountID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cosigners, nil
+}
+
+// loadCosigners reads the co-signers of an account, in ID order.
+func loadCosigners(tx *gorm.DB, accountID int) (*models.Cosigners, error) {
+	ids := []int{}
+	if err := tx.Model(&models.AccountCosigner{}).Where("account_id = ?", accountID).Pluck("user_id", &ids).Error; err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to query co-signers", Details: err.Error(), Err: err}
+	}
+	sort.Ints(ids)
+	return &models.Cosigners{UserIDs: ids}, nil
+}
+
+// isCosigner reports whether the user co-signs the account.
+func isCosigner(tx *gorm.DB, accountID int, userID uint) (bool, error) {
+	var count int64
+	err := tx.Model(&models.AccountCosigner{}).Where("account_id = ? AND user_id = ?", accountID, userID).Count(&count).Error
+	if err != nil {
+		return false, &AppError{Code: 500, Message: "Failed to query co-signers", Details: err.Error(), Err: err}
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,107 @@
+// Path: internal/services/cosigners_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSetCosignersValidation(t *testing.T) {
+	s := NewAccountService(nil, testSecret, AccountConfig{})
+	many := make([]int, MaxCosigners+1)
+	for i := range many {
+		many[i] = i + 10
+	}
+	for name, ids := range map[string][]int{
+		"non-positive ID": {2, 0},
+		"owner":           {2, 1},
+		"too many":        many,
+	} {
+		_, err := s.SetCosigners(1, 1, &models.Cosigners{UserIDs: ids})
+		if names := fieldNames(t, err); len(names) != 1 || names[0] != "user_ids" {
+			t.Errorf("%s: fields %v, want user_ids", name, names)
+		}
+	}
+}
+
+func TestCosignerApprovesTransfer(t *testing.T) {
+	db := testDB(t)
+	alice, bob, carol := seedUser(t, db, "alice"), seedUser(t, db, "bob"), seedUser(t, db, "carol")
+	from, to := seedAccount(t, db, alice, 500, "USD"), seedAccount(t, db, carol, 0, "USD")
+	accounts := NewAccountService(db, testSecret, AccountConfig{})
+	transactions := NewTransactionService(db, testSecret, TransactionConfig{ApprovalThreshold: 100})
+
+	cosigners, err := accounts.SetCosigners(uint(alice.ID), from.ID, &models.Cosigners{UserIDs: []int{bob.ID, bob.ID}})
+	if err != nil {
+		t.Fatalf("set co-signers: %v", err)
+	}
+	if want := (&models.Cosigners{UserIDs: []int{bob.ID}}); !reflect.DeepEqual(cosigners, want) {
+		t.Errorf("co-signers = %+v, want %+v", cosigners, want)
+	}
+	if _, err := accounts.GetCosigners(uint(bob.ID), from.ID); err == nil {
+		t.Error("a co-signer read the co-signers of an account they don't own")
+	}
+	_, err = accounts.SetCosigners(uint(alice.ID), from.ID, &models.Cosigners{UserIDs: []int{bob.ID, 999999}})
+	wantAppError(t, err, 404)
+
+	req := &models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: 150}
+	if err := transactions.ProcessTransfer(req, claimsFor(alice)); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+
+	// Carol receives the transfer but doesn't co-sign the account.
+	err = transactions.ApproveTransfer(req.TransactionID, claimsFor(carol))
+	if appErr := wantAppError(t, err, 403); appErr.Message != "Access denied" {
+		t.Errorf("message = %q", appErr.Message)
+	}
+
+	if err := transactions.ApproveTransfer(req.TransactionID, claimsFor(bob)); err != nil {
+		t.Fatalf("approve by the co-signer: %v", err)
+	}
+	if got := reloadAccount(t, db, to.ID).Balance; got != 150 {
+		t.Errorf("recipient balance = %v, want 150", got)
+	}
+	var transaction models.Transaction
+	if err := db.First(&transaction, "id = ?", req.TransactionID).Error; err != nil {
+		t.Fatalf("load transaction: %v", err)
+	}
+	if transaction.Status != "completed" || transaction.ApproverID == nil || *transaction.ApproverID != bob.ID {
+		t.Errorf("transaction status %q, approver %v; want completed by %d", transaction.Status, transaction.ApproverID, bob.ID)
+	}
+
+	// Once removed, bob no longer decides on the account's transfers.
+	if _, err := accounts.SetCosigners(uint(alice.ID), from.ID, &models.Cosigners{}); err != nil {
+		t.Fatalf("clear co-signers: %v", err)
+	}
+	next := &models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: 150}
+	if err := transactions.ProcessTransfer(next, claimsFor(alice)); err != nil {
+		t.Fatalf("second transfer: %v", err)
+	}
+	wantAppError(t, transactions.RejectTransfer(next.TransactionID, claimsFor(bob)), 403)
+}
+
+func TestCosignerCannotApproveOwnTransfer(t *testing.T) {
+	db := testDB(t)
+	alice, bob := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+	from, to := seedAccount(t, db, alice, 500, "USD"), seedAccount(t, db, bob, 0, "USD")
+	transactions := NewTransactionService(db, testSecret, TransactionConfig{ApprovalThreshold: 100})
+
+	// SetCosigners refuses the owner; the approval check must hold on its own all the same.
+	if err := db.Create(&models.AccountCosigner{AccountID: from.ID, UserID: alice.ID, CreatedAt: time.Now()}).Error; err != nil {
+		t.Fatalf("designate the owner: %v", err)
+	}
+	req := &models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: 150}
+	if err := transactions.ProcessTransfer(req, claimsFor(alice)); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+
+	err := transactions.ApproveTransfer(req.TransactionID, claimsFor(alice))
+	if appErr := wantAppError(t, err, 403); appErr.Message != "Self-approval is not allowed" {
+		t.Errorf("message = %q", appErr.Message)
+	}
+	if got := reloadAccount(t, db, to.ID).Balance; got != 0 {
+		t.Errorf("recipient balance = %v, want 0", got)
+	}
+}
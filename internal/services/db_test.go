@@ -0,0 +1,130 @@
+// Path: internal/services/db_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/accountnumber"
+	"bank-api/pkg/database"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testSecret is the balance secret of the services under test.
+const testSecret = "test-balance-secret"
+
+// testDB opens the Postgres database in TEST_DATABASE_URL on a fresh schema with every migration
+// applied, dropped when the test ends. Tests needing it are skipped without TEST_DATABASE_URL.
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	config := &gorm.Config{Logger: logger.Discard}
+	admin, err := gorm.Open(postgres.Open(dsn), config)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+
+	connConfig, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		t.Fatalf("parse database URL: %v", err)
+	}
+	connConfig.RuntimeParams["search_path"] = schema
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: stdlib.OpenDB(*connConfig)}), config)
+	if err != nil {
+		t.Fatalf("open schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		if sqlDB, err := admin.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	if err := database.Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+// seedUser inserts a user allowed to move money.
+func seedUser(t *testing.T, db *gorm.DB, username string) *models.User {
+	t.Helper()
+	user := &models.User{
+		Username:         username,
+		Password:         "unused",
+		Role:             models.RoleUser,
+		Tier:             models.TierStandard,
+		TransfersEnabled: true,
+		CreatedAt:        time.Now().Format(time.RFC3339),
+	}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return user
+}
+
+// seedAdmin inserts an administrator.
+func seedAdmin(t *testing.T, db *gorm.DB, username string) *models.User {
+	t.Helper()
+	user := seedUser(t, db, username)
+	if err := db.Model(user).Update("role", models.RoleAdmin).Error; err != nil {
+		t.Fatalf("promote user: %v", err)
+	}
+	user.Role = models.RoleAdmin
+	return user
+}
+
+// seedAccount inserts a checking account of user holding balance, signed with testSecret.
+func seedAccount(t *testing.T, db *gorm.DB, user *models.User, balance float64, currency string) *models.Account {
+	t.Helper()
+	account := &models.Account{UserID: user.ID, Balance: balance, Currency: currency, Type: models.AccountTypeChecking}
+	if err := createAccount(db, account, testSecret, time.Now().Add(-24*time.Hour), accountnumber.Internal{}); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	return account
+}
+
+// seedFeeAccounts opens an account collecting the transfer fees in each currency, owned by a user of
+// its own, and returns them as FeeConfig.Accounts.
+func seedFeeAccounts(t *testing.T, db *gorm.DB, currencies ...string) map[string]int {
+	t.Helper()
+	bank := seedUser(t, db, "fees")
+	accounts := make(map[string]int)
+	for _, currency := range currencies {
+		accounts[currency] = seedAccount(t, db, bank, 0, currency).ID
+	}
+	return accounts
+}
+
+// claimsFor returns the claims of a regular user token.
+func claimsFor(user *models.User) *models.Claims {
+	return &models.Claims{UserID: uint(user.ID), Role: user.Role}
+}
+
+// reloadAccount reads an account back from the database.
+func reloadAccount(t *testing.T, db *gorm.DB, id int) *models.Account {
+	t.Helper()
+	var account models.Account
+	if err := db.First(&account, id).Error; err != nil {
+		t.Fatalf("reload account %d: %v", id, err)
+	}
+	return &account
+}
@@ -0,0 +1,60 @@
+// Path: internal/services/deposit_hold_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"testing"
+)
+
+func TestDepositsOverTheThresholdAreHeld(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "depositor")
+	account := seedAccount(t, db, user, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{DepositHoldThreshold: 1000})
+
+	under := &models.TransactionRequest{AccountID: account.ID, Amount: 600}
+	balance, err := s.ProcessDeposit(under, claimsFor(user))
+	if err != nil {
+		t.Fatalf("deposit under the threshold: %v", err)
+	}
+	if under.Status != "completed" || balance.Balance != 600 {
+		t.Errorf("deposit under the threshold: status %q, balance %v; want completed, 600", under.Status, balance.Balance)
+	}
+
+	preview, err := s.PreviewDeposit(&models.TransactionRequest{AccountID: account.ID, Amount: 500}, claimsFor(user))
+	if err != nil {
+		t.Fatalf("preview: %v", err)
+	}
+	if !preview.WouldHold || preview.WindowTotal != 600 {
+		t.Errorf("preview = %+v, want a hold over a window total of 600", preview)
+	}
+
+	over := &models.TransactionRequest{AccountID: account.ID, Amount: 500}
+	balance, err = s.ProcessDeposit(over, claimsFor(user))
+	if err != nil {
+		t.Fatalf("deposit over the threshold: %v", err)
+	}
+	if over.Status != "held" || balance.Balance != 600 {
+		t.Errorf("deposit over the threshold: status %q, balance %v; want held, 600", over.Status, balance.Balance)
+	}
+
+	var held models.Transaction
+	if err := db.First(&held, "id = ?", over.TransactionID).Error; err != nil {
+		t.Fatalf("load held deposit: %v", err)
+	}
+	if held.Status != "held" {
+		t.Errorf("stored status = %q, want held", held.Status)
+	}
+	if got := reloadAccount(t, db, account.ID).Balance; got != 600 {
+		t.Errorf("stored balance = %v, want 600: held deposits are not credited", got)
+	}
+
+	// Held deposits count towards the window, so a small one after them is held too.
+	small := &models.TransactionRequest{AccountID: account.ID, Amount: 1}
+	if _, err := s.ProcessDeposit(small, claimsFor(user)); err != nil {
+		t.Fatalf("small deposit: %v", err)
+	}
+	if small.Status != "held" {
+		t.Errorf("small deposit after a held one: status %q, want held", small.Status)
+	}
+}
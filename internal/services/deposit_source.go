@@ -0,0 +1,122 @@
+// Path: internal/services/deposit_source.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxDepositSourceLength bounds free-form sources when no allowed list is configured.
+const maxDepositSourceLength = 32
+
+// DepositSourceCash is the source of cash deposits, which CashDenominations applies to.
+const DepositSourceCash = "cash"
+
+// DepositSourceConfig restricts the declared sources of deposits (cash, wire, card...).
+type DepositSourceConfig struct {
+	// Allowed lists the accepted sources; a source is then required. Empty makes it optional and free-form.
+	Allowed []string
+	// Limits caps the amount of a single deposit per source.
+	Limits map[string]float64
+	// Flagged sources are always held for review instead of being credited.
+	Flagged []string
+	// CashDenominations is the smallest accepted note or coin per currency: cash deposits must be
+	// a multiple of it. Currencies without one accept any amount their precision allows.
+	CashDenominations map[string]float64
+}
+
+// ParseDenominations parses the smallest cash denominations per currency in the form "USD:1,JPY:1000".
+func ParseDenominations(s string) (map[string]float64, error) {
+	denominations := make(map[string]float64)
+	if strings.TrimSpace(s) == "" {
+		return denominations, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		currency, value, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid denomination %q: expected CURRENCY:AMOUNT", pair)
+		}
+		denomination, err := strconv.ParseFloat(value, 64)
+		if err != nil || denomination <= 0 {
+			return nil, fmt.Errorf("invalid denomination %q: amount must be a positive number", pair)
+		}
+		denominations[strings.ToUpper(strings.TrimSpace(currency))] = denomination
+	}
+	return denominations, nil
+}
+
+// ParseSourceLimits parses per-source deposit limits in the form "cash:1000,card:500".
+func ParseSourceLimits(s string) (map[string]float64, error) {
+	limits := make(map[string]float64)
+	if strings.TrimSpace(s) == "" {
+		return limits, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		source, value, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid source limit %q: expected SOURCE:AMOUNT", pair)
+		}
+		limit, err := strconv.ParseFloat(value, 64)
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("invalid source limit %q: amount must be a positive number", pair)
+		}
+		limits[normalizeDepositSource(source)] = limit
+	}
+	return limits, nil
+}
+
+func normalizeDepositSource(source string) string {
+	return strings.ToLower(strings.TrimSpace(source))
+}
+
+// checkDepositSource normalizes and validates the declared source of a deposit and enforces its limit.
+// It reports whether the source is flagged, in which case the deposit must be held.
+func (s *transactionService) checkDepositSource(req *models.TransactionRequest) (bool, error) {
+	cfg := s.cfg.DepositSources
+	req.Source = normalizeDepositSource(req.Source)
+
+	var v validation
+	if len(cfg.Allowed) > 0 {
+		v.check(contains(cfg.Allowed, req.Source), "source", "Source must be one of: "+strings.Join(cfg.Allowed, ", "))
+	} else {
+		v.check(len(req.Source) <= maxDepositSourceLength, "source", fmt.Sprintf("Source must be at most %d characters", maxDepositSourceLength))
+	}
+	if limit, ok := cfg.Limits[req.Source]; ok {
+		v.check(req.Amount <= limit, "amount", fmt.Sprintf("Deposits from %s are limited to %.2f", req.Source, limit))
+	}
+	if err := v.err(); err != nil {
+		return false, err
+	}
+
+	return req.Source != "" && contains(cfg.Flagged, req.Source), nil
+}
+
+// checkCashDenomination rejects cash deposits that can't be paid in the smallest accepted
+// denomination of the account's currency. The comparison is done in minor units, so float
+// residue never rejects a valid amount.
+func (s *transactionService) checkCashDenomination(req *models.TransactionRequest, currency string) error {
+	denomination, ok := s.cfg.DepositSources.CashDenominations[currency]
+	if req.Source != DepositSourceCash || !ok {
+		return nil
+	}
+
+	decimals := currencyDecimals(currency, s.cfg.CurrencyDecimals)
+	unit := toMinorUnits(denomination, decimals)
+	if unit <= 0 || toMinorUnits(req.Amount, decimals)%unit == 0 {
+		return nil
+	}
+	var v validation
+	v.check(false, "amount", fmt.Sprintf("Cash deposits in %s must be a multiple of %s", currency, formatAmount(denomination, decimals)))
+	return v.err()
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+// Path: internal/services/deposit_source_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"strings"
+	"testing"
+)
+
+func TestCheckDepositSource(t *testing.T) {
+	s := NewTransactionService(nil, testSecret, TransactionConfig{DepositSources: DepositSourceConfig{
+		Allowed: []string{"cash", " Wire ", "card"},
+		Limits:  map[string]float64{"cash": 1000},
+		Flagged: []string{"WIRE"},
+	}}).(*transactionService)
+
+	tests := []struct {
+		source  string
+		amount  float64
+		flagged bool
+		field   string // Invalid field, if any
+	}{
+		{"cash", 1000, false, ""},
+		{" CARD ", 5000, false, ""},
+		{"wire", 50, true, ""},
+		{"cash", 1000.01, false, "amount"},
+		{"crypto", 10, false, "source"},
+		{"", 10, false, "source"},
+	}
+	for _, tt := range tests {
+		req := &models.TransactionRequest{Amount: tt.amount, Source: tt.source}
+		flagged, err := s.checkDepositSource(req)
+		if tt.field != "" {
+			if got := fieldNames(t, err); len(got) != 1 || got[0] != tt.field {
+				t.Errorf("%q %v: invalid fields %v, want %s", tt.source, tt.amount, got, tt.field)
+			}
+			continue
+		}
+		if err != nil || flagged != tt.flagged {
+			t.Errorf("%q %v = %t, %v; want flagged %t", tt.source, tt.amount, flagged, err, tt.flagged)
+		}
+		if req.Source != strings.ToLower(strings.TrimSpace(tt.source)) {
+			t.Errorf("source normalized to %q", req.Source)
+		}
+	}
+}
+
+func TestDepositSourceOptionalWithoutAllowedList(t *testing.T) {
+	s := NewTransactionService(nil, testSecret, TransactionConfig{}).(*transactionService)
+	for _, source := range []string{"", "paycheck"} {
+		if _, err := s.checkDepositSource(&models.TransactionRequest{Amount: 10, Source: source}); err != nil {
+			t.Errorf("source %q: %v", source, err)
+		}
+	}
+	_, err := s.checkDepositSource(&models.TransactionRequest{Amount: 10, Source: strings.Repeat("s", maxDepositSourceLength+1)})
+	wantAppError(t, err, 400)
+}
+
+func TestParseSourceLimits(t *testing.T) {
+	limits, err := ParseSourceLimits(" Cash:1000, card:500")
+	if err != nil || limits["cash"] != 1000 || limits["card"] != 500 {
+		t.Errorf("ParseSourceLimits = %v, %v", limits, err)
+	}
+	for _, bad := range []string{"cash", "cash:x", "cash:0"} {
+		if _, err := ParseSourceLimits(bad); err == nil {
+			t.Errorf("ParseSourceLimits(%q) accepted", bad)
+		}
+	}
+}
+
+func TestFlaggedSourceDepositIsHeld(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "wired")
+	account := seedAccount(t, db, user, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{DepositSources: DepositSourceConfig{Allowed: []string{"cash", "wire"}, Flagged: []string{"wire"}}})
+
+	if _, err := s.ProcessDeposit(&models.TransactionRequest{AccountID: account.ID, Amount: 40, Source: "Cash"}, claimsFor(user)); err != nil {
+		t.Fatalf("cash deposit: %v", err)
+	}
+	if _, err := s.ProcessDeposit(&models.TransactionRequest{AccountID: account.ID, Amount: 60, Source: "wire"}, claimsFor(user)); err != nil {
+		t.Fatalf("wire deposit: %v", err)
+	}
+
+	if got := reloadAccount(t, db, account.ID).Balance; got != 40 {
+		t.Errorf("balance = %v, want 40 with the wire deposit held", got)
+	}
+	var deposits []models.Transaction
+	db.Where("type = ?", "deposit").Order("amount").Find(&deposits)
+	if len(deposits) != 2 || deposits[0].Source != "cash" || deposits[0].Status != "completed" || deposits[1].Source != "wire" || deposits[1].Status != "held" {
+		t.Errorf("deposits = %+v", deposits)
+	}
+}
+
+func TestCheckCashDenomination(t *testing.T) {
+	s := NewTransactionService(nil, testSecret, TransactionConfig{DepositSources: DepositSourceConfig{
+		CashDenominations: map[string]float64{"USD": 1, "EUR": 0.05, "JPY": 1000, "BHD": 0.005},
+	}}).(*transactionService)
+
+	tests := []struct {
+		currency string
+		source   string
+		amount   float64
+		valid    bool
+	}{
+		{"USD", "cash", 20, true},
+		{"USD", "cash", 20.5, false},
+		{"USD", "cash", 0.01, false},
+		{"USD", "wire", 0.01, true}, // Only cash is checked
+		{"EUR", "cash", 0.1 + 0.2, true},
+		{"EUR", "cash", 12.35, true},
+		{"EUR", "cash", 12.37, false},
+		{"JPY", "cash", 15000, true},
+		{"JPY", "cash", 15500, false},
+		{"BHD", "cash", 1.235, true},
+		{"BHD", "cash", 1.234, false},
+		{"GBP", "cash", 0.01, true}, // No denomination configured
+	}
+	for _, tt := range tests {
+		err := s.checkCashDenomination(&models.TransactionRequest{Amount: tt.amount, Source: tt.source}, tt.currency)
+		if tt.valid {
+			if err != nil {
+				t.Errorf("%s %v %s: %v", tt.source, tt.amount, tt.currency, err)
+			}
+			continue
+		}
+		if got := fieldNames(t, err); len(got) != 1 || got[0] != "amount" {
+			t.Errorf("%s %v %s: invalid fields %v, want amount", tt.source, tt.amount, tt.currency, got)
+		}
+	}
+}
+
+func TestParseDenominations(t *testing.T) {
+	denominations, err := ParseDenominations(" usd:1, JPY:1000,EUR:0.05")
+	if err != nil || denominations["USD"] != 1 || denominations["JPY"] != 1000 || denominations["EUR"] != 0.05 {
+		t.Errorf("ParseDenominations = %v, %v", denominations, err)
+	}
+	for _, bad := range []string{"USD", "USD:x", "USD:0", "USD:-1"} {
+		if _, err := ParseDenominations(bad); err == nil {
+			t.Errorf("ParseDenominations(%q) accepted", bad)
+		}
+	}
+}
+
+func TestCashDepositOfAnUnacceptedDenomination(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "teller")
+	account := seedAccount(t, db, user, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{DepositSources: DepositSourceConfig{CashDenominations: map[string]float64{"USD": 1}}})
+
+	_, err := s.ProcessDeposit(&models.TransactionRequest{AccountID: account.ID, Amount: 10.5, Source: "cash"}, claimsFor(user))
+	if appErr := wantAppError(t, err, 400); !strings.Contains(appErr.Fields[0].Message, "multiple of 1.00") {
+		t.Errorf("message = %q, want the denomination named", appErr.Fields[0].Message)
+	}
+	if _, err := s.ProcessDeposit(&models.TransactionRequest{AccountID: account.ID, Amount: 10, Source: "cash"}, claimsFor(user)); err != nil {
+		t.Fatalf("whole cash deposit: %v", err)
+	}
+	if _, err := s.ProcessDeposit(&models.TransactionRequest{AccountID: account.ID, Amount: 0.5, Source: "card"}, claimsFor(user)); err != nil {
+		t.Fatalf("card deposit: %v", err)
+	}
+	if got := reloadAccount(t, db, account.ID).Balance; got != 10.5 {
+		t.Errorf("balance = %v, want 10.5", got)
+	}
+}
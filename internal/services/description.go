@@ -0,0 +1,39 @@
+// Path: internal/services/description.go
+package services
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxDescriptionLength is the maximum description length in runes (not bytes).
+const MaxDescriptionLength = 140
+
+// sanitizeDescription normalizes a user supplied description: it strips control
+// characters and bidi overrides, normalizes to NFC and enforces the rune limit.
+// Emoji are allowed; each code point of an emoji sequence counts towards the limit.
+func sanitizeDescription(description string) (string, error) {
+	description = norm.NFC.String(description)
+	description = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || isBidiOverride(r) || r == utf8.RuneError {
+			return -1
+		}
+		return r
+	}, description)
+	description = strings.TrimSpace(description)
+
+	if utf8.RuneCountInString(description) > MaxDescriptionLength {
+		return "", &AppError{Code: 400, Message: "Invalid description", Details: fmt.Sprintf("Description must be at most %d characters", MaxDescriptionLength)}
+	}
+
+	return description, nil
+}
+
+// isBidiOverride reports whether r is a bidirectional override/isolate that can be used to spoof text.
+func isBidiOverride(r rune) bool {
+	return (r >= '‪' && r <= '‮') || (r >= '⁦' && r <= '⁩')
+}
@@ -0,0 +1,55 @@
+// Path: internal/services/description_test.go
+package services
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSanitizeDescription(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"control characters", "rent\x00 for\tmay\r\n\x1b[31m", "rent formay[31m"},
+		{"bidi overrides", "invoice \u202egnp.exe", "invoice gnp.exe"},
+		{"combining characters are composed", "cafe\u0301", "caf\u00e9"},
+		{"emoji kept", "pizza 🍕", "pizza 🍕"},
+		{"surrounding space trimmed", "  lunch  ", "lunch"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sanitizeDescription(tt.in)
+			if err != nil || got != tt.want {
+				t.Errorf("sanitizeDescription(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeDescriptionLengthInRunes(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		ok   bool
+	}{
+		{"emoji at the limit", strings.Repeat("a", MaxDescriptionLength-1) + "🍕", true},
+		{"emoji over the limit", strings.Repeat("a", MaxDescriptionLength) + "🍕", false},
+		{"multi-byte runes at the limit", strings.Repeat("ж", MaxDescriptionLength), true},
+		// The family emoji is five code points joined by zero width joiners.
+		{"emoji sequence over the limit", strings.Repeat("a", MaxDescriptionLength-4) + "\U0001F468\u200d\U0001F469\u200d\U0001F467", false},
+		{"composed combining characters at the limit", strings.Repeat("e\u0301", MaxDescriptionLength), true},
+		{"control characters don't count", strings.Repeat("a\x00", MaxDescriptionLength), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sanitizeDescription(tt.in)
+			if tt.ok && (err != nil || utf8.RuneCountInString(got) > MaxDescriptionLength) {
+				t.Errorf("got %d runes, %v; want it accepted", utf8.RuneCountInString(got), err)
+			}
+			if !tt.ok {
+				wantAppError(t, err, 400)
+			}
+		})
+	}
+}
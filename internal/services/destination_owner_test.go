@@ -0,0 +1,50 @@
+// Path: internal/services/destination_owner_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"testing"
+)
+
+func TestCheckDestinationOwner(t *testing.T) {
+	from := &models.Account{ID: 1, UserID: 7}
+	own, stranger := &models.Account{ID: 2, UserID: 7}, &models.Account{ID: 3, UserID: 8}
+
+	open := &transactionService{cfg: TransactionConfig{}}
+	restricted := &transactionService{cfg: TransactionConfig{OwnAccountsOnly: true}}
+	for _, s := range []*transactionService{open, restricted} {
+		if err := s.checkDestinationOwner(from, own); err != nil {
+			t.Errorf("OwnAccountsOnly=%v, own account: %v", s.cfg.OwnAccountsOnly, err)
+		}
+	}
+	if err := open.checkDestinationOwner(from, stranger); err != nil {
+		t.Errorf("stranger's account with cross-user transfers allowed: %v", err)
+	}
+	wantAppError(t, restricted.checkDestinationOwner(from, stranger), 403)
+}
+
+func TestOwnAccountsOnly(t *testing.T) {
+	db := testDB(t)
+	alice, bob := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+	checking, savings := seedAccount(t, db, alice, 100, "USD"), seedAccount(t, db, alice, 0, "USD")
+	stranger := seedAccount(t, db, bob, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{OwnAccountsOnly: true})
+
+	if err := s.ProcessTransfer(&models.TransferRequest{FromID: checking.ID, ToID: savings.ID, Amount: 40}, claimsFor(alice)); err != nil {
+		t.Fatalf("transfer to own second account: %v", err)
+	}
+	err := s.ProcessTransfer(&models.TransferRequest{FromID: checking.ID, ToID: stranger.ID, Amount: 10}, claimsFor(alice))
+	wantAppError(t, err, 403)
+
+	for id, want := range map[int]float64{checking.ID: 60, savings.ID: 40, stranger.ID: 0} {
+		if got := reloadAccount(t, db, id).Balance; got != want {
+			t.Errorf("account %d balance = %v, want %v", id, got, want)
+		}
+	}
+
+	// The same transfer goes through once cross-user transfers are allowed.
+	open := NewTransactionService(db, testSecret, TransactionConfig{})
+	if err := open.ProcessTransfer(&models.TransferRequest{FromID: checking.ID, ToID: stranger.ID, Amount: 10}, claimsFor(alice)); err != nil {
+		t.Errorf("transfer to bob without the restriction: %v", err)
+	}
+}
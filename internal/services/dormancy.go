@@ -0,0 +1,136 @@
+// Path: internal/services/dormancy.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DormancyConfig configures how accounts without activity become dormant. A dormant account can't
+// be used until its owner reactivates it.
+type DormancyConfig struct {
+	// After is how long an account may go without deposits, withdrawals or outgoing transfers
+	// before it becomes dormant. Zero disables dormancy.
+	After time.Duration
+	// Fee is charged once when an account becomes dormant, at most its positive balance.
+	Fee float64
+}
+
+// checkNotDormant rejects operations on a dormant account.
+func checkNotDormant(account *models.Account) error {
+	if account.Dormant {
+		return &AppError{Code: 403, Message: "Account dormant", Details: fmt.Sprintf("account_id: %d. Reactivate it with POST /api/accounts/%d/reactivate", account.ID, account.ID)}
+	}
+	return nil
+}
+
+// FlagDormant marks the accounts without activity for longer than the dormancy period as dormant,
+// charging the dormancy fee if one is set. Sandbox accounts are left alone. It returns the number
+// of accounts flagged.
+func (s *accountService) FlagDormant() (int, error) {
+	if s.cfg.Dormancy.After <= 0 {
+		return 0, nil
+	}
+
+	cutoff := s.cfg.Clock.Now().Add(-s.cfg.Dormancy.After)
+	var accounts []models.Account
+	err := s.db.Where("NOT dormant AND NOT sandbox AND closed_at IS NULL AND COALESCE(last_activity_at, created_at) < ?", cutoff).Order("id").Find(&accounts).Error
+	if err != nil {
+		return 0, &AppError{Code: 500, Message: "Failed to query inactive accounts", Details: err.Error(), Err: err}
+	}
+
+	flagged := 0
+	for i := range accounts {
+		ok, err := s.makeDormant(&accounts[i])
+		if err != nil {
+			log.Printf("Failed to mark account %d dormant: %v", accounts[i].ID, err)
+			continue
+		}
+		if ok {
+			flagged++
+		}
+	}
+	return flagged, nil
+}
+
+// makeDormant flags one account and charges the dormancy fee. It reports false if the account
+// changed since it was read; the next run looks at it again.
+func (s *accountService) makeDormant(account *models.Account) (bool, error) {
+	if err := s.verifyIntegrity(account); err != nil {
+		return false, err
+	}
+
+	fee := 0.0
+	if s.cfg.Dormancy.Fee > 0 && account.Balance > 0 {
+		fee = math.Min(s.cfg.Dormancy.Fee, account.Balance)
+	}
+	// The fee draws from the sub-balances in the same priority order as transactions.
+	hash := account.BalanceHash
+	drawSubBalances(account, fee, currencyDecimals(account.Currency, nil), s.cfg.SubBalances.Priority)
+	account.Balance -= fee
+	account.BalanceHash = balanceHash(account, s.secretKey)
+
+	var flagged bool
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Account{}).
+			Where("id = ? AND balance_hash = ? AND NOT dormant", account.ID, hash).
+			Updates(map[string]interface{}{
+				"dormant":      true,
+				"balance":      account.Balance,
+				"sub_balances": account.SubBalances,
+				"balance_hash": account.BalanceHash,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		flagged = true
+		if fee == 0 {
+			return nil
+		}
+		transaction := models.Transaction{
+			ID:            utils.GenerateTransactionID(s.cfg.Clock.Now()),
+			FromAccountID: &account.ID,
+			Amount:        fee,
+			Type:          "dormancy_fee",
+			Status:        "completed",
+			Description:   "Dormant account fee",
+			CreatedAt:     s.cfg.Clock.Now(),
+		}
+		return tx.Create(&transaction).Error
+	})
+	return flagged, err
+}
+
+// Reactivate lets the owner use a dormant account again.
+func (s *accountService) Reactivate(userID uint, accountID int) (*models.Account, error) {
+	account, err := s.findOwnedAccount(s.db, userID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if !account.Dormant {
+		return nil, &AppError{Code: 409, Message: "Account is not dormant", Details: fmt.Sprintf("account_id: %d", accountID)}
+	}
+
+	now := s.cfg.Clock.Now()
+	result := s.db.Model(&models.Account{}).Where("id = ? AND dormant", accountID).
+		Updates(map[string]interface{}{"dormant": false, "last_activity_at": now})
+	if result.Error != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to reactivate account", Details: result.Error.Error(), Err: result.Error}
+	}
+	if result.RowsAffected == 0 {
+		return nil, &AppError{Code: 409, Message: "Account is not dormant", Details: fmt.Sprintf("account_id: %d", accountID)}
+	}
+
+	account.Dormant, account.LastActivityAt = false, &now
+	return account, nil
+}
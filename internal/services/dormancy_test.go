@@ -0,0 +1,82 @@
+// Path: internal/services/dormancy_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"testing"
+	"time"
+)
+
+func TestCheckNotDormant(t *testing.T) {
+	if err := checkNotDormant(&models.Account{ID: 1}); err != nil {
+		t.Errorf("active account: %v", err)
+	}
+	wantAppError(t, checkNotDormant(&models.Account{ID: 1, Dormant: true}), 403)
+}
+
+func TestFlagDormantDisabled(t *testing.T) {
+	s := NewAccountService(nil, testSecret, AccountConfig{}).(*accountService)
+	if flagged, err := s.FlagDormant(); flagged != 0 || err != nil {
+		t.Errorf("FlagDormant without a period = %d, %v", flagged, err)
+	}
+}
+
+func TestDormancyAndReactivation(t *testing.T) {
+	db := testDB(t)
+	alice, bob := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+	idle, active := seedAccount(t, db, alice, 50, "USD"), seedAccount(t, db, alice, 50, "USD")
+	other := seedAccount(t, db, bob, 0, "USD")
+
+	start := time.Now()
+	clock := utils.NewManualClock(start.Add(10 * 24 * time.Hour))
+	transactions := NewTransactionService(db, testSecret, TransactionConfig{Clock: clock})
+	if _, err := transactions.ProcessDeposit(&models.TransactionRequest{AccountID: active.ID, Amount: 5}, claimsFor(alice)); err != nil {
+		t.Fatalf("deposit: %v", err)
+	}
+
+	// Fifteen days on, the idle account crossed the ten day threshold; the other was used five days ago.
+	clock.Set(start.Add(15 * 24 * time.Hour))
+	accounts := NewAccountService(db, testSecret, AccountConfig{Clock: clock, Dormancy: DormancyConfig{After: 10 * 24 * time.Hour, Fee: 2}})
+	if flagged, err := accounts.FlagDormant(); err != nil || flagged != 1 {
+		t.Fatalf("FlagDormant = %d, %v; want 1", flagged, err)
+	}
+	stored := reloadAccount(t, db, idle.ID)
+	if !stored.Dormant || stored.Balance != 48 {
+		t.Errorf("idle account: dormant %v, balance %v; want dormant with the fee of 2 charged", stored.Dormant, stored.Balance)
+	}
+	if stored.BalanceHash != balanceHash(stored, testSecret) {
+		t.Error("stored hash does not match the balance after the fee")
+	}
+	if reloadAccount(t, db, active.ID).Dormant {
+		t.Error("recently used account was flagged")
+	}
+
+	// A dormant account can't be used.
+	_, err := transactions.ProcessDeposit(&models.TransactionRequest{AccountID: idle.ID, Amount: 5}, claimsFor(alice))
+	wantAppError(t, err, 403)
+	_, err = transactions.ProcessWithdraw(&models.TransactionRequest{AccountID: idle.ID, Amount: 5}, claimsFor(alice))
+	wantAppError(t, err, 403)
+	err = transactions.ProcessTransfer(&models.TransferRequest{FromID: idle.ID, ToID: other.ID, Amount: 5}, claimsFor(alice))
+	wantAppError(t, err, 403)
+
+	// Only the owner reactivates it, once.
+	_, err = accounts.Reactivate(uint(bob.ID), idle.ID)
+	wantAppError(t, err, 404)
+	if _, err := accounts.Reactivate(uint(alice.ID), idle.ID); err != nil {
+		t.Fatalf("reactivate: %v", err)
+	}
+	_, err = accounts.Reactivate(uint(alice.ID), idle.ID)
+	wantAppError(t, err, 409)
+
+	// Reactivation counts as activity and restores normal operation.
+	if flagged, err := accounts.FlagDormant(); err != nil || flagged != 0 {
+		t.Errorf("FlagDormant after reactivation = %d, %v; want 0", flagged, err)
+	}
+	if err := transactions.ProcessTransfer(&models.TransferRequest{FromID: idle.ID, ToID: other.ID, Amount: 5}, claimsFor(alice)); err != nil {
+		t.Errorf("transfer after reactivation: %v", err)
+	}
+	if got := reloadAccount(t, db, idle.ID).Balance; got != 43 {
+		t.Errorf("balance = %v, want 43", got)
+	}
+}
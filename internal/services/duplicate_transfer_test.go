@@ -0,0 +1,54 @@
+// Path: internal/services/duplicate_transfer_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"testing"
+	"time"
+)
+
+func TestDuplicateWindowDisabled(t *testing.T) {
+	s := &transactionService{}
+	req := &models.TransferRequest{FromID: 1, ToID: 2, Amount: 10}
+	if duplicate, err := s.findDuplicateTransfer(req, &models.Claims{UserID: 1}); duplicate || err != nil {
+		t.Errorf("without a window: duplicate %v, %v", duplicate, err)
+	}
+}
+
+func TestDuplicateWindow(t *testing.T) {
+	db := testDB(t)
+	alice, bob := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+	from, to := seedAccount(t, db, alice, 100, "USD"), seedAccount(t, db, bob, 0, "USD")
+	clock := utils.NewManualClock(time.Now())
+	s := NewTransactionService(db, testSecret, TransactionConfig{Clock: clock, DuplicateWindow: 10 * time.Second})
+	transfer := func(amount float64, description string) *models.TransferRequest {
+		t.Helper()
+		req := &models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: amount, Description: description}
+		if err := s.ProcessTransfer(req, claimsFor(alice)); err != nil {
+			t.Fatalf("transfer: %v", err)
+		}
+		return req
+	}
+
+	first := transfer(10, "rent")
+	clock.Advance(2 * time.Second)
+	repeat := transfer(10, "rent")
+	if !repeat.Duplicate || repeat.TransactionID != first.TransactionID || repeat.Status != first.Status {
+		t.Errorf("double submit = %+v, want the result of %s", repeat, first.TransactionID)
+	}
+
+	// A different amount or description is a new transfer.
+	if transfer(11, "rent").Duplicate || transfer(10, "rent, part two").Duplicate {
+		t.Error("a transfer differing from the first was deduplicated")
+	}
+
+	// After the window the same transfer executes again.
+	clock.Advance(20 * time.Second)
+	if later := transfer(10, "rent"); later.Duplicate || later.TransactionID == first.TransactionID {
+		t.Errorf("repeat after the window = %+v, want a new transfer", later)
+	}
+	if got := reloadAccount(t, db, from.ID).Balance; got != 59 {
+		t.Errorf("sender balance = %v, want 59 after four transfers", got)
+	}
+}
@@ -0,0 +1,84 @@
+// Path: internal/services/events.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Event types of the event log.
+const (
+	EventAccountCreated = "AccountCreated"
+	EventDeposited      = "Deposited"
+	EventWithdrawn      = "Withdrawn"
+	EventTransferred    = "Transferred"
+)
+
+// eventLogLockID is the advisory lock serializing appends to the event log.
+const eventLogLockID = 7302
+
+// appendEvent adds an event about account to the log within tx, so it is committed or rolled back
+// together with the change it records. transaction is nil for AccountCreated. Sandbox accounts
+// are left out: their money isn't real.
+//
+// Sequence numbers are taken under a lock held until tx commits. Without it a transaction could
+// commit a lower number after a consumer has already read past it, and the event would be lost.
+func appendEvent(tx *gorm.DB, eventType string, account *models.Account, transaction *models.Transaction) error {
+	if account.Sandbox {
+		return nil
+	}
+
+	event := models.Event{
+		Type:      eventType,
+		AccountID: account.ID,
+		Currency:  account.Currency,
+		CreatedAt: account.CreatedAt,
+	}
+	if transaction != nil {
+		event.TransactionID = &transaction.ID
+		event.Amount, event.Fee = transaction.Amount, transaction.Fee
+		event.CreatedAt = transaction.CreatedAt
+		if eventType == EventTransferred {
+			event.CounterAccountID = transaction.ToAccountID
+		}
+	}
+
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", eventLogLockID).Error; err != nil {
+		return &AppError{Code: 500, Message: "Failed to lock event log", Details: err.Error(), Err: err}
+	}
+	if err := tx.Create(&event).Error; err != nil {
+		return &AppError{Code: 500, Message: "Failed to append event", Details: err.Error(), Err: err}
+	}
+	return nil
+}
+
+// appendTransferEvent records a completed transfer, looking up its source account.
+func appendTransferEvent(tx *gorm.DB, transaction *models.Transaction) error {
+	var from models.Account
+	if err := tx.Select("id", "currency", "sandbox").Where("id = ?", *transaction.FromAccountID).First(&from).Error; err != nil {
+		return &AppError{Code: 500, Message: "Failed to query source account", Details: err.Error(), Err: err}
+	}
+	return appendEvent(tx, EventTransferred, &from, transaction)
+}
+
+// ListEvents returns the events following filter.After in sequence order, for consumers tailing
+// the log: pass the Seq of the last event received to get the next page.
+func (s *transactionService) ListEvents(filter models.EventFilter) ([]models.Event, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultHistoryLimit
+	}
+	if filter.Limit > MaxHistoryLimit {
+		filter.Limit = MaxHistoryLimit
+	}
+	if filter.After < 0 {
+		return nil, &AppError{Code: 400, Message: "Invalid query parameter", Details: fmt.Sprintf("after: %d", filter.After)}
+	}
+
+	events := []models.Event{}
+	if err := s.db.Where("seq > ?", filter.After).Order("seq").Limit(filter.Limit).Find(&events).Error; err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to query events", Details: err.Error(), Err: err}
+	}
+	return events, nil
+}
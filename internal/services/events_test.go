@@ -0,0 +1,102 @@
+// Path: internal/services/events_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"reflect"
+	"testing"
+)
+
+func TestListEventsRejectsNegativeSequence(t *testing.T) {
+	s := NewTransactionService(nil, testSecret, TransactionConfig{})
+	_, err := s.ListEvents(models.EventFilter{After: -1})
+	wantAppError(t, err, 400)
+}
+
+// eventTypes returns the types of events in order.
+func eventTypes(events []models.Event) []string {
+	types := make([]string, len(events))
+	for i, event := range events {
+		types[i] = event.Type
+	}
+	return types
+}
+
+func TestOperationsAppendOrderedEvents(t *testing.T) {
+	db := testDB(t)
+	alice, bob := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+	from, to := seedAccount(t, db, alice, 0, "USD"), seedAccount(t, db, bob, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{})
+
+	if _, err := s.ProcessDeposit(&models.TransactionRequest{AccountID: from.ID, Amount: 100}, claimsFor(alice)); err != nil {
+		t.Fatalf("deposit: %v", err)
+	}
+	if _, err := s.ProcessWithdraw(&models.TransactionRequest{AccountID: from.ID, Amount: 20}, claimsFor(alice)); err != nil {
+		t.Fatalf("withdraw: %v", err)
+	}
+	// A failed operation rolls its event back with it.
+	if _, err := s.ProcessWithdraw(&models.TransactionRequest{AccountID: from.ID, Amount: 1000}, claimsFor(alice)); err == nil {
+		t.Fatal("withdrawal beyond the balance succeeded")
+	}
+	transfer := &models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: 30}
+	if err := s.ProcessTransfer(transfer, claimsFor(alice)); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+
+	events, err := s.ListEvents(models.EventFilter{})
+	if err != nil {
+		t.Fatalf("list events: %v", err)
+	}
+	want := []string{EventAccountCreated, EventAccountCreated, EventDeposited, EventWithdrawn, EventTransferred}
+	if got := eventTypes(events); !reflect.DeepEqual(got, want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+	for i := 1; i < len(events); i++ {
+		if events[i].Seq <= events[i-1].Seq {
+			t.Errorf("event %d has seq %d after %d", i, events[i].Seq, events[i-1].Seq)
+		}
+	}
+	if created := events[1]; created.AccountID != to.ID || created.TransactionID != nil {
+		t.Errorf("AccountCreated = %+v, want one for account %d", created, to.ID)
+	}
+	if deposited := events[2]; deposited.AccountID != from.ID || deposited.Amount != 100 || deposited.Currency != "USD" || deposited.TransactionID == nil {
+		t.Errorf("Deposited = %+v", deposited)
+	}
+	transferred := events[4]
+	if transferred.AccountID != from.ID || transferred.CounterAccountID == nil || *transferred.CounterAccountID != to.ID ||
+		transferred.Amount != 30 || transferred.TransactionID == nil || *transferred.TransactionID != transfer.TransactionID {
+		t.Errorf("Transferred = %+v, want 30 from %d to %d", transferred, from.ID, to.ID)
+	}
+}
+
+func TestTailEventsAfterSequence(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "tail")
+	account := seedAccount(t, db, user, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{})
+	for i := 0; i < 4; i++ {
+		if _, err := s.ProcessDeposit(&models.TransactionRequest{AccountID: account.ID, Amount: float64(i + 1)}, claimsFor(user)); err != nil {
+			t.Fatalf("deposit %d: %v", i, err)
+		}
+	}
+
+	all, err := s.ListEvents(models.EventFilter{})
+	if err != nil || len(all) != 5 {
+		t.Fatalf("ListEvents = %d events, %v; want 5", len(all), err)
+	}
+	newer, err := s.ListEvents(models.EventFilter{After: all[2].Seq})
+	if err != nil {
+		t.Fatalf("tail: %v", err)
+	}
+	if !reflect.DeepEqual(newer, all[3:]) {
+		t.Errorf("events after seq %d = %+v, want %+v", all[2].Seq, newer, all[3:])
+	}
+
+	page, err := s.ListEvents(models.EventFilter{After: all[0].Seq, Limit: 2})
+	if err != nil || len(page) != 2 || page[0].Seq != all[1].Seq || page[1].Seq != all[2].Seq {
+		t.Errorf("page of 2 after seq %d = %+v, %v", all[0].Seq, page, err)
+	}
+	if tail, err := s.ListEvents(models.EventFilter{After: all[4].Seq}); err != nil || len(tail) != 0 {
+		t.Errorf("events after the last = %+v, %v; want none", tail, err)
+	}
+}
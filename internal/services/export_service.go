@@ -0,0 +1,147 @@
+// Path: internal/services/export_service.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ExportService builds the data portability archive of a user.
+type ExportService interface {
+	Export(userID uint) (func(w io.Writer) error, error)
+	AccountStatement(userID uint, accountID int, from, to time.Time, format string) (func(w io.Writer) error, error)
+}
+
+type exportService struct {
+	db    *gorm.DB
+	clock utils.Clock
+}
+
+// NewExportService creates a new ExportService.
+func NewExportService(db *gorm.DB, clock utils.Clock) ExportService {
+	return &exportService{
+		db:    db,
+		clock: clock,
+	}
+}
+
+// exportSection is one top-level field of the archive.
+type exportSection struct {
+	name  string
+	value interface{}
+}
+
+// Export loads the user's profile, accounts, payees, tags and login history and returns a function
+// writing them as a JSON archive, followed by the full transaction history streamed from the database.
+// Secrets (password and balance hashes) are never included. Errors found before streaming are returned
+// directly; errors while streaming leave the archive truncated.
+func (s *exportService) Export(userID uint) (func(w io.Writer) error, error) {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &AppError{Code: 404, Message: "User not found", Details: fmt.Sprintf("user_id: %d", userID)}
+		}
+		return nil, &AppError{Code: 500, Message: "Failed to query user", Details: err.Error(), Err: err}
+	}
+
+	accounts := []models.Account{}
+	if err := s.db.Where("user_id = ?", userID).Order("id").Find(&accounts).Error; err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to query accounts", Details: err.Error(), Err: err}
+	}
+	payees := []models.Payee{}
+	if err := s.db.Where("user_id = ?", userID).Order("id").Find(&payees).Error; err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to query payees", Details: err.Error(), Err: err}
+	}
+	tags := []models.TransactionTag{}
+	if err := s.db.Where("user_id = ?", userID).Order("transaction_id, tag").Find(&tags).Error; err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to query tags", Details: err.Error(), Err: err}
+	}
+	logins := []models.LoginAttempt{}
+	if err := s.db.Where("user_id = ?", userID).Order("created_at, id").Find(&logins).Error; err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to query login history", Details: err.Error(), Err: err}
+	}
+
+	sections := []exportSection{
+		{"exported_at", s.clock.Now()},
+		{"profile", user},
+		{"accounts", accounts},
+		{"payees", payees},
+		{"transaction_tags", tags},
+		{"login_history", logins},
+	}
+
+	return func(w io.Writer) error {
+		if _, err := io.WriteString(w, "{"); err != nil {
+			return err
+		}
+		for _, section := range sections {
+			if err := writeExportField(w, section.name, section.value); err != nil {
+				return err
+			}
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		if _, err := io.WriteString(w, `"transactions":[`); err != nil {
+			return err
+		}
+		if err := s.streamTransactions(w, userID); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, "]}")
+		return err
+	}, nil
+}
+
+// streamTransactions writes every transaction touching the user's accounts, oldest first,
+// without holding the whole history in memory.
+func (s *exportService) streamTransactions(w io.Writer, userID uint) error {
+	rows, err := s.db.Model(&models.Transaction{}).
+		Where("from_account_id IN (?) OR to_account_id IN (?)", ownedAccountIDs(s.db, userID), ownedAccountIDs(s.db, userID)).
+		Order("created_at, id").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	first := true
+	for rows.Next() {
+		var transaction models.Transaction
+		if err := s.db.ScanRows(rows, &transaction); err != nil {
+			return err
+		}
+		if !first {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		first = false
+
+		data, err := json.Marshal(transaction)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func writeExportField(w io.Writer, name string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%q:%s", name, data)
+	return err
+}
@@ -0,0 +1,85 @@
+// Path: internal/services/export_service_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportSectionsOmitSecrets(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeExportField(&buf, "profile", models.User{Username: "portable", Password: "hash", TOTPSecret: "totp"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeExportField(&buf, "account", models.Account{ID: 1, BalanceHash: "hmac"}); err != nil {
+		t.Fatal(err)
+	}
+	raw := buf.String()
+	if !strings.HasPrefix(raw, `"profile":{`) || !strings.Contains(raw, `"portable"`) {
+		t.Errorf("section = %s", raw)
+	}
+	for _, secret := range []string{"hash", "totp", "hmac"} {
+		if strings.Contains(raw, `"`+secret+`"`) {
+			t.Errorf("section contains %q: %s", secret, raw)
+		}
+	}
+}
+
+func TestExportOmitsSecrets(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "portable")
+	if err := db.Model(user).Updates(map[string]interface{}{"password": "$2a$10$secretpasswordhash", "totp_secret": "JBSWY3DPEHPK3PXP"}).Error; err != nil {
+		t.Fatalf("set secrets: %v", err)
+	}
+	account := seedAccount(t, db, user, 0, "USD")
+	transactions := NewTransactionService(db, testSecret, TransactionConfig{})
+	for _, amount := range []float64{25, 15} {
+		if _, err := transactions.ProcessDeposit(&models.TransactionRequest{AccountID: account.ID, Amount: amount}, claimsFor(user)); err != nil {
+			t.Fatalf("deposit: %v", err)
+		}
+	}
+	other := seedAccount(t, db, seedUser(t, db, "other"), 10, "USD")
+
+	write, err := NewExportService(db, utils.NewManualClock(time.Now())).Export(uint(user.ID))
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := write(&buf); err != nil {
+		t.Fatalf("write archive: %v", err)
+	}
+
+	var archive struct {
+		Profile      map[string]interface{}   `json:"profile"`
+		Accounts     []map[string]interface{} `json:"accounts"`
+		Transactions []models.Transaction     `json:"transactions"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &archive); err != nil {
+		t.Fatalf("archive is not JSON: %v\n%s", err, buf.String())
+	}
+	if archive.Profile["username"] != "portable" {
+		t.Errorf("profile = %v", archive.Profile)
+	}
+	if len(archive.Accounts) != 1 || int(archive.Accounts[0]["id"].(float64)) != account.ID {
+		t.Errorf("accounts = %v, want only account %d", archive.Accounts, account.ID)
+	}
+	if len(archive.Transactions) != 2 || archive.Transactions[0].Amount != 25 || archive.Transactions[1].Amount != 15 {
+		t.Errorf("transactions = %+v, want both deposits oldest first", archive.Transactions)
+	}
+
+	raw := buf.String()
+	stored := reloadAccount(t, db, account.ID)
+	for _, secret := range []string{"$2a$10$secretpasswordhash", "JBSWY3DPEHPK3PXP", stored.BalanceHash, `"password"`, `"balance_hash"`, `"totp_secret"`} {
+		if strings.Contains(raw, secret) {
+			t.Errorf("archive contains %q", secret)
+		}
+	}
+	if strings.Contains(raw, *other.Number) {
+		t.Error("archive contains another user's account")
+	}
+}
@@ -0,0 +1,73 @@
+// Path: internal/services/failed_transactions.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// recordFailure stores a rejected deposit, withdrawal or transfer with status "failed" when
+// RecordFailures is on, and returns cause unchanged. It runs after the attempt's transaction
+// rolled back, so balances are never touched.
+//
+// Only attempts on an account the user owns are recorded: unknown accounts and pending
+// confirmations leave no row. A failed transfer keeps only its source account, with the
+// destination in the reason, so the recipient never sees attempts that didn't reach them.
+func (s *transactionService) recordFailure(cause error, claims *models.Claims, attempt models.Transaction) error {
+	if cause == nil || !s.cfg.RecordFailures {
+		return cause
+	}
+	var appErr *AppError
+	if errors.As(cause, &appErr) && (appErr.Code == 404 || appErr.Code == 428) {
+		return cause
+	}
+
+	accountID := attempt.ToAccountID
+	if attempt.FromAccountID != nil {
+		accountID = attempt.FromAccountID
+	}
+	var owned int64
+	err := s.db.Model(&models.Account{}).
+		Where("id = ? AND user_id = ? AND sandbox = ?", *accountID, claims.UserID, claims.Sandbox).
+		Count(&owned).Error
+	if err != nil || owned == 0 {
+		return cause
+	}
+
+	reason := failureReason(cause)
+	if attempt.FromAccountID != nil && attempt.ToAccountID != nil {
+		reason = fmt.Sprintf("%s (to account %d)", reason, *attempt.ToAccountID)
+		attempt.ToAccountID = nil
+	}
+
+	initiatorID := int(claims.UserID)
+	attempt.ID = utils.GenerateTransactionID(s.clock.Now())
+	attempt.Status = "failed"
+	attempt.FailureReason = reason
+	attempt.InitiatorID = &initiatorID
+	attempt.CreatedAt = s.clock.Now()
+	if err := s.db.Create(&attempt).Error; err != nil {
+		// The attempt failed anyway; losing its trace must not change the response.
+		log.Printf("Failed to record failed %s on account %d: %v", attempt.Type, *accountID, err)
+	}
+
+	return cause
+}
+
+// failureReason describes why an attempt failed.
+func failureReason(err error) string {
+	var appErr *AppError
+	if !errors.As(err, &appErr) {
+		return err.Error()
+	}
+	if len(appErr.Fields) > 0 {
+		return fmt.Sprintf("%s: %s %s", appErr.Message, appErr.Fields[0].Field, appErr.Fields[0].Message)
+	}
+	if appErr.Details != "" {
+		return appErr.Message + ": " + appErr.Details
+	}
+	return appErr.Message
+}
@@ -0,0 +1,108 @@
+// Path: internal/services/failed_transactions_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFailureReason(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{errors.New("connection reset"), "connection reset"},
+		{&AppError{Code: 403, Message: "Transfers disabled"}, "Transfers disabled"},
+		{&AppError{Code: 400, Message: "Insufficient funds", Details: "account_id: 3"}, "Insufficient funds: account_id: 3"},
+		{&AppError{Code: 400, Message: "Validation failed", Details: "ignored", Fields: []FieldError{{Field: "amount", Message: "must be positive"}, {Field: "to_id", Message: "required"}}},
+			"Validation failed: amount must be positive"},
+	}
+	for _, tt := range tests {
+		if got := failureReason(tt.err); got != tt.want {
+			t.Errorf("failureReason(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRecordFailureSkipsWithoutTouchingTheDatabase(t *testing.T) {
+	cause := &AppError{Code: 400, Message: "Insufficient funds"}
+	accountID := 1
+	attempt := models.Transaction{Type: "withdraw", FromAccountID: &accountID}
+
+	off := NewTransactionService(nil, testSecret, TransactionConfig{}).(*transactionService)
+	if err := off.recordFailure(cause, &models.Claims{UserID: 1}, attempt); err != cause {
+		t.Errorf("disabled recording returned %v", err)
+	}
+
+	on := NewTransactionService(nil, testSecret, TransactionConfig{RecordFailures: true}).(*transactionService)
+	for _, code := range []int{404, 428} {
+		skipped := &AppError{Code: code, Message: "skipped"}
+		if err := on.recordFailure(skipped, &models.Claims{UserID: 1}, attempt); err != skipped {
+			t.Errorf("code %d returned %v", code, err)
+		}
+	}
+	if err := on.recordFailure(nil, &models.Claims{UserID: 1}, attempt); err != nil {
+		t.Errorf("success returned %v", err)
+	}
+}
+
+func TestRejectedWithdrawalIsRecorded(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		db := testDB(t)
+		user := seedUser(t, db, "failing")
+		account := seedAccount(t, db, user, 10, "USD")
+		s := NewTransactionService(db, testSecret, TransactionConfig{RecordFailures: enabled})
+
+		_, err := s.ProcessWithdraw(&models.TransactionRequest{AccountID: account.ID, Amount: 50}, claimsFor(user))
+		wantAppError(t, err, 400)
+
+		var failed []models.Transaction
+		if err := db.Where("status = ?", "failed").Find(&failed).Error; err != nil {
+			t.Fatalf("query failed transactions: %v", err)
+		}
+		if !enabled {
+			if len(failed) != 0 {
+				t.Errorf("recording disabled, yet %d failed rows", len(failed))
+			}
+			continue
+		}
+		if len(failed) != 1 {
+			t.Fatalf("%d failed rows, want 1", len(failed))
+		}
+		row := failed[0]
+		if row.Type != "withdraw" || row.Amount != 50 || row.FromAccountID == nil || *row.FromAccountID != account.ID ||
+			!strings.HasPrefix(row.FailureReason, "Insufficient funds") {
+			t.Errorf("failed row = %+v", row)
+		}
+		if got := reloadAccount(t, db, account.ID).Balance; got != 10 {
+			t.Errorf("balance = %v, want 10", got)
+		}
+	}
+}
+
+func TestFailedTransferHidesTheRecipient(t *testing.T) {
+	db := testDB(t)
+	alice := seedUser(t, db, "alice")
+	bob := seedUser(t, db, "bob")
+	from := seedAccount(t, db, alice, 10, "USD")
+	to := seedAccount(t, db, bob, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{RecordFailures: true})
+
+	err := s.ProcessTransfer(&models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: 50}, claimsFor(alice))
+	wantAppError(t, err, 400)
+
+	var failed models.Transaction
+	if err := db.Where("status = ?", "failed").First(&failed).Error; err != nil {
+		t.Fatalf("no failed row: %v", err)
+	}
+	if failed.ToAccountID != nil || !strings.Contains(failed.FailureReason, "(to account") {
+		t.Errorf("failed transfer = %+v, want the destination only in the reason", failed)
+	}
+	var visible int64
+	db.Model(&models.Transaction{}).Where("to_account_id = ? OR from_account_id = ?", to.ID, to.ID).Count(&visible)
+	if visible != 0 {
+		t.Errorf("recipient sees %d transactions, want 0", visible)
+	}
+}
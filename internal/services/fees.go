@@ -0,0 +1,196 @@
+// Path: internal/services/fees.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Fee waiver reasons recorded on transactions.
+const (
+	FeeWaiverTier      = "tier"
+	FeeWaiverFreeQuota = "free_quota"
+	// FeeWaiverOwnAccounts marks transfers between accounts of the same user on the fast path.
+	FeeWaiverOwnAccounts = "own_accounts"
+)
+
+// FeeConfig describes transfer fees and the rules that waive them.
+type FeeConfig struct {
+	// Flat is charged on every transfer.
+	Flat float64
+	// Percent of the amount is charged on top of Flat, e.g. 0.5 means 0.5%.
+	Percent float64
+	// FreeTransfersPerMonth waives the fee for the first N transfers of a user each calendar month.
+	FreeTransfersPerMonth int
+	// WaivedTiers lists user tiers that never pay transfer fees.
+	WaivedTiers []string
+	// Accounts maps a currency to the account collecting the fees charged in it. A transfer charging
+	// a fee in a currency without one fails, so a fee is never debited without being credited.
+	Accounts map[string]int
+}
+
+// ParseFeeAccounts parses the accounts collecting fees per currency in the form "USD:1,EUR:2".
+func ParseFeeAccounts(s string) (map[string]int, error) {
+	accounts := make(map[string]int)
+	if strings.TrimSpace(s) == "" {
+		return accounts, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		currency, value, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid fee account %q: expected CURRENCY:ACCOUNT_ID", pair)
+		}
+		id, err := strconv.Atoi(value)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("invalid fee account %q: account ID must be a positive integer", pair)
+		}
+		accounts[strings.ToUpper(strings.TrimSpace(currency))] = id
+	}
+	return accounts, nil
+}
+
+// FeeQuote is the result of evaluating the fee rules for a transfer.
+type FeeQuote struct {
+	Fee          float64 // Charged fee, zero when waived
+	Waived       bool
+	WaiverReason string
+}
+
+// feeWaiverRule returns a non-empty reason when the fee should be waived.
+type feeWaiverRule func(tx *gorm.DB, userID uint, now time.Time) (string, error)
+
+type feeEngine struct {
+	cfg   FeeConfig
+	rules []feeWaiverRule
+}
+
+func newFeeEngine(cfg FeeConfig) *feeEngine {
+	e := &feeEngine{cfg: cfg}
+	// Rules are evaluated in order; the first matching one wins.
+	e.rules = []feeWaiverRule{e.tierRule, e.freeQuotaRule}
+	return e
+}
+
+// Quote computes the fee of a transfer in a currency with the given decimal places and applies
+// the waiver rules.
+func (e *feeEngine) Quote(tx *gorm.DB, userID uint, amount float64, decimals int, now time.Time) (FeeQuote, error) {
+	fee := e.fee(amount, decimals)
+	if fee <= 0 {
+		return FeeQuote{}, nil
+	}
+
+	for _, rule := range e.rules {
+		reason, err := rule(tx, userID, now)
+		if err != nil {
+			return FeeQuote{}, err
+		}
+		if reason != "" {
+			return FeeQuote{Waived: true, WaiverReason: reason}, nil
+		}
+	}
+
+	return FeeQuote{Fee: fee}, nil
+}
+
+// fee is the fee of an amount before the waiver rules, rounded to the currency's decimal places.
+func (e *feeEngine) fee(amount float64, decimals int) float64 {
+	return roundAmount(e.cfg.Flat+amount*e.cfg.Percent/100, decimals)
+}
+
+func (e *feeEngine) tierRule(tx *gorm.DB, userID uint, _ time.Time) (string, error) {
+	if len(e.cfg.WaivedTiers) == 0 {
+		return "", nil
+	}
+
+	var user models.User
+	if err := tx.Select("tier").Where("id = ?", userID).First(&user).Error; err != nil {
+		return "", &AppError{Code: 500, Message: "Failed to query user tier", Details: err.Error(), Err: err}
+	}
+	for _, tier := range e.cfg.WaivedTiers {
+		if user.Tier == tier {
+			return FeeWaiverTier, nil
+		}
+	}
+
+	return "", nil
+}
+
+func (e *feeEngine) freeQuotaRule(tx *gorm.DB, userID uint, now time.Time) (string, error) {
+	if e.cfg.FreeTransfersPerMonth <= 0 {
+		return "", nil
+	}
+
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	var count int64
+	err := tx.Model(&models.Transaction{}).
+		Where("initiator_id = ? AND type = ? AND status NOT IN ? AND created_at >= ?", userID, "transfer", []string{"rejected", "cancelled", "declined", "failed"}, monthStart).
+		Count(&count).Error
+	if err != nil {
+		return "", &AppError{Code: 500, Message: "Failed to count monthly transfers", Details: err.Error(), Err: err}
+	}
+	if count < int64(e.cfg.FreeTransfersPerMonth) {
+		return FeeWaiverFreeQuota, nil
+	}
+
+	return "", nil
+}
+
+// feeAccountID returns the account collecting the fees charged on account. Sandbox money isn't
+// real, so sandbox fees are debited without being collected anywhere.
+func (s *transactionService) feeAccountID(account *models.Account) (int, bool, error) {
+	if account.Sandbox {
+		return 0, false, nil
+	}
+	id, ok := s.cfg.Fees.Accounts[account.Currency]
+	if !ok {
+		return 0, false, &AppError{Code: 500, Message: "Fee account not configured", Details: fmt.Sprintf("No account collects the fees charged in %s", account.Currency)}
+	}
+	return id, true, nil
+}
+
+// creditFee credits the fee of a transfer from fromAccount to the fee account of its currency,
+// which may be either side of the transfer itself.
+func (s *transactionService) creditFee(tx *gorm.DB, fromAccount, toAccount *models.Account, fee float64) error {
+	if fee <= 0 {
+		return nil
+	}
+	feeID, ok, err := s.feeAccountID(fromAccount)
+	if err != nil || !ok {
+		return err
+	}
+
+	var feeAccount *models.Account
+	switch feeID {
+	case fromAccount.ID:
+		feeAccount = fromAccount
+	case toAccount.ID:
+		feeAccount = toAccount
+	default:
+		feeAccount = &models.Account{}
+		if err := tx.Where("id = ? AND sandbox = ?", feeID, false).First(feeAccount).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return &AppError{Code: 500, Message: "Fee account not found", Details: fmt.Sprintf("account_id: %d", feeID)}
+			}
+			return &AppError{Code: 500, Message: "Failed to query fee account", Details: err.Error(), Err: err}
+		}
+		if feeAccount.BalanceHash != balanceHash(feeAccount, s.secretKey) {
+			return &AppError{Code: 500, Message: "Fee account balance integrity check failed", Details: fmt.Sprintf("account_id: %d", feeID), Err: s.integrityFailed(feeID)}
+		}
+	}
+	if feeAccount.Currency != fromAccount.Currency {
+		return &AppError{Code: 500, Message: "Fee account currency mismatch", Details: fmt.Sprintf("Fee account %d is in %s, fees are charged in %s", feeID, feeAccount.Currency, fromAccount.Currency)}
+	}
+
+	feeAccount.Balance = s.roundAmount(feeAccount.Balance+fee, feeAccount.Currency)
+	feeAccount.BalanceHash = balanceHash(feeAccount, s.secretKey)
+	if err := tx.Save(feeAccount).Error; err != nil {
+		return &AppError{Code: 500, Message: "Failed to update fee account balance", Details: err.Error(), Err: err}
+	}
+	return nil
+}
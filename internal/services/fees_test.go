@@ -0,0 +1,125 @@
+// Path: internal/services/fees_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"reflect"
+	"testing"
+)
+
+func TestTransferFeeFreeQuota(t *testing.T) {
+	db := testDB(t)
+	alice, bob := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+	from, to := seedAccount(t, db, alice, 100, "USD"), seedAccount(t, db, bob, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{Fees: FeeConfig{Flat: 1, FreeTransfersPerMonth: 2, Accounts: seedFeeAccounts(t, db, "USD")}})
+
+	for i, want := range []struct {
+		fee    float64
+		waived bool
+	}{{0, true}, {0, true}, {1, false}} {
+		req := &models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: 10, Description: string(rune('a' + i))}
+		if err := s.ProcessTransfer(req, claimsFor(alice)); err != nil {
+			t.Fatalf("transfer %d: %v", i+1, err)
+		}
+		if req.Fee != want.fee || req.FeeWaived != want.waived {
+			t.Errorf("transfer %d: fee %v, waived %v; want %v, %v", i+1, req.Fee, req.FeeWaived, want.fee, want.waived)
+		}
+	}
+
+	var waived []models.Transaction
+	if err := db.Where("fee_waiver = ?", FeeWaiverFreeQuota).Find(&waived).Error; err != nil {
+		t.Fatalf("load transactions: %v", err)
+	}
+	if len(waived) != 2 {
+		t.Errorf("%d transfers recorded as free quota, want 2", len(waived))
+	}
+	if got := reloadAccount(t, db, from.ID).Balance; got != 69 {
+		t.Errorf("sender balance = %v, want 69 (three transfers of 10, one fee of 1)", got)
+	}
+}
+
+func TestTransferFeeTierWaiver(t *testing.T) {
+	db := testDB(t)
+	premium, standard, bob := seedUser(t, db, "premium"), seedUser(t, db, "standard"), seedUser(t, db, "bob")
+	if err := db.Model(premium).Update("tier", models.TierPremium).Error; err != nil {
+		t.Fatalf("set tier: %v", err)
+	}
+	to := seedAccount(t, db, bob, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{Fees: FeeConfig{Flat: 1, WaivedTiers: []string{models.TierPremium}, Accounts: seedFeeAccounts(t, db, "USD")}})
+
+	req := &models.TransferRequest{FromID: seedAccount(t, db, premium, 100, "USD").ID, ToID: to.ID, Amount: 10}
+	if err := s.ProcessTransfer(req, claimsFor(premium)); err != nil {
+		t.Fatalf("premium transfer: %v", err)
+	}
+	if req.Fee != 0 || !req.FeeWaived {
+		t.Errorf("premium transfer: fee %v, waived %v; want it waived", req.Fee, req.FeeWaived)
+	}
+
+	req = &models.TransferRequest{FromID: seedAccount(t, db, standard, 100, "USD").ID, ToID: to.ID, Amount: 10}
+	if err := s.ProcessTransfer(req, claimsFor(standard)); err != nil {
+		t.Fatalf("standard transfer: %v", err)
+	}
+	if req.Fee != 1 || req.FeeWaived {
+		t.Errorf("standard transfer: fee %v, waived %v; want 1 charged", req.Fee, req.FeeWaived)
+	}
+}
+
+func TestParseFeeAccounts(t *testing.T) {
+	accounts, err := ParseFeeAccounts(" usd:1, EUR:22 ")
+	if want := map[string]int{"USD": 1, "EUR": 22}; err != nil || !reflect.DeepEqual(accounts, want) {
+		t.Errorf("ParseFeeAccounts = %v, %v; want %v", accounts, err, want)
+	}
+	for _, invalid := range []string{"USD", "USD:0", "USD:x", "USD:-3"} {
+		if _, err := ParseFeeAccounts(invalid); err == nil {
+			t.Errorf("ParseFeeAccounts(%q) succeeded", invalid)
+		}
+	}
+}
+
+func TestFeeAccountID(t *testing.T) {
+	s := NewTransactionService(nil, testSecret, TransactionConfig{Fees: FeeConfig{Flat: 1, Accounts: map[string]int{"USD": 7}}}).(*transactionService)
+
+	if id, ok, err := s.feeAccountID(&models.Account{Currency: "USD"}); id != 7 || !ok || err != nil {
+		t.Errorf("USD fee account = %d, %t, %v; want 7", id, ok, err)
+	}
+	if _, ok, err := s.feeAccountID(&models.Account{Currency: "USD", Sandbox: true}); ok || err != nil {
+		t.Errorf("sandbox fee account = %t, %v; want none collected", ok, err)
+	}
+	_, _, err := s.feeAccountID(&models.Account{Currency: "EUR"})
+	if appErr := wantAppError(t, err, 500); appErr.Message != "Fee account not configured" {
+		t.Errorf("message = %q", appErr.Message)
+	}
+}
+
+func TestTransferFeeCreditedToFeeAccount(t *testing.T) {
+	db := testDB(t)
+	alice, bob := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+	from, to := seedAccount(t, db, alice, 100, "USD"), seedAccount(t, db, bob, 0, "USD")
+	euros := seedAccount(t, db, alice, 100, "EUR")
+	feeAccounts := seedFeeAccounts(t, db, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{Fees: FeeConfig{Flat: 1, Percent: 1, Accounts: feeAccounts}})
+
+	if err := s.ProcessTransfer(&models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: 50}, claimsFor(alice)); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	got := []float64{reloadAccount(t, db, from.ID).Balance, reloadAccount(t, db, to.ID).Balance, reloadAccount(t, db, feeAccounts["USD"]).Balance}
+	if want := []float64{48.5, 50, 1.5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("sender, recipient and fee account balances = %v, want %v", got, want)
+	}
+	if sum := got[0] + got[1] + got[2]; sum != 100 {
+		t.Errorf("balances sum to %v, want the 100 there was", sum)
+	}
+	for _, id := range []int{from.ID, to.ID, feeAccounts["USD"]} {
+		if account := reloadAccount(t, db, id); account.BalanceHash != balanceHash(account, testSecret) {
+			t.Errorf("account %d balance hash doesn't match", id)
+		}
+	}
+
+	// No account collects euro fees: the transfer fails rather than debit a fee into nothing.
+	eurTo := seedAccount(t, db, bob, 0, "EUR")
+	err := s.ProcessTransfer(&models.TransferRequest{FromID: euros.ID, ToID: eurTo.ID, Amount: 50}, claimsFor(alice))
+	wantAppError(t, err, 500)
+	if got := reloadAccount(t, db, euros.ID).Balance; got != 100 {
+		t.Errorf("euro balance = %v, want 100 untouched", got)
+	}
+}
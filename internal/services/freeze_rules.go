@@ -0,0 +1,169 @@
+// Path: internal/services/freeze_rules.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Freeze rules.
+const (
+	FreezeRuleIntegrity        = "integrity_failures"
+	FreezeRuleLargeWithdrawals = "large_withdrawals"
+)
+
+// FreezeRuleConfig configures the rules that freeze an account on suspicious activity.
+// A rule with a zero count is disabled.
+type FreezeRuleConfig struct {
+	// IntegrityFailures freezes an account after this many failed balance integrity checks within Window.
+	IntegrityFailures int
+	// LargeWithdrawals freezes an account after this many withdrawals of at least
+	// LargeWithdrawalAmount within Window.
+	LargeWithdrawals      int
+	LargeWithdrawalAmount float64
+	// Window is the rolling window the rules count events in. Defaults to one hour.
+	Window time.Duration
+}
+
+// integrityFailure is the cause of a failed balance integrity check, naming the account.
+type integrityFailure struct {
+	AccountID int
+}
+
+func (e *integrityFailure) Error() string {
+	return fmt.Sprintf("balance hash mismatch on account %d", e.AccountID)
+}
+
+// applyFreezeRules runs the freeze rules after a money movement from or to accountID ended with
+// err (nil on success). It runs outside the movement's transaction, so the events it records and
+// the freeze stick even when the movement rolled back. Rule errors are logged, never returned.
+func (s *transactionService) applyFreezeRules(err error, txType string, accountID int) {
+	rules := s.cfg.FreezeRules
+
+	var appErr *AppError
+	var failure *integrityFailure
+	if rules.IntegrityFailures > 0 && errors.As(err, &appErr) && errors.As(appErr.Err, &failure) {
+		event := models.SuspiciousActivity{AccountID: failure.AccountID, Rule: FreezeRuleIntegrity, CreatedAt: s.clock.Now()}
+		if err := s.db.Create(&event).Error; err != nil {
+			log.Printf("Failed to record integrity failure on account %d: %v", failure.AccountID, err)
+			return
+		}
+
+		var count int64
+		err := s.db.Model(&models.SuspiciousActivity{}).
+			Where("account_id = ? AND rule = ? AND created_at >= ?", failure.AccountID, FreezeRuleIntegrity, s.clock.Now().Add(-rules.Window)).
+			Count(&count).Error
+		if err != nil {
+			log.Printf("Failed to count integrity failures on account %d: %v", failure.AccountID, err)
+			return
+		}
+		if count >= int64(rules.IntegrityFailures) {
+			s.freezeAccount(failure.AccountID, FreezeRuleIntegrity, fmt.Sprintf("%d failed balance integrity checks within %s", count, rules.Window))
+		}
+		return
+	}
+
+	if err == nil && txType == "withdraw" && rules.LargeWithdrawals > 0 {
+		var count int64
+		err := s.db.Model(&models.Transaction{}).
+			Where("from_account_id = ? AND type = ? AND status = ? AND amount >= ? AND created_at >= ?",
+				accountID, "withdraw", "completed", rules.LargeWithdrawalAmount, s.clock.Now().Add(-rules.Window)).
+			Count(&count).Error
+		if err != nil {
+			log.Printf("Failed to count large withdrawals on account %d: %v", accountID, err)
+			return
+		}
+		if count >= int64(rules.LargeWithdrawals) {
+			s.freezeAccount(accountID, FreezeRuleLargeWithdrawals, fmt.Sprintf("%d withdrawals of at least %.2f within %s", count, rules.LargeWithdrawalAmount, rules.Window))
+		}
+	}
+}
+
+// freezeAccount blocks outgoing payments from the account, records the trigger in the audit
+// trail and notifies the owner. An account that is already frozen is left as it is.
+func (s *transactionService) freezeAccount(accountID int, rule, reason string) {
+	var account models.Account
+	if err := s.db.Select("id", "user_id").First(&account, accountID).Error; err != nil {
+		log.Printf("Failed to query account %d to freeze: %v", accountID, err)
+		return
+	}
+
+	result := s.db.Model(&models.Account{}).Where("id = ? AND NOT frozen", accountID).
+		Updates(map[string]interface{}{"frozen": true, "frozen_reason": reason})
+	if result.Error != nil {
+		log.Printf("Failed to freeze account %d: %v", accountID, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		return
+	}
+
+	log.Printf("Account %d frozen by rule %s: %s", accountID, rule, reason)
+	entry := models.AuditLog{
+		Action:    "account_frozen",
+		Severity:  models.SeverityCritical,
+		Details:   fmt.Sprintf("account_id: %d, rule: %s, reason: %s", accountID, rule, reason),
+		CreatedAt: s.clock.Now(),
+	}
+	if err := s.db.Create(&entry).Error; err != nil {
+		log.Printf("Failed to audit freeze of account %d: %v", accountID, err)
+	}
+
+	subject := fmt.Sprintf("Account %d frozen: %s. Contact support to unfreeze it.", accountID, reason)
+	if err := s.cfg.Notifier.Notify(uint(account.UserID), subject, "", nil); err != nil {
+		log.Printf("Failed to notify user %d of frozen account %d: %v", account.UserID, accountID, err)
+	}
+}
+
+// checkNotFrozen rejects outgoing payments from a frozen account.
+func checkNotFrozen(account *models.Account) error {
+	if account.Frozen {
+		return &AppError{Code: 403, Message: "Account frozen", Details: fmt.Sprintf("account_id: %d, reason: %s", account.ID, account.FrozenReason)}
+	}
+	return nil
+}
+
+// Unfreeze lifts a freeze from an account and records it in the audit trail.
+func (s *accountService) Unfreeze(actorID uint, accountID int) (*models.Account, error) {
+	var account models.Account
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&account, accountID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return &AppError{Code: 404, Message: "Account not found", Details: fmt.Sprintf("account_id: %d", accountID)}
+			}
+			return &AppError{Code: 500, Message: "Failed to query account", Details: err.Error(), Err: err}
+		}
+		if !account.Frozen {
+			return &AppError{Code: 409, Message: "Account is not frozen", Details: fmt.Sprintf("account_id: %d", accountID)}
+		}
+
+		err := tx.Model(&account).Updates(map[string]interface{}{"frozen": false, "frozen_reason": ""}).Error
+		if err != nil {
+			return &AppError{Code: 500, Message: "Failed to unfreeze account", Details: err.Error(), Err: err}
+		}
+
+		actor := int(actorID)
+		entry := models.AuditLog{
+			UserID:    &actor,
+			Action:    "account_unfrozen",
+			Severity:  models.SeverityWarning,
+			Details:   fmt.Sprintf("account_id: %d", accountID),
+			CreatedAt: s.cfg.Clock.Now(),
+		}
+		if err := tx.Create(&entry).Error; err != nil {
+			return &AppError{Code: 500, Message: "Failed to write audit log", Details: err.Error(), Err: err}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	account.Frozen, account.FrozenReason = false, ""
+	return &account, nil
+}
@@ -0,0 +1,93 @@
+// Path: internal/services/freeze_rules_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"strings"
+	"testing"
+)
+
+func TestCheckNotFrozen(t *testing.T) {
+	if err := checkNotFrozen(&models.Account{ID: 1}); err != nil {
+		t.Errorf("active account: %v", err)
+	}
+	err := checkNotFrozen(&models.Account{ID: 1, Frozen: true, FrozenReason: "too many withdrawals"})
+	if appErr := wantAppError(t, err, 403); !strings.Contains(appErr.Details, "too many withdrawals") {
+		t.Errorf("details = %q, want the reason", appErr.Details)
+	}
+}
+
+func TestDisabledFreezeRulesDoNothing(t *testing.T) {
+	s := NewTransactionService(nil, testSecret, TransactionConfig{}).(*transactionService)
+	// With every rule off nothing reaches the (nil) database.
+	s.applyFreezeRules(nil, "withdraw", 1)
+	s.applyFreezeRules(&AppError{Code: 500, Message: "Balance integrity check failed", Err: &integrityFailure{AccountID: 1}}, "withdraw", 1)
+}
+
+func TestLargeWithdrawalsFreezeTheAccount(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "hasty")
+	account := seedAccount(t, db, user, 1000, "USD")
+	notifier := &recordingNotifier{}
+	s := NewTransactionService(db, testSecret, TransactionConfig{
+		FreezeRules: FreezeRuleConfig{LargeWithdrawals: 2, LargeWithdrawalAmount: 100},
+		Notifier:    notifier,
+	})
+	withdraw := func(amount float64) error {
+		_, err := s.ProcessWithdraw(&models.TransactionRequest{AccountID: account.ID, Amount: amount}, claimsFor(user))
+		return err
+	}
+
+	for _, amount := range []float64{150, 20, 100} {
+		if err := withdraw(amount); err != nil {
+			t.Fatalf("withdraw %v: %v", amount, err)
+		}
+	}
+	stored := reloadAccount(t, db, account.ID)
+	if !stored.Frozen || !strings.Contains(stored.FrozenReason, "2 withdrawals") {
+		t.Fatalf("account = frozen %t (%q), want frozen by the second large withdrawal", stored.Frozen, stored.FrozenReason)
+	}
+	if len(notifier.sent) != 1 || notifier.sent[0].userID != uint(user.ID) {
+		t.Errorf("notifications = %+v, want one to the owner", notifier.sent)
+	}
+	var audited int64
+	db.Model(&models.AuditLog{}).Where("action = ?", "account_frozen").Count(&audited)
+	if audited != 1 {
+		t.Errorf("%d freeze audit entries, want 1", audited)
+	}
+
+	// Small withdrawals are blocked too; deposits still arrive.
+	if appErr := wantAppError(t, withdraw(5), 403); appErr.Message != "Account frozen" {
+		t.Errorf("withdrawal from a frozen account: %q", appErr.Message)
+	}
+	if _, err := s.ProcessDeposit(&models.TransactionRequest{AccountID: account.ID, Amount: 5}, claimsFor(user)); err != nil {
+		t.Errorf("deposit into a frozen account: %v", err)
+	}
+
+	admin := seedAdmin(t, db, "admin")
+	if _, err := NewAccountService(db, testSecret, AccountConfig{}).Unfreeze(uint(admin.ID), account.ID); err != nil {
+		t.Fatalf("Unfreeze: %v", err)
+	}
+	if err := withdraw(5); err != nil {
+		t.Errorf("withdrawal after unfreezing: %v", err)
+	}
+}
+
+func TestIntegrityFailuresFreezeTheAccount(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "tampered")
+	account := seedAccount(t, db, user, 100, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{FreezeRules: FreezeRuleConfig{IntegrityFailures: 2}})
+	if err := db.Model(&models.Account{}).Where("id = ?", account.ID).Update("balance", 1e6).Error; err != nil {
+		t.Fatalf("tamper: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := s.ProcessWithdraw(&models.TransactionRequest{AccountID: account.ID, Amount: 10}, claimsFor(user)); err == nil {
+			t.Fatal("withdrawal from a tampered account succeeded")
+		}
+		if frozen := reloadAccount(t, db, account.ID).Frozen; frozen != (i == 1) {
+			t.Errorf("after %d failed checks frozen = %t", i+1, frozen)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Path: internal/services/fx.go
+package services
+
+import (
+	"bank-api/pkg/utils"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCurrency is the currency of accounts that don't specify one.
+const DefaultCurrency = "USD"
+
+// ErrUnsupportedCurrencyPair is returned when no rate is known for a currency pair.
+var ErrUnsupportedCurrencyPair = errors.New("unsupported currency pair")
+
+// ExchangeRateProvider supplies conversion rates between currencies.
+type ExchangeRateProvider interface {
+	// Rate returns how many units of `to` one unit of `from` is worth.
+	Rate(from, to string) (float64, error)
+}
+
+type staticRateProvider struct {
+	// rates holds the value of one unit of each currency in the base currency.
+	rates map[string]float64
+}
+
+// NewStaticRateProvider creates a provider from fixed rates relative to a base currency.
+// The base currency itself always has rate 1.
+func NewStaticRateProvider(base string, rates map[string]float64) ExchangeRateProvider {
+	all := map[string]float64{strings.ToUpper(base): 1}
+	for currency, rate := range rates {
+		all[strings.ToUpper(currency)] = rate
+	}
+	return &staticRateProvider{rates: all}
+}
+
+// ParseRates parses rates in the "EUR:1.08,GBP:1.27" format.
+func ParseRates(s string) (map[string]float64, error) {
+	rates := make(map[string]float64)
+	if strings.TrimSpace(s) == "" {
+		return rates, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		currency, value, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid rate %q: expected CURRENCY:RATE", pair)
+		}
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("invalid rate %q: rate must be a positive number", pair)
+		}
+		rates[strings.ToUpper(currency)] = rate
+	}
+	return rates, nil
+}
+
+func (p *staticRateProvider) Rate(from, to string) (float64, error) {
+	fromRate, ok := p.rates[strings.ToUpper(from)]
+	if !ok {
+		return 0, ErrUnsupportedCurrencyPair
+	}
+	toRate, ok := p.rates[strings.ToUpper(to)]
+	if !ok {
+		return 0, ErrUnsupportedCurrencyPair
+	}
+	return fromRate / toRate, nil
+}
+
+type cachedRate struct {
+	rate      float64
+	err       error
+	fetchedAt time.Time
+}
+
+type cachedRateProvider struct {
+	provider ExchangeRateProvider
+	ttl      time.Duration
+	clock    utils.Clock
+
+	mu    sync.Mutex
+	cache map[string]cachedRate
+}
+
+// NewCachedRateProvider wraps a provider so each pair is fetched at most once per ttl.
+// Unsupported pairs are cached too, so they don't hammer the provider either.
+func NewCachedRateProvider(provider ExchangeRateProvider, ttl time.Duration, clock utils.Clock) ExchangeRateProvider {
+	return &cachedRateProvider{
+		provider: provider,
+		ttl:      ttl,
+		clock:    clock,
+		cache:    make(map[string]cachedRate),
+	}
+}
+
+func (p *cachedRateProvider) Rate(from, to string) (float64, error) {
+	key := strings.ToUpper(from) + "/" + strings.ToUpper(to)
+	now := p.clock.Now()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if cached, ok := p.cache[key]; ok && now.Sub(cached.fetchedAt) < p.ttl {
+		return cached.rate, cached.err
+	}
+
+	rate, err := p.provider.Rate(from, to)
+	p.cache[key] = cachedRate{rate: rate, err: err, fetchedAt: now}
+	return rate, err
+}
@@ -0,0 +1,93 @@
+// Path: internal/services/fx_test.go
+package services
+
+import (
+	"bank-api/pkg/utils"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// countingRates counts the calls reaching a provider.
+type countingRates struct {
+	ExchangeRateProvider
+	calls int
+}
+
+func (p *countingRates) Rate(from, to string) (float64, error) {
+	p.calls++
+	return p.ExchangeRateProvider.Rate(from, to)
+}
+
+func TestCachedRateProviderFetchesOncePerTTL(t *testing.T) {
+	upstream := &countingRates{ExchangeRateProvider: NewStaticRateProvider("USD", map[string]float64{"EUR": 1.1})}
+	clock := utils.NewManualClock(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	rates := NewCachedRateProvider(upstream, time.Minute, clock)
+
+	for i := 0; i < 3; i++ {
+		if rate, err := rates.Rate("EUR", "USD"); err != nil || rate != 1.1 {
+			t.Fatalf("Rate(EUR, USD) = %v, %v", rate, err)
+		}
+		clock.Advance(10 * time.Second)
+	}
+	if upstream.calls != 1 {
+		t.Errorf("provider called %d times within the TTL, want 1", upstream.calls)
+	}
+
+	if _, err := rates.Rate("usd", "eur"); err != nil || upstream.calls != 2 {
+		t.Errorf("another pair: %v, %d calls; want a second fetch", err, upstream.calls)
+	}
+
+	clock.Advance(time.Minute)
+	if _, err := rates.Rate("EUR", "USD"); err != nil || upstream.calls != 3 {
+		t.Errorf("after the TTL: %v, %d calls; want a refetch", err, upstream.calls)
+	}
+}
+
+func TestCachedRateProviderCachesUnsupportedPairs(t *testing.T) {
+	upstream := &countingRates{ExchangeRateProvider: NewStaticRateProvider("USD", nil)}
+	rates := NewCachedRateProvider(upstream, time.Minute, utils.NewManualClock(time.Now()))
+
+	for i := 0; i < 2; i++ {
+		if _, err := rates.Rate("USD", "XYZ"); !errors.Is(err, ErrUnsupportedCurrencyPair) {
+			t.Fatalf("Rate(USD, XYZ) = %v, want ErrUnsupportedCurrencyPair", err)
+		}
+	}
+	if upstream.calls != 1 {
+		t.Errorf("provider called %d times, want 1", upstream.calls)
+	}
+}
+
+func TestGetQuoteAppliesSpread(t *testing.T) {
+	s := NewRateService(NewStaticRateProvider("USD", map[string]float64{"EUR": 1.25}), 2)
+
+	quote, err := s.GetQuote("eur", "usd")
+	if err != nil {
+		t.Fatalf("GetQuote: %v", err)
+	}
+	if quote.From != "EUR" || quote.To != "USD" || quote.Rate != 1.25 || math.Abs(quote.ClientRate-1.225) > 1e-9 {
+		t.Errorf("quote = %+v", quote)
+	}
+
+	if quote, err := s.GetQuote("USD", "USD"); err != nil || quote.ClientRate != 1 {
+		t.Errorf("same currency quote = %+v, %v; want no spread", quote, err)
+	}
+
+	_, err = s.GetQuote("USD", "JPY")
+	wantAppError(t, err, 404)
+	_, err = s.GetQuote("USD", "")
+	wantAppError(t, err, 400)
+}
+
+func TestParseRates(t *testing.T) {
+	rates, err := ParseRates(" eur:1.08, GBP:1.27 ")
+	if err != nil || rates["EUR"] != 1.08 || rates["GBP"] != 1.27 || len(rates) != 2 {
+		t.Errorf("ParseRates = %v, %v", rates, err)
+	}
+	for _, bad := range []string{"EUR", "EUR:x", "EUR:0", "EUR:-1"} {
+		if _, err := ParseRates(bad); err == nil {
+			t.Errorf("ParseRates(%q) accepted", bad)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+// Path: internal/services/helpers_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"bytes"
+	"errors"
+	"log"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// wantAppError fails the test unless err is an AppError with the given status code.
+func wantAppError(t *testing.T, err error, code int) *AppError {
+	t.Helper()
+	var appErr *AppError
+	if !errors.As(err, &appErr) {
+		t.Fatalf("error = %v, want an AppError %d", err, code)
+	}
+	if appErr.Code != code {
+		t.Fatalf("error = %v, want code %d", appErr, code)
+	}
+	return appErr
+}
+
+// wantMaskedInLog logs message as the error handler does, through MaskingWriter, and fails the
+// test if the account number shows in full or its last 4 characters are missing.
+func wantMaskedInLog(t *testing.T, message interface{}, number string) {
+	t.Helper()
+	var buf bytes.Buffer
+	log.New(utils.MaskingWriter{W: &buf}, "", 0).Printf("Error: %v", message)
+	if got := buf.String(); strings.Contains(got, number) || !strings.Contains(got, "*"+number[len(number)-4:]) {
+		t.Errorf("log %q, want %s masked", got, number)
+	}
+}
+
+// memAccounts is an in-memory AccountRepository for services that read accounts through one.
+type memAccounts struct {
+	accounts map[int]models.Account
+}
+
+// newMemAccounts stores the accounts, each signed with testSecret.
+func newMemAccounts(accounts ...models.Account) *memAccounts {
+	r := &memAccounts{accounts: make(map[int]models.Account)}
+	for _, account := range accounts {
+		account.BalanceHash = balanceHash(&account, testSecret)
+		r.accounts[account.ID] = account
+	}
+	return r
+}
+
+func (r *memAccounts) ListByUser(userID uint, sandbox bool) ([]models.Account, error) {
+	var accounts []models.Account
+	for _, account := range r.accounts {
+		if account.UserID == int(userID) && account.Sandbox == sandbox {
+			accounts = append(accounts, account)
+		}
+	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].ID < accounts[j].ID })
+	return accounts, nil
+}
+
+func (r *memAccounts) FindOwned(userID uint, accountID int, sandbox bool) (*models.Account, error) {
+	account, ok := r.accounts[accountID]
+	if !ok || account.UserID != int(userID) || account.Sandbox != sandbox {
+		return nil, ErrNotFound
+	}
+	return &account, nil
+}
+
+func (r *memAccounts) Find(accountID int) (*models.Account, error) {
+	account, ok := r.accounts[accountID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &account, nil
+}
+
+func (r *memAccounts) UpdateNickname(accountID int, nickname string) error {
+	account := r.accounts[accountID]
+	account.Nickname = nickname
+	r.accounts[accountID] = account
+	return nil
+}
+
+// memUsers is an in-memory UserRepository.
+type memUsers map[uint]models.User
+
+func (r memUsers) Find(userID uint) (*models.User, error) {
+	user, ok := r[userID]
+	if !ok || user.DeletedAt != nil {
+		return nil, ErrNotFound
+	}
+	return &user, nil
+}
+
+func (r memUsers) FindByUsername(username string) (*models.User, error) {
+	for _, user := range r {
+		if user.Username == username && user.DeletedAt == nil {
+			return &user, nil
+		}
+	}
+	return nil, ErrNotFound
+}
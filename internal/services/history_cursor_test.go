@@ -0,0 +1,164 @@
+// Path: internal/services/history_cursor_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestHistoryCursorRoundTrip(t *testing.T) {
+	s := NewTransactionService(nil, testSecret, TransactionConfig{}).(*transactionService)
+	createdAt := time.Date(2024, 5, 1, 10, 0, 0, 123456000, time.UTC)
+
+	gotAt, gotID, err := s.decodeHistoryCursor(s.encodeHistoryCursor(createdAt, "tx-42"))
+	if err != nil || !gotAt.Equal(createdAt) || gotID != "tx-42" {
+		t.Errorf("decoded %v %q, %v; want %v tx-42", gotAt, gotID, err, createdAt)
+	}
+}
+
+func TestListTransactionsCapsOffsets(t *testing.T) {
+	s := NewTransactionService(nil, testSecret, TransactionConfig{})
+	_, _, err := s.ListTransactions(1, models.TransactionFilter{Offset: MaxHistoryOffset + 1})
+	wantAppError(t, err, 400)
+}
+
+func TestCursorPagesMatchOffsetPages(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "pager")
+	account := seedAccount(t, db, user, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{})
+	for i := 1; i <= 7; i++ {
+		if _, err := s.ProcessDeposit(&models.TransactionRequest{AccountID: account.ID, Amount: float64(i)}, claimsFor(user)); err != nil {
+			t.Fatalf("deposit: %v", err)
+		}
+	}
+
+	var queries []string
+	db.Callback().Query().After("gorm:query").Register("test:capture", func(tx *gorm.DB) {
+		queries = append(queries, tx.Statement.SQL.String())
+	})
+
+	var byOffset, byCursor []string
+	for offset := 0; offset < 9; offset += 3 {
+		page, _, err := s.ListTransactions(uint(user.ID), models.TransactionFilter{Limit: 3, Offset: offset})
+		if err != nil {
+			t.Fatalf("offset %d: %v", offset, err)
+		}
+		for _, tx := range page {
+			byOffset = append(byOffset, tx.ID)
+		}
+	}
+
+	queries = nil
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("cursor pagination never ended")
+		}
+		page, next, err := s.ListTransactions(uint(user.ID), models.TransactionFilter{Limit: 3, Cursor: cursor})
+		if err != nil {
+			t.Fatalf("cursor page %d: %v", pages, err)
+		}
+		for _, tx := range page {
+			byCursor = append(byCursor, tx.ID)
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	if len(byOffset) != 7 || !reflect.DeepEqual(byCursor, byOffset) {
+		t.Errorf("cursor pages %v, offset pages %v; want the same 7 transactions", byCursor, byOffset)
+	}
+	// Deep cursor pages seek by key instead of scanning past the earlier rows.
+	for _, query := range queries {
+		if strings.Contains(query, "transactions") && strings.Contains(strings.ToUpper(query), "OFFSET") {
+			t.Errorf("cursor page query skips rows: %s", query)
+		}
+	}
+}
+
+func TestHistoryCursorRejectsTampering(t *testing.T) {
+	s := NewTransactionService(nil, testSecret, TransactionConfig{}).(*transactionService)
+	other := NewTransactionService(nil, "another-balance-secret", TransactionConfig{}).(*transactionService)
+	createdAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	cursor := s.encodeHistoryCursor(createdAt, "tx-42")
+	payload, signature, _ := strings.Cut(cursor, ".")
+
+	// Moving the position while keeping the signature, as a client skipping ahead would.
+	forged := strings.Split(s.encodeHistoryCursor(createdAt.Add(-time.Hour), "tx-42"), ".")[0] + "." + signature
+	flipped := []byte(signature)
+	flipped[0] ^= 1
+	for name, bad := range map[string]string{
+		"moved position":     forged,
+		"altered signature":  payload + "." + string(flipped),
+		"unsigned":           payload,
+		"other secret":       other.encodeHistoryCursor(createdAt, "tx-42"),
+		"not a cursor":       "garbage",
+		"truncated payload":  payload[1:] + "." + signature,
+		"signature appended": cursor + "00",
+	} {
+		_, _, err := s.decodeHistoryCursor(bad)
+		if appErr := wantAppError(t, err, 400); appErr.Message != "Invalid cursor" {
+			t.Errorf("%s: %q, want Invalid cursor", name, appErr.Message)
+		}
+	}
+}
+
+func TestCursorPagesSurviveInserts(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "inserter")
+	account := seedAccount(t, db, user, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{})
+	deposit := func(amount float64) {
+		if _, err := s.ProcessDeposit(&models.TransactionRequest{AccountID: account.ID, Amount: amount}, claimsFor(user)); err != nil {
+			t.Fatalf("deposit: %v", err)
+		}
+	}
+	for i := 1; i <= 7; i++ {
+		deposit(float64(i))
+	}
+	all, _, err := s.ListTransactions(uint(user.ID), models.TransactionFilter{Limit: 10})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	var existing []string
+	for _, tx := range all {
+		existing = append(existing, tx.ID)
+	}
+
+	var seen []string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("cursor pagination never ended")
+		}
+		page, next, err := s.ListTransactions(uint(user.ID), models.TransactionFilter{Limit: 3, Cursor: cursor})
+		if err != nil {
+			t.Fatalf("page %d: %v", pages, err)
+		}
+		for _, tx := range page {
+			seen = append(seen, tx.ID)
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+		// New transactions arrive while the client pages back in time.
+		deposit(100)
+		deposit(200)
+	}
+
+	if len(existing) != 7 || !reflect.DeepEqual(seen, existing) {
+		t.Errorf("paged %v, want exactly the 7 earlier transactions %v", seen, existing)
+	}
+
+	_, _, err = s.ListTransactions(uint(user.ID), models.TransactionFilter{Limit: 3, Cursor: cursor[1:]})
+	wantAppError(t, err, 400)
+}
@@ -0,0 +1,231 @@
+// Path: internal/services/holds.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Hold statuses. Only active holds reserve funds.
+const (
+	HoldActive   = "active"
+	HoldCaptured = "captured"
+	HoldReleased = "released"
+)
+
+// PlaceHold reserves an amount of one of the user's accounts, like a card authorization: the
+// balance stays as it is, but the available balance drops until the hold is captured or released.
+// The hold passes the checks of a withdrawal, so its capture can't fail for lack of funds.
+func (s *transactionService) PlaceHold(accountID int, req *models.HoldRequest, claims *models.Claims) (*models.Hold, error) {
+	var v validation
+	v.check(req.Amount > 0, "amount", "Amount must be positive")
+	v.description("description", &req.Description)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	var hold models.Hold
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkTransfersEnabled(tx, claims.UserID); err != nil {
+			return err
+		}
+		account, err := s.loadOwnedAccount(tx, accountID, claims.UserID, claims.Sandbox)
+		if err != nil {
+			return err
+		}
+		if err := checkAmountPrecision(req.Amount, account.Currency, s.cfg.CurrencyDecimals); err != nil {
+			return err
+		}
+		if err := checkNotClosed(account); err != nil {
+			return err
+		}
+		if err := checkNotFrozen(account); err != nil {
+			return err
+		}
+		if err := checkNotDormant(account); err != nil {
+			return err
+		}
+		if err := s.checkAccountAge(account); err != nil {
+			return err
+		}
+		if err := s.checkSpendingLimit(tx, claims, account.Currency, req.Amount); err != nil {
+			return err
+		}
+
+		available, err := s.availableBalance(tx, account, "")
+		if err != nil {
+			return err
+		}
+		if err := s.policyFor(account).CheckDebit(tx, account, available, req.Amount, s.clock.Now()); err != nil {
+			return err
+		}
+
+		hold = models.Hold{
+			AccountID:   accountID,
+			Amount:      req.Amount,
+			Status:      HoldActive,
+			Description: req.Description,
+			CreatedAt:   s.clock.Now(),
+		}
+		if err := tx.Create(&hold).Error; err != nil {
+			return &AppError{Code: 500, Message: "Failed to place hold", Details: err.Error(), Err: err}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// CaptureHold turns an active hold into a withdrawal of up to the held amount and releases the
+// rest. The funds and the spending limit were checked when the hold was placed, so only a freeze
+// or an admin restriction on payments stops the capture.
+func (s *transactionService) CaptureHold(holdID int, req *models.CaptureRequest, claims *models.Claims) (*models.Hold, error) {
+	var hold *models.Hold
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkTransfersEnabled(tx, claims.UserID); err != nil {
+			return err
+		}
+		var err error
+		hold, err = s.loadActiveHold(tx, holdID, claims)
+		if err != nil {
+			return err
+		}
+
+		amount := req.Amount
+		if amount == 0 {
+			amount = hold.Amount
+		}
+		var v validation
+		v.check(amount > 0, "amount", "Amount must be positive")
+		v.check(amount <= hold.Amount, "amount", fmt.Sprintf("At most the held amount, %.2f, can be captured", hold.Amount))
+		if err := v.err(); err != nil {
+			return err
+		}
+
+		account, err := s.loadOwnedAccount(tx, hold.AccountID, claims.UserID, claims.Sandbox)
+		if err != nil {
+			return err
+		}
+		if err := checkAmountPrecision(amount, account.Currency, s.cfg.CurrencyDecimals); err != nil {
+			return err
+		}
+		if err := checkNotFrozen(account); err != nil {
+			return err
+		}
+
+		s.drawSubBalances(account, amount)
+		account.Balance = s.roundAmount(account.Balance-amount, account.Currency)
+		account.BalanceHash = balanceHash(account, s.secretKey)
+		now := s.clock.Now()
+		account.LastActivityAt = &now
+		if err := tx.Save(account).Error; err != nil {
+			return &AppError{Code: 500, Message: "Failed to update account balance", Details: err.Error(), Err: err}
+		}
+
+		initiatorID := int(claims.UserID)
+		transaction := models.Transaction{
+			ID:            utils.GenerateTransactionID(now),
+			FromAccountID: &account.ID,
+			Amount:        amount,
+			Type:          "withdraw",
+			Status:        "completed",
+			Description:   hold.Description,
+			InitiatorID:   &initiatorID,
+			CreatedAt:     now,
+		}
+		if err := tx.Create(&transaction).Error; err != nil {
+			return &AppError{Code: 500, Message: "Failed to insert transaction record", Details: err.Error(), Err: err}
+		}
+		if err := appendEvent(tx, EventWithdrawn, account, &transaction); err != nil {
+			return err
+		}
+
+		hold.Status, hold.CapturedAmount, hold.TransactionID, hold.ResolvedAt = HoldCaptured, amount, &transaction.ID, &now
+		if err := s.resolveHold(tx, hold); err != nil {
+			return err
+		}
+
+		return s.applyRoundUp(tx, claims.UserID, account, amount)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+// ReleaseHold cancels an active hold, making its funds available again.
+func (s *transactionService) ReleaseHold(holdID int, claims *models.Claims) (*models.Hold, error) {
+	var hold *models.Hold
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkTransfersEnabled(tx, claims.UserID); err != nil {
+			return err
+		}
+		var err error
+		hold, err = s.loadActiveHold(tx, holdID, claims)
+		if err != nil {
+			return err
+		}
+
+		now := s.clock.Now()
+		hold.Status, hold.ResolvedAt = HoldReleased, &now
+		return s.resolveHold(tx, hold)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+// GetHolds lists the active holds of one of the user's accounts, oldest first.
+func (s *transactionService) GetHolds(accountID int, claims *models.Claims) ([]models.Hold, error) {
+	if _, err := s.loadOwnedAccount(s.db, accountID, claims.UserID, claims.Sandbox); err != nil {
+		return nil, err
+	}
+
+	holds := []models.Hold{}
+	if err := s.db.Where("account_id = ? AND status = ?", accountID, HoldActive).Order("created_at, id").Find(&holds).Error; err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to query holds", Details: err.Error(), Err: err}
+	}
+	return holds, nil
+}
+
+// loadActiveHold fetches an active hold on an account of the user, in the mode of the token.
+func (s *transactionService) loadActiveHold(tx *gorm.DB, holdID int, claims *models.Claims) (*models.Hold, error) {
+	var hold models.Hold
+	err := tx.Where("id = ? AND account_id IN (?)", holdID, ownedAccountIDs(tx, claims.UserID).Where("sandbox = ?", claims.Sandbox)).
+		First(&hold).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &AppError{Code: 404, Message: "Hold not found", Details: fmt.Sprintf("hold_id: %d", holdID)}
+		}
+		return nil, &AppError{Code: 500, Message: "Failed to query hold", Details: err.Error(), Err: err}
+	}
+	if hold.Status != HoldActive {
+		return nil, &AppError{Code: 409, Message: "Hold is no longer active", Details: fmt.Sprintf("hold_id: %d, status: %s", holdID, hold.Status)}
+	}
+	return &hold, nil
+}
+
+// resolveHold records the capture or release of a hold. The update only applies to a hold still
+// active, so of two concurrent resolutions only one succeeds.
+func (s *transactionService) resolveHold(tx *gorm.DB, hold *models.Hold) error {
+	result := tx.Model(&models.Hold{}).Where("id = ? AND status = ?", hold.ID, HoldActive).Updates(map[string]interface{}{
+		"status":          hold.Status,
+		"captured_amount": hold.CapturedAmount,
+		"transaction_id":  hold.TransactionID,
+		"resolved_at":     hold.ResolvedAt,
+	})
+	if result.Error != nil {
+		return &AppError{Code: 500, Message: "Failed to update hold", Details: result.Error.Error(), Err: result.Error}
+	}
+	if result.RowsAffected == 0 {
+		return &AppError{Code: 409, Message: "Hold is no longer active", Details: fmt.Sprintf("hold_id: %d", hold.ID)}
+	}
+	return nil
+}
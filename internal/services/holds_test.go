@@ -0,0 +1,130 @@
+// Path: internal/services/holds_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"testing"
+)
+
+func TestPlaceHoldValidatesAmount(t *testing.T) {
+	s := NewTransactionService(nil, testSecret, TransactionConfig{})
+	for _, amount := range []float64{0, -5} {
+		_, err := s.PlaceHold(1, &models.HoldRequest{Amount: amount}, &models.Claims{UserID: 1})
+		if names := fieldNames(t, err); len(names) != 1 || names[0] != "amount" {
+			t.Errorf("amount %v: fields %v, want amount", amount, names)
+		}
+	}
+}
+
+func TestHoldReservesFunds(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "holder")
+	account := seedAccount(t, db, user, 100, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{})
+
+	hold, err := s.PlaceHold(account.ID, &models.HoldRequest{Amount: 60, Description: "hotel"}, claimsFor(user))
+	if err != nil {
+		t.Fatalf("place hold: %v", err)
+	}
+	if hold.ID == 0 || hold.Status != HoldActive {
+		t.Fatalf("hold = %+v, want an active hold with an ID", hold)
+	}
+	if got := reloadAccount(t, db, account.ID).Balance; got != 100 {
+		t.Errorf("balance after the hold = %v, want 100 (nothing moved)", got)
+	}
+
+	// The balance of 100 covers 50, but only 40 is available.
+	_, err = s.ProcessWithdraw(&models.TransactionRequest{AccountID: account.ID, Amount: 50}, claimsFor(user))
+	if appErr := wantAppError(t, err, 400); appErr.Message != "Insufficient funds" {
+		t.Errorf("message = %q, want Insufficient funds", appErr.Message)
+	}
+	if _, err := s.PlaceHold(account.ID, &models.HoldRequest{Amount: 50}, claimsFor(user)); err == nil {
+		t.Error("second hold beyond the available balance was placed")
+	}
+
+	holds, err := s.GetHolds(account.ID, claimsFor(user))
+	if err != nil || len(holds) != 1 || holds[0].ID != hold.ID {
+		t.Errorf("GetHolds = %+v, %v; want the one hold", holds, err)
+	}
+}
+
+func TestCaptureLessThanHeld(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "capturer")
+	account := seedAccount(t, db, user, 100, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{})
+
+	hold, err := s.PlaceHold(account.ID, &models.HoldRequest{Amount: 60}, claimsFor(user))
+	if err != nil {
+		t.Fatalf("place hold: %v", err)
+	}
+	_, err = s.CaptureHold(hold.ID, &models.CaptureRequest{Amount: 70}, claimsFor(user))
+	if names := fieldNames(t, err); len(names) != 1 || names[0] != "amount" {
+		t.Errorf("capture beyond the hold: fields %v, want amount", names)
+	}
+
+	captured, err := s.CaptureHold(hold.ID, &models.CaptureRequest{Amount: 25}, claimsFor(user))
+	if err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+	if captured.Status != HoldCaptured || captured.CapturedAmount != 25 || captured.TransactionID == nil {
+		t.Errorf("captured hold = %+v", captured)
+	}
+	stored := reloadAccount(t, db, account.ID)
+	if stored.Balance != 75 || stored.BalanceHash != balanceHash(stored, testSecret) {
+		t.Errorf("balance after capturing 25 = %v, want 75 with a matching hash", stored.Balance)
+	}
+	var withdrawal models.Transaction
+	if err := db.First(&withdrawal, "id = ?", *captured.TransactionID).Error; err != nil {
+		t.Fatalf("load withdrawal: %v", err)
+	}
+	if withdrawal.Type != "withdraw" || withdrawal.Amount != 25 {
+		t.Errorf("capture recorded %s of %v, want a withdrawal of 25", withdrawal.Type, withdrawal.Amount)
+	}
+
+	// The remaining 35 is released: the whole balance is available again.
+	balance, err := s.ProcessWithdraw(&models.TransactionRequest{AccountID: account.ID, Amount: 75}, claimsFor(user))
+	if err != nil {
+		t.Fatalf("withdraw the rest: %v", err)
+	}
+	if balance.AvailableBalance != 0 {
+		t.Errorf("available after withdrawing everything = %v", balance.AvailableBalance)
+	}
+
+	_, err = s.CaptureHold(hold.ID, &models.CaptureRequest{}, claimsFor(user))
+	wantAppError(t, err, 409)
+}
+
+func TestReleaseHold(t *testing.T) {
+	db := testDB(t)
+	user, other := seedUser(t, db, "releaser"), seedUser(t, db, "stranger")
+	account := seedAccount(t, db, user, 100, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{})
+
+	hold, err := s.PlaceHold(account.ID, &models.HoldRequest{Amount: 100}, claimsFor(user))
+	if err != nil {
+		t.Fatalf("place hold: %v", err)
+	}
+	_, err = s.ReleaseHold(hold.ID, claimsFor(other))
+	wantAppError(t, err, 404)
+
+	released, err := s.ReleaseHold(hold.ID, claimsFor(user))
+	if err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if released.Status != HoldReleased || released.ResolvedAt == nil {
+		t.Errorf("released hold = %+v", released)
+	}
+	if got := reloadAccount(t, db, account.ID).Balance; got != 100 {
+		t.Errorf("balance after the release = %v, want 100", got)
+	}
+	if holds, err := s.GetHolds(account.ID, claimsFor(user)); err != nil || len(holds) != 0 {
+		t.Errorf("GetHolds = %+v, %v; want none", holds, err)
+	}
+	if _, err := s.ProcessWithdraw(&models.TransactionRequest{AccountID: account.ID, Amount: 100}, claimsFor(user)); err != nil {
+		t.Errorf("withdraw the released funds: %v", err)
+	}
+
+	_, err = s.ReleaseHold(hold.ID, claimsFor(user))
+	wantAppError(t, err, 409)
+}
@@ -0,0 +1,57 @@
+// Path: internal/services/integrity.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/metrics"
+	"bank-api/pkg/utils"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// IntegrityAlerter is told when an account fails its balance integrity check, which means its
+// balance was changed outside the application. Implementations page an operator; they run in the
+// request that found the failure, so they must not block for long.
+type IntegrityAlerter interface {
+	IntegrityFailed(accountID int, details string)
+}
+
+// NoopIntegrityAlerter pages nobody; failures are still counted and audited. It is the default.
+type NoopIntegrityAlerter struct{}
+
+func (NoopIntegrityAlerter) IntegrityFailed(accountID int, details string) {}
+
+// balanceHash computes the balance hash of account: its balance and sub-balances signed with secretKey.
+func balanceHash(account *models.Account, secretKey string) string {
+	return utils.CalculateAccountHash(account.Balance, account.ID, account.SubBalances, secretKey)
+}
+
+// reportIntegrityFailure counts a failed balance integrity check, audits it as critical and raises
+// the alert. It writes through db rather than the caller's transaction, so the audit entry survives
+// the rollback of the operation that failed. Audit errors are logged, never returned.
+func reportIntegrityFailure(db *gorm.DB, alerter IntegrityAlerter, now time.Time, accountID int) {
+	metrics.BalanceIntegrityViolations.Add(1)
+
+	details := fmt.Sprintf("account_id: %d", accountID)
+	entry := models.AuditLog{
+		Action:    "balance_integrity_failure",
+		Severity:  models.SeverityCritical,
+		Details:   details,
+		CreatedAt: now,
+	}
+	if err := db.Session(&gorm.Session{NewDB: true}).Create(&entry).Error; err != nil {
+		log.Printf("Failed to audit integrity failure on account %d: %v", accountID, err)
+	}
+
+	alerter.IntegrityFailed(accountID, details)
+}
+
+// integrityFailed reports a failed balance integrity check and returns the error cause naming the
+// account, which the freeze rules count.
+func (s *transactionService) integrityFailed(accountID int) *integrityFailure {
+	reportIntegrityFailure(s.db, s.cfg.IntegrityAlerter, s.clock.Now(), accountID)
+	return &integrityFailure{AccountID: accountID}
+}
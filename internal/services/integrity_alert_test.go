@@ -0,0 +1,79 @@
+// Path: internal/services/integrity_alert_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/metrics"
+	"reflect"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// recordingAlerter records the accounts it was alerted about.
+type recordingAlerter struct {
+	mu       sync.Mutex
+	accounts []int
+}
+
+func (a *recordingAlerter) IntegrityFailed(accountID int, details string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.accounts = append(a.accounts, accountID)
+}
+
+// tamperedAccount seeds an account and changes its balance behind the application's back.
+func tamperedAccount(t *testing.T, db *gorm.DB, user *models.User) *models.Account {
+	t.Helper()
+	account := seedAccount(t, db, user, 100, "USD")
+	if err := db.Exec("UPDATE accounts SET balance = 1000000 WHERE id = ?", account.ID).Error; err != nil {
+		t.Fatalf("tamper: %v", err)
+	}
+	return account
+}
+
+// wantOneIntegrityFailure checks that exactly one failure on account was counted, audited and alerted.
+func wantOneIntegrityFailure(t *testing.T, db *gorm.DB, alerter *recordingAlerter, before int64, account *models.Account) {
+	t.Helper()
+	if got := metrics.BalanceIntegrityViolations.Value() - before; got != 1 {
+		t.Errorf("metric went up by %d, want 1", got)
+	}
+	if want := []int{account.ID}; !reflect.DeepEqual(alerter.accounts, want) {
+		t.Errorf("alerts = %v, want %v", alerter.accounts, want)
+	}
+	var entries []models.AuditLog
+	if err := db.Where("action = ?", "balance_integrity_failure").Find(&entries).Error; err != nil {
+		t.Fatalf("load audit entries: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Severity != models.SeverityCritical {
+		t.Errorf("audit entries = %+v, want one critical", entries)
+	}
+}
+
+func TestTamperedBalanceAlertsOnceOnWithdrawal(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "victim")
+	account := tamperedAccount(t, db, user)
+	alerter := &recordingAlerter{}
+	s := NewTransactionService(db, testSecret, TransactionConfig{IntegrityAlerter: alerter})
+
+	before := metrics.BalanceIntegrityViolations.Value()
+	_, err := s.ProcessWithdraw(&models.TransactionRequest{AccountID: account.ID, Amount: 50}, claimsFor(user))
+	wantAppError(t, err, 500)
+	// The audit entry survives the rollback of the withdrawal.
+	wantOneIntegrityFailure(t, db, alerter, before, account)
+}
+
+func TestTamperedBalanceAlertsOnceOnRead(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "reader")
+	account := tamperedAccount(t, db, user)
+	alerter := &recordingAlerter{}
+	s := NewAccountService(db, testSecret, AccountConfig{IntegrityAlerter: alerter})
+
+	before := metrics.BalanceIntegrityViolations.Value()
+	_, err := s.GetAccount(claimsFor(user), account.ID)
+	wantAppError(t, err, 500)
+	wantOneIntegrityFailure(t, db, alerter, before, account)
+}
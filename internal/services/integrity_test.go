@@ -0,0 +1,35 @@
+// Path: internal/services/integrity_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"testing"
+)
+
+func TestHashVerifiesAfterFractionalArithmetic(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "fractions")
+	account := seedAccount(t, db, user, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{})
+
+	for i := 0; i < 50; i++ {
+		if _, err := s.ProcessDeposit(&models.TransactionRequest{AccountID: account.ID, Amount: 0.1}, claimsFor(user)); err != nil {
+			t.Fatalf("deposit %d: %v", i, err)
+		}
+		if _, err := s.ProcessDeposit(&models.TransactionRequest{AccountID: account.ID, Amount: 0.2}, claimsFor(user)); err != nil {
+			t.Fatalf("deposit %d: %v", i, err)
+		}
+		// Withdrawals reload and verify the stored hash first.
+		if _, err := s.ProcessWithdraw(&models.TransactionRequest{AccountID: account.ID, Amount: 0.07}, claimsFor(user)); err != nil {
+			t.Fatalf("withdrawal %d: %v", i, err)
+		}
+	}
+
+	stored := reloadAccount(t, db, account.ID)
+	if stored.Balance != 11.5 {
+		t.Errorf("balance = %v, want 11.5", stored.Balance)
+	}
+	if stored.BalanceHash != balanceHash(stored, testSecret) {
+		t.Error("stored hash does not verify")
+	}
+}
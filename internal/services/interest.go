@@ -0,0 +1,142 @@
+// Path: internal/services/interest.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"fmt"
+	"log"
+	"math/big"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// interestAccount is the part of an account the interest job reads and writes.
+type interestAccount struct {
+	ID                int
+	UserID            int
+	Type              string
+	Currency          string
+	Balance           float64
+	BalanceHash       string
+	SubBalances       models.SubBalances
+	InterestRemainder float64 // Fraction of a minor unit earned but not yet paid
+	InterestAccruedAt *time.Time
+	CreatedAt         time.Time
+}
+
+// AccrueInterest pays the interest earned since the last accrual to every account whose type has
+// an interest rate, for whole days only. Interest is computed on integer minor units and rounded
+// down; the fraction left over is carried to the next accrual, so over time the accounts are paid
+// exactly the annual rate. It returns the number of accounts credited.
+func (s *transactionService) AccrueInterest() (int, error) {
+	var types []string
+	for accountType, policy := range s.policies {
+		if policy.InterestRate() > 0 {
+			types = append(types, accountType)
+		}
+	}
+	if len(types) == 0 {
+		return 0, nil
+	}
+
+	var accounts []interestAccount
+	err := s.db.Model(&models.Account{}).
+		Select("id", "user_id", "type", "currency", "balance", "balance_hash", "sub_balances", "interest_remainder", "interest_accrued_at", "created_at").
+		Where("type IN ?", types).Order("id").
+		Scan(&accounts).Error
+	if err != nil {
+		return 0, &AppError{Code: 500, Message: "Failed to query accounts", Details: err.Error(), Err: err}
+	}
+
+	credited := 0
+	now := s.clock.Now()
+	for i := range accounts {
+		paid, err := s.accrueAccountInterest(&accounts[i], now)
+		if err != nil {
+			log.Printf("Failed to accrue interest on account %d: %v", accounts[i].ID, err)
+			continue
+		}
+		if paid {
+			credited++
+		}
+	}
+	return credited, nil
+}
+
+// accrueAccountInterest pays one account the interest of the whole days since its last accrual and
+// reports whether anything was paid. Days at a zero or negative balance earn nothing.
+func (s *transactionService) accrueAccountInterest(account *interestAccount, now time.Time) (bool, error) {
+	since := account.CreatedAt
+	if account.InterestAccruedAt != nil {
+		since = *account.InterestAccruedAt
+	}
+	days := int(now.Sub(since) / (24 * time.Hour))
+	if days < 1 {
+		return false, nil
+	}
+	accruedAt := since.Add(time.Duration(days) * 24 * time.Hour)
+
+	if account.BalanceHash != utils.CalculateAccountHash(account.Balance, account.ID, account.SubBalances, s.secretKey) {
+		return false, s.integrityFailed(account.ID)
+	}
+
+	decimals := currencyDecimals(account.Currency, s.cfg.CurrencyDecimals)
+	var interest int64
+	remainder := account.InterestRemainder
+	if account.Balance > 0 {
+		rate := s.policies[account.Type].InterestRate()
+		interest, remainder = accrueInterest(toMinorUnits(account.Balance, decimals), rate, days, remainder)
+	}
+
+	balance := account.Balance + fromMinorUnits(interest, decimals)
+	updates := map[string]interface{}{
+		"balance":             balance,
+		"balance_hash":        utils.CalculateAccountHash(balance, account.ID, account.SubBalances, s.secretKey),
+		"interest_remainder":  remainder,
+		"interest_accrued_at": accruedAt,
+	}
+
+	var paid bool
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// The hash condition skips the account if a payment changed it since it was read;
+		// the next run accrues it again.
+		result := tx.Model(&models.Account{}).Where("id = ? AND balance_hash = ?", account.ID, account.BalanceHash).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 || interest == 0 {
+			return nil
+		}
+
+		paid = true
+		transaction := models.Transaction{
+			ID:          utils.GenerateTransactionID(now),
+			ToAccountID: &account.ID,
+			Amount:      fromMinorUnits(interest, decimals),
+			Type:        "interest",
+			Status:      "completed",
+			Description: fmt.Sprintf("Interest for %d days", days),
+			CreatedAt:   now,
+		}
+		return tx.Create(&transaction).Error
+	})
+	return paid, err
+}
+
+// accrueInterest returns the whole minor units of interest earned by balance minor units over days
+// at rate percent a year, and the fraction of a minor unit left over. remainder is the fraction
+// left over by earlier accruals. The calculation is exact; only the carried fraction is a float.
+func accrueInterest(balance int64, rate float64, days int, remainder float64) (int64, float64) {
+	// The decimal form of the rate is exact: 0.1% is 1/1000, not the nearest binary fraction.
+	exact, _ := new(big.Rat).SetString(strconv.FormatFloat(rate, 'f', -1, 64))
+	exact.Mul(exact, new(big.Rat).SetInt64(balance))
+	exact.Mul(exact, big.NewRat(int64(days), 100*365))
+	exact.Add(exact, new(big.Rat).SetFloat64(remainder))
+
+	whole := new(big.Int).Quo(exact.Num(), exact.Denom())
+	left, _ := new(big.Rat).Sub(exact, new(big.Rat).SetInt(whole)).Float64()
+	return whole.Int64(), left
+}
@@ -0,0 +1,109 @@
+// Path: internal/services/interest_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/accountnumber"
+	"bank-api/pkg/utils"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestAccrueInterestDailyOverAYear(t *testing.T) {
+	// 1234.57 at 3.7% earns 45.67909 a year; a day earns 12.51 minor units.
+	const balance, rate = 123457, 3.7
+	var paid int64
+	var remainder float64
+	for day := 0; day < 365; day++ {
+		var interest int64
+		interest, remainder = accrueInterest(balance, rate, 1, remainder)
+		paid += interest
+	}
+	if paid != 4567 {
+		t.Errorf("paid %d minor units over a year, want 4567", paid)
+	}
+	if got := float64(paid) + remainder; math.Abs(got-4567.909) > 1e-6 {
+		t.Errorf("paid plus remainder = %v, want exactly 4567.909", got)
+	}
+
+	// One accrual over the whole year gives the same figure.
+	if whole, left := accrueInterest(balance, rate, 365, 0); whole != paid || math.Abs(left-remainder) > 1e-6 {
+		t.Errorf("yearly accrual = %d + %v, daily = %d + %v", whole, left, paid, remainder)
+	}
+}
+
+func TestAccrueInterestUsesTheDecimalRate(t *testing.T) {
+	// 0.1% of 3650.00 is 3.65 a year, exactly one minor unit a day.
+	interest, remainder := accrueInterest(365000, 0.1, 1, 0)
+	if interest != 1 || remainder != 0 {
+		t.Errorf("one day = %d + %v, want exactly 1", interest, remainder)
+	}
+}
+
+func TestAccrueInterestCompoundsWithinAMinorUnit(t *testing.T) {
+	const rate = 2.5
+	balance := int64(1000000)
+	exact := new(big.Rat).SetInt64(balance)
+	daily := big.NewRat(25, 365000)
+	var remainder float64
+	for day := 0; day < 365; day++ {
+		var interest int64
+		interest, remainder = accrueInterest(balance, rate, 1, remainder)
+		balance += interest
+		exact.Add(exact, new(big.Rat).Mul(exact, daily))
+	}
+	want, _ := exact.Float64()
+	if diff := math.Abs(float64(balance) - want); diff > 1 {
+		t.Errorf("balance after a year = %d minor units, exact compounding gives %v", balance, want)
+	}
+}
+
+func TestAccrueInterestOverAYear(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "saver")
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	account := &models.Account{UserID: user.ID, Balance: 1234.57, Currency: "USD", Type: models.AccountTypeSavings}
+	if err := createAccount(db, account, testSecret, start, accountnumber.Internal{}); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	clock := utils.NewManualClock(start)
+	s := NewTransactionService(db, testSecret, TransactionConfig{
+		Clock:    clock,
+		Policies: AccountPolicyConfig{SavingsInterestRate: 3.7},
+	}).(*transactionService)
+
+	for day := 0; day < 365; day++ {
+		clock.Advance(24 * time.Hour)
+		if _, err := s.AccrueInterest(); err != nil {
+			t.Fatalf("day %d: %v", day+1, err)
+		}
+	}
+
+	// Compounded daily from 123457 minor units: within a minor unit of the exact figure.
+	exact := new(big.Rat).SetInt64(123457)
+	for day := 0; day < 365; day++ {
+		exact.Add(exact, new(big.Rat).Mul(exact, big.NewRat(37, 365000)))
+	}
+	want, _ := exact.Float64()
+	stored := reloadAccount(t, db, account.ID)
+	if got := stored.Balance * 100; math.Abs(got-want) > 1 {
+		t.Errorf("balance after a year = %v, want %v within a cent", stored.Balance, want/100)
+	}
+	if stored.BalanceHash != balanceHash(stored, testSecret) {
+		t.Error("stored hash does not match the balance")
+	}
+	var remainder float64
+	if err := db.Model(&models.Account{}).Where("id = ?", account.ID).Select("interest_remainder").Scan(&remainder).Error; err != nil {
+		t.Fatalf("read remainder: %v", err)
+	}
+	if remainder < 0 || remainder >= 1 {
+		t.Errorf("carried remainder = %v, want a fraction of a minor unit", remainder)
+	}
+
+	// A second run the same day pays nothing.
+	if credited, err := s.AccrueInterest(); err != nil || credited != 0 {
+		t.Errorf("repeat run credited %d accounts, %v", credited, err)
+	}
+}
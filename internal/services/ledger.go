@@ -0,0 +1,87 @@
+// Path: internal/services/ledger.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Ledger entry directions.
+const (
+	DirectionDebit  = "debit"
+	DirectionCredit = "credit"
+)
+
+// recordTransferLegs logs a completed transfer in the event log and writes its debit and credit
+// legs when ledger entries are enabled. A fee adds a pair of legs from the source to the fee account.
+// All legs use the transaction ID as their group and net to zero.
+func (s *transactionService) recordTransferLegs(tx *gorm.DB, transaction *models.Transaction) error {
+	if transaction.FromAccountID == nil || transaction.ToAccountID == nil {
+		return nil
+	}
+	if err := appendTransferEvent(tx, transaction); err != nil {
+		return err
+	}
+	if !s.cfg.LedgerEntries {
+		return nil
+	}
+
+	now := s.clock.Now()
+	legs := []models.LedgerEntry{
+		{GroupID: transaction.ID, AccountID: *transaction.FromAccountID, Direction: DirectionDebit, Amount: -transaction.Amount, CreatedAt: now},
+		{GroupID: transaction.ID, AccountID: *transaction.ToAccountID, Direction: DirectionCredit, Amount: transaction.Amount, CreatedAt: now},
+	}
+	if transaction.Fee > 0 {
+		var from models.Account
+		if err := tx.Select("id", "currency", "sandbox").First(&from, *transaction.FromAccountID).Error; err != nil {
+			return &AppError{Code: 500, Message: "Failed to query source account", Details: err.Error(), Err: err}
+		}
+		feeID, ok, err := s.feeAccountID(&from)
+		if err != nil {
+			return err
+		}
+		if ok {
+			legs = append(legs,
+				models.LedgerEntry{GroupID: transaction.ID, AccountID: from.ID, Direction: DirectionDebit, Amount: -transaction.Fee, CreatedAt: now},
+				models.LedgerEntry{GroupID: transaction.ID, AccountID: feeID, Direction: DirectionCredit, Amount: transaction.Fee, CreatedAt: now},
+			)
+		}
+	}
+	if err := tx.Create(&legs).Error; err != nil {
+		return &AppError{Code: 500, Message: "Failed to insert ledger entries", Details: err.Error(), Err: err}
+	}
+
+	return nil
+}
+
+// ListLedgerEntries returns the legs booked on one of the user's accounts, newest first.
+func (s *transactionService) ListLedgerEntries(userID uint, accountID int, filter models.LedgerFilter) ([]models.LedgerEntry, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultHistoryLimit
+	}
+	if filter.Limit > MaxHistoryLimit {
+		filter.Limit = MaxHistoryLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	var owned int64
+	if err := s.db.Model(&models.Account{}).Where("id = ? AND user_id = ?", accountID, userID).Count(&owned).Error; err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to query account", Details: err.Error(), Err: err}
+	}
+	if owned == 0 {
+		return nil, &AppError{Code: 404, Message: "Account not found or access denied", Details: fmt.Sprintf("account_id: %d, user_id: %d", accountID, userID)}
+	}
+
+	entries := []models.LedgerEntry{}
+	err := s.db.Where("account_id = ?", accountID).Order("created_at DESC, id DESC").
+		Limit(filter.Limit).Offset(filter.Offset).Find(&entries).Error
+	if err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to query ledger entries", Details: err.Error(), Err: err}
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,84 @@
+// Path: internal/services/ledger_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"testing"
+)
+
+func TestTransferLegsNetToZero(t *testing.T) {
+	db := testDB(t)
+	alice := seedUser(t, db, "alice")
+	bob := seedUser(t, db, "bob")
+	from := seedAccount(t, db, alice, 100, "USD")
+	to := seedAccount(t, db, bob, 0, "USD")
+	feeAccounts := seedFeeAccounts(t, db, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{LedgerEntries: true, Fees: FeeConfig{Flat: 1, Accounts: feeAccounts}})
+
+	for _, amount := range []float64{30, 12.5} {
+		if err := s.ProcessTransfer(&models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: amount}, claimsFor(alice)); err != nil {
+			t.Fatalf("transfer %v: %v", amount, err)
+		}
+	}
+
+	var transfers []models.Transaction
+	db.Where("type = ?", "transfer").Order("created_at, id").Find(&transfers)
+	if len(transfers) != 2 {
+		t.Fatalf("%d transfers, want 2", len(transfers))
+	}
+	for _, transfer := range transfers {
+		var legs []models.LedgerEntry
+		db.Where("group_id = ?", transfer.ID).Order("id").Find(&legs)
+		if len(legs) != 4 {
+			t.Fatalf("transfer %s has %d legs, want 4", transfer.ID, len(legs))
+		}
+		want := []models.LedgerEntry{
+			{AccountID: from.ID, Direction: DirectionDebit, Amount: -transfer.Amount},
+			{AccountID: to.ID, Direction: DirectionCredit, Amount: transfer.Amount},
+			{AccountID: from.ID, Direction: DirectionDebit, Amount: -1},
+			{AccountID: feeAccounts["USD"], Direction: DirectionCredit, Amount: 1},
+		}
+		sum := 0.0
+		for i, leg := range legs {
+			if leg.AccountID != want[i].AccountID || leg.Direction != want[i].Direction || leg.Amount != want[i].Amount {
+				t.Errorf("leg %d of %s = %+v, want %+v", i, transfer.ID, leg, want[i])
+			}
+			sum += leg.Amount
+		}
+		if sum != 0 {
+			t.Errorf("legs of %s sum to %v, want 0", transfer.ID, sum)
+		}
+	}
+
+	// The fees moved to the fee account: no money was created or lost.
+	balances := map[int]float64{from.ID: 55.5, to.ID: 42.5, feeAccounts["USD"]: 2}
+	for id, want := range balances {
+		if got := reloadAccount(t, db, id).Balance; got != want {
+			t.Errorf("account %d balance = %v, want %v", id, got, want)
+		}
+	}
+
+	entries, err := s.ListLedgerEntries(uint(bob.ID), to.ID, models.LedgerFilter{})
+	if err != nil || len(entries) != 2 || entries[0].Amount != 12.5 || entries[1].Amount != 30 {
+		t.Errorf("bob's entries = %+v, %v; want both credits newest first", entries, err)
+	}
+	_, err = s.ListLedgerEntries(uint(bob.ID), from.ID, models.LedgerFilter{})
+	wantAppError(t, err, 404)
+}
+
+func TestLedgerEntriesDisabled(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "plain")
+	from := seedAccount(t, db, user, 100, "USD")
+	to := seedAccount(t, db, user, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{})
+
+	if err := s.ProcessTransfer(&models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: 10}, claimsFor(user)); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	var count int64
+	db.Model(&models.LedgerEntry{}).Count(&count)
+	if count != 0 {
+		t.Errorf("%d ledger entries with LedgerEntries off, want 0", count)
+	}
+}
@@ -0,0 +1,64 @@
+// Path: internal/services/login_history.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"log"
+)
+
+// Login history paging and retention limits.
+const (
+	DefaultLoginHistoryLimit     = 20
+	MaxLoginHistoryLimit         = 100
+	DefaultLoginHistoryRetention = 100
+	maxUserAgentLength           = 255
+)
+
+// recordLoginAttempt stores a login attempt and drops the user's attempts beyond the retention limit.
+// Failures are only logged: they must not make logins fail.
+func (s *authService) recordLoginAttempt(userID int, meta models.LoginMeta, outcome string) {
+	userAgent := []rune(meta.UserAgent)
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	attempt := models.LoginAttempt{
+		UserID:    userID,
+		IP:        meta.IP,
+		UserAgent: string(userAgent),
+		Outcome:   outcome,
+		CreatedAt: s.cfg.Clock.Now(),
+	}
+	if err := s.db.Create(&attempt).Error; err != nil {
+		log.Printf("Failed to record login attempt for user %d: %v", userID, err)
+		return
+	}
+
+	kept := s.db.Model(&models.LoginAttempt{}).Select("id").Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").Limit(s.cfg.LoginHistoryRetention)
+	if err := s.db.Where("user_id = ? AND id NOT IN (?)", userID, kept).Delete(&models.LoginAttempt{}).Error; err != nil {
+		log.Printf("Failed to prune login history for user %d: %v", userID, err)
+	}
+}
+
+// LoginHistory returns the user's recent login attempts, newest first.
+func (s *authService) LoginHistory(userID uint, filter models.LoginHistoryFilter) ([]models.LoginAttempt, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultLoginHistoryLimit
+	}
+	if filter.Limit > MaxLoginHistoryLimit {
+		filter.Limit = MaxLoginHistoryLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	attempts := []models.LoginAttempt{}
+	err := s.db.Where("user_id = ?", userID).Order("created_at DESC, id DESC").
+		Limit(filter.Limit).Offset(filter.Offset).Find(&attempts).Error
+	if err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to query login history", Details: err.Error(), Err: err}
+	}
+
+	return attempts, nil
+}
@@ -0,0 +1,62 @@
+// Path: internal/services/login_history_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoginHistoryRecordsAttemptsInOrder(t *testing.T) {
+	db := testDB(t)
+	clock := utils.NewManualClock(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	s := NewAuthService(db, testJWTSecret, AuthConfig{Clock: clock, LoginHistoryRetention: 3})
+	if err := s.Register("watcher", "password1"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	login := func(password, ip string) {
+		clock.Advance(time.Minute)
+		s.Login("watcher", password, models.LoginMeta{IP: ip, UserAgent: "test/" + ip + strings.Repeat("x", maxUserAgentLength)})
+	}
+	login("wrong", "10.0.0.1")
+	login("password1", "10.0.0.2")
+	login("wrong", "10.0.0.3")
+	login("password1", "10.0.0.4")
+	if _, err := s.Login("nobody", "password1", models.LoginMeta{IP: "10.0.0.5"}); err == nil {
+		t.Fatal("login of an unknown user succeeded")
+	}
+
+	user, err := NewUserRepository(db).FindByUsername("watcher")
+	if err != nil {
+		t.Fatalf("find user: %v", err)
+	}
+	history, err := s.LoginHistory(uint(user.ID), models.LoginHistoryFilter{})
+	if err != nil {
+		t.Fatalf("LoginHistory: %v", err)
+	}
+
+	// The oldest attempt is past the retention of 3.
+	want := []struct{ ip, outcome string }{{"10.0.0.4", "success"}, {"10.0.0.3", "failed"}, {"10.0.0.2", "success"}}
+	if len(history) != len(want) {
+		t.Fatalf("history = %+v, want %d attempts", history, len(want))
+	}
+	for i, w := range want {
+		if history[i].IP != w.ip || history[i].Outcome != w.outcome {
+			t.Errorf("history[%d] = %s %s, want %s %s", i, history[i].IP, history[i].Outcome, w.ip, w.outcome)
+		}
+		if n := len([]rune(history[i].UserAgent)); n != maxUserAgentLength {
+			t.Errorf("history[%d] user agent of %d characters, want it cut to %d", i, n, maxUserAgentLength)
+		}
+	}
+	if !history[0].CreatedAt.After(history[1].CreatedAt) {
+		t.Errorf("attempts not newest first: %v, %v", history[0].CreatedAt, history[1].CreatedAt)
+	}
+
+	page, err := s.LoginHistory(uint(user.ID), models.LoginHistoryFilter{Limit: 1, Offset: 1})
+	if err != nil || len(page) != 1 || page[0].IP != "10.0.0.3" {
+		t.Errorf("second page = %+v, %v", page, err)
+	}
+}
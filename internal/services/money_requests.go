@@ -0,0 +1,217 @@
+// Path: internal/services/money_requests.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"errors"
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// Money request statuses. Only pending requests can be paid or rejected.
+const (
+	MoneyRequestPending  = "pending"
+	MoneyRequestPaid     = "paid"
+	MoneyRequestRejected = "rejected"
+	MoneyRequestExpired  = "expired"
+)
+
+// RequestMoney asks another user, named by username or account number, to pay an amount into one
+// of the caller's accounts. Nothing moves until the payer pays the request.
+func (s *transactionService) RequestMoney(req *models.MoneyRequestCreate, claims *models.Claims) (*models.MoneyRequest, error) {
+	var v validation
+	v.check(req.Amount > 0, "amount", "Amount must be positive")
+	v.check(req.Username != "" || req.AccountNumber != "", "username", "A username or an account number is required")
+	v.check(req.Username == "" || req.AccountNumber == "", "username", "Give either a username or an account number, not both")
+	v.description("description", &req.Description)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	account, err := s.loadOwnedAccount(s.db, req.AccountID, claims.UserID, claims.Sandbox)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkAmountPrecision(req.Amount, account.Currency, s.cfg.CurrencyDecimals); err != nil {
+		return nil, err
+	}
+
+	payerID, err := s.resolvePayer(req)
+	if err != nil {
+		return nil, err
+	}
+	if payerID == int(claims.UserID) {
+		return nil, &AppError{Code: 400, Message: "Invalid money request", Details: "You can't request money from yourself"}
+	}
+
+	request := models.MoneyRequest{
+		RequesterID: int(claims.UserID),
+		PayerID:     payerID,
+		AccountID:   account.ID,
+		Amount:      req.Amount,
+		Status:      MoneyRequestPending,
+		Description: req.Description,
+		CreatedAt:   s.clock.Now(),
+	}
+	if err := s.db.Create(&request).Error; err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to save money request", Details: err.Error(), Err: err}
+	}
+	return &request, nil
+}
+
+// resolvePayer finds the user asked to pay a money request.
+func (s *transactionService) resolvePayer(req *models.MoneyRequestCreate) (int, error) {
+	if req.AccountNumber != "" {
+		accountID, err := resolveAccountNumber(s.db, req.AccountNumber, s.cfg.AccountNumbers)
+		if err != nil {
+			return 0, err
+		}
+		var owners []int
+		if err := s.db.Model(&models.Account{}).Where("id = ?", accountID).Pluck("user_id", &owners).Error; err != nil {
+			return 0, &AppError{Code: 500, Message: "Failed to query account", Details: err.Error(), Err: err}
+		}
+		if len(owners) == 0 {
+			return 0, &AppError{Code: 404, Message: "Account not found", Details: fmt.Sprintf("account_id: %d", accountID)}
+		}
+		return owners[0], nil
+	}
+
+	var user models.User
+	if err := s.db.Select("id").Where("username = ?", normalizeUsername(req.Username)).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, &AppError{Code: 404, Message: "User not found", Details: fmt.Sprintf("username: %s", req.Username)}
+		}
+		return 0, &AppError{Code: 500, Message: "Failed to query user", Details: err.Error(), Err: err}
+	}
+	return user.ID, nil
+}
+
+// GetMoneyRequests lists the money requests the user sent or received, newest first. Requests past
+// their TTL are listed as expired even before ExpireMoneyRequests records it.
+func (s *transactionService) GetMoneyRequests(userID uint) ([]models.MoneyRequest, error) {
+	requests := []models.MoneyRequest{}
+	err := s.db.Where("requester_id = ? OR payer_id = ?", userID, userID).Order("created_at DESC, id DESC").Find(&requests).Error
+	if err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to query money requests", Details: err.Error(), Err: err}
+	}
+
+	for i := range requests {
+		if requests[i].Status == MoneyRequestPending && s.moneyRequestExpired(&requests[i]) {
+			expiresAt := requests[i].CreatedAt.Add(s.cfg.MoneyRequestTTL)
+			requests[i].Status, requests[i].ResolvedAt = MoneyRequestExpired, &expiresAt
+		}
+	}
+	return requests, nil
+}
+
+// ExpireMoneyRequests marks the pending requests older than MoneyRequestTTL as expired and returns
+// how many it marked.
+func (s *transactionService) ExpireMoneyRequests() (int, error) {
+	if s.cfg.MoneyRequestTTL <= 0 {
+		return 0, nil
+	}
+
+	now := s.clock.Now()
+	result := s.db.Model(&models.MoneyRequest{}).
+		Where("status = ? AND created_at <= ?", MoneyRequestPending, now.Add(-s.cfg.MoneyRequestTTL)).
+		Updates(map[string]interface{}{"status": MoneyRequestExpired, "resolved_at": now})
+	if result.Error != nil {
+		return 0, &AppError{Code: 500, Message: "Failed to expire money requests", Details: result.Error.Error(), Err: result.Error}
+	}
+	return int(result.RowsAffected), nil
+}
+
+// moneyRequestExpired reports whether the request is past its TTL.
+func (s *transactionService) moneyRequestExpired(request *models.MoneyRequest) bool {
+	return s.cfg.MoneyRequestTTL > 0 && !s.clock.Now().Before(request.CreatedAt.Add(s.cfg.MoneyRequestTTL))
+}
+
+// PayMoneyRequest pays a pending request addressed to the user with a transfer from one of their
+// accounts, subject to every rule of ProcessTransfer. The request is marked paid before the
+// transfer so that it can't be paid twice, and goes back to pending if the transfer fails.
+func (s *transactionService) PayMoneyRequest(requestID int, req *models.MoneyRequestPayment, claims *models.Claims) (*models.MoneyRequest, *models.TransferRequest, error) {
+	request, err := s.loadPendingMoneyRequest(requestID, claims)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := s.clock.Now()
+	if err := s.resolveMoneyRequest(request.ID, map[string]interface{}{"status": MoneyRequestPaid, "resolved_at": now}); err != nil {
+		return nil, nil, err
+	}
+
+	transfer := models.TransferRequest{
+		FromID:           req.FromID,
+		ToID:             request.AccountID,
+		Amount:           request.Amount,
+		Description:      request.Description,
+		ConfirmationCode: req.ConfirmationCode,
+	}
+	if err := s.ProcessTransfer(&transfer, claims); err != nil {
+		reopen := s.db.Model(&models.MoneyRequest{}).
+			Where("id = ? AND status = ?", request.ID, MoneyRequestPaid).
+			Updates(map[string]interface{}{"status": MoneyRequestPending, "resolved_at": nil})
+		if reopen.Error != nil {
+			log.Printf("Failed to reopen money request %d after a failed payment: %v", request.ID, reopen.Error)
+		}
+		return nil, nil, err
+	}
+
+	if err := s.db.Model(&models.MoneyRequest{}).Where("id = ?", request.ID).Update("transaction_id", transfer.TransactionID).Error; err != nil {
+		return nil, nil, &AppError{Code: 500, Message: "Failed to update money request", Details: err.Error(), Err: err}
+	}
+	request.Status, request.TransactionID, request.ResolvedAt = MoneyRequestPaid, &transfer.TransactionID, &now
+	return request, &transfer, nil
+}
+
+// RejectMoneyRequest refuses a pending request addressed to the user. No funds are moved.
+func (s *transactionService) RejectMoneyRequest(requestID int, claims *models.Claims) (*models.MoneyRequest, error) {
+	request, err := s.loadPendingMoneyRequest(requestID, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	if err := s.resolveMoneyRequest(request.ID, map[string]interface{}{"status": MoneyRequestRejected, "resolved_at": now}); err != nil {
+		return nil, err
+	}
+	request.Status, request.ResolvedAt = MoneyRequestRejected, &now
+	return request, nil
+}
+
+// loadPendingMoneyRequest fetches a pending money request addressed to the user. A request past
+// its TTL is expired on the spot, whether or not the sweeper got to it.
+func (s *transactionService) loadPendingMoneyRequest(requestID int, claims *models.Claims) (*models.MoneyRequest, error) {
+	var request models.MoneyRequest
+	if err := s.db.Where("id = ? AND payer_id = ?", requestID, claims.UserID).First(&request).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &AppError{Code: 404, Message: "Money request not found", Details: fmt.Sprintf("request_id: %d", requestID)}
+		}
+		return nil, &AppError{Code: 500, Message: "Failed to query money request", Details: err.Error(), Err: err}
+	}
+	if request.Status == MoneyRequestPending && s.moneyRequestExpired(&request) {
+		if err := s.resolveMoneyRequest(request.ID, map[string]interface{}{"status": MoneyRequestExpired, "resolved_at": s.clock.Now()}); err != nil {
+			return nil, err
+		}
+		return nil, &AppError{Code: 409, Message: "Money request has expired", Details: fmt.Sprintf("request_id: %d", requestID)}
+	}
+	if request.Status != MoneyRequestPending {
+		return nil, &AppError{Code: 409, Message: "Money request is no longer pending", Details: fmt.Sprintf("request_id: %d, status: %s", requestID, request.Status)}
+	}
+	return &request, nil
+}
+
+// resolveMoneyRequest updates a money request still pending, so of two concurrent payments or
+// rejections only one succeeds.
+func (s *transactionService) resolveMoneyRequest(requestID int, updates map[string]interface{}) error {
+	result := s.db.Model(&models.MoneyRequest{}).Where("id = ? AND status = ?", requestID, MoneyRequestPending).Updates(updates)
+	if result.Error != nil {
+		return &AppError{Code: 500, Message: "Failed to update money request", Details: result.Error.Error(), Err: result.Error}
+	}
+	if result.RowsAffected == 0 {
+		return &AppError{Code: 409, Message: "Money request is no longer pending", Details: fmt.Sprintf("request_id: %d", requestID)}
+	}
+	return nil
+}
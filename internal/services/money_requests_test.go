@@ -0,0 +1,231 @@
+// Path: internal/services/money_requests_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"testing"
+	"time"
+)
+
+func TestRequestMoneyValidation(t *testing.T) {
+	s := NewTransactionService(nil, testSecret, TransactionConfig{})
+	for _, tt := range []struct {
+		name string
+		req  models.MoneyRequestCreate
+		want string
+	}{
+		{"no amount", models.MoneyRequestCreate{Username: "bob"}, "amount"},
+		{"no payer", models.MoneyRequestCreate{Amount: 5}, "username"},
+		{"two payers", models.MoneyRequestCreate{Amount: 5, Username: "bob", AccountNumber: "40817810000000000001"}, "username"},
+	} {
+		_, err := s.RequestMoney(&tt.req, &models.Claims{UserID: 1})
+		if names := fieldNames(t, err); len(names) != 1 || names[0] != tt.want {
+			t.Errorf("%s: fields %v, want %s", tt.name, names, tt.want)
+		}
+	}
+}
+
+func TestRequestMoneyResolvesPayer(t *testing.T) {
+	db := testDB(t)
+	alice, bob := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+	into, bobs := seedAccount(t, db, alice, 0, "USD"), seedAccount(t, db, bob, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{})
+
+	byName, err := s.RequestMoney(&models.MoneyRequestCreate{AccountID: into.ID, Username: "BOB", Amount: 25, Description: "dinner"}, claimsFor(alice))
+	if err != nil {
+		t.Fatalf("request by username: %v", err)
+	}
+	if byName.PayerID != bob.ID || byName.RequesterID != alice.ID || byName.Status != MoneyRequestPending {
+		t.Errorf("request = %+v, want a pending request from alice to bob", byName)
+	}
+	byNumber, err := s.RequestMoney(&models.MoneyRequestCreate{AccountID: into.ID, AccountNumber: *bobs.Number, Amount: 5}, claimsFor(alice))
+	if err != nil || byNumber.PayerID != bob.ID {
+		t.Errorf("request by account number = %+v, %v; want bob as the payer", byNumber, err)
+	}
+
+	_, err = s.RequestMoney(&models.MoneyRequestCreate{AccountID: into.ID, Username: "nobody", Amount: 5}, claimsFor(alice))
+	wantAppError(t, err, 404)
+	_, err = s.RequestMoney(&models.MoneyRequestCreate{AccountID: into.ID, Username: "alice", Amount: 5}, claimsFor(alice))
+	wantAppError(t, err, 400)
+	_, err = s.RequestMoney(&models.MoneyRequestCreate{AccountID: bobs.ID, Username: "bob", Amount: 5}, claimsFor(alice))
+	wantAppError(t, err, 404)
+
+	for _, user := range []*models.User{alice, bob} {
+		requests, err := s.GetMoneyRequests(uint(user.ID))
+		if err != nil || len(requests) != 2 || requests[0].ID != byNumber.ID {
+			t.Errorf("requests of %s = %+v, %v; want both, newest first", user.Username, requests, err)
+		}
+	}
+	if balance := reloadAccount(t, db, into.ID).Balance; balance != 0 {
+		t.Errorf("balance after requesting = %v, want 0", balance)
+	}
+}
+
+func TestPayMoneyRequestMovesFunds(t *testing.T) {
+	db := testDB(t)
+	alice, bob := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+	into, from := seedAccount(t, db, alice, 0, "USD"), seedAccount(t, db, bob, 100, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{})
+
+	request, err := s.RequestMoney(&models.MoneyRequestCreate{AccountID: into.ID, Username: "bob", Amount: 40}, claimsFor(alice))
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	_, _, err = s.PayMoneyRequest(request.ID, &models.MoneyRequestPayment{FromID: into.ID}, claimsFor(alice))
+	wantAppError(t, err, 404)
+
+	paid, transfer, err := s.PayMoneyRequest(request.ID, &models.MoneyRequestPayment{FromID: from.ID}, claimsFor(bob))
+	if err != nil {
+		t.Fatalf("pay: %v", err)
+	}
+	if paid.Status != MoneyRequestPaid || paid.TransactionID == nil || *paid.TransactionID != transfer.TransactionID {
+		t.Errorf("paid request = %+v, want paid by transaction %s", paid, transfer.TransactionID)
+	}
+	if payer, requester := reloadAccount(t, db, from.ID), reloadAccount(t, db, into.ID); payer.Balance != 100-40-transfer.Fee || requester.Balance != 40 {
+		t.Errorf("balances after paying = %v and %v, want %v and 40", payer.Balance, requester.Balance, 100-40-transfer.Fee)
+	}
+
+	_, _, err = s.PayMoneyRequest(request.ID, &models.MoneyRequestPayment{FromID: from.ID}, claimsFor(bob))
+	wantAppError(t, err, 409)
+}
+
+func TestFailedPaymentReopensMoneyRequest(t *testing.T) {
+	db := testDB(t)
+	alice, bob := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+	into, from := seedAccount(t, db, alice, 0, "USD"), seedAccount(t, db, bob, 10, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{})
+
+	request, err := s.RequestMoney(&models.MoneyRequestCreate{AccountID: into.ID, Username: "bob", Amount: 40}, claimsFor(alice))
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	_, _, err = s.PayMoneyRequest(request.ID, &models.MoneyRequestPayment{FromID: from.ID}, claimsFor(bob))
+	wantAppError(t, err, 400)
+
+	var stored models.MoneyRequest
+	if err := db.First(&stored, request.ID).Error; err != nil || stored.Status != MoneyRequestPending {
+		t.Errorf("request after the failed payment = %q, %v; want pending", stored.Status, err)
+	}
+}
+
+func TestRejectMoneyRequestMovesNothing(t *testing.T) {
+	db := testDB(t)
+	alice, bob := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+	into, from := seedAccount(t, db, alice, 0, "USD"), seedAccount(t, db, bob, 100, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{})
+
+	request, err := s.RequestMoney(&models.MoneyRequestCreate{AccountID: into.ID, Username: "bob", Amount: 40}, claimsFor(alice))
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	_, err = s.RejectMoneyRequest(request.ID, claimsFor(alice))
+	wantAppError(t, err, 404)
+
+	rejected, err := s.RejectMoneyRequest(request.ID, claimsFor(bob))
+	if err != nil {
+		t.Fatalf("reject: %v", err)
+	}
+	if rejected.Status != MoneyRequestRejected || rejected.ResolvedAt == nil || rejected.TransactionID != nil {
+		t.Errorf("rejected request = %+v", rejected)
+	}
+	if payer, requester := reloadAccount(t, db, from.ID), reloadAccount(t, db, into.ID); payer.Balance != 100 || requester.Balance != 0 {
+		t.Errorf("balances after the rejection = %v and %v, want 100 and 0", payer.Balance, requester.Balance)
+	}
+	var transfers int64
+	if err := db.Model(&models.Transaction{}).Where("type = ?", "transfer").Count(&transfers).Error; err != nil || transfers != 0 {
+		t.Errorf("%d transfers recorded, %v; want none", transfers, err)
+	}
+
+	_, _, err = s.PayMoneyRequest(request.ID, &models.MoneyRequestPayment{FromID: from.ID}, claimsFor(bob))
+	wantAppError(t, err, 409)
+}
+
+func TestMoneyRequestExpiry(t *testing.T) {
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	clock := utils.NewManualClock(created)
+	s := NewTransactionService(nil, testSecret, TransactionConfig{Clock: clock, MoneyRequestTTL: 24 * time.Hour}).(*transactionService)
+	request := &models.MoneyRequest{Status: MoneyRequestPending, CreatedAt: created}
+
+	clock.Advance(24*time.Hour - time.Second)
+	if s.moneyRequestExpired(request) {
+		t.Error("request expired before its TTL")
+	}
+	clock.Advance(time.Second)
+	if !s.moneyRequestExpired(request) {
+		t.Error("request not expired at its TTL")
+	}
+
+	// Without a TTL requests never expire, and the sweeper has nothing to do.
+	never := NewTransactionService(nil, testSecret, TransactionConfig{Clock: clock}).(*transactionService)
+	if never.moneyRequestExpired(request) {
+		t.Error("request expired without a TTL")
+	}
+	if n, err := never.ExpireMoneyRequests(); n != 0 || err != nil {
+		t.Errorf("ExpireMoneyRequests without a TTL = %d, %v", n, err)
+	}
+}
+
+func TestExpiredMoneyRequestCannotBePaid(t *testing.T) {
+	db := testDB(t)
+	alice, bob := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+	into, from := seedAccount(t, db, alice, 0, "USD"), seedAccount(t, db, bob, 100, "USD")
+	clock := utils.NewManualClock(time.Now())
+	s := NewTransactionService(db, testSecret, TransactionConfig{Clock: clock, MoneyRequestTTL: 24 * time.Hour})
+
+	request, err := s.RequestMoney(&models.MoneyRequestCreate{AccountID: into.ID, Username: "bob", Amount: 40}, claimsFor(alice))
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	clock.Advance(25 * time.Hour)
+
+	// Listed as expired before the sweeper runs.
+	requests, err := s.GetMoneyRequests(uint(bob.ID))
+	if err != nil || len(requests) != 1 || requests[0].Status != MoneyRequestExpired {
+		t.Errorf("requests = %+v, %v; want one expired", requests, err)
+	}
+
+	_, _, err = s.PayMoneyRequest(request.ID, &models.MoneyRequestPayment{FromID: from.ID}, claimsFor(bob))
+	if appErr := wantAppError(t, err, 409); appErr.Message != "Money request has expired" {
+		t.Errorf("message = %q", appErr.Message)
+	}
+	_, err = s.RejectMoneyRequest(request.ID, claimsFor(bob))
+	wantAppError(t, err, 409)
+
+	var stored models.MoneyRequest
+	if err := db.First(&stored, request.ID).Error; err != nil || stored.Status != MoneyRequestExpired || stored.ResolvedAt == nil {
+		t.Errorf("stored request = %+v, %v; want expired", stored, err)
+	}
+	if payer := reloadAccount(t, db, from.ID); payer.Balance != 100 {
+		t.Errorf("payer balance = %v, want 100", payer.Balance)
+	}
+}
+
+func TestExpireMoneyRequestsSweepsOldOnes(t *testing.T) {
+	db := testDB(t)
+	alice, _ := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+	into := seedAccount(t, db, alice, 0, "USD")
+	clock := utils.NewManualClock(time.Now())
+	s := NewTransactionService(db, testSecret, TransactionConfig{Clock: clock, MoneyRequestTTL: 24 * time.Hour})
+
+	old, err := s.RequestMoney(&models.MoneyRequestCreate{AccountID: into.ID, Username: "bob", Amount: 5}, claimsFor(alice))
+	if err != nil {
+		t.Fatalf("old request: %v", err)
+	}
+	clock.Advance(20 * time.Hour)
+	fresh, err := s.RequestMoney(&models.MoneyRequestCreate{AccountID: into.ID, Username: "bob", Amount: 5}, claimsFor(alice))
+	if err != nil {
+		t.Fatalf("fresh request: %v", err)
+	}
+	clock.Advance(5 * time.Hour)
+
+	if n, err := s.ExpireMoneyRequests(); n != 1 || err != nil {
+		t.Fatalf("ExpireMoneyRequests = %d, %v; want 1", n, err)
+	}
+	for id, want := range map[int]string{old.ID: MoneyRequestExpired, fresh.ID: MoneyRequestPending} {
+		var stored models.MoneyRequest
+		if err := db.First(&stored, id).Error; err != nil || stored.Status != want {
+			t.Errorf("request %d = %q, %v; want %s", id, stored.Status, err, want)
+		}
+	}
+}
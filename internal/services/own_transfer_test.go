@@ -0,0 +1,71 @@
+// Path: internal/services/own_transfer_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"testing"
+)
+
+func TestOwnTransferFastPath(t *testing.T) {
+	db := testDB(t)
+	alice, bob := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+	checking, savings := seedAccount(t, db, alice, 200, "USD"), seedAccount(t, db, alice, 0, "USD")
+	other := seedAccount(t, db, bob, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{
+		OwnTransferFastPath: true,
+		Fees:                FeeConfig{Flat: 1, Accounts: seedFeeAccounts(t, db, "USD")},
+		ApprovalThreshold:   50,
+		DailySpendingLimit:  100,
+	})
+	transfer := func(from, to *models.Account, amount float64) *models.TransferRequest {
+		t.Helper()
+		req := &models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: amount}
+		if err := s.ProcessTransfer(req, claimsFor(alice)); err != nil {
+			t.Fatalf("transfer %v to account %d: %v", amount, to.ID, err)
+		}
+		return req
+	}
+
+	// Between own accounts: no fee, no approval, and the daily limit neither applies nor runs down.
+	for _, req := range []*models.TransferRequest{transfer(checking, savings, 80), transfer(savings, checking, 80), transfer(checking, savings, 80)} {
+		if req.Status != "completed" || req.Fee != 0 {
+			t.Errorf("own transfer = %s with fee %v, want completed without a fee", req.Status, req.Fee)
+		}
+	}
+	if got := reloadAccount(t, db, savings.ID).Balance; got != 80 {
+		t.Errorf("savings balance = %v, want 80", got)
+	}
+
+	// To another user: the fee and the approval threshold apply.
+	req := transfer(checking, other, 30)
+	if req.Status != "completed" || req.Fee != 1 {
+		t.Errorf("transfer to bob = %s with fee %v, want completed with a fee of 1", req.Status, req.Fee)
+	}
+	req = transfer(checking, other, 60)
+	if req.Status != "pending_approval" {
+		t.Errorf("large transfer to bob = %s, want pending_approval", req.Status)
+	}
+	if got := reloadAccount(t, db, checking.ID).Balance; got != 89 {
+		t.Errorf("checking balance = %v, want 89", got)
+	}
+	for _, id := range []int{checking.ID, savings.ID} {
+		if stored := reloadAccount(t, db, id); stored.BalanceHash != balanceHash(stored, testSecret) {
+			t.Errorf("account %d hash does not match its balance", id)
+		}
+	}
+}
+
+func TestOwnTransferWithoutTheFastPath(t *testing.T) {
+	db := testDB(t)
+	alice := seedUser(t, db, "alice")
+	checking, savings := seedAccount(t, db, alice, 200, "USD"), seedAccount(t, db, alice, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{Fees: FeeConfig{Flat: 1, Accounts: seedFeeAccounts(t, db, "USD")}, ApprovalThreshold: 50})
+
+	req := &models.TransferRequest{FromID: checking.ID, ToID: savings.ID, Amount: 60}
+	if err := s.ProcessTransfer(req, claimsFor(alice)); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	if req.Status != "pending_approval" || req.Fee != 1 {
+		t.Errorf("own transfer without the fast path = %s with fee %v", req.Status, req.Fee)
+	}
+}
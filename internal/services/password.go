@@ -0,0 +1,115 @@
+// Path: internal/services/password.go
+package services
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Supported password hashing algorithms.
+const (
+	PasswordAlgoBcrypt   = "bcrypt"
+	PasswordAlgoArgon2id = "argon2id"
+)
+
+// argon2id parameters for new hashes. Stored hashes carry their own parameters.
+const (
+	argon2Time    = 1
+	argon2Memory  = 64 * 1024
+	argon2Threads = 4
+	argon2KeyLen  = 32
+	argon2SaltLen = 16
+)
+
+var (
+	errPasswordMismatch      = errors.New("password does not match")
+	errUnknownPasswordAlgo   = errors.New("unknown password hash algorithm")
+	errMalformedPasswordHash = errors.New("malformed password hash")
+)
+
+// IsSupportedPasswordAlgo reports whether algo can be used for new registrations.
+func IsSupportedPasswordAlgo(algo string) bool {
+	return algo == PasswordAlgoBcrypt || algo == PasswordAlgoArgon2id
+}
+
+// hashPassword hashes a password with the given algorithm. The result carries
+// an algorithm prefix ("$2a$" for bcrypt, "$argon2id$" for argon2id).
+func hashPassword(algo, password string) (string, error) {
+	switch algo {
+	case PasswordAlgoBcrypt, "":
+		hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return "", err
+		}
+		return string(hashed), nil
+	case PasswordAlgoArgon2id:
+		salt := make([]byte, argon2SaltLen)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+		key := argon2.IDKey([]byte(password), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+			argon2.Version, argon2Memory, argon2Time, argon2Threads,
+			base64.RawStdEncoding.EncodeToString(salt),
+			base64.RawStdEncoding.EncodeToString(key)), nil
+	default:
+		return "", errUnknownPasswordAlgo
+	}
+}
+
+// verifyPassword checks a password against a stored hash, picking the algorithm from the hash prefix.
+func verifyPassword(hash, password string) error {
+	switch {
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+			return errPasswordMismatch
+		}
+		return nil
+	case strings.HasPrefix(hash, "$argon2id$"):
+		return verifyArgon2id(hash, password)
+	default:
+		return errUnknownPasswordAlgo
+	}
+}
+
+func verifyArgon2id(hash, password string) error {
+	// $argon2id$v=19$m=65536,t=1,p=4$<salt>$<key>
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return errMalformedPasswordHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return errMalformedPasswordHash
+	}
+
+	var memory, time uint32
+	var threads uint8
+	// argon2.IDKey panics on zero time or threads, so a tampered hash must not reach it.
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil || memory == 0 || time == 0 || threads == 0 {
+		return errMalformedPasswordHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return errMalformedPasswordHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return errMalformedPasswordHash
+	}
+
+	computed := argon2.IDKey([]byte(password), salt, time, memory, threads, uint32(len(key)))
+	if subtle.ConstantTimeCompare(computed, key) != 1 {
+		return errPasswordMismatch
+	}
+	return nil
+}
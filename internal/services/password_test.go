@@ -0,0 +1,92 @@
+// Path: internal/services/password_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestHashAndVerifyPassword(t *testing.T) {
+	for _, algo := range []string{PasswordAlgoBcrypt, PasswordAlgoArgon2id} {
+		t.Run(algo, func(t *testing.T) {
+			hash, err := hashPassword(algo, "correct horse")
+			if err != nil {
+				t.Fatalf("hash: %v", err)
+			}
+			if err := verifyPassword(hash, "correct horse"); err != nil {
+				t.Errorf("verify: %v", err)
+			}
+			if err := verifyPassword(hash, "wrong"); !errors.Is(err, errPasswordMismatch) {
+				t.Errorf("verify wrong password = %v, want errPasswordMismatch", err)
+			}
+		})
+	}
+}
+
+func TestVerifyPasswordRejectsUnknownPrefix(t *testing.T) {
+	for _, hash := range []string{"", "plaintext", "$scrypt$ln=15$c2FsdA$a2V5", "$argon2i$v=19$m=65536,t=1,p=4$c2FsdA$a2V5"} {
+		if err := verifyPassword(hash, "password"); !errors.Is(err, errUnknownPasswordAlgo) {
+			t.Errorf("verifyPassword(%q) = %v, want errUnknownPasswordAlgo", hash, err)
+		}
+	}
+	if _, err := hashPassword("md5", "password"); !errors.Is(err, errUnknownPasswordAlgo) {
+		t.Errorf("hashPassword(md5) = %v, want errUnknownPasswordAlgo", err)
+	}
+}
+
+func TestVerifyArgon2idRejectsBadParameters(t *testing.T) {
+	hash, err := hashPassword(PasswordAlgoArgon2id, "password")
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	params := strings.Split(hash, "$")[3]
+	for _, bad := range []string{"m=65536,t=1,p=0", "m=65536,t=0,p=4", "m=0,t=1,p=4", "m=65536,t=1"} {
+		tampered := strings.Replace(hash, params, bad, 1)
+		if err := verifyPassword(tampered, "password"); !errors.Is(err, errMalformedPasswordHash) {
+			t.Errorf("params %q: %v, want errMalformedPasswordHash", bad, err)
+		}
+	}
+}
+
+func TestLoginAcrossPasswordAlgorithms(t *testing.T) {
+	db := testDB(t)
+	bcryptAuth := NewAuthService(db, "test-jwt-secret-of-sufficient-length", AuthConfig{PasswordAlgo: PasswordAlgoBcrypt})
+	argonAuth := NewAuthService(db, "test-jwt-secret-of-sufficient-length", AuthConfig{PasswordAlgo: PasswordAlgoArgon2id})
+
+	if err := bcryptAuth.Register("bcryptuser", "password1"); err != nil {
+		t.Fatalf("register bcrypt: %v", err)
+	}
+	if err := argonAuth.Register("argonuser", "password2"); err != nil {
+		t.Fatalf("register argon2id: %v", err)
+	}
+
+	var argonUser models.User
+	if err := db.Where("username = ?", "argonuser").First(&argonUser).Error; err != nil {
+		t.Fatalf("load user: %v", err)
+	}
+	if !strings.HasPrefix(argonUser.Password, "$argon2id$") {
+		t.Errorf("argon2id registration stored %q", argonUser.Password)
+	}
+
+	// Verification follows the stored hash, not the configured algorithm.
+	for _, auth := range []AuthService{bcryptAuth, argonAuth} {
+		if _, err := auth.Login("bcryptuser", "password1", models.LoginMeta{}); err != nil {
+			t.Errorf("login bcrypt user: %v", err)
+		}
+		if _, err := auth.Login("argonuser", "password2", models.LoginMeta{}); err != nil {
+			t.Errorf("login argon2id user: %v", err)
+		}
+		if _, err := auth.Login("argonuser", "password1", models.LoginMeta{}); err == nil {
+			t.Error("login with a wrong password succeeded")
+		}
+	}
+
+	if err := db.Model(&models.User{}).Where("id = ?", argonUser.ID).Update("password", "$unknown$hash").Error; err != nil {
+		t.Fatalf("tamper: %v", err)
+	}
+	if _, err := argonAuth.Login("argonuser", "password2", models.LoginMeta{}); err == nil {
+		t.Error("login with an unknown hash prefix succeeded")
+	}
+}
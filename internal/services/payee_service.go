@@ -0,0 +1,146 @@
+// Path: internal/services/payee_service.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// MaxPayeeLabelLength is the maximum payee label length in characters.
+const MaxPayeeLabelLength = 50
+
+// PayeeService handles saved transfer recipients.
+type PayeeService interface {
+	CreatePayee(req *models.PayeeRequest, claims *models.Claims) (*models.Payee, error)
+	GetPayees(userID uint) ([]models.Payee, error)
+	GetPayee(userID uint, payeeID int) (*models.Payee, error)
+	DeletePayee(payeeID int, claims *models.Claims) error
+}
+
+type payeeService struct {
+	db        *gorm.DB
+	maxPayees int
+	clock     utils.Clock
+}
+
+// NewPayeeService creates a new PayeeService. maxPayees <= 0 means unlimited.
+func NewPayeeService(db *gorm.DB, maxPayees int, clock utils.Clock) PayeeService {
+	return &payeeService{
+		db:        db,
+		maxPayees: maxPayees,
+		clock:     clock,
+	}
+}
+
+// WithContext returns a copy of the service whose queries run under ctx, so a request's deadline
+// and cancellation reach the database.
+func (s *payeeService) WithContext(ctx context.Context) PayeeService {
+	bound := *s
+	bound.db = dbWithContext(s.db, ctx)
+	return &bound
+}
+
+// CreatePayee saves a recipient after checking that its account exists.
+func (s *payeeService) CreatePayee(req *models.PayeeRequest, claims *models.Claims) (*models.Payee, error) {
+	if err := validatePayeeRequest(req); err != nil {
+		return nil, err
+	}
+
+	payee := models.Payee{
+		UserID:    int(claims.UserID),
+		AccountID: req.AccountID,
+		Label:     req.Label,
+		CreatedAt: s.clock.Now(),
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.Account{}).Where("id = ?", req.AccountID).Count(&count).Error; err != nil {
+			return &AppError{Code: 500, Message: "Failed to query account", Details: err.Error(), Err: err}
+		}
+		if count == 0 {
+			return &AppError{Code: 404, Message: "Payee account not found", Details: fmt.Sprintf("account_id: %d", req.AccountID)}
+		}
+
+		if err := tx.Model(&models.Payee{}).Where("user_id = ? AND account_id = ?", claims.UserID, req.AccountID).Count(&count).Error; err != nil {
+			return &AppError{Code: 500, Message: "Failed to query payees", Details: err.Error(), Err: err}
+		}
+		if count > 0 {
+			return &AppError{Code: 409, Message: "Payee already exists", Details: fmt.Sprintf("account_id: %d", req.AccountID)}
+		}
+
+		if s.maxPayees > 0 {
+			if err := tx.Model(&models.Payee{}).Where("user_id = ?", claims.UserID).Count(&count).Error; err != nil {
+				return &AppError{Code: 500, Message: "Failed to query payees", Details: err.Error(), Err: err}
+			}
+			if count >= int64(s.maxPayees) {
+				return &AppError{Code: 400, Message: "Too many payees", Details: fmt.Sprintf("A user can save at most %d payees", s.maxPayees)}
+			}
+		}
+
+		if err := tx.Create(&payee).Error; err != nil {
+			return &AppError{Code: 500, Message: "Failed to save payee", Details: err.Error(), Err: err}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &payee, nil
+}
+
+// GetPayees lists the saved recipients of a user.
+func (s *payeeService) GetPayees(userID uint) ([]models.Payee, error) {
+	payees := []models.Payee{}
+	if err := s.db.Where("user_id = ?", userID).Order("label").Find(&payees).Error; err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to query payees", Details: err.Error(), Err: err}
+	}
+
+	return payees, nil
+}
+
+// GetPayee retrieves a single saved recipient of a user.
+func (s *payeeService) GetPayee(userID uint, payeeID int) (*models.Payee, error) {
+	var payee models.Payee
+	if err := s.db.Where("id = ? AND user_id = ?", payeeID, userID).First(&payee).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &AppError{Code: 404, Message: "Payee not found", Details: fmt.Sprintf("payee_id: %d", payeeID)}
+		}
+		return nil, &AppError{Code: 500, Message: "Failed to query payee", Details: err.Error(), Err: err}
+	}
+
+	return &payee, nil
+}
+
+// DeletePayee removes a saved recipient. Transfers store the destination account itself,
+// so past transfers made through the payee are unaffected.
+func (s *payeeService) DeletePayee(payeeID int, claims *models.Claims) error {
+	result := s.db.Where("id = ? AND user_id = ?", payeeID, claims.UserID).Delete(&models.Payee{})
+	if result.Error != nil {
+		return &AppError{Code: 500, Message: "Failed to delete payee", Details: result.Error.Error(), Err: result.Error}
+	}
+	if result.RowsAffected == 0 {
+		return &AppError{Code: 404, Message: "Payee not found", Details: fmt.Sprintf("payee_id: %d", payeeID)}
+	}
+
+	return nil
+}
+
+// resolvePayee returns the account of a payee owned by the user.
+func resolvePayee(tx *gorm.DB, payeeID int, userID uint) (int, error) {
+	var payee models.Payee
+	if err := tx.Where("id = ? AND user_id = ?", payeeID, userID).First(&payee).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, &AppError{Code: 404, Message: "Payee not found", Details: fmt.Sprintf("payee_id: %d", payeeID)}
+		}
+		return 0, &AppError{Code: 500, Message: "Failed to query payee", Details: err.Error(), Err: err}
+	}
+
+	return payee.AccountID, nil
+}
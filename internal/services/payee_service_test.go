@@ -0,0 +1,71 @@
+// Path: internal/services/payee_service_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"testing"
+)
+
+func TestTransferToPayee(t *testing.T) {
+	db := testDB(t)
+	alice, bob := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+	from, to := seedAccount(t, db, alice, 100, "USD"), seedAccount(t, db, bob, 0, "USD")
+	payees := NewPayeeService(db, 0, utils.RealClock)
+	transactions := NewTransactionService(db, testSecret, TransactionConfig{})
+
+	payee, err := payees.CreatePayee(&models.PayeeRequest{AccountID: to.ID, Label: " Bob "}, claimsFor(alice))
+	if err != nil {
+		t.Fatalf("create payee: %v", err)
+	}
+	if payee.Label != "Bob" || payee.AccountID != to.ID {
+		t.Errorf("payee = %+v", payee)
+	}
+	_, err = payees.CreatePayee(&models.PayeeRequest{AccountID: to.ID + 1000, Label: "Nobody"}, claimsFor(alice))
+	wantAppError(t, err, 404)
+
+	req := &models.TransferRequest{FromID: from.ID, PayeeID: payee.ID, Amount: 30}
+	if err := transactions.ProcessTransfer(req, claimsFor(alice)); err != nil {
+		t.Fatalf("transfer to payee: %v", err)
+	}
+	if req.ToID != to.ID || reloadAccount(t, db, to.ID).Balance != 30 {
+		t.Errorf("transfer went to account %d, want %d with 30", req.ToID, to.ID)
+	}
+
+	// Another user can't use the payee.
+	err = transactions.ProcessTransfer(&models.TransferRequest{FromID: to.ID, PayeeID: payee.ID, Amount: 1}, claimsFor(bob))
+	wantAppError(t, err, 404)
+}
+
+func TestDeletePayeeOfPendingTransfer(t *testing.T) {
+	db := testDB(t)
+	alice, bob, admin := seedUser(t, db, "alice"), seedUser(t, db, "bob"), seedAdmin(t, db, "admin")
+	from, to := seedAccount(t, db, alice, 500, "USD"), seedAccount(t, db, bob, 0, "USD")
+	payees := NewPayeeService(db, 0, utils.RealClock)
+	transactions := NewTransactionService(db, testSecret, TransactionConfig{ApprovalThreshold: 100})
+
+	payee, err := payees.CreatePayee(&models.PayeeRequest{AccountID: to.ID, Label: "Bob"}, claimsFor(alice))
+	if err != nil {
+		t.Fatalf("create payee: %v", err)
+	}
+	req := &models.TransferRequest{FromID: from.ID, PayeeID: payee.ID, Amount: 200}
+	if err := transactions.ProcessTransfer(req, claimsFor(alice)); err != nil || req.Status != "pending_approval" {
+		t.Fatalf("transfer = %q, %v; want pending_approval", req.Status, err)
+	}
+
+	if err := payees.DeletePayee(payee.ID, claimsFor(alice)); err != nil {
+		t.Fatalf("delete payee of a pending transfer: %v", err)
+	}
+	wantAppError(t, payees.DeletePayee(payee.ID, claimsFor(alice)), 404)
+
+	// The pending transfer kept the destination account, so it still completes.
+	if err := transactions.ApproveTransfer(req.TransactionID, claimsFor(admin)); err != nil {
+		t.Fatalf("approve after the payee was deleted: %v", err)
+	}
+	if got := reloadAccount(t, db, to.ID).Balance; got != 200 {
+		t.Errorf("recipient balance = %v, want 200", got)
+	}
+
+	err = transactions.ProcessTransfer(&models.TransferRequest{FromID: from.ID, PayeeID: payee.ID, Amount: 1}, claimsFor(alice))
+	wantAppError(t, err, 404)
+}
@@ -0,0 +1,163 @@
+// Path: internal/services/precision.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// defaultDecimals is used for currencies missing from the registry.
+const defaultDecimals = 2
+
+// currencies is the registry of ISO 4217 minor units and display symbols. Currencies missing
+// from it use 2 decimal places and their code as symbol.
+var currencies = map[string]models.Currency{
+	"BHD": {Code: "BHD", Decimals: 3, Symbol: "BD"},
+	"CHF": {Code: "CHF", Decimals: 2, Symbol: "CHF"},
+	"CLP": {Code: "CLP", Decimals: 0, Symbol: "CLP$"},
+	"CNY": {Code: "CNY", Decimals: 2, Symbol: "CN¥"},
+	"EUR": {Code: "EUR", Decimals: 2, Symbol: "€"},
+	"GBP": {Code: "GBP", Decimals: 2, Symbol: "£"},
+	"ISK": {Code: "ISK", Decimals: 0, Symbol: "kr"},
+	"JOD": {Code: "JOD", Decimals: 3, Symbol: "JD"},
+	"JPY": {Code: "JPY", Decimals: 0, Symbol: "¥"},
+	"KRW": {Code: "KRW", Decimals: 0, Symbol: "₩"},
+	"KWD": {Code: "KWD", Decimals: 3, Symbol: "KD"},
+	"OMR": {Code: "OMR", Decimals: 3, Symbol: "OMR"},
+	"RUB": {Code: "RUB", Decimals: 2, Symbol: "₽"},
+	"TND": {Code: "TND", Decimals: 3, Symbol: "DT"},
+	"USD": {Code: "USD", Decimals: 2, Symbol: "$"},
+	"VND": {Code: "VND", Decimals: 0, Symbol: "₫"},
+}
+
+// ParseCurrencyDecimals parses a list of decimal places per currency in the form "JPY:0,KWD:3".
+func ParseCurrencyDecimals(s string) (map[string]int, error) {
+	decimals := make(map[string]int)
+	if strings.TrimSpace(s) == "" {
+		return decimals, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		currency, value, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid currency decimals %q: expected CURRENCY:DECIMALS", pair)
+		}
+		places, err := strconv.Atoi(value)
+		if err != nil || places < 0 {
+			return nil, fmt.Errorf("invalid currency decimals %q: decimals must be a non-negative integer", pair)
+		}
+		decimals[strings.ToUpper(currency)] = places
+	}
+	return decimals, nil
+}
+
+// lookupCurrency returns the registry entry of currency, with configured decimal overrides applied.
+func lookupCurrency(currency string, overrides map[string]int) models.Currency {
+	c, ok := currencies[currency]
+	if !ok {
+		c = models.Currency{Code: currency, Decimals: defaultDecimals, Symbol: currency}
+	}
+	if places, ok := overrides[currency]; ok {
+		c.Decimals = places
+	}
+	return c
+}
+
+// listCurrencies returns the registry with overrides applied, including overridden currencies
+// missing from it, sorted by code.
+func listCurrencies(overrides map[string]int) []models.Currency {
+	list := make([]models.Currency, 0, len(currencies)+len(overrides))
+	for code := range currencies {
+		list = append(list, lookupCurrency(code, overrides))
+	}
+	for code := range overrides {
+		if _, ok := currencies[code]; !ok {
+			list = append(list, lookupCurrency(code, overrides))
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	return list
+}
+
+// currencyDecimals returns the number of decimal places allowed for currency, preferring configured overrides.
+func currencyDecimals(currency string, overrides map[string]int) int {
+	return lookupCurrency(currency, overrides).Decimals
+}
+
+// toMinorUnits converts an amount to the currency's minor units (cents for USD, yen for JPY), rounding
+// to the nearest unit.
+func toMinorUnits(amount float64, decimals int) int64 {
+	return int64(math.Round(amount * math.Pow10(decimals)))
+}
+
+// fromMinorUnits converts minor units back to an amount.
+func fromMinorUnits(minor int64, decimals int) float64 {
+	return float64(minor) / math.Pow10(decimals)
+}
+
+// roundAmount rounds an amount to the nearest minor unit, removing the float residue that sums of
+// amounts accumulate (0.1 + 0.2 is 0.30000000000000004).
+func roundAmount(amount float64, decimals int) float64 {
+	return fromMinorUnits(toMinorUnits(amount, decimals), decimals)
+}
+
+// formatAmount formats an amount with exactly the currency's decimal places, e.g. "1234.50".
+func formatAmount(amount float64, decimals int) string {
+	return strconv.FormatFloat(amount, 'f', decimals, 64)
+}
+
+// formatMoney formats an amount for display with the currency symbol and thousands separators,
+// e.g. "$1,234.50", "¥1,235" or "BD 1.234".
+func formatMoney(amount float64, currency string, overrides map[string]int) string {
+	c := lookupCurrency(currency, overrides)
+	digits := formatAmount(math.Abs(amount), c.Decimals)
+	whole, fraction, _ := strings.Cut(digits, ".")
+
+	var b strings.Builder
+	if amount < 0 && strings.Trim(digits, "0.") != "" {
+		b.WriteByte('-')
+	}
+	b.WriteString(c.Symbol)
+	// Letter symbols ("BD", "CHF") read better apart from the number.
+	if r, _ := utf8.DecodeLastRuneInString(c.Symbol); unicode.IsLetter(r) {
+		b.WriteByte(' ')
+	}
+	for i, d := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	if fraction != "" {
+		b.WriteByte('.')
+		b.WriteString(fraction)
+	}
+	return b.String()
+}
+
+// decimalPlaces counts the decimal places of the shortest representation of amount,
+// which is what the client sent as a JSON number.
+func decimalPlaces(amount float64) int {
+	s := strconv.FormatFloat(amount, 'f', -1, 64)
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return len(s) - i - 1
+	}
+	return 0
+}
+
+// checkAmountPrecision rejects amounts with more decimal places than the currency allows.
+func checkAmountPrecision(amount float64, currency string, overrides map[string]int) error {
+	var v validation
+	allowed := currencyDecimals(currency, overrides)
+	message := fmt.Sprintf("%s amounts allow at most %d decimal places", currency, allowed)
+	if allowed == 0 {
+		message = fmt.Sprintf("%s amounts must be whole numbers", currency)
+	}
+	v.check(decimalPlaces(amount) <= allowed, "amount", message)
+	return v.err()
+}
@@ -0,0 +1,227 @@
+// Path: internal/services/precision_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestCurrencyPrecision(t *testing.T) {
+	cases := []struct {
+		currency   string
+		decimals   int
+		valid      float64
+		tooPrecise float64
+		stored     float64 // Amount after the float residue of a sum
+		rounded    float64
+		display    string
+	}{
+		{"JPY", 0, 1500, 1500.5, 1234.4999999, 1234, "¥1,234"},
+		{"USD", 2, 10.25, 10.255, 0.1 + 0.2, 0.3, "$0.30"},
+		{"BHD", 3, 1.125, 1.1255, 1.0001 + 0.2339, 1.234, "BD 1.234"},
+	}
+	for _, c := range cases {
+		t.Run(c.currency, func(t *testing.T) {
+			if got := currencyDecimals(c.currency, nil); got != c.decimals {
+				t.Errorf("currencyDecimals = %d, want %d", got, c.decimals)
+			}
+			if err := checkAmountPrecision(c.valid, c.currency, nil); err != nil {
+				t.Errorf("checkAmountPrecision(%v) = %v, want nil", c.valid, err)
+			}
+			var appErr *AppError
+			if err := checkAmountPrecision(c.tooPrecise, c.currency, nil); !errors.As(err, &appErr) || appErr.Code != 400 || len(appErr.Fields) != 1 || appErr.Fields[0].Field != "amount" {
+				t.Errorf("checkAmountPrecision(%v) = %v, want a 400 on amount", c.tooPrecise, err)
+			}
+			if got := roundAmount(c.stored, c.decimals); got != c.rounded {
+				t.Errorf("roundAmount(%v) = %v, want %v", c.stored, got, c.rounded)
+			}
+			if got := formatMoney(c.rounded, c.currency, nil); got != c.display {
+				t.Errorf("formatMoney(%v) = %q, want %q", c.rounded, got, c.display)
+			}
+		})
+	}
+}
+
+func TestParseCurrencyDecimals(t *testing.T) {
+	overrides, err := ParseCurrencyDecimals("jpy:0, BHD:3,XTS:4")
+	if err != nil {
+		t.Fatalf("ParseCurrencyDecimals: %v", err)
+	}
+	if overrides["JPY"] != 0 || overrides["BHD"] != 3 || overrides["XTS"] != 4 {
+		t.Errorf("overrides = %v", overrides)
+	}
+	if got := currencyDecimals("XTS", overrides); got != 4 {
+		t.Errorf("currencyDecimals(XTS) = %d, want the override 4", got)
+	}
+	if got := currencyDecimals("XTS", nil); got != defaultDecimals {
+		t.Errorf("currencyDecimals(XTS) = %d, want the default %d", got, defaultDecimals)
+	}
+
+	for _, bad := range []string{"JPY", "JPY:x", "JPY:-1"} {
+		if _, err := ParseCurrencyDecimals(bad); err == nil {
+			t.Errorf("ParseCurrencyDecimals(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestFormatMoney(t *testing.T) {
+	cases := []struct {
+		amount   float64
+		currency string
+		want     string
+	}{
+		{1234567.5, "USD", "$1,234,567.50"},
+		{-12.5, "USD", "-$12.50"},
+		{-0.001, "USD", "$0.00"},
+		{1234567, "JPY", "¥1,234,567"},
+		{0.5, "BHD", "BD 0.500"},
+		{42, "XTS", "XTS 42.00"},
+	}
+	for _, c := range cases {
+		if got := formatMoney(c.amount, c.currency, nil); got != c.want {
+			t.Errorf("formatMoney(%v, %s) = %q, want %q", c.amount, c.currency, got, c.want)
+		}
+	}
+}
+
+// TestFeeRoundsToCurrency checks fees are rounded to the decimal places of the currency, not to cents.
+func TestFeeRoundsToCurrency(t *testing.T) {
+	fees := newFeeEngine(FeeConfig{Flat: 0.5, Percent: 1.25})
+	cases := []struct {
+		currency string
+		amount   float64
+		want     float64
+	}{
+		{"JPY", 1234, 16},      // 0.5 + 15.425
+		{"USD", 12.34, 0.65},   // 0.5 + 0.15425
+		{"BHD", 12.345, 0.654}, // 0.5 + 0.1543125
+	}
+	for _, c := range cases {
+		if got := fees.fee(c.amount, currencyDecimals(c.currency, nil)); got != c.want {
+			t.Errorf("fee(%v %s) = %v, want %v", c.amount, c.currency, got, c.want)
+		}
+	}
+}
+
+func TestDecimalPlacesOfJSONNumbers(t *testing.T) {
+	var amount float64
+	for input, want := range map[string]int{"10": 0, "10.0": 0, "10.5": 1, "10.99": 2, "10.999": 3, "0.1": 1, "1e-3": 3} {
+		if err := json.Unmarshal([]byte(input), &amount); err != nil {
+			t.Fatal(err)
+		}
+		if got := decimalPlaces(amount); got != want {
+			t.Errorf("decimalPlaces(%s) = %d, want %d", input, got, want)
+		}
+	}
+}
+
+func TestCheckAmountPrecisionHonoursOverrides(t *testing.T) {
+	overrides := map[string]int{"USD": 0}
+	if err := checkAmountPrecision(10.5, "USD", overrides); err == nil {
+		t.Error("10.5 USD accepted with USD overridden to whole numbers")
+	}
+	if err := checkAmountPrecision(10, "USD", overrides); err != nil {
+		t.Errorf("10 USD: %v", err)
+	}
+}
+
+func TestOverPreciseDepositLeavesBalanceUntouched(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "precise")
+	usd := seedAccount(t, db, user, 100, "USD")
+	jpy := seedAccount(t, db, user, 1000, "JPY")
+	s := NewTransactionService(db, testSecret, TransactionConfig{})
+
+	for _, req := range []models.TransactionRequest{{AccountID: usd.ID, Amount: 10.999}, {AccountID: jpy.ID, Amount: 1.5}} {
+		_, err := s.ProcessDeposit(&req, claimsFor(user))
+		wantAppError(t, err, 400)
+		_, err = s.ProcessWithdraw(&req, claimsFor(user))
+		wantAppError(t, err, 400)
+	}
+	if got := reloadAccount(t, db, usd.ID).Balance; got != 100 {
+		t.Errorf("USD balance = %v, want 100", got)
+	}
+	if got := reloadAccount(t, db, jpy.ID).Balance; got != 1000 {
+		t.Errorf("JPY balance = %v, want 1000", got)
+	}
+}
+
+func TestMinorUnitsPerCurrency(t *testing.T) {
+	for _, c := range []struct {
+		currency string
+		amount   float64
+	}{{"JPY", 1234}, {"USD", 12.34}, {"BHD", 1.234}} {
+		decimals := currencyDecimals(c.currency, nil)
+		minor := toMinorUnits(c.amount, decimals)
+		if minor != 1234 {
+			t.Errorf("toMinorUnits(%v %s) = %d, want 1234", c.amount, c.currency, minor)
+		}
+		if got := fromMinorUnits(minor, decimals); got != c.amount {
+			t.Errorf("fromMinorUnits(%d %s) = %v, want %v", minor, c.currency, got, c.amount)
+		}
+	}
+}
+
+func TestListCurrenciesAppliesOverrides(t *testing.T) {
+	list := listCurrencies(map[string]int{"USD": 0, "XTS": 4})
+	found := make(map[string]models.Currency)
+	for i, c := range list {
+		if i > 0 && list[i-1].Code >= c.Code {
+			t.Errorf("%s listed after %s", c.Code, list[i-1].Code)
+		}
+		found[c.Code] = c
+	}
+	if c := found["USD"]; c.Decimals != 0 || c.Symbol != "$" {
+		t.Errorf("USD = %+v, want the override of 0 decimals and its symbol", c)
+	}
+	if c := found["XTS"]; c.Decimals != 4 || c.Symbol != "XTS" {
+		t.Errorf("XTS = %+v, want the overridden currency listed with its code as symbol", c)
+	}
+	if c := found["BHD"]; c.Decimals != 3 {
+		t.Errorf("BHD = %+v, want 3 decimals", c)
+	}
+}
+
+func TestAmountsStoredPerCurrency(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "traveller")
+	s := NewTransactionService(db, testSecret, TransactionConfig{})
+	for _, c := range []struct {
+		currency        string
+		deposits        []float64
+		balance         float64
+		display         string
+		withdrawDisplay string
+	}{
+		{"JPY", []float64{1000, 234}, 1234, "¥1,234", "¥1,233"},
+		{"USD", []float64{0.1, 0.2}, 0.3, "$0.30", "$0.29"},
+		{"BHD", []float64{1.001, 0.233}, 1.234, "BD 1.234", "BD 1.233"},
+	} {
+		account := seedAccount(t, db, user, 0, c.currency)
+		var balance *models.AccountBalance
+		for _, amount := range c.deposits {
+			var err error
+			if balance, err = s.ProcessDeposit(&models.TransactionRequest{AccountID: account.ID, Amount: amount}, claimsFor(user)); err != nil {
+				t.Fatalf("%s deposit %v: %v", c.currency, amount, err)
+			}
+		}
+		if balance.Balance != c.balance || balance.Display != c.display {
+			t.Errorf("%s: balance %v displayed %q, want %v as %q", c.currency, balance.Balance, balance.Display, c.balance, c.display)
+		}
+		if got := reloadAccount(t, db, account.ID).Balance; got != c.balance {
+			t.Errorf("%s: stored balance %v, want %v", c.currency, got, c.balance)
+		}
+
+		// One minor unit of the currency.
+		unit := fromMinorUnits(1, currencyDecimals(c.currency, nil))
+		balance, err := s.ProcessWithdraw(&models.TransactionRequest{AccountID: account.ID, Amount: unit}, claimsFor(user))
+		if err != nil {
+			t.Fatalf("%s withdraw %v: %v", c.currency, unit, err)
+		}
+		if balance.Display != c.withdrawDisplay {
+			t.Errorf("%s: balance after withdrawing one unit displayed %q, want %q", c.currency, balance.Display, c.withdrawDisplay)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// Path: internal/services/rate_service.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"errors"
+	"fmt"
+)
+
+// RateService quotes exchange rates to clients.
+type RateService interface {
+	GetQuote(from, to string) (*models.RateQuote, error)
+}
+
+type rateService struct {
+	rates         ExchangeRateProvider
+	spreadPercent float64
+}
+
+// NewRateService creates a new RateService. spreadPercent is the margin applied to conversions.
+func NewRateService(rates ExchangeRateProvider, spreadPercent float64) RateService {
+	return &rateService{
+		rates:         rates,
+		spreadPercent: spreadPercent,
+	}
+}
+
+// GetQuote returns the current rate and spread for a currency pair.
+func (s *rateService) GetQuote(from, to string) (*models.RateQuote, error) {
+	if err := validateCurrencyPair(&from, &to); err != nil {
+		return nil, err
+	}
+
+	rate, err := s.rates.Rate(from, to)
+	if err != nil {
+		if errors.Is(err, ErrUnsupportedCurrencyPair) {
+			return nil, &AppError{Code: 404, Message: "Unsupported currency pair", Details: fmt.Sprintf("%s/%s", from, to)}
+		}
+		return nil, &AppError{Code: 502, Message: "Failed to fetch exchange rate", Details: err.Error(), Err: err}
+	}
+
+	clientRate := rate
+	if from != to {
+		clientRate = rate * (1 - s.spreadPercent/100)
+	}
+
+	return &models.RateQuote{
+		From:          from,
+		To:            to,
+		Rate:          rate,
+		SpreadPercent: s.spreadPercent,
+		ClientRate:    clientRate,
+	}, nil
+}
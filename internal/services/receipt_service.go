@@ -0,0 +1,97 @@
+// Path: internal/services/receipt_service.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"context"
+	"crypto/hmac"
+	"fmt"
+	"time"
+)
+
+// ReceiptService issues and verifies signed transaction receipts.
+type ReceiptService interface {
+	GetReceipt(transactionID string, claims *models.Claims) (*models.Receipt, error)
+	VerifyReceipt(receipt *models.Receipt) *models.ReceiptVerification
+}
+
+type receiptService struct {
+	transactions     TransactionRepository
+	secretKey        string
+	currencyDecimals map[string]int
+}
+
+// NewReceiptService creates a new ReceiptService. currencyDecimals overrides the decimal places of
+// currencies, as in TransactionConfig.
+func NewReceiptService(transactions TransactionRepository, secretKey string, currencyDecimals map[string]int) ReceiptService {
+	return &receiptService{
+		transactions:     transactions,
+		secretKey:        secretKey,
+		currencyDecimals: currencyDecimals,
+	}
+}
+
+// WithContext returns a copy of the service whose queries run under ctx.
+func (s *receiptService) WithContext(ctx context.Context) ReceiptService {
+	bound := *s
+	bound.transactions = transactionsWithContext(s.transactions, ctx)
+	return &bound
+}
+
+// GetReceipt returns a signed receipt for a transaction touching one of the user's accounts.
+func (s *receiptService) GetReceipt(transactionID string, claims *models.Claims) (*models.Receipt, error) {
+	transaction, err := s.transactions.FindVisible(transactionID, claims.UserID)
+	if err != nil {
+		return nil, transactionLookupError(err, transactionID)
+	}
+	currency, err := s.transactions.Currency(transaction)
+	if err != nil {
+		return nil, transactionLookupError(err, transactionID)
+	}
+
+	receipt := &models.Receipt{
+		TransactionID: transaction.ID,
+		Type:          transaction.Type,
+		Amount:        transaction.Amount,
+		Currency:      currency,
+		FromAccountID: transaction.FromAccountID,
+		ToAccountID:   transaction.ToAccountID,
+		Status:        transaction.Status,
+		CreatedAt:     transaction.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	receipt.Signature = s.sign(receipt)
+
+	return receipt, nil
+}
+
+// VerifyReceipt checks the signature of a submitted receipt. It never touches the database,
+// so nothing beyond the submitted fields is revealed.
+func (s *receiptService) VerifyReceipt(receipt *models.Receipt) *models.ReceiptVerification {
+	expected := s.sign(receipt)
+	submitted := *receipt
+	submitted.Signature = ""
+
+	return &models.ReceiptVerification{
+		Valid:   hmac.Equal([]byte(expected), []byte(receipt.Signature)),
+		Receipt: submitted,
+	}
+}
+
+// sign computes the HMAC over the canonical representation of a receipt.
+func (s *receiptService) sign(receipt *models.Receipt) string {
+	return utils.CreateHMAC("receipt:"+canonicalReceipt(receipt, currencyDecimals(receipt.Currency, s.currencyDecimals)), []byte(s.secretKey))
+}
+
+// canonicalReceipt renders the signed fields in a fixed order and format. The amount is signed in
+// minor units of the currency, so no digit it can hold goes unsigned.
+func canonicalReceipt(r *models.Receipt, decimals int) string {
+	optionalID := func(id *int) string {
+		if id == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%d", *id)
+	}
+	return fmt.Sprintf("%s|%s|%d|%s|%s|%s|%s|%s",
+		r.TransactionID, r.Type, toMinorUnits(r.Amount, decimals), r.Currency, optionalID(r.FromAccountID), optionalID(r.ToAccountID), r.Status, r.CreatedAt)
+}
@@ -0,0 +1,96 @@
+// Path: internal/services/receipt_service_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"testing"
+	"time"
+)
+
+// memTransactions is a TransactionRepository over transactions visible to user 1. Account 3 is
+// in KWD, every other one in USD.
+type memTransactions map[string]models.Transaction
+
+func (r memTransactions) FindVisible(transactionID string, userID uint) (*models.Transaction, error) {
+	transaction, ok := r[transactionID]
+	if !ok || userID != 1 {
+		return nil, ErrNotFound
+	}
+	return &transaction, nil
+}
+
+func (r memTransactions) Currency(transaction *models.Transaction) (string, error) {
+	if transaction.FromAccountID != nil && *transaction.FromAccountID == 3 {
+		return "KWD", nil
+	}
+	return "USD", nil
+}
+
+func TestVerifyReceipt(t *testing.T) {
+	from, to := 1, 2
+	s := NewReceiptService(memTransactions{"tx1": {
+		ID: "tx1", Type: "transfer", Amount: 25.5, FromAccountID: &from, ToAccountID: &to,
+		Status: "completed", CreatedAt: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+	}}, testSecret, nil)
+
+	receipt, err := s.GetReceipt("tx1", &models.Claims{UserID: 1})
+	if err != nil {
+		t.Fatalf("receipt: %v", err)
+	}
+	if !s.VerifyReceipt(receipt).Valid {
+		t.Error("genuine receipt reported invalid")
+	}
+
+	tampered := *receipt
+	tampered.Amount = 2550
+	if s.VerifyReceipt(&tampered).Valid {
+		t.Error("receipt with a changed amount reported valid")
+	}
+	tampered = *receipt
+	tampered.Currency = "EUR"
+	if s.VerifyReceipt(&tampered).Valid {
+		t.Error("receipt with a changed currency reported valid")
+	}
+	tampered = *receipt
+	tampered.ToAccountID = &from
+	if s.VerifyReceipt(&tampered).Valid {
+		t.Error("receipt with a changed recipient reported valid")
+	}
+
+	other := NewReceiptService(memTransactions{}, "another-secret", nil)
+	if other.VerifyReceipt(receipt).Valid {
+		t.Error("receipt verified with another secret")
+	}
+	if verification := s.VerifyReceipt(receipt); verification.Receipt.Signature != "" {
+		t.Error("verification echoes the signature")
+	}
+}
+
+func TestGetReceiptOfAnotherUsersTransaction(t *testing.T) {
+	s := NewReceiptService(memTransactions{"tx1": {ID: "tx1"}}, testSecret, nil)
+	_, err := s.GetReceipt("tx1", &models.Claims{UserID: 2})
+	wantAppError(t, err, 404)
+}
+
+func TestReceiptSignsEveryDecimalOfTheCurrency(t *testing.T) {
+	kuwaiti := 3
+	s := NewReceiptService(memTransactions{"tx1": {
+		ID: "tx1", Type: "withdraw", Amount: 12.345, FromAccountID: &kuwaiti,
+		Status: "completed", CreatedAt: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+	}}, testSecret, nil)
+
+	receipt, err := s.GetReceipt("tx1", &models.Claims{UserID: 1})
+	if err != nil {
+		t.Fatalf("receipt: %v", err)
+	}
+	if receipt.Currency != "KWD" || !s.VerifyReceipt(receipt).Valid {
+		t.Fatalf("receipt = %+v, want a valid KWD receipt", receipt)
+	}
+
+	// 12.346 and 12.345 both render as 12.35 with two decimals; KWD has three.
+	tampered := *receipt
+	tampered.Amount = 12.346
+	if s.VerifyReceipt(&tampered).Valid {
+		t.Error("receipt with a changed third decimal reported valid")
+	}
+}
@@ -0,0 +1,104 @@
+// Path: internal/services/refund.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// RefundTransfer sends part or all of a completed transfer back from the recipient to the sender,
+// as a "refund" transaction linked to the original. Refunds of a transfer can't add up to more
+// than its amount; the original keeps the running total in RefundedAmount. Only the owner of the
+// receiving account or an admin can refund.
+func (s *transactionService) RefundTransfer(transactionID string, req *models.RefundRequest, claims *models.Claims) (*models.Transaction, error) {
+	var v validation
+	v.check(req.Amount > 0, "amount", "Amount must be positive")
+	v.description("description", &req.Description)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	var refund models.Transaction
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		notFound := &AppError{Code: 404, Message: "Transfer not found", Details: fmt.Sprintf("transaction_id: %s", transactionID)}
+
+		var original models.Transaction
+		if err := tx.Where("id = ? AND type = ?", transactionID, "transfer").First(&original).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return notFound
+			}
+			return &AppError{Code: 500, Message: "Failed to query transaction", Details: err.Error(), Err: err}
+		}
+		if original.FromAccountID == nil || original.ToAccountID == nil {
+			return notFound
+		}
+
+		var recipient models.Account
+		if err := tx.First(&recipient, *original.ToAccountID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return notFound
+			}
+			return &AppError{Code: 500, Message: "Failed to query account", Details: err.Error(), Err: err}
+		}
+		if uint(recipient.UserID) != claims.UserID && claims.Role != models.RoleAdmin {
+			return notFound
+		}
+		if original.Status != "completed" {
+			return &AppError{Code: 409, Message: "Only completed transfers can be refunded", Details: fmt.Sprintf("transaction_id: %s, status: %s", transactionID, original.Status)}
+		}
+		if err := checkAmountPrecision(req.Amount, recipient.Currency, s.cfg.CurrencyDecimals); err != nil {
+			return err
+		}
+
+		// The condition keeps concurrent refunds from exceeding the original together.
+		result := tx.Model(&models.Transaction{}).
+			Where("id = ? AND refunded_amount + ? <= amount", original.ID, req.Amount).
+			Update("refunded_amount", gorm.Expr("refunded_amount + ?", req.Amount))
+		if result.Error != nil {
+			return &AppError{Code: 500, Message: "Failed to update refunded amount", Details: result.Error.Error(), Err: result.Error}
+		}
+		if result.RowsAffected == 0 {
+			return &AppError{Code: 400, Message: "Refund exceeds the transferred amount", Details: fmt.Sprintf("transaction_id: %s, amount: %f, already refunded: %f", original.ID, original.Amount, original.RefundedAmount)}
+		}
+
+		fromAccount, toAccount, err := s.loadTransferAccounts(tx, recipient.ID, *original.FromAccountID, req.Amount, uint(recipient.UserID), recipient.Sandbox, "")
+		if err != nil {
+			return err
+		}
+		if err := s.applyTransfer(tx, fromAccount, toAccount, req.Amount, 0); err != nil {
+			return err
+		}
+
+		description := req.Description
+		if description == "" {
+			description = "Refund of " + original.ID
+		}
+		initiatorID := int(claims.UserID)
+		refund = models.Transaction{
+			ID:            utils.GenerateTransactionID(s.clock.Now()),
+			FromAccountID: &fromAccount.ID,
+			ToAccountID:   &toAccount.ID,
+			Amount:        req.Amount,
+			Type:          "refund",
+			Status:        "completed",
+			Description:   description,
+			RefundOf:      &original.ID,
+			InitiatorID:   &initiatorID,
+			CreatedAt:     s.clock.Now(),
+		}
+		if err := tx.Create(&refund).Error; err != nil {
+			return &AppError{Code: 500, Message: "Failed to insert transaction record", Details: err.Error(), Err: err}
+		}
+
+		return s.recordTransferLegs(tx, &refund)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &refund, nil
+}
@@ -0,0 +1,78 @@
+// Path: internal/services/refund_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"testing"
+)
+
+func TestRefundTransferValidation(t *testing.T) {
+	s := NewTransactionService(nil, testSecret, TransactionConfig{})
+	for _, amount := range []float64{0, -5} {
+		_, err := s.RefundTransfer("tx", &models.RefundRequest{Amount: amount}, &models.Claims{UserID: 1})
+		wantAppError(t, err, 400)
+	}
+}
+
+func TestPartialRefunds(t *testing.T) {
+	db := testDB(t)
+	alice := seedUser(t, db, "alice")
+	bob := seedUser(t, db, "bob")
+	from := seedAccount(t, db, alice, 100, "USD")
+	to := seedAccount(t, db, bob, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{})
+
+	req := &models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: 60}
+	if err := s.ProcessTransfer(req, claimsFor(alice)); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	refund := func(user *models.User, amount float64) (*models.Transaction, error) {
+		return s.RefundTransfer(req.TransactionID, &models.RefundRequest{Amount: amount}, claimsFor(user))
+	}
+	balances := func() (float64, float64) {
+		return reloadAccount(t, db, from.ID).Balance, reloadAccount(t, db, to.ID).Balance
+	}
+
+	// Only the recipient refunds.
+	_, err := refund(alice, 10)
+	wantAppError(t, err, 404)
+
+	first, err := refund(bob, 25)
+	if err != nil {
+		t.Fatalf("partial refund: %v", err)
+	}
+	if first.Type != "refund" || first.RefundOf == nil || *first.RefundOf != req.TransactionID || first.Amount != 25 ||
+		*first.FromAccountID != to.ID || *first.ToAccountID != from.ID {
+		t.Errorf("refund = %+v", first)
+	}
+	if a, b := balances(); a != 65 || b != 35 {
+		t.Errorf("balances after the partial refund = %v, %v; want 65, 35", a, b)
+	}
+
+	// Refunds can't add up to more than the transfer.
+	_, err = refund(bob, 35.01)
+	if appErr := wantAppError(t, err, 400); appErr.Message != "Refund exceeds the transferred amount" {
+		t.Errorf("over-refund: %q", appErr.Message)
+	}
+	if _, err := refund(bob, 35); err != nil {
+		t.Fatalf("refund of the remainder: %v", err)
+	}
+	_, err = refund(bob, 0.01)
+	wantAppError(t, err, 400)
+
+	var original models.Transaction
+	if err := db.First(&original, "id = ?", req.TransactionID).Error; err != nil {
+		t.Fatalf("load transfer: %v", err)
+	}
+	if original.RefundedAmount != 60 {
+		t.Errorf("refunded amount = %v, want 60", original.RefundedAmount)
+	}
+	if a, b := balances(); a != 100 || b != 0 {
+		t.Errorf("balances after refunding everything = %v, %v; want 100, 0", a, b)
+	}
+	for _, id := range []int{from.ID, to.ID} {
+		if stored := reloadAccount(t, db, id); stored.BalanceHash != balanceHash(stored, testSecret) {
+			t.Errorf("account %d hash does not match its balance", id)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Path: internal/services/registration_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestIsUniqueViolation(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&pgconn.PgError{Code: pgUniqueViolation}, true},
+		{fmt.Errorf("insert: %w", &pgconn.PgError{Code: pgUniqueViolation}), true},
+		{&pgconn.PgError{Code: "23503"}, false}, // Foreign key violation
+		{errors.New("duplicate key value violates unique constraint"), false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := isUniqueViolation(tt.err); got != tt.want {
+			t.Errorf("isUniqueViolation(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestConcurrentDuplicateRegistrations(t *testing.T) {
+	db := testDB(t)
+	auth := NewAuthService(db, testJWTSecret, AuthConfig{})
+
+	const attempts = 10
+	errs := make(chan error, attempts)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			errs <- auth.Register("racer", "password1")
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	succeeded := 0
+	for err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		if appErr := wantAppError(t, err, 400); appErr.Message != "User already exists" {
+			t.Errorf("duplicate registration = %q, want User already exists", appErr.Message)
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("%d registrations succeeded, want exactly 1", succeeded)
+	}
+
+	var count int64
+	db.Model(&models.User{}).Where("username = ?", "racer").Count(&count)
+	if count != 1 {
+		t.Errorf("%d users named racer, want 1", count)
+	}
+}
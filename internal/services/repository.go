@@ -0,0 +1,171 @@
+// Path: internal/services/repository.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrNotFound is returned by repositories when no record matches.
+var ErrNotFound = errors.New("record not found")
+
+// AccountRepository loads and updates accounts. Services translate its errors into AppErrors.
+type AccountRepository interface {
+	// ListByUser returns the sandbox or the real accounts of a user.
+	ListByUser(userID uint, sandbox bool) ([]models.Account, error)
+	// FindOwned returns a sandbox or a real account of the user, or ErrNotFound if it doesn't exist,
+	// isn't theirs or is of the other mode.
+	FindOwned(userID uint, accountID int, sandbox bool) (*models.Account, error)
+	// Find returns any account, or ErrNotFound.
+	Find(accountID int) (*models.Account, error)
+	UpdateNickname(accountID int, nickname string) error
+}
+
+// UserRepository loads users. Deleted users are never found.
+type UserRepository interface {
+	// Find returns a user, or ErrNotFound.
+	Find(userID uint) (*models.User, error)
+	// FindByUsername returns the user with the username, preferring an exact match over one
+	// differing in case, or ErrNotFound.
+	FindByUsername(username string) (*models.User, error)
+}
+
+// TransactionRepository loads transactions.
+type TransactionRepository interface {
+	// FindVisible returns a transaction touching one of the user's accounts, or ErrNotFound.
+	FindVisible(transactionID string, userID uint) (*models.Transaction, error)
+	// Currency returns the currency of a transaction, that of the accounts it moved money between,
+	// or ErrNotFound when neither account exists anymore.
+	Currency(transaction *models.Transaction) (string, error)
+}
+
+// NewAccountRepository creates an AccountRepository backed by db, which may be a transaction.
+func NewAccountRepository(db *gorm.DB) AccountRepository {
+	return &accountRepository{db: db}
+}
+
+// NewUserRepository creates a UserRepository backed by db, which may be a transaction.
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &userRepository{db: db}
+}
+
+// NewTransactionRepository creates a TransactionRepository backed by db, which may be a transaction.
+func NewTransactionRepository(db *gorm.DB) TransactionRepository {
+	return &transactionRepository{db: db}
+}
+
+// dbWithContext binds db to ctx. Services built on repositories alone have no db to bind.
+func dbWithContext(db *gorm.DB, ctx context.Context) *gorm.DB {
+	if db == nil {
+		return nil
+	}
+	return db.WithContext(ctx)
+}
+
+// accountsWithContext binds the default AccountRepository to ctx. Other implementations don't
+// query the database through gorm and are returned as they are; so are userRepository and
+// transactionRepository below.
+func accountsWithContext(r AccountRepository, ctx context.Context) AccountRepository {
+	if repo, ok := r.(*accountRepository); ok {
+		return &accountRepository{db: dbWithContext(repo.db, ctx)}
+	}
+	return r
+}
+
+func usersWithContext(r UserRepository, ctx context.Context) UserRepository {
+	if repo, ok := r.(*userRepository); ok {
+		return &userRepository{db: dbWithContext(repo.db, ctx)}
+	}
+	return r
+}
+
+func transactionsWithContext(r TransactionRepository, ctx context.Context) TransactionRepository {
+	if repo, ok := r.(*transactionRepository); ok {
+		return &transactionRepository{db: dbWithContext(repo.db, ctx)}
+	}
+	return r
+}
+
+type accountRepository struct {
+	db *gorm.DB
+}
+
+func (r *accountRepository) ListByUser(userID uint, sandbox bool) ([]models.Account, error) {
+	var accounts []models.Account
+	err := r.db.Where("user_id = ? AND sandbox = ?", userID, sandbox).Find(&accounts).Error
+	return accounts, err
+}
+
+func (r *accountRepository) FindOwned(userID uint, accountID int, sandbox bool) (*models.Account, error) {
+	var account models.Account
+	err := r.db.Where("id = ? AND user_id = ? AND sandbox = ?", accountID, userID, sandbox).First(&account).Error
+	return &account, notFound(err)
+}
+
+func (r *accountRepository) Find(accountID int) (*models.Account, error) {
+	var account models.Account
+	err := r.db.First(&account, accountID).Error
+	return &account, notFound(err)
+}
+
+func (r *accountRepository) UpdateNickname(accountID int, nickname string) error {
+	return r.db.Model(&models.Account{}).Where("id = ?", accountID).Update("nickname", nickname).Error
+}
+
+type userRepository struct {
+	db *gorm.DB
+}
+
+func (r *userRepository) Find(userID uint) (*models.User, error) {
+	var user models.User
+	err := r.db.Where("deleted_at IS NULL").First(&user, userID).Error
+	return &user, notFound(err)
+}
+
+func (r *userRepository) FindByUsername(username string) (*models.User, error) {
+	// Users registered before usernames were normalized may have capitals.
+	var user models.User
+	err := r.db.Where("username IN ? AND deleted_at IS NULL", []string{username, normalizeUsername(username)}).
+		Order(clause.OrderBy{Expression: clause.Expr{SQL: "username = ? DESC", Vars: []interface{}{username}}}).
+		First(&user).Error
+	return &user, notFound(err)
+}
+
+type transactionRepository struct {
+	db *gorm.DB
+}
+
+func (r *transactionRepository) FindVisible(transactionID string, userID uint) (*models.Transaction, error) {
+	var transaction models.Transaction
+	err := r.db.Where("id = ?", transactionID).
+		Where("from_account_id IN (?) OR to_account_id IN (?)", ownedAccountIDs(r.db, userID), ownedAccountIDs(r.db, userID)).
+		First(&transaction).Error
+	return &transaction, notFound(err)
+}
+
+func (r *transactionRepository) Currency(transaction *models.Transaction) (string, error) {
+	ids := []int{}
+	for _, id := range []*int{transaction.FromAccountID, transaction.ToAccountID} {
+		if id != nil {
+			ids = append(ids, *id)
+		}
+	}
+	if len(ids) == 0 {
+		return "", ErrNotFound
+	}
+	var account models.Account
+	err := r.db.Select("currency").Where("id IN ?", ids).Take(&account).Error
+	return account.Currency, notFound(err)
+}
+
+// notFound replaces gorm's not found error with ErrNotFound, so callers don't depend on gorm.
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,118 @@
+// Path: internal/services/repository_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"errors"
+	"testing"
+)
+
+// failingAccounts is an AccountRepository whose storage is down.
+type failingAccounts struct {
+	AccountRepository
+}
+
+func (failingAccounts) ListByUser(userID uint, sandbox bool) ([]models.Account, error) {
+	return nil, errors.New("connection refused")
+}
+
+func (failingAccounts) FindOwned(userID uint, accountID int, sandbox bool) (*models.Account, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestGetAccountsThroughTheRepository(t *testing.T) {
+	accounts := newMemAccounts(
+		models.Account{ID: 1, UserID: 10, Balance: 50, Currency: "USD"},
+		models.Account{ID: 2, UserID: 10, Balance: 1000, Currency: "USD", Sandbox: true},
+		models.Account{ID: 3, UserID: 20, Balance: 75, Currency: "EUR"},
+	)
+	s := NewAccountService(nil, testSecret, AccountConfig{Accounts: accounts})
+
+	for _, tt := range []struct {
+		sandbox bool
+		want    int
+	}{{false, 1}, {true, 2}} {
+		list, err := s.GetAccounts(10, tt.sandbox)
+		if err != nil {
+			t.Fatalf("GetAccounts(sandbox %v): %v", tt.sandbox, err)
+		}
+		if len(list) != 1 || list[0].ID != tt.want {
+			t.Errorf("GetAccounts(sandbox %v) = %+v, want only account %d", tt.sandbox, list, tt.want)
+		}
+	}
+
+	account, err := s.GetAccount(&models.Claims{UserID: 20}, 3)
+	if err != nil || account.Balance != 75 {
+		t.Errorf("GetAccount = %+v, %v", account, err)
+	}
+	_, err = s.GetAccount(&models.Claims{UserID: 10}, 3)
+	wantAppError(t, err, 404)
+}
+
+func TestRepositoryErrorsBecomeAppErrors(t *testing.T) {
+	s := NewAccountService(nil, testSecret, AccountConfig{Accounts: failingAccounts{}})
+	_, err := s.GetAccounts(10, false)
+	wantAppError(t, err, 500)
+	_, err = s.GetAccount(&models.Claims{UserID: 10}, 1)
+	wantAppError(t, err, 500)
+}
+
+func TestGormRepositories(t *testing.T) {
+	db := testDB(t)
+	alice, bob := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+	mine, theirs := seedAccount(t, db, alice, 10, "USD"), seedAccount(t, db, bob, 20, "USD")
+
+	accounts := NewAccountRepository(db)
+	if list, err := accounts.ListByUser(uint(alice.ID), false); err != nil || len(list) != 1 || list[0].ID != mine.ID {
+		t.Errorf("ListByUser = %+v, %v", list, err)
+	}
+	if _, err := accounts.FindOwned(uint(alice.ID), theirs.ID, false); !errors.Is(err, ErrNotFound) {
+		t.Errorf("FindOwned of bob's account = %v, want ErrNotFound", err)
+	}
+	if _, err := accounts.FindOwned(uint(alice.ID), mine.ID, true); !errors.Is(err, ErrNotFound) {
+		t.Errorf("FindOwned of a real account for a sandbox token = %v, want ErrNotFound", err)
+	}
+	if account, err := accounts.Find(theirs.ID); err != nil || account.UserID != bob.ID {
+		t.Errorf("Find = %+v, %v", account, err)
+	}
+	if err := accounts.UpdateNickname(mine.ID, "Savings"); err != nil {
+		t.Fatalf("UpdateNickname: %v", err)
+	}
+	if got := reloadAccount(t, db, mine.ID).Nickname; got != "Savings" {
+		t.Errorf("nickname = %q", got)
+	}
+
+	// A user registered before usernames were normalized keeps their capitals; the exact match wins.
+	legacy := seedUser(t, db, "Alice")
+	users := NewUserRepository(db)
+	if user, err := users.FindByUsername("Alice"); err != nil || user.ID != legacy.ID {
+		t.Errorf("FindByUsername(Alice) = %+v, %v; want the legacy user", user, err)
+	}
+	if user, err := users.FindByUsername("alice"); err != nil || user.ID != alice.ID {
+		t.Errorf("FindByUsername(alice) = %+v, %v", user, err)
+	}
+	if _, err := users.Find(uint(bob.ID + legacy.ID)); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Find of a missing user = %v, want ErrNotFound", err)
+	}
+
+	transfers := NewTransactionService(db, testSecret, TransactionConfig{})
+	req := &models.TransferRequest{FromID: mine.ID, ToID: theirs.ID, Amount: 5}
+	if err := transfers.ProcessTransfer(req, claimsFor(alice)); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	transactions := NewTransactionRepository(db)
+	for _, user := range []*models.User{alice, bob} {
+		if tx, err := transactions.FindVisible(req.TransactionID, uint(user.ID)); err != nil || tx.Amount != 5 {
+			t.Errorf("%s: FindVisible = %+v, %v", user.Username, tx, err)
+		}
+	}
+	if _, err := transactions.FindVisible(req.TransactionID, uint(legacy.ID)); !errors.Is(err, ErrNotFound) {
+		t.Errorf("FindVisible by a third user = %v, want ErrNotFound", err)
+	}
+	if currency, err := transactions.Currency(&models.Transaction{ToAccountID: &theirs.ID}); err != nil || currency != theirs.Currency {
+		t.Errorf("Currency = %q, %v; want %s", currency, err, theirs.Currency)
+	}
+	if _, err := transactions.Currency(&models.Transaction{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Currency without accounts = %v, want ErrNotFound", err)
+	}
+}
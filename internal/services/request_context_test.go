@@ -0,0 +1,38 @@
+// Path: internal/services/request_context_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestWithContextCancelsQueries(t *testing.T) {
+	db := testDB(t)
+	alice := seedUser(t, db, "alice")
+	bob := seedUser(t, db, "bob")
+	from := seedAccount(t, db, alice, 100, "USD")
+	to := seedAccount(t, db, bob, 0, "USD")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	accounts := NewAccountService(db, testSecret, AccountConfig{}).(*accountService)
+	_, err := accounts.WithContext(ctx).GetAccounts(uint(alice.ID), false)
+	var appErr *AppError
+	if !errors.As(err, &appErr) || !errors.Is(appErr.Err, context.Canceled) {
+		t.Errorf("GetAccounts under a cancelled context = %v, want context.Canceled", err)
+	}
+	if _, err := accounts.WithContext(context.Background()).GetAccounts(uint(alice.ID), false); err != nil {
+		t.Errorf("GetAccounts under a live context: %v", err)
+	}
+
+	transactions := NewTransactionService(db, testSecret, TransactionConfig{}).(*transactionService)
+	err = transactions.WithContext(ctx).ProcessTransfer(&models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: 30}, claimsFor(alice))
+	if err == nil {
+		t.Fatal("transfer under a cancelled context succeeded")
+	}
+	if got := reloadAccount(t, db, from.ID).Balance; got != 100 {
+		t.Errorf("source balance = %v, want 100 untouched", got)
+	}
+}
@@ -0,0 +1,118 @@
+// Path: internal/services/round_up.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"errors"
+	"fmt"
+	"math"
+
+	"gorm.io/gorm"
+)
+
+// roundUpDescription is the description of the transfers moving round-ups to the savings account.
+const roundUpDescription = "Round-up savings"
+
+// GetRoundUp returns the round-up savings settings of the user.
+func (s *accountService) GetRoundUp(userID uint) (*models.RoundUpSettings, error) {
+	var user models.User
+	if err := s.db.Select("round_up_enabled", "round_up_account_id").First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &AppError{Code: 404, Message: "User not found", Details: fmt.Sprintf("user_id: %d", userID)}
+		}
+		return nil, &AppError{Code: 500, Message: "Failed to query user", Details: err.Error(), Err: err}
+	}
+
+	return &models.RoundUpSettings{Enabled: user.RoundUpEnabled, AccountID: user.RoundUpAccountID}, nil
+}
+
+// SetRoundUp enables or disables round-up savings. Enabling requires one of the user's accounts as the savings account.
+func (s *accountService) SetRoundUp(userID uint, req *models.RoundUpSettings) (*models.RoundUpSettings, error) {
+	var v validation
+	v.check(!req.Enabled || req.AccountID != nil, "account_id", "A savings account is required to enable round-ups")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	if req.AccountID != nil {
+		if _, err := s.findOwnedAccount(s.db, userID, *req.AccountID); err != nil {
+			return nil, err
+		}
+	}
+
+	err := s.db.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"round_up_enabled":    req.Enabled,
+		"round_up_account_id": req.AccountID,
+	}).Error
+	if err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to update round-up settings", Details: err.Error(), Err: err}
+	}
+
+	return &models.RoundUpSettings{Enabled: req.Enabled, AccountID: req.AccountID}, nil
+}
+
+// roundUpAmount returns how much rounds amount up to the next whole unit, at the given decimal places.
+func roundUpAmount(amount float64, decimals int) float64 {
+	return fromMinorUnits(toMinorUnits(math.Ceil(amount)-amount, decimals), decimals)
+}
+
+// applyRoundUp moves the round-up of a completed withdrawal or transfer from source to the user's
+// savings account, recorded as its own transfer. It is skipped, not failed, when round-ups are off,
+// the savings account is unusable, or the policy of source doesn't allow the extra debit.
+func (s *transactionService) applyRoundUp(tx *gorm.DB, userID uint, source *models.Account, amount float64) error {
+	var user models.User
+	if err := tx.Select("round_up_enabled", "round_up_account_id").First(&user, userID).Error; err != nil {
+		return &AppError{Code: 500, Message: "Failed to query round-up settings", Details: err.Error(), Err: err}
+	}
+	if !user.RoundUpEnabled || user.RoundUpAccountID == nil || *user.RoundUpAccountID == source.ID {
+		return nil
+	}
+
+	roundUp := roundUpAmount(amount, currencyDecimals(source.Currency, s.cfg.CurrencyDecimals))
+	if roundUp <= 0 {
+		return nil
+	}
+
+	savings, err := s.loadOwnedAccount(tx, *user.RoundUpAccountID, userID, source.Sandbox)
+	if err != nil {
+		var appErr *AppError
+		if errors.As(err, &appErr) && appErr.Code == 404 {
+			return nil
+		}
+		return err
+	}
+	if savings.Currency != source.Currency {
+		return nil
+	}
+
+	available, err := s.availableBalance(tx, source, "")
+	if err != nil {
+		return err
+	}
+	if s.policyFor(source).CheckDebit(tx, source, available, roundUp, s.clock.Now()) != nil {
+		return nil
+	}
+
+	if err := s.applyTransfer(tx, source, savings, roundUp, 0); err != nil {
+		return err
+	}
+
+	initiatorID := int(userID)
+	transaction := models.Transaction{
+		ID:            utils.GenerateTransactionID(s.clock.Now()),
+		FromAccountID: &source.ID,
+		ToAccountID:   &savings.ID,
+		Amount:        roundUp,
+		Type:          "transfer",
+		Status:        "completed",
+		Description:   roundUpDescription,
+		InitiatorID:   &initiatorID,
+		CreatedAt:     s.clock.Now(),
+	}
+	if err := tx.Create(&transaction).Error; err != nil {
+		return &AppError{Code: 500, Message: "Failed to insert round-up transaction", Details: err.Error(), Err: err}
+	}
+
+	return s.recordTransferLegs(tx, &transaction)
+}
@@ -0,0 +1,78 @@
+// Path: internal/services/round_up_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"testing"
+)
+
+func TestRoundUpAmount(t *testing.T) {
+	cases := []struct {
+		amount   float64
+		decimals int
+		want     float64
+	}{
+		{3.25, 2, 0.75},
+		{4.01, 2, 0.99},
+		{5, 2, 0},
+		{0.1 + 0.2, 2, 0.7},
+		{1.125, 3, 0.875},
+		{1500, 0, 0},
+	}
+	for _, c := range cases {
+		if got := roundUpAmount(c.amount, c.decimals); got != c.want {
+			t.Errorf("roundUpAmount(%v, %d) = %v, want %v", c.amount, c.decimals, got, c.want)
+		}
+	}
+}
+
+func TestRoundUpMovesChangeToSavings(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "saver")
+	checking := seedAccount(t, db, user, 10.5, "USD")
+	savings := seedAccount(t, db, user, 0, "USD")
+	accounts := NewAccountService(db, testSecret, AccountConfig{})
+	transactions := NewTransactionService(db, testSecret, TransactionConfig{})
+	withdraw := func(amount float64) {
+		t.Helper()
+		if _, err := transactions.ProcessWithdraw(&models.TransactionRequest{AccountID: checking.ID, Amount: amount}, claimsFor(user)); err != nil {
+			t.Fatalf("withdraw %v: %v", amount, err)
+		}
+	}
+	balances := func() (float64, float64) {
+		return reloadAccount(t, db, checking.ID).Balance, reloadAccount(t, db, savings.ID).Balance
+	}
+
+	_, err := accounts.SetRoundUp(uint(user.ID), &models.RoundUpSettings{Enabled: true})
+	wantAppError(t, err, 400)
+	if _, err := accounts.SetRoundUp(uint(user.ID), &models.RoundUpSettings{Enabled: true, AccountID: &savings.ID}); err != nil {
+		t.Fatalf("enable round-ups: %v", err)
+	}
+
+	withdraw(3.25)
+	if c, s := balances(); c != 6.5 || s != 0.75 {
+		t.Errorf("after 3.25: checking %v, savings %v; want 6.5 and 0.75", c, s)
+	}
+	var roundUps []models.Transaction
+	db.Where("description = ?", roundUpDescription).Find(&roundUps)
+	if len(roundUps) != 1 || roundUps[0].Amount != 0.75 || *roundUps[0].ToAccountID != savings.ID || roundUps[0].Type != "transfer" {
+		t.Errorf("round-up transactions = %+v", roundUps)
+	}
+
+	// 6.20 leaves 0.30, short of the 0.80 round-up: skipped rather than failing the withdrawal.
+	withdraw(6.2)
+	if c, s := balances(); c != 0.3 || s != 0.75 {
+		t.Errorf("after 6.20: checking %v, savings %v; want the round-up skipped", c, s)
+	}
+
+	if _, err := transactions.ProcessDeposit(&models.TransactionRequest{AccountID: checking.ID, Amount: 9.7}, claimsFor(user)); err != nil {
+		t.Fatalf("deposit: %v", err)
+	}
+	if _, err := accounts.SetRoundUp(uint(user.ID), &models.RoundUpSettings{Enabled: false}); err != nil {
+		t.Fatalf("disable round-ups: %v", err)
+	}
+	withdraw(1.5)
+	if c, s := balances(); c != 8.5 || s != 0.75 {
+		t.Errorf("after disabling: checking %v, savings %v; want no round-up", c, s)
+	}
+}
@@ -0,0 +1,115 @@
+// Path: internal/services/sandbox_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/accountnumber"
+	"testing"
+	"time"
+)
+
+func TestSandboxIsIsolatedFromRealFunds(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "integrator")
+	real := seedAccount(t, db, user, 100, "USD")
+	sandbox := &models.Account{UserID: user.ID, Balance: 100, Currency: "USD", Sandbox: true}
+	if err := createAccount(db, sandbox, testSecret, time.Now().Add(-24*time.Hour), accountnumber.Internal{}); err != nil {
+		t.Fatalf("create sandbox account: %v", err)
+	}
+	sandboxClaims := &models.Claims{UserID: uint(user.ID), Role: user.Role, Sandbox: true}
+	s := NewTransactionService(db, testSecret, TransactionConfig{})
+
+	if _, err := s.ProcessDeposit(&models.TransactionRequest{AccountID: sandbox.ID, Amount: 50}, sandboxClaims); err != nil {
+		t.Fatalf("sandbox deposit: %v", err)
+	}
+	if _, err := s.ProcessWithdraw(&models.TransactionRequest{AccountID: sandbox.ID, Amount: 30}, sandboxClaims); err != nil {
+		t.Fatalf("sandbox withdrawal: %v", err)
+	}
+
+	// Neither mode reaches the accounts of the other.
+	_, err := s.ProcessDeposit(&models.TransactionRequest{AccountID: real.ID, Amount: 10}, sandboxClaims)
+	wantAppError(t, err, 404)
+	err = s.ProcessTransfer(&models.TransferRequest{FromID: sandbox.ID, ToID: real.ID, Amount: 10}, sandboxClaims)
+	wantAppError(t, err, 404)
+	_, err = s.ProcessWithdraw(&models.TransactionRequest{AccountID: sandbox.ID, Amount: 10}, claimsFor(user))
+	wantAppError(t, err, 404)
+	err = s.ProcessTransfer(&models.TransferRequest{FromID: real.ID, ToID: sandbox.ID, Amount: 10}, claimsFor(user))
+	wantAppError(t, err, 404)
+
+	if got := reloadAccount(t, db, real.ID).Balance; got != 100 {
+		t.Errorf("real balance = %v, want 100", got)
+	}
+	if got := reloadAccount(t, db, sandbox.ID).Balance; got != 120 {
+		t.Errorf("sandbox balance = %v, want 120", got)
+	}
+
+	accounts := NewAccountService(db, testSecret, AccountConfig{})
+	for mode, want := range map[bool]int{false: real.ID, true: sandbox.ID} {
+		listed, err := accounts.GetAccounts(uint(user.ID), mode)
+		if err != nil || len(listed) != 1 || listed[0].ID != want {
+			t.Errorf("GetAccounts(sandbox %t) = %+v, %v; want only account %d", mode, listed, err, want)
+		}
+	}
+}
+
+func TestLoginIssuesSandboxTokens(t *testing.T) {
+	db := testDB(t)
+	auth := NewAuthService(db, testJWTSecret, AuthConfig{})
+	if err := auth.Register("sandboxer", "password1"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	for _, sandbox := range []bool{false, true} {
+		token, err := auth.Login("sandboxer", "password1", models.LoginMeta{Sandbox: sandbox})
+		if err != nil {
+			t.Fatalf("login: %v", err)
+		}
+		claims, err := auth.ValidateToken(token)
+		if err != nil || claims.Sandbox != sandbox {
+			t.Errorf("token asked with sandbox %t = %+v, %v", sandbox, claims, err)
+		}
+	}
+}
+
+func TestAccountReadsStayInTheTokenMode(t *testing.T) {
+	accounts := newMemAccounts(
+		models.Account{ID: 1, UserID: 10, Balance: 50, Currency: "USD"},
+		models.Account{ID: 2, UserID: 10, Balance: 1000, Currency: "USD", Sandbox: true},
+	)
+	s := NewAccountService(nil, testSecret, AccountConfig{Accounts: accounts})
+	real := &models.Claims{UserID: 10, Role: models.RoleUser}
+	sandbox := &models.Claims{UserID: 10, Role: models.RoleUser, Sandbox: true}
+
+	for _, tt := range []struct {
+		name    string
+		claims  *models.Claims
+		own     int
+		foreign int
+	}{
+		{"sandbox token", sandbox, 2, 1},
+		{"real token", real, 1, 2},
+	} {
+		if _, err := s.GetAccount(tt.claims, tt.own); err != nil {
+			t.Errorf("%s: GetAccount of its mode: %v", tt.name, err)
+		}
+		_, err := s.GetAccount(tt.claims, tt.foreign)
+		wantAppError(t, err, 404)
+
+		if _, err := s.GetBalanceProof(tt.claims, tt.own); err != nil {
+			t.Errorf("%s: GetBalanceProof of its mode: %v", tt.name, err)
+		}
+		_, err = s.GetBalanceProof(tt.claims, tt.foreign)
+		wantAppError(t, err, 404)
+
+		if _, err := s.SetNickname(tt.claims, tt.own, &models.UpdateAccountRequest{Nickname: "Mine"}); err != nil {
+			t.Errorf("%s: SetNickname of its mode: %v", tt.name, err)
+		}
+		_, err = s.SetNickname(tt.claims, tt.foreign, &models.UpdateAccountRequest{Nickname: "Crossed"})
+		wantAppError(t, err, 404)
+	}
+	for id, account := range accounts.accounts {
+		if account.Nickname != "Mine" {
+			t.Errorf("account %d nickname = %q, want only its own mode's rename", id, account.Nickname)
+		}
+	}
+}
@@ -2,11 +2,8 @@ package services
 
 import (
 	"bank-api/internal/models"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
+	"bank-api/pkg/utils"
 	"errors"
-	"fmt"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
@@ -106,9 +103,7 @@ func (s *Service) Transfer(userID uint, req *models.TransferRequest) error {
 	})
 }
 
+// CalculateBalanceHash is kept for callers of the legacy Service; it uses the canonical utils.CalculateBalanceHash.
 func CalculateBalanceHash(balance float64, accountID uint, secretKey string) string {
-	h := hmac.New(sha256.New, []byte(secretKey))
-	data := fmt.Sprintf("%f:%d", balance, accountID)
-	h.Write([]byte(data))
-	return hex.EncodeToString(h.Sum(nil))
+	return utils.CalculateBalanceHash(balance, int(accountID), secretKey)
 }
@@ -0,0 +1,105 @@
+// Path: internal/services/spending_limit.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GetLimits returns the user's own daily spending limit, the system maximum and the effective limit.
+func (s *transactionService) GetLimits(userID uint) (*models.SpendingLimits, error) {
+	var user models.User
+	if err := s.db.Select("daily_spending_limit").First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &AppError{Code: 404, Message: "User not found", Details: fmt.Sprintf("user_id: %d", userID)}
+		}
+		return nil, &AppError{Code: 500, Message: "Failed to query user", Details: err.Error(), Err: err}
+	}
+
+	return s.spendingLimits(user.DailySpendingLimit), nil
+}
+
+// SetLimits sets the user's own daily spending limit. It may only tighten the system limit;
+// a null limit removes it.
+func (s *transactionService) SetLimits(userID uint, req *models.SpendingLimits) (*models.SpendingLimits, error) {
+	var v validation
+	if req.DailyLimit != nil {
+		v.check(*req.DailyLimit > 0, "daily_limit", "Daily limit must be positive")
+		v.check(s.cfg.DailySpendingLimit <= 0 || *req.DailyLimit <= s.cfg.DailySpendingLimit, "daily_limit",
+			fmt.Sprintf("Daily limit must not exceed %g", s.cfg.DailySpendingLimit))
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	err := s.db.Model(&models.User{}).Where("id = ?", userID).Update("daily_spending_limit", req.DailyLimit).Error
+	if err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to update spending limits", Details: err.Error(), Err: err}
+	}
+
+	return s.spendingLimits(req.DailyLimit), nil
+}
+
+// spendingLimits combines the user's limit with the system one. Zero means unlimited.
+func (s *transactionService) spendingLimits(userLimit *float64) *models.SpendingLimits {
+	effective := s.cfg.DailySpendingLimit
+	if userLimit != nil && (effective <= 0 || *userLimit < effective) {
+		effective = *userLimit
+	}
+	return &models.SpendingLimits{
+		DailyLimit:     userLimit,
+		MaxDailyLimit:  s.cfg.DailySpendingLimit,
+		EffectiveLimit: effective,
+	}
+}
+
+// checkSpendingLimit rejects a withdrawal, transfer or hold of amount in currency that would take
+// the user's spending today, across all their accounts of the same mode in that currency, over the
+// effective daily limit. Amounts aren't converted: the limit applies to each currency separately.
+func (s *transactionService) checkSpendingLimit(tx *gorm.DB, claims *models.Claims, currency string, amount float64) error {
+	var user models.User
+	if err := tx.Select("daily_spending_limit").First(&user, claims.UserID).Error; err != nil {
+		return &AppError{Code: 500, Message: "Failed to query spending limits", Details: err.Error(), Err: err}
+	}
+	limit := s.spendingLimits(user.DailySpendingLimit).EffectiveLimit
+	if limit <= 0 {
+		return nil
+	}
+
+	now := s.clock.Now()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	spendingAccounts := ownedAccountIDs(tx, claims.UserID).Where("sandbox = ? AND currency = ?", claims.Sandbox, currency)
+	var spent float64
+	query := tx.Model(&models.Transaction{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("from_account_id IN (?)", spendingAccounts).
+		Where("type IN ? AND status IN ? AND created_at >= ?", []string{"withdraw", "transfer"}, []string{"completed", "pending_approval", "incoming_pending"}, dayStart)
+	if s.cfg.OwnTransferFastPath {
+		// Transfers between the user's own accounts skip the limit, so they don't use it up either.
+		query = query.Where("(to_account_id IS NULL OR to_account_id NOT IN (?))", ownedAccountIDs(tx, claims.UserID))
+	}
+	err := query.Scan(&spent).Error
+	if err != nil {
+		return &AppError{Code: 500, Message: "Failed to sum today's spending", Details: err.Error(), Err: err}
+	}
+	// Active holds are withdrawals yet to be booked: their capture isn't checked again, so they
+	// count until captured or released, whatever day they were placed.
+	var held float64
+	err = tx.Model(&models.Hold{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("account_id IN (?) AND status = ?", spendingAccounts, HoldActive).
+		Scan(&held).Error
+	if err != nil {
+		return &AppError{Code: 500, Message: "Failed to sum today's spending", Details: err.Error(), Err: err}
+	}
+	spent += held
+
+	if spent+amount > limit {
+		return &AppError{Code: 403, Message: "Daily spending limit exceeded", Details: fmt.Sprintf("limit: %.2f %s, spent today: %.2f %s", limit, currency, spent, currency)}
+	}
+	return nil
+}
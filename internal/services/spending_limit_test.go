@@ -0,0 +1,131 @@
+// Path: internal/services/spending_limit_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"testing"
+	"time"
+)
+
+func TestDailySpendingLimitRollsOverAtMidnight(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "spender")
+	account := seedAccount(t, db, user, 500, "USD")
+	clock := utils.NewManualClock(time.Date(2024, 3, 1, 23, 0, 0, 0, time.Local))
+	s := NewTransactionService(db, testSecret, TransactionConfig{DailySpendingLimit: 100, Clock: clock})
+
+	withdraw := func(amount float64) error {
+		_, err := s.ProcessWithdraw(&models.TransactionRequest{AccountID: account.ID, Amount: amount}, claimsFor(user))
+		return err
+	}
+	if err := withdraw(80); err != nil {
+		t.Fatalf("first withdrawal: %v", err)
+	}
+	wantAppError(t, withdraw(30), 403)
+
+	clock.Advance(30 * time.Minute)
+	wantAppError(t, withdraw(30), 403)
+
+	// Past midnight the window starts over.
+	clock.Advance(time.Hour)
+	if err := withdraw(30); err != nil {
+		t.Errorf("withdrawal on the next day: %v", err)
+	}
+}
+
+func TestSpendingLimitsTakeTheLowerLimit(t *testing.T) {
+	s := NewTransactionService(nil, testSecret, TransactionConfig{DailySpendingLimit: 1000}).(*transactionService)
+	low, high := 200.0, 5000.0
+	tests := []struct {
+		name      string
+		userLimit *float64
+		want      float64
+	}{
+		{"no user limit", nil, 1000},
+		{"user limit below the system", &low, 200},
+		{"user limit above the system", &high, 1000},
+	}
+	for _, tt := range tests {
+		if got := s.spendingLimits(tt.userLimit).EffectiveLimit; got != tt.want {
+			t.Errorf("%s: effective limit = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	unlimited := NewTransactionService(nil, testSecret, TransactionConfig{}).(*transactionService)
+	if got := unlimited.spendingLimits(&low).EffectiveLimit; got != 200 {
+		t.Errorf("user limit without a system limit = %v, want 200", got)
+	}
+}
+
+func TestSetLimitsRejectsLimitsAboveTheMax(t *testing.T) {
+	s := NewTransactionService(nil, testSecret, TransactionConfig{DailySpendingLimit: 1000})
+	for _, limit := range []float64{1000.01, 0, -5} {
+		_, err := s.SetLimits(1, &models.SpendingLimits{DailyLimit: &limit})
+		if appErr := wantAppError(t, err, 400); len(appErr.Fields) != 1 || appErr.Fields[0].Field != "daily_limit" {
+			t.Errorf("limit %v: %+v", limit, appErr)
+		}
+	}
+}
+
+func TestUserSpendingLimitIsEnforcedOnTransfers(t *testing.T) {
+	db := testDB(t)
+	alice := seedUser(t, db, "alice")
+	bob := seedUser(t, db, "bob")
+	from := seedAccount(t, db, alice, 1000, "USD")
+	to := seedAccount(t, db, bob, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{DailySpendingLimit: 500})
+
+	limit := 100.0
+	limits, err := s.SetLimits(uint(alice.ID), &models.SpendingLimits{DailyLimit: &limit})
+	if err != nil || limits.EffectiveLimit != 100 || limits.MaxDailyLimit != 500 {
+		t.Fatalf("SetLimits = %+v, %v", limits, err)
+	}
+	if got, err := s.GetLimits(uint(alice.ID)); err != nil || got.DailyLimit == nil || *got.DailyLimit != 100 {
+		t.Errorf("GetLimits = %+v, %v", got, err)
+	}
+
+	transfer := func(amount float64) error {
+		return s.ProcessTransfer(&models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: amount}, claimsFor(alice))
+	}
+	if err := transfer(80); err != nil {
+		t.Fatalf("transfer within the limit: %v", err)
+	}
+	wantAppError(t, transfer(30), 403)
+
+	// Removing the user limit falls back to the system one.
+	if _, err := s.SetLimits(uint(alice.ID), &models.SpendingLimits{}); err != nil {
+		t.Fatalf("remove limit: %v", err)
+	}
+	if err := transfer(30); err != nil {
+		t.Errorf("transfer under the system limit: %v", err)
+	}
+	if got := reloadAccount(t, db, to.ID).Balance; got != 110 {
+		t.Errorf("destination balance = %v, want 110", got)
+	}
+}
+
+func TestSpendingLimitCountsEachCurrencySeparately(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "traveller")
+	dollars := seedAccount(t, db, user, 500, "USD")
+	yen := seedAccount(t, db, user, 50000, "JPY")
+	s := NewTransactionService(db, testSecret, TransactionConfig{DailySpendingLimit: 100})
+
+	withdraw := func(account *models.Account, amount float64) error {
+		_, err := s.ProcessWithdraw(&models.TransactionRequest{AccountID: account.ID, Amount: amount}, claimsFor(user))
+		return err
+	}
+	if err := withdraw(dollars, 90); err != nil {
+		t.Fatalf("dollar withdrawal: %v", err)
+	}
+	// 90 dollars spent don't use up the yen limit, and yen spent don't count against dollars.
+	if err := withdraw(yen, 95); err != nil {
+		t.Errorf("yen withdrawal after spending dollars: %v", err)
+	}
+	wantAppError(t, withdraw(yen, 10), 403)
+	if err := withdraw(dollars, 10); err != nil {
+		t.Errorf("dollar withdrawal up to the limit after spending yen: %v", err)
+	}
+	wantAppError(t, withdraw(dollars, 1), 403)
+}
@@ -0,0 +1,94 @@
+// Path: internal/services/split_deposit.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// MaxSplitDepositParts caps the number of accounts one deposit can be split across.
+const MaxSplitDepositParts = 20
+
+// ProcessSplitDeposit deposits one amount across several of the user's accounts, all in the
+// same currency, within one database transaction: either every part is deposited or none is.
+// Each part goes through the checks of a single deposit and may be held on its own.
+func (s *transactionService) ProcessSplitDeposit(req *models.SplitDepositRequest, claims *models.Claims) ([]models.DepositResult, error) {
+	var v validation
+	v.check(req.Total > 0, "total", "Total must be positive")
+	v.check(len(req.Parts) > 0, "parts", "At least one part is required")
+	v.check(len(req.Parts) <= MaxSplitDepositParts, "parts", fmt.Sprintf("At most %d parts per deposit", MaxSplitDepositParts))
+	seen := make(map[int]bool, len(req.Parts))
+	for i, part := range req.Parts {
+		field := fmt.Sprintf("parts[%d]", i)
+		v.check(part.Amount > 0, field+".amount", "Amount must be positive")
+		v.check(!seen[part.AccountID], field+".account_id", "Each account can only appear once")
+		seen[part.AccountID] = true
+	}
+	v.description("description", &req.Description)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	// Source limits apply to the whole amount, as if it was deposited at once.
+	flagged, err := s.checkDepositSource(&models.TransactionRequest{Amount: req.Total, Source: req.Source})
+	if err != nil {
+		return nil, err
+	}
+	req.Source = normalizeDepositSource(req.Source)
+
+	var results []models.DepositResult
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		accounts := make([]*models.Account, len(req.Parts))
+		for i, part := range req.Parts {
+			account, err := s.loadOwnedAccount(tx, part.AccountID, claims.UserID, claims.Sandbox)
+			if err != nil {
+				return err
+			}
+			if i > 0 && account.Currency != accounts[0].Currency {
+				return &AppError{Code: 400, Message: "Currency mismatch", Details: fmt.Sprintf("account %d is in %s, account %d in %s", accounts[0].ID, accounts[0].Currency, account.ID, account.Currency)}
+			}
+			accounts[i] = account
+		}
+
+		// Compare in minor units, so float residue never rejects parts that add up.
+		decimals := currencyDecimals(accounts[0].Currency, s.cfg.CurrencyDecimals)
+		var sum int64
+		for _, part := range req.Parts {
+			sum += toMinorUnits(part.Amount, decimals)
+		}
+		if sum != toMinorUnits(req.Total, decimals) {
+			var v validation
+			v.check(false, "total", fmt.Sprintf("The parts add up to %s, not %s", formatAmount(fromMinorUnits(sum, decimals), decimals), formatAmount(req.Total, decimals)))
+			return v.err()
+		}
+
+		results = make([]models.DepositResult, len(req.Parts))
+		for i, part := range req.Parts {
+			deposit := models.TransactionRequest{
+				AccountID:   part.AccountID,
+				Amount:      part.Amount,
+				Description: req.Description,
+				Source:      req.Source,
+			}
+			balance, err := s.creditDeposit(tx, accounts[i], &deposit, claims, flagged)
+			if err != nil {
+				return err
+			}
+			results[i] = models.DepositResult{
+				TransactionID: deposit.TransactionID,
+				Status:        deposit.Status,
+				Amount:        deposit.Amount,
+				Balance:       *balance,
+			}
+		}
+		return nil
+	})
+	// Of the freeze rules only the integrity one applies to deposits, and it names the account itself.
+	s.applyFreezeRules(err, "deposit", 0)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,80 @@
+// Path: internal/services/split_deposit_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"reflect"
+	"testing"
+)
+
+func TestSplitDepositValidation(t *testing.T) {
+	s := NewTransactionService(nil, testSecret, TransactionConfig{})
+	claims := &models.Claims{UserID: 1}
+
+	_, err := s.ProcessSplitDeposit(&models.SplitDepositRequest{}, claims)
+	if got := fieldNames(t, err); !reflect.DeepEqual(got, []string{"total", "parts"}) {
+		t.Errorf("empty request: fields %v, want total and parts", got)
+	}
+	_, err = s.ProcessSplitDeposit(&models.SplitDepositRequest{Total: 10, Parts: []models.SplitDepositPart{
+		{AccountID: 1, Amount: 5}, {AccountID: 1, Amount: 5}, {AccountID: 2, Amount: -1},
+	}}, claims)
+	if got := fieldNames(t, err); !reflect.DeepEqual(got, []string{"parts[1].account_id", "parts[2].amount"}) {
+		t.Errorf("bad parts: fields %v", got)
+	}
+	parts := make([]models.SplitDepositPart, MaxSplitDepositParts+1)
+	for i := range parts {
+		parts[i] = models.SplitDepositPart{AccountID: i + 1, Amount: 1}
+	}
+	_, err = s.ProcessSplitDeposit(&models.SplitDepositRequest{Total: float64(len(parts)), Parts: parts}, claims)
+	if got := fieldNames(t, err); !reflect.DeepEqual(got, []string{"parts"}) {
+		t.Errorf("too many parts: fields %v", got)
+	}
+}
+
+func TestSplitDeposit(t *testing.T) {
+	db := testDB(t)
+	alice, bob := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+	checking, savings := seedAccount(t, db, alice, 10, "USD"), seedAccount(t, db, alice, 0, "USD")
+	euros := seedAccount(t, db, alice, 0, "EUR")
+	bobs := seedAccount(t, db, bob, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{})
+	split := func(total float64, parts ...models.SplitDepositPart) ([]models.DepositResult, error) {
+		return s.ProcessSplitDeposit(&models.SplitDepositRequest{Total: total, Description: "Paycheck", Parts: parts}, claimsFor(alice))
+	}
+
+	// 0.1 + 0.2 adds up to 0.3 in cents.
+	results, err := split(100.3, models.SplitDepositPart{AccountID: checking.ID, Amount: 100.1}, models.SplitDepositPart{AccountID: savings.ID, Amount: 0.2})
+	if err != nil {
+		t.Fatalf("split: %v", err)
+	}
+	if len(results) != 2 || results[0].Status != "completed" || results[0].Balance.Balance != 110.1 || results[1].Balance.Balance != 0.2 {
+		t.Errorf("results = %+v", results)
+	}
+	for id, want := range map[int]float64{checking.ID: 110.1, savings.ID: 0.2} {
+		stored := reloadAccount(t, db, id)
+		if stored.Balance != want || stored.BalanceHash != balanceHash(stored, testSecret) {
+			t.Errorf("account %d: balance %v, want %v with a matching hash", id, stored.Balance, want)
+		}
+	}
+
+	// A part the user doesn't own, a sum mismatch or mixed currencies deposit nothing at all.
+	_, err = split(20, models.SplitDepositPart{AccountID: checking.ID, Amount: 10}, models.SplitDepositPart{AccountID: bobs.ID, Amount: 10})
+	wantAppError(t, err, 404)
+	_, err = split(25, models.SplitDepositPart{AccountID: checking.ID, Amount: 10}, models.SplitDepositPart{AccountID: savings.ID, Amount: 10})
+	if got := fieldNames(t, err); !reflect.DeepEqual(got, []string{"total"}) {
+		t.Errorf("sum mismatch: fields %v, want total", got)
+	}
+	_, err = split(20, models.SplitDepositPart{AccountID: checking.ID, Amount: 10}, models.SplitDepositPart{AccountID: euros.ID, Amount: 10})
+	wantAppError(t, err, 400)
+
+	for id, want := range map[int]float64{checking.ID: 110.1, savings.ID: 0.2, bobs.ID: 0, euros.ID: 0} {
+		if got := reloadAccount(t, db, id).Balance; got != want {
+			t.Errorf("account %d balance = %v after rejected splits, want %v", id, got, want)
+		}
+	}
+	var deposits int64
+	db.Model(&models.Transaction{}).Where("type = ?", "deposit").Count(&deposits)
+	if deposits != 2 {
+		t.Errorf("%d deposits recorded, want the 2 of the successful split", deposits)
+	}
+}
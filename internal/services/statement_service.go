@@ -0,0 +1,178 @@
+// Path: internal/services/statement_service.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// statementPeriodLayout names a monthly statement period, e.g. "2024-05".
+const statementPeriodLayout = "2006-01"
+
+// Notifier delivers messages with an attachment to users (by email in production).
+type Notifier interface {
+	Notify(userID uint, subject, attachmentName string, attachment []byte) error
+}
+
+// LogNotifier only logs what would be delivered. It is the default until a real channel is configured.
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(userID uint, subject, attachmentName string, attachment []byte) error {
+	log.Printf("Notification for user %d: %s (%s, %d bytes)", userID, subject, attachmentName, len(attachment))
+	return nil
+}
+
+// StatementService generates monthly account statements and sends them to the users who opted in.
+type StatementService interface {
+	GetSettings(userID uint) (*models.StatementSettings, error)
+	SetSettings(userID uint, req *models.StatementSettings) (*models.StatementSettings, error)
+	Generate(userID uint, from, to time.Time) ([]byte, error)
+	SendDue() (int, error)
+}
+
+type statementService struct {
+	db       *gorm.DB
+	notifier Notifier
+	clock    utils.Clock
+}
+
+// NewStatementService creates a new StatementService. A nil notifier defaults to LogNotifier.
+func NewStatementService(db *gorm.DB, notifier Notifier, clock utils.Clock) StatementService {
+	if notifier == nil {
+		notifier = LogNotifier{}
+	}
+	return &statementService{
+		db:       db,
+		notifier: notifier,
+		clock:    clock,
+	}
+}
+
+// WithContext returns a copy of the service whose queries run under ctx, so a request's deadline
+// and cancellation reach the database.
+func (s *statementService) WithContext(ctx context.Context) StatementService {
+	bound := *s
+	bound.db = dbWithContext(s.db, ctx)
+	return &bound
+}
+
+// GetSettings returns whether the user receives monthly statements.
+func (s *statementService) GetSettings(userID uint) (*models.StatementSettings, error) {
+	var user models.User
+	if err := s.db.Select("statements_enabled").First(&user, userID).Error; err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to query statement settings", Details: err.Error(), Err: err}
+	}
+	return &models.StatementSettings{Enabled: user.StatementsEnabled}, nil
+}
+
+// SetSettings opts the user in or out of monthly statements. The first statement after opting in
+// covers the last full month.
+func (s *statementService) SetSettings(userID uint, req *models.StatementSettings) (*models.StatementSettings, error) {
+	err := s.db.Model(&models.User{}).Where("id = ?", userID).Update("statements_enabled", req.Enabled).Error
+	if err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to update statement settings", Details: err.Error(), Err: err}
+	}
+	return &models.StatementSettings{Enabled: req.Enabled}, nil
+}
+
+// Generate builds a CSV statement of the transactions touching the user's accounts in [from, to).
+func (s *statementService) Generate(userID uint, from, to time.Time) ([]byte, error) {
+	var transactions []models.Transaction
+	err := s.db.Where("from_account_id IN (?) OR to_account_id IN (?)", ownedAccountIDs(s.db, userID), ownedAccountIDs(s.db, userID)).
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Order("created_at, id").
+		Find(&transactions).Error
+	if err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to query transactions", Details: err.Error(), Err: err}
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"id", "created_at", "type", "status", "from_account_id", "to_account_id", "amount", "fee", "description"})
+	for _, t := range transactions {
+		w.Write([]string{
+			t.ID,
+			t.CreatedAt.Format(time.RFC3339),
+			t.Type,
+			t.Status,
+			optionalID(t.FromAccountID),
+			optionalID(t.ToAccountID),
+			strconv.FormatFloat(t.Amount, 'f', -1, 64),
+			strconv.FormatFloat(t.Fee, 'f', -1, 64),
+			t.Description,
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to write statement", Details: err.Error(), Err: err}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// SendDue sends the statement of the last full month to every opted-in user who hasn't received it
+// and returns how many were sent. Each user is claimed before sending, so concurrent runs don't send
+// twice; a failed delivery releases the claim and is retried on the next run.
+func (s *statementService) SendDue() (int, error) {
+	now := s.clock.Now()
+	to := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	from := to.AddDate(0, -1, 0)
+	period := from.Format(statementPeriodLayout)
+
+	var users []models.User
+	err := s.db.Select("id", "statement_last_period").
+		Where("statements_enabled AND deleted_at IS NULL AND statement_last_period <> ?", period).
+		Order("id").
+		Find(&users).Error
+	if err != nil {
+		return 0, &AppError{Code: 500, Message: "Failed to query statement recipients", Details: err.Error(), Err: err}
+	}
+
+	sent := 0
+	for _, user := range users {
+		userID := uint(user.ID)
+		claim := s.db.Model(&models.User{}).
+			Where("id = ? AND statement_last_period = ?", user.ID, user.StatementLastPeriod).
+			Update("statement_last_period", period)
+		if claim.Error != nil {
+			return sent, &AppError{Code: 500, Message: "Failed to claim statement", Details: claim.Error.Error(), Err: claim.Error}
+		}
+		if claim.RowsAffected == 0 {
+			continue // Another run got there first.
+		}
+
+		err := s.send(userID, period, from, to)
+		if err != nil {
+			log.Printf("Failed to send %s statement to user %d: %v", period, userID, err)
+			s.db.Model(&models.User{}).Where("id = ?", user.ID).Update("statement_last_period", user.StatementLastPeriod)
+			continue
+		}
+		sent++
+	}
+
+	return sent, nil
+}
+
+func (s *statementService) send(userID uint, period string, from, to time.Time) error {
+	statement, err := s.Generate(userID, from, to)
+	if err != nil {
+		return err
+	}
+	return s.notifier.Notify(userID, fmt.Sprintf("Account statement for %s", period), fmt.Sprintf("statement-%s.csv", period), statement)
+}
+
+func optionalID(id *int) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.Itoa(*id)
+}
@@ -0,0 +1,113 @@
+// Path: internal/services/statement_service_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingNotifier keeps every notification, failing while fail is set.
+type recordingNotifier struct {
+	sent []notification
+	fail bool
+}
+
+type notification struct {
+	userID     uint
+	subject    string
+	attachment string
+}
+
+func (n *recordingNotifier) Notify(userID uint, subject, attachmentName string, attachment []byte) error {
+	if n.fail {
+		return errors.New("mail server down")
+	}
+	n.sent = append(n.sent, notification{userID, subject, string(attachment)})
+	return nil
+}
+
+func TestSendDueSendsOncePerPeriod(t *testing.T) {
+	db := testDB(t)
+	optedIn := seedUser(t, db, "optedin")
+	optedOut := seedUser(t, db, "optedout")
+	account := seedAccount(t, db, optedIn, 0, "USD")
+	seedAccount(t, db, optedOut, 0, "USD")
+	if _, err := NewTransactionService(db, testSecret, TransactionConfig{}).ProcessDeposit(
+		&models.TransactionRequest{AccountID: account.ID, Amount: 42}, claimsFor(optedIn)); err != nil {
+		t.Fatalf("deposit: %v", err)
+	}
+
+	// Early next month the statement of this month is due.
+	now := time.Now()
+	clock := utils.NewManualClock(time.Date(now.Year(), now.Month()+1, 1, 12, 0, 0, 0, now.Location()))
+	notifier := &recordingNotifier{}
+	s := NewStatementService(db, notifier, clock)
+	if _, err := s.SetSettings(uint(optedIn.ID), &models.StatementSettings{Enabled: true}); err != nil {
+		t.Fatalf("opt in: %v", err)
+	}
+
+	if sent, err := s.SendDue(); err != nil || sent != 1 {
+		t.Fatalf("SendDue = %d, %v; want 1", sent, err)
+	}
+	if len(notifier.sent) != 1 || notifier.sent[0].userID != uint(optedIn.ID) {
+		t.Fatalf("notifications = %+v, want one for the opted-in user", notifier.sent)
+	}
+	got := notifier.sent[0]
+	if want := "Account statement for " + now.Format(statementPeriodLayout); got.subject != want {
+		t.Errorf("subject = %q, want %q", got.subject, want)
+	}
+	if lines := strings.Split(strings.TrimSpace(got.attachment), "\n"); len(lines) != 2 || !strings.Contains(lines[1], ",42,") {
+		t.Errorf("statement = %q, want a header and the deposit", got.attachment)
+	}
+
+	// A second run in the same period sends nothing.
+	if sent, err := s.SendDue(); err != nil || sent != 0 || len(notifier.sent) != 1 {
+		t.Errorf("second SendDue = %d, %v with %d notifications; want nothing sent", sent, err, len(notifier.sent))
+	}
+
+	// The next period is due again.
+	clock.Advance(31 * 24 * time.Hour)
+	if sent, err := s.SendDue(); err != nil || sent != 1 || len(notifier.sent) != 2 {
+		t.Errorf("next period SendDue = %d, %v; want 1", sent, err)
+	}
+}
+
+func TestSendDueRetriesFailedDeliveries(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "retry")
+	now := time.Now()
+	clock := utils.NewManualClock(time.Date(now.Year(), now.Month()+1, 1, 12, 0, 0, 0, now.Location()))
+	notifier := &recordingNotifier{fail: true}
+	s := NewStatementService(db, notifier, clock)
+	if _, err := s.SetSettings(uint(user.ID), &models.StatementSettings{Enabled: true}); err != nil {
+		t.Fatalf("opt in: %v", err)
+	}
+
+	if sent, err := s.SendDue(); err != nil || sent != 0 {
+		t.Fatalf("SendDue while failing = %d, %v; want 0", sent, err)
+	}
+	notifier.fail = false
+	if sent, err := s.SendDue(); err != nil || sent != 1 || len(notifier.sent) != 1 {
+		t.Errorf("SendDue after recovery = %d, %v; want the statement sent once", sent, err)
+	}
+}
+
+func TestStatementSettingsRoundTrip(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "settings")
+	s := NewStatementService(db, nil, utils.RealClock)
+
+	if got, err := s.GetSettings(uint(user.ID)); err != nil || got.Enabled {
+		t.Errorf("default settings = %+v, %v; want disabled", got, err)
+	}
+	if _, err := s.SetSettings(uint(user.ID), &models.StatementSettings{Enabled: true}); err != nil {
+		t.Fatalf("SetSettings: %v", err)
+	}
+	if got, err := s.GetSettings(uint(user.ID)); err != nil || !got.Enabled {
+		t.Errorf("settings = %+v, %v; want enabled", got, err)
+	}
+}
@@ -0,0 +1,134 @@
+// Path: internal/services/sub_balances.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"sort"
+	"strings"
+)
+
+// SubBalancePrincipal names the principal, the part of a balance outside the sub-balances.
+const SubBalancePrincipal = "principal"
+
+// SubBalanceConfig configures the sub-balances deposits can be credited to.
+type SubBalanceConfig struct {
+	// Names lists the sub-balances deposits may target, e.g. "bonus". Empty credits everything to
+	// the principal.
+	Names []string
+	// Priority is the order debits draw from the sub-balances, SubBalancePrincipal included.
+	// Defaults to the principal, then Names in order; the ones left out are drawn last.
+	Priority []string
+}
+
+// normalize lowercases the names and completes Priority, so every configured name appears in it once.
+func (cfg *SubBalanceConfig) normalize() {
+	for i, name := range cfg.Names {
+		cfg.Names[i] = normalizeDepositSource(name)
+	}
+	priority := []string{}
+	for _, name := range append(append(cfg.Priority, SubBalancePrincipal), cfg.Names...) {
+		if name = normalizeDepositSource(name); name != "" && !contains(priority, name) {
+			priority = append(priority, name)
+		}
+	}
+	cfg.Priority = priority
+}
+
+// checkSubBalance normalizes and validates the sub-balance a deposit targets. The principal is
+// stored as an empty name.
+func (s *transactionService) checkSubBalance(req *models.TransactionRequest) error {
+	req.SubBalance = normalizeDepositSource(req.SubBalance)
+	if req.SubBalance == SubBalancePrincipal {
+		req.SubBalance = ""
+	}
+
+	var v validation
+	v.check(req.SubBalance == "" || contains(s.cfg.SubBalances.Names, req.SubBalance),
+		"sub_balance", "Sub-balance must be one of: "+strings.Join(append([]string{SubBalancePrincipal}, s.cfg.SubBalances.Names...), ", "))
+	return v.err()
+}
+
+// drawSubBalances takes a debit of amount from account out of its parts in priority order: the
+// principal up to what it holds, each sub-balance up to its amount, then the sub-balances missing
+// from priority by name. What none of them covers, such as an overdraft, comes out of the principal.
+// Sub-balances drawn to zero are removed. Call it before the debit is applied to account.Balance
+// and before the account is re-signed.
+func drawSubBalances(account *models.Account, amount float64, decimals int, priority []string) {
+	if len(account.SubBalances) == 0 {
+		return
+	}
+
+	principal := toMinorUnits(account.Balance, decimals)
+	var rest []string
+	for name, held := range account.SubBalances {
+		principal -= toMinorUnits(held, decimals)
+		if !contains(priority, name) {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	order := append([]string{}, priority...)
+	if !contains(order, SubBalancePrincipal) {
+		order = append([]string{SubBalancePrincipal}, order...)
+	}
+
+	remaining := toMinorUnits(amount, decimals)
+	for _, name := range append(order, rest...) {
+		if remaining <= 0 {
+			break
+		}
+		if name == SubBalancePrincipal {
+			remaining -= min(remaining, max(principal, 0))
+			continue
+		}
+		held := toMinorUnits(account.SubBalances[name], decimals)
+		take := min(remaining, held)
+		if take <= 0 {
+			continue
+		}
+		if held == take {
+			delete(account.SubBalances, name)
+		} else {
+			account.SubBalances[name] = fromMinorUnits(held-take, decimals)
+		}
+		remaining -= take
+	}
+}
+
+// drawSubBalances applies drawSubBalances with the configured priority.
+func (s *transactionService) drawSubBalances(account *models.Account, amount float64) {
+	decimals := currencyDecimals(account.Currency, s.cfg.CurrencyDecimals)
+	drawSubBalances(account, amount, decimals, s.cfg.SubBalances.Priority)
+}
+
+// creditSubBalance adds a completed deposit to the named sub-balance of account. Call it before
+// the account is re-signed.
+func (s *transactionService) creditSubBalance(account *models.Account, name string, amount float64) {
+	if account.SubBalances == nil {
+		account.SubBalances = models.SubBalances{}
+	}
+	account.SubBalances[name] = s.roundAmount(account.SubBalances[name]+amount, account.Currency)
+}
+
+// GetSubBalances returns the parts of the balance of one of the user's accounts: the principal
+// first, then the sub-balances by name. The principal is negative while the account is overdrawn.
+func (s *transactionService) GetSubBalances(accountID int, claims *models.Claims) ([]models.SubBalance, error) {
+	account, err := s.loadOwnedAccount(s.db, accountID, claims.UserID, claims.Sandbox)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(account.SubBalances))
+	principal := account.Balance
+	for name, amount := range account.SubBalances {
+		names = append(names, name)
+		principal -= amount
+	}
+	sort.Strings(names)
+
+	subs := []models.SubBalance{{Name: SubBalancePrincipal, Amount: s.roundAmount(principal, account.Currency)}}
+	for _, name := range names {
+		subs = append(subs, models.SubBalance{Name: name, Amount: account.SubBalances[name]})
+	}
+	return subs, nil
+}
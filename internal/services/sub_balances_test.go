@@ -0,0 +1,157 @@
+// Path: internal/services/sub_balances_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDrawSubBalancesFollowsPriority(t *testing.T) {
+	tests := []struct {
+		name     string
+		priority []string
+		amount   float64
+		want     models.SubBalances
+	}{
+		{"principal first", []string{SubBalancePrincipal, "bonus"}, 80, models.SubBalances{"bonus": 20, "promo": 10}},
+		{"bonus first", []string{"bonus", SubBalancePrincipal}, 40, models.SubBalances{"promo": 10}},
+		{"bonus first, partly", []string{"bonus", SubBalancePrincipal}, 25, models.SubBalances{"bonus": 5, "promo": 10}},
+		{"missing names drawn last", []string{"bonus"}, 105, models.SubBalances{"promo": 5}},
+		{"overdraft leaves the sub-balances empty", nil, 150, models.SubBalances{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 70 principal, 30 bonus, 10 promo.
+			account := &models.Account{Balance: 110, SubBalances: models.SubBalances{"bonus": 30, "promo": 10}}
+			drawSubBalances(account, tt.amount, 2, tt.priority)
+			if !reflect.DeepEqual(account.SubBalances, tt.want) {
+				t.Errorf("sub-balances = %v, want %v", account.SubBalances, tt.want)
+			}
+		})
+	}
+}
+
+func TestBalanceHashCoversSubBalances(t *testing.T) {
+	account := &models.Account{ID: 7, Balance: 100, SubBalances: models.SubBalances{"bonus": 30}}
+	hash := balanceHash(account, testSecret)
+
+	account.SubBalances["bonus"] = 60
+	if balanceHash(account, testSecret) == hash {
+		t.Error("hash unchanged after a sub-balance changed")
+	}
+
+	plain := &models.Account{ID: 7, Balance: 100}
+	if balanceHash(plain, testSecret) == hash {
+		t.Error("account without sub-balances has the same hash")
+	}
+}
+
+func TestSubBalancesDepositAndWithdraw(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "subs")
+	account := seedAccount(t, db, user, 50, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{
+		SubBalances: SubBalanceConfig{Names: []string{"bonus"}, Priority: []string{"bonus", SubBalancePrincipal}},
+	})
+
+	if _, err := s.ProcessDeposit(&models.TransactionRequest{AccountID: account.ID, Amount: 30, SubBalance: "bonus"}, claimsFor(user)); err != nil {
+		t.Fatalf("deposit: %v", err)
+	}
+	if _, err := s.ProcessWithdraw(&models.TransactionRequest{AccountID: account.ID, Amount: 40}, claimsFor(user)); err != nil {
+		t.Fatalf("withdraw: %v", err)
+	}
+
+	subs, err := s.GetSubBalances(account.ID, claimsFor(user))
+	if err != nil {
+		t.Fatalf("sub-balances: %v", err)
+	}
+	want := []models.SubBalance{{Name: SubBalancePrincipal, Amount: 40}}
+	if !reflect.DeepEqual(subs, want) {
+		t.Errorf("sub-balances = %v, want %v (bonus drawn first)", subs, want)
+	}
+
+	stored := reloadAccount(t, db, account.ID)
+	if stored.BalanceHash != balanceHash(stored, testSecret) {
+		t.Error("stored hash does not match the stored balance and sub-balances")
+	}
+}
+
+func TestSubBalanceTamperingFailsIntegrity(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "tamper")
+	account := seedAccount(t, db, user, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{SubBalances: SubBalanceConfig{Names: []string{"bonus"}}})
+
+	if _, err := s.ProcessDeposit(&models.TransactionRequest{AccountID: account.ID, Amount: 30, SubBalance: "bonus"}, claimsFor(user)); err != nil {
+		t.Fatalf("deposit: %v", err)
+	}
+	if err := db.Exec(`UPDATE accounts SET sub_balances = '{"bonus": 0}' WHERE id = ?`, account.ID).Error; err != nil {
+		t.Fatalf("tamper: %v", err)
+	}
+
+	if _, err := s.GetSubBalances(account.ID, claimsFor(user)); err == nil {
+		t.Error("tampered sub-balance passed the integrity check")
+	}
+}
+
+func TestDormancyFeeFollowsPriority(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "dormant")
+	account := seedAccount(t, db, user, 50, "USD")
+	subs := SubBalanceConfig{Names: []string{"bonus"}, Priority: []string{"bonus", SubBalancePrincipal}}
+	transactions := NewTransactionService(db, testSecret, TransactionConfig{SubBalances: subs})
+	if _, err := transactions.ProcessDeposit(&models.TransactionRequest{AccountID: account.ID, Amount: 30, SubBalance: "bonus"}, claimsFor(user)); err != nil {
+		t.Fatalf("deposit: %v", err)
+	}
+
+	accounts := NewAccountService(db, testSecret, AccountConfig{
+		Clock:       utils.NewManualClock(time.Now().Add(48 * time.Hour)),
+		Dormancy:    DormancyConfig{After: 24 * time.Hour, Fee: 10},
+		SubBalances: subs,
+	})
+	if flagged, err := accounts.FlagDormant(); err != nil || flagged != 1 {
+		t.Fatalf("FlagDormant = %d, %v; want 1", flagged, err)
+	}
+
+	stored := reloadAccount(t, db, account.ID)
+	if want := (models.SubBalances{"bonus": 20}); stored.Balance != 70 || !reflect.DeepEqual(stored.SubBalances, want) {
+		t.Errorf("balance %v, sub-balances %v; want 70 with %v (fee from the bonus)", stored.Balance, stored.SubBalances, want)
+	}
+	if stored.BalanceHash != balanceHash(stored, testSecret) {
+		t.Error("stored hash does not match the stored balance and sub-balances")
+	}
+}
+
+func TestCheckSubBalance(t *testing.T) {
+	s := NewTransactionService(nil, testSecret, TransactionConfig{SubBalances: SubBalanceConfig{Names: []string{"Bonus"}}}).(*transactionService)
+	for in, want := range map[string]string{"": "", "principal": "", " BONUS ": "bonus"} {
+		req := &models.TransactionRequest{SubBalance: in}
+		if err := s.checkSubBalance(req); err != nil || req.SubBalance != want {
+			t.Errorf("sub-balance %q = %q, %v; want %q", in, req.SubBalance, err, want)
+		}
+	}
+	err := s.checkSubBalance(&models.TransactionRequest{SubBalance: "promo"})
+	if names := fieldNames(t, err); len(names) != 1 || names[0] != "sub_balance" {
+		t.Errorf("unknown sub-balance: fields %v, want sub_balance", names)
+	}
+}
+
+func TestSubBalanceConfigPriority(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		cfg  SubBalanceConfig
+		want []string
+	}{
+		{"default", SubBalanceConfig{Names: []string{"bonus", "promo"}}, []string{SubBalancePrincipal, "bonus", "promo"}},
+		{"partial priority", SubBalanceConfig{Names: []string{"bonus", "promo"}, Priority: []string{"Promo"}}, []string{"promo", SubBalancePrincipal, "bonus"}},
+		{"duplicates", SubBalanceConfig{Names: []string{"bonus"}, Priority: []string{"bonus", "BONUS", "principal"}}, []string{"bonus", SubBalancePrincipal}},
+	} {
+		tt.cfg.normalize()
+		if !reflect.DeepEqual(tt.cfg.Priority, tt.want) {
+			t.Errorf("%s: priority %v, want %v", tt.name, tt.cfg.Priority, tt.want)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+// Path: internal/services/timestamps_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTransactionTimestampsComeFromTheServer(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "forger")
+	account := seedAccount(t, db, user, 0, "USD")
+	now := time.Date(2024, 6, 1, 9, 30, 0, 0, time.UTC)
+	s := NewTransactionService(db, testSecret, TransactionConfig{Clock: utils.NewManualClock(now)})
+
+	// Even if a forged created_at got past the middleware, the request has no place for it.
+	var req models.TransactionRequest
+	body := fmt.Sprintf(`{"account_id": %d, "amount": 10, "created_at": "2099-01-01T00:00:00Z"}`, account.ID)
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if _, err := s.ProcessDeposit(&req, claimsFor(user)); err != nil {
+		t.Fatalf("deposit: %v", err)
+	}
+
+	var stored models.Transaction
+	if err := db.Where("to_account_id = ?", account.ID).First(&stored).Error; err != nil {
+		t.Fatalf("load transaction: %v", err)
+	}
+	if !stored.CreatedAt.Equal(now) {
+		t.Errorf("created_at = %v, want the server time %v", stored.CreatedAt, now)
+	}
+}
@@ -0,0 +1,221 @@
+// Path: internal/services/transaction_history.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"crypto/hmac"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// History paging and tagging limits.
+const (
+	DefaultHistoryLimit   = 50
+	MaxHistoryLimit       = 100
+	MaxHistoryOffset      = 10000 // Deeper pages must use a cursor
+	MaxTagsPerTransaction = 10
+	maxTagLength          = 32
+)
+
+var tagPattern = regexp.MustCompile(`^[\p{L}\p{N}_-]+$`)
+
+// ListTransactions returns the transactions touching the user's accounts, newest first, and the cursor
+// of the next page when this one is full. Cursor pages cost the same at any depth; offsets are capped.
+func (s *transactionService) ListTransactions(userID uint, filter models.TransactionFilter) ([]models.Transaction, string, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultHistoryLimit
+	}
+	if filter.Limit > MaxHistoryLimit {
+		filter.Limit = MaxHistoryLimit
+	}
+	if filter.Offset < 0 || filter.Cursor != "" {
+		filter.Offset = 0
+	}
+	if filter.Offset > MaxHistoryOffset {
+		return nil, "", &AppError{Code: 400, Message: "Offset too large", Details: fmt.Sprintf("Offsets above %d are not supported, page with the cursor instead", MaxHistoryOffset)}
+	}
+
+	query := s.db.Model(&models.Transaction{})
+	if filter.AccountID != 0 {
+		var count int64
+		if err := s.db.Model(&models.Account{}).Where("id = ? AND user_id = ?", filter.AccountID, userID).Count(&count).Error; err != nil {
+			return nil, "", &AppError{Code: 500, Message: "Failed to query account", Details: err.Error(), Err: err}
+		}
+		if count == 0 {
+			return nil, "", &AppError{Code: 404, Message: "Account not found or access denied", Details: fmt.Sprintf("account_id: %d, user_id: %d", filter.AccountID, userID)}
+		}
+		if filter.CounterpartyID != 0 {
+			query = query.Where("(from_account_id = ? AND to_account_id = ?) OR (from_account_id = ? AND to_account_id = ?)",
+				filter.AccountID, filter.CounterpartyID, filter.CounterpartyID, filter.AccountID)
+		} else {
+			query = query.Where("from_account_id = ? OR to_account_id = ?", filter.AccountID, filter.AccountID)
+		}
+	} else {
+		query = query.Where("from_account_id IN (?) OR to_account_id IN (?)", ownedAccountIDs(s.db, userID), ownedAccountIDs(s.db, userID))
+	}
+
+	if filter.Tag != "" {
+		query = query.Where("id IN (?)", s.db.Model(&models.TransactionTag{}).Select("transaction_id").Where("user_id = ? AND tag = ?", userID, normalizeTag(filter.Tag)))
+	}
+
+	if filter.Cursor != "" {
+		createdAt, id, err := s.decodeHistoryCursor(filter.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		query = query.Where("(created_at, id) < (?, ?)", createdAt, id)
+	}
+
+	transactions := []models.Transaction{}
+	if err := query.Order("created_at DESC, id DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&transactions).Error; err != nil {
+		return nil, "", &AppError{Code: 500, Message: "Failed to query transactions", Details: err.Error(), Err: err}
+	}
+
+	if err := s.attachTags(userID, transactions); err != nil {
+		return nil, "", err
+	}
+
+	next := ""
+	if len(transactions) == filter.Limit {
+		last := transactions[len(transactions)-1]
+		next = s.encodeHistoryCursor(last.CreatedAt, last.ID)
+	}
+
+	return transactions, next, nil
+}
+
+// historyCursorDomain prefixes the signed cursor data, so that its signature can't be mistaken for
+// another HMAC made with the same secret, such as a balance hash.
+const historyCursorDomain = "history-cursor|"
+
+// encodeHistoryCursor encodes the position after a transaction in the newest-first order. The
+// cursor is opaque to clients and signed, so a page can only continue where the previous one
+// stopped. Positions are keyset (created_at, id), which inserts of newer transactions don't shift.
+func (s *transactionService) encodeHistoryCursor(createdAt time.Time, id string) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(createdAt.UTC().Format(time.RFC3339Nano) + "|" + id))
+	return payload + "." + utils.CreateHMAC(historyCursorDomain+payload, []byte(s.secretKey))
+}
+
+func (s *transactionService) decodeHistoryCursor(cursor string) (time.Time, string, error) {
+	invalid := &AppError{Code: 400, Message: "Invalid cursor", Details: "Use the cursor returned by the previous page"}
+	payload, signature, ok := strings.Cut(cursor, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(utils.CreateHMAC(historyCursorDomain+payload, []byte(s.secretKey)))) {
+		return time.Time{}, "", invalid
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return time.Time{}, "", invalid
+	}
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return time.Time{}, "", invalid
+	}
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return time.Time{}, "", invalid
+	}
+	return t, id, nil
+}
+
+// UpdateTags adds and removes the user's tags on a transaction and returns the resulting tags.
+func (s *transactionService) UpdateTags(transactionID string, req *models.TagsRequest, claims *models.Claims) ([]string, error) {
+	add, remove, err := validateTagsRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := findVisibleTransaction(tx, transactionID, claims.UserID); err != nil {
+			return err
+		}
+
+		if len(remove) > 0 {
+			if err := tx.Where("transaction_id = ? AND user_id = ? AND tag IN ?", transactionID, claims.UserID, remove).Delete(&models.TransactionTag{}).Error; err != nil {
+				return &AppError{Code: 500, Message: "Failed to remove tags", Details: err.Error(), Err: err}
+			}
+		}
+		for _, tag := range add {
+			entry := models.TransactionTag{TransactionID: transactionID, UserID: int(claims.UserID), Tag: tag}
+			if err := tx.Where(entry).FirstOrCreate(&entry).Error; err != nil {
+				return &AppError{Code: 500, Message: "Failed to add tag", Details: err.Error(), Err: err}
+			}
+		}
+
+		if err := tx.Model(&models.TransactionTag{}).Where("transaction_id = ? AND user_id = ?", transactionID, claims.UserID).Order("tag").Pluck("tag", &tags).Error; err != nil {
+			return &AppError{Code: 500, Message: "Failed to query tags", Details: err.Error(), Err: err}
+		}
+		if len(tags) > MaxTagsPerTransaction {
+			return &AppError{Code: 400, Message: "Too many tags", Details: fmt.Sprintf("A transaction can have at most %d tags", MaxTagsPerTransaction)}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if tags == nil {
+		tags = []string{}
+	}
+	return tags, nil
+}
+
+// attachTags fills in the user's tags of the listed transactions with a single query.
+func (s *transactionService) attachTags(userID uint, transactions []models.Transaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(transactions))
+	for i, t := range transactions {
+		ids[i] = t.ID
+	}
+
+	var tags []models.TransactionTag
+	if err := s.db.Where("user_id = ? AND transaction_id IN ?", userID, ids).Order("tag").Find(&tags).Error; err != nil {
+		return &AppError{Code: 500, Message: "Failed to query tags", Details: err.Error(), Err: err}
+	}
+
+	byTransaction := make(map[string][]string)
+	for _, t := range tags {
+		byTransaction[t.TransactionID] = append(byTransaction[t.TransactionID], t.Tag)
+	}
+	for i := range transactions {
+		transactions[i].Tags = byTransaction[transactions[i].ID]
+	}
+
+	return nil
+}
+
+// findVisibleTransaction loads a transaction touching one of the user's accounts.
+func findVisibleTransaction(tx *gorm.DB, transactionID string, userID uint) (*models.Transaction, error) {
+	transaction, err := NewTransactionRepository(tx).FindVisible(transactionID, userID)
+	if err != nil {
+		return nil, transactionLookupError(err, transactionID)
+	}
+	return transaction, nil
+}
+
+// transactionLookupError translates a repository error for a transaction visible to the user.
+func transactionLookupError(err error, transactionID string) error {
+	if errors.Is(err, ErrNotFound) {
+		return &AppError{Code: 404, Message: "Transaction not found or access denied", Details: fmt.Sprintf("transaction_id: %s", transactionID)}
+	}
+	return &AppError{Code: 500, Message: "Failed to query transaction", Details: err.Error(), Err: err}
+}
+
+// ownedAccountIDs is a subquery selecting the IDs of the user's accounts.
+func ownedAccountIDs(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).Model(&models.Account{}).Select("id").Where("user_id = ?", userID)
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
@@ -0,0 +1,116 @@
+// Path: internal/services/transaction_history_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestValidateTagsRequest(t *testing.T) {
+	add, remove, err := validateTagsRequest(&models.TagsRequest{Add: []string{" Groceries ", "groceries", "café"}, Remove: []string{"SALARY"}})
+	if err != nil {
+		t.Fatalf("valid request: %v", err)
+	}
+	if !reflect.DeepEqual(add, []string{"groceries", "café"}) || !reflect.DeepEqual(remove, []string{"salary"}) {
+		t.Errorf("add %v, remove %v", add, remove)
+	}
+
+	_, _, err = validateTagsRequest(&models.TagsRequest{Add: []string{"ok", "", "two words"}, Remove: []string{strings.Repeat("x", maxTagLength+1)}})
+	appErr := wantAppError(t, err, 400)
+	var fields []string
+	for _, f := range appErr.Fields {
+		fields = append(fields, f.Field)
+	}
+	if want := []string{"add[1]", "add[2]", "remove[0]"}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("invalid fields = %v, want %v", fields, want)
+	}
+}
+
+func TestTagsAndHistoryFilter(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "tagger")
+	account := seedAccount(t, db, user, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{})
+	for _, amount := range []float64{10, 20} {
+		if _, err := s.ProcessDeposit(&models.TransactionRequest{AccountID: account.ID, Amount: amount}, claimsFor(user)); err != nil {
+			t.Fatalf("deposit: %v", err)
+		}
+	}
+	history, _, err := s.ListTransactions(uint(user.ID), models.TransactionFilter{})
+	if err != nil || len(history) != 2 {
+		t.Fatalf("history = %v, %v", history, err)
+	}
+	tagged := history[0].ID
+
+	tags, err := s.UpdateTags(tagged, &models.TagsRequest{Add: []string{"Salary", "bonus"}}, claimsFor(user))
+	if err != nil || !reflect.DeepEqual(tags, []string{"bonus", "salary"}) {
+		t.Fatalf("add tags = %v, %v", tags, err)
+	}
+	tags, err = s.UpdateTags(tagged, &models.TagsRequest{Remove: []string{"bonus"}}, claimsFor(user))
+	if err != nil || !reflect.DeepEqual(tags, []string{"salary"}) {
+		t.Errorf("remove tag = %v, %v", tags, err)
+	}
+
+	filtered, _, err := s.ListTransactions(uint(user.ID), models.TransactionFilter{Tag: "SALARY"})
+	if err != nil || len(filtered) != 1 || filtered[0].ID != tagged || !reflect.DeepEqual(filtered[0].Tags, []string{"salary"}) {
+		t.Errorf("filtered by salary = %+v, %v", filtered, err)
+	}
+	if filtered, _, _ := s.ListTransactions(uint(user.ID), models.TransactionFilter{Tag: "bonus"}); len(filtered) != 0 {
+		t.Errorf("filtered by a removed tag = %+v", filtered)
+	}
+
+	stranger := seedUser(t, db, "stranger")
+	_, err = s.UpdateTags(tagged, &models.TagsRequest{Add: []string{"mine"}}, claimsFor(stranger))
+	wantAppError(t, err, 404)
+}
+
+func TestListTransactionsWithCounterparty(t *testing.T) {
+	db := testDB(t)
+	alice := seedUser(t, db, "alice")
+	bob := seedUser(t, db, "bob")
+	carol := seedUser(t, db, "carol")
+	a := seedAccount(t, db, alice, 100, "USD")
+	b := seedAccount(t, db, bob, 100, "USD")
+	c := seedAccount(t, db, carol, 100, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{})
+
+	transfer := func(from *models.Account, owner *models.User, to *models.Account, amount float64) {
+		t.Helper()
+		if err := s.ProcessTransfer(&models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: amount}, claimsFor(owner)); err != nil {
+			t.Fatalf("transfer %d -> %d: %v", from.ID, to.ID, err)
+		}
+	}
+	transfer(a, alice, b, 10)
+	transfer(b, bob, a, 5)
+	transfer(a, alice, c, 7) // Unrelated to bob
+	transfer(c, carol, b, 3) // Unrelated to alice
+	transfer(a, alice, b, 1)
+	if _, err := s.ProcessDeposit(&models.TransactionRequest{AccountID: a.ID, Amount: 20}, claimsFor(alice)); err != nil {
+		t.Fatalf("deposit: %v", err)
+	}
+
+	exchanged, _, err := s.ListTransactions(uint(alice.ID), models.TransactionFilter{AccountID: a.ID, CounterpartyID: b.ID})
+	if err != nil {
+		t.Fatalf("ListTransactions: %v", err)
+	}
+	var amounts []float64
+	for _, tx := range exchanged {
+		amounts = append(amounts, tx.Amount)
+	}
+	sort.Float64s(amounts)
+	if want := []float64{1, 5, 10}; !reflect.DeepEqual(amounts, want) {
+		t.Errorf("amounts exchanged with bob = %v, want %v", amounts, want)
+	}
+
+	page, _, err := s.ListTransactions(uint(alice.ID), models.TransactionFilter{AccountID: a.ID, CounterpartyID: b.ID, Limit: 2, Offset: 2})
+	if err != nil || len(page) != 1 {
+		t.Errorf("second page = %+v, %v; want 1 transaction", page, err)
+	}
+
+	// Only the owner of the account can look at its counterparties.
+	_, _, err = s.ListTransactions(uint(bob.ID), models.TransactionFilter{AccountID: a.ID, CounterpartyID: b.ID})
+	wantAppError(t, err, 404)
+}
@@ -0,0 +1,101 @@
+// Path: internal/services/transaction_reference_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConcurrentTransactionReferences(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "busy")
+	s := NewTransactionService(db, testSecret, TransactionConfig{})
+	const workers, deposits = 8, 5
+	accounts := make([]*models.Account, workers)
+	for i := range accounts {
+		accounts[i] = seedAccount(t, db, user, 0, "USD")
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*deposits)
+	for _, account := range accounts {
+		wg.Add(1)
+		go func(account *models.Account) {
+			defer wg.Done()
+			for i := 0; i < deposits; i++ {
+				if _, err := s.ProcessDeposit(&models.TransactionRequest{AccountID: account.ID, Amount: 1}, claimsFor(user)); err != nil {
+					errs <- err
+				}
+			}
+		}(account)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("deposit: %v", err)
+	}
+
+	var transactions []models.Transaction
+	if err := db.Order("created_at, id").Find(&transactions).Error; err != nil {
+		t.Fatalf("load transactions: %v", err)
+	}
+	if len(transactions) != workers*deposits {
+		t.Fatalf("%d transactions, want %d", len(transactions), workers*deposits)
+	}
+	format := regexp.MustCompile(fmt.Sprintf(`^TXN-%d-(\d{6,})$`, time.Now().UTC().Year()))
+	seen := make(map[string]bool)
+	for _, tx := range transactions {
+		match := format.FindStringSubmatch(tx.Reference)
+		if match == nil {
+			t.Errorf("transaction %s has reference %q", tx.ID, tx.Reference)
+			continue
+		}
+		if seen[tx.Reference] {
+			t.Errorf("reference %s assigned twice", tx.Reference)
+		}
+		seen[tx.Reference] = true
+		if n, _ := strconv.Atoi(match[1]); n <= 0 {
+			t.Errorf("reference %s has number %d", tx.Reference, n)
+		}
+	}
+
+	// Support finds a transaction by the reference a customer reads out.
+	quoted := transactions[3]
+	page, err := s.SearchTransactions(models.AdminTransactionFilter{Reference: " " + strings.ToLower(quoted.Reference) + " "})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if page.Total != 1 || page.Transactions[0].ID != quoted.ID {
+		t.Errorf("search by %s = %+v, want transaction %s", quoted.Reference, page.Transactions, quoted.ID)
+	}
+}
+
+func TestTransactionReferencesIncrease(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "steady")
+	account := seedAccount(t, db, user, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{})
+
+	var previous int
+	for i := 0; i < 3; i++ {
+		req := &models.TransactionRequest{AccountID: account.ID, Amount: 1}
+		if _, err := s.ProcessDeposit(req, claimsFor(user)); err != nil {
+			t.Fatalf("deposit: %v", err)
+		}
+		var tx models.Transaction
+		if err := db.First(&tx, "id = ?", req.TransactionID).Error; err != nil {
+			t.Fatalf("load transaction: %v", err)
+		}
+		n, err := strconv.Atoi(tx.Reference[strings.LastIndexByte(tx.Reference, '-')+1:])
+		if err != nil || n <= previous {
+			t.Errorf("reference %s after number %d, want a higher one", tx.Reference, previous)
+		}
+		previous = n
+	}
+}
@@ -3,214 +3,1017 @@ package services
 
 import (
 	"bank-api/internal/models"
+	"bank-api/pkg/accountnumber"
 	"bank-api/pkg/utils"
+	"context"
 	"errors"
 	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
 )
 
 // TransactionService handles transaction-related operations.
 type TransactionService interface {
-	ProcessDeposit(req *models.TransactionRequest, claims *models.Claims) error
-	ProcessWithdraw(req *models.TransactionRequest, claims *models.Claims) error
+	ProcessDeposit(req *models.TransactionRequest, claims *models.Claims) (*models.AccountBalance, error)
+	ProcessSplitDeposit(req *models.SplitDepositRequest, claims *models.Claims) ([]models.DepositResult, error)
+	PreviewDeposit(req *models.TransactionRequest, claims *models.Claims) (*models.DepositPreview, error)
+	ProcessWithdraw(req *models.TransactionRequest, claims *models.Claims) (*models.AccountBalance, error)
 	ProcessTransfer(req *models.TransferRequest, claims *models.Claims) error
+	QuoteTransfer(req *models.TransferRequest, claims *models.Claims) (*models.TransferQuote, error)
+	ApproveTransfer(transactionID string, claims *models.Claims) error
+	RejectTransfer(transactionID string, claims *models.Claims) error
+	CancelTransfer(transactionID string, claims *models.Claims) error
+	AcceptTransfer(transactionID string, claims *models.Claims) error
+	DeclineTransfer(transactionID string, claims *models.Claims) error
+	ListTransactions(userID uint, filter models.TransactionFilter) ([]models.Transaction, string, error)
+	SearchTransactions(filter models.AdminTransactionFilter) (*models.AdminTransactionPage, error)
+	UpdateTags(transactionID string, req *models.TagsRequest, claims *models.Claims) ([]string, error)
+	ListLedgerEntries(userID uint, accountID int, filter models.LedgerFilter) ([]models.LedgerEntry, error)
+	ProcessBatch(req *models.BatchTransferRequest, claims *models.Claims) (*models.BatchTransfer, error)
+	GetBatch(batchID int, userID uint) (*models.BatchTransfer, error)
+	GetLimits(userID uint) (*models.SpendingLimits, error)
+	SetLimits(userID uint, req *models.SpendingLimits) (*models.SpendingLimits, error)
+	RefundTransfer(transactionID string, req *models.RefundRequest, claims *models.Claims) (*models.Transaction, error)
+	AdjustBalance(accountID int, req *models.AdjustmentRequest, claims *models.Claims) (*models.Transaction, error)
+	AccrueInterest() (int, error)
+	Currencies() []models.Currency
+	ValidateTransfer(req *models.TransferRequest, claims *models.Claims) (*models.TransferValidation, error)
+	DistributeToBeneficiaries(accountID int, req *models.DistributionRequest, claims *models.Claims) ([]models.Transaction, error)
+	PlaceHold(accountID int, req *models.HoldRequest, claims *models.Claims) (*models.Hold, error)
+	GetHolds(accountID int, claims *models.Claims) ([]models.Hold, error)
+	CaptureHold(holdID int, req *models.CaptureRequest, claims *models.Claims) (*models.Hold, error)
+	ReleaseHold(holdID int, claims *models.Claims) (*models.Hold, error)
+	RequestMoney(req *models.MoneyRequestCreate, claims *models.Claims) (*models.MoneyRequest, error)
+	GetMoneyRequests(userID uint) ([]models.MoneyRequest, error)
+	PayMoneyRequest(requestID int, req *models.MoneyRequestPayment, claims *models.Claims) (*models.MoneyRequest, *models.TransferRequest, error)
+	RejectMoneyRequest(requestID int, claims *models.Claims) (*models.MoneyRequest, error)
+	ExpireMoneyRequests() (int, error)
+	Velocity(userID uint, sandbox bool) (*models.Velocity, error)
+	ListEvents(filter models.EventFilter) ([]models.Event, error)
+	GetSubBalances(accountID int, claims *models.Claims) ([]models.SubBalance, error)
+}
+
+// TransactionConfig holds the tunable limits of the transaction service.
+type TransactionConfig struct {
+	// ApprovalThreshold is the transfer amount above which a second approver is required. Zero disables it.
+	ApprovalThreshold float64
+	// DepositHoldThreshold is the cumulative deposit amount within DepositHoldWindow above which
+	// new deposits are held for review instead of being credited. Zero disables it.
+	DepositHoldThreshold float64
+	// DepositHoldWindow is the rolling window for DepositHoldThreshold. Defaults to 24 hours.
+	DepositHoldWindow time.Duration
+	// CurrencyDecimals overrides the decimal places allowed in amounts per currency.
+	// Currencies not listed use their ISO 4217 minor units, or 2.
+	CurrencyDecimals map[string]int
+	// MinAccountAge is how old an account must be before withdrawals and transfers from it are allowed.
+	MinAccountAge time.Duration
+	// DailySpendingLimit caps the withdrawals and transfers of a user per calendar day. Users may set a
+	// lower limit for themselves, but never a higher one. Zero disables the system limit.
+	DailySpendingLimit float64
+	// Fees configures transfer fees and waivers.
+	Fees FeeConfig
+	// Policies configures the rules of the account types.
+	Policies AccountPolicyConfig
+	// DepositSources restricts and limits the declared sources of deposits.
+	DepositSources DepositSourceConfig
+	// ConfirmationThreshold is the transfer amount above which a one-time code sent by CodeSender
+	// must confirm the transfer. Zero disables it.
+	ConfirmationThreshold float64
+	// ConfirmationTTL is how long a confirmation code stays valid. Defaults to DefaultConfirmationTTL.
+	ConfirmationTTL time.Duration
+	// CodeSender delivers confirmation codes. Defaults to LogCodeSender.
+	CodeSender CodeSender
+	// FreezeRules freeze accounts automatically on suspicious activity.
+	FreezeRules FreezeRuleConfig
+	// Notifier tells users their account was frozen. Defaults to LogNotifier.
+	Notifier Notifier
+	// LedgerEntries additionally records completed transfers as linked debit and credit legs.
+	LedgerEntries bool
+	// DuplicateWindow returns the result of an identical transfer (same accounts, amount and
+	// description) made by the user within the window, instead of executing a repeat. It catches
+	// accidental double submits; it is best effort and not a substitute for idempotency keys. Zero disables it.
+	DuplicateWindow time.Duration
+	// OwnAccountsOnly rejects transfers to accounts of other users; users can only move money between
+	// their own accounts. Refunds still return money to the original sender.
+	OwnAccountsOnly bool
+	// OwnTransferFastPath lets transfers between two accounts of the same user skip the fee, the
+	// confirmation code, the approval threshold and the daily spending limit, and leaves them out of
+	// the spending counted against that limit. Balance hashes and the source account checks still apply.
+	OwnTransferFastPath bool
+	// RecordFailures records rejected deposits, withdrawals and transfers with status "failed".
+	RecordFailures bool
+	// MoneyRequestTTL is how long a money request can be paid or rejected; after that it expires.
+	// Zero keeps requests pending until the payer answers.
+	MoneyRequestTTL time.Duration
+	// Clock supplies the current time. Defaults to the wall clock.
+	Clock utils.Clock
+	// AccountNumbers is the configured account number scheme; numbers in any supported format are accepted.
+	AccountNumbers accountnumber.Scheme
+	// IntegrityAlerter is told about failed balance integrity checks. Defaults to NoopIntegrityAlerter.
+	IntegrityAlerter IntegrityAlerter
+	// VelocityCacheTTL is how long the velocity of a user is reused before it is computed again.
+	// Defaults to DefaultVelocityCacheTTL.
+	VelocityCacheTTL time.Duration
+	// DescriptionThreshold is the transfer amount above which a description is required. Zero disables it.
+	DescriptionThreshold float64
+	// SubBalances are the named parts of a balance deposits can be credited to, and the order debits
+	// draw from them.
+	SubBalances SubBalanceConfig
 }
 
 type transactionService struct {
 	db        *gorm.DB
 	secretKey string
+	cfg       TransactionConfig
+	fees      *feeEngine
+	policies  map[string]AccountPolicy
+	clock     utils.Clock
+	velocity  *velocityCache
 }
 
 // NewTransactionService creates a new TransactionService.
-func NewTransactionService(db *gorm.DB, secretKey string) TransactionService {
+func NewTransactionService(db *gorm.DB, secretKey string, cfg TransactionConfig) TransactionService {
+	if cfg.DepositHoldWindow <= 0 {
+		cfg.DepositHoldWindow = 24 * time.Hour
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = utils.RealClock
+	}
+	if cfg.AccountNumbers == nil {
+		cfg.AccountNumbers = accountnumber.Internal{}
+	}
+	if cfg.ConfirmationTTL <= 0 {
+		cfg.ConfirmationTTL = DefaultConfirmationTTL
+	}
+	if cfg.CodeSender == nil {
+		cfg.CodeSender = LogCodeSender{}
+	}
+	if cfg.IntegrityAlerter == nil {
+		cfg.IntegrityAlerter = NoopIntegrityAlerter{}
+	}
+	if cfg.VelocityCacheTTL <= 0 {
+		cfg.VelocityCacheTTL = DefaultVelocityCacheTTL
+	}
+	if cfg.FreezeRules.Window <= 0 {
+		cfg.FreezeRules.Window = time.Hour
+	}
+	if cfg.Notifier == nil {
+		cfg.Notifier = LogNotifier{}
+	}
+	for i, source := range cfg.DepositSources.Allowed {
+		cfg.DepositSources.Allowed[i] = normalizeDepositSource(source)
+	}
+	for i, source := range cfg.DepositSources.Flagged {
+		cfg.DepositSources.Flagged[i] = normalizeDepositSource(source)
+	}
+	cfg.SubBalances.normalize()
 	return &transactionService{
 		db:        db,
 		secretKey: secretKey,
+		cfg:       cfg,
+		fees:      newFeeEngine(cfg.Fees),
+		policies:  newAccountPolicies(cfg.Policies),
+		clock:     cfg.Clock,
+		velocity:  &velocityCache{entries: make(map[velocityKey]cachedVelocity)},
 	}
 }
 
+// WithContext returns a copy of the service whose queries run under ctx, so a request's deadline
+// and cancellation reach the database.
+func (s *transactionService) WithContext(ctx context.Context) TransactionService {
+	bound := *s
+	bound.db = dbWithContext(s.db, ctx)
+	return &bound
+}
+
 // AppError is a custom error type that includes an HTTP status code.
 type AppError struct {
 	Code    int    `json:"-"`
 	Message string `json:"message"`
 	Details string `json:"details"`
-	Err     error  `json:"-"`
+	// Fields lists every invalid request field when the error comes from request validation.
+	Fields []FieldError `json:"fields,omitempty"`
+	Err    error        `json:"-"`
 }
 
 func (e *AppError) Error() string {
 	return fmt.Sprintf("AppError: %s (Code: %d, Details: %s)", e.Message, e.Code, e.Details)
 }
 
+// pgUniqueViolation is the Postgres SQLSTATE of a unique constraint violation.
+const pgUniqueViolation = "23505"
+
+// isUniqueViolation reports whether err is a Postgres unique constraint violation,
+// i.e. a concurrent request inserted the same row first.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation
+}
+
 // ProcessDeposit handles a deposit transaction.
-func (s *transactionService) ProcessDeposit(req *models.TransactionRequest, claims *models.Claims) error {
-	if req.Amount <= 0 {
-		return &AppError{Code: 400, Message: "Invalid deposit amount", Details: "Amount must be positive"}
+// Deposits pushing the account over the AML threshold, or from a flagged source, are recorded as "held" and not credited.
+// It returns the resulting balance of the account.
+func (s *transactionService) ProcessDeposit(req *models.TransactionRequest, claims *models.Claims) (*models.AccountBalance, error) {
+	if err := validateTransactionRequest(req); err != nil {
+		return nil, err
+	}
+	flagged, err := s.checkDepositSource(req)
+	if err != nil {
+		return nil, err
 	}
 
-	return s.db.Transaction(func(tx *gorm.DB) error {
-		var account models.Account
-		if err := tx.Where("id = ? AND user_id = ?", req.AccountID, claims.UserID).First(&account).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return &AppError{Code: 404, Message: "Account not found or access denied", Details: fmt.Sprintf("account_id: %d, user_id: %d", req.AccountID, claims.UserID)}
-			}
-			return &AppError{Code: 500, Message: "Failed to query account", Details: err.Error(), Err: err}
+	var balance *models.AccountBalance
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		account, err := s.loadOwnedAccount(tx, req.AccountID, claims.UserID, claims.Sandbox)
+		if err != nil {
+			return err
 		}
+		balance, err = s.creditDeposit(tx, account, req, claims, flagged)
+		return err
+	})
+	s.applyFreezeRules(err, "deposit", req.AccountID)
+	if err := s.recordFailure(err, claims, models.Transaction{
+		ToAccountID: &req.AccountID,
+		Amount:      req.Amount,
+		Type:        "deposit",
+		Description: req.Description,
+		Source:      req.Source,
+	}); err != nil {
+		return nil, err
+	}
+	return balance, nil
+}
 
-		// Verify balance hash
-		expectedHash := utils.CreateHMAC(fmt.Sprintf("%f:%d", account.Balance, req.AccountID), []byte(s.secretKey))
-		if account.BalanceHash != expectedHash {
-			return &AppError{Code: 500, Message: "Balance integrity check failed", Details: fmt.Sprintf("account_id: %d", req.AccountID)}
-		}
+// creditDeposit deposits req.Amount into an account already loaded and verified within tx.
+// The deposit is recorded as "held" and the balance left as it is if it pushes the account
+// over the AML threshold or comes from a flagged source. req is filled in with the transaction
+// ID and status.
+func (s *transactionService) creditDeposit(tx *gorm.DB, account *models.Account, req *models.TransactionRequest, claims *models.Claims, flagged bool) (*models.AccountBalance, error) {
+	if err := checkAmountPrecision(req.Amount, account.Currency, s.cfg.CurrencyDecimals); err != nil {
+		return nil, err
+	}
+	if err := s.checkCashDenomination(req, account.Currency); err != nil {
+		return nil, err
+	}
+	if err := checkNotClosed(account); err != nil {
+		return nil, err
+	}
+	if err := checkNotDormant(account); err != nil {
+		return nil, err
+	}
+	if err := s.checkSubBalance(req); err != nil {
+		return nil, err
+	}
+
+	hold, _, err := s.shouldHoldDeposit(tx, req.AccountID, req.Amount)
+	if err != nil {
+		return nil, err
+	}
 
+	status := "completed"
+	if hold || flagged {
+		status = "held"
+	} else {
 		// Update the account balance and hash.
-		account.Balance += req.Amount
-		account.BalanceHash = utils.CreateHMAC(fmt.Sprintf("%f:%d", account.Balance, req.AccountID), []byte(s.secretKey))
-		if err := tx.Save(&account).Error; err != nil {
-			return &AppError{Code: 500, Message: "Failed to update account balance", Details: err.Error(), Err: err}
+		if req.SubBalance != "" {
+			s.creditSubBalance(account, req.SubBalance, req.Amount)
 		}
+		account.Balance = s.roundAmount(account.Balance+req.Amount, account.Currency)
+		account.BalanceHash = balanceHash(account, s.secretKey)
+		now := s.clock.Now()
+		account.LastActivityAt = &now
+		if err := tx.Save(account).Error; err != nil {
+			return nil, &AppError{Code: 500, Message: "Failed to update account balance", Details: err.Error(), Err: err}
+		}
+	}
 
-		req.TransactionID = utils.GenerateTransactionID() // Генерация transactionID
-
-		// Insert the transaction record.
-		transaction := models.Transaction{
-			ID:          req.TransactionID,
-			ToAccountID: &req.AccountID,
-			Amount:      req.Amount,
-			Type:        "deposit",
-			Status:      "completed",
-			CreatedAt:   utils.GetCurrentTimestamp(),
+	req.TransactionID = utils.GenerateTransactionID(s.clock.Now()) // Генерация transactionID
+	req.Status = status
+
+	// Insert the transaction record.
+	initiatorID := int(claims.UserID)
+	transaction := models.Transaction{
+		ID:          req.TransactionID,
+		ToAccountID: &req.AccountID,
+		Amount:      req.Amount,
+		Type:        "deposit",
+		Status:      status,
+		Description: req.Description,
+		Source:      req.Source,
+		SubBalance:  req.SubBalance,
+		InitiatorID: &initiatorID,
+		CreatedAt:   s.clock.Now(),
+	}
+	if err := tx.Create(&transaction).Error; err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to insert transaction record", Details: err.Error(), Err: err}
+	}
+	if status == "completed" {
+		if err := appendEvent(tx, EventDeposited, account, &transaction); err != nil {
+			return nil, err
 		}
-		if err := tx.Create(&transaction).Error; err != nil {
-			return &AppError{Code: 500, Message: "Failed to insert transaction record", Details: err.Error(), Err: err}
+	}
+
+	return s.accountBalance(tx, account)
+}
+
+// PreviewDeposit reports whether a deposit would be held, without executing it.
+func (s *transactionService) PreviewDeposit(req *models.TransactionRequest, claims *models.Claims) (*models.DepositPreview, error) {
+	if err := validateTransactionRequest(req); err != nil {
+		return nil, err
+	}
+	flagged, err := s.checkDepositSource(req)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := s.loadOwnedAccount(s.db, req.AccountID, claims.UserID, claims.Sandbox)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkAmountPrecision(req.Amount, account.Currency, s.cfg.CurrencyDecimals); err != nil {
+		return nil, err
+	}
+	if err := s.checkCashDenomination(req, account.Currency); err != nil {
+		return nil, err
+	}
+
+	hold, windowTotal, err := s.shouldHoldDeposit(s.db, req.AccountID, req.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.DepositPreview{
+		AccountID:   req.AccountID,
+		Amount:      req.Amount,
+		WindowTotal: windowTotal,
+		Threshold:   s.cfg.DepositHoldThreshold,
+		WouldHold:   hold || flagged,
+	}, nil
+}
+
+// loadOwnedAccount fetches an account owned by the user and verifies its balance hash.
+// Accounts of the other mode (sandbox or real) are treated as not found.
+func (s *transactionService) loadOwnedAccount(tx *gorm.DB, accountID int, userID uint, sandbox bool) (*models.Account, error) {
+	var account models.Account
+	if err := tx.Where("id = ? AND user_id = ? AND sandbox = ?", accountID, userID, sandbox).First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &AppError{Code: 404, Message: "Account not found or access denied", Details: fmt.Sprintf("account_id: %d, user_id: %d", accountID, userID)}
 		}
+		return nil, &AppError{Code: 500, Message: "Failed to query account", Details: err.Error(), Err: err}
+	}
 
-		return nil
-	})
+	// Verify balance hash
+	expectedHash := balanceHash(&account, s.secretKey)
+	if account.BalanceHash != expectedHash {
+		return nil, &AppError{Code: 500, Message: "Balance integrity check failed", Details: fmt.Sprintf("account_id: %d", accountID), Err: s.integrityFailed(accountID)}
+	}
+
+	return &account, nil
 }
 
-// ProcessWithdraw handles a withdrawal transaction.
-func (s *transactionService) ProcessWithdraw(req *models.TransactionRequest, claims *models.Claims) error {
-	if req.Amount <= 0 {
-		return &AppError{Code: 400, Message: "Invalid withdrawal amount", Details: "Amount must be positive"}
+// shouldHoldDeposit sums the deposits made within the rolling window and reports whether
+// adding amount would exceed the hold threshold.
+func (s *transactionService) shouldHoldDeposit(tx *gorm.DB, accountID int, amount float64) (bool, float64, error) {
+	if s.cfg.DepositHoldThreshold <= 0 {
+		return false, 0, nil
 	}
 
-	return s.db.Transaction(func(tx *gorm.DB) error {
-		var account models.Account
-		if err := tx.Where("id = ? AND user_id = ?", req.AccountID, claims.UserID).First(&account).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return &AppError{Code: 404, Message: "Account not found or access denied", Details: fmt.Sprintf("account_id: %d, user_id: %d", req.AccountID, claims.UserID)}
-			}
-			return &AppError{Code: 500, Message: "Failed to query account", Details: err.Error(), Err: err}
+	var windowTotal float64
+	since := s.clock.Now().Add(-s.cfg.DepositHoldWindow)
+	err := tx.Model(&models.Transaction{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("to_account_id = ? AND type = ? AND status IN ? AND created_at >= ?", accountID, "deposit", []string{"completed", "held"}, since).
+		Scan(&windowTotal).Error
+	if err != nil {
+		return false, 0, &AppError{Code: 500, Message: "Failed to sum recent deposits", Details: err.Error(), Err: err}
+	}
+
+	return windowTotal+amount > s.cfg.DepositHoldThreshold, windowTotal, nil
+}
+
+// ProcessWithdraw handles a withdrawal transaction and returns the resulting balance of the account.
+func (s *transactionService) ProcessWithdraw(req *models.TransactionRequest, claims *models.Claims) (*models.AccountBalance, error) {
+	if err := validateTransactionRequest(req); err != nil {
+		return nil, err
+	}
+
+	var balance *models.AccountBalance
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkTransfersEnabled(tx, claims.UserID); err != nil {
+			return err
 		}
 
-		// Verify balance hash
-		expectedHash := utils.CreateHMAC(fmt.Sprintf("%f:%d", account.Balance, req.AccountID), []byte(s.secretKey))
-		if account.BalanceHash != expectedHash {
-			return &AppError{Code: 500, Message: "Balance integrity check failed", Details: fmt.Sprintf("account_id: %d", req.AccountID)}
+		account, err := s.loadOwnedAccount(tx, req.AccountID, claims.UserID, claims.Sandbox)
+		if err != nil {
+			return err
+		}
+		if err := checkAmountPrecision(req.Amount, account.Currency, s.cfg.CurrencyDecimals); err != nil {
+			return err
+		}
+		if err := checkNotClosed(account); err != nil {
+			return err
+		}
+		if err := checkNotFrozen(account); err != nil {
+			return err
+		}
+		if err := checkNotDormant(account); err != nil {
+			return err
+		}
+		if err := s.checkAccountAge(account); err != nil {
+			return err
+		}
+		if err := s.checkSpendingLimit(tx, claims, account.Currency, req.Amount); err != nil {
+			return err
 		}
 
-		if account.Balance < req.Amount {
-			return &AppError{Code: 400, Message: "Insufficient funds", Details: fmt.Sprintf("account_id: %d, balance: %f, requested: %f", req.AccountID, account.Balance, req.Amount)}
+		available, err := s.availableBalance(tx, account, "")
+		if err != nil {
+			return err
+		}
+		if err := s.policyFor(account).CheckDebit(tx, account, available, req.Amount, s.clock.Now()); err != nil {
+			return err
 		}
 
 		// Update account balance and hash.
-		account.Balance -= req.Amount
-		account.BalanceHash = utils.CreateHMAC(fmt.Sprintf("%f:%d", account.Balance, req.AccountID), []byte(s.secretKey))
-		if err := tx.Save(&account).Error; err != nil {
+		s.drawSubBalances(account, req.Amount)
+		account.Balance = s.roundAmount(account.Balance-req.Amount, account.Currency)
+		account.BalanceHash = balanceHash(account, s.secretKey)
+		now := s.clock.Now()
+		account.LastActivityAt = &now
+		if err := tx.Save(account).Error; err != nil {
 			return &AppError{Code: 500, Message: "Failed to update account balance", Details: err.Error(), Err: err}
 		}
 
-		req.TransactionID = utils.GenerateTransactionID() // Генерация transactionID
+		req.TransactionID = utils.GenerateTransactionID(s.clock.Now()) // Генерация transactionID
+		req.Status = "completed"
 
 		// Insert transaction record.
+		initiatorID := int(claims.UserID)
 		transaction := models.Transaction{
 			ID:            req.TransactionID,
 			FromAccountID: &req.AccountID,
 			Amount:        req.Amount,
 			Type:          "withdraw",
-			Status:        "completed",
-			CreatedAt:     utils.GetCurrentTimestamp(),
+			Status:        req.Status,
+			Description:   req.Description,
+			InitiatorID:   &initiatorID,
+			CreatedAt:     s.clock.Now(),
 		}
 		if err := tx.Create(&transaction).Error; err != nil {
 			return &AppError{Code: 500, Message: "Failed to insert transaction record", Details: err.Error(), Err: err}
 		}
+		if err := appendEvent(tx, EventWithdrawn, account, &transaction); err != nil {
+			return err
+		}
 
-		return nil
+		if err := s.applyRoundUp(tx, claims.UserID, account, req.Amount); err != nil {
+			return err
+		}
+
+		balance, err = s.accountBalance(tx, account)
+		return err
 	})
+	s.applyFreezeRules(err, "withdraw", req.AccountID)
+	if err := s.recordFailure(err, claims, models.Transaction{
+		FromAccountID: &req.AccountID,
+		Amount:        req.Amount,
+		Type:          "withdraw",
+		Description:   req.Description,
+	}); err != nil {
+		return nil, err
+	}
+	return balance, nil
 }
 
 // ProcessTransfer handles a fund transfer between two accounts.
+// Transfers above the configured approval threshold are recorded as
+// "pending_approval" and only move funds once approved by another user.
+// Transfers requiring acceptance are recorded as "incoming_pending" and
+// only move funds once the recipient accepts them.
+// A send_all transfer moves the whole spendable balance; req.Amount is set to the amount sent.
 func (s *transactionService) ProcessTransfer(req *models.TransferRequest, claims *models.Claims) error {
-	if req.Amount <= 0 {
-		return &AppError{Code: 400, Message: "Invalid transfer amount", Details: "Amount must be positive"}
+	if err := validateTransferRequest(req); err != nil {
+		return err
+	}
+	if err := s.resolveDestination(req, claims); err != nil {
+		return err
 	}
 	if req.FromID == req.ToID {
 		return &AppError{Code: 400, Message: "Invalid transfer", Details: "Source and destination accounts must be different"}
 	}
-
-	return s.db.Transaction(func(tx *gorm.DB) error {
-		var fromAccount, toAccount models.Account
-
-		// Check if the source account exists, belongs to the user, and has sufficient funds.
-		if err := tx.Where("id = ? AND user_id = ?", req.FromID, claims.UserID).First(&fromAccount).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return &AppError{Code: 404, Message: "Source account not found or access denied", Details: fmt.Sprintf("account_id: %d, user_id: %d", req.FromID, claims.UserID)}
-			}
-			return &AppError{Code: 500, Message: "Failed to query source account", Details: err.Error(), Err: err}
+	own, err := s.isOwnTransfer(req, claims)
+	if err != nil {
+		return err
+	}
+	var sendAll *sendAllQuote
+	if req.SendAll {
+		if sendAll, err = s.resolveSendAll(req, claims, own); err != nil {
+			return err
 		}
-
-		// Verify balance hash of the source account.
-		expectedFromHash := utils.CreateHMAC(fmt.Sprintf("%f:%d", fromAccount.Balance, req.FromID), []byte(s.secretKey))
-		if fromAccount.BalanceHash != expectedFromHash {
-			return &AppError{Code: 500, Message: "Source account balance integrity check failed", Details: fmt.Sprintf("account_id: %d", req.FromID)}
+	}
+	if err := s.checkTransferDescription(req); err != nil {
+		return err
+	}
+	if duplicate, err := s.findDuplicateTransfer(req, claims); err != nil || duplicate {
+		return err
+	}
+	if !own {
+		if err := s.confirmTransfer(req, claims); err != nil {
+			return err
 		}
+	}
 
-		if fromAccount.Balance < req.Amount {
-			return &AppError{Code: 400, Message: "Insufficient funds in source account", Details: fmt.Sprintf("account_id: %d, balance: %f, requested: %f", req.FromID, fromAccount.Balance, req.Amount)}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkTransfersEnabled(tx, claims.UserID); err != nil {
+			return err
+		}
+		currency, err := s.checkTransferPrecision(tx, req.FromID, req.Amount)
+		if err != nil {
+			return err
 		}
 
-		// Check if the destination account exists.
-		if err := tx.Where("id = ?", req.ToID).First(&toAccount).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return &AppError{Code: 404, Message: "Destination account not found", Details: fmt.Sprintf("account_id: %d", req.ToID)}
+		if !own {
+			if err := s.checkSpendingLimit(tx, claims, currency, req.Amount); err != nil {
+				return err
 			}
-			return &AppError{Code: 500, Message: "Failed to query destination account", Details: err.Error(), Err: err}
 		}
-
-		// Verify balance hash of the destination account
-		expectedToHash := utils.CreateHMAC(fmt.Sprintf("%f:%d", toAccount.Balance, req.ToID), []byte(s.secretKey))
-		if toAccount.BalanceHash != expectedToHash {
-			return &AppError{Code: 500, Message: "Destination account balance integrity check failed", Details: fmt.Sprintf("account_id: %d", req.ToID)}
+		fee, err := s.transferFee(tx, claims.UserID, req.Amount, currency, own)
+		if err != nil {
+			return err
+		}
+		debit := req.Amount + fee.Fee
+		if sendAll != nil {
+			fee, debit = sendAll.fee, sendAll.debit
 		}
 
-		// Perform the transfer (update balances and hashes).
-		fromAccount.Balance -= req.Amount
-		fromAccount.BalanceHash = utils.CreateHMAC(fmt.Sprintf("%f:%d", fromAccount.Balance, req.FromID), []byte(s.secretKey))
-		if err := tx.Save(&fromAccount).Error; err != nil {
-			return &AppError{Code: 500, Message: "Failed to update source account balance", Details: err.Error(), Err: err}
+		fromAccount, toAccount, err := s.loadTransferAccounts(tx, req.FromID, req.ToID, debit, claims.UserID, claims.Sandbox, "")
+		if err != nil {
+			return err
+		}
+		if err := s.checkDestinationOwner(fromAccount, toAccount); err != nil {
+			return err
+		}
+		if err := checkTransferWhitelist(tx, fromAccount, toAccount.ID); err != nil {
+			return err
 		}
 
-		toAccount.Balance += req.Amount
-		toAccount.BalanceHash = utils.CreateHMAC(fmt.Sprintf("%f:%d", toAccount.Balance, req.ToID), []byte(s.secretKey))
-		if err := tx.Save(&toAccount).Error; err != nil {
-			return &AppError{Code: 500, Message: "Failed to update destination account balance", Details: err.Error(), Err: err}
+		status := "completed"
+		if !own && s.cfg.ApprovalThreshold > 0 && req.Amount > s.cfg.ApprovalThreshold {
+			if req.RequireAcceptance {
+				return &AppError{Code: 400, Message: "Invalid transfer", Details: "Transfers that need approval can't require acceptance"}
+			}
+			status = "pending_approval"
+		} else if req.RequireAcceptance {
+			status = "incoming_pending"
+		} else if err := s.applyTransfer(tx, fromAccount, toAccount, req.Amount, fee.Fee); err != nil {
+			return err
 		}
 
-		transactionID := utils.GenerateTransactionID()
+		initiatorID := int(claims.UserID)
+		req.TransactionID = utils.GenerateTransactionID(s.clock.Now())
+		req.Status = status
+		req.Fee = fee.Fee
+		req.FeeWaived = fee.Waived
 		// Кароче успешная транзакция.
 		transaction := models.Transaction{
-			ID:            transactionID,
+			ID:            req.TransactionID,
 			FromAccountID: &req.FromID,
 			ToAccountID:   &req.ToID,
 			Amount:        req.Amount,
 			Type:          "transfer",
-			Status:        "completed",
-			CreatedAt:     utils.GetCurrentTimestamp(),
+			Status:        status,
+			Description:   req.Description,
+			Fee:           fee.Fee,
+			FeeWaived:     fee.Waived,
+			FeeWaiver:     fee.WaiverReason,
+			InitiatorID:   &initiatorID,
+			CreatedAt:     s.clock.Now(),
 		}
 		if err := tx.Create(&transaction).Error; err != nil {
 			return &AppError{Code: 500, Message: "Failed to insert transaction record", Details: err.Error(), Err: err}
 		}
 
+		if status != "completed" {
+			return nil
+		}
+		if err := s.recordTransferLegs(tx, &transaction); err != nil {
+			return err
+		}
+		return s.applyRoundUp(tx, claims.UserID, fromAccount, req.Amount)
+	})
+	s.applyFreezeRules(err, "transfer", req.FromID)
+	return s.recordFailure(err, claims, models.Transaction{
+		FromAccountID: &req.FromID,
+		ToAccountID:   &req.ToID,
+		Amount:        req.Amount,
+		Type:          "transfer",
+		Description:   req.Description,
+	})
+}
+
+// checkTransferDescription requires a description on transfers above DescriptionThreshold, for
+// traceability. It runs after the description was sanitized, so blank ones count as missing.
+func (s *transactionService) checkTransferDescription(req *models.TransferRequest) error {
+	var v validation
+	v.check(s.cfg.DescriptionThreshold <= 0 || req.Amount <= s.cfg.DescriptionThreshold || req.Description != "",
+		"description", fmt.Sprintf("A description is required for transfers above %g", s.cfg.DescriptionThreshold))
+	return v.err()
+}
+
+// resolveDestination fills ToID from a payee or an account number when the transfer uses one.
+func (s *transactionService) resolveDestination(req *models.TransferRequest, claims *models.Claims) error {
+	var err error
+	switch {
+	case req.PayeeID != 0:
+		req.ToID, err = resolvePayee(s.db, req.PayeeID, claims.UserID)
+	case req.ToNumber != "":
+		req.ToID, err = resolveAccountNumber(s.db, req.ToNumber, s.cfg.AccountNumbers)
+	}
+	return err
+}
+
+// findDuplicateTransfer looks for an identical transfer of the user within the duplicate window.
+// When it finds one, it fills in the request with its result and reports true.
+func (s *transactionService) findDuplicateTransfer(req *models.TransferRequest, claims *models.Claims) (bool, error) {
+	if s.cfg.DuplicateWindow <= 0 {
+		return false, nil
+	}
+
+	var prior []models.Transaction
+	err := s.db.Where("initiator_id = ? AND type = ? AND from_account_id = ? AND to_account_id = ? AND amount = ? AND description = ?",
+		claims.UserID, "transfer", req.FromID, req.ToID, req.Amount, req.Description).
+		Where("status IN ? AND created_at >= ?", []string{"completed", "pending_approval", "incoming_pending"}, s.clock.Now().Add(-s.cfg.DuplicateWindow)).
+		Order("created_at DESC").Limit(1).Find(&prior).Error
+	if err != nil {
+		return false, &AppError{Code: 500, Message: "Failed to query recent transfers", Details: err.Error(), Err: err}
+	}
+	if len(prior) == 0 {
+		return false, nil
+	}
+
+	req.TransactionID = prior[0].ID
+	req.Status = prior[0].Status
+	req.Fee = prior[0].Fee
+	req.FeeWaived = prior[0].FeeWaived
+	req.Duplicate = true
+	return true, nil
+}
+
+// isOwnTransfer reports whether the transfer takes the fast path: it is enabled and the caller
+// owns the destination account too. Ownership of the source is checked with the transfer itself.
+func (s *transactionService) isOwnTransfer(req *models.TransferRequest, claims *models.Claims) (bool, error) {
+	if !s.cfg.OwnTransferFastPath {
+		return false, nil
+	}
+
+	var count int64
+	err := s.db.Model(&models.Account{}).
+		Where("id IN ? AND user_id = ? AND sandbox = ?", []int{req.FromID, req.ToID}, claims.UserID, claims.Sandbox).
+		Count(&count).Error
+	if err != nil {
+		return false, &AppError{Code: 500, Message: "Failed to query accounts", Details: err.Error(), Err: err}
+	}
+	return count == 2, nil
+}
+
+// ApproveTransfer completes a transfer waiting for a second approver.
+func (s *transactionService) ApproveTransfer(transactionID string, claims *models.Claims) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		transaction, err := s.loadPendingApproval(tx, transactionID, claims)
+		if err != nil {
+			return err
+		}
+
+		fromAccount, toAccount, err := s.loadTransferAccounts(tx, *transaction.FromAccountID, *transaction.ToAccountID, transaction.Amount+transaction.Fee, uint(*transaction.InitiatorID), claims.Sandbox, transaction.ID)
+		if err != nil {
+			return err
+		}
+		if err := s.checkDestinationOwner(fromAccount, toAccount); err != nil {
+			return err
+		}
+		// The fee was quoted when the transfer was requested.
+		if err := s.applyTransfer(tx, fromAccount, toAccount, transaction.Amount, transaction.Fee); err != nil {
+			return err
+		}
+
+		if err := s.resolvePendingApproval(tx, transaction, "completed", claims); err != nil {
+			return err
+		}
+		return s.recordTransferLegs(tx, transaction)
+	})
+}
+
+// RejectTransfer cancels a transfer waiting for a second approver. No funds are moved.
+func (s *transactionService) RejectTransfer(transactionID string, claims *models.Claims) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		transaction, err := s.loadPendingApproval(tx, transactionID, claims)
+		if err != nil {
+			return err
+		}
+
+		return s.resolvePendingApproval(tx, transaction, "rejected", claims)
+	})
+}
+
+// CancelTransfer lets the sender withdraw a transfer that is still waiting for approval or for
+// the recipient, which releases the funds it reserved. Admins can cancel any pending transfer.
+func (s *transactionService) CancelTransfer(transactionID string, claims *models.Claims) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var transaction models.Transaction
+		if err := tx.Where("id = ? AND type = ?", transactionID, "transfer").First(&transaction).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return &AppError{Code: 404, Message: "Transfer not found", Details: fmt.Sprintf("transaction_id: %s", transactionID)}
+			}
+			return &AppError{Code: 500, Message: "Failed to query transaction", Details: err.Error(), Err: err}
+		}
+
+		initiator := transaction.InitiatorID != nil && uint(*transaction.InitiatorID) == claims.UserID
+		if !initiator && claims.Role != models.RoleAdmin {
+			return &AppError{Code: 403, Message: "Access denied", Details: "Only the sender can cancel a transfer"}
+		}
+
+		// The status condition makes a concurrent approval and cancellation exclusive.
+		result := tx.Model(&models.Transaction{}).
+			Where("id = ? AND status IN ?", transaction.ID, []string{"pending_approval", "incoming_pending"}).
+			Update("status", "cancelled")
+		if result.Error != nil {
+			return &AppError{Code: 500, Message: "Failed to update transaction status", Details: result.Error.Error(), Err: result.Error}
+		}
+		if result.RowsAffected == 0 {
+			return &AppError{Code: 409, Message: "Only pending transfers can be cancelled", Details: fmt.Sprintf("transaction_id: %s, status: %s", transaction.ID, transaction.Status)}
+		}
+
 		return nil
 	})
 }
+
+// loadPendingApproval fetches a pending transfer and checks that the caller may decide on it: an
+// administrator or a co-signer of the source account, other than the initiator.
+func (s *transactionService) loadPendingApproval(tx *gorm.DB, transactionID string, claims *models.Claims) (*models.Transaction, error) {
+	var transaction models.Transaction
+	if err := tx.Where("id = ? AND status = ?", transactionID, "pending_approval").First(&transaction).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &AppError{Code: 404, Message: "Pending transfer not found", Details: fmt.Sprintf("transaction_id: %s", transactionID)}
+		}
+		return nil, &AppError{Code: 500, Message: "Failed to query transaction", Details: err.Error(), Err: err}
+	}
+
+	if transaction.InitiatorID == nil || transaction.FromAccountID == nil || transaction.ToAccountID == nil {
+		return nil, &AppError{Code: 500, Message: "Pending transfer is incomplete", Details: fmt.Sprintf("transaction_id: %s", transactionID)}
+	}
+	if claims.Role != models.RoleAdmin {
+		cosigner, err := isCosigner(tx, *transaction.FromAccountID, claims.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if !cosigner {
+			return nil, &AppError{Code: 403, Message: "Access denied", Details: "Only administrators and co-signers of the account can approve or reject transfers"}
+		}
+	}
+	if uint(*transaction.InitiatorID) == claims.UserID {
+		return nil, &AppError{Code: 403, Message: "Self-approval is not allowed", Details: fmt.Sprintf("transaction_id: %s", transactionID)}
+	}
+
+	return &transaction, nil
+}
+
+// resolvePendingApproval records the approver's decision on a pending transfer, and when it completed.
+func (s *transactionService) resolvePendingApproval(tx *gorm.DB, transaction *models.Transaction, status string, claims *models.Claims) error {
+	updates := map[string]interface{}{"status": status, "approver_id": int(claims.UserID)}
+	if status == "completed" {
+		updates["completed_at"] = s.clock.Now()
+	}
+	result := tx.Model(&models.Transaction{}).
+		Where("id = ? AND status = ?", transaction.ID, "pending_approval").
+		Updates(updates)
+	if result.Error != nil {
+		return &AppError{Code: 500, Message: "Failed to update transaction status", Details: result.Error.Error(), Err: result.Error}
+	}
+	if result.RowsAffected == 0 {
+		return &AppError{Code: 409, Message: "Transfer is no longer pending", Details: fmt.Sprintf("transaction_id: %s", transaction.ID)}
+	}
+
+	return nil
+}
+
+// loadTransferAccounts fetches both sides of a transfer and runs the ownership, integrity, status,
+// funds and currency checks. pendingID is the pending transfer being executed, if any, so its own
+// reservation isn't counted twice. Both accounts must be of the given mode, so sandbox money never
+// reaches real accounts and vice versa.
+func (s *transactionService) loadTransferAccounts(tx *gorm.DB, fromID, toID int, amount float64, userID uint, sandbox bool, pendingID string) (*models.Account, *models.Account, error) {
+	fromAccount, err := s.loadSourceAccount(tx, fromID, userID, sandbox)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.checkSourceStatus(fromAccount); err != nil {
+		return nil, nil, err
+	}
+	if err := s.checkFunds(tx, fromAccount, amount, pendingID); err != nil {
+		return nil, nil, err
+	}
+
+	toAccount, err := s.loadDestinationAccount(tx, toID, sandbox)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkDestinationOpen(toAccount); err != nil {
+		return nil, nil, err
+	}
+	if err := checkTransferCurrency(fromAccount, toAccount); err != nil {
+		return nil, nil, err
+	}
+
+	return fromAccount, toAccount, nil
+}
+
+// loadSourceAccount fetches the source of a transfer, which must belong to the user, and verifies its balance hash.
+func (s *transactionService) loadSourceAccount(tx *gorm.DB, fromID int, userID uint, sandbox bool) (*models.Account, error) {
+	var fromAccount models.Account
+	if err := tx.Where("id = ? AND user_id = ? AND sandbox = ?", fromID, userID, sandbox).First(&fromAccount).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &AppError{Code: 404, Message: "Source account not found or access denied", Details: fmt.Sprintf("account_id: %d, user_id: %d", fromID, userID)}
+		}
+		return nil, &AppError{Code: 500, Message: "Failed to query source account", Details: err.Error(), Err: err}
+	}
+
+	// Verify balance hash of the source account.
+	expectedFromHash := balanceHash(&fromAccount, s.secretKey)
+	if fromAccount.BalanceHash != expectedFromHash {
+		return nil, &AppError{Code: 500, Message: "Source account balance integrity check failed", Details: fmt.Sprintf("account_id: %d", fromID), Err: s.integrityFailed(fromID)}
+	}
+	return &fromAccount, nil
+}
+
+// checkSourceStatus rejects outgoing payments from frozen, dormant and too new accounts.
+func (s *transactionService) checkSourceStatus(fromAccount *models.Account) error {
+	if err := checkNotClosed(fromAccount); err != nil {
+		return err
+	}
+	if err := checkNotFrozen(fromAccount); err != nil {
+		return err
+	}
+	if err := checkNotDormant(fromAccount); err != nil {
+		return err
+	}
+	return s.checkAccountAge(fromAccount)
+}
+
+// checkFunds checks that the policy of the source account allows debiting amount from its available balance.
+func (s *transactionService) checkFunds(tx *gorm.DB, fromAccount *models.Account, amount float64, pendingID string) error {
+	available, err := s.availableBalance(tx, fromAccount, pendingID)
+	if err != nil {
+		return err
+	}
+	return s.policyFor(fromAccount).CheckDebit(tx, fromAccount, available, amount, s.clock.Now())
+}
+
+// loadDestinationAccount fetches the destination of a transfer and verifies its balance hash.
+func (s *transactionService) loadDestinationAccount(tx *gorm.DB, toID int, sandbox bool) (*models.Account, error) {
+	var toAccount models.Account
+	if err := tx.Where("id = ? AND sandbox = ?", toID, sandbox).First(&toAccount).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &AppError{Code: 404, Message: "Destination account not found", Details: fmt.Sprintf("account_id: %d", toID)}
+		}
+		return nil, &AppError{Code: 500, Message: "Failed to query destination account", Details: err.Error(), Err: err}
+	}
+
+	// Verify balance hash of the destination account
+	expectedToHash := balanceHash(&toAccount, s.secretKey)
+	if toAccount.BalanceHash != expectedToHash {
+		return nil, &AppError{Code: 500, Message: "Destination account balance integrity check failed", Details: fmt.Sprintf("account_id: %d", toID), Err: s.integrityFailed(toID)}
+	}
+	return &toAccount, nil
+}
+
+// checkTransferCurrency rejects transfers between accounts in different currencies; transfers are never converted.
+func checkTransferCurrency(fromAccount, toAccount *models.Account) error {
+	if fromAccount.Currency != toAccount.Currency {
+		return &AppError{Code: 400, Message: "Currency mismatch", Details: fmt.Sprintf("Source account is in %s, destination account in %s", fromAccount.Currency, toAccount.Currency)}
+	}
+	return nil
+}
+
+// checkDestinationOwner applies the cross-user policy. The destination of a transfer is looked up
+// without an owner, so a transfer to another user's account is only allowed when the policy permits it.
+func (s *transactionService) checkDestinationOwner(fromAccount, toAccount *models.Account) error {
+	if toAccount.UserID == fromAccount.UserID {
+		return nil
+	}
+	if s.cfg.OwnAccountsOnly {
+		return &AppError{Code: 403, Message: "Transfers to other users are disabled", Details: fmt.Sprintf("account_id: %d", toAccount.ID)}
+	}
+	return nil
+}
+
+// checkTransfersEnabled rejects outgoing payments of users restricted by an admin.
+func checkTransfersEnabled(tx *gorm.DB, userID uint) error {
+	var enabled []bool
+	if err := tx.Model(&models.User{}).Where("id = ?", userID).Pluck("transfers_enabled", &enabled).Error; err != nil {
+		return &AppError{Code: 500, Message: "Failed to query user", Details: err.Error(), Err: err}
+	}
+	if len(enabled) == 0 || !enabled[0] {
+		return &AppError{Code: 403, Message: "Transfers disabled", Details: "Transfers and withdrawals are disabled for this user, contact support"}
+	}
+	return nil
+}
+
+// checkTransferPrecision checks the amount against the currency of the source account and returns
+// that currency. A missing account is left for loadTransferAccounts to report.
+func (s *transactionService) checkTransferPrecision(tx *gorm.DB, fromID int, amount float64) (string, error) {
+	var currencies []string
+	if err := tx.Model(&models.Account{}).Where("id = ?", fromID).Pluck("currency", &currencies).Error; err != nil {
+		return "", &AppError{Code: 500, Message: "Failed to query source account", Details: err.Error(), Err: err}
+	}
+	if len(currencies) == 0 {
+		return "", nil
+	}
+	return currencies[0], checkAmountPrecision(amount, currencies[0], s.cfg.CurrencyDecimals)
+}
+
+// checkAccountAge rejects outgoing money movement from accounts younger than the configured minimum age.
+func (s *transactionService) checkAccountAge(account *models.Account) error {
+	if s.cfg.MinAccountAge <= 0 {
+		return nil
+	}
+
+	age := s.clock.Now().Sub(account.CreatedAt)
+	if age < s.cfg.MinAccountAge {
+		return &AppError{Code: 403, Message: "Account is too new for outgoing payments", Details: fmt.Sprintf("account_id: %d, available in: %s", account.ID, (s.cfg.MinAccountAge - age).Round(time.Second))}
+	}
+
+	return nil
+}
+
+// availableBalance returns the balance minus funds reserved by pending outbound transfers, whether
+// waiting for approval or for the recipient, and active holds. excludeID skips one pending transaction (the one currently being executed).
+func (s *transactionService) availableBalance(tx *gorm.DB, account *models.Account, excludeID string) (float64, error) {
+	var reserved float64
+	query := tx.Model(&models.Transaction{}).
+		Select("COALESCE(SUM(amount + fee), 0)").
+		Where("from_account_id = ? AND status IN ?", account.ID, []string{"pending_approval", "incoming_pending"})
+	if excludeID != "" {
+		query = query.Where("id <> ?", excludeID)
+	}
+	if err := query.Scan(&reserved).Error; err != nil {
+		return 0, &AppError{Code: 500, Message: "Failed to calculate available balance", Details: err.Error(), Err: err}
+	}
+
+	var held float64
+	err := tx.Model(&models.Hold{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("account_id = ? AND status = ?", account.ID, HoldActive).
+		Scan(&held).Error
+	if err != nil {
+		return 0, &AppError{Code: 500, Message: "Failed to calculate available balance", Details: err.Error(), Err: err}
+	}
+
+	return account.Balance - reserved - held, nil
+}
+
+// accountBalance reports the balance of an account as updated within tx.
+func (s *transactionService) accountBalance(tx *gorm.DB, account *models.Account) (*models.AccountBalance, error) {
+	available, err := s.availableBalance(tx, account, "")
+	if err != nil {
+		return nil, err
+	}
+	return &models.AccountBalance{
+		AccountID:        account.ID,
+		Balance:          account.Balance,
+		AvailableBalance: available,
+		Currency:         account.Currency,
+		Display:          formatMoney(account.Balance, account.Currency, s.cfg.CurrencyDecimals),
+	}, nil
+}
+
+// roundAmount rounds an amount to the minor units of currency.
+func (s *transactionService) roundAmount(amount float64, currency string) float64 {
+	return roundAmount(amount, currencyDecimals(currency, s.cfg.CurrencyDecimals))
+}
+
+// Currencies lists the currency registry: decimal places and display symbols.
+func (s *transactionService) Currencies() []models.Currency {
+	return listCurrencies(s.cfg.CurrencyDecimals)
+}
+
+// applyTransfer moves funds between two already verified accounts (updates balances and hashes).
+// The fee is debited from the source on top of the amount.
+func (s *transactionService) applyTransfer(tx *gorm.DB, fromAccount, toAccount *models.Account, amount, fee float64) error {
+	s.drawSubBalances(fromAccount, amount+fee)
+	fromAccount.Balance = s.roundAmount(fromAccount.Balance-amount-fee, fromAccount.Currency)
+	fromAccount.BalanceHash = balanceHash(fromAccount, s.secretKey)
+	now := s.clock.Now()
+	fromAccount.LastActivityAt = &now
+	if err := tx.Save(fromAccount).Error; err != nil {
+		return &AppError{Code: 500, Message: "Failed to update source account balance", Details: err.Error(), Err: err}
+	}
+
+	toAccount.Balance = s.roundAmount(toAccount.Balance+amount, toAccount.Currency)
+	toAccount.BalanceHash = balanceHash(toAccount, s.secretKey)
+	if err := tx.Save(toAccount).Error; err != nil {
+		return &AppError{Code: 500, Message: "Failed to update destination account balance", Details: err.Error(), Err: err}
+	}
+
+	return s.creditFee(tx, fromAccount, toAccount, fee)
+}
@@ -0,0 +1,88 @@
+// Path: internal/services/transfer_acceptance.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// AcceptTransfer completes a transfer waiting for the recipient. The funds reserved on the source
+// account are checked again, as the sender's account may have been frozen in the meantime.
+func (s *transactionService) AcceptTransfer(transactionID string, claims *models.Claims) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		transaction, err := s.loadIncomingTransfer(tx, transactionID, claims)
+		if err != nil {
+			return err
+		}
+
+		fromAccount, toAccount, err := s.loadTransferAccounts(tx, *transaction.FromAccountID, *transaction.ToAccountID, transaction.Amount+transaction.Fee, uint(*transaction.InitiatorID), claims.Sandbox, transaction.ID)
+		if err != nil {
+			return err
+		}
+		// The fee was quoted when the transfer was requested.
+		if err := s.applyTransfer(tx, fromAccount, toAccount, transaction.Amount, transaction.Fee); err != nil {
+			return err
+		}
+
+		if err := s.resolveIncomingTransfer(tx, transaction, "completed"); err != nil {
+			return err
+		}
+		return s.recordTransferLegs(tx, transaction)
+	})
+}
+
+// DeclineTransfer refuses a transfer waiting for the recipient. No funds are moved: the
+// reservation on the source account is released.
+func (s *transactionService) DeclineTransfer(transactionID string, claims *models.Claims) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		transaction, err := s.loadIncomingTransfer(tx, transactionID, claims)
+		if err != nil {
+			return err
+		}
+
+		return s.resolveIncomingTransfer(tx, transaction, "declined")
+	})
+}
+
+// loadIncomingTransfer fetches a transfer waiting for the recipient, which must be the caller.
+// Transfers to other users' accounts are reported as not found.
+func (s *transactionService) loadIncomingTransfer(tx *gorm.DB, transactionID string, claims *models.Claims) (*models.Transaction, error) {
+	var transaction models.Transaction
+	err := tx.Where("id = ? AND type = ? AND status = ?", transactionID, "transfer", "incoming_pending").
+		Where("to_account_id IN (?)", ownedAccountIDs(tx, claims.UserID).Where("sandbox = ?", claims.Sandbox)).
+		First(&transaction).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &AppError{Code: 404, Message: "Pending transfer not found", Details: fmt.Sprintf("transaction_id: %s", transactionID)}
+		}
+		return nil, &AppError{Code: 500, Message: "Failed to query transaction", Details: err.Error(), Err: err}
+	}
+
+	if transaction.InitiatorID == nil || transaction.FromAccountID == nil || transaction.ToAccountID == nil {
+		return nil, &AppError{Code: 500, Message: "Pending transfer is incomplete", Details: fmt.Sprintf("transaction_id: %s", transactionID)}
+	}
+	return &transaction, nil
+}
+
+// resolveIncomingTransfer records the recipient's decision, and when the transfer completed. The
+// status condition makes it exclusive with a concurrent cancellation by the sender.
+func (s *transactionService) resolveIncomingTransfer(tx *gorm.DB, transaction *models.Transaction, status string) error {
+	updates := map[string]interface{}{"status": status}
+	if status == "completed" {
+		updates["completed_at"] = s.clock.Now()
+	}
+	result := tx.Model(&models.Transaction{}).
+		Where("id = ? AND status = ?", transaction.ID, "incoming_pending").
+		Updates(updates)
+	if result.Error != nil {
+		return &AppError{Code: 500, Message: "Failed to update transaction status", Details: result.Error.Error(), Err: result.Error}
+	}
+	if result.RowsAffected == 0 {
+		return &AppError{Code: 409, Message: "Transfer is no longer pending", Details: fmt.Sprintf("transaction_id: %s", transaction.ID)}
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+// Path: internal/services/transfer_acceptance_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"testing"
+)
+
+func TestAcceptTransferCreditsRecipient(t *testing.T) {
+	db := testDB(t)
+	alice, bob := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+	from, to := seedAccount(t, db, alice, 100, "USD"), seedAccount(t, db, bob, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{})
+
+	pending := &models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: 80, RequireAcceptance: true}
+	if err := s.ProcessTransfer(pending, claimsFor(alice)); err != nil || pending.Status != "incoming_pending" {
+		t.Fatalf("pending transfer = %q, %v; want incoming_pending", pending.Status, err)
+	}
+	if got := reloadAccount(t, db, to.ID).Balance; got != 0 {
+		t.Errorf("recipient balance before acceptance = %v, want 0", got)
+	}
+
+	// Only the recipient decides.
+	wantAppError(t, s.AcceptTransfer(pending.TransactionID, claimsFor(alice)), 404)
+
+	if err := s.AcceptTransfer(pending.TransactionID, claimsFor(bob)); err != nil {
+		t.Fatalf("accept: %v", err)
+	}
+	sender, recipient := reloadAccount(t, db, from.ID), reloadAccount(t, db, to.ID)
+	if sender.Balance != 100-80-pending.Fee || recipient.Balance != 80 {
+		t.Errorf("balances after acceptance = %v and %v, want %v and 80", sender.Balance, recipient.Balance, 100-80-pending.Fee)
+	}
+	for _, account := range []*models.Account{sender, recipient} {
+		if account.BalanceHash != balanceHash(account, testSecret) {
+			t.Errorf("account %d hash does not match its balance", account.ID)
+		}
+	}
+	var stored models.Transaction
+	if err := db.First(&stored, "id = ?", pending.TransactionID).Error; err != nil || stored.Status != "completed" {
+		t.Errorf("transaction status = %q, %v; want completed", stored.Status, err)
+	}
+
+	wantAppError(t, s.AcceptTransfer(pending.TransactionID, claimsFor(bob)), 404)
+	wantAppError(t, s.DeclineTransfer(pending.TransactionID, claimsFor(bob)), 404)
+}
+
+func TestDeclineTransferReturnsFunds(t *testing.T) {
+	db := testDB(t)
+	alice, bob := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+	from, to := seedAccount(t, db, alice, 100, "USD"), seedAccount(t, db, bob, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{})
+
+	pending := &models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: 80, RequireAcceptance: true}
+	if err := s.ProcessTransfer(pending, claimsFor(alice)); err != nil {
+		t.Fatalf("pending transfer: %v", err)
+	}
+	wantAppError(t, s.DeclineTransfer(pending.TransactionID, claimsFor(alice)), 404)
+
+	if err := s.DeclineTransfer(pending.TransactionID, claimsFor(bob)); err != nil {
+		t.Fatalf("decline: %v", err)
+	}
+	if sender, recipient := reloadAccount(t, db, from.ID), reloadAccount(t, db, to.ID); sender.Balance != 100 || recipient.Balance != 0 {
+		t.Errorf("balances after the decline = %v and %v, want 100 and 0", sender.Balance, recipient.Balance)
+	}
+	var stored models.Transaction
+	if err := db.First(&stored, "id = ?", pending.TransactionID).Error; err != nil || stored.Status != "declined" {
+		t.Errorf("transaction status = %q, %v; want declined", stored.Status, err)
+	}
+
+	// The reservation is gone: the sender can spend all of it again.
+	balance, err := s.ProcessWithdraw(&models.TransactionRequest{AccountID: from.ID, Amount: 100}, claimsFor(alice))
+	if err != nil {
+		t.Fatalf("withdraw the returned funds: %v", err)
+	}
+	if balance.Balance != 0 {
+		t.Errorf("balance after withdrawing everything = %v", balance.Balance)
+	}
+	wantAppError(t, s.AcceptTransfer(pending.TransactionID, claimsFor(bob)), 404)
+}
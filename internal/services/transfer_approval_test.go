@@ -0,0 +1,71 @@
+// Path: internal/services/transfer_approval_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"testing"
+)
+
+func TestTransferAboveThresholdWaitsForApproval(t *testing.T) {
+	db := testDB(t)
+	alice, bob, admin := seedUser(t, db, "alice"), seedUser(t, db, "bob"), seedAdmin(t, db, "admin")
+	from, to := seedAccount(t, db, alice, 500, "USD"), seedAccount(t, db, bob, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{ApprovalThreshold: 100})
+
+	small := &models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: 100}
+	if err := s.ProcessTransfer(small, claimsFor(alice)); err != nil {
+		t.Fatalf("transfer at the threshold: %v", err)
+	}
+	if small.Status != "completed" {
+		t.Errorf("transfer at the threshold: status %q, want completed", small.Status)
+	}
+
+	large := &models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: 150}
+	if err := s.ProcessTransfer(large, claimsFor(alice)); err != nil {
+		t.Fatalf("transfer above the threshold: %v", err)
+	}
+	if large.Status != "pending_approval" {
+		t.Fatalf("transfer above the threshold: status %q, want pending_approval", large.Status)
+	}
+	if got := reloadAccount(t, db, to.ID).Balance; got != 100 {
+		t.Errorf("recipient balance before approval = %v, want 100", got)
+	}
+
+	if err := s.ApproveTransfer(large.TransactionID, claimsFor(admin)); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	if got := reloadAccount(t, db, from.ID).Balance; got != 250 {
+		t.Errorf("sender balance after approval = %v, want 250", got)
+	}
+	if got := reloadAccount(t, db, to.ID).Balance; got != 250 {
+		t.Errorf("recipient balance after approval = %v, want 250", got)
+	}
+
+	var transaction models.Transaction
+	if err := db.First(&transaction, "id = ?", large.TransactionID).Error; err != nil {
+		t.Fatalf("load transaction: %v", err)
+	}
+	if transaction.Status != "completed" || transaction.ApproverID == nil || *transaction.ApproverID != admin.ID {
+		t.Errorf("transaction status %q, approver %v; want completed by %d", transaction.Status, transaction.ApproverID, admin.ID)
+	}
+}
+
+func TestTransferSelfApprovalRejected(t *testing.T) {
+	db := testDB(t)
+	admin, bob := seedAdmin(t, db, "admin"), seedUser(t, db, "bob")
+	from, to := seedAccount(t, db, admin, 500, "USD"), seedAccount(t, db, bob, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{ApprovalThreshold: 100})
+
+	req := &models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: 150}
+	if err := s.ProcessTransfer(req, claimsFor(admin)); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+
+	err := s.ApproveTransfer(req.TransactionID, claimsFor(admin))
+	if appErr := wantAppError(t, err, 403); appErr.Message != "Self-approval is not allowed" {
+		t.Errorf("message = %q", appErr.Message)
+	}
+	if got := reloadAccount(t, db, to.ID).Balance; got != 0 {
+		t.Errorf("recipient balance = %v, want 0", got)
+	}
+}
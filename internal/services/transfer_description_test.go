@@ -0,0 +1,72 @@
+// Path: internal/services/transfer_description_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"testing"
+)
+
+func TestCheckTransferDescription(t *testing.T) {
+	s := NewTransactionService(nil, testSecret, TransactionConfig{DescriptionThreshold: 1000}).(*transactionService)
+	off := NewTransactionService(nil, testSecret, TransactionConfig{}).(*transactionService)
+	for _, tt := range []struct {
+		name        string
+		s           *transactionService
+		amount      float64
+		description string
+		rejected    bool
+	}{
+		{"large without description", s, 1000.01, "", true},
+		{"large with description", s, 5000, "rent", false},
+		{"at the threshold", s, 1000, "", false},
+		{"small without description", s, 10, "", false},
+		{"threshold disabled", off, 1e9, "", false},
+	} {
+		err := tt.s.checkTransferDescription(&models.TransferRequest{Amount: tt.amount, Description: tt.description})
+		if !tt.rejected {
+			if err != nil {
+				t.Errorf("%s: %v", tt.name, err)
+			}
+			continue
+		}
+		if names := fieldNames(t, err); len(names) != 1 || names[0] != "description" {
+			t.Errorf("%s: fields %v, want description", tt.name, names)
+		}
+	}
+}
+
+func TestTransferDescriptionThreshold(t *testing.T) {
+	db := testDB(t)
+	alice, bob := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+	from, to := seedAccount(t, db, alice, 5000, "USD"), seedAccount(t, db, bob, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{DescriptionThreshold: 1000})
+	transfer := func(amount float64, description string) error {
+		return s.ProcessTransfer(&models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: amount, Description: description}, claimsFor(alice))
+	}
+
+	for name, description := range map[string]string{"missing": "", "blank": " \t "} {
+		err := transfer(2000, description)
+		if names := fieldNames(t, err); len(names) != 1 || names[0] != "description" {
+			t.Errorf("large transfer with a %s description: fields %v, want description", name, names)
+		}
+	}
+	if got := reloadAccount(t, db, to.ID).Balance; got != 0 {
+		t.Errorf("recipient balance after the rejections = %v, want 0", got)
+	}
+
+	if err := transfer(2000, "deposit for the flat"); err != nil {
+		t.Errorf("large transfer with a description: %v", err)
+	}
+	if err := transfer(50, ""); err != nil {
+		t.Errorf("small transfer without a description: %v", err)
+	}
+	if got := reloadAccount(t, db, to.ID).Balance; got != 2050 {
+		t.Errorf("recipient balance = %v, want 2050", got)
+	}
+
+	// A send_all is checked against the amount it resolves to.
+	err := s.ProcessTransfer(&models.TransferRequest{FromID: from.ID, ToID: to.ID, SendAll: true}, claimsFor(alice))
+	if names := fieldNames(t, err); len(names) != 1 || names[0] != "description" {
+		t.Errorf("send_all of 2950 without a description: fields %v, want description", names)
+	}
+}
@@ -0,0 +1,61 @@
+// Path: internal/services/transfer_quote.go
+package services
+
+import (
+	"bank-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// QuoteTransfer computes the fee of a transfer without executing it. It resolves the destination
+// and applies the fee rules exactly as ProcessTransfer does, so the quote matches the charge unless
+// something changes in between, such as the free monthly transfers being used up.
+// Transfers are not converted between currencies, so there is no exchange rate to quote.
+func (s *transactionService) QuoteTransfer(req *models.TransferRequest, claims *models.Claims) (*models.TransferQuote, error) {
+	if err := validateTransferRequest(req); err != nil {
+		return nil, err
+	}
+	if err := s.resolveDestination(req, claims); err != nil {
+		return nil, err
+	}
+	if req.FromID == req.ToID {
+		return nil, &AppError{Code: 400, Message: "Invalid transfer", Details: "Source and destination accounts must be different"}
+	}
+
+	account, err := s.loadOwnedAccount(s.db, req.FromID, claims.UserID, claims.Sandbox)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkAmountPrecision(req.Amount, account.Currency, s.cfg.CurrencyDecimals); err != nil {
+		return nil, err
+	}
+
+	own, err := s.isOwnTransfer(req, claims)
+	if err != nil {
+		return nil, err
+	}
+	fee, err := s.transferFee(s.db, claims.UserID, req.Amount, account.Currency, own)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TransferQuote{
+		FromID:     req.FromID,
+		ToID:       req.ToID,
+		Amount:     req.Amount,
+		Currency:   account.Currency,
+		Fee:        fee.Fee,
+		FeeWaived:  fee.Waived,
+		FeeWaiver:  fee.WaiverReason,
+		TotalDebit: s.roundAmount(req.Amount+fee.Fee, account.Currency),
+	}, nil
+}
+
+// transferFee is the fee of a transfer from an account in currency: none on the own-accounts fast
+// path, otherwise the fee rules.
+func (s *transactionService) transferFee(tx *gorm.DB, userID uint, amount float64, currency string, own bool) (FeeQuote, error) {
+	if own {
+		return FeeQuote{Waived: true, WaiverReason: FeeWaiverOwnAccounts}, nil
+	}
+	return s.fees.Quote(tx, userID, amount, currencyDecimals(currency, s.cfg.CurrencyDecimals), s.clock.Now())
+}
@@ -0,0 +1,59 @@
+// Path: internal/services/transfer_quote_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"testing"
+)
+
+func TestQuoteMatchesTheChargedFee(t *testing.T) {
+	db := testDB(t)
+	standard, premium, bob := seedUser(t, db, "standard"), seedUser(t, db, "premium"), seedUser(t, db, "bob")
+	if err := db.Model(premium).Update("tier", models.TierPremium).Error; err != nil {
+		t.Fatalf("upgrade user: %v", err)
+	}
+	to := seedAccount(t, db, bob, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{
+		Fees: FeeConfig{Flat: 0.25, Percent: 1.5, WaivedTiers: []string{models.TierPremium}, Accounts: seedFeeAccounts(t, db, "USD")},
+	})
+
+	for _, user := range []*models.User{standard, premium} {
+		from := seedAccount(t, db, user, 5000, "USD")
+		for _, amount := range []float64{0.01, 10, 99.99, 1234.56} {
+			quote, err := s.QuoteTransfer(&models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: amount}, claimsFor(user))
+			if err != nil {
+				t.Fatalf("%s: quote %v: %v", user.Username, amount, err)
+			}
+			before := reloadAccount(t, db, from.ID).Balance
+
+			req := &models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: amount}
+			if err := s.ProcessTransfer(req, claimsFor(user)); err != nil {
+				t.Fatalf("%s: transfer %v: %v", user.Username, amount, err)
+			}
+			if req.Fee != quote.Fee || req.FeeWaived != quote.FeeWaived {
+				t.Errorf("%s, %v: charged %v (waived %v), quoted %v (waived %v)", user.Username, amount, req.Fee, req.FeeWaived, quote.Fee, quote.FeeWaived)
+			}
+			if debit := roundAmount(before-reloadAccount(t, db, from.ID).Balance, 2); debit != quote.TotalDebit {
+				t.Errorf("%s, %v: debited %v, quoted a total of %v", user.Username, amount, debit, quote.TotalDebit)
+			}
+		}
+	}
+}
+
+func TestQuoteOwnTransferIsFree(t *testing.T) {
+	db := testDB(t)
+	alice := seedUser(t, db, "alice")
+	checking, savings := seedAccount(t, db, alice, 100, "USD"), seedAccount(t, db, alice, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{Fees: FeeConfig{Flat: 1, Accounts: seedFeeAccounts(t, db, "USD")}, OwnTransferFastPath: true})
+
+	quote, err := s.QuoteTransfer(&models.TransferRequest{FromID: checking.ID, ToID: savings.ID, Amount: 40}, claimsFor(alice))
+	if err != nil {
+		t.Fatalf("quote: %v", err)
+	}
+	if quote.Fee != 0 || quote.FeeWaiver != FeeWaiverOwnAccounts || quote.TotalDebit != 40 {
+		t.Errorf("quote = %+v, want a waived fee and a total of 40", quote)
+	}
+	if got := reloadAccount(t, db, checking.ID).Balance; got != 100 {
+		t.Errorf("balance after a quote = %v, want it untouched", got)
+	}
+}
@@ -0,0 +1,68 @@
+// Path: internal/services/transfer_send_all.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"fmt"
+	"math"
+)
+
+// sendAllQuote is the outcome of resolving a send_all transfer.
+type sendAllQuote struct {
+	fee FeeQuote
+	// debit is what the transfer takes from the source, fee included: its whole spendable balance.
+	debit float64
+}
+
+// resolveSendAll sets the amount of a send_all transfer to everything the source account can spend
+// after the fee, so that it ends at exactly zero or at its minimum balance. The work is done in
+// minor units: the amount is the largest one whose fee still fits, and when rounding the fee leaves
+// a minor unit over, it goes to the recipient and the fee of the smaller amount is charged.
+func (s *transactionService) resolveSendAll(req *models.TransferRequest, claims *models.Claims, own bool) (*sendAllQuote, error) {
+	account, err := s.loadSourceAccount(s.db, req.FromID, claims.UserID, claims.Sandbox)
+	if err != nil {
+		return nil, err
+	}
+	available, err := s.availableBalance(s.db, account, "")
+	if err != nil {
+		return nil, err
+	}
+	spendable := available - s.policyFor(account).MinBalance()
+	decimals := currencyDecimals(account.Currency, s.cfg.CurrencyDecimals)
+	total := toMinorUnits(spendable, decimals)
+	if total <= 0 {
+		return nil, &AppError{Code: 400, Message: "Insufficient funds", Details: fmt.Sprintf("account_id: %d, available: %f", account.ID, spendable)}
+	}
+
+	fee, err := s.transferFee(s.db, claims.UserID, spendable, account.Currency, own)
+	if err != nil {
+		return nil, err
+	}
+	if fee.Waived || fee.Fee == 0 {
+		req.Amount = fromMinorUnits(total, decimals)
+		return &sendAllQuote{fee: fee, debit: spendable}, nil
+	}
+
+	feeOf := func(amount int64) int64 {
+		return toMinorUnits(s.fees.fee(fromMinorUnits(amount, decimals), decimals), decimals)
+	}
+	// Start from the exact solution of amount + flat + amount*percent = total and walk to the
+	// largest amount that fits; the fee's rounding moves it by a unit or two at most.
+	amount := int64(math.Floor((spendable - s.fees.cfg.Flat) / (1 + s.fees.cfg.Percent/100) * math.Pow10(decimals)))
+	if amount < 0 {
+		amount = 0
+	}
+	for amount > 0 && amount+feeOf(amount) > total {
+		amount--
+	}
+	for amount+1+feeOf(amount+1) <= total {
+		amount++
+	}
+	if amount <= 0 {
+		return nil, &AppError{Code: 400, Message: "Insufficient funds", Details: fmt.Sprintf("account_id: %d, available: %f, the balance does not cover the fee", account.ID, spendable)}
+	}
+
+	charged := feeOf(amount)
+	req.Amount = fromMinorUnits(total-charged, decimals)
+	return &sendAllQuote{fee: FeeQuote{Fee: fromMinorUnits(charged, decimals)}, debit: spendable}, nil
+}
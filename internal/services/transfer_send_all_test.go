@@ -0,0 +1,115 @@
+// Path: internal/services/transfer_send_all_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/accountnumber"
+	"testing"
+	"time"
+)
+
+func TestSendAllRejectsAnAmount(t *testing.T) {
+	err := validateTransferRequest(&models.TransferRequest{FromID: 1, ToID: 2, Amount: 5, SendAll: true})
+	if names := fieldNames(t, err); len(names) != 1 || names[0] != "amount" {
+		t.Errorf("fields %v, want amount", names)
+	}
+	if err := validateTransferRequest(&models.TransferRequest{FromID: 1, ToID: 2, SendAll: true}); err != nil {
+		t.Errorf("send_all without an amount: %v", err)
+	}
+}
+
+func TestSendAllEmptiesTheSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		balance float64
+		fees    FeeConfig
+	}{
+		{"without fees", 123.45, FeeConfig{}},
+		{"flat fee", 100, FeeConfig{Flat: 1}},
+		{"flat and percent fee", 100, FeeConfig{Flat: 1, Percent: 0.5}},
+		{"percent fee rounding", 10.07, FeeConfig{Percent: 1.5}},
+		{"waived fee", 50.01, FeeConfig{Flat: 1, FreeTransfersPerMonth: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testDB(t)
+			alice, bob := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+			from, to := seedAccount(t, db, alice, tt.balance, "USD"), seedAccount(t, db, bob, 0, "USD")
+			tt.fees.Accounts = seedFeeAccounts(t, db, "USD")
+			s := NewTransactionService(db, testSecret, TransactionConfig{Fees: tt.fees})
+
+			req := &models.TransferRequest{FromID: from.ID, ToID: to.ID, SendAll: true}
+			if err := s.ProcessTransfer(req, claimsFor(alice)); err != nil {
+				t.Fatalf("send all: %v", err)
+			}
+			if got := roundAmount(req.Amount+req.Fee, 2); got != tt.balance {
+				t.Errorf("amount %v + fee %v = %v, want the balance %v", req.Amount, req.Fee, got, tt.balance)
+			}
+			if got := roundAmount(reloadAccount(t, db, from.ID).Balance, 2); got != 0 {
+				t.Errorf("source balance = %v, want exactly 0", got)
+			}
+			if got := reloadAccount(t, db, to.ID).Balance; got != req.Amount {
+				t.Errorf("recipient balance = %v, want %v", got, req.Amount)
+			}
+		})
+	}
+}
+
+func TestSendAllStopsAtTheFloor(t *testing.T) {
+	db := testDB(t)
+	alice, bob := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+	from := &models.Account{UserID: alice.ID, Balance: 20, Currency: "USD", Type: models.AccountTypeOverdraft}
+	if err := createAccount(db, from, testSecret, time.Now().Add(-24*time.Hour), accountnumber.Internal{}); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	to := seedAccount(t, db, bob, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{
+		Fees:     FeeConfig{Flat: 1, Accounts: seedFeeAccounts(t, db, "USD")},
+		Policies: AccountPolicyConfig{OverdraftLimit: 50},
+	})
+
+	req := &models.TransferRequest{FromID: from.ID, ToID: to.ID, SendAll: true}
+	if err := s.ProcessTransfer(req, claimsFor(alice)); err != nil {
+		t.Fatalf("send all: %v", err)
+	}
+	if req.Amount != 69 || req.Fee != 1 {
+		t.Errorf("amount %v, fee %v; want 69 and 1", req.Amount, req.Fee)
+	}
+	if got := roundAmount(reloadAccount(t, db, from.ID).Balance, 2); got != -50 {
+		t.Errorf("source balance = %v, want the overdraft floor of -50", got)
+	}
+}
+
+func TestSendAllLeavesReservedFunds(t *testing.T) {
+	db := testDB(t)
+	alice, bob := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+	from, to := seedAccount(t, db, alice, 100, "USD"), seedAccount(t, db, bob, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{})
+
+	pending := &models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: 30, RequireAcceptance: true}
+	if err := s.ProcessTransfer(pending, claimsFor(alice)); err != nil {
+		t.Fatalf("pending transfer: %v", err)
+	}
+	req := &models.TransferRequest{FromID: from.ID, ToID: to.ID, SendAll: true}
+	if err := s.ProcessTransfer(req, claimsFor(alice)); err != nil {
+		t.Fatalf("send all: %v", err)
+	}
+	if req.Amount != 70 {
+		t.Errorf("amount = %v, want the 70 not reserved", req.Amount)
+	}
+}
+
+func TestSendAllWithNothingToSend(t *testing.T) {
+	db := testDB(t)
+	alice, bob := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+	to := seedAccount(t, db, bob, 0, "USD")
+	s := NewTransactionService(db, testSecret, TransactionConfig{Fees: FeeConfig{Flat: 1, Accounts: seedFeeAccounts(t, db, "USD")}})
+
+	for _, balance := range []float64{0, 0.5, 1} {
+		from := seedAccount(t, db, alice, balance, "USD")
+		err := s.ProcessTransfer(&models.TransferRequest{FromID: from.ID, ToID: to.ID, SendAll: true}, claimsFor(alice))
+		if appErr := wantAppError(t, err, 400); appErr.Message != "Insufficient funds" {
+			t.Errorf("balance %v: %q, want Insufficient funds", balance, appErr.Message)
+		}
+	}
+}
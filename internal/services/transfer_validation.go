@@ -0,0 +1,154 @@
+// Path: internal/services/transfer_validation.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"errors"
+	"strings"
+)
+
+// Transfer checks reported by ValidateTransfer, in the order they run.
+const (
+	TransferCheckRequest     = "request"     // Amount, source and a single destination are given
+	TransferCheckDestination = "destination" // The destination resolves to an existing account
+	TransferCheckSource      = "source"      // The source exists, belongs to the user and is intact
+	TransferCheckCurrency    = "currency"    // Both accounts share a currency the amount fits
+	TransferCheckStatus      = "status"      // The user and source may send, and the destination may receive
+	TransferCheckLimits      = "limits"      // The daily spending limit
+	TransferCheckFunds       = "funds"       // The source can cover the amount and fee
+)
+
+// Transfer check outcomes. A check is skipped when a check it depends on failed.
+const (
+	TransferCheckPassed  = "passed"
+	TransferCheckFailed  = "failed"
+	TransferCheckSkipped = "skipped"
+)
+
+// ValidateTransfer runs the checks of ProcessTransfer without executing the transfer and reports
+// the outcome of each, so a client can show why a transfer would be refused. Nothing is written:
+// no confirmation code is issued and no spending is recorded. Internal errors abort the validation.
+func (s *transactionService) ValidateTransfer(req *models.TransferRequest, claims *models.Claims) (*models.TransferValidation, error) {
+	result := &models.TransferValidation{Valid: true, Checks: []models.TransferCheck{}}
+	// record adds the outcome of a check and reports whether it passed. Errors that don't
+	// describe the transfer, such as a database failure, are returned instead.
+	record := func(name string, err error) (bool, error) {
+		check := models.TransferCheck{Name: name, Status: TransferCheckPassed}
+		if err != nil {
+			var appErr *AppError
+			if !errors.As(err, &appErr) || appErr.Code >= 500 {
+				return false, err
+			}
+			check.Status, check.Message, check.Details = TransferCheckFailed, appErr.Message, appErr.Details
+			if len(appErr.Fields) > 0 {
+				messages := make([]string, len(appErr.Fields))
+				for i, field := range appErr.Fields {
+					messages[i] = field.Field + ": " + field.Message
+				}
+				check.Details = strings.Join(messages, "; ")
+			}
+			result.Valid = false
+		}
+		result.Checks = append(result.Checks, check)
+		return err == nil, nil
+	}
+	skip := func(names ...string) {
+		for _, name := range names {
+			result.Checks = append(result.Checks, models.TransferCheck{Name: name, Status: TransferCheckSkipped})
+		}
+	}
+
+	err := validateTransferRequest(req)
+	if err == nil {
+		err = s.checkTransferDescription(req)
+	}
+	requestOK, err := record(TransferCheckRequest, err)
+	if err != nil {
+		return nil, err
+	}
+
+	var to *models.Account
+	err = s.resolveDestination(req, claims)
+	switch {
+	case err != nil:
+	case req.ToID <= 0:
+		err = &AppError{Code: 400, Message: "Destination is required", Details: "Specify one of to_id, payee_id or to_account_number"}
+	case req.FromID == req.ToID:
+		err = &AppError{Code: 400, Message: "Invalid transfer", Details: "Source and destination accounts must be different"}
+	default:
+		to, err = s.loadDestinationAccount(s.db, req.ToID, claims.Sandbox)
+	}
+	if _, err := record(TransferCheckDestination, err); err != nil {
+		return nil, err
+	}
+
+	var from *models.Account
+	if req.FromID > 0 {
+		from, err = s.loadSourceAccount(s.db, req.FromID, claims.UserID, claims.Sandbox)
+	} else {
+		err = &AppError{Code: 400, Message: "Source account is required", Details: "Specify from_id"}
+	}
+	if _, err := record(TransferCheckSource, err); err != nil {
+		return nil, err
+	}
+
+	if from == nil || to == nil {
+		skip(TransferCheckCurrency, TransferCheckStatus, TransferCheckLimits, TransferCheckFunds)
+		return result, nil
+	}
+
+	err = checkTransferCurrency(from, to)
+	if err == nil && requestOK {
+		err = checkAmountPrecision(req.Amount, from.Currency, s.cfg.CurrencyDecimals)
+	}
+	if _, err := record(TransferCheckCurrency, err); err != nil {
+		return nil, err
+	}
+
+	err = checkTransfersEnabled(s.db, claims.UserID)
+	if err == nil {
+		err = s.checkSourceStatus(from)
+	}
+	if err == nil {
+		err = checkDestinationOpen(to)
+	}
+	if err == nil {
+		err = s.checkDestinationOwner(from, to)
+	}
+	if err == nil {
+		err = checkTransferWhitelist(s.db, from, to.ID)
+	}
+	if _, err := record(TransferCheckStatus, err); err != nil {
+		return nil, err
+	}
+
+	if !requestOK {
+		skip(TransferCheckLimits, TransferCheckFunds)
+		return result, nil
+	}
+
+	own, err := s.isOwnTransfer(req, claims)
+	if err != nil {
+		return nil, err
+	}
+	err = nil
+	if !own {
+		err = s.checkSpendingLimit(s.db, claims, from.Currency, req.Amount)
+	}
+	if _, err := record(TransferCheckLimits, err); err != nil {
+		return nil, err
+	}
+
+	fee, err := s.transferFee(s.db, claims.UserID, req.Amount, from.Currency, own)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := record(TransferCheckFunds, s.checkFunds(s.db, from, req.Amount+fee.Fee, "")); err != nil {
+		return nil, err
+	}
+
+	result.Fee = fee.Fee
+	result.RequiresConfirmation = !own && s.cfg.ConfirmationThreshold > 0 && req.Amount > s.cfg.ConfirmationThreshold
+	result.RequiresApproval = !own && s.cfg.ApprovalThreshold > 0 && req.Amount > s.cfg.ApprovalThreshold
+	return result, nil
+}
@@ -0,0 +1,108 @@
+// Path: internal/services/transfer_validation_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"reflect"
+	"testing"
+)
+
+// checkStatuses maps each reported check to its outcome.
+func checkStatuses(v *models.TransferValidation) map[string]string {
+	statuses := make(map[string]string, len(v.Checks))
+	for _, check := range v.Checks {
+		statuses[check.Name] = check.Status
+	}
+	return statuses
+}
+
+func TestValidateTransferReportsEachCheck(t *testing.T) {
+	db := testDB(t)
+	alice, bob, carol := seedUser(t, db, "alice"), seedUser(t, db, "bob"), seedUser(t, db, "carol")
+	from := seedAccount(t, db, alice, 100, "USD")
+	to, euros := seedAccount(t, db, bob, 0, "USD"), seedAccount(t, db, bob, 0, "EUR")
+	bobs := seedAccount(t, db, bob, 100, "USD")
+	restricted := seedAccount(t, db, carol, 100, "USD")
+	if err := db.Model(carol).Update("transfers_enabled", false).Error; err != nil {
+		t.Fatalf("restrict user: %v", err)
+	}
+	s := NewTransactionService(db, testSecret, TransactionConfig{Fees: FeeConfig{Flat: 1}, DailySpendingLimit: 50, ApprovalThreshold: 40})
+
+	passed := func(failed string, skipped ...string) map[string]string {
+		want := make(map[string]string)
+		for _, name := range []string{TransferCheckRequest, TransferCheckDestination, TransferCheckSource, TransferCheckCurrency, TransferCheckStatus, TransferCheckLimits, TransferCheckFunds} {
+			want[name] = TransferCheckPassed
+		}
+		if failed != "" {
+			want[failed] = TransferCheckFailed
+		}
+		for _, name := range skipped {
+			want[name] = TransferCheckSkipped
+		}
+		return want
+	}
+	tests := []struct {
+		name string
+		user *models.User
+		req  models.TransferRequest
+		want map[string]string
+	}{
+		{"all pass", alice, models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: 45}, passed("")},
+		{"bad amount", alice, models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: -5},
+			passed(TransferCheckRequest, TransferCheckLimits, TransferCheckFunds)},
+		{"unknown destination", alice, models.TransferRequest{FromID: from.ID, ToID: bobs.ID + 1000, Amount: 10},
+			passed(TransferCheckDestination, TransferCheckCurrency, TransferCheckStatus, TransferCheckLimits, TransferCheckFunds)},
+		{"someone else's source", alice, models.TransferRequest{FromID: bobs.ID, ToID: to.ID, Amount: 10},
+			passed(TransferCheckSource, TransferCheckCurrency, TransferCheckStatus, TransferCheckLimits, TransferCheckFunds)},
+		{"another currency", alice, models.TransferRequest{FromID: from.ID, ToID: euros.ID, Amount: 10}, passed(TransferCheckCurrency)},
+		{"transfers disabled", carol, models.TransferRequest{FromID: restricted.ID, ToID: to.ID, Amount: 10}, passed(TransferCheckStatus)},
+		{"over the daily limit", alice, models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: 60}, passed(TransferCheckLimits)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := s.ValidateTransfer(&tt.req, claimsFor(tt.user))
+			if err != nil {
+				t.Fatalf("ValidateTransfer: %v", err)
+			}
+			if got := checkStatuses(v); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("checks = %v, want %v", got, tt.want)
+			}
+			if v.Valid != (tt.name == "all pass") {
+				t.Errorf("valid = %v", v.Valid)
+			}
+			for _, check := range v.Checks {
+				if check.Status == TransferCheckFailed && check.Message == "" {
+					t.Errorf("failed check %s has no message", check.Name)
+				}
+			}
+		})
+	}
+
+	// The funds check counts the fee: 100 covers 99 but not 99 plus a fee of 1.
+	unlimited := NewTransactionService(db, testSecret, TransactionConfig{Fees: FeeConfig{Flat: 1}})
+	v, err := unlimited.ValidateTransfer(&models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: 99.5}, claimsFor(alice))
+	if err != nil {
+		t.Fatalf("ValidateTransfer: %v", err)
+	}
+	if got := checkStatuses(v); !reflect.DeepEqual(got, passed(TransferCheckFunds)) {
+		t.Errorf("insufficient funds: checks = %v", got)
+	}
+
+	v, err = s.ValidateTransfer(&models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: 45}, claimsFor(alice))
+	if err != nil {
+		t.Fatalf("ValidateTransfer: %v", err)
+	}
+	if v.Fee != 1 || !v.RequiresApproval || v.RequiresConfirmation {
+		t.Errorf("validation = %+v, want a fee of 1 and approval required", v)
+	}
+
+	// Validating moves no money and records nothing.
+	if got := reloadAccount(t, db, from.ID).Balance; got != 100 {
+		t.Errorf("balance = %v, want 100", got)
+	}
+	var count int64
+	db.Model(&models.Transaction{}).Count(&count)
+	if count != 0 {
+		t.Errorf("%d transactions recorded, want none", count)
+	}
+}
@@ -0,0 +1,116 @@
+// Path: internal/services/transfer_whitelist.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"fmt"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// MaxWhitelistedAccounts caps the destinations on the transfer whitelist of one account.
+const MaxWhitelistedAccounts = 50
+
+// GetTransferWhitelist returns the transfer whitelist of one of the user's accounts.
+func (s *accountService) GetTransferWhitelist(userID uint, accountID int) (*models.TransferWhitelist, error) {
+	account, err := s.findOwnedAccount(s.db, userID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	return loadTransferWhitelist(s.db, account)
+}
+
+// SetTransferWhitelist replaces the transfer whitelist of one of the user's accounts and turns it on
+// or off. The destinations must exist in the same mode (sandbox or real) as the account.
+func (s *accountService) SetTransferWhitelist(userID uint, accountID int, req *models.TransferWhitelist) (*models.TransferWhitelist, error) {
+	destinations := map[int]bool{}
+	positive, self := true, false
+	for _, id := range req.AccountIDs {
+		destinations[id] = true
+		positive = positive && id > 0
+		self = self || id == accountID
+	}
+	var v validation
+	v.check(positive, "account_ids", "Account IDs must be positive")
+	v.check(!self, "account_ids", "An account can't whitelist itself")
+	v.check(len(destinations) <= MaxWhitelistedAccounts, "account_ids", fmt.Sprintf("At most %d accounts can be whitelisted", MaxWhitelistedAccounts))
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	var whitelist *models.TransferWhitelist
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		account, err := s.findOwnedAccount(tx, userID, accountID)
+		if err != nil {
+			return err
+		}
+
+		ids := make([]int, 0, len(destinations))
+		for id := range destinations {
+			ids = append(ids, id)
+		}
+		var found int64
+		if err := tx.Model(&models.Account{}).Where("id IN ? AND sandbox = ?", ids, account.Sandbox).Count(&found).Error; err != nil {
+			return &AppError{Code: 500, Message: "Failed to query whitelisted accounts", Details: err.Error(), Err: err}
+		}
+		if int(found) != len(ids) {
+			return &AppError{Code: 404, Message: "Whitelisted account not found", Details: fmt.Sprintf("%d of %d accounts don't exist", len(ids)-int(found), len(ids))}
+		}
+
+		if err := tx.Where("account_id = ?", accountID).Delete(&models.TransferWhitelistEntry{}).Error; err != nil {
+			return &AppError{Code: 500, Message: "Failed to update transfer whitelist", Details: err.Error(), Err: err}
+		}
+		if len(ids) > 0 {
+			now := s.cfg.Clock.Now()
+			entries := make([]models.TransferWhitelistEntry, len(ids))
+			for i, id := range ids {
+				entries[i] = models.TransferWhitelistEntry{AccountID: accountID, DestinationAccountID: id, CreatedAt: now}
+			}
+			if err := tx.Create(&entries).Error; err != nil {
+				return &AppError{Code: 500, Message: "Failed to update transfer whitelist", Details: err.Error(), Err: err}
+			}
+		}
+		if err := tx.Model(&models.Account{}).Where("id = ?", accountID).Update("transfer_whitelist", req.Enabled).Error; err != nil {
+			return &AppError{Code: 500, Message: "Failed to update transfer whitelist", Details: err.Error(), Err: err}
+		}
+
+		account.TransferWhitelist = req.Enabled
+		whitelist, err = loadTransferWhitelist(tx, account)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return whitelist, nil
+}
+
+// loadTransferWhitelist reads the whitelisted destinations of an account, in ID order.
+func loadTransferWhitelist(tx *gorm.DB, account *models.Account) (*models.TransferWhitelist, error) {
+	ids := []int{}
+	if err := tx.Model(&models.TransferWhitelistEntry{}).Where("account_id = ?", account.ID).Pluck("destination_account_id", &ids).Error; err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to query transfer whitelist", Details: err.Error(), Err: err}
+	}
+	sort.Ints(ids)
+	return &models.TransferWhitelist{Enabled: account.TransferWhitelist, AccountIDs: ids}, nil
+}
+
+// checkTransferWhitelist rejects a transfer to a destination missing from the whitelist of the
+// source account, when the whitelist is on.
+func checkTransferWhitelist(tx *gorm.DB, fromAccount *models.Account, toID int) error {
+	if !fromAccount.TransferWhitelist {
+		return nil
+	}
+
+	var count int64
+	err := tx.Model(&models.TransferWhitelistEntry{}).
+		Where("account_id = ? AND destination_account_id = ?", fromAccount.ID, toID).
+		Count(&count).Error
+	if err != nil {
+		return &AppError{Code: 500, Message: "Failed to query transfer whitelist", Details: err.Error(), Err: err}
+	}
+	if count == 0 {
+		return &AppError{Code: 403, Message: "Destination not whitelisted", Details: fmt.Sprintf("Account %d only transfers to the accounts on its whitelist, not to %d", fromAccount.ID, toID)}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+// Path: internal/services/transfer_whitelist_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"reflect"
+	"testing"
+)
+
+func TestSetTransferWhitelistValidation(t *testing.T) {
+	s := NewAccountService(nil, testSecret, AccountConfig{})
+	many := make([]int, MaxWhitelistedAccounts+1)
+	for i := range many {
+		many[i] = i + 10
+	}
+	for name, ids := range map[string][]int{
+		"non-positive ID": {2, 0},
+		"itself":          {2, 1},
+		"too many":        many,
+	} {
+		_, err := s.SetTransferWhitelist(1, 1, &models.TransferWhitelist{Enabled: true, AccountIDs: ids})
+		if names := fieldNames(t, err); len(names) != 1 || names[0] != "account_ids" {
+			t.Errorf("%s: fields %v, want account_ids", name, names)
+		}
+	}
+}
+
+func TestDisabledWhitelistAllowsEveryDestination(t *testing.T) {
+	// Without a whitelist the check doesn't touch the database.
+	if err := checkTransferWhitelist(nil, &models.Account{ID: 1}, 2); err != nil {
+		t.Errorf("disabled whitelist: %v", err)
+	}
+}
+
+func TestTransferWhitelist(t *testing.T) {
+	db := testDB(t)
+	alice, bob := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+	from := seedAccount(t, db, alice, 100, "USD")
+	approved, other := seedAccount(t, db, bob, 0, "USD"), seedAccount(t, db, bob, 0, "USD")
+	accounts := NewAccountService(db, testSecret, AccountConfig{})
+	transactions := NewTransactionService(db, testSecret, TransactionConfig{})
+	transfer := func(to *models.Account) error {
+		return transactions.ProcessTransfer(&models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: 10}, claimsFor(alice))
+	}
+
+	// Disabled: every destination is allowed.
+	if err := transfer(other); err != nil {
+		t.Errorf("transfer without a whitelist: %v", err)
+	}
+
+	whitelist, err := accounts.SetTransferWhitelist(uint(alice.ID), from.ID, &models.TransferWhitelist{Enabled: true, AccountIDs: []int{approved.ID, approved.ID}})
+	if err != nil {
+		t.Fatalf("set whitelist: %v", err)
+	}
+	if want := (&models.TransferWhitelist{Enabled: true, AccountIDs: []int{approved.ID}}); !reflect.DeepEqual(whitelist, want) {
+		t.Errorf("whitelist = %+v, want %+v", whitelist, want)
+	}
+	if err := transfer(approved); err != nil {
+		t.Errorf("transfer to the whitelisted account: %v", err)
+	}
+	if appErr := wantAppError(t, transfer(other), 403); appErr.Message != "Destination not whitelisted" {
+		t.Errorf("transfer to another account: %q", appErr.Message)
+	}
+	if got := reloadAccount(t, db, other.ID).Balance; got != 10 {
+		t.Errorf("balance of the account not whitelisted = %v, want only the first 10", got)
+	}
+
+	// Turning it off keeps the list but allows everything again.
+	if _, err := accounts.SetTransferWhitelist(uint(alice.ID), from.ID, &models.TransferWhitelist{AccountIDs: []int{approved.ID}}); err != nil {
+		t.Fatalf("disable whitelist: %v", err)
+	}
+	if err := transfer(other); err != nil {
+		t.Errorf("transfer with the whitelist off: %v", err)
+	}
+	stored, err := accounts.GetTransferWhitelist(uint(alice.ID), from.ID)
+	if want := (&models.TransferWhitelist{AccountIDs: []int{approved.ID}}); err != nil || !reflect.DeepEqual(stored, want) {
+		t.Errorf("GetTransferWhitelist = %+v, %v; want %+v", stored, err, want)
+	}
+}
+
+func TestTransferWhitelistNeedsExistingOwnedAccounts(t *testing.T) {
+	db := testDB(t)
+	alice, bob := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+	from, bobs := seedAccount(t, db, alice, 100, "USD"), seedAccount(t, db, bob, 0, "USD")
+	accounts := NewAccountService(db, testSecret, AccountConfig{})
+
+	_, err := accounts.SetTransferWhitelist(uint(alice.ID), from.ID, &models.TransferWhitelist{Enabled: true, AccountIDs: []int{bobs.ID, bobs.ID + 1000}})
+	wantAppError(t, err, 404)
+	_, err = accounts.SetTransferWhitelist(uint(bob.ID), from.ID, &models.TransferWhitelist{Enabled: true, AccountIDs: []int{bobs.ID}})
+	wantAppError(t, err, 404)
+	if reloadAccount(t, db, from.ID).TransferWhitelist {
+		t.Error("failed update turned the whitelist on")
+	}
+}
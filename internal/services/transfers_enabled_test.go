@@ -0,0 +1,60 @@
+// Path: internal/services/transfers_enabled_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"testing"
+)
+
+func TestDisabledTransfersKeepReadAccess(t *testing.T) {
+	db := testDB(t)
+	auth := NewAuthService(db, testJWTSecret, AuthConfig{BalanceSecret: testSecret})
+	if err := auth.Register("readonly", "password1"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	user, err := NewUserRepository(db).FindByUsername("readonly")
+	if err != nil {
+		t.Fatalf("find user: %v", err)
+	}
+	admin := seedAdmin(t, db, "admin")
+	from := seedAccount(t, db, user, 100, "USD")
+	to := seedAccount(t, db, admin, 0, "USD")
+
+	if updated, err := auth.SetTransfersEnabled(uint(admin.ID), uint(user.ID), false); err != nil || updated.TransfersEnabled {
+		t.Fatalf("SetTransfersEnabled = %+v, %v", updated, err)
+	}
+	var audits int64
+	db.Model(&models.AuditLog{}).Where("user_id = ?", admin.ID).Count(&audits)
+	if audits != 1 {
+		t.Errorf("%d audit entries by the admin, want 1", audits)
+	}
+
+	transactions := NewTransactionService(db, testSecret, TransactionConfig{})
+	claims := claimsFor(user)
+	err = transactions.ProcessTransfer(&models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: 10}, claims)
+	if appErr := wantAppError(t, err, 403); appErr.Message != "Transfers disabled" {
+		t.Errorf("transfer: %q", appErr.Message)
+	}
+	_, err = transactions.ProcessWithdraw(&models.TransactionRequest{AccountID: from.ID, Amount: 10}, claims)
+	if appErr := wantAppError(t, err, 403); appErr.Message != "Transfers disabled" {
+		t.Errorf("withdrawal: %q", appErr.Message)
+	}
+	if _, err := transactions.ProcessDeposit(&models.TransactionRequest{AccountID: from.ID, Amount: 10}, claims); err != nil {
+		t.Errorf("deposit: %v", err)
+	}
+
+	if _, err := auth.Login("readonly", "password1", models.LoginMeta{}); err != nil {
+		t.Errorf("login: %v", err)
+	}
+	accounts, err := NewAccountService(db, testSecret, AccountConfig{}).GetAccounts(uint(user.ID), false)
+	if err != nil || len(accounts) != 2 {
+		t.Errorf("GetAccounts = %d accounts, %v; want 2", len(accounts), err)
+	}
+
+	if _, err := auth.SetTransfersEnabled(uint(admin.ID), uint(user.ID), true); err != nil {
+		t.Fatalf("re-enable: %v", err)
+	}
+	if err := transactions.ProcessTransfer(&models.TransferRequest{FromID: from.ID, ToID: to.ID, Amount: 10}, claims); err != nil {
+		t.Errorf("transfer after re-enabling: %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+// Path: internal/services/two_factor_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"testing"
+	"time"
+)
+
+// totpSecret is the base32 TOTP secret of the test users.
+const totpSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestElevateRejectsBadCodes(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	users := memUsers{
+		1: {ID: 1, Username: "secured", TOTPSecret: totpSecret, TOTPLastStep: utils.TOTPStep(now)},
+		2: {ID: 2, Username: "plain"},
+	}
+	s := NewAuthService(nil, testJWTSecret, AuthConfig{Users: users, Clock: utils.NewManualClock(now)})
+	current, _ := utils.TOTPCode(totpSecret, utils.TOTPStep(now))
+	stale, _ := utils.TOTPCode(totpSecret, utils.TOTPStep(now)-5)
+
+	tests := []struct {
+		name   string
+		userID uint
+		code   string
+		want   int
+	}{
+		{"missing code", 1, "", 400},
+		{"2FA not set up", 2, current, 400},
+		{"wrong code", 1, "12345", 401},
+		{"expired code", 1, stale, 401},
+		{"replayed code", 1, current, 401},
+		{"unknown user", 3, current, 404},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.Elevate(&models.Claims{UserID: tt.userID}, tt.code)
+			wantAppError(t, err, tt.want)
+		})
+	}
+}
+
+func TestElevateIssuesTwoFactorToken(t *testing.T) {
+	db := testDB(t)
+	now := time.Now()
+	clock := utils.NewManualClock(now)
+	s := NewAuthService(db, testJWTSecret, AuthConfig{Clock: clock})
+	user := seedUser(t, db, "elevated")
+	setup, err := s.SetupTwoFactor(uint(user.ID))
+	if err != nil {
+		t.Fatalf("SetupTwoFactor: %v", err)
+	}
+	if _, err := s.SetupTwoFactor(uint(user.ID)); err == nil {
+		t.Error("2FA secret replaced by a second setup")
+	}
+
+	base, err := s.ValidateToken(signTestToken(t, testJWTSecret, now))
+	if err != nil {
+		t.Fatalf("base token: %v", err)
+	}
+	base.UserID = uint(user.ID)
+	code, _ := utils.TOTPCode(setup.Secret, utils.TOTPStep(now))
+
+	token, err := s.Elevate(base, code)
+	if err != nil {
+		t.Fatalf("Elevate: %v", err)
+	}
+	claims, err := s.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("elevated token: %v", err)
+	}
+	if !claims.TwoFactor || claims.UserID != uint(user.ID) || len(claims.AMR) != 2 || claims.AMR[1] != "otp" {
+		t.Errorf("elevated claims = %+v", claims)
+	}
+	if !claims.ExpiresAt.Equal(base.ExpiresAt.Time) {
+		t.Errorf("elevated token expires at %v, want the base token's %v", claims.ExpiresAt, base.ExpiresAt)
+	}
+
+	_, err = s.Elevate(base, code)
+	wantAppError(t, err, 401)
+}
@@ -0,0 +1,141 @@
+// Path: internal/services/user_deletion.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultUserRetention is how long the data of a deleted user is kept before it can be purged.
+const DefaultUserRetention = 90 * 24 * time.Hour
+
+// DeleteUser soft-deletes a user: they can no longer log in nor be found, but their data stays
+// until it is purged after the retention window. Every real account of the user must be closed
+// first, so no money is left behind.
+func (s *authService) DeleteUser(actorID, userID uint) (*models.User, error) {
+	var user models.User
+	now := s.cfg.Clock.Now()
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.loadUser(tx, userID, &user); err != nil {
+			return err
+		}
+		if user.DeletedAt != nil {
+			return &AppError{Code: 409, Message: "User is already deleted", Details: fmt.Sprintf("user_id: %d", userID)}
+		}
+		if err := checkAccountsClosed(tx, userID); err != nil {
+			return err
+		}
+
+		result := tx.Model(&models.User{}).Where("id = ? AND deleted_at IS NULL", userID).Update("deleted_at", now)
+		if result.Error != nil {
+			return &AppError{Code: 500, Message: "Failed to delete user", Details: result.Error.Error(), Err: result.Error}
+		}
+		if result.RowsAffected == 0 {
+			return &AppError{Code: 409, Message: "User is already deleted", Details: fmt.Sprintf("user_id: %d", userID)}
+		}
+		user.DeletedAt = &now
+		return recordUserAudit(tx, actorID, "user_deleted", userID, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// PurgeUser erases a deleted user once the retention window has passed. The ledger must stay
+// intact, so their accounts and transactions are kept but anonymized: the accounts lose their
+// owner and nickname, the transactions their initiator, approver and description. Amounts and
+// timestamps are left as they are. The user row goes, and with it their payees, tags, webhooks,
+// login history and other data of their own.
+func (s *authService) PurgeUser(actorID, userID uint) error {
+	now := s.cfg.Clock.Now()
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := s.loadUser(tx, userID, &user); err != nil {
+			return err
+		}
+		if user.DeletedAt == nil {
+			return &AppError{Code: 409, Message: "User is not deleted", Details: fmt.Sprintf("user_id: %d", userID)}
+		}
+		if purgeAt := user.DeletedAt.Add(s.cfg.UserRetention); now.Before(purgeAt) {
+			return &AppError{Code: 409, Message: "Retention window has not passed", Details: fmt.Sprintf("user_id: %d, can be purged from %s", userID, purgeAt.Format(time.RFC3339))}
+		}
+		// An admin may have reopened an account since the deletion.
+		if err := checkAccountsClosed(tx, userID); err != nil {
+			return err
+		}
+
+		accounts := tx.Session(&gorm.Session{NewDB: true}).Model(&models.Account{}).Select("id").Where("user_id = ?", userID)
+		steps := []struct {
+			what  string
+			query *gorm.DB
+			set   map[string]interface{}
+		}{
+			{"transactions", tx.Model(&models.Transaction{}).Where("from_account_id IN (?) OR to_account_id IN (?)", accounts, accounts), map[string]interface{}{"description": ""}},
+			{"transactions", tx.Model(&models.Transaction{}).Where("initiator_id = ?", userID), map[string]interface{}{"initiator_id": nil}},
+			{"transactions", tx.Model(&models.Transaction{}).Where("approver_id = ?", userID), map[string]interface{}{"approver_id": nil}},
+			{"audit log", tx.Model(&models.AuditLog{}).Where("user_id = ?", userID), map[string]interface{}{"user_id": nil}},
+			// Sandbox accounts are left open by the deletion; nobody may pay into an ownerless account.
+			{"accounts", tx.Model(&models.Account{}).Where("user_id = ? AND closed_at IS NULL", userID), map[string]interface{}{"closed_at": now}},
+			{"accounts", tx.Model(&models.Account{}).Where("user_id = ?", userID), map[string]interface{}{"user_id": nil, "nickname": ""}},
+		}
+		for _, step := range steps {
+			if err := step.query.Updates(step.set).Error; err != nil {
+				return &AppError{Code: 500, Message: "Failed to anonymize " + step.what, Details: err.Error(), Err: err}
+			}
+		}
+
+		result := tx.Where("id = ? AND deleted_at IS NOT NULL", userID).Delete(&models.User{})
+		if result.Error != nil {
+			return &AppError{Code: 500, Message: "Failed to purge user", Details: result.Error.Error(), Err: result.Error}
+		}
+		if result.RowsAffected == 0 {
+			return &AppError{Code: 409, Message: "User changed while purging", Details: fmt.Sprintf("user_id: %d", userID)}
+		}
+		return recordUserAudit(tx, actorID, "user_purged", userID, now)
+	})
+}
+
+// loadUser loads a user, deleted or not, into user.
+func (s *authService) loadUser(tx *gorm.DB, userID uint, user *models.User) error {
+	if err := tx.First(user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &AppError{Code: 404, Message: "User not found", Details: fmt.Sprintf("user_id: %d", userID)}
+		}
+		return &AppError{Code: 500, Message: "Failed to query user", Details: err.Error(), Err: err}
+	}
+	return nil
+}
+
+// checkAccountsClosed rejects deleting a user who still has an open real account.
+func checkAccountsClosed(tx *gorm.DB, userID uint) error {
+	var open int64
+	err := tx.Model(&models.Account{}).Where("user_id = ? AND NOT sandbox AND closed_at IS NULL", userID).Count(&open).Error
+	if err != nil {
+		return &AppError{Code: 500, Message: "Failed to query accounts", Details: err.Error(), Err: err}
+	}
+	if open > 0 {
+		return &AppError{Code: 409, Message: "User has open accounts", Details: fmt.Sprintf("user_id: %d, open accounts: %d", userID, open)}
+	}
+	return nil
+}
+
+// recordUserAudit writes an audit log entry for an admin's change to a user.
+func recordUserAudit(tx *gorm.DB, actorID uint, action string, userID uint, at time.Time) error {
+	actor := int(actorID)
+	entry := models.AuditLog{
+		UserID:    &actor,
+		Action:    action,
+		Severity:  models.SeverityWarning,
+		Details:   fmt.Sprintf("user_id: %d", userID),
+		CreatedAt: at,
+	}
+	if err := tx.Create(&entry).Error; err != nil {
+		return &AppError{Code: 500, Message: "Failed to write audit log", Details: err.Error(), Err: err}
+	}
+	return nil
+}
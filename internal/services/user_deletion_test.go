@@ -0,0 +1,157 @@
+// Path: internal/services/user_deletion_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"testing"
+	"time"
+)
+
+func TestDeletedUsersCannotLogIn(t *testing.T) {
+	hash, err := hashPassword(PasswordAlgoBcrypt, "password1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	deletedAt := time.Now()
+	users := memUsers{1: {ID: 1, Username: "gone", Password: hash, DeletedAt: &deletedAt}}
+	s := NewAuthService(nil, testJWTSecret, AuthConfig{Users: users})
+
+	_, err = s.Login("gone", "password1", models.LoginMeta{})
+	wantAppError(t, err, 401)
+}
+
+func TestDeleteUser(t *testing.T) {
+	db := testDB(t)
+	admin := seedAdmin(t, db, "admin")
+	auth := NewAuthService(db, testJWTSecret, AuthConfig{BalanceSecret: testSecret})
+	if err := auth.Register("alice", "password1"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	var alice models.User
+	if err := db.Where("username = ?", "alice").First(&alice).Error; err != nil {
+		t.Fatal(err)
+	}
+	var account models.Account
+	if err := db.Where("user_id = ?", alice.ID).First(&account).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// An open account, even an empty one, blocks the deletion.
+	_, err := auth.DeleteUser(uint(admin.ID), uint(alice.ID))
+	wantAppError(t, err, 409)
+	accounts := NewAccountService(db, testSecret, AccountConfig{})
+	if _, err := accounts.CloseAccount(claimsFor(&alice), account.ID); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	deleted, err := auth.DeleteUser(uint(admin.ID), uint(alice.ID))
+	if err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if deleted.DeletedAt == nil {
+		t.Error("deleted user has no deleted_at")
+	}
+	_, err = auth.DeleteUser(uint(admin.ID), uint(alice.ID))
+	wantAppError(t, err, 409)
+
+	// The user is gone from logins and lookups, but their data stays.
+	_, err = auth.Login("alice", "password1", models.LoginMeta{})
+	wantAppError(t, err, 401)
+	if found, err := auth.SearchUsers(models.UserSearchFilter{Query: "alice"}); err != nil || len(found) != 0 {
+		t.Errorf("SearchUsers = %v, %v; want no users", found, err)
+	}
+	_, err = auth.SetTransfersEnabled(uint(admin.ID), uint(alice.ID), false)
+	wantAppError(t, err, 404)
+	if reloadAccount(t, db, account.ID).UserID != alice.ID {
+		t.Error("the account of a deleted user lost its owner before the purge")
+	}
+}
+
+func TestPurgeUser(t *testing.T) {
+	db := testDB(t)
+	admin := seedAdmin(t, db, "admin")
+	alice, bob := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+	aliceAccount, bobAccount := seedAccount(t, db, alice, 50, "USD"), seedAccount(t, db, bob, 30, "USD")
+
+	clock := utils.NewManualClock(time.Now())
+	transactions := NewTransactionService(db, testSecret, TransactionConfig{Clock: clock})
+	accounts := NewAccountService(db, testSecret, AccountConfig{Clock: clock})
+	auth := NewAuthService(db, testJWTSecret, AuthConfig{Clock: clock, UserRetention: 30 * 24 * time.Hour})
+
+	// Alice pays bob, bob pays her back a part, and she empties and closes her account.
+	if err := transactions.ProcessTransfer(&models.TransferRequest{FromID: aliceAccount.ID, ToID: bobAccount.ID, Amount: 50, Description: "Rent for Baker St 221b"}, claimsFor(alice)); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	if err := transactions.ProcessTransfer(&models.TransferRequest{FromID: bobAccount.ID, ToID: aliceAccount.ID, Amount: 20, Description: "Alice's share"}, claimsFor(bob)); err != nil {
+		t.Fatalf("transfer back: %v", err)
+	}
+	if _, err := transactions.ProcessWithdraw(&models.TransactionRequest{AccountID: aliceAccount.ID, Amount: 20}, claimsFor(alice)); err != nil {
+		t.Fatalf("withdraw: %v", err)
+	}
+	if _, err := accounts.CloseAccount(claimsFor(alice), aliceAccount.ID); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	var before []models.Transaction
+	if err := db.Where("from_account_id = ? OR to_account_id = ?", aliceAccount.ID, aliceAccount.ID).Order("id").Find(&before).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(before) != 3 {
+		t.Fatalf("alice has %d transactions, want 3", len(before))
+	}
+
+	// Only deleted users are purged, and only once the retention window has passed.
+	wantAppError(t, auth.PurgeUser(uint(admin.ID), uint(alice.ID)), 409)
+	if _, err := auth.DeleteUser(uint(admin.ID), uint(alice.ID)); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	clock.Advance(29 * 24 * time.Hour)
+	wantAppError(t, auth.PurgeUser(uint(admin.ID), uint(alice.ID)), 409)
+	var users int64
+	if db.Model(&models.User{}).Where("id = ?", alice.ID).Count(&users); users != 1 {
+		t.Fatal("user was purged before the retention window passed")
+	}
+
+	clock.Advance(2 * 24 * time.Hour)
+	if err := auth.PurgeUser(uint(admin.ID), uint(alice.ID)); err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	wantAppError(t, auth.PurgeUser(uint(admin.ID), uint(alice.ID)), 404)
+	if db.Model(&models.User{}).Where("id = ?", alice.ID).Count(&users); users != 0 {
+		t.Error("purged user still exists")
+	}
+
+	// Her transactions are still there with their amounts and times, but no longer point to her.
+	var after []models.Transaction
+	if err := db.Where("id IN ?", []string{before[0].ID, before[1].ID, before[2].ID}).Order("id").Find(&after).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(after) != len(before) {
+		t.Fatalf("%d transactions left after the purge, want %d", len(after), len(before))
+	}
+	for i, tx := range after {
+		if tx.Amount != before[i].Amount || !tx.CreatedAt.Equal(before[i].CreatedAt) || tx.Type != before[i].Type {
+			t.Errorf("transaction %s changed: %+v, was %+v", tx.ID, tx, before[i])
+		}
+		if tx.Description != "" {
+			t.Errorf("transaction %s kept the description %q", tx.ID, tx.Description)
+		}
+		if tx.InitiatorID != nil && *tx.InitiatorID == alice.ID {
+			t.Errorf("transaction %s still names alice as its initiator", tx.ID)
+		}
+		if tx.FromAccountID == nil && tx.ToAccountID == nil {
+			t.Errorf("transaction %s lost its accounts", tx.ID)
+		}
+	}
+
+	// Her account stays for the ledger, closed and without an owner; bob's is untouched.
+	var owners []*int
+	if err := db.Model(&models.Account{}).Where("id = ?", aliceAccount.ID).Pluck("user_id", &owners).Error; err != nil || len(owners) != 1 || owners[0] != nil {
+		t.Errorf("purged account owners = %v, %v; want one account without an owner", owners, err)
+	}
+	if got := reloadAccount(t, db, aliceAccount.ID); got.ClosedAt == nil || got.Balance != 0 {
+		t.Errorf("purged account: closed at %v, balance %v", got.ClosedAt, got.Balance)
+	}
+	if got := reloadAccount(t, db, bobAccount.ID).Balance; got != 60 {
+		t.Errorf("bob's balance = %v, want 60", got)
+	}
+}
@@ -0,0 +1,46 @@
+// Path: internal/services/user_search.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"strings"
+)
+
+// User search paging limits.
+const (
+	DefaultUserSearchLimit = 20
+	MaxUserSearchLimit     = 100
+)
+
+// likeEscaper escapes the LIKE wildcards so that the query only ever matches as a literal prefix.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchUsers finds users by username prefix, ordered by username, with their account counts.
+// Deleted users are left out.
+func (s *authService) SearchUsers(filter models.UserSearchFilter) ([]models.UserSummary, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultUserSearchLimit
+	}
+	if filter.Limit > MaxUserSearchLimit {
+		filter.Limit = MaxUserSearchLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	query := s.db.Model(&models.User{}).
+		Select("users.id, users.username, users.role, users.tier, users.transfers_enabled, users.created_at, " +
+			"(SELECT COUNT(*) FROM accounts WHERE accounts.user_id = users.id) AS account_count").
+		Where("users.deleted_at IS NULL")
+	if q := strings.TrimSpace(filter.Query); q != "" {
+		query = query.Where(`users.username ILIKE ? ESCAPE '\'`, likeEscaper.Replace(q)+"%")
+	}
+
+	users := []models.UserSummary{}
+	err := query.Order("users.username").Limit(filter.Limit).Offset(filter.Offset).Scan(&users).Error
+	if err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to search users", Details: err.Error(), Err: err}
+	}
+
+	return users, nil
+}
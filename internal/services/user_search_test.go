@@ -0,0 +1,65 @@
+// Path: internal/services/user_search_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"testing"
+)
+
+func TestLikeEscaperMatchesLiterally(t *testing.T) {
+	for in, want := range map[string]string{"al": "al", "100%": `100\%`, "a_b": `a\_b`, `c:\x`: `c:\\x`} {
+		if got := likeEscaper.Replace(in); got != want {
+			t.Errorf("escape %q = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSearchUsers(t *testing.T) {
+	db := testDB(t)
+	for _, name := range []string{"alice", "Albert", "al_x", "bob"} {
+		user := seedUser(t, db, name)
+		if name == "alice" {
+			seedAccount(t, db, user, 0, "USD")
+			seedAccount(t, db, user, 0, "EUR")
+		}
+	}
+	s := NewAuthService(db, testJWTSecret, AuthConfig{})
+	search := func(filter models.UserSearchFilter) []string {
+		t.Helper()
+		users, err := s.SearchUsers(filter)
+		if err != nil {
+			t.Fatalf("SearchUsers(%+v): %v", filter, err)
+		}
+		names := make([]string, len(users))
+		for i, u := range users {
+			names[i] = u.Username
+		}
+		return names
+	}
+
+	if got := search(models.UserSearchFilter{Query: "AL"}); len(got) != 3 {
+		t.Errorf("prefix AL = %v, want the three al* users", got)
+	}
+	if got := search(models.UserSearchFilter{Query: "al_"}); len(got) != 1 || got[0] != "al_x" {
+		t.Errorf("prefix al_ = %v, want only al_x: _ is not a wildcard", got)
+	}
+	if got := search(models.UserSearchFilter{Query: "%"}); len(got) != 0 {
+		t.Errorf("prefix %% = %v, want none", got)
+	}
+	if got := search(models.UserSearchFilter{Query: "x' OR '1'='1"}); len(got) != 0 {
+		t.Errorf("injection attempt = %v, want none", got)
+	}
+
+	all := search(models.UserSearchFilter{})
+	if len(all) != 4 {
+		t.Fatalf("all users = %v", all)
+	}
+	if page := search(models.UserSearchFilter{Limit: 2, Offset: 1}); len(page) != 2 || page[0] != all[1] || page[1] != all[2] {
+		t.Errorf("page = %v, want %v", page, all[1:3])
+	}
+
+	users, _ := s.SearchUsers(models.UserSearchFilter{Query: "alice"})
+	if len(users) != 1 || users[0].AccountCount != 2 {
+		t.Errorf("alice = %+v, want 2 accounts", users)
+	}
+}
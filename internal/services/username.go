@@ -0,0 +1,71 @@
+// Path: internal/services/username.go
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Default username length bounds.
+const (
+	DefaultUsernameMinLength = 3
+	DefaultUsernameMaxLength = 32
+)
+
+// DefaultReservedUsernames can't be registered, so nobody can pose as staff or the system.
+var DefaultReservedUsernames = []string{"admin", "administrator", "root", "system", "support", "security", "bank", "api", "null"}
+
+// usernamePattern allows lowercase letters, digits and . _ - inside; usernames start with a letter or digit.
+var usernamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// UsernamePolicy holds the rules for new usernames.
+type UsernamePolicy struct {
+	// MinLength and MaxLength bound the length in characters. Default to DefaultUsernameMinLength
+	// and DefaultUsernameMaxLength.
+	MinLength int
+	MaxLength int
+	// Reserved usernames can't be registered. Defaults to DefaultReservedUsernames.
+	Reserved []string
+}
+
+func (p UsernamePolicy) withDefaults() UsernamePolicy {
+	if p.MinLength <= 0 {
+		p.MinLength = DefaultUsernameMinLength
+	}
+	if p.MaxLength <= 0 {
+		p.MaxLength = DefaultUsernameMaxLength
+	}
+	if p.Reserved == nil {
+		p.Reserved = DefaultReservedUsernames
+	}
+	return p
+}
+
+// normalizeUsername makes usernames case-insensitive: "Alice" and "alice" are the same user.
+func normalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// username normalizes a new username in place and checks it against the policy.
+func (v *validation) username(field string, username *string, policy UsernamePolicy) {
+	*username = normalizeUsername(*username)
+	length := utf8.RuneCountInString(*username)
+
+	switch {
+	case length < policy.MinLength:
+		v.check(false, field, fmt.Sprintf("Username must be at least %d characters", policy.MinLength))
+	case length > policy.MaxLength:
+		v.check(false, field, fmt.Sprintf("Username must be at most %d characters", policy.MaxLength))
+	case !usernamePattern.MatchString(*username):
+		v.check(false, field, "Username may only contain letters, digits, '.', '_' and '-', and must start with a letter or digit")
+	default:
+		for _, reserved := range policy.Reserved {
+			if *username == normalizeUsername(reserved) {
+				v.check(false, field, "Username is reserved")
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Path: internal/services/username_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"strings"
+	"testing"
+)
+
+func TestRegisterRejectsBadUsernames(t *testing.T) {
+	s := NewAuthService(nil, testJWTSecret, AuthConfig{})
+	tests := []struct {
+		name, username, message string
+	}{
+		{"empty", "", "at least 3"},
+		{"too short", "ab", "at least 3"},
+		{"too long", strings.Repeat("a", DefaultUsernameMaxLength+1), "at most 32"},
+		{"space", "john doe", "may only contain"},
+		{"control character", "john\x00doe", "may only contain"},
+		{"leading punctuation", ".john", "may only contain"},
+		{"reserved", "admin", "reserved"},
+		{"reserved in capitals", "  Root ", "reserved"},
+	}
+	for _, tt := range tests {
+		err := s.Register(tt.username, "password1")
+		appErr := wantAppError(t, err, 400)
+		if len(appErr.Fields) != 1 || appErr.Fields[0].Field != "username" || !strings.Contains(appErr.Fields[0].Message, tt.message) {
+			t.Errorf("%s: fields = %+v, want a username error containing %q", tt.name, appErr.Fields, tt.message)
+		}
+	}
+}
+
+func TestUsernamePolicyIsConfigurable(t *testing.T) {
+	policy := UsernamePolicy{MinLength: 5, MaxLength: 8, Reserved: []string{"Teller"}}.withDefaults()
+	for username, wantErr := range map[string]bool{
+		"abcd":      true,
+		"abcde":     false,
+		"abcdefghi": true,
+		"teller":    true,
+		"admin":     false, // Only the configured names are reserved
+	} {
+		var v validation
+		v.username("username", &username, policy)
+		if got := v.err() != nil; got != wantErr {
+			t.Errorf("%q: error %t, want %t", username, got, wantErr)
+		}
+	}
+}
+
+func TestUsernameIsNormalized(t *testing.T) {
+	var v validation
+	username := "  Jane.Doe_1 "
+	v.username("username", &username, UsernamePolicy{}.withDefaults())
+	if err := v.err(); err != nil || username != "jane.doe_1" {
+		t.Errorf("username = %q, %v; want jane.doe_1", username, err)
+	}
+}
+
+func TestUsernamesAreCaseInsensitive(t *testing.T) {
+	db := testDB(t)
+	auth := NewAuthService(db, testJWTSecret, AuthConfig{})
+	if err := auth.Register("Alice", "password1"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	var user models.User
+	if err := db.First(&user).Error; err != nil || user.Username != "alice" {
+		t.Errorf("stored username = %q, %v; want alice", user.Username, err)
+	}
+	if _, err := auth.Login("ALICE", "password1", models.LoginMeta{}); err != nil {
+		t.Errorf("login in capitals: %v", err)
+	}
+	err := auth.Register("aLiCe", "password2")
+	if appErr := wantAppError(t, err, 400); appErr.Message != "User already exists" {
+		t.Errorf("duplicate in other case: %q", appErr.Message)
+	}
+}
@@ -0,0 +1,174 @@
+// Path: internal/services/validation.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// FieldError describes why a single request field is invalid.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// validation collects field errors so that a request reports every invalid field at once.
+type validation struct {
+	fields []FieldError
+}
+
+// check records message for field unless ok holds.
+func (v *validation) check(ok bool, field, message string) {
+	if !ok {
+		v.fields = append(v.fields, FieldError{Field: field, Message: message})
+	}
+}
+
+// add records err for field, using the details of an AppError as the message.
+func (v *validation) add(field string, err error) {
+	message := err.Error()
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		message = appErr.Details
+	}
+	v.fields = append(v.fields, FieldError{Field: field, Message: message})
+}
+
+// err returns a 400 AppError listing every failing field, or nil if the request is valid.
+func (v *validation) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(v.fields))
+	for i, field := range v.fields {
+		names[i] = field.Field
+	}
+	return &AppError{Code: 400, Message: "Validation failed", Details: "Invalid fields: " + strings.Join(names, ", "), Fields: v.fields}
+}
+
+// description sanitizes a description field in place.
+func (v *validation) description(field string, description *string) {
+	sanitized, err := sanitizeDescription(*description)
+	if err != nil {
+		v.add(field, err)
+		return
+	}
+	*description = sanitized
+}
+
+// validateCredentials checks the username and password of a registration or login.
+func validateCredentials(username, password string) error {
+	var v validation
+	v.check(strings.TrimSpace(username) != "", "username", "Username is required")
+	v.check(password != "", "password", "Password is required")
+	return v.err()
+}
+
+// validateTransactionRequest checks a deposit or withdrawal and sanitizes its description.
+func validateTransactionRequest(req *models.TransactionRequest) error {
+	var v validation
+	v.check(req.Amount > 0, "amount", "Amount must be positive")
+	v.description("description", &req.Description)
+	return v.err()
+}
+
+// validateTransferRequest checks a transfer and sanitizes its description.
+// The destination is only checked for shape here; payees and account numbers are resolved later.
+func validateTransferRequest(req *models.TransferRequest) error {
+	var v validation
+	v.check(req.Amount > 0 || req.SendAll, "amount", "Amount must be positive")
+	v.check(req.Amount == 0 || !req.SendAll, "amount", "Amount can't be given with send_all")
+	v.check(req.FromID > 0, "from_id", "Source account is required")
+
+	given := 0
+	for _, set := range []bool{req.ToID != 0, req.PayeeID != 0, req.ToNumber != ""} {
+		if set {
+			given++
+		}
+	}
+	v.check(given > 0, "to_id", "Specify one of to_id, payee_id or to_account_number")
+	v.check(given <= 1, "to_id", "Specify only one of to_id, payee_id or to_account_number")
+	v.check(req.ToID == 0 || req.ToID != req.FromID, "to_id", "Source and destination accounts must be different")
+
+	v.description("description", &req.Description)
+	return v.err()
+}
+
+// validateCreateAccountRequest checks a new account request, normalizing its currency and nickname.
+func validateCreateAccountRequest(req *models.CreateAccountRequest) error {
+	var v validation
+	req.Currency = strings.ToUpper(strings.TrimSpace(req.Currency))
+	if req.Currency == "" {
+		req.Currency = DefaultCurrency
+	}
+	v.check(currencyCodePattern.MatchString(req.Currency), "currency", "Currency must be a 3-letter ISO 4217 code")
+
+	req.Nickname = strings.TrimSpace(req.Nickname)
+	v.check(utf8.RuneCountInString(req.Nickname) <= MaxNicknameLength, "nickname", fmt.Sprintf("Nickname must be at most %d characters", MaxNicknameLength))
+
+	req.Type = strings.ToLower(strings.TrimSpace(req.Type))
+	if req.Type == "" {
+		req.Type = models.AccountTypeChecking
+	}
+	v.check(IsSupportedAccountType(req.Type), "type", "Type must be checking, savings or overdraft")
+	return v.err()
+}
+
+// validateUpdateAccountRequest checks an account update, trimming its nickname.
+func validateUpdateAccountRequest(req *models.UpdateAccountRequest) error {
+	var v validation
+	req.Nickname = strings.TrimSpace(req.Nickname)
+	v.check(utf8.RuneCountInString(req.Nickname) <= MaxNicknameLength, "nickname", fmt.Sprintf("Nickname must be at most %d characters", MaxNicknameLength))
+	return v.err()
+}
+
+// validatePayeeRequest checks a new payee, trimming its label.
+func validatePayeeRequest(req *models.PayeeRequest) error {
+	var v validation
+	v.check(req.AccountID > 0, "account_id", "Account is required")
+
+	req.Label = strings.TrimSpace(req.Label)
+	length := utf8.RuneCountInString(req.Label)
+	v.check(length > 0 && length <= MaxPayeeLabelLength, "label", fmt.Sprintf("Label must be 1 to %d characters", MaxPayeeLabelLength))
+	return v.err()
+}
+
+// validateTagsRequest checks every tag of a tag update and returns the normalized, deduplicated tags.
+func validateTagsRequest(req *models.TagsRequest) (add, remove []string, err error) {
+	var v validation
+	add = v.tags("add", req.Add)
+	remove = v.tags("remove", req.Remove)
+	return add, remove, v.err()
+}
+
+// tags normalizes a list of tags, recording each invalid one under field[index].
+func (v *validation) tags(field string, tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool)
+	for i, tag := range tags {
+		tag = normalizeTag(tag)
+		valid := tag != "" && utf8.RuneCountInString(tag) <= maxTagLength && tagPattern.MatchString(tag)
+		v.check(valid, fmt.Sprintf("%s[%d]", field, i), fmt.Sprintf("Tags must be 1 to %d letters, digits, '_' or '-'", maxTagLength))
+		if !valid {
+			continue
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
+
+// validateCurrencyPair checks the currencies of a rate quote, upper-casing them.
+func validateCurrencyPair(from, to *string) error {
+	var v validation
+	*from, *to = strings.ToUpper(*from), strings.ToUpper(*to)
+	v.check(currencyCodePattern.MatchString(*from), "from", "Currency must be a 3-letter ISO 4217 code")
+	v.check(currencyCodePattern.MatchString(*to), "to", "Currency must be a 3-letter ISO 4217 code")
+	return v.err()
+}
@@ -0,0 +1,55 @@
+// Path: internal/services/validation_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fieldNames lists the invalid fields of a validation error, in order.
+func fieldNames(t *testing.T, err error) []string {
+	t.Helper()
+	appErr := wantAppError(t, err, 400)
+	names := make([]string, len(appErr.Fields))
+	for i, field := range appErr.Fields {
+		names[i] = field.Field
+	}
+	return names
+}
+
+func TestValidationListsEveryInvalidField(t *testing.T) {
+	long := strings.Repeat("d", MaxDescriptionLength+1)
+	tests := []struct {
+		name string
+		err  error
+		want []string
+	}{
+		{"credentials", validateCredentials(" ", ""), []string{"username", "password"}},
+		{"deposit", validateTransactionRequest(&models.TransactionRequest{Amount: -1, Description: long}), []string{"amount", "description"}},
+		{"transfer", validateTransferRequest(&models.TransferRequest{Amount: 0, Description: long}), []string{"amount", "from_id", "to_id", "description"}},
+		{"account", validateCreateAccountRequest(&models.CreateAccountRequest{Currency: "dollars", Nickname: strings.Repeat("n", MaxNicknameLength+1), Type: "gold"}), []string{"currency", "nickname", "type"}},
+		{"payee", validatePayeeRequest(&models.PayeeRequest{Label: " "}), []string{"account_id", "label"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fieldNames(t, tt.err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("invalid fields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidationAcceptsValidRequests(t *testing.T) {
+	req := &models.CreateAccountRequest{Currency: " eur ", Nickname: " Savings "}
+	if err := validateCreateAccountRequest(req); err != nil {
+		t.Fatalf("valid account request: %v", err)
+	}
+	if req.Currency != "EUR" || req.Nickname != "Savings" || req.Type != models.AccountTypeChecking {
+		t.Errorf("normalized request = %+v", req)
+	}
+	if err := validateTransferRequest(&models.TransferRequest{Amount: 5, FromID: 1, ToID: 2}); err != nil {
+		t.Errorf("valid transfer: %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+// Path: internal/services/velocity.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"sync"
+	"time"
+)
+
+// DefaultVelocityCacheTTL is how long a computed velocity is reused by default.
+const DefaultVelocityCacheTTL = 30 * time.Second
+
+type velocityKey struct {
+	userID  uint
+	sandbox bool
+}
+
+type cachedVelocity struct {
+	velocity *models.Velocity
+	expires  time.Time
+}
+
+// velocityCache keeps the velocity of recently asked users, so that polling clients and rules
+// evaluated on every payment don't run the aggregate each time.
+type velocityCache struct {
+	mu      sync.Mutex
+	entries map[velocityKey]cachedVelocity
+}
+
+func (c *velocityCache) get(key velocityKey, now time.Time) (*models.Velocity, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.entries[key]
+	if !ok || !now.Before(cached.expires) {
+		return nil, false
+	}
+	return cached.velocity, true
+}
+
+// put stores a velocity and drops the expired ones, so the cache only holds active users.
+func (c *velocityCache) put(key velocityKey, velocity *models.Velocity, expires time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, cached := range c.entries {
+		if !velocity.ComputedAt.Before(cached.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = cachedVelocity{velocity: velocity, expires: expires}
+}
+
+// Velocity returns the number and volume of the user's withdrawals and transfers in the last hour
+// and the last 24 hours, counting the ones that count toward the daily spending limit. A window
+// ending now holds the transactions created strictly after its start. Results are cached for
+// VelocityCacheTTL, so they can lag behind by that much; the returned value must not be modified.
+func (s *transactionService) Velocity(userID uint, sandbox bool) (*models.Velocity, error) {
+	key := velocityKey{userID: userID, sandbox: sandbox}
+	now := s.clock.Now()
+	if velocity, ok := s.velocity.get(key, now); ok {
+		return velocity, nil
+	}
+
+	var rows []struct {
+		Currency   string
+		DayCount   int64
+		DayVolume  float64
+		HourCount  int64
+		HourVolume float64
+	}
+	hourStart, dayStart := now.Add(-time.Hour), now.Add(-24*time.Hour)
+	err := s.db.Model(&models.Transaction{}).
+		Joins("JOIN accounts ON accounts.id = transactions.from_account_id").
+		Select(`accounts.currency,
+			COUNT(*) AS day_count,
+			COALESCE(SUM(transactions.amount), 0) AS day_volume,
+			COUNT(*) FILTER (WHERE transactions.created_at > ?) AS hour_count,
+			COALESCE(SUM(transactions.amount) FILTER (WHERE transactions.created_at > ?), 0) AS hour_volume`, hourStart, hourStart).
+		Where("accounts.user_id = ? AND accounts.sandbox = ?", userID, sandbox).
+		Where("transactions.type IN ? AND transactions.status IN ? AND transactions.created_at > ?",
+			[]string{"withdraw", "transfer"}, []string{"completed", "pending_approval", "incoming_pending"}, dayStart).
+		Group("accounts.currency").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to calculate velocity", Details: err.Error(), Err: err}
+	}
+
+	velocity := &models.Velocity{
+		LastHour:   models.VelocityWindow{Volume: map[string]float64{}},
+		LastDay:    models.VelocityWindow{Volume: map[string]float64{}},
+		ComputedAt: now,
+	}
+	for _, row := range rows {
+		velocity.LastDay.Count += row.DayCount
+		velocity.LastDay.Volume[row.Currency] = s.roundAmount(row.DayVolume, row.Currency)
+		if row.HourCount > 0 {
+			velocity.LastHour.Count += row.HourCount
+			velocity.LastHour.Volume[row.Currency] = s.roundAmount(row.HourVolume, row.Currency)
+		}
+	}
+
+	s.velocity.put(key, velocity, now.Add(s.cfg.VelocityCacheTTL))
+	return velocity, nil
+}
@@ -0,0 +1,99 @@
+// Path: internal/services/velocity_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestVelocityIsCached(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	clock := utils.NewManualClock(now)
+	// No database: a cache miss would panic.
+	s := NewTransactionService(nil, testSecret, TransactionConfig{Clock: clock, VelocityCacheTTL: time.Minute}).(*transactionService)
+	cached := &models.Velocity{LastHour: models.VelocityWindow{Count: 3}, ComputedAt: now}
+	s.velocity.put(velocityKey{userID: 1}, cached, now.Add(time.Minute))
+
+	clock.Advance(59 * time.Second)
+	if got, err := s.Velocity(1, false); err != nil || got != cached {
+		t.Errorf("Velocity within the TTL = %+v, %v; want the cached value", got, err)
+	}
+	if _, ok := s.velocity.get(velocityKey{userID: 1, sandbox: true}, clock.Now()); ok {
+		t.Error("sandbox velocity served from the live entry")
+	}
+	if _, ok := s.velocity.get(velocityKey{userID: 1}, now.Add(time.Minute)); ok {
+		t.Error("entry served at its expiry")
+	}
+}
+
+func TestVelocityCacheDropsExpiredEntries(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	c := &velocityCache{entries: make(map[velocityKey]cachedVelocity)}
+	c.put(velocityKey{userID: 1}, &models.Velocity{ComputedAt: now}, now.Add(time.Minute))
+	c.put(velocityKey{userID: 2}, &models.Velocity{ComputedAt: now.Add(30 * time.Second)}, now.Add(90*time.Second))
+	c.put(velocityKey{userID: 3}, &models.Velocity{ComputedAt: now.Add(time.Minute)}, now.Add(2*time.Minute))
+
+	if len(c.entries) != 2 {
+		t.Errorf("%d entries, want the expired one dropped", len(c.entries))
+	}
+	if _, ok := c.entries[velocityKey{userID: 1}]; ok {
+		t.Error("expired entry kept")
+	}
+}
+
+func TestVelocityWindows(t *testing.T) {
+	db := testDB(t)
+	alice, bob := seedUser(t, db, "alice"), seedUser(t, db, "bob")
+	usd, eur, bobs := seedAccount(t, db, alice, 0, "USD"), seedAccount(t, db, alice, 0, "EUR"), seedAccount(t, db, bob, 0, "USD")
+	now := time.Now().UTC().Truncate(time.Second)
+	s := NewTransactionService(db, testSecret, TransactionConfig{Clock: utils.NewManualClock(now)})
+
+	for i, tx := range []struct {
+		account *models.Account
+		typ     string
+		status  string
+		amount  float64
+		ago     time.Duration
+	}{
+		{usd, "withdraw", "completed", 10, 30 * time.Minute},
+		{usd, "transfer", "pending_approval", 20, time.Hour - time.Second},
+		{eur, "transfer", "completed", 5, 10 * time.Minute},
+		{usd, "withdraw", "completed", 40, time.Hour}, // The hour starts strictly after this
+		{usd, "transfer", "incoming_pending", 50, 23 * time.Hour},
+		{usd, "withdraw", "completed", 1000, 24 * time.Hour}, // Outside the day
+		{usd, "deposit", "completed", 1000, time.Minute},     // Not outgoing
+		{usd, "withdraw", "failed", 1000, time.Minute},       // Didn't happen
+		{usd, "transfer", "cancelled", 1000, time.Minute},    // Didn't happen
+		{bobs, "withdraw", "completed", 1000, time.Minute},   // Someone else's
+	} {
+		created := now.Add(-tx.ago)
+		record := models.Transaction{
+			ID:            utils.GenerateTransactionID(created),
+			FromAccountID: &tx.account.ID,
+			Amount:        tx.amount,
+			Type:          tx.typ,
+			Status:        tx.status,
+			CreatedAt:     created,
+		}
+		if err := db.Create(&record).Error; err != nil {
+			t.Fatalf("seed transaction %d: %v", i, err)
+		}
+	}
+
+	velocity, err := s.Velocity(uint(alice.ID), false)
+	if err != nil {
+		t.Fatalf("velocity: %v", err)
+	}
+	wantHour := models.VelocityWindow{Count: 3, Volume: map[string]float64{"USD": 30, "EUR": 5}}
+	wantDay := models.VelocityWindow{Count: 5, Volume: map[string]float64{"USD": 120, "EUR": 5}}
+	if !reflect.DeepEqual(velocity.LastHour, wantHour) || !reflect.DeepEqual(velocity.LastDay, wantDay) {
+		t.Errorf("velocity = hour %+v, day %+v; want %+v and %+v", velocity.LastHour, velocity.LastDay, wantHour, wantDay)
+	}
+
+	if velocity, err := s.Velocity(uint(alice.ID), true); err != nil || velocity.LastDay.Count != 0 {
+		t.Errorf("sandbox velocity = %+v, %v; want nothing", velocity, err)
+	}
+}
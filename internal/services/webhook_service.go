@@ -0,0 +1,300 @@
+// Path: internal/services/webhook_service.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// WebhookMaxAttempts is how many times a delivery is attempted before it is marked failed.
+	WebhookMaxAttempts = 3
+	// MaxWebhookDeliveries caps the number of deliveries listed at once.
+	MaxWebhookDeliveries = 100
+	// webhookSignatureHeader carries the hex HMAC-SHA256 of the body, keyed with the webhook secret.
+	webhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// WebhookService registers webhooks and delivers the user's transaction events to them.
+type WebhookService interface {
+	CreateWebhook(userID uint, req *models.WebhookRequest) (*models.Webhook, error)
+	GetWebhooks(userID uint) ([]models.Webhook, error)
+	GetDeliveries(userID uint, webhookID, limit int) ([]models.WebhookDelivery, error)
+	Redeliver(userID uint, webhookID, deliveryID int) (*models.WebhookDelivery, error)
+	Dispatch(userID uint, event string, payload interface{})
+	// Shutdown stops sending new deliveries and waits for the ones in flight, until ctx is done.
+	Shutdown(ctx context.Context) error
+}
+
+type webhookService struct {
+	db         *gorm.DB
+	client     *http.Client
+	clock      utils.Clock
+	retryDelay time.Duration
+
+	mu       sync.Mutex
+	inFlight sync.WaitGroup
+	closed   bool
+	stop     chan struct{} // Closed by Shutdown to cut retry backoffs short
+}
+
+// NewWebhookService creates a new WebhookService. A nil client defaults to one with a 10 second timeout.
+func NewWebhookService(db *gorm.DB, client *http.Client, clock utils.Clock) WebhookService {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &webhookService{
+		db:         db,
+		client:     client,
+		clock:      clock,
+		retryDelay: time.Second,
+		stop:       make(chan struct{}),
+	}
+}
+
+// CreateWebhook registers an endpoint for the user's events. The signing secret is only returned here.
+func (s *webhookService) CreateWebhook(userID uint, req *models.WebhookRequest) (*models.Webhook, error) {
+	var v validation
+	endpoint, err := url.Parse(req.URL)
+	v.check(err == nil && (endpoint.Scheme == "https" || endpoint.Scheme == "http") && endpoint.Host != "", "url", "An absolute http or https URL is required")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to generate webhook secret", Details: err.Error(), Err: err}
+	}
+
+	webhook := models.Webhook{
+		UserID:    int(userID),
+		URL:       req.URL,
+		Secret:    hex.EncodeToString(secret),
+		CreatedAt: s.clock.Now(),
+	}
+	if err := s.db.Create(&webhook).Error; err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to save webhook", Details: err.Error(), Err: err}
+	}
+
+	return &webhook, nil
+}
+
+// GetWebhooks lists the user's webhooks, without their secrets.
+func (s *webhookService) GetWebhooks(userID uint) ([]models.Webhook, error) {
+	webhooks := []models.Webhook{}
+	if err := s.db.Omit("secret").Where("user_id = ?", userID).Order("id").Find(&webhooks).Error; err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to query webhooks", Details: err.Error(), Err: err}
+	}
+	return webhooks, nil
+}
+
+// GetDeliveries lists the most recent deliveries of one of the user's webhooks, newest first.
+func (s *webhookService) GetDeliveries(userID uint, webhookID, limit int) ([]models.WebhookDelivery, error) {
+	if limit <= 0 || limit > MaxWebhookDeliveries {
+		limit = MaxWebhookDeliveries
+	}
+	if _, err := s.findWebhook(userID, webhookID); err != nil {
+		return nil, err
+	}
+
+	deliveries := []models.WebhookDelivery{}
+	err := s.db.Where("webhook_id = ?", webhookID).Order("created_at DESC, id DESC").Limit(limit).Find(&deliveries).Error
+	if err != nil {
+		return nil, &AppError{Code: 500, Message: "Failed to query webhook deliveries", Details: err.Error(), Err: err}
+	}
+	return deliveries, nil
+}
+
+// Redeliver sends a delivery once more, whatever its status, and returns its updated record.
+func (s *webhookService) Redeliver(userID uint, webhookID, deliveryID int) (*models.WebhookDelivery, error) {
+	webhook, err := s.findWebhook(userID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	var delivery models.WebhookDelivery
+	if err := s.db.Where("id = ? AND webhook_id = ?", deliveryID, webhookID).First(&delivery).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &AppError{Code: 404, Message: "Delivery not found", Details: fmt.Sprintf("delivery_id: %d", deliveryID)}
+		}
+		return nil, &AppError{Code: 500, Message: "Failed to query webhook delivery", Details: err.Error(), Err: err}
+	}
+
+	if err := s.attempt(webhook, &delivery); err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// Dispatch records the event for each of the user's webhooks and delivers it in the background,
+// retrying up to WebhookMaxAttempts times. Failures are recorded on the delivery, never returned.
+// After Shutdown deliveries are only recorded, as pending, and can be redelivered later.
+func (s *webhookService) Dispatch(userID uint, event string, payload interface{}) {
+	var webhooks []models.Webhook
+	if err := s.db.Where("user_id = ?", userID).Find(&webhooks).Error; err != nil {
+		log.Printf("Failed to query webhooks of user %d: %v", userID, err)
+		return
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"event": event, "data": payload})
+	if err != nil {
+		log.Printf("Failed to encode %s webhook event: %v", event, err)
+		return
+	}
+
+	for i := range webhooks {
+		webhook := &webhooks[i]
+		delivery := &models.WebhookDelivery{
+			WebhookID: webhook.ID,
+			Event:     event,
+			Payload:   string(body),
+			Status:    "pending",
+			CreatedAt: s.clock.Now(),
+		}
+		if err := s.db.Create(delivery).Error; err != nil {
+			log.Printf("Failed to record %s delivery to webhook %d: %v", event, webhook.ID, err)
+			continue
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			continue
+		}
+		s.inFlight.Add(1)
+		s.mu.Unlock()
+		go func() {
+			defer s.inFlight.Done()
+			s.deliver(webhook, delivery)
+		}()
+	}
+}
+
+// Shutdown stops sending new deliveries and waits for the ones in flight. Deliveries waiting to be
+// retried stop waiting: they make no more attempts and stay pending. Every delivery is recorded
+// before it is sent, so none is lost even if ctx ends first.
+func (s *webhookService) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.stop)
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.inFlight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// deliver attempts a delivery until it succeeds or runs out of attempts, backing off between them.
+func (s *webhookService) deliver(webhook *models.Webhook, delivery *models.WebhookDelivery) {
+	delay := s.retryDelay
+	for delivery.Attempts < WebhookMaxAttempts {
+		if err := s.attempt(webhook, delivery); err != nil {
+			log.Printf("Failed to record attempt of webhook delivery %d: %v", delivery.ID, err)
+			return
+		}
+		if delivery.Status == "delivered" {
+			return
+		}
+		select {
+		case <-time.After(delay):
+		case <-s.stop:
+			return
+		}
+		delay *= 2
+	}
+}
+
+// attempt POSTs the delivery once and records the outcome. A 2xx response marks it delivered;
+// otherwise it stays pending until WebhookMaxAttempts is reached, then fails.
+func (s *webhookService) attempt(webhook *models.Webhook, delivery *models.WebhookDelivery) error {
+	statusCode, sendErr := s.post(webhook, delivery.Payload)
+
+	now := s.clock.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.StatusCode = statusCode
+	delivery.LastError = ""
+	switch {
+	case sendErr == nil:
+		delivery.Status = "delivered"
+	case delivery.Attempts >= WebhookMaxAttempts:
+		delivery.Status = "failed"
+		delivery.LastError = sendErr.Error()
+	default:
+		delivery.Status = "pending"
+		delivery.LastError = sendErr.Error()
+	}
+
+	err := s.db.Model(delivery).Updates(map[string]interface{}{
+		"status":          delivery.Status,
+		"status_code":     delivery.StatusCode,
+		"attempts":        delivery.Attempts,
+		"last_error":      delivery.LastError,
+		"last_attempt_at": delivery.LastAttemptAt,
+	}).Error
+	if err != nil {
+		return &AppError{Code: 500, Message: "Failed to update webhook delivery", Details: err.Error(), Err: err}
+	}
+	return nil
+}
+
+// post sends a signed payload and returns the response status, or 0 if there was no response.
+func (s *webhookService) post(webhook *models.Webhook, payload string) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewBufferString(payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhookSignatureHeader, utils.CreateHMAC(payload, []byte(webhook.Secret)))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+func (s *webhookService) findWebhook(userID uint, webhookID int) (*models.Webhook, error) {
+	var webhook models.Webhook
+	if err := s.db.Where("id = ? AND user_id = ?", webhookID, userID).First(&webhook).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &AppError{Code: 404, Message: "Webhook not found", Details: fmt.Sprintf("webhook_id: %d", webhookID)}
+		}
+		return nil, &AppError{Code: 500, Message: "Failed to query webhook", Details: err.Error(), Err: err}
+	}
+	return &webhook, nil
+}
@@ -0,0 +1,195 @@
+// Path: internal/services/webhook_service_test.go
+package services
+
+import (
+	"bank-api/internal/models"
+	"bank-api/pkg/utils"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCreateWebhookRequiresAnAbsoluteURL(t *testing.T) {
+	s := NewWebhookService(nil, nil, utils.RealClock)
+	for _, url := range []string{"", "example.com/hook", "ftp://example.com/hook", "https://"} {
+		_, err := s.CreateWebhook(1, &models.WebhookRequest{URL: url})
+		wantAppError(t, err, 400)
+	}
+}
+
+func TestWebhookPostIsSigned(t *testing.T) {
+	var signature, body string
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body, signature = string(data), r.Header.Get(webhookSignatureHeader)
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer endpoint.Close()
+
+	s := NewWebhookService(nil, endpoint.Client(), utils.RealClock).(*webhookService)
+	status, err := s.post(&models.Webhook{URL: endpoint.URL, Secret: "hook-secret"}, `{"event":"deposit"}`)
+	if status != http.StatusTeapot || err == nil {
+		t.Errorf("post = %d, %v; want 418 and an error", status, err)
+	}
+	if body != `{"event":"deposit"}` || signature != utils.CreateHMAC(body, []byte("hook-secret")) {
+		t.Errorf("endpoint got %q signed %q", body, signature)
+	}
+}
+
+func TestFailedDeliveriesAreRecordedAndRedelivered(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "integrator")
+	var hits, healthy int32
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if atomic.LoadInt32(&healthy) == 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer endpoint.Close()
+
+	s := NewWebhookService(db, endpoint.Client(), utils.RealClock)
+	s.(*webhookService).retryDelay = time.Millisecond
+	webhook, err := s.CreateWebhook(uint(user.ID), &models.WebhookRequest{URL: endpoint.URL})
+	if err != nil {
+		t.Fatalf("CreateWebhook: %v", err)
+	}
+
+	s.Dispatch(uint(user.ID), "deposit", map[string]int{"amount": 10})
+	deadline := time.Now().Add(5 * time.Second)
+	var deliveries []models.WebhookDelivery
+	for {
+		deliveries, err = s.GetDeliveries(uint(user.ID), webhook.ID, 0)
+		if err != nil {
+			t.Fatalf("GetDeliveries: %v", err)
+		}
+		if len(deliveries) == 1 && deliveries[0].Status == "failed" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("deliveries = %+v, want one failed", deliveries)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	failed := deliveries[0]
+	if failed.Attempts != WebhookMaxAttempts || failed.StatusCode != 500 || failed.LastError == "" || failed.LastAttemptAt == nil {
+		t.Errorf("failed delivery = %+v", failed)
+	}
+	if got := atomic.LoadInt32(&hits); got != WebhookMaxAttempts {
+		t.Errorf("endpoint hit %d times, want %d", got, WebhookMaxAttempts)
+	}
+
+	atomic.StoreInt32(&healthy, 1)
+	redelivered, err := s.Redeliver(uint(user.ID), webhook.ID, failed.ID)
+	if err != nil {
+		t.Fatalf("Redeliver: %v", err)
+	}
+	if redelivered.Status != "delivered" || redelivered.StatusCode != 200 || redelivered.Attempts != WebhookMaxAttempts+1 {
+		t.Errorf("redelivered = %+v", redelivered)
+	}
+	if got := atomic.LoadInt32(&hits); got != WebhookMaxAttempts+1 {
+		t.Errorf("endpoint hit %d times, want one more POST", got)
+	}
+
+	stranger := seedUser(t, db, "stranger")
+	_, err = s.Redeliver(uint(stranger.ID), webhook.ID, failed.ID)
+	wantAppError(t, err, 404)
+	_, err = s.GetDeliveries(uint(stranger.ID), webhook.ID, 0)
+	wantAppError(t, err, 404)
+	_, err = s.Redeliver(uint(user.ID), webhook.ID, failed.ID+1)
+	wantAppError(t, err, 404)
+
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown: %v", err)
+	}
+}
+
+func TestShutdownWaitsForInFlightDeliveries(t *testing.T) {
+	s := NewWebhookService(nil, nil, utils.RealClock).(*webhookService)
+	release := make(chan struct{})
+	s.inFlight.Add(1)
+	go func() {
+		defer s.inFlight.Done()
+		<-release
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Shutdown with a delivery in flight = %v, want the deadline", err)
+	}
+	select {
+	case <-s.stop:
+	default:
+		t.Error("Shutdown did not cut retry backoffs short")
+	}
+
+	close(release)
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Errorf("second Shutdown = %v", err)
+	}
+}
+
+func TestShutdownFlushesDispatchedEvents(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "integrator")
+	release := make(chan struct{})
+	var hits int32
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		atomic.AddInt32(&hits, 1)
+	}))
+	defer endpoint.Close()
+
+	s := NewWebhookService(db, endpoint.Client(), utils.RealClock)
+	webhook, err := s.CreateWebhook(uint(user.ID), &models.WebhookRequest{URL: endpoint.URL})
+	if err != nil {
+		t.Fatalf("CreateWebhook: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		s.Dispatch(uint(user.ID), "deposit", map[string]int{"amount": i})
+	}
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- s.Shutdown(context.Background()) }()
+	select {
+	case err := <-shutdown:
+		t.Fatalf("Shutdown returned %v before the deliveries finished", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	if err := <-shutdown; err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	// Everything dispatched before shutdown was delivered by the time it returned.
+	if got := atomic.LoadInt32(&hits); got != 3 {
+		t.Errorf("endpoint hit %d times, want 3", got)
+	}
+	deliveries, err := s.GetDeliveries(uint(user.ID), webhook.ID, 0)
+	if err != nil {
+		t.Fatalf("GetDeliveries: %v", err)
+	}
+	for _, delivery := range deliveries {
+		if delivery.Status != "delivered" {
+			t.Errorf("delivery %d is %s, want delivered", delivery.ID, delivery.Status)
+		}
+	}
+
+	// Later events are recorded as pending but not sent.
+	s.Dispatch(uint(user.ID), "withdraw", map[string]int{"amount": 1})
+	deliveries, err = s.GetDeliveries(uint(user.ID), webhook.ID, 0)
+	if err != nil {
+		t.Fatalf("GetDeliveries: %v", err)
+	}
+	if len(deliveries) != 4 || deliveries[0].Status != "pending" {
+		t.Errorf("deliveries after shutdown = %+v, want the new one pending", deliveries)
+	}
+	if got := atomic.LoadInt32(&hits); got != 3 {
+		t.Errorf("endpoint hit %d times after shutdown, want no more", got)
+	}
+}
@@ -0,0 +1,170 @@
+// Path: pkg/accountnumber/accountnumber.go
+package accountnumber
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidNumber is returned for account numbers that match no known scheme.
+var ErrInvalidNumber = errors.New("invalid account number")
+
+// Scheme generates and validates account numbers of one format.
+type Scheme interface {
+	// Name identifies the scheme in configuration ("internal", "iban").
+	Name() string
+	// Generate derives the account number from the account ID.
+	Generate(id uint) string
+	// Validate checks the format and check digits of a normalized number.
+	Validate(number string) error
+}
+
+// Normalize strips separators and upper-cases a number as typed by a user.
+func Normalize(number string) string {
+	return strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(number)))
+}
+
+// ValidateAny normalizes a number and accepts it if any of the schemes validates it.
+func ValidateAny(number string, schemes ...Scheme) (string, error) {
+	number = Normalize(number)
+	for _, scheme := range schemes {
+		if scheme.Validate(number) == nil {
+			return number, nil
+		}
+	}
+	return "", ErrInvalidNumber
+}
+
+// Internal is the short internal format: the zero-padded account ID followed by a Luhn check digit.
+type Internal struct{}
+
+func (Internal) Name() string { return "internal" }
+
+func (Internal) Generate(id uint) string {
+	digits := fmt.Sprintf("%08d", id)
+	return digits + string(rune('0'+luhnCheckDigit(digits)))
+}
+
+func (Internal) Validate(number string) error {
+	if len(number) < 9 || !isDigits(number) {
+		return ErrInvalidNumber
+	}
+	if luhnCheckDigit(number[:len(number)-1]) != int(number[len(number)-1]-'0') {
+		return ErrInvalidNumber
+	}
+	return nil
+}
+
+// IBAN is the ISO 13616 format: country code, two mod-97 check digits, then the
+// bank code and the zero-padded account ID.
+type IBAN struct {
+	Country  string // ISO 3166 alpha-2, e.g. "DE"
+	BankCode string // Alphanumeric bank identifier, e.g. "BNKX"
+}
+
+func (IBAN) Name() string { return "iban" }
+
+func (s IBAN) Generate(id uint) string {
+	country := strings.ToUpper(s.Country)
+	bban := strings.ToUpper(s.BankCode) + fmt.Sprintf("%010d", id)
+	check := 98 - mod97(bban+country+"00")
+	return fmt.Sprintf("%s%02d%s", country, check, bban)
+}
+
+// Validate accepts any well-formed IBAN, not only those of the configured country:
+// the account lookup decides whether it belongs to this bank.
+func (IBAN) Validate(number string) error {
+	if len(number) < 15 || len(number) > 34 {
+		return ErrInvalidNumber
+	}
+	if !isLetters(number[:2]) || !isDigits(number[2:4]) || !isAlnum(number[4:]) {
+		return ErrInvalidNumber
+	}
+	if mod97(number[4:]+number[:4]) != 1 {
+		return ErrInvalidNumber
+	}
+	return nil
+}
+
+// SchemeByName returns the configured scheme. IBAN settings are ignored for other schemes.
+func SchemeByName(name, ibanCountry, ibanBankCode string) (Scheme, error) {
+	switch name {
+	case "", "internal":
+		return Internal{}, nil
+	case "iban":
+		if len(ibanCountry) != 2 || !isLetters(strings.ToUpper(ibanCountry)) {
+			return nil, fmt.Errorf("invalid IBAN country code %q", ibanCountry)
+		}
+		if ibanBankCode == "" || !isAlnum(strings.ToUpper(ibanBankCode)) {
+			return nil, fmt.Errorf("invalid IBAN bank code %q", ibanBankCode)
+		}
+		return IBAN{Country: ibanCountry, BankCode: ibanBankCode}, nil
+	default:
+		return nil, fmt.Errorf("unknown account number scheme %q", name)
+	}
+}
+
+// All lists every supported scheme, used to parse numbers in whichever format they arrive.
+func All(configured Scheme) []Scheme {
+	return []Scheme{configured, Internal{}, IBAN{}}
+}
+
+// mod97 computes the remainder of the number obtained by replacing letters with 10..35.
+func mod97(s string) int {
+	remainder := 0
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			remainder = (remainder*10 + int(r-'0')) % 97
+		case r >= 'A' && r <= 'Z':
+			remainder = (remainder*100 + int(r-'A'+10)) % 97
+		}
+	}
+	return remainder
+}
+
+// luhnCheckDigit computes the Luhn check digit for a string of digits.
+func luhnCheckDigit(digits string) int {
+	sum := 0
+	double := true
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return (10 - sum%10) % 10
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func isLetters(s string) bool {
+	for _, r := range s {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func isAlnum(s string) bool {
+	for _, r := range s {
+		if (r < '0' || r > '9') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,78 @@
+// Path: pkg/accountnumber/accountnumber_test.go
+package accountnumber
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestInternalCheckDigit(t *testing.T) {
+	s := Internal{}
+	if got := s.Generate(7992739871); got != "79927398713" {
+		t.Errorf("Generate = %q, want the Luhn check digit 3", got)
+	}
+	for _, id := range []uint{1, 42, 12345678} {
+		number := s.Generate(id)
+		if err := s.Validate(number); err != nil {
+			t.Errorf("Validate(Generate(%d) = %q): %v", id, number, err)
+		}
+	}
+	for _, bad := range []string{"", "00000001", "000000011", "0000000A8", "79927398710"} {
+		if err := s.Validate(bad); !errors.Is(err, ErrInvalidNumber) {
+			t.Errorf("Validate(%q) = %v, want ErrInvalidNumber", bad, err)
+		}
+	}
+}
+
+func TestIBANCheckDigits(t *testing.T) {
+	s := IBAN{Country: "de", BankCode: "bnkx"}
+	number := s.Generate(42)
+	if number[:2] != "DE" || number[4:] != "BNKX0000000042" {
+		t.Errorf("Generate = %q", number)
+	}
+	for _, valid := range []string{number, "GB82WEST12345698765432", "DE89370400440532013000"} {
+		if err := s.Validate(valid); err != nil {
+			t.Errorf("Validate(%q): %v", valid, err)
+		}
+	}
+	for _, bad := range []string{
+		"GB83WEST12345698765432", // wrong check digits
+		"GB82WEST12345698765433", // mistyped digit
+		"1282WEST12345698765432", // no country code
+		"GB82WEST1234",           // too short
+		"GB82WEST-2345698765432", // not normalized
+	} {
+		if err := s.Validate(bad); !errors.Is(err, ErrInvalidNumber) {
+			t.Errorf("Validate(%q) = %v, want ErrInvalidNumber", bad, err)
+		}
+	}
+}
+
+func TestValidateAnyParsesEitherFormat(t *testing.T) {
+	schemes := All(Internal{})
+	for input, want := range map[string]string{
+		"0000-0001-8":                  "000000018",
+		" gb82 west 1234 5698 7654 32": "GB82WEST12345698765432",
+	} {
+		if got, err := ValidateAny(input, schemes...); err != nil || got != want {
+			t.Errorf("ValidateAny(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	if _, err := ValidateAny("GB00WEST12345698765432", schemes...); !errors.Is(err, ErrInvalidNumber) {
+		t.Errorf("ValidateAny with bad check digits = %v, want ErrInvalidNumber", err)
+	}
+}
+
+func TestSchemeByName(t *testing.T) {
+	if s, err := SchemeByName("", "", ""); err != nil || s.Name() != "internal" {
+		t.Errorf("default scheme = %v, %v", s, err)
+	}
+	if s, err := SchemeByName("iban", "DE", "BNKX"); err != nil || s.Name() != "iban" {
+		t.Errorf("iban scheme = %v, %v", s, err)
+	}
+	for _, args := range [][3]string{{"iban", "D", "BNKX"}, {"iban", "DE", ""}, {"iban", "DE", "BN-X"}, {"swift", "", ""}} {
+		if _, err := SchemeByName(args[0], args[1], args[2]); err == nil {
+			t.Errorf("SchemeByName%q accepted", args)
+		}
+	}
+}
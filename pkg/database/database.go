@@ -2,60 +2,395 @@
 package database
 
 import (
+	"bank-api/pkg/utils"
 	"fmt"
+	"log"
+	"strconv"
+	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 // User represents a user in the database.
 type User struct {
-	ID        uint   `gorm:"primaryKey"`
-	Username  string `gorm:"unique;not null"`
-	Password  string `gorm:"not null"`
-	CreatedAt string `gorm:"not null"`
+	ID       uint   `gorm:"primaryKey"`
+	Username string `gorm:"unique;not null"`
+	Password string `gorm:"not null"`
+	Role     string `gorm:"not null;default:user"`
+	Tier     string `gorm:"not null;default:standard"`
+	// TransfersEnabled is cleared by admins to restrict a user to read-only.
+	TransfersEnabled bool `gorm:"not null;default:true"`
+	// RoundUpEnabled moves the change of each withdrawal and transfer to the RoundUpAccount.
+	RoundUpEnabled   bool     `gorm:"not null;default:false"`
+	RoundUpAccountID *uint    `gorm:"index"`
+	RoundUpAccount   *Account `gorm:"constraint:OnDelete:SET NULL;"`
+	// TOTPSecret is empty until the user sets up 2FA.
+	TOTPSecret   string `gorm:"not null;default:''"`
+	TOTPLastStep int64  `gorm:"not null;default:0"`
+	// StatementLastPeriod is the month ("2006-01") of the last statement sent.
+	StatementsEnabled   bool   `gorm:"not null;default:false"`
+	StatementLastPeriod string `gorm:"not null;default:''"`
+	// DailySpendingLimit is the user's own daily limit; NULL leaves only the system one.
+	DailySpendingLimit *float64
+	CreatedAt          string `gorm:"not null"`
+	DeletedAt          *time.Time
 }
 
 // Account represents an account in the database.
 type Account struct {
-	ID          uint    `gorm:"primaryKey"`
-	UserID      uint    `gorm:"not null"`
-	Balance     float64 `gorm:"not null;default:0"`
-	BalanceHash string  `gorm:"not null"`
-	CreatedAt   string  `gorm:"not null"`
-	User        User    `gorm:"constraint:OnDelete:CASCADE;"`
+	ID           uint      `gorm:"primaryKey"`
+	UserID       *uint     // NULL once the owner was purged; the account stays for the ledger.
+	Number       *string   `gorm:"uniqueIndex"`
+	Balance      float64   `gorm:"not null;default:0"`
+	Currency     string    `gorm:"not null;default:USD"`
+	Type         string    `gorm:"not null;default:checking"`
+	BalanceHash  string    `gorm:"not null"`
+	Nickname     string    `gorm:"not null;default:''"`
+	Sandbox      bool      `gorm:"not null;default:false"`
+	Frozen       bool      `gorm:"not null;default:false"`
+	FrozenReason string    `gorm:"not null;default:''"`
+	CreatedAt    time.Time `gorm:"not null"`
+	User         *User     `gorm:"constraint:OnDelete:SET NULL;"`
+
+	// InterestRemainder is the fraction of a minor unit of interest carried to the next accrual.
+	InterestRemainder float64 `gorm:"not null;default:0"`
+	InterestAccruedAt *time.Time
+	Dormant           bool `gorm:"not null;default:false"`
+	LastActivityAt    *time.Time
+	ClosedAt          *time.Time
+	TransferWhitelist bool `gorm:"not null;default:false"`
+	// SubBalances is a JSON object of the named parts of Balance; NULL when there are none.
+	SubBalances *string `gorm:"type:jsonb"`
+}
+
+// SuspiciousActivity represents an event counted by the account freeze rules in the database.
+type SuspiciousActivity struct {
+	ID        uint      `gorm:"primaryKey"`
+	AccountID uint      `gorm:"not null;index:idx_suspicious_activity_account_rule,priority:1"`
+	Rule      string    `gorm:"not null;index:idx_suspicious_activity_account_rule,priority:2"`
+	CreatedAt time.Time `gorm:"not null"`
+	Account   Account   `gorm:"constraint:OnDelete:CASCADE;"`
 }
 
 // Transaction represents a transaction in the database.
 type Transaction struct {
-	ID            string `gorm:"primaryKey"`
-	FromAccountID *uint
-	ToAccountID   *uint
-	Amount        float64  `gorm:"not null"`
-	Type          string   `gorm:"not null"`
-	Status        string   `gorm:"not null"`
-	CreatedAt     string   `gorm:"not null"`
-	FromAccount   *Account `gorm:"constraint:OnDelete:SET NULL;"`
-	ToAccount     *Account `gorm:"constraint:OnDelete:SET NULL;"`
-}
-
-// InitDB initializes the database and creates tables if they don't exist.
-func InitDB(dsn string) (*gorm.DB, error) {
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	ID             string `gorm:"primaryKey"`
+	FromAccountID  *uint
+	ToAccountID    *uint
+	InitiatorID    *uint
+	ApproverID     *uint
+	Amount         float64   `gorm:"not null"`
+	Type           string    `gorm:"not null"`
+	Status         string    `gorm:"not null"`
+	Description    string    `gorm:"not null;default:''"`
+	Source         string    `gorm:"not null;default:''"`
+	Fee            float64   `gorm:"not null;default:0"`
+	FeeWaived      bool      `gorm:"not null;default:false"`
+	FeeWaiver      string    `gorm:"not null;default:''"`
+	FailureReason  string    `gorm:"not null;default:''"`
+	RefundedAmount float64   `gorm:"not null;default:0"`
+	RefundOf       *string   `gorm:"index"`
+	CreatedAt      time.Time `gorm:"not null;index"`
+	CompletedAt    *time.Time
+	FromAccount    *Account `gorm:"constraint:OnDelete:SET NULL;"`
+	ToAccount      *Account `gorm:"constraint:OnDelete:SET NULL;"`
+
+	// Reference is the human-friendly number support quotes, e.g. TXN-2024-000123. The default
+	// and the sequence behind it are created by migration 20.
+	Reference string `gorm:"not null;uniqueIndex:idx_transactions_reference;default:next_transaction_reference()"`
+
+	// SubBalance is the sub-balance a deposit was credited to; empty for the principal.
+	SubBalance string `gorm:"not null;default:''"`
+}
+
+// TransactionTag represents a user's tag on a transaction in the database.
+type TransactionTag struct {
+	TransactionID string      `gorm:"primaryKey"`
+	UserID        uint        `gorm:"primaryKey"`
+	Tag           string      `gorm:"primaryKey;index"`
+	Transaction   Transaction `gorm:"constraint:OnDelete:CASCADE;"`
+	User          User        `gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+// Payee represents a saved transfer recipient in the database.
+type Payee struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_payee_user_account"`
+	AccountID uint      `gorm:"not null;uniqueIndex:idx_payee_user_account"`
+	Label     string    `gorm:"not null"`
+	CreatedAt time.Time `gorm:"not null"`
+	User      User      `gorm:"constraint:OnDelete:CASCADE;"`
+	Account   Account   `gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+// TransferWhitelistEntry represents a destination an account may transfer to in the database.
+type TransferWhitelistEntry struct {
+	AccountID            uint      `gorm:"primaryKey"`
+	DestinationAccountID uint      `gorm:"primaryKey"`
+	CreatedAt            time.Time `gorm:"not null"`
+	Account              Account   `gorm:"constraint:OnDelete:CASCADE;"`
+	DestinationAccount   Account   `gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+// AccountCosigner represents a user designated to approve the transfers from an account in the database.
+type AccountCosigner struct {
+	AccountID uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"primaryKey;index"`
+	CreatedAt time.Time `gorm:"not null"`
+	Account   Account   `gorm:"constraint:OnDelete:CASCADE;"`
+	User      User      `gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+// AuditLog represents an entry of the audit trail in the database.
+type AuditLog struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    *uint     `gorm:"index"`
+	Action    string    `gorm:"not null;index"`
+	Severity  string    `gorm:"not null"`
+	Details   string    `gorm:"not null"`
+	CreatedAt time.Time `gorm:"not null;index"`
+}
+
+// LedgerEntry represents one leg of a completed transfer in the database.
+type LedgerEntry struct {
+	ID          uint        `gorm:"primaryKey"`
+	GroupID     string      `gorm:"not null;index"`
+	AccountID   uint        `gorm:"not null;index:idx_ledger_entry_account_created,priority:1"`
+	Direction   string      `gorm:"not null"`
+	Amount      float64     `gorm:"not null"`
+	CreatedAt   time.Time   `gorm:"not null;index:idx_ledger_entry_account_created,priority:2"`
+	Transaction Transaction `gorm:"foreignKey:GroupID;constraint:OnDelete:CASCADE;"`
+	Account     Account     `gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+// BatchTransfer represents a batch of transfers in the database.
+type BatchTransfer struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	CreatedAt time.Time `gorm:"not null"`
+	User      User      `gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+// BatchItem represents the outcome of one transfer of a batch in the database.
+type BatchItem struct {
+	ID            uint          `gorm:"primaryKey"`
+	BatchID       uint          `gorm:"not null;index"`
+	Position      int           `gorm:"not null"`
+	FromID        int           `gorm:"not null"`
+	ToID          int           `gorm:"not null"`
+	Amount        float64       `gorm:"not null"`
+	Status        string        `gorm:"not null"`
+	Error         string        `gorm:"not null;default:''"`
+	TransactionID *string       `gorm:"index"`
+	Batch         BatchTransfer `gorm:"constraint:OnDelete:CASCADE;"`
+	Transaction   *Transaction  `gorm:"constraint:OnDelete:SET NULL;"`
+}
+
+// Webhook represents an endpoint receiving a user's transaction events in the database.
+type Webhook struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	URL       string    `gorm:"not null"`
+	Secret    string    `gorm:"not null"`
+	CreatedAt time.Time `gorm:"not null"`
+	User      User      `gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+// WebhookDelivery represents one event sent, or being sent, to a webhook in the database.
+type WebhookDelivery struct {
+	ID            uint   `gorm:"primaryKey"`
+	WebhookID     uint   `gorm:"not null;index"`
+	Event         string `gorm:"not null"`
+	Payload       string `gorm:"not null"`
+	Status        string `gorm:"not null"`
+	StatusCode    int    `gorm:"not null;default:0"`
+	Attempts      int    `gorm:"not null;default:0"`
+	LastError     string `gorm:"not null;default:''"`
+	LastAttemptAt *time.Time
+	CreatedAt     time.Time `gorm:"not null"`
+	Webhook       Webhook   `gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+// Beneficiary represents an account named to receive a share of another account in the database.
+type Beneficiary struct {
+	ID                   uint      `gorm:"primaryKey"`
+	AccountID            uint      `gorm:"not null;uniqueIndex:idx_beneficiary_account,priority:1"`
+	BeneficiaryAccountID uint      `gorm:"not null;uniqueIndex:idx_beneficiary_account,priority:2"`
+	SharePercent         float64   `gorm:"not null"`
+	CreatedAt            time.Time `gorm:"not null"`
+	Account              Account   `gorm:"constraint:OnDelete:CASCADE;"`
+	BeneficiaryAccount   Account   `gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+// Hold represents funds of an account reserved for a later capture in the database.
+type Hold struct {
+	ID             uint      `gorm:"primaryKey"`
+	AccountID      uint      `gorm:"not null;index:idx_holds_account_status,priority:1"`
+	Amount         float64   `gorm:"not null"`
+	CapturedAmount float64   `gorm:"not null;default:0"`
+	Status         string    `gorm:"not null;index:idx_holds_account_status,priority:2"`
+	Description    string    `gorm:"not null;default:''"`
+	TransactionID  *string   `gorm:"index"`
+	CreatedAt      time.Time `gorm:"not null"`
+	ResolvedAt     *time.Time
+	Account        Account      `gorm:"constraint:OnDelete:CASCADE;"`
+	Transaction    *Transaction `gorm:"constraint:OnDelete:SET NULL;"`
+}
+
+// MoneyRequest represents a request of one user to be paid by another in the database.
+type MoneyRequest struct {
+	ID            uint      `gorm:"primaryKey"`
+	RequesterID   uint      `gorm:"not null;index"`
+	PayerID       uint      `gorm:"not null;index"`
+	AccountID     uint      `gorm:"not null"`
+	Amount        float64   `gorm:"not null"`
+	Status        string    `gorm:"not null"`
+	Description   string    `gorm:"not null;default:''"`
+	TransactionID *string   `gorm:"index"`
+	CreatedAt     time.Time `gorm:"not null"`
+	ResolvedAt    *time.Time
+	Requester     User         `gorm:"constraint:OnDelete:CASCADE;"`
+	Payer         User         `gorm:"constraint:OnDelete:CASCADE;"`
+	Account       Account      `gorm:"constraint:OnDelete:CASCADE;"`
+	Transaction   *Transaction `gorm:"constraint:OnDelete:SET NULL;"`
+}
+
+// Event represents an entry of the append-only event log in the database.
+type Event struct {
+	Seq              uint   `gorm:"primaryKey"`
+	Type             string `gorm:"not null"`
+	AccountID        uint   `gorm:"not null"`
+	CounterAccountID *uint
+	TransactionID    *string
+	Amount           float64   `gorm:"not null;default:0"`
+	Fee              float64   `gorm:"not null;default:0"`
+	Currency         string    `gorm:"not null"`
+	CreatedAt        time.Time `gorm:"not null"`
+}
+
+// ConfirmationCode represents a one-time transfer confirmation code in the database.
+type ConfirmationCode struct {
+	ID          uint      `gorm:"primaryKey"`
+	UserID      uint      `gorm:"not null;index:idx_confirmation_code_user_fingerprint,priority:1"`
+	Fingerprint string    `gorm:"not null;index:idx_confirmation_code_user_fingerprint,priority:2"`
+	CodeHash    string    `gorm:"not null"`
+	Attempts    int       `gorm:"not null;default:0"`
+	ExpiresAt   time.Time `gorm:"not null"`
+	UsedAt      *time.Time
+	CreatedAt   time.Time `gorm:"not null"`
+	User        User      `gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+// LoginAttempt represents a successful or failed login in the database.
+type LoginAttempt struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index:idx_login_attempt_user_created,priority:1"`
+	IP        string    `gorm:"not null"`
+	UserAgent string    `gorm:"not null"`
+	Outcome   string    `gorm:"not null"`
+	CreatedAt time.Time `gorm:"not null;index:idx_login_attempt_user_created,priority:2"`
+	User      User      `gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+// Config holds the connection and schema settings of the database.
+type Config struct {
+	// AutoMigrate additionally syncs the schema with the models via gorm AutoMigrate, for development
+	// only: it can't express data backfills or destructive changes.
+	AutoMigrate bool
+	// StatementTimeout makes Postgres cancel statements running longer than this. Zero disables it.
+	StatementTimeout time.Duration
+	// SlowQueryThreshold is the duration above which queries are logged, parameterized. Defaults to 200ms.
+	SlowQueryThreshold time.Duration
+	// EncryptionKey enables encryption at rest of the columns tagged with EncryptedSerializer
+	// (32 bytes, AES-256-GCM). Values stored before it was set are encrypted on startup.
+	EncryptionKey []byte
+}
+
+// InitDB opens the database and applies pending migrations.
+func InitDB(dsn string, cfg Config) (*gorm.DB, error) {
+	if cfg.SlowQueryThreshold <= 0 {
+		cfg.SlowQueryThreshold = 200 * time.Millisecond
+	}
+	cipher, err := setEncryptionKey(cfg.EncryptionKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %w", err)
+		return nil, err
 	}
 
-	if err := createTables(db); err != nil {
+	connConfig, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse database URL: %w", err)
+	}
+
+	// Migrations and the encryption backfill may run far longer than any request, so they get
+	// their own connection without the statement timeout.
+	schemaDB, err := open(connConfig, cfg.SlowQueryThreshold)
+	if err != nil {
+		return nil, err
+	}
+	err = prepareSchema(schemaDB, cfg.AutoMigrate, cipher)
+	if sqlDB, dbErr := schemaDB.DB(); dbErr == nil {
+		sqlDB.Close()
+	}
+	if err != nil {
 		return nil, err
 	}
 
+	if cfg.StatementTimeout > 0 {
+		connConfig = connConfig.Copy()
+		connConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10)
+	}
+	return open(connConfig, cfg.SlowQueryThreshold)
+}
+
+func open(connConfig *pgx.ConnConfig, slowQueryThreshold time.Duration) (*gorm.DB, error) {
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: stdlib.OpenDB(*connConfig)}), &gorm.Config{
+		Logger: queryLogger(log.Default(), slowQueryThreshold),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
 	return db, nil
 }
 
-// createTables creates the necessary tables in the database.
+// prepareSchema applies pending migrations, syncs the tables with the models when autoMigrate is
+// set and encrypts the values of columns stored before encryption was enabled.
+func prepareSchema(db *gorm.DB, autoMigrate bool, cipher *utils.Cipher) error {
+	if err := Migrate(db); err != nil {
+		return err
+	}
+
+	if autoMigrate {
+		if err := createTables(db); err != nil {
+			return err
+		}
+	}
+
+	if cipher != nil {
+		if err := encryptExisting(db, cipher); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// queryLogger logs failed queries and those slower than threshold, with their parameters left out.
+func queryLogger(out *log.Logger, threshold time.Duration) logger.Interface {
+	return logger.New(out, logger.Config{
+		SlowThreshold:             threshold,
+		LogLevel:                  logger.Warn,
+		IgnoreRecordNotFoundError: true,
+		ParameterizedQueries:      true,
+	})
+}
+
+// createTables syncs the tables with the models using gorm AutoMigrate.
 func createTables(db *gorm.DB) error {
-	err := db.AutoMigrate(&User{}, &Account{}, &Transaction{})
+	err := db.AutoMigrate(&User{}, &Account{}, &Transaction{}, &AuditLog{}, &Payee{}, &TransactionTag{}, &LoginAttempt{}, &LedgerEntry{}, &ConfirmationCode{}, &BatchTransfer{}, &BatchItem{}, &Webhook{}, &WebhookDelivery{}, &SuspiciousActivity{}, &Beneficiary{}, &Hold{}, &MoneyRequest{}, &Event{}, &TransferWhitelistEntry{}, &AccountCosigner{})
 	if err != nil {
 		return fmt.Errorf("failed to auto-migrate tables: %w", err)
 	}
@@ -0,0 +1,97 @@
+// Path: pkg/database/database_test.go
+package database
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestQueryLoggerLogsSlowQueries(t *testing.T) {
+	var out bytes.Buffer
+	l := queryLogger(log.New(&out, "", 0), 100*time.Millisecond)
+	ctx := context.Background()
+	query := func() (string, int64) { return "UPDATE accounts SET balance = $1 WHERE id = $2", 1 }
+
+	l.Trace(ctx, time.Now().Add(-10*time.Millisecond), query, nil)
+	l.Trace(ctx, time.Now(), query, gorm.ErrRecordNotFound)
+	if out.Len() > 0 {
+		t.Errorf("fast query logged: %s", out.String())
+	}
+
+	l.Trace(ctx, time.Now().Add(-250*time.Millisecond), query, nil)
+	logged := out.String()
+	if !strings.Contains(logged, "SLOW SQL >= 100ms") || !strings.Contains(logged, "UPDATE accounts SET balance = $1 WHERE id = $2") {
+		t.Errorf("slow query log = %q", logged)
+	}
+
+	out.Reset()
+	l.Trace(ctx, time.Now(), query, errors.New("deadlock detected"))
+	if !strings.Contains(out.String(), "deadlock detected") {
+		t.Errorf("failed query log = %q", out.String())
+	}
+}
+
+func TestQueryLoggerLeavesParametersOut(t *testing.T) {
+	l := queryLogger(log.New(&bytes.Buffer{}, "", 0), time.Second)
+	filter, ok := l.(gorm.ParamsFilter)
+	if !ok {
+		t.Fatal("query logger does not filter parameters")
+	}
+	if sql, params := filter.ParamsFilter(context.Background(), "SELECT * FROM users WHERE password = $1", "secret"); sql == "" || params != nil {
+		t.Errorf("ParamsFilter = %q, %v; want the parameters dropped", sql, params)
+	}
+}
+
+func TestInitDBMigratesWithoutStatementTimeout(t *testing.T) {
+	schema := emptySchema(t)
+	var name string
+	if err := schema.Raw("SELECT current_schema()").Scan(&name).Error; err != nil {
+		t.Fatalf("current schema: %v", err)
+	}
+
+	// A migration slower than the statement timeout still runs to completion.
+	released := migrations
+	t.Cleanup(func() { migrations = released })
+	migrations = append(released[:len(released):len(released)], Migration{
+		Version: len(released) + 1,
+		Name:    "slow",
+		Up:      execAll(`SELECT pg_sleep(0.2)`),
+	})
+
+	db, err := InitDB(schemaDSN(os.Getenv("TEST_DATABASE_URL"), name), Config{StatementTimeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("InitDB: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	var applied int
+	if err := db.Raw("SELECT count(*) FROM schema_migrations WHERE version = ?", len(migrations)).Scan(&applied).Error; err != nil || applied != 1 {
+		t.Errorf("slow migration applied %d times (%v), want once", applied, err)
+	}
+	if err := db.Exec("SELECT pg_sleep(0.2)").Error; err == nil {
+		t.Error("a slow statement on the returned pool wasn't cancelled by the statement timeout")
+	}
+}
+
+// schemaDSN adds a search path to dsn, in either of the formats pgx accepts.
+func schemaDSN(dsn, schema string) string {
+	if !strings.HasPrefix(dsn, "postgres://") && !strings.HasPrefix(dsn, "postgresql://") {
+		return dsn + " search_path=" + schema
+	}
+	if strings.Contains(dsn, "?") {
+		return dsn + "&search_path=" + schema
+	}
+	return dsn + "?search_path=" + schema
+}
@@ -0,0 +1,128 @@
+// Path: pkg/database/encryption_test.go
+package database
+
+import (
+	"bank-api/pkg/utils"
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm/schema"
+)
+
+// secret is a model with an encrypted column.
+type secret struct {
+	ID    uint
+	Value string `gorm:"serializer:encrypted"`
+}
+
+// useEncryptionKey enables column encryption with key until the test ends.
+func useEncryptionKey(t *testing.T, key []byte) *utils.Cipher {
+	t.Helper()
+	c, err := setEncryptionKey(key)
+	if err != nil {
+		t.Fatalf("setEncryptionKey: %v", err)
+	}
+	t.Cleanup(func() { setEncryptionKey(nil) })
+	return c
+}
+
+func TestEncryptedSerializer(t *testing.T) {
+	sch, err := schema.Parse(&secret{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("parse schema: %v", err)
+	}
+	field := sch.LookUpField("Value")
+	ctx := context.Background()
+	var s encryptedSerializer
+	read := func(stored interface{}) (string, error) {
+		var row secret
+		err := s.Scan(ctx, field, reflect.ValueOf(&row).Elem(), stored)
+		return row.Value, err
+	}
+
+	// Without a key values are stored and read as they are.
+	setEncryptionKey(nil)
+	if stored, err := s.Value(ctx, field, reflect.Value{}, "totp"); stored != "totp" || err != nil {
+		t.Errorf("Value without a key = %v, %v", stored, err)
+	}
+
+	useEncryptionKey(t, bytes.Repeat([]byte{1}, utils.EncryptionKeySize))
+	stored, err := s.Value(ctx, field, reflect.Value{}, "totp")
+	if err != nil || !utils.IsEncrypted(stored.(string)) {
+		t.Fatalf("Value = %v, %v; want ciphertext", stored, err)
+	}
+	if empty, _ := s.Value(ctx, field, reflect.Value{}, ""); empty != "" {
+		t.Errorf("empty value stored as %v", empty)
+	}
+	if got, err := read(stored); err != nil || got != "totp" {
+		t.Errorf("Scan = %q, %v; want totp", got, err)
+	}
+	if got, err := read([]byte("legacy")); err != nil || got != "legacy" {
+		t.Errorf("Scan of a value stored before encryption = %q, %v", got, err)
+	}
+	if got, err := read(nil); err != nil || got != "" {
+		t.Errorf("Scan of NULL = %q, %v", got, err)
+	}
+
+	// A wrong key fails the read with an error, not garbage.
+	useEncryptionKey(t, bytes.Repeat([]byte{2}, utils.EncryptionKeySize))
+	if _, err := read(stored); !errors.Is(err, utils.ErrDecrypt) {
+		t.Errorf("Scan with a wrong key = %v, want ErrDecrypt", err)
+	}
+	setEncryptionKey(nil)
+	if _, err := read(stored); err == nil {
+		t.Error("Scan of ciphertext without a key succeeded")
+	}
+}
+
+func TestEncryptionKeySize(t *testing.T) {
+	if _, err := setEncryptionKey([]byte("short")); err == nil {
+		t.Error("short key accepted")
+	}
+}
+
+func TestEncryptedColumnsAtRest(t *testing.T) {
+	db := emptySchema(t)
+	if err := db.Exec("CREATE TABLE secrets (id serial PRIMARY KEY, value text NOT NULL DEFAULT '')").Error; err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	if err := db.Exec("INSERT INTO secrets (value) VALUES ('stored-before-the-key'), ('')").Error; err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	columns := encryptedColumns
+	encryptedColumns = []struct{ table, column string }{{"secrets", "value"}}
+	t.Cleanup(func() { encryptedColumns = columns })
+
+	c := useEncryptionKey(t, bytes.Repeat([]byte{1}, utils.EncryptionKeySize))
+	if err := encryptExisting(db, c); err != nil {
+		t.Fatalf("encryptExisting: %v", err)
+	}
+	if err := db.Create(&secret{Value: "written-with-the-key"}).Error; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	var raw []string
+	if err := db.Raw("SELECT value FROM secrets ORDER BY id").Scan(&raw).Error; err != nil {
+		t.Fatalf("read raw values: %v", err)
+	}
+	if len(raw) != 3 || !utils.IsEncrypted(raw[0]) || raw[1] != "" || !utils.IsEncrypted(raw[2]) {
+		t.Errorf("stored values = %q, want ciphertext except the empty one", raw)
+	}
+
+	var rows []secret
+	if err := db.Order("id").Find(&rows).Error; err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if len(rows) != 3 || rows[0].Value != "stored-before-the-key" || rows[1].Value != "" || rows[2].Value != "written-with-the-key" {
+		t.Errorf("read back %+v", rows)
+	}
+
+	useEncryptionKey(t, bytes.Repeat([]byte{2}, utils.EncryptionKeySize))
+	if err := db.First(&secret{}, rows[0].ID).Error; !errors.Is(err, utils.ErrDecrypt) {
+		t.Errorf("read with a wrong key = %v, want ErrDecrypt", err)
+	}
+}
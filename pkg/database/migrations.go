@@ -0,0 +1,457 @@
+// Path: pkg/database/migrations.go
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migrationLockID is the advisory lock key serializing migrations across instances starting at once.
+const migrationLockID = 7301
+
+// Migration is an ordered, one-way schema change. Applied versions are recorded in schema_migrations
+// and never run again, so a released migration must not be edited: add a new one instead.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+}
+
+// SchemaMigration records an applied migration in the database.
+type SchemaMigration struct {
+	Version   int       `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// migrations lists every schema change in the order it is applied.
+var migrations = []Migration{
+	{Version: 1, Name: "baseline", Up: baseline},
+	{Version: 2, Name: "user_transfers_enabled", Up: execAll(
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS transfers_enabled boolean NOT NULL DEFAULT true`,
+	)},
+	{Version: 3, Name: "user_round_up", Up: execAll(
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS round_up_enabled boolean NOT NULL DEFAULT false`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS round_up_account_id bigint CONSTRAINT fk_users_round_up_account REFERENCES accounts(id) ON DELETE SET NULL`,
+		`CREATE INDEX IF NOT EXISTS idx_users_round_up_account_id ON users (round_up_account_id)`,
+	)},
+	{Version: 4, Name: "account_type", Up: execAll(
+		`ALTER TABLE accounts ADD COLUMN IF NOT EXISTS type text NOT NULL DEFAULT 'checking'`,
+	)},
+	{Version: 5, Name: "ledger_entries", Up: execAll(
+		`CREATE TABLE IF NOT EXISTS ledger_entries (
+			id bigserial PRIMARY KEY,
+			group_id text NOT NULL CONSTRAINT fk_ledger_entries_transaction REFERENCES transactions(id) ON DELETE CASCADE,
+			account_id bigint NOT NULL CONSTRAINT fk_ledger_entries_account REFERENCES accounts(id) ON DELETE CASCADE,
+			direction text NOT NULL,
+			amount decimal NOT NULL,
+			created_at timestamptz NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_ledger_entries_group_id ON ledger_entries (group_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_ledger_entry_account_created ON ledger_entries (account_id, created_at)`,
+	)},
+	{Version: 6, Name: "deposit_source", Up: execAll(
+		`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS source text NOT NULL DEFAULT ''`,
+	)},
+	{Version: 7, Name: "confirmation_codes", Up: execAll(
+		`CREATE TABLE IF NOT EXISTS confirmation_codes (
+			id bigserial PRIMARY KEY,
+			user_id bigint NOT NULL CONSTRAINT fk_confirmation_codes_user REFERENCES users(id) ON DELETE CASCADE,
+			fingerprint text NOT NULL,
+			code_hash text NOT NULL,
+			attempts bigint NOT NULL DEFAULT 0,
+			expires_at timestamptz NOT NULL,
+			used_at timestamptz,
+			created_at timestamptz NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_confirmation_code_user_fingerprint ON confirmation_codes (user_id, fingerprint)`,
+	)},
+	{Version: 8, Name: "user_totp", Up: execAll(
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_secret text NOT NULL DEFAULT ''`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_last_step bigint NOT NULL DEFAULT 0`,
+	)},
+	{Version: 9, Name: "batch_transfers", Up: execAll(
+		`CREATE TABLE IF NOT EXISTS batch_transfers (
+			id bigserial PRIMARY KEY,
+			user_id bigint NOT NULL CONSTRAINT fk_batch_transfers_user REFERENCES users(id) ON DELETE CASCADE,
+			created_at timestamptz NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_batch_transfers_user_id ON batch_transfers (user_id)`,
+		`CREATE TABLE IF NOT EXISTS batch_items (
+			id bigserial PRIMARY KEY,
+			batch_id bigint NOT NULL CONSTRAINT fk_batch_items_batch REFERENCES batch_transfers(id) ON DELETE CASCADE,
+			position bigint NOT NULL,
+			from_id bigint NOT NULL,
+			to_id bigint NOT NULL,
+			amount decimal NOT NULL,
+			status text NOT NULL,
+			error text NOT NULL DEFAULT '',
+			transaction_id text CONSTRAINT fk_batch_items_transaction REFERENCES transactions(id) ON DELETE SET NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_batch_items_batch_id ON batch_items (batch_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_batch_items_transaction_id ON batch_items (transaction_id)`,
+	)},
+	{Version: 10, Name: "user_statements", Up: execAll(
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS statements_enabled boolean NOT NULL DEFAULT false`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS statement_last_period text NOT NULL DEFAULT ''`,
+	)},
+	{Version: 11, Name: "account_sandbox", Up: execAll(
+		`ALTER TABLE accounts ADD COLUMN IF NOT EXISTS sandbox boolean NOT NULL DEFAULT false`,
+	)},
+	{Version: 12, Name: "transaction_failure_reason", Up: execAll(
+		`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS failure_reason text NOT NULL DEFAULT ''`,
+	)},
+	{Version: 13, Name: "user_daily_spending_limit", Up: execAll(
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS daily_spending_limit decimal`,
+	)},
+	{Version: 14, Name: "webhooks", Up: execAll(
+		`CREATE TABLE IF NOT EXISTS webhooks (
+			id bigserial PRIMARY KEY,
+			user_id bigint NOT NULL CONSTRAINT fk_webhooks_user REFERENCES users(id) ON DELETE CASCADE,
+			url text NOT NULL,
+			secret text NOT NULL,
+			created_at timestamptz NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_webhooks_user_id ON webhooks (user_id)`,
+		`CREATE TABLE IF NOT EXISTS webhook_deliveries (
+			id bigserial PRIMARY KEY,
+			webhook_id bigint NOT NULL CONSTRAINT fk_webhook_deliveries_webhook REFERENCES webhooks(id) ON DELETE CASCADE,
+			event text NOT NULL,
+			payload text NOT NULL,
+			status text NOT NULL,
+			status_code bigint NOT NULL DEFAULT 0,
+			attempts bigint NOT NULL DEFAULT 0,
+			last_error text NOT NULL DEFAULT '',
+			last_attempt_at timestamptz,
+			created_at timestamptz NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id)`,
+	)},
+	{Version: 15, Name: "account_freeze", Up: execAll(
+		`ALTER TABLE accounts ADD COLUMN IF NOT EXISTS frozen boolean NOT NULL DEFAULT false`,
+		`ALTER TABLE accounts ADD COLUMN IF NOT EXISTS frozen_reason text NOT NULL DEFAULT ''`,
+		`CREATE TABLE IF NOT EXISTS suspicious_activities (
+			id bigserial PRIMARY KEY,
+			account_id bigint NOT NULL CONSTRAINT fk_suspicious_activities_account REFERENCES accounts(id) ON DELETE CASCADE,
+			rule text NOT NULL,
+			created_at timestamptz NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_suspicious_activity_account_rule ON suspicious_activities (account_id, rule)`,
+	)},
+	{Version: 16, Name: "transaction_refunds", Up: execAll(
+		`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS refunded_amount decimal NOT NULL DEFAULT 0`,
+		`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS refund_of text CONSTRAINT fk_transactions_refund_of REFERENCES transactions(id) ON DELETE SET NULL`,
+		`CREATE INDEX IF NOT EXISTS idx_transactions_refund_of ON transactions (refund_of)`,
+	)},
+	{Version: 17, Name: "account_interest", Up: execAll(
+		`ALTER TABLE accounts ADD COLUMN IF NOT EXISTS interest_remainder double precision NOT NULL DEFAULT 0`,
+		`ALTER TABLE accounts ADD COLUMN IF NOT EXISTS interest_accrued_at timestamptz`,
+	)},
+	{Version: 18, Name: "account_dormancy", Up: execAll(
+		`ALTER TABLE accounts ADD COLUMN IF NOT EXISTS dormant boolean NOT NULL DEFAULT false`,
+		`ALTER TABLE accounts ADD COLUMN IF NOT EXISTS last_activity_at timestamptz`,
+	)},
+	{Version: 19, Name: "beneficiaries", Up: execAll(
+		`CREATE TABLE IF NOT EXISTS beneficiaries (
+			id bigserial PRIMARY KEY,
+			account_id bigint NOT NULL CONSTRAINT fk_beneficiaries_account REFERENCES accounts(id) ON DELETE CASCADE,
+			beneficiary_account_id bigint NOT NULL CONSTRAINT fk_beneficiaries_beneficiary_account REFERENCES accounts(id) ON DELETE CASCADE,
+			share_percent decimal NOT NULL,
+			created_at timestamptz NOT NULL
+		)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_beneficiary_account ON beneficiaries (account_id, beneficiary_account_id)`,
+	)},
+	// References come from a sequence, so concurrent inserts never collide. Numbers lost to rolled
+	// back transactions leave gaps, which is fine: references only need to be unique and increasing.
+	{Version: 20, Name: "transaction_references", Up: execAll(
+		`CREATE SEQUENCE IF NOT EXISTS transaction_reference_seq`,
+		`CREATE OR REPLACE FUNCTION format_transaction_reference(created timestamptz, n bigint) RETURNS text AS $$
+			SELECT 'TXN-' || to_char(created AT TIME ZONE 'UTC', 'YYYY') || '-' || lpad(n::text, greatest(6, length(n::text)), '0')
+		$$ LANGUAGE sql IMMUTABLE`,
+		`CREATE OR REPLACE FUNCTION next_transaction_reference() RETURNS text AS $$
+			SELECT format_transaction_reference(now(), nextval('transaction_reference_seq'))
+		$$ LANGUAGE sql VOLATILE`,
+		`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS reference text`,
+		`UPDATE transactions t SET reference = format_transaction_reference(o.created_at, o.n)
+			FROM (SELECT id, created_at, row_number() OVER (ORDER BY created_at, id) AS n FROM transactions) o
+			WHERE t.id = o.id AND t.reference IS NULL`,
+		`SELECT setval('transaction_reference_seq', GREATEST((SELECT count(*) FROM transactions), 1), (SELECT count(*) FROM transactions) > 0)`,
+		`ALTER TABLE transactions ALTER COLUMN reference SET DEFAULT next_transaction_reference()`,
+		`ALTER TABLE transactions ALTER COLUMN reference SET NOT NULL`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_reference ON transactions (reference)`,
+	)},
+	{Version: 21, Name: "holds", Up: execAll(
+		`CREATE TABLE IF NOT EXISTS holds (
+			id bigserial PRIMARY KEY,
+			account_id bigint NOT NULL CONSTRAINT fk_holds_account REFERENCES accounts(id) ON DELETE CASCADE,
+			amount decimal NOT NULL,
+			captured_amount decimal NOT NULL DEFAULT 0,
+			status text NOT NULL,
+			description text NOT NULL DEFAULT '',
+			transaction_id text CONSTRAINT fk_holds_transaction REFERENCES transactions(id) ON DELETE SET NULL,
+			created_at timestamptz NOT NULL,
+			resolved_at timestamptz
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_holds_account_status ON holds (account_id, status)`,
+		`CREATE INDEX IF NOT EXISTS idx_holds_transaction_id ON holds (transaction_id)`,
+	)},
+	{Version: 22, Name: "money_requests", Up: execAll(
+		`CREATE TABLE IF NOT EXISTS money_requests (
+			id bigserial PRIMARY KEY,
+			requester_id bigint NOT NULL CONSTRAINT fk_money_requests_requester REFERENCES users(id) ON DELETE CASCADE,
+			payer_id bigint NOT NULL CONSTRAINT fk_money_requests_payer REFERENCES users(id) ON DELETE CASCADE,
+			account_id bigint NOT NULL CONSTRAINT fk_money_requests_account REFERENCES accounts(id) ON DELETE CASCADE,
+			amount decimal NOT NULL,
+			status text NOT NULL,
+			description text NOT NULL DEFAULT '',
+			transaction_id text CONSTRAINT fk_money_requests_transaction REFERENCES transactions(id) ON DELETE SET NULL,
+			created_at timestamptz NOT NULL,
+			resolved_at timestamptz
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_money_requests_requester_id ON money_requests (requester_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_money_requests_payer_id ON money_requests (payer_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_money_requests_transaction_id ON money_requests (transaction_id)`,
+	)},
+	// The event log is append-only and outlives what it describes, so it has no foreign keys.
+	{Version: 23, Name: "events", Up: execAll(
+		`CREATE TABLE IF NOT EXISTS events (
+			seq bigserial PRIMARY KEY,
+			type text NOT NULL,
+			account_id bigint NOT NULL,
+			counter_account_id bigint,
+			transaction_id text,
+			amount decimal NOT NULL DEFAULT 0,
+			fee decimal NOT NULL DEFAULT 0,
+			currency text NOT NULL,
+			created_at timestamptz NOT NULL
+		)`,
+	)},
+	{Version: 24, Name: "transfer_whitelist", Up: execAll(
+		`ALTER TABLE accounts ADD COLUMN IF NOT EXISTS transfer_whitelist boolean NOT NULL DEFAULT false`,
+		`CREATE TABLE IF NOT EXISTS transfer_whitelist_entries (
+			account_id bigint CONSTRAINT fk_transfer_whitelist_entries_account REFERENCES accounts(id) ON DELETE CASCADE,
+			destination_account_id bigint CONSTRAINT fk_transfer_whitelist_entries_destination REFERENCES accounts(id) ON DELETE CASCADE,
+			created_at timestamptz NOT NULL,
+			PRIMARY KEY (account_id, destination_account_id)
+		)`,
+	)},
+	{Version: 25, Name: "sub_balances", Up: execAll(
+		`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS sub_balance text NOT NULL DEFAULT ''`,
+		`CREATE TABLE IF NOT EXISTS sub_balances (
+			account_id bigint CONSTRAINT fk_sub_balances_account REFERENCES accounts(id) ON DELETE CASCADE,
+			name text,
+			amount decimal NOT NULL DEFAULT 0,
+			hash text NOT NULL,
+			PRIMARY KEY (account_id, name)
+		)`,
+	)},
+	// Sub-balances move into the account row, signed by its balance hash. The hash needs the secret
+	// key, so the migration can't re-sign accounts: the parts still in the table fold back into the
+	// principal, which leaves balances and their hashes as they were.
+	{Version: 26, Name: "account_sub_balances", Up: execAll(
+		`ALTER TABLE accounts ADD COLUMN IF NOT EXISTS sub_balances jsonb`,
+		`DROP TABLE IF EXISTS sub_balances`,
+	)},
+	{Version: 27, Name: "account_cosigners", Up: execAll(
+		`CREATE TABLE IF NOT EXISTS account_cosigners (
+			account_id bigint CONSTRAINT fk_account_cosigners_account REFERENCES accounts(id) ON DELETE CASCADE,
+			user_id bigint CONSTRAINT fk_account_cosigners_user REFERENCES users(id) ON DELETE CASCADE,
+			created_at timestamptz NOT NULL,
+			PRIMARY KEY (account_id, user_id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_account_cosigners_user_id ON account_cosigners (user_id)`,
+	)},
+	// Transfers completed by an approval or by the recipient record when; those created completed
+	// leave it null and complete at created_at.
+	{Version: 28, Name: "transaction_completed_at", Up: execAll(
+		`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS completed_at timestamptz`,
+	)},
+	{Version: 29, Name: "account_closed_at", Up: execAll(
+		`ALTER TABLE accounts ADD COLUMN IF NOT EXISTS closed_at timestamptz`,
+	)},
+	// Purging a deleted user keeps their accounts, and with them the ledger, without an owner.
+	{Version: 30, Name: "user_deletion", Up: execAll(
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at timestamptz`,
+		`ALTER TABLE accounts ALTER COLUMN user_id DROP NOT NULL`,
+		`ALTER TABLE accounts DROP CONSTRAINT IF EXISTS fk_accounts_user`,
+		`ALTER TABLE accounts ADD CONSTRAINT fk_accounts_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL`,
+	)},
+}
+
+// Migrate applies the pending migrations in order, each in its own transaction.
+func Migrate(db *gorm.DB) error {
+	return applyMigrations(db, migrations)
+}
+
+// applyMigrations applies the pending ones of the given migrations in order.
+func applyMigrations(db *gorm.DB, migrations []Migration) error {
+	err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version bigint PRIMARY KEY,
+		name text NOT NULL,
+		applied_at timestamptz NOT NULL
+	)`).Error
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	for _, m := range migrations {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockID).Error; err != nil {
+				return err
+			}
+
+			var applied int64
+			if err := tx.Model(&SchemaMigration{}).Where("version = ?", m.Version).Count(&applied).Error; err != nil {
+				return err
+			}
+			if applied > 0 {
+				return nil
+			}
+
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// execAll returns a migration running the given SQL statements in order.
+func execAll(statements ...string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// baseline creates the schema as it was before versioned migrations. Databases previously set up by
+// AutoMigrate already have these tables, possibly as the first release left them: the columns added
+// since are added to them, and the timestamps it stored as text are converted.
+func baseline(tx *gorm.DB) error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS users (
+			id bigserial PRIMARY KEY,
+			username text NOT NULL CONSTRAINT uni_users_username UNIQUE,
+			password text NOT NULL,
+			role text NOT NULL DEFAULT 'user',
+			tier text NOT NULL DEFAULT 'standard',
+			created_at text NOT NULL
+		)`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS role text NOT NULL DEFAULT 'user'`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS tier text NOT NULL DEFAULT 'standard'`,
+		`CREATE TABLE IF NOT EXISTS accounts (
+			id bigserial PRIMARY KEY,
+			user_id bigint NOT NULL CONSTRAINT fk_accounts_user REFERENCES users(id) ON DELETE CASCADE,
+			number text,
+			balance decimal NOT NULL DEFAULT 0,
+			currency text NOT NULL DEFAULT 'USD',
+			balance_hash text NOT NULL,
+			nickname text NOT NULL DEFAULT '',
+			created_at timestamptz NOT NULL
+		)`,
+		`ALTER TABLE accounts ADD COLUMN IF NOT EXISTS number text`,
+		`ALTER TABLE accounts ADD COLUMN IF NOT EXISTS currency text NOT NULL DEFAULT 'USD'`,
+		`ALTER TABLE accounts ADD COLUMN IF NOT EXISTS nickname text NOT NULL DEFAULT ''`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_accounts_number ON accounts (number)`,
+		`CREATE TABLE IF NOT EXISTS transactions (
+			id text PRIMARY KEY,
+			from_account_id bigint CONSTRAINT fk_transactions_from_account REFERENCES accounts(id) ON DELETE SET NULL,
+			to_account_id bigint CONSTRAINT fk_transactions_to_account REFERENCES accounts(id) ON DELETE SET NULL,
+			initiator_id bigint,
+			approver_id bigint,
+			amount decimal NOT NULL,
+			type text NOT NULL,
+			status text NOT NULL,
+			description text NOT NULL DEFAULT '',
+			fee decimal NOT NULL DEFAULT 0,
+			fee_waived boolean NOT NULL DEFAULT false,
+			fee_waiver text NOT NULL DEFAULT '',
+			created_at timestamptz NOT NULL
+		)`,
+		`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS initiator_id bigint`,
+		`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS approver_id bigint`,
+		`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS description text NOT NULL DEFAULT ''`,
+		`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS fee decimal NOT NULL DEFAULT 0`,
+		`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS fee_waived boolean NOT NULL DEFAULT false`,
+		`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS fee_waiver text NOT NULL DEFAULT ''`,
+		`CREATE INDEX IF NOT EXISTS idx_transactions_created_at ON transactions (created_at)`,
+		`CREATE TABLE IF NOT EXISTS audit_logs (
+			id bigserial PRIMARY KEY,
+			user_id bigint,
+			action text NOT NULL,
+			severity text NOT NULL,
+			details text NOT NULL,
+			created_at timestamptz NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_audit_logs_user_id ON audit_logs (user_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_audit_logs_action ON audit_logs (action)`,
+		`CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs (created_at)`,
+		`CREATE TABLE IF NOT EXISTS payees (
+			id bigserial PRIMARY KEY,
+			user_id bigint NOT NULL CONSTRAINT fk_payees_user REFERENCES users(id) ON DELETE CASCADE,
+			account_id bigint NOT NULL CONSTRAINT fk_payees_account REFERENCES accounts(id) ON DELETE CASCADE,
+			label text NOT NULL,
+			created_at timestamptz NOT NULL
+		)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_payee_user_account ON payees (user_id, account_id)`,
+		`CREATE TABLE IF NOT EXISTS transaction_tags (
+			transaction_id text CONSTRAINT fk_transaction_tags_transaction REFERENCES transactions(id) ON DELETE CASCADE,
+			user_id bigint CONSTRAINT fk_transaction_tags_user REFERENCES users(id) ON DELETE CASCADE,
+			tag text,
+			PRIMARY KEY (transaction_id, user_id, tag)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_transaction_tags_tag ON transaction_tags (tag)`,
+		`CREATE TABLE IF NOT EXISTS login_attempts (
+			id bigserial PRIMARY KEY,
+			user_id bigint NOT NULL CONSTRAINT fk_login_attempts_user REFERENCES users(id) ON DELETE CASCADE,
+			ip text NOT NULL,
+			user_agent text NOT NULL,
+			outcome text NOT NULL,
+			created_at timestamptz NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_login_attempt_user_created ON login_attempts (user_id, created_at)`,
+	}
+	if err := execAll(statements...)(tx); err != nil {
+		return err
+	}
+
+	for _, table := range []string{"accounts", "transactions"} {
+		if err := convertCreatedAt(tx, table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// convertCreatedAt turns the created_at column of table from text into a timestamp on databases
+// created before it was one. Older versions left accounts.created_at empty, which can't be cast.
+func convertCreatedAt(tx *gorm.DB, table string) error {
+	columns, err := tx.Migrator().ColumnTypes(table)
+	if err != nil {
+		return fmt.Errorf("failed to inspect %s table: %w", table, err)
+	}
+	for _, column := range columns {
+		if column.Name() != "created_at" || column.DatabaseTypeName() != "text" {
+			continue
+		}
+		if err := tx.Exec("UPDATE " + table + " SET created_at = NOW()::text WHERE created_at = ''").Error; err != nil {
+			return fmt.Errorf("failed to backfill %s.created_at: %w", table, err)
+		}
+		if err := tx.Exec("ALTER TABLE " + table + " ALTER COLUMN created_at TYPE timestamptz USING created_at::timestamptz").Error; err != nil {
+			return fmt.Errorf("failed to convert %s.created_at: %w", table, err)
+		}
+	}
+
+	return nil
+}
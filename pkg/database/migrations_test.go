@@ -0,0 +1,259 @@
+// Path: pkg/database/migrations_test.go
+package database
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestMigrationVersionsAreOrdered(t *testing.T) {
+	names := make(map[string]bool)
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("migration %q has version %d, want %d", m.Name, m.Version, i+1)
+		}
+		if m.Name == "" || names[m.Name] {
+			t.Errorf("migration %d has an empty or duplicate name %q", m.Version, m.Name)
+		}
+		names[m.Name] = true
+		if m.Up == nil {
+			t.Errorf("migration %d has no Up", m.Version)
+		}
+	}
+}
+
+// emptySchema opens the Postgres database in TEST_DATABASE_URL on a new empty schema, dropped when
+// the test ends. Tests needing it are skipped without TEST_DATABASE_URL.
+func emptySchema(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	config := &gorm.Config{Logger: logger.Discard}
+	admin, err := gorm.Open(postgres.Open(dsn), config)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	name := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	if err := admin.Exec("CREATE SCHEMA " + name).Error; err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+
+	connConfig, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		t.Fatalf("parse database URL: %v", err)
+	}
+	connConfig.RuntimeParams["search_path"] = name
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: stdlib.OpenDB(*connConfig)}), config)
+	if err != nil {
+		t.Fatalf("open schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+		admin.Exec("DROP SCHEMA " + name + " CASCADE")
+		if sqlDB, err := admin.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// checkModelColumns fails the test unless every table and column the models map exists.
+func checkModelColumns(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	models := []interface{}{&User{}, &Account{}, &Transaction{}, &AuditLog{}, &Payee{}, &TransactionTag{}, &LoginAttempt{}, &LedgerEntry{}, &ConfirmationCode{}, &BatchTransfer{}, &BatchItem{}, &Webhook{}, &WebhookDelivery{}, &SuspiciousActivity{}, &Beneficiary{}, &Hold{}, &MoneyRequest{}, &Event{}, &TransferWhitelistEntry{}, &AccountCosigner{}}
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			t.Fatalf("parse %T: %v", model, err)
+		}
+		if !db.Migrator().HasTable(stmt.Schema.Table) {
+			t.Errorf("table %s is missing", stmt.Schema.Table)
+			continue
+		}
+		for _, column := range stmt.Schema.DBNames {
+			if !db.Migrator().HasColumn(model, column) {
+				t.Errorf("column %s.%s is missing", stmt.Schema.Table, column)
+			}
+		}
+	}
+}
+
+func TestMigrateFromEmptyToLatest(t *testing.T) {
+	db := emptySchema(t)
+	if err := Migrate(db); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+
+	var applied []SchemaMigration
+	if err := db.Order("version").Find(&applied).Error; err != nil {
+		t.Fatalf("read schema_migrations: %v", err)
+	}
+	if len(applied) != len(migrations) {
+		t.Fatalf("%d migrations recorded, want %d", len(applied), len(migrations))
+	}
+	for i, m := range applied {
+		if m.Version != migrations[i].Version || m.Name != migrations[i].Name {
+			t.Errorf("applied[%d] = %d %s, want %d %s", i, m.Version, m.Name, migrations[i].Version, migrations[i].Name)
+		}
+	}
+
+	// Every column the models map must exist without AutoMigrate.
+	checkModelColumns(t, db)
+
+	// Running again applies nothing and fails nothing.
+	if err := Migrate(db); err != nil {
+		t.Fatalf("second Migrate: %v", err)
+	}
+	var count int64
+	db.Model(&SchemaMigration{}).Count(&count)
+	if count != int64(len(migrations)) {
+		t.Errorf("%d migrations recorded after a second run, want %d", count, len(migrations))
+	}
+}
+
+func TestAccountSubBalancesMigrationReplacesTable(t *testing.T) {
+	db := emptySchema(t)
+	if err := applyMigrations(db, migrations[:25]); err != nil {
+		t.Fatalf("migrate to 25: %v", err)
+	}
+	statements := []string{
+		`INSERT INTO users (id, username, password, created_at) VALUES (1, 'alice', 'x', '2024-01-01')`,
+		`INSERT INTO accounts (id, user_id, number, balance, balance_hash, created_at) VALUES (1, 1, '4000000001', 150, 'hash', now())`,
+		`INSERT INTO sub_balances (account_id, name, amount, hash) VALUES (1, 'bonus', 50, 'sub-hash')`,
+	}
+	if err := execAll(statements...)(db); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	if err := Migrate(db); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	if db.Migrator().HasTable("sub_balances") {
+		t.Error("table sub_balances still exists")
+	}
+	var account struct {
+		Balance     float64
+		BalanceHash string
+		SubBalances *string
+	}
+	if err := db.Table("accounts").Where("id = 1").Take(&account).Error; err != nil {
+		t.Fatalf("load account: %v", err)
+	}
+	if account.Balance != 150 || account.BalanceHash != "hash" || account.SubBalances != nil {
+		t.Errorf("account = %+v, want balance 150 and hash unchanged, no sub-balances", account)
+	}
+}
+
+// The models of the first release, whose schema AutoMigrate created.
+type releaseUser struct {
+	ID        uint   `gorm:"primaryKey"`
+	Username  string `gorm:"unique;not null"`
+	Password  string `gorm:"not null"`
+	CreatedAt string `gorm:"not null"`
+}
+
+func (releaseUser) TableName() string { return "users" }
+
+type releaseAccount struct {
+	ID          uint        `gorm:"primaryKey"`
+	UserID      uint        `gorm:"not null"`
+	Balance     float64     `gorm:"not null;default:0"`
+	BalanceHash string      `gorm:"not null"`
+	CreatedAt   string      `gorm:"not null"`
+	User        releaseUser `gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+func (releaseAccount) TableName() string { return "accounts" }
+
+type releaseTransaction struct {
+	ID            string `gorm:"primaryKey"`
+	FromAccountID *uint
+	ToAccountID   *uint
+	Amount        float64         `gorm:"not null"`
+	Type          string          `gorm:"not null"`
+	Status        string          `gorm:"not null"`
+	CreatedAt     string          `gorm:"not null"`
+	FromAccount   *releaseAccount `gorm:"constraint:OnDelete:SET NULL;"`
+	ToAccount     *releaseAccount `gorm:"constraint:OnDelete:SET NULL;"`
+}
+
+func (releaseTransaction) TableName() string { return "transactions" }
+
+func TestMigrateFromFirstRelease(t *testing.T) {
+	db := emptySchema(t)
+	if err := db.AutoMigrate(&releaseUser{}, &releaseAccount{}, &releaseTransaction{}); err != nil {
+		t.Fatalf("create the first release schema: %v", err)
+	}
+	// The first release left accounts.created_at empty and stored transaction times as pgx renders them.
+	statements := []string{
+		`INSERT INTO users (id, username, password, created_at) VALUES (1, 'alice', 'hash', '2024-01-01T10:00:00Z'), (2, 'bob', 'hash', '2024-01-02T10:00:00Z')`,
+		`INSERT INTO accounts (id, user_id, balance, balance_hash, created_at) VALUES (1, 1, 70.5, 'alice-hash', ''), (2, 2, 29.5, 'bob-hash', '')`,
+		`INSERT INTO transactions (id, from_account_id, to_account_id, amount, type, status, created_at) VALUES
+			('t1', NULL, 1, 100, 'deposit', 'completed', '2024-01-03 09:30:00.123456+00'),
+			('t2', 1, 2, 29.5, 'transfer', 'completed', '2024-01-04 18:00:00+03')`,
+		`SELECT setval(pg_get_serial_sequence('users', 'id'), 2)`,
+		`SELECT setval(pg_get_serial_sequence('accounts', 'id'), 2)`,
+	}
+	if err := execAll(statements...)(db); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	if err := Migrate(db); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	checkModelColumns(t, db)
+
+	var users []User
+	if err := db.Order("id").Find(&users).Error; err != nil {
+		t.Fatalf("load users: %v", err)
+	}
+	if len(users) != 2 || users[0].Username != "alice" || users[0].Role != "user" || users[0].Tier != "standard" {
+		t.Errorf("users = %+v, want both kept with the default role and tier", users)
+	}
+
+	var accounts []Account
+	if err := db.Order("id").Find(&accounts).Error; err != nil {
+		t.Fatalf("load accounts: %v", err)
+	}
+	if len(accounts) != 2 || accounts[0].Balance != 70.5 || accounts[0].BalanceHash != "alice-hash" || accounts[0].Currency != "USD" || accounts[0].CreatedAt.IsZero() {
+		t.Errorf("accounts = %+v, want balances and hashes kept, USD and a creation time", accounts)
+	}
+
+	var transactions []Transaction
+	if err := db.Order("id").Find(&transactions).Error; err != nil {
+		t.Fatalf("load transactions: %v", err)
+	}
+	if len(transactions) != 2 {
+		t.Fatalf("%d transactions, want 2", len(transactions))
+	}
+	want := []time.Time{
+		time.Date(2024, 1, 3, 9, 30, 0, 123456000, time.UTC),
+		time.Date(2024, 1, 4, 15, 0, 0, 0, time.UTC),
+	}
+	for i, transaction := range transactions {
+		if !transaction.CreatedAt.Equal(want[i]) {
+			t.Errorf("transaction %s created at %v, want %v", transaction.ID, transaction.CreatedAt, want[i])
+		}
+		if transaction.Reference == "" || transaction.Fee != 0 || transaction.Description != "" {
+			t.Errorf("transaction %s = %+v, want a reference and no fee or description", transaction.ID, transaction)
+		}
+	}
+
+	// The upgraded schema takes new rows.
+	if err := db.Exec(`INSERT INTO accounts (user_id, number, balance_hash, currency, created_at) VALUES (1, '000000034', 'h', 'EUR', now())`).Error; err != nil {
+		t.Errorf("insert an account after the upgrade: %v", err)
+	}
+}
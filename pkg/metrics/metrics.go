@@ -0,0 +1,32 @@
+// Path: pkg/metrics/metrics.go
+package metrics
+
+import "expvar"
+
+// Метрики публикуются через expvar; администраторам они доступны на /api/admin/metrics.
+var (
+	// BalanceIntegrityFailures is a gauge: number of accounts failing the last balance audit.
+	BalanceIntegrityFailures = newInt("balance_integrity_failures")
+	// BalanceAuditRuns counts completed balance audits.
+	BalanceAuditRuns = newInt("balance_audit_runs")
+	// BalanceIntegrityViolations counts balance integrity checks failed while serving requests and jobs.
+	BalanceIntegrityViolations = newInt("balance_integrity_violations")
+)
+
+var registered = map[string]*expvar.Int{}
+
+func newInt(name string) *expvar.Int {
+	v := expvar.NewInt(name)
+	registered[name] = v
+	return v
+}
+
+// Snapshot returns the current value of every metric by name. Unlike /debug/vars it leaves out
+// the runtime variables (memstats, cmdline), so it is safe to serve.
+func Snapshot() map[string]int64 {
+	snapshot := make(map[string]int64, len(registered))
+	for name, v := range registered {
+		snapshot[name] = v.Value()
+	}
+	return snapshot
+}
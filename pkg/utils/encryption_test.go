@@ -0,0 +1,66 @@
+// Path: pkg/utils/encryption_test.go
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, EncryptionKeySize)
+}
+
+func TestCipherRoundTrip(t *testing.T) {
+	c, err := NewCipher(testKey(1))
+	if err != nil {
+		t.Fatalf("NewCipher: %v", err)
+	}
+	first, err := c.Encrypt("JBSWY3DPEHPK3PXP")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	second, _ := c.Encrypt("JBSWY3DPEHPK3PXP")
+	if !IsEncrypted(first) || strings.Contains(first, "JBSWY3DPEHPK3PXP") {
+		t.Errorf("ciphertext %q", first)
+	}
+	if first == second {
+		t.Error("the same plaintext encrypted twice gives the same ciphertext")
+	}
+	for _, value := range []string{first, second} {
+		if got, err := c.Decrypt(value); err != nil || got != "JBSWY3DPEHPK3PXP" {
+			t.Errorf("Decrypt = %q, %v", got, err)
+		}
+	}
+}
+
+func TestCipherRejectsWrongKeysAndTampering(t *testing.T) {
+	c, _ := NewCipher(testKey(1))
+	other, _ := NewCipher(testKey(2))
+	value, _ := c.Encrypt("webhook-secret")
+
+	if _, err := other.Decrypt(value); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("wrong key: %v, want ErrDecrypt", err)
+	}
+	tampered := value[:len(value)-2] + "AA"
+	if tampered == value {
+		tampered = value[:len(value)-2] + "BB"
+	}
+	for _, bad := range []string{tampered, encryptedPrefix + "not base64!", encryptedPrefix + "AAAA"} {
+		if _, err := c.Decrypt(bad); !errors.Is(err, ErrDecrypt) {
+			t.Errorf("Decrypt(%q) = %v, want ErrDecrypt", bad, err)
+		}
+	}
+	if _, err := c.Decrypt("webhook-secret"); err == nil {
+		t.Error("plaintext decrypted without an error")
+	}
+}
+
+func TestNewCipherRequiresA32ByteKey(t *testing.T) {
+	for _, size := range []int{0, 16, 31, 33} {
+		if _, err := NewCipher(make([]byte, size)); err == nil {
+			t.Errorf("key of %d bytes accepted", size)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// Path: pkg/utils/mask.go
+package utils
+
+import (
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Identifiers that must not appear in full in logs: "account_id: 123", "from_id=123",
+// JSON fields, prose like "frozen account 123", account-scoped URL paths like /api/deposit/123 and
+// account numbers, IBANs included, like "account_number: DE89370400440532013000".
+var (
+	maskFieldPattern  = regexp.MustCompile(`((?:account_id|from_id|to_id|from_account_id|to_account_id)"?\s*[:=]\s*"?)(\d+)`)
+	maskNumberPattern = regexp.MustCompile(`((?:account_number|[Aa]ccount number)"?\s*[:=]\s*"?)([A-Za-z0-9]+)`)
+	maskWordPattern   = regexp.MustCompile(`(\b[Aa]ccount\s+)(\d+)`)
+	maskPathPattern   = regexp.MustCompile(`(/(?:accounts|deposit|withdraw)/)(\d+)`)
+)
+
+// MaskAccountNumber hides all but the last 4 characters of an account number.
+// Numbers with 4 characters or fewer are masked completely.
+func MaskAccountNumber(number string) string {
+	if len(number) <= 4 {
+		return strings.Repeat("*", len(number))
+	}
+	return strings.Repeat("*", len(number)-4) + number[len(number)-4:]
+}
+
+// MaskIdentifiers masks every account identifier found in a log line.
+func MaskIdentifiers(s string) string {
+	replace := func(re *regexp.Regexp, s string) string {
+		return re.ReplaceAllStringFunc(s, func(m string) string {
+			parts := re.FindStringSubmatch(m)
+			return parts[1] + MaskAccountNumber(parts[2])
+		})
+	}
+	return replace(maskPathPattern, replace(maskWordPattern, replace(maskNumberPattern, replace(maskFieldPattern, s))))
+}
+
+// MaskingWriter masks account identifiers in everything written through it.
+type MaskingWriter struct {
+	W io.Writer
+}
+
+func (m MaskingWriter) Write(p []byte) (int, error) {
+	if _, err := m.W.Write([]byte(MaskIdentifiers(string(p)))); err != nil {
+		return 0, err
+	}
+	// Report the original length so callers don't treat masking as a short write.
+	return len(p), nil
+}
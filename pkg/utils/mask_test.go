@@ -0,0 +1,93 @@
+// Path: pkg/utils/mask_test.go
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestMaskAccountNumber(t *testing.T) {
+	cases := map[string]string{
+		"":                       "",
+		"1234":                   "****",
+		"12345":                  "*2345",
+		"0000000034":             "******0034",
+		"DE89370400440532013000": "******************3000",
+	}
+	for number, want := range cases {
+		if got := MaskAccountNumber(number); got != want {
+			t.Errorf("MaskAccountNumber(%q) = %q, want %q", number, got, want)
+		}
+	}
+}
+
+// TestMaskingWriterLogFormats runs the log formats used across the services through a logger
+// writing to MaskingWriter: none may leave the account ID in full.
+func TestMaskingWriterLogFormats(t *testing.T) {
+	const id = 1234567
+	failure := errors.New("connection reset")
+	lines := []string{
+		fmt.Sprintf("Failed to record integrity failure on account %d: %v", id, failure),
+		fmt.Sprintf("Failed to count large withdrawals on account %d: %v", id, failure),
+		fmt.Sprintf("Failed to query account %d to freeze: %v", id, failure),
+		fmt.Sprintf("Account %d frozen by rule %s: %s", id, "large_withdrawals", "3 large withdrawals"),
+		fmt.Sprintf("Failed to notify user %d of frozen account %d: %v", 42, id, failure),
+		fmt.Sprintf("Failed to mark account %d dormant: %v", id, failure),
+		fmt.Sprintf("Failed to record failed %s on account %d: %v", "withdraw", id, failure),
+		fmt.Sprintf("Failed to audit integrity failure on account %d: %v", id, failure),
+		fmt.Sprintf("Failed to accrue interest on account %d: %v", id, failure),
+		fmt.Sprintf("Error: %s", "Balance integrity check failed: account_id: 1234567"),
+		fmt.Sprintf("Error: %s", `{"from_id": 1234567, "to_id":1234567}`),
+		fmt.Sprintf("Error: %s", "from_account_id=1234567"),
+		"POST /api/deposit/1234567 200",
+		"GET /api/accounts/1234567/statement 200",
+	}
+
+	for _, line := range lines {
+		var buf bytes.Buffer
+		logger := log.New(MaskingWriter{W: &buf}, "", 0)
+		logger.Print(line)
+
+		got := buf.String()
+		if strings.Contains(got, "1234567") {
+			t.Errorf("account ID left unmasked in %q", got)
+		}
+		if !strings.Contains(got, "***4567") {
+			t.Errorf("masked ID missing from %q", got)
+		}
+	}
+}
+
+func TestMaskIdentifiersAccountNumbers(t *testing.T) {
+	cases := map[string]string{
+		"Error: AppError: Invalid account number (Code: 400, Details: account_number: DE89370400440532013001)": "Error: AppError: Invalid account number (Code: 400, Details: account_number: ******************3001)",
+		`{"to_account_number": "000012348"}`:                                    `{"to_account_number": "*****2348"}`,
+		"GET /api/transfer/fee?from=1&to_account_number=DE89370400440532013000": "GET /api/transfer/fee?from=1&to_account_number=******************3000",
+		"Invalid account number: GB82WEST12345698765432":                        "Invalid account number: ******************5432",
+	}
+	for line, want := range cases {
+		if got := MaskIdentifiers(line); got != want {
+			t.Errorf("MaskIdentifiers(%q) = %q, want %q", line, got, want)
+		}
+	}
+}
+
+func TestMaskIdentifiersKeepsOtherNumbers(t *testing.T) {
+	line := "Failed to notify user 1234567 of 3 accounts: took 1500ms"
+	if got := MaskIdentifiers(line); got != line {
+		t.Errorf("MaskIdentifiers(%q) = %q, want it unchanged", line, got)
+	}
+}
+
+func TestMaskingWriterReportsFullLength(t *testing.T) {
+	var buf bytes.Buffer
+	p := []byte("account_id: 1234567\n")
+	n, err := MaskingWriter{W: &buf}.Write(p)
+	if err != nil || n != len(p) {
+		t.Fatalf("Write = %d, %v; want %d, nil", n, err, len(p))
+	}
+}
@@ -0,0 +1,112 @@
+// Path: pkg/utils/scheduler.go
+package utils
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Scheduler runs background jobs at fixed intervals. Each job starts after its own random delay
+// within the warmup, so jobs with the same interval don't all hit the database at once after a
+// restart, and at most maxConcurrent jobs run at the same time.
+type Scheduler struct {
+	warmup time.Duration
+	slots  chan struct{} // nil when concurrency is unbounded
+	jobs   []scheduledJob
+
+	stop    chan struct{}
+	running sync.WaitGroup
+}
+
+type scheduledJob struct {
+	interval time.Duration
+	run      func()
+}
+
+// NewScheduler creates a Scheduler. A maxConcurrent of 0 doesn't bound concurrency; a zero
+// warmup starts every job right away, as plain tickers would.
+func NewScheduler(maxConcurrent int, warmup time.Duration) *Scheduler {
+	s := &Scheduler{warmup: warmup, stop: make(chan struct{})}
+	if maxConcurrent > 0 {
+		s.slots = make(chan struct{}, maxConcurrent)
+	}
+	return s
+}
+
+// Every registers run to be called once per interval. A run still going when the next one is due
+// delays it rather than overlapping it. Jobs must be registered before Start.
+func (s *Scheduler) Every(interval time.Duration, run func()) {
+	s.jobs = append(s.jobs, scheduledJob{interval: interval, run: run})
+}
+
+// Start launches the registered jobs. The first run of a job comes one interval after its start.
+func (s *Scheduler) Start() {
+	for _, job := range s.jobs {
+		s.running.Add(1)
+		go func() {
+			defer s.running.Done()
+			s.loop(job, s.startDelay())
+		}()
+	}
+}
+
+// Stop stops scheduling runs and waits for the ones in progress, until ctx is done.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	close(s.stop)
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) startDelay() time.Duration {
+	if s.warmup <= 0 {
+		return 0
+	}
+	return rand.N(s.warmup)
+}
+
+func (s *Scheduler) loop(job scheduledJob, delay time.Duration) {
+	select {
+	case <-time.After(delay):
+	case <-s.stop:
+		return
+	}
+
+	ticker := time.NewTicker(job.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.stop:
+			return
+		}
+		// A tick and Stop may both be ready; select picks either, so check for Stop again.
+		select {
+		case <-s.stop:
+			return
+		default:
+		}
+
+		if s.slots != nil {
+			select {
+			case s.slots <- struct{}{}:
+			case <-s.stop:
+				return
+			}
+		}
+		job.run()
+		if s.slots != nil {
+			<-s.slots
+		}
+	}
+}
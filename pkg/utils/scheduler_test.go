@@ -0,0 +1,139 @@
+// Path: pkg/utils/scheduler_test.go
+package utils
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func stopScheduler(t *testing.T, s *Scheduler) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.Stop(ctx); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+}
+
+func TestSchedulerHonoursInterval(t *testing.T) {
+	var runs atomic.Int32
+	s := NewScheduler(0, 0)
+	s.Every(40*time.Millisecond, func() { runs.Add(1) })
+	start := time.Now()
+	s.Start()
+
+	time.Sleep(20 * time.Millisecond)
+	if n := runs.Load(); n != 0 {
+		t.Errorf("%d runs before the first interval passed", n)
+	}
+	time.Sleep(190 * time.Millisecond)
+	stopScheduler(t, s)
+	// Four intervals fit into the ~210ms; a loaded machine may lose one.
+	if n := runs.Load(); n < 3 || n > 5 {
+		t.Errorf("%d runs in %v at a 40ms interval, want about 4", n, time.Since(start))
+	}
+}
+
+func TestSchedulerWarmupStaggersJobs(t *testing.T) {
+	const jobs = 10
+	var mu sync.Mutex
+	first := make(map[int]time.Duration)
+	s := NewScheduler(0, 300*time.Millisecond)
+	start := time.Now()
+	for i := 0; i < jobs; i++ {
+		s.Every(5*time.Millisecond, func() {
+			mu.Lock()
+			defer mu.Unlock()
+			if _, ok := first[i]; !ok {
+				first[i] = time.Since(start)
+			}
+		})
+	}
+	s.Start()
+	time.Sleep(400 * time.Millisecond)
+	stopScheduler(t, s)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(first) != jobs {
+		t.Fatalf("%d of %d jobs ran within the warmup", len(first), jobs)
+	}
+	earliest, latest := time.Hour, time.Duration(0)
+	for _, at := range first {
+		earliest, latest = min(earliest, at), max(latest, at)
+	}
+	// Ten delays drawn from 300ms all landing within 30ms of each other is vanishingly unlikely.
+	if latest-earliest < 30*time.Millisecond {
+		t.Errorf("first runs between %v and %v, want them spread over the warmup", earliest, latest)
+	}
+}
+
+func TestSchedulerBoundsConcurrentJobs(t *testing.T) {
+	var running, peak, runs atomic.Int32
+	s := NewScheduler(2, 0)
+	for i := 0; i < 5; i++ {
+		s.Every(5*time.Millisecond, func() {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			running.Add(-1)
+			runs.Add(1)
+		})
+	}
+	s.Start()
+	time.Sleep(150 * time.Millisecond)
+	stopScheduler(t, s)
+
+	if p := peak.Load(); p != 2 {
+		t.Errorf("at most %d jobs ran at once, want 2", p)
+	}
+	if runs.Load() == 0 {
+		t.Error("no job ran")
+	}
+}
+
+func TestSchedulerStopWaitsForRunningJob(t *testing.T) {
+	entered, release := make(chan struct{}), make(chan struct{})
+	var finished, runs atomic.Int32
+	s := NewScheduler(0, 0)
+	s.Every(5*time.Millisecond, func() {
+		if runs.Add(1) == 1 {
+			close(entered)
+			<-release
+		}
+		finished.Add(1)
+	})
+	s.Start()
+	<-entered
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.Stop(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Stop with a job still running = %v, want the deadline", err)
+	}
+	close(release)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		t.Fatal("jobs kept running after Stop")
+	}
+	if n := finished.Load(); n != 1 {
+		t.Errorf("%d runs finished, want the one in progress and no new ones", n)
+	}
+}
@@ -0,0 +1,55 @@
+// Path: pkg/utils/totp_test.go
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 key of the RFC 6238 test vectors, "12345678901234567890", in base32.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCodeMatchesRFC6238(t *testing.T) {
+	// The RFC lists 8-digit codes; 6-digit codes are their last six digits.
+	vectors := map[int64]string{59: "287082", 1111111109: "081804", 1234567890: "005924", 2000000000: "279037"}
+	for unix, want := range vectors {
+		got, err := TOTPCode(rfcSecret, TOTPStep(time.Unix(unix, 0)))
+		if err != nil || got != want {
+			t.Errorf("TOTPCode at %d = %q, %v; want %q", unix, got, err, want)
+		}
+	}
+	if _, err := TOTPCode("not base32!", 1); err == nil {
+		t.Error("invalid secret accepted")
+	}
+}
+
+func TestVerifyTOTPAcceptsOneStepOfSkew(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	current := TOTPStep(now)
+	for _, offset := range []int64{-1, 0, 1} {
+		code, _ := TOTPCode(rfcSecret, current+offset)
+		if step, ok := VerifyTOTP(rfcSecret, code, now); !ok || step != current+offset {
+			t.Errorf("code of step %+d = %d, %t", offset, step, ok)
+		}
+	}
+	for _, offset := range []int64{-2, 2} {
+		code, _ := TOTPCode(rfcSecret, current+offset)
+		if _, ok := VerifyTOTP(rfcSecret, code, now); ok {
+			t.Errorf("code of step %+d accepted", offset)
+		}
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	a, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := GenerateTOTPSecret()
+	if len(a) != 32 || a == b {
+		t.Errorf("secrets %q and %q, want distinct 160-bit base32 keys", a, b)
+	}
+	if _, err := TOTPCode(a, 1); err != nil {
+		t.Errorf("generated secret unusable: %v", err)
+	}
+}
@@ -6,7 +6,11 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"math"
 	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -28,21 +32,63 @@ func CreateHMAC(data string, secret []byte) string {
 	return hex.EncodeToString(h.Sum(nil))
 }
 
-// Генератор транзакционных ID
-func GenerateTransactionID() string {
-	timestamp := time.Now().UnixNano()
+// Генератор транзакционных ID, now - текущее время по часам сервиса
+func GenerateTransactionID(now time.Time) string {
+	timestamp := now.UnixNano()
 	random := GenerateRandomString(8)
 	return fmt.Sprintf("%d-%s", timestamp, random)
 }
 
-// GetCurrentTimestamp возвращает текущую временную метку в формате RFC3339.
+// GetCurrentTimestamp возвращает текущую временную метку по реальным часам.
+// Сервисы используют свои внедрённые Clock.
 func GetCurrentTimestamp() time.Time {
-	return time.Now()
+	return RealClock.Now()
 }
 
+// balanceHashDecimals - число знаков после запятой, с которым баланс попадает в хэш.
+// Совпадает с прежним форматом %f, поэтому уже сохранённые хэши остаются валидными.
+const balanceHashDecimals = 6
+
+// CanonicalBalance возвращает единственное строковое представление баланса для хэша:
+// округление до balanceHashDecimals знаков, без экспоненты и без "-0".
+func CanonicalBalance(balance float64) string {
+	scale := math.Pow10(balanceHashDecimals)
+	rounded := math.Round(balance*scale) / scale
+	if rounded == 0 {
+		rounded = 0 // -0 превращается в 0
+	}
+	return strconv.FormatFloat(rounded, 'f', balanceHashDecimals, 64)
+}
+
+// BalanceHashMessage возвращает строку, которую подписывает хэш баланса: "<канонический баланс>:<ID счёта>".
+func BalanceHashMessage(balance float64, accountID int) string {
+	return fmt.Sprintf("%s:%d", CanonicalBalance(balance), accountID)
+}
+
+// CalculateBalanceHash считает HMAC баланса счёта. Все пути записи и проверки должны использовать только её.
 func CalculateBalanceHash(balance float64, accountID int, secretKey string) string {
-	h := hmac.New(sha256.New, []byte(secretKey))
-	data := fmt.Sprintf("%f:%d", balance, accountID)
-	h.Write([]byte(data))
-	return hex.EncodeToString(h.Sum(nil))
+	return CreateHMAC(BalanceHashMessage(balance, accountID), []byte(secretKey))
+}
+
+// AccountHashMessage дополняет BalanceHashMessage частями баланса (например, бонусной) в алфавитном
+// порядке: "<баланс>:<ID счёта>:bonus=<канонический остаток>". Для счёта без частей совпадает с BalanceHashMessage.
+func AccountHashMessage(balance float64, accountID int, subBalances map[string]float64) string {
+	names := make([]string, 0, len(subBalances))
+	for name := range subBalances {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString(BalanceHashMessage(balance, accountID))
+	for _, name := range names {
+		fmt.Fprintf(&b, ":%s=%s", name, CanonicalBalance(subBalances[name]))
+	}
+	return b.String()
+}
+
+// CalculateAccountHash считает HMAC баланса счёта вместе с его частями, так что подмена любой части
+// обнаруживается той же проверкой balance_hash.
+func CalculateAccountHash(balance float64, accountID int, subBalances map[string]float64, secretKey string) string {
+	return CreateHMAC(AccountHashMessage(balance, accountID, subBalances), []byte(secretKey))
 }
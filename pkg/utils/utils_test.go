@@ -0,0 +1,71 @@
+// Path: pkg/utils/utils_test.go
+package utils
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestAccountHashMessage(t *testing.T) {
+	if got, want := AccountHashMessage(100, 7, nil), BalanceHashMessage(100, 7); got != want {
+		t.Errorf("without sub-balances: %q, want %q", got, want)
+	}
+
+	got := AccountHashMessage(100, 7, map[string]float64{"promo": 5, "bonus": 30})
+	if want := "100.000000:7:bonus=30.000000:promo=5.000000"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCalculateAccountHashCoversSubBalances(t *testing.T) {
+	hash := CalculateAccountHash(100, 7, map[string]float64{"bonus": 30}, "secret")
+	if CalculateAccountHash(100, 7, map[string]float64{"bonus": 31}, "secret") == hash {
+		t.Error("hash unchanged after a sub-balance changed")
+	}
+	if CalculateBalanceHash(100, 7, "secret") != CalculateAccountHash(100, 7, nil, "secret") {
+		t.Error("account without sub-balances must keep its balance hash")
+	}
+}
+
+func TestCanonicalBalance(t *testing.T) {
+	cases := []struct {
+		balance float64
+		want    string
+	}{
+		{100, "100.000000"},
+		{0.1 + 0.2, "0.300000"},
+		{-0.0000001, "0.000000"},
+		{-12.5, "-12.500000"},
+		{1e21, "1000000000000000000000.000000"},
+		{1234.5678901, "1234.567890"},
+	}
+	for _, c := range cases {
+		if got := CanonicalBalance(c.balance); got != c.want {
+			t.Errorf("CanonicalBalance(%v) = %q, want %q", c.balance, got, c.want)
+		}
+	}
+}
+
+func TestBalanceHashMatchesThePreviousFormat(t *testing.T) {
+	// Hashes stored before the canonical format signed fmt.Sprintf("%f:%d").
+	for _, balance := range []float64{0, 100, 99.99, 1234.5} {
+		if got, want := BalanceHashMessage(balance, 3), fmt.Sprintf("%f:%d", balance, 3); got != want {
+			t.Errorf("BalanceHashMessage(%v) = %q, want %q", balance, got, want)
+		}
+	}
+}
+
+func TestBalanceHashSurvivesFloatDrift(t *testing.T) {
+	balance := 0.0
+	for i := 0; i < 1000; i++ {
+		balance += 0.1
+		balance -= 0.07
+	}
+	// 1000 * 0.03 = 30, off by the accumulated float residue.
+	if balance == 30 {
+		t.Skip("no drift to test on this platform")
+	}
+	if CalculateBalanceHash(balance, 1, "secret") != CalculateBalanceHash(30, 1, "secret") {
+		t.Errorf("hash of %v differs from the hash of 30", balance)
+	}
+}